@@ -10,76 +10,9 @@ import (
 	openaiembed "github.com/rexleimo/agno-go/pkg/agno/embeddings/openai"
 	"github.com/rexleimo/agno-go/pkg/agno/knowledge"
 	openaimodel "github.com/rexleimo/agno-go/pkg/agno/models/openai"
-	"github.com/rexleimo/agno-go/pkg/agno/tools/toolkit"
-	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
 	"github.com/rexleimo/agno-go/pkg/agno/vectordb/chromadb"
 )
 
-// RAGToolkit provides knowledge retrieval tools for the agent
-type RAGToolkit struct {
-	*toolkit.BaseToolkit
-	vectorDB vectordb.VectorDB
-}
-
-// NewRAGToolkit creates a new RAG toolkit
-func NewRAGToolkit(db vectordb.VectorDB) *RAGToolkit {
-	t := &RAGToolkit{
-		BaseToolkit: toolkit.NewBaseToolkit("knowledge_retrieval"),
-		vectorDB:    db,
-	}
-
-	// Register search function
-	t.RegisterFunction(&toolkit.Function{
-		Name:        "search_knowledge",
-		Description: "Search the knowledge base for relevant information. Use this to find answers to user questions.",
-		Parameters: map[string]toolkit.Parameter{
-			"query": {
-				Type:        "string",
-				Description: "The search query or question",
-				Required:    true,
-			},
-			"limit": {
-				Type:        "integer",
-				Description: "Maximum number of results to return (default: 3)",
-				Required:    false,
-			},
-		},
-		Handler: t.searchKnowledge,
-	})
-
-	return t
-}
-
-func (t *RAGToolkit) searchKnowledge(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	query, ok := args["query"].(string)
-	if !ok {
-		return nil, fmt.Errorf("query must be a string")
-	}
-
-	limit := 3
-	if l, ok := args["limit"].(float64); ok {
-		limit = int(l)
-	}
-
-	results, err := t.vectorDB.Query(ctx, query, limit, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search knowledge base: %w", err)
-	}
-
-	// Format results for the agent
-	var formattedResults []map[string]interface{}
-	for i, result := range results {
-		formattedResults = append(formattedResults, map[string]interface{}{
-			"rank":     i + 1,
-			"content":  result.Content,
-			"score":    result.Score,
-			"metadata": result.Metadata,
-		})
-	}
-
-	return formattedResults, nil
-}
-
 func main() {
 	fmt.Println("🚀 RAG (Retrieval-Augmented Generation) Demo")
 	fmt.Println("This example demonstrates:")
@@ -178,44 +111,32 @@ func main() {
 		},
 	}
 
-	// Chunk documents (optional, useful for large documents)
-	chunker := knowledge.NewCharacterChunker(500, 50)
-	var allChunks []knowledge.Chunk
-	for _, doc := range sampleDocs {
-		chunks, err := chunker.Chunk(doc)
-		if err != nil {
-			log.Printf("Warning: Failed to chunk document %s: %v", doc.ID, err)
-			continue
-		}
-		allChunks = append(allChunks, chunks...)
+	// The knowledge base chunks the documents and stores them in the
+	// vector database; ChromaDB embeds them with the embedding function.
+	kb, err := knowledge.NewBase(knowledge.BaseConfig{
+		VectorDB: db,
+		Chunker:  knowledge.NewCharacterChunker(500, 50),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create knowledge base: %v", err)
 	}
-	fmt.Printf("   ✅ Loaded %d documents, created %d chunks\n", len(sampleDocs), len(allChunks))
+	fmt.Printf("   ✅ Loaded %d documents\n", len(sampleDocs))
 
 	// Step 4: Generate embeddings and store in vector DB
 	fmt.Println("\n🔢 Step 4: Generating embeddings and storing in ChromaDB...")
 
-	var vdbDocs []vectordb.Document
-	for _, chunk := range allChunks {
-		vdbDocs = append(vdbDocs, vectordb.Document{
-			ID:       chunk.ID,
-			Content:  chunk.Content,
-			Metadata: chunk.Metadata,
-			// Embedding will be generated automatically by ChromaDB
-		})
-	}
-
-	err = db.Add(ctx, vdbDocs)
+	result, err := kb.LoadDocuments(ctx, sampleDocs)
 	if err != nil {
-		log.Fatalf("Failed to add documents to vector DB: %v", err)
+		log.Fatalf("Failed to add documents to knowledge base: %v", err)
 	}
 
 	count, _ := db.Count(ctx)
-	fmt.Printf("   ✅ Stored %d documents in vector database\n\n", count)
+	fmt.Printf("   ✅ Stored %d chunks from %d documents (%d unchanged)\n\n", count, result.Documents, result.Skipped)
 
 	// Step 5: Test retrieval
 	fmt.Println("🔍 Step 5: Testing knowledge retrieval...")
 	testQuery := "What is machine learning?"
-	results, err := db.Query(ctx, testQuery, 2, nil)
+	results, err := kb.Search(ctx, testQuery, 2, nil)
 	if err != nil {
 		log.Fatalf("Failed to query: %v", err)
 	}
@@ -241,17 +162,16 @@ func main() {
 		log.Fatalf("Failed to create model: %v", err)
 	}
 
-	// Create RAG toolkit
-	ragToolkit := NewRAGToolkit(db)
-
-	// Create agent with RAG capabilities
+	// Create agent with RAG capabilities: the knowledge base is exposed
+	// as the search_knowledge_base tool
 	ag, err := agent.New(agent.Config{
-		Name:     "RAG Assistant",
-		Model:    model,
-		Toolkits: []toolkit.Toolkit{ragToolkit},
+		Name:          "RAG Assistant",
+		Model:         model,
+		Knowledge:     kb,
+		KnowledgeMode: agent.KnowledgeModeTool,
 		Instructions: `You are a helpful AI assistant with access to a knowledge base.
 When users ask questions:
-1. Use the search_knowledge tool to find relevant information
+1. Use the search_knowledge_base tool to find relevant information
 2. Base your answer on the retrieved information
 3. Cite the sources when possible
 4. If you can't find relevant information, say so
@@ -13,12 +13,15 @@ import (
 func main() {
 	var (
 		action       = flag.String("action", "up", "Migration action: up|down")
-		provider     = flag.String("provider", "chroma", "VectorDB provider: chroma")
+		provider     = flag.String("provider", "chroma", "VectorDB provider: chroma|pgvector|redis")
 		collection   = flag.String("collection", "", "Collection name")
 		chromaURL    = flag.String("chroma-url", os.Getenv("CHROMA_URL"), "Chroma base URL (default from CHROMA_URL)")
 		chromaTenant = flag.String("chroma-tenant", os.Getenv("CHROMA_TENANT"), "Chroma tenant")
 		chromaDB     = flag.String("chroma-db", os.Getenv("CHROMA_DB"), "Chroma database")
 		distance     = flag.String("distance", os.Getenv("VECTOR_DISTANCE"), "Distance function: l2|cosine|ip")
+		pgDSN        = flag.String("pg-dsn", os.Getenv("PGVECTOR_DSN"), "Postgres DSN for pgvector (default from PGVECTOR_DSN)")
+		dimensions   = flag.Int("dimensions", 0, "Embedding dimensions (required by pgvector)")
+		index        = flag.String("index", "", "pgvector index: hnsw|ivfflat (default none)")
 		timeout      = flag.Duration("timeout", 30*time.Second, "Operation timeout")
 	)
 	flag.Parse()
@@ -35,6 +38,9 @@ func main() {
 		ChromaTenant:   *chromaTenant,
 		ChromaDatabase: *chromaDB,
 		Distance:       *distance,
+		PostgresDSN:    *pgDSN,
+		Dimensions:     *dimensions,
+		Index:          *index,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
@@ -16,6 +16,10 @@ type Options struct {
 	ChromaTenant   string
 	ChromaDatabase string
 	Distance       string // l2|cosine|ip
+	// pgvector-specific
+	PostgresDSN string
+	Dimensions  int
+	Index       string // hnsw|ivfflat
 }
 
 // Factory creates a VectorDB instance from options
@@ -1,3 +1,5 @@
+//go:build !redis
+
 package migrate
 
 import (
@@ -7,12 +9,14 @@ import (
     "github.com/rexleimo/agno-go/pkg/agno/vectordb/chromadb"
 )
 
-// defaultFactory provides Chroma provider by default; Redis is optional and disabled here.
+// defaultFactory provides Chroma and pgvector providers by default; Redis is optional and disabled here.
 func defaultFactory(opts Options) (vectordb.VectorDB, error) {
     switch opts.Provider {
     case "chroma", "chromadb", "chromad b":
         cfg := chromadb.Config{BaseURL: opts.ChromaBaseURL, CollectionName: opts.Collection, Database: opts.ChromaDatabase, Tenant: opts.ChromaTenant}
         return chromadb.New(cfg)
+    case "pgvector", "postgres":
+        return newPgVector(opts)
     case "redis":
         return nil, fmt.Errorf("redis vectordb provider not enabled (build with -tags redis)")
     default:
@@ -10,12 +10,14 @@ import (
 	"github.com/rexleimo/agno-go/pkg/agno/vectordb/redisdb"
 )
 
-// defaultFactory compiled with redis tag supports both chroma and redis providers.
+// defaultFactory compiled with redis tag supports chroma, pgvector and redis providers.
 func defaultFactory(opts Options) (vectordb.VectorDB, error) {
 	switch opts.Provider {
 	case "chroma", "chromadb", "chromad b":
 		cfg := chromadb.Config{BaseURL: opts.ChromaBaseURL, CollectionName: opts.Collection, Database: opts.ChromaDatabase, Tenant: opts.ChromaTenant}
 		return chromadb.New(cfg)
+	case "pgvector", "postgres":
+		return newPgVector(opts)
 	case "redis":
 		cfg := redisdb.Config{Addr: opts.ChromaBaseURL /* reusing field for addr if provided */, CollectionName: opts.Collection}
 		return redisdb.New(cfg)
@@ -0,0 +1,16 @@
+package migrate
+
+import (
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb/pgvector"
+)
+
+func newPgVector(opts Options) (vectordb.VectorDB, error) {
+	cfg := pgvector.Config{
+		DSN:            opts.PostgresDSN,
+		CollectionName: opts.Collection,
+		Dimensions:     opts.Dimensions,
+		Index:          pgvector.IndexType(opts.Index),
+	}
+	return pgvector.New(cfg)
+}
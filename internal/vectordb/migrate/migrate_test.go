@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb/pgvector"
 )
 
 type fakeDB struct {
@@ -77,3 +78,18 @@ func TestRedisProvider_Gated(t *testing.T) {
 		t.Fatalf("Down error: %v", err)
 	}
 }
+
+func TestDefaultFactory_PgVector(t *testing.T) {
+	db, err := defaultFactory(Options{Provider: "pgvector", Collection: "docs", PostgresDSN: "postgres://localhost/agno", Dimensions: 3, Index: "hnsw"})
+	if err != nil {
+		t.Fatalf("defaultFactory error: %v", err)
+	}
+	defer db.Close()
+	if _, ok := db.(*pgvector.PgVector); !ok {
+		t.Fatalf("expected *pgvector.PgVector, got %T", db)
+	}
+
+	if _, err := defaultFactory(Options{Provider: "pgvector", Collection: "docs"}); err == nil {
+		t.Fatal("expected error without a dsn")
+	}
+}
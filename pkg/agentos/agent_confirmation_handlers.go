@@ -0,0 +1,130 @@
+package agentos
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rexleimo/agno-go/pkg/agno/agent"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// AgentContinueRequest answers the pending tool calls of a paused agent run.
+type AgentContinueRequest struct {
+	Decisions []agent.ToolCallDecision `json:"decisions"`
+}
+
+// AgentCancelResponse reports a paused agent run that was cancelled.
+type AgentCancelResponse struct {
+	RunID  string          `json:"run_id"`
+	Status agent.RunStatus `json:"status"`
+}
+
+// handleAgentContinue executes the confirmed tool calls of a paused run and continues it.
+// POST /api/v1/agents/:id/runs/:run_id/continue
+func (s *Server) handleAgentContinue(c *gin.Context) {
+	agentID := c.Param("id")
+	runID := c.Param("run_id")
+
+	var req AgentContinueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid request",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	ag, ok := s.lookupAgent(c, agentID)
+	if !ok {
+		return
+	}
+
+	s.logger.Info("agent run continue requested",
+		"agent_id", agentID,
+		"run_id", runID,
+		"decisions", len(req.Decisions))
+
+	output, err := ag.ContinueRun(c.Request.Context(), runID, req.Decisions)
+	if err != nil {
+		s.writePausedRunError(c, err)
+		return
+	}
+
+	metadata := map[string]interface{}{"agent_id": agentID}
+	for k, v := range output.Metadata {
+		metadata[k] = v
+	}
+	c.JSON(http.StatusOK, AgentRunResponse{
+		RunID:            output.RunID,
+		Status:           output.Status,
+		Content:          output.Content,
+		PendingToolCalls: output.PendingToolCalls,
+		Metadata:         metadata,
+	})
+}
+
+// handleAgentCancel abandons a paused run without executing its tool calls.
+// POST /api/v1/agents/:id/runs/:run_id/cancel
+func (s *Server) handleAgentCancel(c *gin.Context) {
+	agentID := c.Param("id")
+	runID := c.Param("run_id")
+
+	ag, ok := s.lookupAgent(c, agentID)
+	if !ok {
+		return
+	}
+
+	if err := ag.CancelPausedRun(runID); err != nil {
+		s.writePausedRunError(c, err)
+		return
+	}
+
+	s.logger.Info("paused agent run cancelled", "agent_id", agentID, "run_id", runID)
+	c.JSON(http.StatusOK, AgentCancelResponse{RunID: runID, Status: agent.RunStatusCancelled})
+}
+
+func (s *Server) lookupAgent(c *gin.Context, agentID string) (*agent.Agent, bool) {
+	ag, err := s.agentRegistry.Get(agentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "agent not found",
+			Message: err.Error(),
+			Code:    "AGENT_NOT_FOUND",
+		})
+		return nil, false
+	}
+	return ag, true
+}
+
+func (s *Server) writePausedRunError(c *gin.Context, err error) {
+	var agnoErr *types.AgnoError
+	switch {
+	case errors.Is(err, agent.ErrRunNotPaused):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "run is not paused",
+			Message: err.Error(),
+			Code:    "RUN_NOT_PAUSED",
+		})
+	case errors.As(err, &agnoErr) && agnoErr.Code == types.ErrCodeInvalidInput:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid tool call decisions",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+	case errors.As(err, &agnoErr) && agnoErr.Code == types.ErrCodeCancelled:
+		c.JSON(http.StatusRequestTimeout, ErrorResponse{
+			Error:   "agent execution failed",
+			Message: err.Error(),
+			Code:    string(types.ErrCodeCancelled),
+		})
+	default:
+		s.logger.Error("agent run continue failed", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "agent execution failed",
+			Message: err.Error(),
+			Code:    "EXECUTION_ERROR",
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package agentos
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/agent"
+	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/tools/toolkit"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// confirmModel calls send once, then answers with the tool result it saw.
+type confirmModel struct {
+	models.BaseModel
+}
+
+func (m *confirmModel) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+	for _, msg := range req.Messages {
+		if msg.Role == types.RoleTool {
+			return &types.ModelResponse{Content: msg.Content}, nil
+		}
+	}
+	return &types.ModelResponse{ToolCalls: []types.ToolCall{{
+		ID:       "call_send",
+		Type:     "function",
+		Function: types.ToolCallFunction{Name: "send", Arguments: `{"to":"alice"}`},
+	}}}, nil
+}
+
+func (m *confirmModel) InvokeStream(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
+	ch := make(chan types.ResponseChunk)
+	close(ch)
+	return ch, nil
+}
+
+func newConfirmServer(t *testing.T) *Server {
+	t.Helper()
+
+	server, err := NewServer(nil)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	tk := toolkit.NewBaseToolkit("mail")
+	tk.RegisterFunction(&toolkit.Function{
+		Name:        "send",
+		Description: "send a message",
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "sent to " + args["to"].(string), nil
+		},
+	})
+	if err := tk.RequireConfirmation("send"); err != nil {
+		t.Fatalf("RequireConfirmation() error = %v", err)
+	}
+
+	ag, err := agent.New(agent.Config{
+		Name:     "mailer",
+		Model:    &confirmModel{BaseModel: models.BaseModel{ID: "mock-model", Provider: "mock"}},
+		Toolkits: []toolkit.Toolkit{tk},
+	})
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	if err := server.RegisterAgent("mailer", ag); err != nil {
+		t.Fatalf("failed to register agent: %v", err)
+	}
+	return server
+}
+
+func startPausedAgentRun(t *testing.T, server *Server) AgentRunResponse {
+	t.Helper()
+
+	w := postApproval(server, "/api/v1/agents/mailer/run", AgentRunRequest{Input: "mail alice"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp AgentRunResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Status != agent.RunStatusPaused || len(resp.PendingToolCalls) != 1 ||
+		resp.PendingToolCalls[0].ID != "call_send" {
+		t.Fatalf("expected a paused run pending call_send, got %+v", resp)
+	}
+	return resp
+}
+
+func TestHandleAgentContinue_EditRunsTool(t *testing.T) {
+	server := newConfirmServer(t)
+	paused := startPausedAgentRun(t, server)
+
+	// The paused run blocks new runs of the same user and session
+	w := postApproval(server, "/api/v1/agents/mailer/run", AgentRunRequest{Input: "again"})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+
+	path := "/api/v1/agents/mailer/runs/" + paused.RunID + "/continue"
+	w = postApproval(server, path, AgentContinueRequest{Decisions: []agent.ToolCallDecision{
+		{ToolCallID: "call_send", Action: agent.ToolCallEdit, Arguments: map[string]interface{}{"to": "bob"}},
+	}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp AgentRunResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Status != agent.RunStatusCompleted || !strings.Contains(resp.Content, "sent to bob") {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	// The run is no longer paused
+	w = postApproval(server, path, AgentContinueRequest{})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleAgentContinue_InvalidDecisions(t *testing.T) {
+	server := newConfirmServer(t)
+	paused := startPausedAgentRun(t, server)
+
+	w := postApproval(server, "/api/v1/agents/mailer/runs/"+paused.RunID+"/continue", AgentContinueRequest{})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = postApproval(server, "/api/v1/agents/missing/runs/"+paused.RunID+"/continue", AgentContinueRequest{})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleAgentCancel(t *testing.T) {
+	server := newConfirmServer(t)
+	paused := startPausedAgentRun(t, server)
+	path := "/api/v1/agents/mailer/runs/" + paused.RunID + "/cancel"
+
+	req, _ := http.NewRequest(http.MethodPost, path, nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp AgentCancelResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.RunID != paused.RunID || resp.Status != agent.RunStatusCancelled {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// New runs are accepted again
+	w = postApproval(server, "/api/v1/agents/mailer/run", AgentRunRequest{Input: "mail alice"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
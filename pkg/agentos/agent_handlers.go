@@ -66,6 +66,24 @@ func buildMediaPlaceholder(count int) string {
 	return fmt.Sprintf("Media request (%d attachments)", count)
 }
 
+// modelContentParts converts URL attachments into content parts for the
+// model. Path attachments are not forwarded: they name files on the server,
+// which must not be read on behalf of API clients.
+func modelContentParts(attachments []media.Attachment) []types.ContentPart {
+	parts := make([]types.ContentPart, 0, len(attachments))
+	for _, att := range attachments {
+		if att.URL == "" {
+			continue
+		}
+		part, err := media.ToContentPart(att)
+		if err != nil {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
 // AgentRunResponse represents the response from running an agent
 type AgentRunResponse struct {
 	RunID     string                 `json:"run_id,omitempty"`
@@ -73,6 +91,9 @@ type AgentRunResponse struct {
 	Content   string                 `json:"content"`
 	SessionID string                 `json:"session_id,omitempty"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	// PendingToolCalls await confirmation when Status is paused; answer them
+	// with POST /api/v1/agents/:id/runs/:run_id/continue
+	PendingToolCalls []types.ToolCall `json:"pending_tool_calls,omitempty"`
 }
 
 // handleAgentRun runs an agent with the given input
@@ -174,15 +195,20 @@ func (s *Server) handleAgentRun(c *gin.Context) {
 	// Run the agent (inject a run-context id for correlation)
 	baseCtx := ctxWithRunContext
 	// Run the agent
-	output, err := ag.Run(baseCtx, req.Input)
+	output, err := ag.Run(baseCtx, req.Input, modelContentParts(attachments)...)
 
 	if err != nil {
 		s.logger.Error("agent run failed", "error", err, "agent_id", agentID)
 		status := http.StatusInternalServerError
 		errorCode := "EXECUTION_ERROR"
+		var pausedErr *agent.PausedRunError
 		if agnoErr, ok := err.(*types.AgnoError); ok && agnoErr.Code == types.ErrCodeCancelled {
 			status = http.StatusRequestTimeout
 			errorCode = string(types.ErrCodeCancelled)
+		} else if errors.As(err, &pausedErr) {
+			// Another run of this user and session awaits confirmation
+			status = http.StatusConflict
+			errorCode = "RUN_PAUSED"
 		}
 		c.JSON(status, ErrorResponse{
 			Error:   "agent execution failed",
@@ -219,11 +245,12 @@ func (s *Server) handleAgentRun(c *gin.Context) {
 	}
 
 	response := AgentRunResponse{
-		RunID:     output.RunID,
-		Status:    output.Status,
-		Content:   output.Content,
-		SessionID: req.SessionID,
-		Metadata:  metadata,
+		RunID:            output.RunID,
+		Status:           output.Status,
+		Content:          output.Content,
+		SessionID:        req.SessionID,
+		Metadata:         metadata,
+		PendingToolCalls: output.PendingToolCalls,
 	}
 
 	s.logger.Info("agent run completed", "agent_id", agentID, "run_id", output.RunID)
@@ -318,7 +345,7 @@ func (s *Server) streamAgentRun(
 		flusher.Flush()
 	}
 
-	result, err := ag.RunStream(ctx, req.Input)
+	result, err := ag.RunStream(ctx, req.Input, modelContentParts(attachments)...)
 	if err != nil {
 		code := "AGENT_ERROR"
 		if agnoErr, ok := err.(*types.AgnoError); ok {
@@ -367,6 +394,17 @@ func (s *Server) streamAgentRun(
 				continue
 			}
 
+			if toolEvent := buildToolCallEvent(evt); toolEvent != nil {
+				toolEvent.AgentID = agentID
+				toolEvent.SessionID = req.SessionID
+				toolEvent.RunContextID = runCtxID
+				if filter.ShouldSend(toolEvent) {
+					s.sendSSE(c.Writer, toolEvent)
+					flusher.Flush()
+				}
+				continue
+			}
+
 			contentEvent, ok := evt.(*run.RunContentEvent)
 			if !ok || strings.TrimSpace(contentEvent.Content) == "" {
 				continue
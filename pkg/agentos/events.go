@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/rexleimo/agno-go/pkg/agno/media"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
 )
 
 // EventType 表示事件类型
@@ -104,6 +105,18 @@ type ToolCallData struct {
 	// Result 工具结果（可选）
 	// Result is the tool result (optional)
 	Result interface{} `json:"result,omitempty"`
+
+	// ToolCallID 工具调用 ID（可选）
+	// ToolCallID correlates started and completed events (optional)
+	ToolCallID string `json:"tool_call_id,omitempty"`
+
+	// Status 工具调用状态（started 或 completed）
+	// Status is the tool call status ("started" or "completed")
+	Status string `json:"status,omitempty"`
+
+	// Error 工具错误信息（可选）
+	// Error is the tool error message (optional)
+	Error string `json:"error,omitempty"`
 }
 
 // TokenData 令牌事件数据
@@ -184,6 +197,10 @@ type CompleteData struct {
 
 	// CancellationReason 取消原因
 	CancellationReason string `json:"cancellation_reason,omitempty"`
+
+	// PendingToolCalls 等待确认的工具调用（状态为 paused 时）
+	// PendingToolCalls lists the tool calls awaiting confirmation when the status is paused
+	PendingToolCalls []types.ToolCall `json:"pending_tool_calls,omitempty"`
 }
 
 // ReasoningSummary provides a compact reasoning representation
@@ -1,6 +1,7 @@
 package agentos
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/rexleimo/agno-go/pkg/agno/agent"
+	"github.com/rexleimo/agno-go/pkg/agno/run"
 	"github.com/rexleimo/agno-go/pkg/agno/types"
 )
 
@@ -67,6 +69,41 @@ func (s *Server) sendSSE(w io.Writer, event *Event) error {
 	return err
 }
 
+// buildToolCallEvent converts agent tool-call run events into SSE tool_call
+// events. It returns nil for any other event type.
+func buildToolCallEvent(evt run.BaseRunOutputEvent) *Event {
+	switch e := evt.(type) {
+	case *run.ToolCallStartedEvent:
+		return NewEvent(EventToolCall, ToolCallData{
+			ToolName:   e.ToolName,
+			Arguments:  parseToolArguments(e.Arguments),
+			ToolCallID: e.ToolCallID,
+			Status:     "started",
+		})
+	case *run.ToolCallCompletedEvent:
+		return NewEvent(EventToolCall, ToolCallData{
+			ToolName:   e.ToolName,
+			Result:     e.Result,
+			ToolCallID: e.ToolCallID,
+			Status:     "completed",
+			Error:      e.Error,
+		})
+	default:
+		return nil
+	}
+}
+
+func parseToolArguments(raw string) map[string]interface{} {
+	if strings.TrimSpace(raw) == "" {
+		return map[string]interface{}{}
+	}
+	args := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(raw), &args); err != nil {
+		return map[string]interface{}{"raw": raw}
+	}
+	return args
+}
+
 func buildReasoningEvents(messages []*types.Message, provider, modelID string) ([]*Event, *ReasoningSummary) {
 	if len(messages) == 0 {
 		return nil, nil
@@ -197,6 +234,7 @@ func (s *Server) emitRunEvents(w io.Writer, flusher http.Flusher, filter *EventF
 		CacheHit:           cacheHit,
 		RunID:              output.RunID,
 		CancellationReason: output.CancellationReason,
+		PendingToolCalls:   output.PendingToolCalls,
 	})
 	complete.AgentID = agentID
 	complete.SessionID = sessionID
@@ -13,9 +13,14 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rexleimo/agno-go/pkg/agno/knowledge"
 	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
 )
 
+// rerankCandidateFactor 重排序时额外召回的候选倍数
+// rerankCandidateFactor widens retrieval when results are reranked
+const rerankCandidateFactor = 4
+
 // KnowledgeService 封装知识库操作
 // KnowledgeService encapsulates knowledge operations
 type KnowledgeService struct {
@@ -90,6 +95,10 @@ type KnowledgeServiceConfig struct {
 
 	// AllowedSourceSchemes 允许的来源 URL scheme
 	AllowedSourceSchemes []string
+
+	// Reranker 搜索结果重排序器（可选）
+	// Reranker reorders search results before pagination (optional)
+	Reranker knowledge.Reranker
 }
 
 // NewKnowledgeService 创建知识库服务
@@ -281,7 +290,12 @@ func (s *Server) handleKnowledgeSearch(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), knowledgeSvc.searchTimeout())
 	defer cancel()
 
-	results, err := knowledgeSvc.vectorDB.Query(ctx, req.Query, req.Limit+req.Offset, req.Filters)
+	fetch := req.Limit + req.Offset
+	if knowledgeSvc.config.Reranker != nil {
+		fetch *= rerankCandidateFactor
+	}
+
+	results, err := knowledgeSvc.vectorDB.Query(ctx, req.Query, fetch, req.Filters)
 	if err != nil {
 		s.logger.Error("knowledge search failed", "error", err, "query", req.Query)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -291,6 +305,20 @@ func (s *Server) handleKnowledgeSearch(c *gin.Context) {
 		return
 	}
 
+	// 重排序后再分页，保证各页顺序一致
+	// Rerank before paginating so pages follow the reranked order
+	if knowledgeSvc.config.Reranker != nil {
+		results, err = knowledgeSvc.config.Reranker.Rerank(ctx, req.Query, results, 0)
+		if err != nil {
+			s.logger.Error("knowledge rerank failed", "error", err, "query", req.Query)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "rerank_failed",
+				"message": fmt.Sprintf("failed to rerank: %v", err),
+			})
+			return
+		}
+	}
+
 	totalCount := len(results)
 	paginatedResults := results
 	if req.Offset < len(results) {
@@ -319,6 +319,59 @@ func TestHandleKnowledgeSearch_Pagination(t *testing.T) {
 	mockVectorDB.AssertExpectations(t)
 }
 
+// reverseReranker 按相反顺序返回结果
+// reverseReranker returns results in reverse order
+type reverseReranker struct{}
+
+func (reverseReranker) Rerank(ctx context.Context, query string, results []vectordb.SearchResult, topN int) ([]vectordb.SearchResult, error) {
+	out := make([]vectordb.SearchResult, 0, len(results))
+	for i := len(results) - 1; i >= 0; i-- {
+		out = append(out, results[i])
+	}
+	return out, nil
+}
+
+func TestHandleKnowledgeSearch_Rerank(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockVectorDB := new(MockVectorDB)
+	mockEmbedding := new(MockEmbeddingFunc)
+
+	// 重排序时召回 limit 的 4 倍候选
+	// Reranking retrieves four times the limit as candidates
+	mockVectorDB.On("Query", mock.Anything, "test query", 8, mock.Anything).
+		Return([]vectordb.SearchResult{{ID: "doc1"}, {ID: "doc2"}, {ID: "doc3"}}, nil)
+
+	knowledgeSvc := NewKnowledgeService(mockVectorDB, mockEmbedding, KnowledgeServiceConfig{
+		Reranker: reverseReranker{},
+	})
+	server := &Server{
+		knowledgeService: knowledgeSvc,
+		logger:           slog.Default(),
+	}
+
+	router := gin.New()
+	router.POST("/search", server.handleKnowledgeSearch)
+
+	reqBody, _ := json.Marshal(VectorSearchRequest{Query: "test query", Limit: 2})
+	httpReq := httptest.NewRequest(http.MethodPost, "/search", bytes.NewBuffer(reqBody))
+	httpReq.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response VectorSearchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, len(response.Results))
+	assert.Equal(t, "doc3", response.Results[0].ID)
+	assert.Equal(t, "doc2", response.Results[1].ID)
+	assert.True(t, response.Meta.HasMore)
+
+	mockVectorDB.AssertExpectations(t)
+}
+
 func TestHandleKnowledgeSearch_ServiceNotConfigured(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
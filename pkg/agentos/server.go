@@ -11,10 +11,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rexleimo/agno-go/pkg/agno/agent"
 	"github.com/rexleimo/agno-go/pkg/agno/embeddings/openai"
+	"github.com/rexleimo/agno-go/pkg/agno/knowledge"
 	"github.com/rexleimo/agno-go/pkg/agno/session"
 	"github.com/rexleimo/agno-go/pkg/agno/team"
 	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
 	"github.com/rexleimo/agno-go/pkg/agno/vectordb/chromadb"
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb/localdb"
+	"github.com/rexleimo/agno-go/pkg/agno/workflow"
 )
 
 // Server represents the AgentOS HTTP server
@@ -24,6 +27,7 @@ type Server struct {
 	sessionStorage   session.Storage
 	agentRegistry    *AgentRegistry
 	teamRegistry     *TeamRegistry
+	workflowRegistry *WorkflowRegistry
 	logger           *slog.Logger
 	httpServer       *http.Server
 	knowledgeService *KnowledgeService // 知识库服务
@@ -91,8 +95,8 @@ type Config struct {
 // VectorDBConfig 向量数据库配置
 // VectorDBConfig is the vector database configuration
 type VectorDBConfig struct {
-	// Type 向量数据库类型（chromadb）
-	// Type is the vector database type
+	// Type 向量数据库类型（chromadb、local）
+	// Type is the vector database type (chromadb, local)
 	Type string
 
 	// BaseURL 向量数据库 URL
@@ -110,6 +114,10 @@ type VectorDBConfig struct {
 	// Tenant 租户名称
 	// Tenant is the tenant name
 	Tenant string
+
+	// Path local 类型的 SQLite 持久化文件（为空则仅内存）
+	// Path is the SQLite file persisting the local type (memory only if empty)
+	Path string
 }
 
 // EmbeddingConfig 嵌入模型配置
@@ -166,6 +174,10 @@ type KnowledgeAPIOptions struct {
 
 	// AllowedSourceSchemes 允许的来源 URL scheme（为空表示不限制）
 	AllowedSourceSchemes []string
+
+	// Reranker 搜索结果重排序器（可选，例如 knowledge.NewHTTPReranker）
+	// Reranker reorders search results (optional, e.g. knowledge.NewHTTPReranker)
+	Reranker knowledge.Reranker
 }
 
 func normalizeKnowledgeOptions(opts *KnowledgeAPIOptions) {
@@ -267,14 +279,15 @@ func NewServer(config *Config) (*Server, error) {
 	router.Use(timeoutMiddleware(config.RequestTimeout))
 
 	server := &Server{
-		router:         router,
-		config:         config,
-		sessionStorage: config.SessionStorage,
-		agentRegistry:  NewAgentRegistry(),
-		teamRegistry:   NewTeamRegistry(),
-		logger:         config.Logger,
-		summaryManager: config.SummaryManager,
-		instantiatedAt: time.Now().UTC(),
+		router:           router,
+		config:           config,
+		sessionStorage:   config.SessionStorage,
+		agentRegistry:    NewAgentRegistry(),
+		teamRegistry:     NewTeamRegistry(),
+		workflowRegistry: NewWorkflowRegistry(),
+		logger:           config.Logger,
+		summaryManager:   config.SummaryManager,
+		instantiatedAt:   time.Now().UTC(),
 	}
 
 	// 初始化知识库服务（如果配置了）
@@ -356,6 +369,19 @@ func (s *Server) GetTeamRegistry() *TeamRegistry {
 	return s.teamRegistry
 }
 
+// RegisterWorkflow registers a workflow with the server.
+func (s *Server) RegisterWorkflow(workflowID string, wf *workflow.Workflow) error {
+	if s.workflowRegistry == nil {
+		s.workflowRegistry = NewWorkflowRegistry()
+	}
+	return s.workflowRegistry.Register(workflowID, wf)
+}
+
+// GetWorkflowRegistry returns the workflow registry.
+func (s *Server) GetWorkflowRegistry() *WorkflowRegistry {
+	return s.workflowRegistry
+}
+
 // registerRoutes registers all API routes
 // registerRoutes 注册所有 API 路由
 func (s *Server) registerRoutes() {
@@ -400,6 +426,8 @@ func (s *Server) registerRoutes() {
 			agents.GET("", s.handleListAgents)
 			agents.POST("/:id/run", s.handleAgentRun)
 			agents.POST("/:id/run/stream", s.handleAgentRunStream) // P1: SSE 流式输出
+			agents.POST("/:id/runs/:run_id/continue", s.handleAgentContinue)
+			agents.POST("/:id/runs/:run_id/cancel", s.handleAgentCancel)
 		}
 
 		// Team endpoints
@@ -408,6 +436,13 @@ func (s *Server) registerRoutes() {
 			teams.GET("/:id/tools", s.handleTeamTools)
 		}
 
+		// Workflow endpoints
+		workflows := v1.Group("/workflows")
+		{
+			workflows.GET("/:id/runs/:run_id/approval", s.handleGetWorkflowApproval)
+			workflows.POST("/:id/runs/:run_id/approval", s.handleResolveWorkflowApproval)
+		}
+
 		// Knowledge endpoints
 		if s.knowledgeService != nil {
 			knowledge := v1.Group("/knowledge")
@@ -598,6 +633,16 @@ func initKnowledgeService(config *Config, logger *slog.Logger) (*KnowledgeServic
 		}
 
 		vdb = chromaDB
+	case "local":
+		localDB, err := localdb.New(localdb.Config{
+			CollectionName:    config.VectorDBConfig.CollectionName,
+			Path:              config.VectorDBConfig.Path,
+			EmbeddingFunction: embFunc,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create local vector db: %w", err)
+		}
+		vdb = localDB
 	default:
 		return nil, fmt.Errorf("unsupported vector db type: %s", config.VectorDBConfig.Type)
 	}
@@ -623,6 +668,7 @@ func initKnowledgeService(config *Config, logger *slog.Logger) (*KnowledgeServic
 		AllowedCollections:   opts.AllowedCollections,
 		AllowAllCollections:  opts.AllowAllCollections,
 		AllowedSourceSchemes: opts.AllowedSourceSchemes,
+		Reranker:             opts.Reranker,
 	}
 
 	return NewKnowledgeService(vdb, embFunc, svcConfig), nil
@@ -13,6 +13,8 @@ import (
 	"github.com/rexleimo/agno-go/pkg/agno/agent"
 	"github.com/rexleimo/agno-go/pkg/agno/models"
 	"github.com/rexleimo/agno-go/pkg/agno/session"
+	"github.com/rexleimo/agno-go/pkg/agno/tools/calculator"
+	"github.com/rexleimo/agno-go/pkg/agno/tools/toolkit"
 	"github.com/rexleimo/agno-go/pkg/agno/types"
 )
 
@@ -25,7 +27,34 @@ func (m *simpleModel) Invoke(ctx context.Context, req *models.InvokeRequest) (*t
 }
 
 func (m *simpleModel) InvokeStream(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
-	ch := make(chan types.ResponseChunk)
+	ch := make(chan types.ResponseChunk, 1)
+	ch <- types.ResponseChunk{Content: "OK"}
+	close(ch)
+	return ch, nil
+}
+
+// toolStreamModel streams a single calculator tool call, then a final answer.
+type toolStreamModel struct {
+	models.BaseModel
+	calls int
+}
+
+func (m *toolStreamModel) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+	return &types.ModelResponse{Content: "OK", Model: m.ID}, nil
+}
+
+func (m *toolStreamModel) InvokeStream(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
+	m.calls++
+	ch := make(chan types.ResponseChunk, 1)
+	if m.calls == 1 {
+		ch <- types.ResponseChunk{ToolCalls: []types.ToolCall{{
+			ID:       "call_1",
+			Type:     "function",
+			Function: types.ToolCallFunction{Name: "add", Arguments: `{"a": 2, "b": 3}`},
+		}}}
+	} else {
+		ch <- types.ResponseChunk{Content: "sum is 5"}
+	}
 	close(ch)
 	return ch, nil
 }
@@ -66,6 +95,19 @@ func TestNewServer_WithConfig(t *testing.T) {
 	}
 }
 
+func TestNewServer_LocalVectorDB(t *testing.T) {
+	server, err := NewServer(&Config{
+		VectorDBConfig:  &VectorDBConfig{Type: "local", CollectionName: "docs"},
+		EmbeddingConfig: &EmbeddingConfig{Provider: "openai", APIKey: "test-key"},
+	})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	if server.knowledgeService == nil {
+		t.Fatal("expected knowledge service backed by the local vector db")
+	}
+}
+
 func TestHealthEndpoint(t *testing.T) {
 	server, _ := NewServer(nil)
 
@@ -747,6 +789,18 @@ func TestAgentRun_MediaOnly(t *testing.T) {
 	if !ok || len(mediaList) != 1 {
 		t.Fatalf("expected media metadata, got %#v", resp.Metadata["media"])
 	}
+
+	var imageURL string
+	for _, msg := range agentInstance.Memory.GetMessages(agentInstance.UserID) {
+		for _, part := range msg.Parts {
+			if part.Type == types.ContentPartImage {
+				imageURL = part.URL
+			}
+		}
+	}
+	if imageURL != "https://example.com/image.png" {
+		t.Fatalf("expected the image to reach the model, got %q", imageURL)
+	}
 }
 
 func TestAgentRun_StreamEvents(t *testing.T) {
@@ -787,3 +841,40 @@ func TestAgentRun_StreamEvents(t *testing.T) {
 		t.Fatalf("expected complete event")
 	}
 }
+
+func TestAgentRun_StreamEmitsToolCallEvents(t *testing.T) {
+	server, _ := NewServer(nil)
+
+	agentInstance, err := agent.New(agent.Config{
+		Name:     "tool-streamer",
+		Model:    &toolStreamModel{BaseModel: models.BaseModel{ID: "mock-model", Provider: "mock"}},
+		Toolkits: []toolkit.Toolkit{calculator.New()},
+	})
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	if err := server.RegisterAgent("tool-streamer", agentInstance); err != nil {
+		t.Fatalf("failed to register agent: %v", err)
+	}
+
+	body, _ := json.Marshal(AgentRunRequest{Input: "add 2 and 3"})
+	req, _ := http.NewRequest("POST", "/api/v1/agents/tool-streamer/run/stream", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	payload := w.Body.String()
+	started := strings.Index(payload, `"tool_call_id":"call_1","status":"started"`)
+	completed := strings.Index(payload, `"tool_call_id":"call_1","status":"completed"`)
+	token := strings.Index(payload, "event: token")
+	if started < 0 || completed < 0 || token < 0 {
+		t.Fatalf("expected tool_call started/completed and token events, got %s", payload)
+	}
+	if !(started < completed && completed < token) {
+		t.Fatalf("expected tool events before content tokens, got %s", payload)
+	}
+	if !strings.Contains(payload, "event: tool_call") || !strings.Contains(payload, `"tool_name":"add"`) {
+		t.Fatalf("expected add tool_call event, got %s", payload)
+	}
+}
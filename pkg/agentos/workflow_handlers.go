@@ -0,0 +1,176 @@
+package agentos
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+	"github.com/rexleimo/agno-go/pkg/agno/workflow"
+)
+
+// WorkflowApprovalRequest is the reviewer decision for a run waiting at an approval node.
+type WorkflowApprovalRequest struct {
+	Action    workflow.ApprovalAction `json:"action"`
+	Input     string                  `json:"input,omitempty"`
+	Comment   string                  `json:"comment,omitempty"`
+	DecidedBy string                  `json:"decided_by,omitempty"`
+}
+
+// WorkflowApprovalResponse reports the state of a workflow run after an approval call.
+type WorkflowApprovalResponse struct {
+	WorkflowID      string                    `json:"workflow_id"`
+	RunID           string                    `json:"run_id"`
+	Status          workflow.RunStatus        `json:"status"`
+	Output          string                    `json:"output,omitempty"`
+	Error           string                    `json:"error,omitempty"`
+	PendingApproval *workflow.ApprovalRequest `json:"pending_approval,omitempty"`
+}
+
+// handleGetWorkflowApproval returns the approval request a workflow run is waiting on.
+// GET /api/v1/workflows/:id/runs/:run_id/approval
+func (s *Server) handleGetWorkflowApproval(c *gin.Context) {
+	workflowID := c.Param("id")
+	runID := c.Param("run_id")
+
+	wf, ok := s.lookupWorkflow(c, workflowID)
+	if !ok {
+		return
+	}
+
+	request, err := wf.PendingApproval(c.Request.Context(), runID)
+	if err != nil {
+		s.writeWorkflowError(c, err)
+		return
+	}
+	if request == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error: "no pending approval",
+			Code:  "APPROVAL_NOT_PENDING",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, WorkflowApprovalResponse{
+		WorkflowID:      workflowID,
+		RunID:           runID,
+		Status:          workflow.RunStatusPending,
+		PendingApproval: request,
+	})
+}
+
+// handleResolveWorkflowApproval approves, rejects or edits a pending approval and resumes the run.
+// POST /api/v1/workflows/:id/runs/:run_id/approval
+func (s *Server) handleResolveWorkflowApproval(c *gin.Context) {
+	workflowID := c.Param("id")
+	runID := c.Param("run_id")
+
+	var req WorkflowApprovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid request",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+	decision := workflow.ApprovalDecision{
+		Action:    req.Action,
+		Input:     req.Input,
+		Comment:   req.Comment,
+		DecidedBy: req.DecidedBy,
+	}
+	if err := decision.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid approval decision",
+			Message: err.Error(),
+			Code:    "INVALID_REQUEST",
+		})
+		return
+	}
+
+	wf, ok := s.lookupWorkflow(c, workflowID)
+	if !ok {
+		return
+	}
+
+	s.logger.Info("workflow approval received",
+		"workflow_id", workflowID,
+		"run_id", runID,
+		"action", req.Action)
+
+	result, err := wf.ResolveApproval(c.Request.Context(), runID, decision)
+	response := WorkflowApprovalResponse{WorkflowID: workflowID, RunID: runID}
+
+	var pending *workflow.PendingApprovalError
+	switch {
+	case err == nil:
+		response.Status = workflow.RunStatusCompleted
+		response.Output = result.Output
+		c.JSON(http.StatusOK, response)
+	case errors.As(err, &pending):
+		// The run reached the next approval node
+		response.Status = workflow.RunStatusPending
+		response.PendingApproval = pending.Request
+		c.JSON(http.StatusAccepted, response)
+	case errors.Is(err, workflow.ErrApprovalRejected):
+		response.Status = workflow.RunStatusFailed
+		response.Error = err.Error()
+		c.JSON(http.StatusOK, response)
+	default:
+		s.writeWorkflowError(c, err)
+	}
+}
+
+func (s *Server) lookupWorkflow(c *gin.Context, workflowID string) (*workflow.Workflow, bool) {
+	if s.workflowRegistry == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "workflow not found",
+			Message: "no workflows registered",
+			Code:    "WORKFLOW_NOT_FOUND",
+		})
+		return nil, false
+	}
+
+	wf, err := s.workflowRegistry.Get(workflowID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "workflow not found",
+			Message: err.Error(),
+			Code:    "WORKFLOW_NOT_FOUND",
+		})
+		return nil, false
+	}
+	return wf, true
+}
+
+func (s *Server) writeWorkflowError(c *gin.Context, err error) {
+	var agnoErr *types.AgnoError
+	switch {
+	case errors.Is(err, workflow.ErrRunNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "run not found",
+			Message: err.Error(),
+			Code:    "RUN_NOT_FOUND",
+		})
+	case errors.Is(err, workflow.ErrRunConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "run was resumed by another request",
+			Message: err.Error(),
+			Code:    "RUN_CONFLICT",
+		})
+	case errors.As(err, &agnoErr) && agnoErr.Code == types.ErrCodeInvalidInput:
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "run is not waiting for approval",
+			Message: err.Error(),
+			Code:    "APPROVAL_NOT_PENDING",
+		})
+	default:
+		s.logger.Error("workflow execution failed", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "workflow execution failed",
+			Message: err.Error(),
+			Code:    "EXECUTION_ERROR",
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package agentos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+	"github.com/rexleimo/agno-go/pkg/agno/workflow"
+)
+
+type workflowFuncNode struct {
+	id string
+	fn func(*workflow.ExecutionContext)
+}
+
+func (n *workflowFuncNode) Execute(_ context.Context, execCtx *workflow.ExecutionContext) (*workflow.ExecutionContext, error) {
+	n.fn(execCtx)
+	return execCtx, nil
+}
+
+func (n *workflowFuncNode) GetID() string              { return n.id }
+func (n *workflowFuncNode) GetType() workflow.NodeType { return workflow.NodeTypeStep }
+
+func newApprovalServer(t *testing.T) (*Server, *workflow.Workflow) {
+	t.Helper()
+
+	server, err := NewServer(nil)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	review, err := workflow.NewApproval(workflow.ApprovalConfig{ID: "review", Message: "Send the refund?"})
+	if err != nil {
+		t.Fatalf("failed to create approval: %v", err)
+	}
+	wf, err := workflow.New(workflow.Config{
+		ID: "refunds",
+		Steps: []workflow.Node{
+			&workflowFuncNode{id: "quote", fn: func(execCtx *workflow.ExecutionContext) { execCtx.Output = "refund $20" }},
+			review,
+			&workflowFuncNode{id: "send", fn: func(execCtx *workflow.ExecutionContext) { execCtx.Output = "sent " + execCtx.Output }},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create workflow: %v", err)
+	}
+	if err := server.RegisterWorkflow("refunds", wf); err != nil {
+		t.Fatalf("failed to register workflow: %v", err)
+	}
+	return server, wf
+}
+
+func startPendingRun(t *testing.T, wf *workflow.Workflow) string {
+	t.Helper()
+
+	_, err := wf.Run(context.Background(), "customer complaint", "")
+	var pending *workflow.PendingApprovalError
+	if !errors.As(err, &pending) {
+		t.Fatalf("expected pending approval, got %v", err)
+	}
+	return pending.RunID
+}
+
+func postApproval(server *Server, path string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandleWorkflowApproval_ApproveResumesRun(t *testing.T) {
+	server, wf := newApprovalServer(t)
+	runID := startPendingRun(t, wf)
+	path := "/api/v1/workflows/refunds/runs/" + runID + "/approval"
+
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var pending WorkflowApprovalResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &pending); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if pending.Status != workflow.RunStatusPending || pending.PendingApproval == nil ||
+		pending.PendingApproval.StepID != "review" || pending.PendingApproval.Output != "refund $20" {
+		t.Fatalf("unexpected pending response %+v", pending)
+	}
+
+	w = postApproval(server, path, WorkflowApprovalRequest{Action: workflow.ApprovalActionEdit, Input: "refund $15", DecidedBy: "lead"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp WorkflowApprovalResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Status != workflow.RunStatusCompleted || resp.Output != "sent refund $15" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	// The run is no longer waiting
+	w = postApproval(server, path, WorkflowApprovalRequest{Action: workflow.ApprovalActionApprove})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleWorkflowApproval_Reject(t *testing.T) {
+	server, wf := newApprovalServer(t)
+	runID := startPendingRun(t, wf)
+
+	w := postApproval(server, "/api/v1/workflows/refunds/runs/"+runID+"/approval",
+		WorkflowApprovalRequest{Action: workflow.ApprovalActionReject, Comment: "duplicate"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp WorkflowApprovalResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Status != workflow.RunStatusFailed || resp.Error == "" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestHandleWorkflowApproval_Errors(t *testing.T) {
+	server, wf := newApprovalServer(t)
+	runID := startPendingRun(t, wf)
+
+	cases := []struct {
+		name string
+		path string
+		body WorkflowApprovalRequest
+		want int
+	}{
+		{"unknown workflow", "/api/v1/workflows/missing/runs/" + runID + "/approval", WorkflowApprovalRequest{Action: workflow.ApprovalActionApprove}, http.StatusNotFound},
+		{"unknown run", "/api/v1/workflows/refunds/runs/missing/approval", WorkflowApprovalRequest{Action: workflow.ApprovalActionApprove}, http.StatusNotFound},
+		{"invalid action", "/api/v1/workflows/refunds/runs/" + runID + "/approval", WorkflowApprovalRequest{Action: "maybe"}, http.StatusBadRequest},
+		{"edit without input", "/api/v1/workflows/refunds/runs/" + runID + "/approval", WorkflowApprovalRequest{Action: workflow.ApprovalActionEdit}, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		if w := postApproval(server, c.path, c.body); w.Code != c.want {
+			t.Errorf("%s: expected %d, got %d: %s", c.name, c.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestWriteWorkflowError_RunConflict(t *testing.T) {
+	server, _ := newApprovalServer(t)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	server.writeWorkflowError(c, types.NewError(types.ErrCodeUnknown, "run was resumed by another caller", workflow.ErrRunConflict))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Code != "RUN_CONFLICT" {
+		t.Fatalf("unexpected error code %q", resp.Code)
+	}
+}
@@ -0,0 +1,83 @@
+package agentos
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rexleimo/agno-go/pkg/agno/workflow"
+)
+
+// WorkflowRegistry manages registered workflows.
+type WorkflowRegistry struct {
+	mu        sync.RWMutex
+	workflows map[string]*workflow.Workflow
+}
+
+// NewWorkflowRegistry creates a new workflow registry.
+func NewWorkflowRegistry() *WorkflowRegistry {
+	return &WorkflowRegistry{
+		workflows: make(map[string]*workflow.Workflow),
+	}
+}
+
+// Register registers a workflow with the given ID.
+func (r *WorkflowRegistry) Register(workflowID string, wf *workflow.Workflow) error {
+	if workflowID == "" {
+		return fmt.Errorf("workflow ID cannot be empty")
+	}
+	if wf == nil {
+		return fmt.Errorf("workflow cannot be nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.workflows[workflowID]; exists {
+		return fmt.Errorf("workflow with ID '%s' already registered", workflowID)
+	}
+
+	r.workflows[workflowID] = wf
+	return nil
+}
+
+// Get retrieves a workflow by ID.
+func (r *WorkflowRegistry) Get(workflowID string) (*workflow.Workflow, error) {
+	if workflowID == "" {
+		return nil, fmt.Errorf("workflow ID cannot be empty")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wf, exists := r.workflows[workflowID]
+	if !exists {
+		return nil, fmt.Errorf("workflow with ID '%s' not found", workflowID)
+	}
+	return wf, nil
+}
+
+// Exists checks if a workflow with the given ID is registered.
+func (r *WorkflowRegistry) Exists(workflowID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, exists := r.workflows[workflowID]
+	return exists
+}
+
+// List returns a copy of all registered workflows.
+func (r *WorkflowRegistry) List() map[string]*workflow.Workflow {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[string]*workflow.Workflow, len(r.workflows))
+	for id, wf := range r.workflows {
+		out[id] = wf
+	}
+	return out
+}
+
+// Clear removes all workflows from the registry.
+func (r *WorkflowRegistry) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workflows = make(map[string]*workflow.Workflow)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"strings"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/rexleimo/agno-go/pkg/agno/cache"
 	"github.com/rexleimo/agno-go/pkg/agno/hooks"
+	"github.com/rexleimo/agno-go/pkg/agno/knowledge"
 	"github.com/rexleimo/agno-go/pkg/agno/memory"
 	"github.com/rexleimo/agno-go/pkg/agno/models"
 	"github.com/rexleimo/agno-go/pkg/agno/reasoning"
@@ -22,7 +24,25 @@ import (
 	"github.com/rexleimo/agno-go/pkg/agno/types"
 )
 
-const defaultCacheTTL = 5 * time.Minute
+const (
+	defaultCacheTTL             = 5 * time.Minute
+	defaultMaxParallelToolCalls = 4
+
+	// knowledgeContextPrefix starts the system message carrying the passages
+	// retrieved in KnowledgeModeContext
+	knowledgeContextPrefix = "Use the following information from the knowledge base to answer the user's question:\n\n"
+)
+
+// KnowledgeMode selects how an agent uses its knowledge base.
+type KnowledgeMode string
+
+const (
+	// KnowledgeModeContext retrieves passages for the input before the run and
+	// adds them to the system context
+	KnowledgeModeContext KnowledgeMode = "context"
+	// KnowledgeModeTool lets the model search through the search_knowledge_base tool
+	KnowledgeModeTool KnowledgeMode = "tool"
+)
 
 // RunStatus represents the lifecycle status of a run.
 type RunStatus string
@@ -32,6 +52,9 @@ const (
 	RunStatusCompleted RunStatus = "completed"
 	RunStatusCancelled RunStatus = "cancelled"
 	RunStatusError     RunStatus = "error"
+	// RunStatusPaused means the run stopped before tool calls that require
+	// confirmation; resume it with ContinueRun
+	RunStatusPaused RunStatus = "paused"
 )
 
 // Agent represents an AI agent
@@ -41,6 +64,8 @@ type Agent struct {
 	Model        models.Model
 	Toolkits     []toolkit.Toolkit
 	Memory       memory.Memory
+	UserMemory   *memory.UserMemoryManager // Long-term user memories, nil disables / 长期用户记忆，nil 表示禁用
+	Knowledge    *knowledge.Base           // Knowledge base, nil disables / 知识库，nil 表示禁用
 	Instructions string
 	MaxLoops     int          // Maximum tool calling loops
 	UserID       string       // User ID for multi-tenant memory isolation / 多租户内存隔离的用户ID
@@ -51,6 +76,19 @@ type Agent struct {
 	cacheTTL     time.Duration
 	cacheEnabled bool
 
+	// Tool execution control / 工具执行控制
+	parallelToolCalls    bool          // Run independent tool calls concurrently / 并发执行独立的工具调用
+	maxParallelToolCalls int           // Concurrency limit for parallel tool calls / 并发工具调用上限
+	toolCallTimeout      time.Duration // Per-call timeout, zero disables / 单次工具调用超时，0 表示不限制
+
+	// Knowledge retrieval / 知识检索
+	knowledgeMode  KnowledgeMode // How Knowledge is used / 知识库使用方式
+	knowledgeLimit int           // Passages injected per run in context mode / context 模式下每次注入的段落数
+
+	// Structured output / 结构化输出
+	outputSchema        *outputSchema // Compiled Config.OutputSchema, nil when disabled / 编译后的输出 schema
+	outputSchemaRetries int           // Retries after an invalid structured reply / 结构化回复无效时的重试次数
+
 	// Storage control / 存储控制
 	storeToolMessages    bool // Whether to store tool messages in RunOutput / 是否在 RunOutput 中存储工具消息
 	storeHistoryMessages bool // Whether to store history messages in RunOutput / 是否在 RunOutput 中存储历史消息
@@ -59,6 +97,11 @@ type Agent struct {
 	// 临时 instructions 支持,用于工作流历史注入
 	tempInstructions string       // Temporary instructions (single execution only) / 临时指令（仅单次执行）
 	instructionsMu   sync.RWMutex // Protects instructions modification / 保护指令修改
+
+	// Runs paused for tool-call confirmation, keyed by run ID / 等待工具调用确认的运行，按运行 ID 索引
+	pausedRuns   map[string]*runState
+	pausedRunsMu sync.Mutex
+	pausedRunTTL time.Duration // How long a paused run can be continued / 暂停的运行可继续的时长
 }
 
 // Config contains agent configuration
@@ -78,6 +121,33 @@ type Config struct {
 	CacheProvider cache.Provider
 	CacheTTL      time.Duration
 
+	// ParallelToolCalls runs the tool calls of a single model response concurrently.
+	// Results are still written to Memory in the original tool-call order.
+	// ParallelToolCalls 并发执行同一模型响应中的工具调用，结果仍按原始顺序写入 Memory
+	ParallelToolCalls bool
+
+	// MaxParallelToolCalls limits how many tool calls run at once when
+	// ParallelToolCalls is enabled (default: 4).
+	// MaxParallelToolCalls 限制并发执行的工具调用数量（默认: 4）
+	MaxParallelToolCalls int
+
+	// ToolCallTimeout bounds each individual tool call. Zero means no timeout.
+	// ToolCallTimeout 限制每次工具调用的执行时间，0 表示不限制
+	ToolCallTimeout time.Duration
+
+	// OutputSchema requests structured output. It accepts a Go value whose type
+	// describes the output (e.g. MyResult{} or (*MyResult)(nil)), a reflect.Type,
+	// or a JSON schema as map[string]interface{}. The reply is validated and
+	// decoded into RunOutput.Structured.
+	// OutputSchema 启用结构化输出，可为 Go 类型的值、reflect.Type 或 JSON schema，
+	// 回复经校验后解码到 RunOutput.Structured
+	OutputSchema interface{}
+
+	// OutputSchemaRetries is how many times Run asks the model again after a
+	// reply that fails to parse or validate (default: 2, negative disables).
+	// OutputSchemaRetries 回复解析或校验失败时的重试次数（默认: 2，负数表示不重试）
+	OutputSchemaRetries int
+
 	// Storage control flags (nil means use default: true) / 存储控制标志 (nil 表示使用默认值: true)
 	// StoreToolMessages controls whether tool-related messages are included in RunOutput.
 	// When false, tool messages and tool-related fields are filtered from output.
@@ -90,6 +160,29 @@ type Config struct {
 	// StoreHistoryMessages 控制是否在 RunOutput 中包含历史消息(来自 Memory)
 	// 当为 false 时，仅包含当前 Run 生成的消息
 	StoreHistoryMessages *bool
+
+	// UserMemory maintains durable facts about the user across sessions.
+	// Relevant memories are added to the system context of every run and the
+	// manager extracts new ones from each completed run.
+	// UserMemory 跨会话维护用户的长期记忆：相关记忆注入每次运行的系统上下文，
+	// 并在运行完成后提取新的记忆
+	UserMemory *memory.UserMemoryManager
+
+	// Knowledge gives the agent a knowledge base. In KnowledgeModeContext (the
+	// default) the top KnowledgeLimit passages for the input are added to the
+	// system context; in KnowledgeModeTool the model gets a
+	// search_knowledge_base tool instead.
+	// Knowledge 为 agent 提供知识库：context 模式（默认）自动注入检索结果，
+	// tool 模式则提供 search_knowledge_base 工具
+	Knowledge      *knowledge.Base
+	KnowledgeMode  KnowledgeMode
+	KnowledgeLimit int // Passages injected per run (default: the Base's SearchLimit) / 每次注入的段落数
+
+	// PausedRunTTL is how long a run paused for tool-call confirmation waits
+	// for ContinueRun (default: 1h). Expired runs are cancelled on the next
+	// Run, RunStream or ContinueRun.
+	// PausedRunTTL 为等待工具调用确认的运行的有效期（默认: 1 小时），过期后自动取消
+	PausedRunTTL time.Duration
 }
 
 // New creates a new agent
@@ -135,6 +228,40 @@ func New(config Config) (*Agent, error) {
 		cacheTTL = defaultCacheTTL
 	}
 
+	pausedRunTTL := config.PausedRunTTL
+	if pausedRunTTL <= 0 {
+		pausedRunTTL = defaultPausedRunTTL
+	}
+
+	maxParallelToolCalls := config.MaxParallelToolCalls
+	if maxParallelToolCalls <= 0 {
+		maxParallelToolCalls = defaultMaxParallelToolCalls
+	}
+
+	knowledgeMode := config.KnowledgeMode
+	if knowledgeMode == "" {
+		knowledgeMode = KnowledgeModeContext
+	}
+	if knowledgeMode != KnowledgeModeContext && knowledgeMode != KnowledgeModeTool {
+		return nil, types.NewInvalidConfigError(fmt.Sprintf("unknown knowledge mode %q", knowledgeMode), nil)
+	}
+	toolkits := config.Toolkits
+	if config.Knowledge != nil && knowledgeMode == KnowledgeModeTool {
+		toolkits = append(append([]toolkit.Toolkit(nil), toolkits...), knowledge.NewToolkit(config.Knowledge))
+	}
+
+	outSchema, err := newOutputSchema(config.OutputSchema)
+	if err != nil {
+		return nil, types.NewInvalidConfigError("invalid output schema", err)
+	}
+
+	outputSchemaRetries := config.OutputSchemaRetries
+	if outputSchemaRetries == 0 {
+		outputSchemaRetries = defaultOutputSchemaRetries
+	} else if outputSchemaRetries < 0 {
+		outputSchemaRetries = 0
+	}
+
 	// Helper function to get bool value or default / 辅助函数获取布尔值或默认值
 	boolOrDefault := func(ptr *bool, defaultVal bool) bool {
 		if ptr == nil {
@@ -147,8 +274,10 @@ func New(config Config) (*Agent, error) {
 		ID:           config.ID,
 		Name:         config.Name,
 		Model:        config.Model,
-		Toolkits:     config.Toolkits,
+		Toolkits:     toolkits,
 		Memory:       config.Memory,
+		UserMemory:   config.UserMemory,
+		Knowledge:    config.Knowledge,
 		Instructions: config.Instructions,
 		MaxLoops:     config.MaxLoops,
 		UserID:       config.UserID,
@@ -159,6 +288,18 @@ func New(config Config) (*Agent, error) {
 		cacheTTL:     cacheTTL,
 		cacheEnabled: config.EnableCache && cacheProvider != nil,
 
+		parallelToolCalls:    config.ParallelToolCalls,
+		maxParallelToolCalls: maxParallelToolCalls,
+		toolCallTimeout:      config.ToolCallTimeout,
+
+		knowledgeMode:  knowledgeMode,
+		knowledgeLimit: config.KnowledgeLimit,
+
+		outputSchema:        outSchema,
+		outputSchemaRetries: outputSchemaRetries,
+
+		pausedRunTTL: pausedRunTTL,
+
 		// Storage control (default to true for backward compatibility) / 存储控制 (默认为 true 以保持向后兼容)
 		storeToolMessages:    boolOrDefault(config.StoreToolMessages, true),
 		storeHistoryMessages: boolOrDefault(config.StoreHistoryMessages, true),
@@ -184,6 +325,17 @@ type RunOutput struct {
 	Messages           []*types.Message       `json:"messages"`
 	Metadata           map[string]interface{} `json:"metadata,omitempty"`
 	Events             run.Events             `json:"events,omitempty"`
+
+	// Structured holds the decoded reply when Config.OutputSchema is set: a
+	// pointer to a new value of the schema's Go type, or the decoded JSON
+	// value for raw JSON schemas.
+	// Structured 在设置 OutputSchema 时保存解码后的结构化结果
+	Structured interface{} `json:"structured,omitempty"`
+
+	// PendingToolCalls lists the tool calls awaiting confirmation when Status
+	// is RunStatusPaused. Pass a decision for each to ContinueRun.
+	// PendingToolCalls 在运行暂停时列出等待确认的工具调用
+	PendingToolCalls []types.ToolCall `json:"pending_tool_calls,omitempty"`
 }
 
 // RunStreamDone represents the terminal result of a streaming run.
@@ -211,11 +363,13 @@ func singleDoneChannel(output *RunOutput, err error) <-chan RunStreamDone {
 	return ch
 }
 
-// Run executes the agent with the given input
-func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
+// Run executes the agent with the given input. Optional content parts, such
+// as images, audio or files, are attached to the user message for
+// multimodal models; input may be empty when parts are given.
+func (a *Agent) Run(ctx context.Context, input string, parts ...types.ContentPart) (*RunOutput, error) {
 	defer a.ClearTempInstructions()
 
-	if input == "" {
+	if input == "" && len(parts) == 0 {
 		return nil, types.NewInvalidInputError("input cannot be empty", nil)
 	}
 
@@ -224,6 +378,9 @@ func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
 	if runCtx != nil && runCtx.UserID == "" && a.UserID != "" {
 		runCtx.UserID = a.UserID
 	}
+	if err := a.checkPausedRuns(runCtx); err != nil {
+		return nil, err
+	}
 	runID := runCtx.RunID
 
 	currentInstructions := a.GetInstructions()
@@ -243,8 +400,10 @@ func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
 		}
 	}
 
-	userMsg := types.NewUserMessage(input)
-	a.Memory.Add(userMsg, a.UserID)
+	userMsg := types.NewUserMessageWithParts(input, parts...)
+	memory.AddWithContext(ctx, a.Memory, userMsg, a.UserID)
+	userMemoryMsg := a.loadUserMemories(ctx, input)
+	knowledgeMsg := a.searchKnowledge(ctx, input)
 
 	output := &RunOutput{
 		RunID:     runID,
@@ -253,24 +412,66 @@ func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
 		Metadata:  map[string]interface{}{},
 	}
 
-	var finalResponse *types.ModelResponse
-	loopCount := 0
-	cacheHit := false
+	return a.runLoop(ctx, &runState{
+		input:               input,
+		instructions:        currentInstructions,
+		userMsg:             userMsg,
+		userMemoryMsg:       userMemoryMsg,
+		knowledgeMsg:        knowledgeMsg,
+		runCtx:              runCtx,
+		output:              output,
+		initialMessageCount: initialMessageCount,
+	})
+}
+
+// runState carries a non-streaming run between model turns, so a run paused
+// for tool-call confirmation can continue where it stopped.
+type runState struct {
+	input               string
+	instructions        string
+	userMsg             *types.Message
+	userMemoryMsg       *types.Message
+	knowledgeMsg        *types.Message
+	runCtx              *run.RunContext
+	output              *RunOutput
+	initialMessageCount int
+
+	loopCount     int
+	cacheHit      bool
+	schemaRetries int
+
+	// toolCalls is the batch awaiting confirmation while the run is paused
+	toolCalls []types.ToolCall
+	pausedAt  time.Time
+}
+
+// runLoop calls the model and executes tool calls until a final reply, a
+// pause for confirmation or an error.
+func (a *Agent) runLoop(ctx context.Context, st *runState) (*RunOutput, error) {
+	output := st.output
+	runID := output.RunID
+
+	var (
+		finalResponse *types.ModelResponse
+		structured    interface{}
+	)
 
-	for loopCount < a.MaxLoops {
+	for st.loopCount < a.MaxLoops {
 		if ctxErr := ctx.Err(); ctxErr != nil {
-			cancelled := a.markRunCancelled(output, loopCount, cacheHit, ctxErr, initialMessageCount)
+			cancelled := a.markRunCancelled(output, st.loopCount, st.cacheHit, ctxErr, st.initialMessageCount)
 			return cancelled, types.NewCancellationError("agent run cancelled", ctxErr)
 		}
 
-		loopCount++
+		st.loopCount++
 
-		messages := a.Memory.GetMessages(a.UserID)
-		if currentInstructions != a.Instructions && currentInstructions != "" {
-			messages = a.updateSystemMessage(messages, currentInstructions)
+		messages := a.requestMessages()
+		if st.instructions != a.Instructions && st.instructions != "" {
+			messages = a.updateSystemMessage(messages, st.instructions)
 		}
+		messages = insertAfterSystemMessages(messages, st.userMemoryMsg)
+		messages = insertAfterSystemMessages(messages, st.knowledgeMsg)
 
-		req := &models.InvokeRequest{Messages: messages}
+		req := &models.InvokeRequest{Messages: messages, ResponseFormat: a.outputSchema.responseFormat()}
 		if len(a.Toolkits) > 0 {
 			req.Tools = toolkit.ToModelToolDefinitions(a.Toolkits)
 		}
@@ -291,7 +492,7 @@ func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
 			} else if ok {
 				resp = cachedResp
 				fromCache = true
-				cacheHit = true
+				st.cacheHit = true
 			}
 		}
 
@@ -299,7 +500,7 @@ func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
 			resp, invokeErr = a.Model.Invoke(ctx, req)
 			if invokeErr != nil {
 				if errors.Is(invokeErr, context.Canceled) || errors.Is(invokeErr, context.DeadlineExceeded) || ctx.Err() != nil {
-					cancelled := a.markRunCancelled(output, loopCount, cacheHit, invokeErr, initialMessageCount)
+					cancelled := a.markRunCancelled(output, st.loopCount, st.cacheHit, invokeErr, st.initialMessageCount)
 					return cancelled, types.NewCancellationError("agent run cancelled", invokeErr)
 				}
 				a.logger.Error("model invocation failed", "error", invokeErr)
@@ -312,6 +513,7 @@ func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
 			}
 		}
 
+		a.outputSchema.takeOutputToolCall(resp)
 		reasoningContent := a.extractReasoning(ctx, resp)
 		assistantMsg := &types.Message{
 			Role:             types.RoleAssistant,
@@ -319,9 +521,19 @@ func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
 			ToolCalls:        resp.ToolCalls,
 			ReasoningContent: reasoningContent,
 		}
-		a.Memory.Add(assistantMsg, a.UserID)
+		memory.AddWithContext(ctx, a.Memory, assistantMsg, a.UserID)
 
 		if !resp.HasToolCalls() {
+			if a.outputSchema != nil {
+				parsed, retry, err := a.acceptStructured(resp.Content, &st.schemaRetries)
+				if err != nil {
+					return nil, err
+				}
+				if retry {
+					continue
+				}
+				structured = parsed
+			}
 			if a.cacheEnabled && !fromCache {
 				a.tryCacheSet(ctx, cacheKey, resp)
 			}
@@ -329,10 +541,14 @@ func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
 			break
 		}
 
+		if a.needsConfirmation(assistantMsg.ToolCalls) {
+			return a.pauseRun(st, assistantMsg.ToolCalls), nil
+		}
+
 		a.logger.Info("executing tool calls", "count", len(resp.ToolCalls))
-		if err := a.executeToolCalls(ctx, resp.ToolCalls); err != nil {
+		if err := a.executeToolCalls(ctx, resp.ToolCalls, nil, nil); err != nil {
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
-				cancelled := a.markRunCancelled(output, loopCount, cacheHit, err, initialMessageCount)
+				cancelled := a.markRunCancelled(output, st.loopCount, st.cacheHit, err, st.initialMessageCount)
 				return cancelled, types.NewCancellationError("agent run cancelled", err)
 			}
 			a.logger.Error("tool execution failed", "error", err)
@@ -341,7 +557,7 @@ func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
 	}
 
 	if finalResponse == nil {
-		if loopCount >= a.MaxLoops {
+		if st.loopCount >= a.MaxLoops {
 			a.logger.Warn("max loops reached", "max_loops", a.MaxLoops)
 			return nil, types.NewError(types.ErrCodeUnknown, "max tool calling loops reached", nil)
 		}
@@ -350,7 +566,7 @@ func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
 
 	if len(a.PostHooks) > 0 {
 		a.logger.Debug("executing post-hooks", "count", len(a.PostHooks))
-		hookInput := hooks.NewHookInput(input).
+		hookInput := hooks.NewHookInput(st.input).
 			WithOutput(finalResponse.Content).
 			WithAgentID(a.ID).
 			WithMessages([]interface{}{})
@@ -361,16 +577,23 @@ func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
 		}
 	}
 
+	a.extractUserMemories(ctx, st.userMsg, finalResponse.Content)
+
 	a.logger.Info("agent run completed", "agent_id", a.ID)
 
 	output.Status = RunStatusCompleted
 	output.CompletedAt = time.Now().UTC()
 	output.Content = finalResponse.Content
+	output.Structured = structured
+	output.PendingToolCalls = nil
 	output.Messages = a.Memory.GetMessages(a.UserID)
-	output.Metadata["loops"] = loopCount
+	output.Metadata["loops"] = st.loopCount
 	output.Metadata["usage"] = finalResponse.Usage
-	output.Metadata["cache_hit"] = cacheHit
-	addRunContextMetadata(output, runCtx)
+	output.Metadata["cache_hit"] = st.cacheHit
+	if a.outputSchema != nil {
+		output.Metadata["output_schema_retries"] = st.schemaRetries
+	}
+	addRunContextMetadata(output, st.runCtx)
 
 	sequence := len(output.Events)
 	if finalResponse.Content != "" {
@@ -379,7 +602,7 @@ func (a *Agent) Run(ctx context.Context, input string) (*RunOutput, error) {
 	}
 	output.appendEvent(run.NewRunCompletedEvent(runID, a.ID, "", string(output.Status), finalResponse.Content))
 
-	a.scrubRunOutputWithContext(output, initialMessageCount)
+	a.scrubRunOutputWithContext(output, st.initialMessageCount)
 
 	return output, nil
 }
@@ -425,6 +648,13 @@ func (a *Agent) buildCacheKey(req *models.InvokeRequest) string {
 		builder.WriteString(string(msg.Role))
 		builder.WriteString(":")
 		builder.WriteString(msg.Content)
+		for _, part := range msg.Parts {
+			builder.WriteString("#")
+			builder.WriteString(string(part.Type))
+			builder.WriteString(":")
+			builder.WriteString(part.Text)
+			builder.WriteString(part.DataURL())
+		}
 		if len(msg.ToolCalls) > 0 {
 			builder.WriteString("#toolcalls")
 		}
@@ -438,6 +668,13 @@ func (a *Agent) buildCacheKey(req *models.InvokeRequest) string {
 		}
 	}
 
+	if req.ResponseFormat != nil {
+		builder.WriteString("|format:")
+		builder.WriteString(string(req.ResponseFormat.Type))
+		builder.WriteString(":")
+		builder.WriteString(req.ResponseFormat.SchemaName())
+	}
+
 	sum := sha256.Sum256([]byte(builder.String()))
 	return hex.EncodeToString(sum[:])
 }
@@ -450,18 +687,23 @@ func (output *RunOutput) appendEvent(evt run.BaseRunOutputEvent) {
 }
 
 // RunStream executes the agent using the model's streaming API and returns
-// a pair of channels: one for incremental content events and one that carries
-// the final RunOutput once aggregation completes.
+// a pair of channels: one for incremental run events and one that carries
+// the final RunOutput once the run completes.
 //
-// The initial behaviour focuses on single-pass streaming responses:
-// - Pre-hooks, memory, run-context and post-hooks are honoured.
-// - Model.InvokeStream is used to stream content chunks.
-// - Tool calls present in streaming chunks are aggregated but not executed.
-// - Cache is bypassed for streaming runs.
-func (a *Agent) RunStream(ctx context.Context, input string) (*RunStreamResult, error) {
+// RunStream follows the same tool-calling loop as Run:
+//   - Pre-hooks, memory, run-context and post-hooks are honoured.
+//   - Model.InvokeStream is used for every model pass, bounded by MaxLoops.
+//   - Tool calls aggregated from a pass are executed before the next pass, and
+//     ToolCallStartedEvent / ToolCallCompletedEvent values are emitted on
+//     Events between the content deltas.
+//   - Cache is bypassed for streaming runs.
+//   - With an OutputSchema the final reply is parsed into RunOutput.Structured
+//     and invalid replies are retried as in Run; the content of a rejected
+//     reply has already been streamed and cannot be retracted.
+func (a *Agent) RunStream(ctx context.Context, input string, parts ...types.ContentPart) (*RunStreamResult, error) {
 	defer a.ClearTempInstructions()
 
-	if strings.TrimSpace(input) == "" {
+	if strings.TrimSpace(input) == "" && len(parts) == 0 {
 		return nil, types.NewInvalidInputError("input cannot be empty", nil)
 	}
 
@@ -469,6 +711,9 @@ func (a *Agent) RunStream(ctx context.Context, input string) (*RunStreamResult,
 	if runCtx != nil && runCtx.UserID == "" && a.UserID != "" {
 		runCtx.UserID = a.UserID
 	}
+	if err := a.checkPausedRuns(runCtx); err != nil {
+		return nil, err
+	}
 	runID := runCtx.RunID
 
 	currentInstructions := a.GetInstructions()
@@ -488,8 +733,10 @@ func (a *Agent) RunStream(ctx context.Context, input string) (*RunStreamResult,
 		}
 	}
 
-	userMsg := types.NewUserMessage(input)
-	a.Memory.Add(userMsg, a.UserID)
+	userMsg := types.NewUserMessageWithParts(input, parts...)
+	memory.AddWithContext(ctx, a.Memory, userMsg, a.UserID)
+	userMemoryMsg := a.loadUserMemories(ctx, input)
+	knowledgeMsg := a.searchKnowledge(ctx, input)
 
 	output := &RunOutput{
 		RunID:     runID,
@@ -498,21 +745,27 @@ func (a *Agent) RunStream(ctx context.Context, input string) (*RunStreamResult,
 		Metadata:  map[string]interface{}{},
 	}
 
-	// Prepare messages and request for streaming invocation (single-pass).
-	messages := a.Memory.GetMessages(a.UserID)
-	if currentInstructions != a.Instructions && currentInstructions != "" {
-		messages = a.updateSystemMessage(messages, currentInstructions)
-	}
+	buildRequest := func() *models.InvokeRequest {
+		messages := a.requestMessages()
+		if currentInstructions != a.Instructions && currentInstructions != "" {
+			messages = a.updateSystemMessage(messages, currentInstructions)
+		}
+		messages = insertAfterSystemMessages(messages, userMemoryMsg)
+		messages = insertAfterSystemMessages(messages, knowledgeMsg)
 
-	req := &models.InvokeRequest{Messages: messages}
-	if len(a.Toolkits) > 0 {
-		req.Tools = toolkit.ToModelToolDefinitions(a.Toolkits)
+		req := &models.InvokeRequest{Messages: messages, Stream: true, ResponseFormat: a.outputSchema.responseFormat()}
+		if len(a.Toolkits) > 0 {
+			req.Tools = toolkit.ToModelToolDefinitions(a.Toolkits)
+		}
+		attachRunContextToRequest(ctx, req)
+		return req
 	}
-	attachRunContextToRequest(ctx, req)
 
-	stream, err := a.Model.InvokeStream(ctx, req)
+	// The first pass is started synchronously so invocation errors are
+	// reported to the caller directly rather than through Done.
+	stream, err := a.Model.InvokeStream(ctx, buildRequest())
 	if err != nil {
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+		if isCancellation(ctx, err) {
 			cancelled := a.markRunCancelled(output, 0, false, err, initialMessageCount)
 			return &RunStreamResult{
 				Events: nil,
@@ -528,35 +781,28 @@ func (a *Agent) RunStream(ctx context.Context, input string) (*RunStreamResult,
 
 	go func() {
 		defer close(eventsCh)
-		aggregatorCh := make(chan types.ResponseChunk)
-		doneAgg := make(chan struct{})
 
-		var (
-			resp   *types.ModelResponse
-			aggErr error
-		)
+		sequence := 0
+		loopCount := 0
 
-		go func() {
-			defer close(doneAgg)
-			// Aggregate the same chunks we stream out so we can build
-			// a final ModelResponse bound to this run.
-			resp, aggErr = AggregateResponseStream(ctx, aggregatorCh)
-		}()
-
-		aggregatorClosed := false
-		closeAggregator := func() {
-			if !aggregatorClosed {
-				close(aggregatorCh)
-				aggregatorClosed = true
+		emit := func(evt run.BaseRunOutputEvent) error {
+			output.appendEvent(evt)
+			select {
+			case eventsCh <- evt:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
 			}
 		}
 
-		sequence := 0
+		emitContent := func(content string) error {
+			evt := run.NewRunContentEvent(runID, a.ID, string(types.RoleAssistant), content, sequence)
+			sequence++
+			return emit(evt)
+		}
 
 		finishCancelled := func(reason error) {
-			closeAggregator()
-			<-doneAgg
-			cancelled := a.markRunCancelled(output, 0, false, reason, initialMessageCount)
+			cancelled := a.markRunCancelled(output, loopCount, false, reason, initialMessageCount)
 			doneCh <- RunStreamDone{
 				Output: cancelled,
 				Err:    types.NewCancellationError("agent run cancelled", reason),
@@ -564,124 +810,167 @@ func (a *Agent) RunStream(ctx context.Context, input string) (*RunStreamResult,
 		}
 
 		finishError := func(err error) {
-			closeAggregator()
-			<-doneAgg
 			doneCh <- RunStreamDone{
 				Output: nil,
 				Err:    err,
 			}
 		}
 
-		for {
-			select {
-			case <-ctx.Done():
-				finishCancelled(ctx.Err())
-				return
-
-			case chunk, ok := <-stream:
-				if !ok {
-					// Streaming complete; finalise aggregation.
-					closeAggregator()
-					<-doneAgg
-
-					if aggErr != nil {
-						if errors.Is(aggErr, context.Canceled) || errors.Is(aggErr, context.DeadlineExceeded) {
-							finishCancelled(aggErr)
-						} else {
-							finishError(aggErr)
-						}
-						return
-					}
-
-					if resp == nil {
-						resp = &types.ModelResponse{}
-					}
-
-					// Attach reasoning (if any) and store assistant message.
-					reasoningContent := a.extractReasoning(ctx, resp)
-					assistantMsg := &types.Message{
-						Role:             types.RoleAssistant,
-						Content:          resp.Content,
-						ToolCalls:        resp.ToolCalls,
-						ReasoningContent: reasoningContent,
-					}
-					a.Memory.Add(assistantMsg, a.UserID)
-
-					if len(a.PostHooks) > 0 {
-						a.logger.Debug("executing post-hooks (stream)", "count", len(a.PostHooks))
-						hookInput := hooks.NewHookInput(input).
-							WithOutput(resp.Content).
-							WithAgentID(a.ID).
-							WithMessages([]interface{}{})
-
-						if err := hooks.ExecuteHooks(ctx, a.PostHooks, hookInput); err != nil {
-							a.logger.Error("post-hook failed (stream)", "error", err)
-							doneCh <- RunStreamDone{
-								Output: nil,
-								Err:    types.NewOutputCheckError("post-hook validation failed", err),
-							}
-							return
-						}
-					}
-
-					a.logger.Info("agent run (stream) completed", "agent_id", a.ID)
+		observer := &toolCallObserver{
+			started: func(tc types.ToolCall) error {
+				return emit(run.NewToolCallStartedEvent(runID, a.ID, tc.ID, tc.Function.Name, tc.Function.Arguments))
+			},
+			completed: func(tc types.ToolCall, result string, toolErr error) error {
+				errMsg := ""
+				if toolErr != nil {
+					errMsg = toolErr.Error()
+				}
+				return emit(run.NewToolCallCompletedEvent(runID, a.ID, tc.ID, tc.Function.Name, result, errMsg))
+			},
+		}
 
-					output.Status = RunStatusCompleted
-					output.CompletedAt = time.Now().UTC()
-					output.Content = resp.Content
-					output.Messages = a.Memory.GetMessages(a.UserID)
-					output.Metadata["loops"] = 1
-					output.Metadata["usage"] = resp.Usage
-					output.Metadata["cache_hit"] = false
-					addRunContextMetadata(output, runCtx)
+		var (
+			finalResponse *types.ModelResponse
+			structured    interface{}
+			schemaRetries int
+		)
 
-					// Append terminal completion event (incremental content events
-					// were recorded as chunks arrived).
-					completed := run.NewRunCompletedEvent(runID, a.ID, "", string(output.Status), output.Content)
-					output.appendEvent(completed)
+		for loopCount < a.MaxLoops {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				finishCancelled(ctxErr)
+				return
+			}
 
-					a.scrubRunOutputWithContext(output, initialMessageCount)
+			loopCount++
 
-					doneCh <- RunStreamDone{
-						Output: output,
-						Err:    nil,
+			if stream == nil {
+				stream, err = a.Model.InvokeStream(ctx, buildRequest())
+				if err != nil {
+					if isCancellation(ctx, err) {
+						finishCancelled(err)
+						return
 					}
+					a.logger.Error("model streaming invocation failed", "error", err)
+					finishError(types.NewAPIError("model streaming invocation failed", err))
 					return
 				}
+			}
+
+			resp, passErr := consumeModelStream(ctx, stream, emitContent)
+			stream = nil
+			if passErr != nil {
+				if isCancellation(ctx, passErr) {
+					finishCancelled(passErr)
+				} else {
+					finishError(passErr)
+				}
+				return
+			}
 
-				// Forward chunk to aggregator so we can reconstruct a final response.
-				if !aggregatorClosed {
-					select {
-					case aggregatorCh <- chunk:
-					case <-ctx.Done():
-						finishCancelled(ctx.Err())
+			// Attach reasoning (if any) and store assistant message.
+			a.outputSchema.takeOutputToolCall(resp)
+			reasoningContent := a.extractReasoning(ctx, resp)
+			assistantMsg := &types.Message{
+				Role:             types.RoleAssistant,
+				Content:          resp.Content,
+				ToolCalls:        resp.ToolCalls,
+				ReasoningContent: reasoningContent,
+			}
+			memory.AddWithContext(ctx, a.Memory, assistantMsg, a.UserID)
+
+			if !resp.HasToolCalls() {
+				if a.outputSchema != nil {
+					parsed, retry, err := a.acceptStructured(resp.Content, &schemaRetries)
+					if err != nil {
+						finishError(err)
 						return
 					}
+					if retry {
+						continue
+					}
+					structured = parsed
 				}
+				finalResponse = resp
+				break
+			}
 
-				if chunk.Error != nil {
-					if errors.Is(chunk.Error, context.Canceled) || errors.Is(chunk.Error, context.DeadlineExceeded) || ctx.Err() != nil {
-						finishCancelled(chunk.Error)
-					} else {
-						finishError(chunk.Error)
-					}
+			if a.needsConfirmation(assistantMsg.ToolCalls) {
+				// The paused run continues without streaming through ContinueRun
+				paused := a.pauseRun(&runState{
+					input:               input,
+					instructions:        currentInstructions,
+					userMsg:             userMsg,
+					userMemoryMsg:       userMemoryMsg,
+					knowledgeMsg:        knowledgeMsg,
+					runCtx:              runCtx,
+					output:              output,
+					initialMessageCount: initialMessageCount,
+					loopCount:           loopCount,
+				}, assistantMsg.ToolCalls)
+				doneCh <- RunStreamDone{Output: paused}
+				return
+			}
+
+			a.logger.Info("executing tool calls (stream)", "count", len(resp.ToolCalls))
+			if err := a.executeToolCalls(ctx, resp.ToolCalls, observer, nil); err != nil {
+				if isCancellation(ctx, err) {
+					finishCancelled(err)
 					return
 				}
+				a.logger.Error("tool execution failed (stream)", "error", err)
+				finishError(types.NewToolExecutionError("tool execution failed", err))
+				return
+			}
+		}
 
-				if chunk.Content != "" {
-					evt := run.NewRunContentEvent(runID, a.ID, string(types.RoleAssistant), chunk.Content, sequence)
-					sequence++
-					output.appendEvent(evt)
+		if finalResponse == nil {
+			a.logger.Warn("max loops reached (stream)", "max_loops", a.MaxLoops)
+			finishError(types.NewError(types.ErrCodeUnknown, "max tool calling loops reached", nil))
+			return
+		}
 
-					select {
-					case eventsCh <- evt:
-					case <-ctx.Done():
-						finishCancelled(ctx.Err())
-						return
-					}
-				}
+		if len(a.PostHooks) > 0 {
+			a.logger.Debug("executing post-hooks (stream)", "count", len(a.PostHooks))
+			hookInput := hooks.NewHookInput(input).
+				WithOutput(finalResponse.Content).
+				WithAgentID(a.ID).
+				WithMessages([]interface{}{})
+
+			if err := hooks.ExecuteHooks(ctx, a.PostHooks, hookInput); err != nil {
+				a.logger.Error("post-hook failed (stream)", "error", err)
+				finishError(types.NewOutputCheckError("post-hook validation failed", err))
+				return
 			}
 		}
+
+		a.extractUserMemories(ctx, userMsg, finalResponse.Content)
+
+		a.logger.Info("agent run (stream) completed", "agent_id", a.ID)
+
+		output.Status = RunStatusCompleted
+		output.CompletedAt = time.Now().UTC()
+		output.Content = finalResponse.Content
+		output.Structured = structured
+		output.Messages = a.Memory.GetMessages(a.UserID)
+		output.Metadata["loops"] = loopCount
+		output.Metadata["usage"] = finalResponse.Usage
+		output.Metadata["cache_hit"] = false
+		if a.outputSchema != nil {
+			output.Metadata["output_schema_retries"] = schemaRetries
+		}
+		addRunContextMetadata(output, runCtx)
+
+		// Append terminal completion event (incremental content and tool
+		// events were recorded as they were emitted).
+		completed := run.NewRunCompletedEvent(runID, a.ID, "", string(output.Status), output.Content)
+		output.appendEvent(completed)
+
+		a.scrubRunOutputWithContext(output, initialMessageCount)
+
+		doneCh <- RunStreamDone{
+			Output: output,
+			Err:    nil,
+		}
 	}()
 
 	return &RunStreamResult{
@@ -690,6 +979,52 @@ func (a *Agent) RunStream(ctx context.Context, input string) (*RunStreamResult,
 	}, nil
 }
 
+// consumeModelStream drains a single streaming model pass. Content deltas are
+// forwarded to emit as they arrive while chunks are aggregated into the
+// ModelResponse for the pass, in the same way as AggregateResponseStream.
+func consumeModelStream(ctx context.Context, stream <-chan types.ResponseChunk, emit func(content string) error) (*types.ModelResponse, error) {
+	resp := &types.ModelResponse{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case chunk, ok := <-stream:
+			if !ok {
+				return resp, nil
+			}
+			if chunk.Error != nil {
+				// Some providers report the end of the stream as io.EOF.
+				if errors.Is(chunk.Error, io.EOF) {
+					return resp, nil
+				}
+				return nil, chunk.Error
+			}
+
+			for _, tc := range chunk.ToolCalls {
+				resp.ToolCalls = mergeToolCallDelta(resp.ToolCalls, tc)
+			}
+			for k, v := range chunk.Metadata {
+				if resp.Metadata.Extra == nil {
+					resp.Metadata.Extra = make(map[string]interface{})
+				}
+				resp.Metadata.Extra[k] = v
+			}
+			if chunk.Content != "" {
+				resp.Content += chunk.Content
+				if err := emit(chunk.Content); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+}
+
+// isCancellation reports whether err stems from context cancellation.
+func isCancellation(ctx context.Context, err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil
+}
+
 func ensureRunContext(ctx context.Context) (context.Context, *run.RunContext) {
 	if ctx == nil {
 		ctx = context.Background()
@@ -766,65 +1101,209 @@ func attachRunContextToRequest(ctx context.Context, req *models.InvokeRequest) {
 	req.Extra["run_context"] = meta
 }
 
-// executeToolCalls executes all tool calls and adds results to memory
-func (a *Agent) executeToolCalls(ctx context.Context, toolCalls []types.ToolCall) error {
+// toolCallObserver receives notifications around each tool invocation.
+// Either callback may be nil. Returning an error aborts the remaining calls.
+type toolCallObserver struct {
+	started   func(tc types.ToolCall) error
+	completed func(tc types.ToolCall, result string, toolErr error) error
+}
+
+// executeToolCalls executes all tool calls and adds results to memory.
+// When parallel tool calls are enabled the calls run concurrently, but their
+// results are always added to memory in the original tool-call order.
+// Calls listed in rejected are not executed; the rejection is reported to the
+// model as their result.
+func (a *Agent) executeToolCalls(ctx context.Context, toolCalls []types.ToolCall, observer *toolCallObserver, rejected map[string]string) error {
+	if a.parallelToolCalls && len(toolCalls) > 1 {
+		return a.executeToolCallsParallel(ctx, toolCalls, observer, rejected)
+	}
+
 	for _, tc := range toolCalls {
-		// Find the toolkit that has this function
-		var targetToolkit toolkit.Toolkit
-		for _, tk := range a.Toolkits {
-			if _, exists := tk.Functions()[tc.Function.Name]; exists {
-				targetToolkit = tk
-				break
+		if observer != nil && observer.started != nil {
+			if err := observer.started(tc); err != nil {
+				return err
 			}
 		}
 
-		if targetToolkit == nil {
-			errMsg := fmt.Sprintf("function %s not found in any toolkit", tc.Function.Name)
-			a.logger.Warn("tool not found", "function", tc.Function.Name)
-			a.Memory.Add(types.NewToolMessage(tc.ID, errMsg), a.UserID)
-			continue
+		result, toolErr := a.runToolCall(ctx, tc, rejected)
+		memory.AddWithContext(ctx, a.Memory, types.NewToolMessage(tc.ID, result), a.UserID)
+
+		if observer != nil && observer.completed != nil {
+			if err := observer.completed(tc, result, toolErr); err != nil {
+				return err
+			}
 		}
+	}
 
-		// Parse arguments
-		args, err := toolkit.ParseArguments(tc.Function.Arguments)
-		if err != nil {
-			errMsg := fmt.Sprintf("failed to parse arguments: %v", err)
-			a.logger.Error("argument parsing failed", "error", err)
-			a.Memory.Add(types.NewToolMessage(tc.ID, errMsg), a.UserID)
-			continue
+	return nil
+}
+
+// executeToolCallsParallel runs tool calls concurrently, bounded by
+// maxParallelToolCalls. Observer callbacks are serialised so they never run
+// concurrently with each other.
+func (a *Agent) executeToolCallsParallel(ctx context.Context, toolCalls []types.ToolCall, observer *toolCallObserver, rejected map[string]string) error {
+	type toolCallOutcome struct {
+		result  string
+		toolErr error
+	}
+
+	limit := a.maxParallelToolCalls
+	if limit <= 0 {
+		limit = defaultMaxParallelToolCalls
+	}
+
+	outcomes := make([]toolCallOutcome, len(toolCalls))
+	sem := make(chan struct{}, limit)
+
+	var (
+		wg          sync.WaitGroup
+		observerMu  sync.Mutex
+		observerErr error
+	)
+
+	notify := func(fn func() error) {
+		observerMu.Lock()
+		defer observerMu.Unlock()
+		if observerErr != nil {
+			return
 		}
+		if err := fn(); err != nil {
+			observerErr = err
+		}
+	}
 
-		// Execute tool
-		a.logger.Info("executing tool", "function", tc.Function.Name, "args", args)
+	a.logger.Debug("executing tool calls in parallel", "count", len(toolCalls), "limit", limit)
 
-		// Get the function and execute it directly
-		fn := targetToolkit.Functions()[tc.Function.Name]
-		if fn == nil {
-			errMsg := fmt.Sprintf("function %s not found", tc.Function.Name)
-			a.logger.Error("function not found", "function", tc.Function.Name)
-			a.Memory.Add(types.NewToolMessage(tc.ID, errMsg), a.UserID)
-			continue
+	for i, tc := range toolCalls {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, tc types.ToolCall) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if observer != nil && observer.started != nil {
+				notify(func() error { return observer.started(tc) })
+			}
+
+			result, toolErr := a.runToolCall(ctx, tc, rejected)
+			outcomes[i] = toolCallOutcome{result: result, toolErr: toolErr}
+
+			if observer != nil && observer.completed != nil {
+				notify(func() error { return observer.completed(tc, result, toolErr) })
+			}
+		}(i, tc)
+	}
+
+	wg.Wait()
+
+	// Preserve provider message ordering: tool results follow the order of
+	// the assistant's tool calls regardless of completion order.
+	for i, tc := range toolCalls {
+		memory.AddWithContext(ctx, a.Memory, types.NewToolMessage(tc.ID, outcomes[i].result), a.UserID)
+	}
+
+	return observerErr
+}
+
+// executeToolCallWithTimeout runs a single tool call bounded by the configured
+// per-call timeout. A handler that ignores its context is abandoned once the
+// timeout elapses and a timeout error is reported to the model instead.
+func (a *Agent) executeToolCallWithTimeout(ctx context.Context, tc types.ToolCall) (string, error) {
+	if a.toolCallTimeout <= 0 {
+		return a.executeToolCall(ctx, tc)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, a.toolCallTimeout)
+	defer cancel()
+
+	type toolCallOutcome struct {
+		result  string
+		toolErr error
+	}
+	done := make(chan toolCallOutcome, 1)
+	go func() {
+		result, toolErr := a.executeToolCall(callCtx, tc)
+		done <- toolCallOutcome{result: result, toolErr: toolErr}
+	}()
+
+	select {
+	case outcome := <-done:
+		return outcome.result, outcome.toolErr
+	case <-callCtx.Done():
+		err := callCtx.Err()
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			a.logger.Warn("tool execution timed out", "function", tc.Function.Name, "timeout", a.toolCallTimeout)
+			err = fmt.Errorf("tool %s timed out after %s", tc.Function.Name, a.toolCallTimeout)
 		}
+		return fmt.Sprintf("tool execution error: %v", err), err
+	}
+}
 
-		result, err := fn.Handler(ctx, args)
-		if err != nil {
-			errMsg := fmt.Sprintf("tool execution error: %v", err)
-			a.logger.Error("tool execution failed", "function", tc.Function.Name, "error", err)
-			a.Memory.Add(types.NewToolMessage(tc.ID, errMsg), a.UserID)
-			continue
+// executeToolCall runs a single tool call and returns the content to report
+// back to the model. Failures are returned both as a message for the model and
+// as toolErr so callers can surface them separately.
+func (a *Agent) executeToolCall(ctx context.Context, tc types.ToolCall) (result string, toolErr error) {
+	// Find the toolkit that has this function
+	var targetToolkit toolkit.Toolkit
+	for _, tk := range a.Toolkits {
+		if _, exists := tk.Functions()[tc.Function.Name]; exists {
+			targetToolkit = tk
+			break
 		}
+	}
+
+	if targetToolkit == nil {
+		errMsg := fmt.Sprintf("function %s not found in any toolkit", tc.Function.Name)
+		a.logger.Warn("tool not found", "function", tc.Function.Name)
+		return errMsg, errors.New(errMsg)
+	}
+
+	// Parse arguments
+	args, err := toolkit.ParseArguments(tc.Function.Arguments)
+	if err != nil {
+		a.logger.Error("argument parsing failed", "error", err)
+		return fmt.Sprintf("failed to parse arguments: %v", err), err
+	}
+
+	// Execute tool
+	a.logger.Info("executing tool", "function", tc.Function.Name, "args", args)
+
+	// Get the function and execute it directly
+	fn := targetToolkit.Functions()[tc.Function.Name]
+	if fn == nil {
+		errMsg := fmt.Sprintf("function %s not found", tc.Function.Name)
+		a.logger.Error("function not found", "function", tc.Function.Name)
+		return errMsg, errors.New(errMsg)
+	}
 
-		// Format and store result
-		resultStr, err := toolkit.FormatResult(result)
-		if err != nil {
-			resultStr = fmt.Sprintf("%v", result)
+	// Validate against the parameter schema; invalid arguments are reported
+	// back to the model as structured JSON so it can correct the next call.
+	args, err = toolkit.ValidateArguments(fn, args)
+	if err != nil {
+		var argErr *toolkit.ArgumentError
+		if errors.As(err, &argErr) {
+			a.logger.Warn("invalid tool arguments", "function", tc.Function.Name, "error", err)
+			if msg, fmtErr := toolkit.FormatResult(argErr); fmtErr == nil {
+				return msg, err
+			}
 		}
+		return fmt.Sprintf("invalid arguments: %v", err), err
+	}
 
-		a.logger.Info("tool executed successfully", "function", tc.Function.Name)
-		a.Memory.Add(types.NewToolMessage(tc.ID, resultStr), a.UserID)
+	out, err := fn.Handler(ctx, args)
+	if err != nil {
+		a.logger.Error("tool execution failed", "function", tc.Function.Name, "error", err)
+		return fmt.Sprintf("tool execution error: %v", err), err
 	}
 
-	return nil
+	// Format and store result
+	resultStr, err := toolkit.FormatResult(out)
+	if err != nil {
+		resultStr = fmt.Sprintf("%v", out)
+	}
+
+	a.logger.Info("tool executed successfully", "function", tc.Function.Name)
+	return resultStr, nil
 }
 
 // ClearMemory clears the agent's conversation history for this user
@@ -919,6 +1398,71 @@ func (a *Agent) updateSystemMessage(messages []*types.Message, instructions stri
 	return result
 }
 
+// loadUserMemories returns the system message with the user's memories
+// relevant to input, or nil when there are none or UserMemory is disabled.
+// Retrieval failures are logged and the run continues without memories.
+// loadUserMemories 返回包含与输入相关的用户记忆的系统消息
+func (a *Agent) loadUserMemories(ctx context.Context, input string) *types.Message {
+	if a.UserMemory == nil {
+		return nil
+	}
+	memories, err := a.UserMemory.Relevant(ctx, a.UserID, input)
+	if err != nil {
+		a.logger.Warn("failed to load user memories", "user_id", a.UserID, "error", err)
+		return nil
+	}
+	return a.UserMemory.SystemMessage(memories)
+}
+
+// extractUserMemories lets UserMemory update the user's memories from the
+// completed run. Failures are logged and do not fail the run.
+// extractUserMemories 根据已完成的运行更新用户记忆，失败时仅记录日志
+func (a *Agent) extractUserMemories(ctx context.Context, userMsg *types.Message, reply string) {
+	if a.UserMemory == nil {
+		return
+	}
+	messages := []*types.Message{userMsg, types.NewAssistantMessage(reply)}
+	if err := a.UserMemory.Extract(ctx, a.UserID, messages); err != nil {
+		a.logger.Warn("failed to extract user memories", "user_id", a.UserID, "error", err)
+	}
+}
+
+// searchKnowledge returns a system message with the knowledge passages
+// relevant to input in KnowledgeModeContext, or nil otherwise. Search
+// failures are logged and the run continues without the passages.
+// searchKnowledge 在 context 模式下返回包含相关知识段落的系统消息
+func (a *Agent) searchKnowledge(ctx context.Context, input string) *types.Message {
+	if a.Knowledge == nil || a.knowledgeMode != KnowledgeModeContext || strings.TrimSpace(input) == "" {
+		return nil
+	}
+	results, err := a.Knowledge.Search(ctx, input, a.knowledgeLimit, nil)
+	if err != nil {
+		a.logger.Warn("knowledge search failed", "agent_id", a.ID, "error", err)
+		return nil
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return types.NewSystemMessage(knowledgeContextPrefix + knowledge.FormatResults(results))
+}
+
+// insertAfterSystemMessages returns messages with msg placed after the
+// leading system messages, leaving the original slice untouched
+// insertAfterSystemMessages 将 msg 插入到前置系统消息之后（不修改原切片）
+func insertAfterSystemMessages(messages []*types.Message, msg *types.Message) []*types.Message {
+	if msg == nil {
+		return messages
+	}
+	i := 0
+	for i < len(messages) && messages[i].Role == types.RoleSystem {
+		i++
+	}
+	result := make([]*types.Message, 0, len(messages)+1)
+	result = append(result, messages[:i]...)
+	result = append(result, msg)
+	return append(result, messages[i:]...)
+}
+
 // filterToolMessages removes tool-related messages from the slice.
 // It filters out messages with Role == RoleTool and clears tool-related fields from other messages.
 // filterToolMessages 从切片中移除工具相关消息
@@ -2,18 +2,22 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/rexleimo/agno-go/pkg/agno/cache"
+	"github.com/rexleimo/agno-go/pkg/agno/knowledge"
 	"github.com/rexleimo/agno-go/pkg/agno/memory"
 	"github.com/rexleimo/agno-go/pkg/agno/models"
 	"github.com/rexleimo/agno-go/pkg/agno/run"
 	"github.com/rexleimo/agno-go/pkg/agno/tools/calculator"
 	"github.com/rexleimo/agno-go/pkg/agno/tools/toolkit"
 	"github.com/rexleimo/agno-go/pkg/agno/types"
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
 )
 
 // MockModel is a simple mock for testing
@@ -197,6 +201,128 @@ func TestAgent_RunStream_Basic(t *testing.T) {
 	}
 }
 
+func TestAgent_RunStream_ExecutesToolCalls(t *testing.T) {
+	callCount := 0
+	mockModel := &MockModel{
+		BaseModel: models.BaseModel{ID: "test", Provider: "mock"},
+		InvokeStreamFunc: func(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
+			callCount++
+			ch := make(chan types.ResponseChunk, 3)
+			if callCount == 1 {
+				// Arguments arrive split across chunks, as with OpenAI deltas.
+				ch <- types.ResponseChunk{ToolCalls: []types.ToolCall{
+					{ID: "call_1", Type: "function", Function: types.ToolCallFunction{Name: "add", Arguments: `{"a": 5, `}},
+				}}
+				ch <- types.ResponseChunk{ToolCalls: []types.ToolCall{
+					{Function: types.ToolCallFunction{Arguments: `"b": 3}`}},
+				}}
+			} else {
+				ch <- types.ResponseChunk{Content: "The result"}
+				ch <- types.ResponseChunk{Content: " is 8"}
+			}
+			close(ch)
+			return ch, nil
+		},
+	}
+
+	ag, err := New(Config{
+		Name:     "StreamToolAgent",
+		Model:    mockModel,
+		Toolkits: []toolkit.Toolkit{calculator.New()},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	result, err := ag.RunStream(context.Background(), "What is 5 + 3?")
+	if err != nil {
+		t.Fatalf("RunStream() error = %v", err)
+	}
+
+	var kinds []string
+	var completedEvt *run.ToolCallCompletedEvent
+	for evt := range result.Events {
+		kinds = append(kinds, evt.EventType())
+		if e, ok := evt.(*run.ToolCallCompletedEvent); ok {
+			completedEvt = e
+		}
+	}
+
+	done := <-result.Done
+	if done.Err != nil {
+		t.Fatalf("RunStream() Done error = %v", done.Err)
+	}
+	if callCount != 2 {
+		t.Fatalf("expected 2 model passes, got %d", callCount)
+	}
+
+	want := []string{run.EventTypeToolCallStarted, run.EventTypeToolCallCompleted, run.EventTypeRunContent, run.EventTypeRunContent}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected event order %v, want %v", kinds, want)
+	}
+	if completedEvt == nil || completedEvt.Error != "" || completedEvt.Result != "8" {
+		t.Fatalf("unexpected tool completion event: %+v", completedEvt)
+	}
+	if done.Output.Content != "The result is 8" {
+		t.Errorf("RunStream() content = %q", done.Output.Content)
+	}
+	if loops, _ := done.Output.Metadata["loops"].(int); loops != 2 {
+		t.Errorf("RunStream() loops = %v, want 2", done.Output.Metadata["loops"])
+	}
+
+	var toolMsgs int
+	for _, msg := range done.Output.Messages {
+		if msg.Role == types.RoleTool && msg.ToolCallID == "call_1" {
+			toolMsgs++
+		}
+	}
+	if toolMsgs != 1 {
+		t.Fatalf("expected 1 tool message for call_1, got %d", toolMsgs)
+	}
+}
+
+func TestAgent_RunStream_MaxLoops(t *testing.T) {
+	mockModel := &MockModel{
+		BaseModel: models.BaseModel{ID: "test", Provider: "mock"},
+		InvokeStreamFunc: func(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
+			ch := make(chan types.ResponseChunk, 1)
+			ch <- types.ResponseChunk{ToolCalls: []types.ToolCall{
+				{ID: fmt.Sprintf("call_%d", len(req.Messages)), Type: "function", Function: types.ToolCallFunction{Name: "add", Arguments: `{"a": 1, "b": 1}`}},
+			}}
+			close(ch)
+			return ch, nil
+		},
+	}
+
+	ag, err := New(Config{
+		Name:     "LoopingStreamAgent",
+		Model:    mockModel,
+		Toolkits: []toolkit.Toolkit{calculator.New()},
+		MaxLoops: 2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	result, err := ag.RunStream(context.Background(), "loop")
+	if err != nil {
+		t.Fatalf("RunStream() error = %v", err)
+	}
+	started := 0
+	for evt := range result.Events {
+		if evt.EventType() == run.EventTypeToolCallStarted {
+			started++
+		}
+	}
+	done := <-result.Done
+	if done.Err == nil {
+		t.Fatal("expected max loops error")
+	}
+	if started != 2 {
+		t.Fatalf("expected 2 tool calls before giving up, got %d", started)
+	}
+}
+
 func TestAgent_Run_EmitsEvents(t *testing.T) {
 	agent, err := New(Config{
 		Name: "events-agent",
@@ -608,3 +734,538 @@ func TestAgent_MultiTenant(t *testing.T) {
 		t.Errorf("User2 memory should be unaffected, got %d messages", sharedMemory.Size("user2"))
 	}
 }
+
+func newSleepToolkit(delays map[string]time.Duration, active, peak *int32) toolkit.Toolkit {
+	tk := toolkit.NewBaseToolkit("sleepers")
+	for name, delay := range delays {
+		delay := delay
+		tk.RegisterFunction(&toolkit.Function{
+			Name:        name,
+			Description: "sleeps before answering",
+			Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				if active != nil {
+					n := atomic.AddInt32(active, 1)
+					defer atomic.AddInt32(active, -1)
+					for {
+						p := atomic.LoadInt32(peak)
+						if n <= p || atomic.CompareAndSwapInt32(peak, p, n) {
+							break
+						}
+					}
+				}
+				select {
+				case <-time.After(delay):
+					return delay.String(), nil
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			},
+		})
+	}
+	return tk
+}
+
+func toolCallModel(calls ...string) *MockModel {
+	callCount := 0
+	return &MockModel{
+		BaseModel: models.BaseModel{ID: "test", Provider: "mock"},
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			callCount++
+			if callCount > 1 {
+				return &types.ModelResponse{Content: "done"}, nil
+			}
+			toolCalls := make([]types.ToolCall, len(calls))
+			for i, name := range calls {
+				toolCalls[i] = types.ToolCall{
+					ID:       fmt.Sprintf("call_%d", i),
+					Type:     "function",
+					Function: types.ToolCallFunction{Name: name, Arguments: `{}`},
+				}
+			}
+			return &types.ModelResponse{ToolCalls: toolCalls}, nil
+		},
+	}
+}
+
+func TestAgent_Run_ParallelToolCalls(t *testing.T) {
+	var active, peak int32
+	tk := newSleepToolkit(map[string]time.Duration{
+		"slow":   60 * time.Millisecond,
+		"medium": 40 * time.Millisecond,
+		"fast":   10 * time.Millisecond,
+	}, &active, &peak)
+
+	ag, err := New(Config{
+		Name:                 "ParallelAgent",
+		Model:                toolCallModel("slow", "medium", "fast", "slow"),
+		Toolkits:             []toolkit.Toolkit{tk},
+		ParallelToolCalls:    true,
+		MaxParallelToolCalls: 3,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	start := time.Now()
+	output, err := ag.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 170*time.Millisecond {
+		t.Fatalf("expected tool calls to overlap, took %s", elapsed)
+	}
+	if got := atomic.LoadInt32(&peak); got > 3 || got < 2 {
+		t.Fatalf("expected concurrency between 2 and 3, got %d", got)
+	}
+
+	var ids []string
+	for _, msg := range output.Messages {
+		if msg.Role == types.RoleTool {
+			ids = append(ids, msg.ToolCallID)
+		}
+	}
+	if strings.Join(ids, ",") != "call_0,call_1,call_2,call_3" {
+		t.Fatalf("tool results out of order: %v", ids)
+	}
+}
+
+func TestAgent_Run_ToolCallTimeout(t *testing.T) {
+	tk := newSleepToolkit(map[string]time.Duration{
+		"hang": time.Second,
+		"fast": time.Millisecond,
+	}, nil, nil)
+
+	ag, err := New(Config{
+		Name:            "TimeoutAgent",
+		Model:           toolCallModel("hang", "fast"),
+		Toolkits:        []toolkit.Toolkit{tk},
+		ToolCallTimeout: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	start := time.Now()
+	output, err := ag.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 500*time.Millisecond {
+		t.Fatalf("expected timeout to cut the hanging tool short, took %s", elapsed)
+	}
+
+	var toolMsgs []*types.Message
+	for _, msg := range output.Messages {
+		if msg.Role == types.RoleTool {
+			toolMsgs = append(toolMsgs, msg)
+		}
+	}
+	if len(toolMsgs) != 2 {
+		t.Fatalf("expected 2 tool messages, got %d", len(toolMsgs))
+	}
+	if !strings.Contains(toolMsgs[0].Content, "timed out") {
+		t.Fatalf("expected timeout message, got %q", toolMsgs[0].Content)
+	}
+	if toolMsgs[1].Content != `"1ms"` {
+		t.Fatalf("expected fast tool result, got %q", toolMsgs[1].Content)
+	}
+}
+
+type weatherReport struct {
+	City        string  `json:"city"`
+	Temperature float64 `json:"temperature"`
+	Conditions  string  `json:"conditions" enum:"sunny,rainy"`
+}
+
+func TestAgent_Run_WithContentParts(t *testing.T) {
+	var received *types.Message
+	model := &MockModel{
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			received = req.Messages[len(req.Messages)-1]
+			return &types.ModelResponse{Content: "a cat"}, nil
+		},
+	}
+
+	ag, err := New(Config{Name: "VisionAgent", Model: model})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	image := types.NewImageURLPart("https://example.com/cat.png")
+	if _, err := ag.Run(context.Background(), "", image); err != nil {
+		t.Fatalf("Run() with only an image error = %v", err)
+	}
+	if received == nil || len(received.Parts) != 1 || received.Parts[0].URL != image.URL {
+		t.Fatalf("expected the image part to reach the model, got %+v", received)
+	}
+
+	if _, err := ag.Run(context.Background(), ""); err == nil {
+		t.Fatal("expected error for empty input without parts")
+	}
+}
+
+func TestAgent_Run_UserMemory(t *testing.T) {
+	extractor := &MockModel{
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			return &types.ModelResponse{Content: `{"operations":[{"action":"add","memory":"Prefers Go"}]}`}, nil
+		},
+	}
+	manager, err := memory.NewUserMemoryManager(memory.UserMemoryManagerConfig{Model: extractor})
+	if err != nil {
+		t.Fatalf("NewUserMemoryManager() error = %v", err)
+	}
+
+	var requests [][]*types.Message
+	model := &MockModel{
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			requests = append(requests, req.Messages)
+			return &types.ModelResponse{Content: "ok"}, nil
+		},
+	}
+	ag, err := New(Config{Model: model, Instructions: "be brief", UserID: "ada", UserMemory: manager})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	if _, err := ag.Run(context.Background(), "I like Go"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for _, msg := range requests[0] {
+		if strings.HasPrefix(msg.Content, memory.UserMemoryContextPrefix) {
+			t.Fatal("first run should have no memories to inject")
+		}
+	}
+	if memories, _ := manager.Memories(context.Background(), "ada"); len(memories) != 1 {
+		t.Fatalf("expected one extracted memory, got %d", len(memories))
+	}
+
+	// A later session of the same user sees the memory after the instructions.
+	ag.ClearMemory()
+	output, err := ag.Run(context.Background(), "what should I use?")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	msgs := requests[1]
+	if len(msgs) != 3 || msgs[0].Content != "be brief" || msgs[1].Content != memory.UserMemoryContextPrefix+"- Prefers Go" {
+		t.Fatalf("expected memories after the instructions, got %+v", msgs)
+	}
+	for _, msg := range output.Messages {
+		if strings.HasPrefix(msg.Content, memory.UserMemoryContextPrefix) {
+			t.Fatal("memories should not be stored in the conversation history")
+		}
+	}
+}
+
+// knowledgeVectorDB returns every stored document for any query
+type knowledgeVectorDB struct {
+	vectordb.VectorDB
+	docs []vectordb.Document
+}
+
+func (k *knowledgeVectorDB) Add(ctx context.Context, docs []vectordb.Document) error {
+	k.docs = append(k.docs, docs...)
+	return nil
+}
+
+func (k *knowledgeVectorDB) Get(ctx context.Context, ids []string) ([]vectordb.Document, error) {
+	return nil, nil
+}
+
+func (k *knowledgeVectorDB) Query(ctx context.Context, query string, limit int, filter map[string]interface{}) ([]vectordb.SearchResult, error) {
+	results := make([]vectordb.SearchResult, 0, len(k.docs))
+	for _, doc := range k.docs {
+		results = append(results, vectordb.SearchResult{ID: doc.ID, Content: doc.Content, Metadata: doc.Metadata})
+	}
+	return results, nil
+}
+
+func newTestKnowledge(t *testing.T) *knowledge.Base {
+	t.Helper()
+	base, err := knowledge.NewBase(knowledge.BaseConfig{VectorDB: &knowledgeVectorDB{}})
+	if err != nil {
+		t.Fatalf("NewBase() error = %v", err)
+	}
+	docs := []knowledge.Document{{ID: "faq", Content: "The office opens at 9am.", Source: "faq.md"}}
+	if _, err := base.LoadDocuments(context.Background(), docs); err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+	return base
+}
+
+func TestAgent_Run_KnowledgeContext(t *testing.T) {
+	var received []*types.Message
+	model := &MockModel{
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			received = req.Messages
+			if len(req.Tools) != 0 {
+				t.Fatalf("context mode should not add tools, got %d", len(req.Tools))
+			}
+			return &types.ModelResponse{Content: "9am"}, nil
+		},
+	}
+	ag, err := New(Config{Model: model, Instructions: "be brief", Knowledge: newTestKnowledge(t)})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	if _, err := ag.Run(context.Background(), "When does the office open?"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(received) != 3 || received[1].Role != types.RoleSystem {
+		t.Fatalf("expected knowledge after the instructions, got %+v", received)
+	}
+	if !strings.HasPrefix(received[1].Content, knowledgeContextPrefix) || !strings.Contains(received[1].Content, "The office opens at 9am.") {
+		t.Fatalf("unexpected knowledge message %q", received[1].Content)
+	}
+}
+
+func TestAgent_Run_KnowledgeTool(t *testing.T) {
+	calls := 0
+	var toolResult string
+	model := &MockModel{
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			calls++
+			if calls == 1 {
+				if len(req.Tools) != 1 || req.Tools[0].Function.Name != knowledge.SearchToolName {
+					t.Fatalf("expected the knowledge tool, got %+v", req.Tools)
+				}
+				return &types.ModelResponse{ToolCalls: []types.ToolCall{{
+					ID:       "call_1",
+					Type:     "function",
+					Function: types.ToolCallFunction{Name: knowledge.SearchToolName, Arguments: `{"query":"office hours"}`},
+				}}}, nil
+			}
+			toolResult = req.Messages[len(req.Messages)-1].Content
+			return &types.ModelResponse{Content: "9am"}, nil
+		},
+	}
+	ag, err := New(Config{Model: model, Knowledge: newTestKnowledge(t), KnowledgeMode: KnowledgeModeTool})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	if _, err := ag.Run(context.Background(), "When does the office open?"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !strings.Contains(toolResult, "The office opens at 9am.") {
+		t.Fatalf("expected the search result as tool output, got %q", toolResult)
+	}
+
+	if _, err := New(Config{Model: model, KnowledgeMode: "bogus"}); err == nil {
+		t.Fatal("expected error for an unknown knowledge mode")
+	}
+}
+
+func TestAgent_Run_OutputSchema(t *testing.T) {
+	replies := []string{
+		"It is sunny in Paris.",
+		`{"city":"Paris","temperature":"warm","conditions":"sunny"}`,
+		"```json\n{\"city\":\"Paris\",\"temperature\":21.5,\"conditions\":\"sunny\"}\n```",
+	}
+	var calls int32
+	model := &MockModel{
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			if req.ResponseFormat == nil || req.ResponseFormat.Type != models.ResponseFormatJSONSchema {
+				t.Fatalf("expected json_schema response format, got %+v", req.ResponseFormat)
+			}
+			n := atomic.AddInt32(&calls, 1)
+			return &types.ModelResponse{Content: replies[n-1]}, nil
+		},
+	}
+
+	ag, err := New(Config{
+		Name:         "StructuredAgent",
+		Model:        model,
+		OutputSchema: weatherReport{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	output, err := ag.Run(context.Background(), "weather in Paris")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 model calls, got %d", calls)
+	}
+	report, ok := output.Structured.(*weatherReport)
+	if !ok {
+		t.Fatalf("expected *weatherReport, got %T", output.Structured)
+	}
+	if report.City != "Paris" || report.Temperature != 21.5 || report.Conditions != "sunny" {
+		t.Fatalf("unexpected structured output: %+v", report)
+	}
+	if output.Metadata["output_schema_retries"] != 2 {
+		t.Fatalf("expected 2 retries recorded, got %v", output.Metadata["output_schema_retries"])
+	}
+}
+
+func TestAgent_Run_OutputSchemaExhausted(t *testing.T) {
+	var calls int32
+	model := &MockModel{
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			atomic.AddInt32(&calls, 1)
+			return &types.ModelResponse{Content: `{"answer":1}`}, nil
+		},
+	}
+
+	ag, err := New(Config{
+		Name:  "StructuredAgent",
+		Model: model,
+		OutputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"answer": map[string]interface{}{"type": "string"}},
+			"required":   []interface{}{"answer"},
+		},
+		OutputSchemaRetries: 1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	_, err = ag.Run(context.Background(), "answer")
+	if err == nil {
+		t.Fatal("expected structured output error")
+	}
+	var agnoErr *types.AgnoError
+	if !errors.As(err, &agnoErr) || agnoErr.Code != types.ErrCodeOutputCheck {
+		t.Fatalf("expected output check error, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 model calls, got %d", calls)
+	}
+}
+
+func TestAgent_RunStream_OutputSchema(t *testing.T) {
+	tk := toolkit.NewBaseToolkit("weather")
+	tk.RegisterFunction(&toolkit.Function{
+		Name:        "lookup",
+		Description: "lookup",
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "sunny", nil
+		},
+	})
+
+	// The first reply is invalid; the second arrives as a call of the
+	// synthetic output tool, as providers that force a tool choice send it.
+	var calls int32
+	model := &MockModel{
+		BaseModel: models.BaseModel{ID: "test", Provider: "mock"},
+		InvokeStreamFunc: func(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
+			ch := make(chan types.ResponseChunk, 1)
+			if atomic.AddInt32(&calls, 1) == 1 {
+				ch <- types.ResponseChunk{Content: "It is sunny in Paris."}
+			} else {
+				ch <- types.ResponseChunk{ToolCalls: []types.ToolCall{{
+					ID:   "toolu_1",
+					Type: "function",
+					Function: types.ToolCallFunction{
+						Name:      req.ResponseFormat.SchemaName(),
+						Arguments: `{"city":"Paris","temperature":21.5,"conditions":"sunny"}`,
+					},
+				}}}
+			}
+			close(ch)
+			return ch, nil
+		},
+	}
+
+	ag, err := New(Config{
+		Name:         "StructuredAgent",
+		Model:        model,
+		Toolkits:     []toolkit.Toolkit{tk},
+		OutputSchema: weatherReport{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	result, err := ag.RunStream(context.Background(), "weather in Paris")
+	if err != nil {
+		t.Fatalf("RunStream() error = %v", err)
+	}
+	for evt := range result.Events {
+		if _, ok := evt.(*run.ToolCallStartedEvent); ok {
+			t.Fatal("the output tool must not be executed")
+		}
+	}
+	done := <-result.Done
+	if done.Err != nil {
+		t.Fatalf("RunStream() Done error = %v", done.Err)
+	}
+	report, ok := done.Output.Structured.(*weatherReport)
+	if !ok || report.City != "Paris" {
+		t.Fatalf("unexpected structured output: %#v", done.Output.Structured)
+	}
+	if calls != 2 || done.Output.Metadata["output_schema_retries"] != 1 {
+		t.Fatalf("expected 2 model calls and 1 retry, got %d and %v", calls, done.Output.Metadata["output_schema_retries"])
+	}
+}
+
+func TestNew_InvalidOutputSchema(t *testing.T) {
+	_, err := New(Config{
+		Model:        &MockModel{BaseModel: models.BaseModel{ID: "test", Provider: "mock"}},
+		OutputSchema: make(chan int),
+	})
+	if err == nil {
+		t.Fatal("expected error for unsupported output schema type")
+	}
+}
+
+func TestAgent_Run_ValidatesToolArguments(t *testing.T) {
+	var toolResults []string
+	callCount := 0
+	mockModel := &MockModel{
+		BaseModel: models.BaseModel{ID: "test", Provider: "mock"},
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			callCount++
+			last := req.Messages[len(req.Messages)-1]
+			if last.Role == types.RoleTool {
+				toolResults = append(toolResults, last.Content)
+			}
+
+			switch callCount {
+			case 1:
+				// Invalid: b is not a number
+				return &types.ModelResponse{ToolCalls: []types.ToolCall{{
+					ID:       "call_1",
+					Type:     "function",
+					Function: types.ToolCallFunction{Name: "add", Arguments: `{"a": 5, "b": "three"}`},
+				}}}, nil
+			case 2:
+				// Coercible: numeric strings are accepted
+				return &types.ModelResponse{ToolCalls: []types.ToolCall{{
+					ID:       "call_2",
+					Type:     "function",
+					Function: types.ToolCallFunction{Name: "add", Arguments: `{"a": "5", "b": "3"}`},
+				}}}, nil
+			default:
+				return &types.ModelResponse{Content: "The result is 8"}, nil
+			}
+		},
+	}
+
+	ag, err := New(Config{
+		Name:     "ValidatingAgent",
+		Model:    mockModel,
+		Toolkits: []toolkit.Toolkit{calculator.New()},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	if _, err := ag.Run(context.Background(), "What is 5 + 3?"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(toolResults) != 2 {
+		t.Fatalf("expected 2 tool results, got %d", len(toolResults))
+	}
+	if !strings.Contains(toolResults[0], `"invalid_arguments"`) || !strings.Contains(toolResults[0], `$.b`) {
+		t.Fatalf("expected structured validation error, got %s", toolResults[0])
+	}
+	if toolResults[1] != "8" {
+		t.Fatalf("expected coerced call to succeed, got %s", toolResults[1])
+	}
+}
@@ -0,0 +1,378 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/memory"
+	"github.com/rexleimo/agno-go/pkg/agno/run"
+	"github.com/rexleimo/agno-go/pkg/agno/tools/toolkit"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// defaultPausedRunTTL is how long a paused run waits for ContinueRun
+const defaultPausedRunTTL = time.Hour
+
+// ErrRunNotPaused is wrapped by the errors of ContinueRun and CancelPausedRun
+// for a run that is not paused, or was already continued or cancelled.
+var ErrRunNotPaused = errors.New("run is not paused")
+
+// ToolCallAction is the decision taken on a tool call awaiting confirmation.
+type ToolCallAction string
+
+const (
+	// ToolCallApprove executes the call with the model's arguments
+	ToolCallApprove ToolCallAction = "approve"
+	// ToolCallReject skips the call and tells the model it was rejected
+	ToolCallReject ToolCallAction = "reject"
+	// ToolCallEdit executes the call with Arguments instead of the model's
+	ToolCallEdit ToolCallAction = "edit"
+)
+
+// ToolCallDecision answers one pending tool call of a paused run.
+type ToolCallDecision struct {
+	ToolCallID string                 `json:"tool_call_id"`
+	Action     ToolCallAction         `json:"action"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"` // Replacement arguments for ToolCallEdit
+	Reason     string                 `json:"reason,omitempty"`    // Reported to the model on ToolCallReject
+}
+
+// PausedRunError reports a run that is paused waiting for tool-call confirmation.
+type PausedRunError struct {
+	RunID            string
+	PendingToolCalls []types.ToolCall
+}
+
+func (e *PausedRunError) Error() string {
+	names := make([]string, len(e.PendingToolCalls))
+	for i, tc := range e.PendingToolCalls {
+		names[i] = tc.Function.Name
+	}
+	return fmt.Sprintf("run %s is paused awaiting confirmation of %s", e.RunID, strings.Join(names, ", "))
+}
+
+// PausedError returns a *PausedRunError when the run is paused and nil
+// otherwise. Callers that cannot ask a human for confirmation use it to treat
+// a pause as a failure instead of an empty reply.
+func (o *RunOutput) PausedError() error {
+	if o == nil || o.Status != RunStatusPaused {
+		return nil
+	}
+	return &PausedRunError{RunID: o.RunID, PendingToolCalls: o.PendingToolCalls}
+}
+
+// ContinueRun resumes a run that Run or RunStream returned with status
+// RunStatusPaused. decisions must answer every pending tool call; the tool
+// calls of the paused model turn are then executed in order (rejected calls are
+// reported to the model instead) and the run continues without streaming.
+// ContinueRun 根据每个待确认工具调用的决定恢复暂停的运行
+func (a *Agent) ContinueRun(ctx context.Context, runID string, decisions []ToolCallDecision) (*RunOutput, error) {
+	a.expirePausedRuns()
+
+	a.pausedRunsMu.Lock()
+	st := a.pausedRuns[runID]
+	a.pausedRunsMu.Unlock()
+	if st == nil {
+		return nil, types.NewInvalidInputError(fmt.Sprintf("run %s is not paused", runID), ErrRunNotPaused)
+	}
+
+	byID, err := matchToolCallDecisions(a.pendingConfirmations(st.toolCalls), decisions)
+	if err != nil {
+		return nil, types.NewInvalidInputError("invalid tool call decisions", err)
+	}
+
+	// Claim the run so concurrent calls cannot continue it twice
+	a.pausedRunsMu.Lock()
+	if a.pausedRuns[runID] != st {
+		a.pausedRunsMu.Unlock()
+		return nil, types.NewInvalidInputError(fmt.Sprintf("run %s is not paused", runID), ErrRunNotPaused)
+	}
+	delete(a.pausedRuns, runID)
+	a.pausedRunsMu.Unlock()
+
+	rejected := make(map[string]string)
+	for i := range st.toolCalls {
+		tc := &st.toolCalls[i]
+		decision, ok := byID[tc.ID]
+		if !ok {
+			continue
+		}
+		switch decision.Action {
+		case ToolCallReject:
+			rejected[tc.ID] = decision.Reason
+		case ToolCallEdit:
+			args, _ := json.Marshal(decision.Arguments)
+			tc.Function.Arguments = string(args)
+			a.storeToolCallEdit(ctx, *tc)
+		}
+	}
+
+	ctx = run.WithContext(ctx, st.runCtx)
+	st.output.Status = RunStatusRunning
+	st.output.PendingToolCalls = nil
+	a.logger.Info("agent run continued", "agent_id", a.ID, "run_id", runID, "rejected", len(rejected))
+
+	toolCalls := st.toolCalls
+	st.toolCalls = nil
+	if err := a.executeToolCalls(ctx, toolCalls, nil, rejected); err != nil {
+		if isCancellation(ctx, err) {
+			cancelled := a.markRunCancelled(st.output, st.loopCount, st.cacheHit, err, st.initialMessageCount)
+			return cancelled, types.NewCancellationError("agent run cancelled", err)
+		}
+		a.logger.Error("tool execution failed", "error", err)
+		return nil, types.NewToolExecutionError("tool execution failed", err)
+	}
+
+	return a.runLoop(ctx, st)
+}
+
+// CancelPausedRun abandons a paused run. Its tool calls are answered in
+// Memory with a cancellation notice, so the history stays valid for the next run.
+// CancelPausedRun 放弃暂停的运行，并在 Memory 中为其工具调用补充取消结果
+func (a *Agent) CancelPausedRun(runID string) error {
+	a.pausedRunsMu.Lock()
+	st := a.pausedRuns[runID]
+	delete(a.pausedRuns, runID)
+	a.pausedRunsMu.Unlock()
+	if st == nil {
+		return types.NewInvalidInputError(fmt.Sprintf("run %s is not paused", runID), ErrRunNotPaused)
+	}
+
+	a.abandonRun(st, "cancelled")
+	return nil
+}
+
+// checkPausedRuns refuses to start a run for the user and session of a
+// paused run, whose model turn has tool calls without results. Runs of other
+// users and sessions are not blocked; requestMessages leaves the paused calls
+// out of their requests.
+func (a *Agent) checkPausedRuns(rc *run.RunContext) error {
+	a.expirePausedRuns()
+
+	a.pausedRunsMu.Lock()
+	defer a.pausedRunsMu.Unlock()
+	for runID, st := range a.pausedRuns {
+		if !sameRunScope(st.runCtx, rc) {
+			continue
+		}
+		return types.NewInvalidInputError("agent has a paused run; continue or cancel it first", &PausedRunError{
+			RunID:            runID,
+			PendingToolCalls: a.pendingConfirmations(st.toolCalls),
+		})
+	}
+	return nil
+}
+
+// sameRunScope reports whether two runs belong to the same user and session.
+func sameRunScope(x, y *run.RunContext) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	return x.UserID == y.UserID && x.SessionID == y.SessionID
+}
+
+// requestMessages returns the history sent to the model. The tool calls of
+// paused runs have no results yet, so they are left out, and each tool
+// result is placed right after its call: results of a continued or
+// abandoned run are recorded after the turns of other runs.
+func (a *Agent) requestMessages() []*types.Message {
+	paused := make(map[string]bool)
+	a.pausedRunsMu.Lock()
+	for _, st := range a.pausedRuns {
+		for _, tc := range st.toolCalls {
+			paused[tc.ID] = true
+		}
+	}
+	a.pausedRunsMu.Unlock()
+
+	messages := a.Memory.GetMessages(a.UserID)
+	called := make(map[string]bool)
+	results := make(map[string]*types.Message)
+	for _, msg := range messages {
+		switch msg.Role {
+		case types.RoleAssistant:
+			for _, tc := range msg.ToolCalls {
+				called[tc.ID] = true
+			}
+		case types.RoleTool:
+			if _, ok := results[msg.ToolCallID]; !ok {
+				results[msg.ToolCallID] = msg
+			}
+		}
+	}
+
+	arranged := make([]*types.Message, 0, len(messages))
+	for _, msg := range messages {
+		switch msg.Role {
+		case types.RoleAssistant:
+			if len(msg.ToolCalls) > 0 && paused[msg.ToolCalls[0].ID] {
+				continue
+			}
+			arranged = append(arranged, msg)
+			for _, tc := range msg.ToolCalls {
+				if result, ok := results[tc.ID]; ok {
+					arranged = append(arranged, result)
+				}
+			}
+		case types.RoleTool:
+			if !called[msg.ToolCallID] {
+				arranged = append(arranged, msg)
+			}
+		default:
+			arranged = append(arranged, msg)
+		}
+	}
+	return arranged
+}
+
+// storeToolCallEdit replaces the tool call in Memory, so the model sees the
+// arguments that were actually used, also after a restart.
+func (a *Agent) storeToolCallEdit(ctx context.Context, tc types.ToolCall) {
+	editor, ok := a.Memory.(memory.ToolCallEditor)
+	if !ok {
+		a.logger.Warn("memory cannot store edited tool call arguments", "tool_call_id", tc.ID)
+		return
+	}
+	if err := editor.EditToolCall(ctx, tc, a.UserID); err != nil {
+		a.logger.Warn("failed to store edited tool call arguments", "tool_call_id", tc.ID, "error", err)
+	}
+}
+
+// expirePausedRuns abandons the runs that have been paused longer than the TTL.
+func (a *Agent) expirePausedRuns() {
+	now := time.Now()
+	var expired []*runState
+
+	a.pausedRunsMu.Lock()
+	for runID, st := range a.pausedRuns {
+		if now.Sub(st.pausedAt) > a.pausedRunTTL {
+			delete(a.pausedRuns, runID)
+			expired = append(expired, st)
+		}
+	}
+	a.pausedRunsMu.Unlock()
+
+	for _, st := range expired {
+		a.abandonRun(st, "expired")
+	}
+}
+
+// abandonRun records a result for each tool call of a paused run that will
+// never be continued.
+func (a *Agent) abandonRun(st *runState, reason string) {
+	for _, tc := range st.toolCalls {
+		a.Memory.Add(types.NewToolMessage(tc.ID, "tool call not executed: the run was "+reason), a.UserID)
+	}
+	a.logger.Info("paused agent run "+reason, "agent_id", a.ID, "run_id", st.output.RunID)
+}
+
+// pendingConfirmations returns the tool calls whose functions require confirmation.
+func (a *Agent) pendingConfirmations(toolCalls []types.ToolCall) []types.ToolCall {
+	var pending []types.ToolCall
+	for _, tc := range toolCalls {
+		if fn := a.findFunction(tc.Function.Name); fn != nil && fn.RequiresConfirmation {
+			pending = append(pending, tc)
+		}
+	}
+	return pending
+}
+
+func (a *Agent) needsConfirmation(toolCalls []types.ToolCall) bool {
+	return len(a.pendingConfirmations(toolCalls)) > 0
+}
+
+// pauseRun stores the run state and returns a paused snapshot of its output.
+func (a *Agent) pauseRun(st *runState, toolCalls []types.ToolCall) *RunOutput {
+	st.toolCalls = toolCalls
+	st.pausedAt = time.Now()
+
+	paused := *st.output
+	paused.Status = RunStatusPaused
+	paused.PendingToolCalls = a.pendingConfirmations(toolCalls)
+	paused.Messages = a.Memory.GetMessages(a.UserID)
+	paused.Metadata = make(map[string]interface{}, len(st.output.Metadata)+1)
+	for k, v := range st.output.Metadata {
+		paused.Metadata[k] = v
+	}
+	paused.Metadata["loops"] = st.loopCount
+	addRunContextMetadata(&paused, st.runCtx)
+	a.scrubRunOutputWithContext(&paused, st.initialMessageCount)
+
+	a.pausedRunsMu.Lock()
+	if a.pausedRuns == nil {
+		a.pausedRuns = make(map[string]*runState)
+	}
+	a.pausedRuns[paused.RunID] = st
+	a.pausedRunsMu.Unlock()
+
+	a.logger.Info("agent run paused for tool confirmation",
+		"agent_id", a.ID,
+		"run_id", paused.RunID,
+		"pending", len(paused.PendingToolCalls))
+	return &paused
+}
+
+// matchToolCallDecisions checks that decisions answer each pending call exactly once.
+func matchToolCallDecisions(pending []types.ToolCall, decisions []ToolCallDecision) (map[string]ToolCallDecision, error) {
+	waiting := make(map[string]bool, len(pending))
+	for _, tc := range pending {
+		waiting[tc.ID] = true
+	}
+
+	byID := make(map[string]ToolCallDecision, len(decisions))
+	for _, decision := range decisions {
+		if !waiting[decision.ToolCallID] {
+			return nil, fmt.Errorf("tool call %q is not awaiting confirmation", decision.ToolCallID)
+		}
+		if _, dup := byID[decision.ToolCallID]; dup {
+			return nil, fmt.Errorf("duplicate decision for tool call %q", decision.ToolCallID)
+		}
+		switch decision.Action {
+		case ToolCallApprove, ToolCallReject:
+		case ToolCallEdit:
+			if decision.Arguments == nil {
+				return nil, fmt.Errorf("edit decision for tool call %q requires arguments", decision.ToolCallID)
+			}
+		default:
+			return nil, fmt.Errorf("unknown action %q for tool call %q", decision.Action, decision.ToolCallID)
+		}
+		byID[decision.ToolCallID] = decision
+	}
+
+	var missing []error
+	for _, tc := range pending {
+		if _, ok := byID[tc.ID]; !ok {
+			missing = append(missing, fmt.Errorf("missing decision for tool call %q (%s)", tc.ID, tc.Function.Name))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.Join(missing...)
+	}
+	return byID, nil
+}
+
+// runToolCall executes tc unless it was rejected during confirmation.
+func (a *Agent) runToolCall(ctx context.Context, tc types.ToolCall, rejected map[string]string) (string, error) {
+	if reason, ok := rejected[tc.ID]; ok {
+		a.logger.Info("tool call rejected", "function", tc.Function.Name, "reason", reason)
+		if reason == "" {
+			return "tool call rejected by user", nil
+		}
+		return "tool call rejected by user: " + reason, nil
+	}
+	return a.executeToolCallWithTimeout(ctx, tc)
+}
+
+// findFunction returns the toolkit function with the given name, or nil.
+func (a *Agent) findFunction(name string) *toolkit.Function {
+	for _, tk := range a.Toolkits {
+		if fn, ok := tk.Functions()[name]; ok {
+			return fn
+		}
+	}
+	return nil
+}
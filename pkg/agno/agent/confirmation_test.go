@@ -0,0 +1,489 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/memory"
+	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/run"
+	"github.com/rexleimo/agno-go/pkg/agno/tools/toolkit"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// confirmToolkit registers "send", which requires confirmation, and "read",
+// which does not. Each call records its arguments.
+type confirmToolkit struct {
+	*toolkit.BaseToolkit
+	mu    sync.Mutex
+	calls map[string][]map[string]interface{}
+}
+
+func newConfirmToolkit() *confirmToolkit {
+	tk := &confirmToolkit{
+		BaseToolkit: toolkit.NewBaseToolkit("confirm"),
+		calls:       make(map[string][]map[string]interface{}),
+	}
+	for _, name := range []string{"send", "read"} {
+		name := name
+		tk.RegisterFunction(&toolkit.Function{
+			Name:                 name,
+			Description:          name,
+			RequiresConfirmation: name == "send",
+			Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+				tk.mu.Lock()
+				defer tk.mu.Unlock()
+				tk.calls[name] = append(tk.calls[name], args)
+				return name + " ok", nil
+			},
+		})
+	}
+	return tk
+}
+
+func (tk *confirmToolkit) callsTo(name string) []map[string]interface{} {
+	tk.mu.Lock()
+	defer tk.mu.Unlock()
+	return tk.calls[name]
+}
+
+// confirmModel asks for send and read once, then answers with the tool results it saw.
+func confirmModel() *MockModel {
+	return &MockModel{
+		BaseModel: models.BaseModel{ID: "test", Provider: "mock"},
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			var toolResults []string
+			for _, msg := range req.Messages {
+				if msg.Role == types.RoleTool {
+					toolResults = append(toolResults, msg.Content)
+				}
+			}
+			if len(toolResults) > 0 {
+				return &types.ModelResponse{Content: strings.Join(toolResults, "|")}, nil
+			}
+			return &types.ModelResponse{ToolCalls: []types.ToolCall{
+				{ID: "call_send", Type: "function", Function: types.ToolCallFunction{Name: "send", Arguments: `{"to":"alice"}`}},
+				{ID: "call_read", Type: "function", Function: types.ToolCallFunction{Name: "read", Arguments: `{}`}},
+			}}, nil
+		},
+	}
+}
+
+func newConfirmAgent(t *testing.T) (*Agent, *confirmToolkit) {
+	t.Helper()
+	tk := newConfirmToolkit()
+	ag, err := New(Config{
+		Name:     "ConfirmAgent",
+		Model:    confirmModel(),
+		Toolkits: []toolkit.Toolkit{tk},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	return ag, tk
+}
+
+func TestAgent_Run_PausesForConfirmation(t *testing.T) {
+	ag, tk := newConfirmAgent(t)
+
+	output, err := ag.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if output.Status != RunStatusPaused {
+		t.Fatalf("status = %s, want %s", output.Status, RunStatusPaused)
+	}
+	if len(output.PendingToolCalls) != 1 || output.PendingToolCalls[0].ID != "call_send" {
+		t.Fatalf("pending tool calls = %+v, want call_send only", output.PendingToolCalls)
+	}
+	if output.RunID == "" {
+		t.Fatal("paused output should carry a run ID")
+	}
+	if len(tk.callsTo("send")) != 0 || len(tk.callsTo("read")) != 0 {
+		t.Fatal("no tool should run before the decisions are given")
+	}
+}
+
+func TestAgent_ContinueRun_Approve(t *testing.T) {
+	ag, tk := newConfirmAgent(t)
+
+	paused, err := ag.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	output, err := ag.ContinueRun(context.Background(), paused.RunID, []ToolCallDecision{
+		{ToolCallID: "call_send", Action: ToolCallApprove},
+	})
+	if err != nil {
+		t.Fatalf("ContinueRun() error = %v", err)
+	}
+	if output.Status != RunStatusCompleted {
+		t.Fatalf("status = %s, want %s", output.Status, RunStatusCompleted)
+	}
+	if output.Content != `"send ok"|"read ok"` {
+		t.Fatalf("content = %q", output.Content)
+	}
+	if output.RunID != paused.RunID {
+		t.Fatalf("run ID = %s, want %s", output.RunID, paused.RunID)
+	}
+	if calls := tk.callsTo("send"); len(calls) != 1 || calls[0]["to"] != "alice" {
+		t.Fatalf("send calls = %v", calls)
+	}
+
+	if _, err := ag.ContinueRun(context.Background(), paused.RunID, nil); err == nil {
+		t.Fatal("expected error when continuing a finished run")
+	}
+}
+
+func TestAgent_ContinueRun_Reject(t *testing.T) {
+	ag, tk := newConfirmAgent(t)
+
+	paused, err := ag.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	output, err := ag.ContinueRun(context.Background(), paused.RunID, []ToolCallDecision{
+		{ToolCallID: "call_send", Action: ToolCallReject, Reason: "wrong recipient"},
+	})
+	if err != nil {
+		t.Fatalf("ContinueRun() error = %v", err)
+	}
+	if output.Content != `tool call rejected by user: wrong recipient|"read ok"` {
+		t.Fatalf("content = %q", output.Content)
+	}
+	if len(tk.callsTo("send")) != 0 {
+		t.Fatal("rejected tool call should not run")
+	}
+	if len(tk.callsTo("read")) != 1 {
+		t.Fatal("unconfirmed tool calls of the same turn should still run")
+	}
+}
+
+func TestAgent_ContinueRun_Edit(t *testing.T) {
+	ag, tk := newConfirmAgent(t)
+
+	paused, err := ag.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	output, err := ag.ContinueRun(context.Background(), paused.RunID, []ToolCallDecision{
+		{ToolCallID: "call_send", Action: ToolCallEdit, Arguments: map[string]interface{}{"to": "bob"}},
+	})
+	if err != nil {
+		t.Fatalf("ContinueRun() error = %v", err)
+	}
+	if calls := tk.callsTo("send"); len(calls) != 1 || calls[0]["to"] != "bob" {
+		t.Fatalf("send calls = %v, want edited arguments", calls)
+	}
+
+	for _, msg := range output.Messages {
+		for _, tc := range msg.ToolCalls {
+			if tc.ID == "call_send" && tc.Function.Arguments != `{"to":"bob"}` {
+				t.Fatalf("assistant message arguments = %s, want edited arguments", tc.Function.Arguments)
+			}
+		}
+	}
+}
+
+// copyingMemory stores copies of the messages, like a database-backed
+// memory, so changes to a message after Add do not reach the history.
+type copyingMemory struct {
+	*memory.InMemory
+}
+
+func (m copyingMemory) Add(message *types.Message, userID ...string) {
+	stored := *message
+	stored.ToolCalls = append([]types.ToolCall(nil), message.ToolCalls...)
+	m.InMemory.Add(&stored, userID...)
+}
+
+func TestAgent_ContinueRun_EditStoredInMemory(t *testing.T) {
+	tk := newConfirmToolkit()
+	ag, err := New(Config{
+		Name:     "ConfirmAgent",
+		Model:    confirmModel(),
+		Toolkits: []toolkit.Toolkit{tk},
+		Memory:   copyingMemory{memory.NewInMemory(100)},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	paused, err := ag.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if _, err := ag.ContinueRun(context.Background(), paused.RunID, []ToolCallDecision{
+		{ToolCallID: "call_send", Action: ToolCallEdit, Arguments: map[string]interface{}{"to": "bob"}},
+	}); err != nil {
+		t.Fatalf("ContinueRun() error = %v", err)
+	}
+
+	found := false
+	for _, msg := range ag.Memory.GetMessages(ag.UserID) {
+		for _, tc := range msg.ToolCalls {
+			if tc.ID == "call_send" {
+				found = true
+				if tc.Function.Arguments != `{"to":"bob"}` {
+					t.Fatalf("stored arguments = %s, want edited arguments", tc.Function.Arguments)
+				}
+			}
+		}
+	}
+	if !found {
+		t.Fatal("expected the assistant message in memory")
+	}
+}
+
+func TestAgent_ContinueRun_InvalidDecisions(t *testing.T) {
+	ag, tk := newConfirmAgent(t)
+
+	paused, err := ag.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		decisions []ToolCallDecision
+	}{
+		{"missing", nil},
+		{"not pending", []ToolCallDecision{
+			{ToolCallID: "call_send", Action: ToolCallApprove},
+			{ToolCallID: "call_read", Action: ToolCallApprove},
+		}},
+		{"duplicate", []ToolCallDecision{
+			{ToolCallID: "call_send", Action: ToolCallApprove},
+			{ToolCallID: "call_send", Action: ToolCallReject},
+		}},
+		{"edit without arguments", []ToolCallDecision{{ToolCallID: "call_send", Action: ToolCallEdit}}},
+		{"unknown action", []ToolCallDecision{{ToolCallID: "call_send", Action: "maybe"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ag.ContinueRun(context.Background(), paused.RunID, tt.decisions)
+			var agnoErr *types.AgnoError
+			if !errors.As(err, &agnoErr) || agnoErr.Code != types.ErrCodeInvalidInput {
+				t.Fatalf("expected invalid input error, got %v", err)
+			}
+		})
+	}
+
+	if _, err := ag.ContinueRun(context.Background(), "unknown-run", nil); err == nil {
+		t.Fatal("expected error for unknown run")
+	}
+
+	// Invalid decisions leave the run paused
+	if _, err := ag.ContinueRun(context.Background(), paused.RunID, []ToolCallDecision{
+		{ToolCallID: "call_send", Action: ToolCallApprove},
+	}); err != nil {
+		t.Fatalf("ContinueRun() error = %v", err)
+	}
+	if len(tk.callsTo("send")) != 1 {
+		t.Fatal("expected send to run after valid decisions")
+	}
+}
+
+func TestAgent_RunStream_PausesForConfirmation(t *testing.T) {
+	tk := newConfirmToolkit()
+	model := confirmModel()
+	model.InvokeStreamFunc = func(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
+		ch := make(chan types.ResponseChunk, 1)
+		ch <- types.ResponseChunk{ToolCalls: []types.ToolCall{
+			{ID: "call_send", Type: "function", Function: types.ToolCallFunction{Name: "send", Arguments: `{"to":"alice"}`}},
+		}}
+		close(ch)
+		return ch, nil
+	}
+	ag, err := New(Config{
+		Name:     "ConfirmStreamAgent",
+		Model:    model,
+		Toolkits: []toolkit.Toolkit{tk},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	result, err := ag.RunStream(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("RunStream() error = %v", err)
+	}
+	for range result.Events {
+	}
+	done := <-result.Done
+	if done.Err != nil {
+		t.Fatalf("RunStream() done error = %v", done.Err)
+	}
+	if done.Output.Status != RunStatusPaused || len(done.Output.PendingToolCalls) != 1 {
+		t.Fatalf("expected paused output with one pending call, got %+v", done.Output)
+	}
+	if len(tk.callsTo("send")) != 0 {
+		t.Fatal("send should not run before confirmation")
+	}
+
+	output, err := ag.ContinueRun(context.Background(), done.Output.RunID, []ToolCallDecision{
+		{ToolCallID: "call_send", Action: ToolCallApprove},
+	})
+	if err != nil {
+		t.Fatalf("ContinueRun() error = %v", err)
+	}
+	if output.Content != `"send ok"` {
+		t.Fatalf("content = %q", output.Content)
+	}
+}
+
+func TestAgent_Run_RefusedWhilePaused(t *testing.T) {
+	ag, tk := newConfirmAgent(t)
+
+	paused, err := ag.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	_, err = ag.Run(context.Background(), "again")
+	var pausedErr *PausedRunError
+	if !errors.As(err, &pausedErr) || pausedErr.RunID != paused.RunID {
+		t.Fatalf("expected PausedRunError for %s, got %v", paused.RunID, err)
+	}
+	if _, err := ag.RunStream(context.Background(), "again"); !errors.As(err, &pausedErr) {
+		t.Fatalf("expected RunStream to be refused, got %v", err)
+	}
+
+	if err := ag.CancelPausedRun(paused.RunID); err != nil {
+		t.Fatalf("CancelPausedRun() error = %v", err)
+	}
+	if err := ag.CancelPausedRun(paused.RunID); !errors.Is(err, ErrRunNotPaused) {
+		t.Fatalf("expected ErrRunNotPaused when cancelling a run that is no longer paused, got %v", err)
+	}
+	if _, err := ag.ContinueRun(context.Background(), paused.RunID, nil); err == nil {
+		t.Fatal("expected error when continuing a cancelled run")
+	}
+
+	// Both tool calls of the paused turn are answered, so the history is valid
+	answered := 0
+	for _, msg := range ag.Memory.GetMessages(ag.UserID) {
+		if msg.Role == types.RoleTool && strings.Contains(msg.Content, "was cancelled") {
+			answered++
+		}
+	}
+	if answered != 2 {
+		t.Fatalf("expected 2 cancelled tool results in memory, got %d", answered)
+	}
+
+	output, err := ag.Run(context.Background(), "again")
+	if err != nil {
+		t.Fatalf("Run() after cancel error = %v", err)
+	}
+	if output.Status != RunStatusCompleted {
+		t.Fatalf("status = %s, want %s", output.Status, RunStatusCompleted)
+	}
+	if len(tk.callsTo("send")) != 0 || len(tk.callsTo("read")) != 0 {
+		t.Fatal("cancelled tool calls must not run")
+	}
+}
+
+func TestAgent_PausedRunScopedToUserAndSession(t *testing.T) {
+	var requests [][]*types.Message
+	model := &MockModel{
+		BaseModel: models.BaseModel{ID: "test", Provider: "mock"},
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			requests = append(requests, req.Messages)
+			if last := req.Messages[len(req.Messages)-1]; last.Role == types.RoleUser && last.Content == "go" {
+				return &types.ModelResponse{ToolCalls: []types.ToolCall{
+					{ID: "call_send", Type: "function", Function: types.ToolCallFunction{Name: "send", Arguments: `{}`}},
+				}}, nil
+			}
+			return &types.ModelResponse{Content: "done"}, nil
+		},
+	}
+	ag, err := New(Config{Name: "ConfirmAgent", Model: model, Toolkits: []toolkit.Toolkit{newConfirmToolkit()}})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+	scope := func(userID, sessionID string) context.Context {
+		return run.WithContext(context.Background(), &run.RunContext{UserID: userID, SessionID: sessionID})
+	}
+
+	paused, err := ag.Run(scope("u1", "s1"), "go")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var pausedErr *PausedRunError
+	if _, err := ag.Run(scope("u1", "s1"), "again"); !errors.As(err, &pausedErr) {
+		t.Fatalf("expected the same session to be refused, got %v", err)
+	}
+
+	output, err := ag.Run(scope("u1", "s2"), "hello")
+	if err != nil {
+		t.Fatalf("Run() in another session error = %v", err)
+	}
+	if output.Status != RunStatusCompleted {
+		t.Fatalf("status = %s, want %s", output.Status, RunStatusCompleted)
+	}
+	for _, msg := range requests[len(requests)-1] {
+		if len(msg.ToolCalls) > 0 || msg.Role == types.RoleTool {
+			t.Fatalf("request of another session should not carry the paused tool call: %+v", msg)
+		}
+	}
+
+	if _, err := ag.ContinueRun(context.Background(), paused.RunID, []ToolCallDecision{
+		{ToolCallID: "call_send", Action: ToolCallApprove},
+	}); err != nil {
+		t.Fatalf("ContinueRun() error = %v", err)
+	}
+	messages := requests[len(requests)-1]
+	for i, msg := range messages {
+		if len(msg.ToolCalls) == 0 {
+			continue
+		}
+		if i+1 >= len(messages) || messages[i+1].ToolCallID != "call_send" {
+			t.Fatalf("tool result should follow its call, got %+v", messages)
+		}
+	}
+}
+
+func TestAgent_PausedRunExpires(t *testing.T) {
+	ag, _ := newConfirmAgent(t)
+	ag.pausedRunTTL = time.Millisecond
+
+	paused, err := ag.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := ag.ContinueRun(context.Background(), paused.RunID, []ToolCallDecision{
+		{ToolCallID: "call_send", Action: ToolCallApprove},
+	}); err == nil {
+		t.Fatal("expected error when continuing an expired run")
+	}
+	if _, err := ag.Run(context.Background(), "again"); err != nil {
+		t.Fatalf("Run() after expiry error = %v", err)
+	}
+}
+
+func TestRunOutput_PausedError(t *testing.T) {
+	if err := (&RunOutput{Status: RunStatusCompleted}).PausedError(); err != nil {
+		t.Fatalf("PausedError() = %v, want nil", err)
+	}
+
+	output := &RunOutput{
+		RunID:  "run-1",
+		Status: RunStatusPaused,
+		PendingToolCalls: []types.ToolCall{
+			{ID: "call_send", Function: types.ToolCallFunction{Name: "send"}},
+		},
+	}
+	var pausedErr *PausedRunError
+	if err := output.PausedError(); !errors.As(err, &pausedErr) || !strings.Contains(err.Error(), "send") {
+		t.Fatalf("PausedError() = %v, want PausedRunError naming send", err)
+	}
+}
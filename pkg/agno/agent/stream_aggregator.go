@@ -8,8 +8,9 @@ import (
 
 // AggregateResponseStream consumes a stream of ResponseChunk values and
 // reconstructs a single ModelResponse. It concatenates content in arrival
-// order and aggregates any tool calls. If a chunk carries a non-nil Error,
-// aggregation stops and the error is returned.
+// order and aggregates any tool calls, merging argument fragments streamed
+// across several chunks. If a chunk carries a non-nil Error, aggregation
+// stops and the error is returned.
 //
 // This helper is intended for future streaming Agent implementations so that
 // the final assistant message is always bound to a concrete ModelResponse
@@ -36,9 +37,50 @@ func AggregateResponseStream(ctx context.Context, ch <-chan types.ResponseChunk)
 			if chunk.Content != "" {
 				resp.Content += chunk.Content
 			}
-			if len(chunk.ToolCalls) > 0 {
-				resp.ToolCalls = append(resp.ToolCalls, chunk.ToolCalls...)
+			for _, tc := range chunk.ToolCalls {
+				resp.ToolCalls = mergeToolCallDelta(resp.ToolCalls, tc)
 			}
 		}
 	}
 }
+
+// mergeToolCallDelta folds a streamed tool-call fragment into calls.
+// Providers send the call ID and name with the first fragment and only
+// argument text afterwards, so a fragment without an ID continues the most
+// recent call while a known ID continues that call.
+func mergeToolCallDelta(calls []types.ToolCall, delta types.ToolCall) []types.ToolCall {
+	target := -1
+	if delta.ID != "" {
+		for i := range calls {
+			if calls[i].ID == delta.ID {
+				target = i
+				break
+			}
+		}
+	} else if len(calls) > 0 {
+		target = len(calls) - 1
+	}
+
+	if target < 0 {
+		if delta.Type == "" {
+			delta.Type = "function"
+		}
+		return append(calls, delta)
+	}
+
+	call := &calls[target]
+	if call.Function.Name == "" {
+		call.Function.Name = delta.Function.Name
+	}
+	if call.Type == "" {
+		call.Type = delta.Type
+	}
+	call.Function.Arguments += delta.Function.Arguments
+	for k, v := range delta.Metadata {
+		if call.Metadata == nil {
+			call.Metadata = make(map[string]interface{})
+		}
+		call.Metadata[k] = v
+	}
+	return calls
+}
@@ -68,6 +68,37 @@ func TestAggregateResponseStream_AggregatesToolCalls(t *testing.T) {
 	}
 }
 
+func TestAggregateResponseStream_MergesToolCallFragments(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	ch := make(chan types.ResponseChunk, 3)
+	ch <- types.ResponseChunk{ToolCalls: []types.ToolCall{
+		{ID: "tc1", Function: types.ToolCallFunction{Name: "tool_one", Arguments: `{"q":`}},
+	}}
+	ch <- types.ResponseChunk{ToolCalls: []types.ToolCall{
+		{Function: types.ToolCallFunction{Arguments: `"go"}`}},
+	}}
+	ch <- types.ResponseChunk{ToolCalls: []types.ToolCall{
+		{ID: "tc2", Function: types.ToolCallFunction{Name: "tool_two", Arguments: `{}`}},
+	}}
+	close(ch)
+
+	resp, err := AggregateResponseStream(ctx, ch)
+	if err != nil {
+		t.Fatalf("AggregateResponseStream returned error: %v", err)
+	}
+	if len(resp.ToolCalls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %d", len(resp.ToolCalls))
+	}
+	if resp.ToolCalls[0].Function.Arguments != `{"q":"go"}` {
+		t.Fatalf("unexpected merged arguments: %q", resp.ToolCalls[0].Function.Arguments)
+	}
+	if resp.ToolCalls[0].Type != "function" {
+		t.Fatalf("expected default type function, got %q", resp.ToolCalls[0].Type)
+	}
+}
+
 func TestAggregateResponseStream_StopsOnErrorChunk(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
@@ -0,0 +1,181 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/schema"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+const defaultOutputSchemaRetries = 2
+
+// outputSchema holds the compiled form of Config.OutputSchema.
+// outputSchema 保存 Config.OutputSchema 的编译结果
+type outputSchema struct {
+	schema map[string]interface{}
+	goType reflect.Type // nil when the schema was given as a raw JSON schema
+}
+
+// newOutputSchema accepts a JSON schema map, a reflect.Type, or any Go value
+// (typically a struct or a pointer to one) whose type describes the output.
+func newOutputSchema(v interface{}) (*outputSchema, error) {
+	switch s := v.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		if len(s) == 0 {
+			return nil, fmt.Errorf("output schema is empty")
+		}
+		return &outputSchema{schema: s}, nil
+	case reflect.Type:
+		return outputSchemaFromType(s)
+	default:
+		return outputSchemaFromType(reflect.TypeOf(v))
+	}
+}
+
+func outputSchemaFromType(t reflect.Type) (*outputSchema, error) {
+	if t == nil {
+		return nil, fmt.Errorf("output schema type is nil")
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	s, err := schema.FromType(t)
+	if err != nil {
+		return nil, err
+	}
+	return &outputSchema{schema: s, goType: t}, nil
+}
+
+// responseFormat returns the ResponseFormat sent to the model.
+func (s *outputSchema) responseFormat() *models.ResponseFormat {
+	if s == nil {
+		return nil
+	}
+	name := ""
+	if s.goType != nil {
+		name = s.goType.Name()
+	}
+	return &models.ResponseFormat{
+		Type:   models.ResponseFormatJSONSchema,
+		Name:   name,
+		Schema: s.schema,
+	}
+}
+
+// parse extracts the JSON document from content, validates it against the
+// schema and decodes it. For Go types the result is a pointer to a new value
+// of that type; for raw schemas it is the decoded JSON value.
+// parse 从模型回复中提取 JSON，校验并解码
+func (s *outputSchema) parse(content string) (interface{}, error) {
+	raw := extractJSON(content)
+	if raw == "" {
+		return nil, fmt.Errorf("response does not contain a JSON value")
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		return nil, fmt.Errorf("response is not valid JSON: %w", err)
+	}
+	if err := schema.Validate(s.schema, decoded); err != nil {
+		return nil, err
+	}
+	if s.goType == nil {
+		return decoded, nil
+	}
+
+	target := reflect.New(s.goType)
+	if err := json.Unmarshal([]byte(raw), target.Interface()); err != nil {
+		return nil, fmt.Errorf("cannot decode response into %s: %w", s.goType, err)
+	}
+	return target.Interface(), nil
+}
+
+// takeOutputToolCall moves a call of the synthetic output tool, which
+// providers use to force schema-constrained replies, into the reply content so
+// it is never executed as a regular tool.
+func (s *outputSchema) takeOutputToolCall(resp *types.ModelResponse) {
+	if s == nil || resp == nil {
+		return
+	}
+	name := s.responseFormat().SchemaName()
+	for i, tc := range resp.ToolCalls {
+		if tc.Function.Name != name {
+			continue
+		}
+		resp.Content = tc.Function.Arguments
+		resp.ToolCalls = append(resp.ToolCalls[:i:i], resp.ToolCalls[i+1:]...)
+		if len(resp.ToolCalls) == 0 {
+			resp.ToolCalls = nil
+		}
+		return
+	}
+}
+
+// acceptStructured parses a final reply against the output schema. While
+// retries remain, an invalid reply is answered in Memory with a corrective
+// prompt and retry is true; afterwards an OutputCheckError is returned.
+// acceptStructured 解析结构化回复，无效时在重试次数内追加纠正提示
+func (a *Agent) acceptStructured(content string, retries *int) (parsed interface{}, retry bool, err error) {
+	parsed, parseErr := a.outputSchema.parse(content)
+	if parseErr == nil {
+		return parsed, false, nil
+	}
+	if *retries >= a.outputSchemaRetries {
+		a.logger.Error("structured output invalid", "error", parseErr)
+		return nil, false, types.NewOutputCheckError("structured output validation failed", parseErr)
+	}
+	*retries++
+	a.logger.Warn("structured output invalid, retrying", "attempt", *retries, "error", parseErr)
+	a.Memory.Add(types.NewUserMessage(a.outputSchema.retryPrompt(parseErr)), a.UserID)
+	return nil, true, nil
+}
+
+// retryPrompt builds the corrective message sent after an invalid reply.
+func (s *outputSchema) retryPrompt(err error) string {
+	schemaJSON, _ := json.Marshal(s.schema)
+	return fmt.Sprintf(
+		"Your previous response could not be accepted: %v\nReply again with only a JSON value that matches this JSON schema, without any other text:\n%s",
+		err, schemaJSON,
+	)
+}
+
+// extractJSON returns the JSON document contained in a model reply, removing
+// Markdown code fences and any surrounding prose.
+func extractJSON(content string) string {
+	text := strings.TrimSpace(content)
+
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```")
+		if nl := strings.IndexByte(text, '\n'); nl >= 0 {
+			text = text[nl+1:] // drop the language tag line
+		}
+		if end := strings.LastIndex(text, "```"); end >= 0 {
+			text = text[:end]
+		}
+		text = strings.TrimSpace(text)
+	}
+
+	if text == "" || text[0] == '{' || text[0] == '[' {
+		return text
+	}
+
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return ""
+	}
+	closing := "}"
+	if text[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(text, closing)
+	if end < start {
+		return ""
+	}
+	return text[start : end+1]
+}
@@ -0,0 +1,291 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rexleimo/agno-go/pkg/agno/memory"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+const (
+	defaultMemoryTable       = "memory_messages"
+	defaultMemoryMaxMessages = 100
+	defaultMemoryTimeout     = 5 * time.Second
+)
+
+// MemoryConfig 控制 Postgres 对话记忆行为
+// MemoryConfig controls the Postgres conversation memory.
+type MemoryConfig struct {
+	// Schema 默认 public
+	Schema string
+	// Table 默认 memory_messages
+	Table string
+	// OperationTimeout 限制单次查询时间（默认 5s）
+	OperationTimeout time.Duration
+	// MaxMessages 限制 GetMessages 返回的消息数（默认 100），所有消息仍会保存
+	// MaxMessages limits the history returned by GetMessages; all messages stay stored.
+	MaxMessages int
+	// Logger 记录 memory.Memory 方法中无法返回的错误
+	Logger *slog.Logger
+}
+
+// Memory 将对话历史持久化到 Postgres，通过 memory.StoreMemory 实现 memory.Memory。
+// Memory persists conversation history in Postgres and implements memory.Memory
+// through memory.StoreMemory. Messages are stored as JSONB so tool calls,
+// reasoning content and metadata round-trip unchanged.
+type Memory struct {
+	*memory.StoreMemory
+
+	db            *sql.DB
+	tableName     string
+	indexName     string
+	roleIndexName string
+	timeout       time.Duration
+}
+
+// NewMemory 创建 Postgres 对话记忆；表可通过 CreateTable 或迁移脚本创建。
+// NewMemory creates a Postgres conversation memory. Create the table with
+// CreateTable or scripts/init-db.sql.
+func NewMemory(db *sql.DB, cfg MemoryConfig) (*Memory, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+
+	table := cfg.Table
+	if table == "" {
+		table = defaultMemoryTable
+	}
+	tableName, err := buildQualifiedName(cfg.Schema, table)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := cfg.OperationTimeout
+	if timeout <= 0 {
+		timeout = defaultMemoryTimeout
+	}
+	maxMessages := cfg.MaxMessages
+	if maxMessages <= 0 {
+		maxMessages = defaultMemoryMaxMessages
+	}
+
+	m := &Memory{
+		db:            db,
+		tableName:     tableName,
+		indexName:     fmt.Sprintf(`"idx_%s_user_id"`, table),
+		roleIndexName: fmt.Sprintf(`"idx_%s_user_role"`, table),
+		timeout:       timeout,
+	}
+	m.StoreMemory = memory.NewStoreMemory(m, maxMessages, cfg.Logger)
+	return m, nil
+}
+
+// CreateTable 创建消息表和索引（若不存在）。
+// CreateTable creates the message table and its index if they do not exist.
+func (m *Memory) CreateTable(ctx context.Context) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id BIGSERIAL PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		message_id TEXT NOT NULL,
+		role TEXT NOT NULL,
+		data JSONB NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`, m.tableName)
+	if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+		return err
+	}
+
+	indexes := []string{
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (user_id, id)`, m.indexName, m.tableName),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (user_id, role, id)`, m.roleIndexName, m.tableName),
+	}
+	for _, index := range indexes {
+		if _, err := m.db.ExecContext(ctx, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Append 保存用户的一条消息。
+// Append stores a message for the user.
+func (m *Memory) Append(ctx context.Context, userID string, message *types.Message) error {
+	if message == nil {
+		return fmt.Errorf("message cannot be nil")
+	}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	if message.ID == "" {
+		message.ID = "msg-" + uuid.NewString()
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, message_id, role, data, created_at)
+		VALUES ($1, $2, $3, $4, $5)`, m.tableName)
+	_, err = m.db.ExecContext(ctx, query,
+		memory.ResolveUserID(userID),
+		message.ID,
+		string(message.Role),
+		data,
+		time.Now().UTC(),
+	)
+	return err
+}
+
+// Load 按写入顺序返回用户的所有消息。
+// Load returns every stored message of the user in insertion order.
+func (m *Memory) Load(ctx context.Context, userID string) ([]*types.Message, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT data FROM %s WHERE user_id = $1 ORDER BY id`, m.tableName)
+	rows, err := m.db.QueryContext(ctx, query, memory.ResolveUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+	return scanMessages(rows)
+}
+
+// Recent 按写入顺序返回用户的系统消息和最近 limit 条其他消息，查询走索引。
+// Recent returns the user's system messages and at most limit of the most
+// recent other messages, in insertion order. Both parts are read through
+// indexes, so the cost does not grow with the stored history.
+func (m *Memory) Recent(ctx context.Context, userID string, limit int) ([]*types.Message, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT data FROM %[1]s WHERE user_id = $1 AND (role = $2 OR id IN (
+		SELECT id FROM %[1]s WHERE user_id = $1 AND role <> $2 ORDER BY id DESC LIMIT $3
+	)) ORDER BY id`, m.tableName)
+	rows, err := m.db.QueryContext(ctx, query, memory.ResolveUserID(userID), string(types.RoleSystem), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanMessages(rows)
+}
+
+// ReplaceToolCall 替换用户最新一条包含该工具调用的助手消息中的工具调用。
+// ReplaceToolCall replaces the tool call with the same ID in the newest
+// assistant message of the user that carries it.
+func (m *Memory) ReplaceToolCall(ctx context.Context, userID string, toolCall types.ToolCall) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT id, data FROM %s WHERE user_id = $1 AND role = $2 AND strpos(data::text, $3) > 0
+		ORDER BY id DESC`, m.tableName)
+	rows, err := m.db.QueryContext(ctx, query,
+		memory.ResolveUserID(userID), string(types.RoleAssistant), toolCall.ID)
+	if err != nil {
+		return err
+	}
+	id, data, err := findToolCall(rows, toolCall)
+	if err != nil {
+		return err
+	}
+
+	update := fmt.Sprintf(`UPDATE %s SET data = $1 WHERE id = $2`, m.tableName)
+	_, err = m.db.ExecContext(ctx, update, data, id)
+	return err
+}
+
+// Delete 删除用户的所有消息。
+// Delete removes the stored messages of the user.
+func (m *Memory) Delete(ctx context.Context, userID string) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1`, m.tableName)
+	_, err := m.db.ExecContext(ctx, query, memory.ResolveUserID(userID))
+	return err
+}
+
+// DeleteAll 删除所有用户的消息。
+// DeleteAll removes the stored messages of every user.
+func (m *Memory) DeleteAll(ctx context.Context) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	_, err := m.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s`, m.tableName))
+	return err
+}
+
+func (m *Memory) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, m.timeout)
+}
+
+// findToolCall 返回第一条包含该工具调用的行的 id 及修改后的数据。
+// findToolCall returns the id and the edited data of the first row whose
+// message carries the tool call.
+func findToolCall(rows *sql.Rows, toolCall types.ToolCall) (int64, []byte, error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id   int64
+			data []byte
+		)
+		if err := rows.Scan(&id, &data); err != nil {
+			return 0, nil, err
+		}
+		msg := &types.Message{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			return 0, nil, fmt.Errorf("failed to decode message: %w", err)
+		}
+		if !memory.SetToolCall(msg, toolCall) {
+			continue
+		}
+		edited, err := json.Marshal(msg)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to encode message: %w", err)
+		}
+		return id, edited, nil
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	return 0, nil, memory.ErrToolCallNotFound
+}
+
+func scanMessages(rows *sql.Rows) ([]*types.Message, error) {
+	defer rows.Close()
+
+	messages := make([]*types.Message, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		msg := &types.Message{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			return nil, fmt.Errorf("failed to decode message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+var (
+	_ memory.Memory        = (*Memory)(nil)
+	_ memory.MessageStore  = (*Memory)(nil)
+	_ memory.ToolCallStore = (*Memory)(nil)
+)
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// captureArg records the value passed for a query argument
+type captureArg struct {
+	value []byte
+}
+
+func (c *captureArg) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	c.value = b
+	return ok
+}
+
+func TestNewMemory_InvalidTable(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	defer db.Close()
+
+	if _, err := NewMemory(db, MemoryConfig{Table: "bad-name;"}); err == nil {
+		t.Fatal("expected invalid table name error")
+	}
+}
+
+func TestMemory_CreateTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	defer db.Close()
+
+	mem, err := NewMemory(db, MemoryConfig{Schema: "agno"})
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "agno"."memory_messages"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE INDEX IF NOT EXISTS "idx_memory_messages_user_id" ON "agno"."memory_messages"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE INDEX IF NOT EXISTS "idx_memory_messages_user_role" ON "agno"."memory_messages" (user_id, role, id)`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := mem.CreateTable(context.Background()); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("not all expectations met: %v", err)
+	}
+}
+
+func TestMemory_AddAndGetMessagesRoundTrip(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	defer db.Close()
+
+	mem, err := NewMemory(db, MemoryConfig{})
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+
+	msg := &types.Message{
+		ID:   "msg-1",
+		Role: types.RoleAssistant,
+		ToolCalls: []types.ToolCall{{
+			ID:       "call_1",
+			Type:     "function",
+			Function: types.ToolCallFunction{Name: "lookup", Arguments: `{"id":7}`},
+		}},
+		Metadata:         map[string]interface{}{"source": "test"},
+		ReasoningContent: &types.ReasoningContent{Content: "reasoning"},
+	}
+
+	data := &captureArg{}
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "public"."memory_messages"`)).
+		WithArgs("alice", "msg-1", "assistant", data, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mem.Add(msg, "alice")
+	if len(data.value) == 0 {
+		t.Fatal("message data was not written")
+	}
+
+	mock.ExpectQuery(`SELECT data FROM "public"."memory_messages" WHERE user_id = \$1 AND \(role = \$2 OR id IN \(\s*SELECT id FROM "public"."memory_messages" WHERE user_id = \$1 AND role <> \$2 ORDER BY id DESC LIMIT \$3`).
+		WithArgs("alice", "system", defaultMemoryMaxMessages).
+		WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow(data.value))
+
+	got := mem.GetMessages("alice")
+	if len(got) != 1 || !reflect.DeepEqual(got[0], msg) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, msg)
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "public"."memory_messages" WHERE user_id = $1`)).
+		WithArgs("default").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mem.Clear()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("not all expectations met: %v", err)
+	}
+}
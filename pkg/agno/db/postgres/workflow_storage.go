@@ -0,0 +1,644 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/workflow"
+)
+
+const (
+	defaultWorkflowTablePrefix = "workflow"
+	defaultWorkflowTimeout     = 5 * time.Second
+)
+
+// WorkflowStorageConfig 控制 Postgres 工作流存储行为
+// WorkflowStorageConfig controls the Postgres workflow storage.
+type WorkflowStorageConfig struct {
+	// Schema 默认 public
+	Schema string
+	// TablePrefix 决定表名 <prefix>_sessions、<prefix>_runs、<prefix>_cancellations（默认 workflow）
+	// TablePrefix names the tables <prefix>_sessions, <prefix>_runs and <prefix>_cancellations.
+	TablePrefix string
+	// OperationTimeout 限制单次操作时间（默认 5s）
+	OperationTimeout time.Duration
+}
+
+// WorkflowStorage 将工作流会话、运行记录与取消记录持久化到 Postgres。
+// WorkflowStorage persists workflow sessions, their runs (including messages,
+// events and resume data) and cancellation records in Postgres. It implements
+// workflow.WorkflowStorage, workflow.StorageStats, workflow.SessionQuerier,
+// workflow.RunLookup and workflow.RunStore.
+type WorkflowStorage struct {
+	db                *sql.DB
+	prefix            string
+	sessionTable      string
+	runTable          string
+	cancellationTable string
+	timeout           time.Duration
+}
+
+// NewWorkflowStorage 创建 Postgres 工作流存储；表可通过 CreateTables 创建。
+// NewWorkflowStorage creates a Postgres workflow storage. Create the tables
+// with CreateTables.
+func NewWorkflowStorage(db *sql.DB, cfg WorkflowStorageConfig) (*WorkflowStorage, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+
+	prefix := cfg.TablePrefix
+	if prefix == "" {
+		prefix = defaultWorkflowTablePrefix
+	}
+	sessionTable, err := buildQualifiedName(cfg.Schema, prefix+"_sessions")
+	if err != nil {
+		return nil, err
+	}
+	runTable, _ := buildQualifiedName(cfg.Schema, prefix+"_runs")
+	cancellationTable, _ := buildQualifiedName(cfg.Schema, prefix+"_cancellations")
+
+	timeout := cfg.OperationTimeout
+	if timeout <= 0 {
+		timeout = defaultWorkflowTimeout
+	}
+
+	return &WorkflowStorage{
+		db:                db,
+		prefix:            prefix,
+		sessionTable:      sessionTable,
+		runTable:          runTable,
+		cancellationTable: cancellationTable,
+		timeout:           timeout,
+	}, nil
+}
+
+// CreateTables 创建工作流表和索引（若不存在）。
+// CreateTables creates the workflow tables and indexes if they do not exist.
+func (s *WorkflowStorage) CreateTables(ctx context.Context) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			session_id TEXT PRIMARY KEY,
+			workflow_id TEXT NOT NULL,
+			user_id TEXT NOT NULL DEFAULT '',
+			metadata JSONB,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		)`, s.sessionTable),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS "idx_%s_sessions_workflow" ON %s (workflow_id, created_at)`, s.prefix, s.sessionTable),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS "idx_%s_sessions_user" ON %s (user_id, created_at)`, s.prefix, s.sessionTable),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			session_id TEXT NOT NULL REFERENCES %s (session_id) ON DELETE CASCADE,
+			seq INTEGER NOT NULL,
+			run_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			duration_ns BIGINT,
+			data JSONB NOT NULL,
+			PRIMARY KEY (session_id, seq)
+		)`, s.runTable, s.sessionTable),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS "idx_%s_runs_run_id" ON %s (run_id)`, s.prefix, s.runTable),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			session_id TEXT NOT NULL REFERENCES %s (session_id) ON DELETE CASCADE,
+			seq INTEGER NOT NULL,
+			run_id TEXT NOT NULL,
+			data JSONB NOT NULL,
+			PRIMARY KEY (session_id, seq)
+		)`, s.cancellationTable, s.sessionTable),
+	}
+	for _, stmt := range statements {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateSession 实现 workflow.WorkflowStorage。
+// CreateSession implements workflow.WorkflowStorage.
+func (s *WorkflowStorage) CreateSession(ctx context.Context, sessionID, workflowID, userID string) (*workflow.WorkflowSession, error) {
+	if sessionID == "" {
+		return nil, workflow.ErrInvalidSessionID
+	}
+	if workflowID == "" {
+		return nil, workflow.ErrInvalidWorkflowID
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	session := workflow.NewWorkflowSession(sessionID, workflowID, userID)
+	metadata, err := json.Marshal(session.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (session_id, workflow_id, user_id, metadata, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (session_id) DO NOTHING`, s.sessionTable)
+	result, err := s.db.ExecContext(ctx, query, sessionID, workflowID, userID, string(metadata),
+		session.CreatedAt.UTC(), session.UpdatedAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return nil, workflow.ErrSessionExists
+	}
+	return session, nil
+}
+
+// GetSession 实现 workflow.WorkflowStorage。
+// GetSession implements workflow.WorkflowStorage.
+func (s *WorkflowStorage) GetSession(ctx context.Context, sessionID string) (*workflow.WorkflowSession, error) {
+	if sessionID == "" {
+		return nil, workflow.ErrInvalidSessionID
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	sessions, err := s.querySessions(ctx, `WHERE session_id = $1`, []interface{}{sessionID}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, workflow.ErrSessionNotFound
+	}
+	return sessions[0], nil
+}
+
+// UpdateSession 实现 workflow.WorkflowStorage，运行与取消记录整体替换。
+// UpdateSession implements workflow.WorkflowStorage. Runs and cancellation
+// records are replaced with the session's current lists.
+func (s *WorkflowStorage) UpdateSession(ctx context.Context, session *workflow.WorkflowSession) error {
+	if session == nil || session.SessionID == "" {
+		return workflow.ErrInvalidSessionID
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	session.UpdatedAt = time.Now()
+	metadata, err := json.Marshal(session.Metadata)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET workflow_id = $1, user_id = $2, metadata = $3, updated_at = $4
+		WHERE session_id = $5`, s.sessionTable),
+		session.WorkflowID, session.UserID, string(metadata), session.UpdatedAt.UTC(), session.SessionID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return workflow.ErrSessionNotFound
+	}
+
+	for _, table := range []string{s.runTable, s.cancellationTable} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE session_id = $1`, table), session.SessionID); err != nil {
+			return err
+		}
+	}
+
+	runQuery := fmt.Sprintf(`INSERT INTO %s (session_id, seq, run_id, status, duration_ns, data)
+		VALUES ($1, $2, $3, $4, $5, $6)`, s.runTable)
+	for i, run := range session.GetRuns() {
+		if run == nil {
+			continue
+		}
+		data, err := json.Marshal(run)
+		if err != nil {
+			return fmt.Errorf("failed to encode run %s: %w", run.RunID, err)
+		}
+		var duration sql.NullInt64
+		if run.IsCompleted() {
+			duration = sql.NullInt64{Int64: int64(run.Duration()), Valid: true}
+		}
+		if _, err := tx.ExecContext(ctx, runQuery, session.SessionID, i, run.RunID, string(run.Status), duration, string(data)); err != nil {
+			return err
+		}
+	}
+
+	cancelQuery := fmt.Sprintf(`INSERT INTO %s (session_id, seq, run_id, data) VALUES ($1, $2, $3, $4)`, s.cancellationTable)
+	for i, record := range session.GetCancellations() {
+		if record == nil {
+			continue
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to encode cancellation for run %s: %w", record.RunID, err)
+		}
+		if _, err := tx.ExecContext(ctx, cancelQuery, session.SessionID, i, record.RunID, string(data)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteSession 实现 workflow.WorkflowStorage。
+// DeleteSession implements workflow.WorkflowStorage.
+func (s *WorkflowStorage) DeleteSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return workflow.ErrInvalidSessionID
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE session_id = $1`, s.sessionTable), sessionID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return workflow.ErrSessionNotFound
+	}
+	return nil
+}
+
+// ListSessions 实现 workflow.WorkflowStorage。
+// ListSessions implements workflow.WorkflowStorage.
+func (s *WorkflowStorage) ListSessions(ctx context.Context, workflowID string, limit, offset int) ([]*workflow.WorkflowSession, error) {
+	if workflowID == "" {
+		return nil, workflow.ErrInvalidWorkflowID
+	}
+	return s.QuerySessions(ctx, workflow.SessionFilter{WorkflowID: workflowID, Limit: limit, Offset: offset})
+}
+
+// ListUserSessions 实现 workflow.WorkflowStorage。
+// ListUserSessions implements workflow.WorkflowStorage.
+func (s *WorkflowStorage) ListUserSessions(ctx context.Context, userID string, limit, offset int) ([]*workflow.WorkflowSession, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	return s.querySessions(ctx, `WHERE user_id = $1`, []interface{}{userID}, limit, offset)
+}
+
+// QuerySessions 实现 workflow.SessionQuerier。
+// QuerySessions implements workflow.SessionQuerier.
+func (s *WorkflowStorage) QuerySessions(ctx context.Context, filter workflow.SessionFilter) ([]*workflow.WorkflowSession, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var (
+		clauses []string
+		args    []interface{}
+	)
+	add := func(clause string, arg interface{}) {
+		args = append(args, arg)
+		clauses = append(clauses, fmt.Sprintf(clause, len(args)))
+	}
+	if filter.WorkflowID != "" {
+		add("workflow_id = $%d", filter.WorkflowID)
+	}
+	if filter.UserID != "" {
+		add("user_id = $%d", filter.UserID)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		add("created_at > $%d", filter.CreatedAfter.UTC())
+	}
+	if !filter.CreatedBefore.IsZero() {
+		add("created_at < $%d", filter.CreatedBefore.UTC())
+	}
+
+	where := ""
+	if len(clauses) > 0 {
+		where = "WHERE " + strings.Join(clauses, " AND ")
+	}
+	return s.querySessions(ctx, where, args, filter.Limit, filter.Offset)
+}
+
+// GetSessionByRunID 实现 workflow.RunLookup。
+// GetSessionByRunID implements workflow.RunLookup.
+func (s *WorkflowStorage) GetSessionByRunID(ctx context.Context, runID string) (*workflow.WorkflowSession, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	lookupCtx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var sessionID string
+	err := s.db.QueryRowContext(lookupCtx, fmt.Sprintf(`SELECT session_id FROM %s WHERE run_id = $1 LIMIT 1`, s.runTable), runID).
+		Scan(&sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, workflow.ErrRunNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetSession(ctx, sessionID)
+}
+
+// SaveRun 实现 workflow.RunStore，只写入给定的运行，检查点不会重写会话中的其他运行。
+// SaveRun implements workflow.RunStore. It writes only the given run, so
+// checkpoints do not rewrite the other runs of the session.
+func (s *WorkflowStorage) SaveRun(ctx context.Context, sessionID string, run *workflow.WorkflowRun) error {
+	if sessionID == "" {
+		return workflow.ErrInvalidSessionID
+	}
+	if run == nil || run.RunID == "" {
+		return workflow.ErrRunNotFound
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	revision := run.Revision
+	run.Revision++
+	data, err := json.Marshal(run)
+	if err != nil {
+		run.Revision = revision
+		return fmt.Errorf("failed to encode run %s: %w", run.RunID, err)
+	}
+	if err := s.saveRun(ctx, sessionID, run, revision, string(data)); err != nil {
+		run.Revision = revision
+		return err
+	}
+	return nil
+}
+
+func (s *WorkflowStorage) saveRun(ctx context.Context, sessionID string, run *workflow.WorkflowRun, revision int64, data string) error {
+	var duration sql.NullInt64
+	if run.IsCompleted() {
+		duration = sql.NullInt64{Int64: int64(run.Duration()), Valid: true}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// 先更新会话行，持有行锁以串行化同一会话的保存
+	// Updating the session row first locks it, serializing saves within the session
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET updated_at = $1 WHERE session_id = $2`, s.sessionTable),
+		time.Now().UTC(), sessionID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return workflow.ErrSessionNotFound
+	}
+
+	result, err = tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET status = $1, duration_ns = $2, data = $3
+		WHERE session_id = $4 AND run_id = $5 AND COALESCE((data->>'revision')::BIGINT, 0) = $6`, s.runTable),
+		string(run.Status), duration, data, sessionID, run.RunID, revision)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE session_id = $1 AND run_id = $2)`, s.runTable),
+			sessionID, run.RunID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return workflow.ErrRunConflict
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %[1]s (session_id, seq, run_id, status, duration_ns, data)
+			SELECT $1, COALESCE(MAX(seq) + 1, 0), $2, $3, $4, $5 FROM %[1]s WHERE session_id = $1`, s.runTable),
+			sessionID, run.RunID, string(run.Status), duration, data); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Clear 实现 workflow.WorkflowStorage。
+// Clear implements workflow.WorkflowStorage.
+func (s *WorkflowStorage) Clear(ctx context.Context, olderThan time.Duration) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	cutoff := time.Now().Add(-olderThan).UTC()
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE created_at < $1`, s.sessionTable), cutoff)
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// Close 实现 workflow.WorkflowStorage；数据库连接由调用方管理。
+// Close implements workflow.WorkflowStorage. The database handle belongs to
+// the caller and is left open.
+func (s *WorkflowStorage) Close() error {
+	return nil
+}
+
+// GetStats 实现 workflow.StorageStats。
+// GetStats implements workflow.StorageStats.
+func (s *WorkflowStorage) GetStats(ctx context.Context) (*workflow.SessionStats, error) {
+	return s.stats(ctx, "", nil)
+}
+
+// GetWorkflowStats 实现 workflow.StorageStats。
+// GetWorkflowStats implements workflow.StorageStats.
+func (s *WorkflowStorage) GetWorkflowStats(ctx context.Context, workflowID string) (*workflow.SessionStats, error) {
+	if workflowID == "" {
+		return nil, workflow.ErrInvalidWorkflowID
+	}
+	return s.stats(ctx, "WHERE workflow_id = $1", []interface{}{workflowID})
+}
+
+func (s *WorkflowStorage) stats(ctx context.Context, where string, args []interface{}) (*workflow.SessionStats, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	stats := &workflow.SessionStats{}
+	if err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s %s`, s.sessionTable, where), args...).
+		Scan(&stats.TotalSessions); err != nil {
+		return nil, err
+	}
+
+	// 与 MemoryStorage 一致：所有未成功的已完成运行都计为失败
+	// Failed runs mirror MemoryStorage: every completed run that did not succeed
+	statusArg := fmt.Sprintf("$%d", len(args)+1)
+	query := fmt.Sprintf(`SELECT COUNT(*),
+		COALESCE(SUM(CASE WHEN duration_ns IS NOT NULL THEN 1 ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN status = %s THEN 1 ELSE 0 END), 0),
+		AVG(duration_ns)::float8
+		FROM %s WHERE session_id IN (SELECT session_id FROM %s %s)`, statusArg, s.runTable, s.sessionTable, where)
+	var average sql.NullFloat64
+	if err := s.db.QueryRowContext(ctx, query, append(args, string(workflow.RunStatusCompleted))...).
+		Scan(&stats.TotalRuns, &stats.CompletedRuns, &stats.SuccessfulRuns, &average); err != nil {
+		return nil, err
+	}
+	stats.FailedRuns = stats.CompletedRuns - stats.SuccessfulRuns
+	if average.Valid {
+		stats.AverageDuration = time.Duration(average.Float64)
+	}
+	return stats, nil
+}
+
+// querySessions loads the sessions selected by where, oldest first, together
+// with their runs and cancellation records.
+func (s *WorkflowStorage) querySessions(ctx context.Context, where string, args []interface{}, limit, offset int) ([]*workflow.WorkflowSession, error) {
+	query := fmt.Sprintf(`SELECT session_id, workflow_id, user_id, metadata, created_at, updated_at
+		FROM %s %s ORDER BY created_at, session_id`, s.sessionTable, where)
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+	if offset > 0 {
+		args = append(args, offset)
+		query += fmt.Sprintf(` OFFSET $%d`, len(args))
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]*workflow.WorkflowSession, 0)
+	byID := make(map[string]*workflow.WorkflowSession)
+	for rows.Next() {
+		var (
+			session  workflow.WorkflowSession
+			metadata sql.NullString
+		)
+		if err := rows.Scan(&session.SessionID, &session.WorkflowID, &session.UserID, &metadata, &session.CreatedAt, &session.UpdatedAt); err != nil {
+			return nil, err
+		}
+		session.Runs = make([]*workflow.WorkflowRun, 0)
+		session.Cancellations = make([]*workflow.CancellationRecord, 0)
+		if err := decodeJSON(metadata, &session.Metadata); err != nil {
+			return nil, err
+		}
+		if session.Metadata == nil {
+			session.Metadata = make(map[string]interface{})
+		}
+		sessions = append(sessions, &session)
+		byID[session.SessionID] = &session
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return sessions, nil
+	}
+
+	if err := s.loadChildren(ctx, byID); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (s *WorkflowStorage) loadChildren(ctx context.Context, byID map[string]*workflow.WorkflowSession) error {
+	ids := make([]interface{}, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+
+	// 分批查询以避免超出参数数量上限
+	// Query in chunks to stay below the bind parameter limit
+	const chunk = 1000
+	for start := 0; start < len(ids); start += chunk {
+		part := ids[start:min(start+chunk, len(ids))]
+		in := placeholders(len(part))
+
+		err := s.scanChildren(ctx, s.runTable, in, part, func(sessionID string, data []byte) error {
+			var run workflow.WorkflowRun
+			if err := json.Unmarshal(data, &run); err != nil {
+				return fmt.Errorf("failed to decode run of session %s: %w", sessionID, err)
+			}
+			byID[sessionID].Runs = append(byID[sessionID].Runs, &run)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.scanChildren(ctx, s.cancellationTable, in, part, func(sessionID string, data []byte) error {
+			var record workflow.CancellationRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return fmt.Errorf("failed to decode cancellation of session %s: %w", sessionID, err)
+			}
+			byID[sessionID].Cancellations = append(byID[sessionID].Cancellations, &record)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *WorkflowStorage) scanChildren(ctx context.Context, table, in string, ids []interface{}, fn func(sessionID string, data []byte) error) error {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT session_id, data FROM %s
+		WHERE session_id IN (%s) ORDER BY session_id, seq`, table, in), ids...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			sessionID string
+			data      []byte
+		)
+		if err := rows.Scan(&sessionID, &data); err != nil {
+			return err
+		}
+		if err := fn(sessionID, data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *WorkflowStorage) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+var (
+	_ workflow.WorkflowStorage = (*WorkflowStorage)(nil)
+	_ workflow.StorageStats    = (*WorkflowStorage)(nil)
+	_ workflow.SessionQuerier  = (*WorkflowStorage)(nil)
+	_ workflow.RunLookup       = (*WorkflowStorage)(nil)
+	_ workflow.RunStore        = (*WorkflowStorage)(nil)
+)
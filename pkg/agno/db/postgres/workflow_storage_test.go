@@ -0,0 +1,259 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	"github.com/rexleimo/agno-go/pkg/agno/workflow"
+	"github.com/rexleimo/agno-go/pkg/agno/workflow/storagetest"
+)
+
+func TestNewWorkflowStorage_Validation(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	defer db.Close()
+
+	if _, err := NewWorkflowStorage(nil, WorkflowStorageConfig{}); err == nil {
+		t.Fatal("expected error for a nil db")
+	}
+	if _, err := NewWorkflowStorage(db, WorkflowStorageConfig{TablePrefix: "bad-prefix;"}); err == nil {
+		t.Fatal("expected error for an invalid table prefix")
+	}
+	storage, err := NewWorkflowStorage(db, WorkflowStorageConfig{Schema: "agno"})
+	if err != nil {
+		t.Fatalf("NewWorkflowStorage() error = %v", err)
+	}
+	if storage.sessionTable != `"agno"."workflow_sessions"` || storage.runTable != `"agno"."workflow_runs"` {
+		t.Fatalf("unexpected table names %s, %s", storage.sessionTable, storage.runTable)
+	}
+}
+
+func TestWorkflowStorage_CreateSessionExists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	defer db.Close()
+
+	storage, _ := NewWorkflowStorage(db, WorkflowStorageConfig{})
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "public"."workflow_sessions"`)).
+		WithArgs("session-1", "workflow-1", "user-1", "{}", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err := storage.CreateSession(context.Background(), "session-1", "workflow-1", "user-1"); !errors.Is(err, workflow.ErrSessionExists) {
+		t.Fatalf("expected ErrSessionExists, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("not all expectations met: %v", err)
+	}
+}
+
+func TestWorkflowStorage_UpdateSessionReplacesRuns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	defer db.Close()
+
+	storage, _ := NewWorkflowStorage(db, WorkflowStorageConfig{})
+	session := workflow.NewWorkflowSession("session-1", "workflow-1", "user-1")
+	run := workflow.NewWorkflowRun("run-1", "session-1", "workflow-1", "input")
+	run.MarkCompleted("output")
+	session.AddRun(run)
+	session.AddCancellation(&workflow.CancellationRecord{RunID: "run-0", Reason: "stop"})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "public"."workflow_sessions" SET`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "public"."workflow_runs" WHERE session_id = $1`)).
+		WithArgs("session-1").WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "public"."workflow_cancellations" WHERE session_id = $1`)).
+		WithArgs("session-1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "public"."workflow_runs"`)).
+		WithArgs("session-1", 0, "run-1", "completed", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "public"."workflow_cancellations"`)).
+		WithArgs("session-1", 0, "run-0", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := storage.UpdateSession(context.Background(), session); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("not all expectations met: %v", err)
+	}
+}
+
+func TestWorkflowStorage_UpdateSessionNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	defer db.Close()
+
+	storage, _ := NewWorkflowStorage(db, WorkflowStorageConfig{})
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "public"."workflow_sessions" SET`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	session := workflow.NewWorkflowSession("missing", "workflow-1", "user-1")
+	if err := storage.UpdateSession(context.Background(), session); !errors.Is(err, workflow.ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("not all expectations met: %v", err)
+	}
+}
+
+func TestWorkflowStorage_SaveRunConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	defer db.Close()
+
+	storage, _ := NewWorkflowStorage(db, WorkflowStorageConfig{})
+	run := workflow.NewWorkflowRun("run-1", "session-1", "workflow-1", "input")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "public"."workflow_sessions" SET updated_at = $1 WHERE session_id = $2`)).
+		WithArgs(sqlmock.AnyArg(), "session-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "public"."workflow_runs" SET status = $1, duration_ns = $2, data = $3`)).
+		WithArgs("pending", nil, sqlmock.AnyArg(), "session-1", "run-1", int64(0)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM "public"."workflow_runs"`)).
+		WithArgs("session-1", "run-1").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	if err := storage.SaveRun(context.Background(), "session-1", run); !errors.Is(err, workflow.ErrRunConflict) {
+		t.Fatalf("expected ErrRunConflict, got %v", err)
+	}
+	if run.Revision != 0 {
+		t.Errorf("expected revision to stay 0 after a conflict, got %d", run.Revision)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("not all expectations met: %v", err)
+	}
+}
+
+func TestWorkflowStorage_QuerySessionsBuildsFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	defer db.Close()
+
+	storage, _ := NewWorkflowStorage(db, WorkflowStorageConfig{})
+	after := time.Unix(1_700_000_000, 0)
+	created := after.Add(time.Hour).UTC()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "public"."workflow_sessions" WHERE workflow_id = $1 AND user_id = $2 AND created_at > $3 ORDER BY created_at, session_id LIMIT $4 OFFSET $5`)).
+		WithArgs("workflow-1", "user-1", after.UTC(), 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "workflow_id", "user_id", "metadata", "created_at", "updated_at"}).
+			AddRow("session-1", "workflow-1", "user-1", `{"tier":"gold"}`, created, created))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT session_id, data FROM "public"."workflow_runs"`)).
+		WithArgs("session-1").
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "data"}).
+			AddRow("session-1", []byte(`{"run_id":"run-1","status":"completed","last_step_id":"step-2"}`)))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT session_id, data FROM "public"."workflow_cancellations"`)).
+		WithArgs("session-1").
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "data"}))
+
+	sessions, err := storage.QuerySessions(context.Background(), workflow.SessionFilter{
+		WorkflowID:   "workflow-1",
+		UserID:       "user-1",
+		CreatedAfter: after,
+		Limit:        10,
+		Offset:       20,
+	})
+	if err != nil {
+		t.Fatalf("QuerySessions() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Metadata["tier"] != "gold" {
+		t.Fatalf("unexpected sessions %+v", sessions)
+	}
+	if last := sessions[0].GetLastRun(); last == nil || last.LastStepID != "step-2" {
+		t.Fatalf("unexpected runs %+v", sessions[0].Runs)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("not all expectations met: %v", err)
+	}
+}
+
+func TestWorkflowStorage_Conformance(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	container, err := startWorkflowPostgres(ctx)
+	if err != nil {
+		if errors.Is(err, errWorkflowDockerUnavailable) {
+			t.Skipf("skipping Postgres workflow storage tests: %v", err)
+		}
+		t.Fatalf("failed to start postgres container: %v", err)
+	}
+	defer container.Terminate(context.Background()) //nolint:errcheck
+
+	dsn, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatalf("ConnectionString() error = %v", err)
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	count := 0
+	storagetest.Run(t, func(t *testing.T) workflow.WorkflowStorage {
+		// Every subtest gets its own tables
+		count++
+		storage, err := NewWorkflowStorage(db, WorkflowStorageConfig{TablePrefix: fmt.Sprintf("wf_%d", count)})
+		if err != nil {
+			t.Fatalf("NewWorkflowStorage() error = %v", err)
+		}
+		if err := storage.CreateTables(context.Background()); err != nil {
+			t.Fatalf("CreateTables() error = %v", err)
+		}
+		return storage
+	})
+}
+
+var errWorkflowDockerUnavailable = errors.New("docker unavailable")
+
+func startWorkflowPostgres(ctx context.Context) (container *tcpostgres.PostgresContainer, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", errWorkflowDockerUnavailable, r)
+		}
+	}()
+
+	container, err = tcpostgres.Run(ctx, "postgres:16-alpine",
+		tcpostgres.WithDatabase("agno"),
+		tcpostgres.WithUsername("agno"),
+		tcpostgres.WithPassword("agno"),
+		tcpostgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		msg := err.Error()
+		if strings.Contains(msg, "Cannot connect to the Docker daemon") ||
+			strings.Contains(msg, "permission denied while trying to connect to the Docker daemon") ||
+			strings.Contains(msg, "rootless Docker not found") {
+			err = fmt.Errorf("%w: %v", errWorkflowDockerUnavailable, err)
+		}
+	}
+	return
+}
@@ -0,0 +1,285 @@
+//go:build redis
+
+// Package redis provides a Redis-backed memory.Memory.
+//
+// Like the Redis VectorDB provider it is an optional build: compile with
+// `-tags redis`.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/rexleimo/agno-go/pkg/agno/memory"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+const (
+	defaultKeyPrefix      = "agno:memory"
+	defaultMaxMessages    = 100
+	defaultOperationLimit = 2 * time.Second
+
+	// systemScanLimit bounds how many leading entries Recent inspects for
+	// system messages, which agents store at the start of a history.
+	systemScanLimit = 16
+
+	// toolCallPageSize is how many entries ReplaceToolCall reads at a time.
+	toolCallPageSize = 100
+)
+
+// Config configures the Redis conversation memory.
+type Config struct {
+	// Client is an existing client; when nil one is created from Addr,
+	// Password and DB.
+	Client goredis.UniversalClient
+	// Addr like "localhost:6379".
+	Addr string
+	// Password optional.
+	Password string
+	// DB index.
+	DB int
+	// KeyPrefix namespaces the keys (default: agno:memory).
+	KeyPrefix string
+	// TTL expires a user's history after this long without new messages
+	// (0 keeps it forever).
+	TTL time.Duration
+	// OperationTimeout bounds each command (default: 2s).
+	OperationTimeout time.Duration
+	// MaxMessages limits the history returned by GetMessages, like
+	// memory.InMemory's maxSize (default: 100). All messages stay stored.
+	MaxMessages int
+	// Logger receives errors from the memory.Memory methods, which cannot
+	// return them (default: slog.Default()).
+	Logger *slog.Logger
+}
+
+// Memory persists conversation history in Redis lists, one per user, and
+// implements memory.Memory through memory.StoreMemory. Messages are stored as
+// JSON so tool calls, reasoning content and metadata round-trip unchanged.
+type Memory struct {
+	*memory.StoreMemory
+
+	client  goredis.UniversalClient
+	prefix  string
+	ttl     time.Duration
+	timeout time.Duration
+}
+
+// NewMemory creates a Redis conversation memory.
+func NewMemory(cfg Config) (*Memory, error) {
+	client := cfg.Client
+	if client == nil {
+		if cfg.Addr == "" {
+			return nil, fmt.Errorf("redis client or address is required")
+		}
+		client = goredis.NewClient(&goredis.Options{Addr: cfg.Addr, Password: cfg.Password, DB: cfg.DB})
+	}
+
+	prefix := cfg.KeyPrefix
+	if prefix == "" {
+		prefix = defaultKeyPrefix
+	}
+	timeout := cfg.OperationTimeout
+	if timeout <= 0 {
+		timeout = defaultOperationLimit
+	}
+	maxMessages := cfg.MaxMessages
+	if maxMessages <= 0 {
+		maxMessages = defaultMaxMessages
+	}
+
+	m := &Memory{client: client, prefix: prefix, ttl: cfg.TTL, timeout: timeout}
+	m.StoreMemory = memory.NewStoreMemory(m, maxMessages, cfg.Logger)
+	return m, nil
+}
+
+func (m *Memory) keyUser(userID string) string {
+	return fmt.Sprintf("%s:user:%s", m.prefix, userID)
+}
+
+func (m *Memory) keyUsers() string {
+	return fmt.Sprintf("%s:users", m.prefix)
+}
+
+// Append stores a message for the user.
+func (m *Memory) Append(ctx context.Context, userID string, message *types.Message) error {
+	if message == nil {
+		return fmt.Errorf("message cannot be nil")
+	}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	if message.ID == "" {
+		message.ID = "msg-" + uuid.NewString()
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	uid := memory.ResolveUserID(userID)
+	key := m.keyUser(uid)
+	pipe := m.client.TxPipeline()
+	pipe.RPush(ctx, key, data)
+	pipe.SAdd(ctx, m.keyUsers(), uid)
+	if m.ttl > 0 {
+		pipe.Expire(ctx, key, m.ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Load returns every stored message of the user in insertion order.
+func (m *Memory) Load(ctx context.Context, userID string) ([]*types.Message, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	values, err := m.client.LRange(ctx, m.keyUser(memory.ResolveUserID(userID)), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	return decodeMessages(values)
+}
+
+// Recent returns the user's leading system messages and at most limit of the
+// most recent other messages, in insertion order. It reads the tail of the
+// list with a negative LRANGE start, so the cost does not grow with the
+// stored history.
+func (m *Memory) Recent(ctx context.Context, userID string, limit int) ([]*types.Message, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	key := m.keyUser(memory.ResolveUserID(userID))
+	pipe := m.client.TxPipeline()
+	lengthCmd := pipe.LLen(ctx, key)
+	headCmd := pipe.LRange(ctx, key, 0, systemScanLimit-1)
+	tailCmd := pipe.LRange(ctx, key, -int64(limit), -1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	head, err := decodeMessages(headCmd.Val())
+	if err != nil {
+		return nil, err
+	}
+	tail, err := decodeMessages(tailCmd.Val())
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the leading system messages that fall before the tail window.
+	tailStart := int(lengthCmd.Val()) - len(tail)
+	messages := make([]*types.Message, 0, len(head)+len(tail))
+	for i, msg := range head {
+		if i >= tailStart || msg.Role != types.RoleSystem {
+			break
+		}
+		messages = append(messages, msg)
+	}
+	return append(messages, tail...), nil
+}
+
+// ReplaceToolCall replaces the tool call with the same ID in the newest
+// message of the user that carries it. The list is read backwards in pages,
+// so recent calls are found without loading the
+// whole history.
+func (m *Memory) ReplaceToolCall(ctx context.Context, userID string, toolCall types.ToolCall) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	key := m.keyUser(memory.ResolveUserID(userID))
+	length, err := m.client.LLen(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	// Positive indexes stay valid while new messages are appended.
+	for end := length - 1; end >= 0; end -= toolCallPageSize {
+		start := max(end-toolCallPageSize+1, 0)
+		values, err := m.client.LRange(ctx, key, start, end).Result()
+		if err != nil {
+			return err
+		}
+		messages, err := decodeMessages(values)
+		if err != nil {
+			return err
+		}
+		for i := len(messages) - 1; i >= 0; i-- {
+			if !memory.SetToolCall(messages[i], toolCall) {
+				continue
+			}
+			data, err := json.Marshal(messages[i])
+			if err != nil {
+				return fmt.Errorf("failed to encode message: %w", err)
+			}
+			return m.client.LSet(ctx, key, start+int64(i), data).Err()
+		}
+	}
+	return memory.ErrToolCallNotFound
+}
+
+// Delete removes the stored messages of the user.
+func (m *Memory) Delete(ctx context.Context, userID string) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	uid := memory.ResolveUserID(userID)
+	pipe := m.client.TxPipeline()
+	pipe.Del(ctx, m.keyUser(uid))
+	pipe.SRem(ctx, m.keyUsers(), uid)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// DeleteAll removes the stored messages of every user.
+func (m *Memory) DeleteAll(ctx context.Context) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	users, err := m.client.SMembers(ctx, m.keyUsers()).Result()
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(users)+1)
+	for _, uid := range users {
+		keys = append(keys, m.keyUser(uid))
+	}
+	keys = append(keys, m.keyUsers())
+	return m.client.Del(ctx, keys...).Err()
+}
+
+// Close closes the underlying client.
+func (m *Memory) Close() error {
+	return m.client.Close()
+}
+
+func (m *Memory) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, m.timeout)
+}
+
+func decodeMessages(values []string) ([]*types.Message, error) {
+	messages := make([]*types.Message, 0, len(values))
+	for _, value := range values {
+		msg := &types.Message{}
+		if err := json.Unmarshal([]byte(value), msg); err != nil {
+			return nil, fmt.Errorf("failed to decode message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+var (
+	_ memory.Memory        = (*Memory)(nil)
+	_ memory.MessageStore  = (*Memory)(nil)
+	_ memory.ToolCallStore = (*Memory)(nil)
+)
@@ -0,0 +1,71 @@
+//go:build redis
+
+package redis
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+func TestNewMemory_RequiresAddress(t *testing.T) {
+	if _, err := NewMemory(Config{}); err == nil {
+		t.Fatal("expected error without client or address")
+	}
+}
+
+func TestMemory_Smoke(t *testing.T) {
+	if os.Getenv("TEST_REDIS_MEMORY") != "1" {
+		t.Skip("set TEST_REDIS_MEMORY=1 to run redis memory test")
+	}
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	mem, err := NewMemory(Config{Addr: addr, KeyPrefix: "agno:test:memory"})
+	if err != nil {
+		t.Fatalf("new redis memory: %v", err)
+	}
+	defer mem.Close()
+	defer mem.ClearAll()
+
+	msg := &types.Message{
+		ID:   "msg-1",
+		Role: types.RoleAssistant,
+		ToolCalls: []types.ToolCall{{
+			ID:       "call_1",
+			Type:     "function",
+			Function: types.ToolCallFunction{Name: "lookup", Arguments: `{"id":7}`},
+		}},
+		Metadata:         map[string]interface{}{"source": "test"},
+		ReasoningContent: &types.ReasoningContent{Content: "reasoning"},
+	}
+	mem.Add(msg, "alice")
+	mem.Add(types.NewUserMessage("hello"), "bob")
+
+	got := mem.GetMessages("alice")
+	if len(got) != 1 || !reflect.DeepEqual(got[0], msg) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, msg)
+	}
+
+	mem.Clear("alice")
+	if mem.Size("alice") != 0 || mem.Size("bob") != 1 {
+		t.Fatal("Clear should only remove the given user's messages")
+	}
+
+	ctx := context.Background()
+	mem.Add(types.NewSystemMessage("system"), "carol")
+	for _, text := range []string{"one", "two", "three", "four"} {
+		mem.Add(types.NewUserMessage(text), "carol")
+	}
+	recent, err := mem.Recent(ctx, "carol", 2)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	if len(recent) != 3 || recent[0].Role != types.RoleSystem || recent[1].Content != "three" || recent[2].Content != "four" {
+		t.Fatalf("unexpected recent messages: %+v", recent)
+	}
+}
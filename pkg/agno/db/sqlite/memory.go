@@ -0,0 +1,295 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rexleimo/agno-go/pkg/agno/memory"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+const (
+	defaultMemoryTable       = "memory_messages"
+	defaultMemoryMaxMessages = 100
+)
+
+// MemoryConfig configures the SQLite conversation memory.
+type MemoryConfig struct {
+	// Table stores the messages (default: memory_messages).
+	Table string
+	// OperationTimeout bounds each query (default: 200ms).
+	OperationTimeout time.Duration
+	// MaxMessages limits the history returned by GetMessages, like
+	// memory.InMemory's maxSize (default: 100). All messages stay stored.
+	MaxMessages int
+	// Logger receives errors from the memory.Memory methods, which cannot
+	// return them (default: slog.Default()).
+	Logger *slog.Logger
+}
+
+// Memory persists conversation history in SQLite and implements memory.Memory
+// through memory.StoreMemory. Every message is stored as JSON, so tool calls,
+// reasoning content and metadata survive a restart.
+type Memory struct {
+	*memory.StoreMemory
+
+	db      *sql.DB
+	table   string
+	timeout time.Duration
+}
+
+// NewMemory constructs a SQLite-backed conversation memory.
+func NewMemory(db *sql.DB, cfg MemoryConfig) (*Memory, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+
+	table := cfg.Table
+	if table == "" {
+		table = defaultMemoryTable
+	}
+	if !identifierPattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name: %s", table)
+	}
+	if err := ensureMemorySchema(db, table); err != nil {
+		return nil, err
+	}
+
+	timeout := cfg.OperationTimeout
+	if timeout <= 0 {
+		timeout = defaultOperationLimit
+	}
+	maxMessages := cfg.MaxMessages
+	if maxMessages <= 0 {
+		maxMessages = defaultMemoryMaxMessages
+	}
+
+	m := &Memory{db: db, table: table, timeout: timeout}
+	m.StoreMemory = memory.NewStoreMemory(m, maxMessages, cfg.Logger)
+	return m, nil
+}
+
+// Append stores a message for the user.
+func (m *Memory) Append(ctx context.Context, userID string, message *types.Message) error {
+	if message == nil {
+		return fmt.Errorf("message cannot be nil")
+	}
+	if err := ensureContext(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := m.applyTimeout(ctx)
+	defer cancel()
+
+	if message.ID == "" {
+		message.ID = "msg-" + uuid.NewString()
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, message_id, role, data, created_at)
+		VALUES (?, ?, ?, ?, ?)`, m.table)
+	_, err = m.db.ExecContext(ctx, query,
+		memory.ResolveUserID(userID),
+		message.ID,
+		string(message.Role),
+		string(data),
+		time.Now().UTC(),
+	)
+	return err
+}
+
+// Load returns every stored message of the user in insertion order.
+func (m *Memory) Load(ctx context.Context, userID string) ([]*types.Message, error) {
+	if err := ensureContext(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.applyTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT data FROM %s WHERE user_id = ? ORDER BY id`, m.table)
+	rows, err := m.db.QueryContext(ctx, query, memory.ResolveUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+	return scanMessages(rows)
+}
+
+// Recent returns the user's system messages and at most limit of the most
+// recent other messages, in insertion order. Both parts are read through
+// indexes, so the cost does not grow with the stored history.
+func (m *Memory) Recent(ctx context.Context, userID string, limit int) ([]*types.Message, error) {
+	if err := ensureContext(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.applyTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT data FROM %[1]s WHERE user_id = ? AND (role = ? OR id IN (
+		SELECT id FROM %[1]s WHERE user_id = ? AND role <> ? ORDER BY id DESC LIMIT ?
+	)) ORDER BY id`, m.table)
+	uid := memory.ResolveUserID(userID)
+	system := string(types.RoleSystem)
+	rows, err := m.db.QueryContext(ctx, query, uid, system, uid, system, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanMessages(rows)
+}
+
+// ReplaceToolCall replaces the tool call with the same ID in the newest
+// assistant message of the user that carries it.
+func (m *Memory) ReplaceToolCall(ctx context.Context, userID string, toolCall types.ToolCall) error {
+	if err := ensureContext(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := m.applyTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT id, data FROM %s WHERE user_id = ? AND role = ? AND instr(data, ?) > 0
+		ORDER BY id DESC`, m.table)
+	rows, err := m.db.QueryContext(ctx, query,
+		memory.ResolveUserID(userID), string(types.RoleAssistant), toolCall.ID)
+	if err != nil {
+		return err
+	}
+	id, data, err := findToolCall(rows, toolCall)
+	if err != nil {
+		return err
+	}
+
+	update := fmt.Sprintf(`UPDATE %s SET data = ? WHERE id = ?`, m.table)
+	_, err = m.db.ExecContext(ctx, update, string(data), id)
+	return err
+}
+
+// Delete removes the stored messages of the user.
+func (m *Memory) Delete(ctx context.Context, userID string) error {
+	if err := ensureContext(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := m.applyTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = ?`, m.table)
+	_, err := m.db.ExecContext(ctx, query, memory.ResolveUserID(userID))
+	return err
+}
+
+// DeleteAll removes the stored messages of every user.
+func (m *Memory) DeleteAll(ctx context.Context) error {
+	if err := ensureContext(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := m.applyTimeout(ctx)
+	defer cancel()
+
+	_, err := m.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s`, m.table))
+	return err
+}
+
+func (m *Memory) applyTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= m.timeout {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, m.timeout)
+}
+
+func scanMessages(rows *sql.Rows) ([]*types.Message, error) {
+	defer rows.Close()
+
+	messages := make([]*types.Message, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		msg := &types.Message{}
+		if err := json.Unmarshal([]byte(data), msg); err != nil {
+			return nil, fmt.Errorf("failed to decode message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// findToolCall returns the id and the edited data of the first row whose
+// message carries the tool call.
+func findToolCall(rows *sql.Rows, toolCall types.ToolCall) (int64, []byte, error) {
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id   int64
+			data string
+		)
+		if err := rows.Scan(&id, &data); err != nil {
+			return 0, nil, err
+		}
+		msg := &types.Message{}
+		if err := json.Unmarshal([]byte(data), msg); err != nil {
+			return 0, nil, fmt.Errorf("failed to decode message: %w", err)
+		}
+		if !memory.SetToolCall(msg, toolCall) {
+			continue
+		}
+		edited, err := json.Marshal(msg)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to encode message: %w", err)
+		}
+		return id, edited, nil
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	return 0, nil, memory.ErrToolCallNotFound
+}
+
+func ensureMemorySchema(db *sql.DB, table string) error {
+	stmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id TEXT NOT NULL,
+		message_id TEXT NOT NULL,
+		role TEXT NOT NULL,
+		data TEXT NOT NULL,
+		created_at DATETIME
+	)`, table)
+	if _, err := db.Exec(stmt); err != nil {
+		return err
+	}
+
+	indexes := []string{
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%[1]s_user_id ON %[1]s (user_id, id)`, table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%[1]s_user_role ON %[1]s (user_id, role, id)`, table),
+	}
+	for _, index := range indexes {
+		if _, err := db.Exec(index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	_ memory.Memory        = (*Memory)(nil)
+	_ memory.MessageStore  = (*Memory)(nil)
+	_ memory.ToolCallStore = (*Memory)(nil)
+)
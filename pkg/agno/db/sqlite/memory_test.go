@@ -0,0 +1,207 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/memory"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+func openMemoryTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMemory_RoundTripsAllFields(t *testing.T) {
+	db := openMemoryTestDB(t)
+	mem, err := NewMemory(db, MemoryConfig{})
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+
+	redacted := "hidden"
+	assistant := &types.Message{
+		ID:      "msg-1",
+		Role:    types.RoleAssistant,
+		Content: "checking",
+		Name:    "helper",
+		ToolCalls: []types.ToolCall{{
+			ID:       "call_1",
+			Type:     "function",
+			Function: types.ToolCallFunction{Name: "search", Arguments: `{"q":"go"}`},
+			Metadata: map[string]interface{}{"source": "model"},
+		}},
+		Metadata: map[string]interface{}{"turn": float64(1)},
+		ReasoningContent: &types.ReasoningContent{
+			Content:         "think",
+			RedactedContent: &redacted,
+		},
+	}
+	tool := types.NewToolMessage("call_1", "result")
+	tool.ID = "msg-2"
+
+	mem.Add(assistant, "alice")
+	mem.Add(tool, "alice")
+
+	// A fresh instance over the same database sees the history.
+	restarted, err := NewMemory(db, MemoryConfig{})
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+	got := restarted.GetMessages("alice")
+	if len(got) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(got))
+	}
+	if !reflect.DeepEqual(got[0], assistant) {
+		t.Errorf("assistant message mismatch:\n got %+v\nwant %+v", got[0], assistant)
+	}
+	if !reflect.DeepEqual(got[1], tool) {
+		t.Errorf("tool message mismatch:\n got %+v\nwant %+v", got[1], tool)
+	}
+}
+
+func TestMemory_EditToolCall(t *testing.T) {
+	db := openMemoryTestDB(t)
+	mem, err := NewMemory(db, MemoryConfig{})
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+
+	call := types.ToolCall{
+		ID:       "call_1",
+		Type:     "function",
+		Function: types.ToolCallFunction{Name: "send", Arguments: `{"to":"a"}`},
+	}
+	mem.Add(&types.Message{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{call}}, "alice")
+	mem.Add(types.NewUserMessage("mentions call_1"), "alice")
+
+	call.Function.Arguments = `{"to":"b"}`
+	if err := mem.EditToolCall(context.Background(), call, "alice"); err != nil {
+		t.Fatalf("EditToolCall() error = %v", err)
+	}
+
+	got := mem.GetMessages("alice")
+	if len(got) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(got))
+	}
+	if args := got[0].ToolCalls[0].Function.Arguments; args != `{"to":"b"}` {
+		t.Errorf("expected edited arguments, got %s", args)
+	}
+	if err := mem.EditToolCall(context.Background(), call, "bob"); !errors.Is(err, memory.ErrToolCallNotFound) {
+		t.Errorf("expected memory.ErrToolCallNotFound for another user, got %v", err)
+	}
+}
+
+func TestMemory_UserIsolation(t *testing.T) {
+	db := openMemoryTestDB(t)
+	mem, err := NewMemory(db, MemoryConfig{})
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+
+	mem.Add(types.NewUserMessage("hi from alice"), "alice")
+	mem.Add(types.NewUserMessage("hi from bob"), "bob")
+	mem.Add(types.NewUserMessage("hi from default"))
+
+	if mem.Size("alice") != 1 || mem.Size("bob") != 1 || mem.Size() != 1 {
+		t.Fatalf("unexpected sizes: alice=%d bob=%d default=%d", mem.Size("alice"), mem.Size("bob"), mem.Size())
+	}
+	if got := mem.GetMessages("bob")[0].Content; got != "hi from bob" {
+		t.Fatalf("bob got %q", got)
+	}
+
+	mem.Clear("alice")
+	if mem.Size("alice") != 0 || mem.Size("bob") != 1 {
+		t.Fatal("Clear should only remove the given user's messages")
+	}
+
+	mem.ClearAll()
+	if mem.Size("bob") != 0 || mem.Size() != 0 {
+		t.Fatal("ClearAll should remove every user's messages")
+	}
+}
+
+func TestMemory_MaxMessages(t *testing.T) {
+	db := openMemoryTestDB(t)
+	mem, err := NewMemory(db, MemoryConfig{MaxMessages: 3})
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if err := mem.Append(ctx, "u", types.NewSystemMessage("system")); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	for _, text := range []string{"one", "two", "three", "four"} {
+		if err := mem.Append(ctx, "u", types.NewUserMessage(text)); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	messages := mem.GetMessages("u")
+	if len(messages) != 3 || messages[0].Role != types.RoleSystem || messages[2].Content != "four" {
+		t.Fatalf("unexpected window: %+v", messages)
+	}
+
+	stored, err := mem.Load(ctx, "u")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(stored) != 5 {
+		t.Fatalf("all messages should stay stored, got %d", len(stored))
+	}
+}
+
+func TestMemory_RecentKeepsSystemMessages(t *testing.T) {
+	db := openMemoryTestDB(t)
+	mem, err := NewMemory(db, MemoryConfig{MaxMessages: 3})
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+
+	ctx := context.Background()
+	call := &types.Message{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "call_1", Type: "function"}}}
+	history := []*types.Message{
+		types.NewSystemMessage("system"),
+		types.NewUserMessage("one"),
+		call,
+		types.NewToolMessage("call_1", "result"),
+		types.NewAssistantMessage("two"),
+		types.NewUserMessage("three"),
+	}
+	for _, msg := range history {
+		if err := mem.Append(ctx, "u", msg); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	recent, err := mem.Recent(ctx, "u", 3)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	if len(recent) != 4 || recent[0].Role != types.RoleSystem || recent[1].Role != types.RoleTool || recent[3].Content != "three" {
+		t.Fatalf("unexpected recent messages: %+v", recent)
+	}
+
+	// The tool result whose call fell out of the window is dropped.
+	messages := mem.GetMessages("u")
+	if len(messages) != 3 || messages[0].Role != types.RoleSystem || messages[1].Content != "two" || messages[2].Content != "three" {
+		t.Fatalf("unexpected window: %+v", messages)
+	}
+}
+
+func TestNewMemory_InvalidTable(t *testing.T) {
+	db := openMemoryTestDB(t)
+	if _, err := NewMemory(db, MemoryConfig{Table: "messages; DROP TABLE x"}); err == nil {
+		t.Fatal("expected error for invalid table name")
+	}
+}
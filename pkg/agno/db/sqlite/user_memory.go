@@ -0,0 +1,207 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/memory"
+)
+
+const defaultUserMemoryTable = "user_memories"
+
+// UserMemoryStoreConfig configures the SQLite user memory store.
+type UserMemoryStoreConfig struct {
+	// Table stores the memories (default: user_memories).
+	Table string
+	// OperationTimeout bounds each query (default: 200ms).
+	OperationTimeout time.Duration
+}
+
+// UserMemoryStore persists long-term user memories in SQLite and implements
+// memory.UserMemoryStore.
+type UserMemoryStore struct {
+	db      *sql.DB
+	table   string
+	timeout time.Duration
+}
+
+// NewUserMemoryStore constructs a SQLite-backed user memory store.
+func NewUserMemoryStore(db *sql.DB, cfg UserMemoryStoreConfig) (*UserMemoryStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+
+	table := cfg.Table
+	if table == "" {
+		table = defaultUserMemoryTable
+	}
+	if err := ensureUserMemorySchema(db, table); err != nil {
+		return nil, err
+	}
+
+	timeout := cfg.OperationTimeout
+	if timeout <= 0 {
+		timeout = defaultOperationLimit
+	}
+
+	return &UserMemoryStore{db: db, table: table, timeout: timeout}, nil
+}
+
+// Upsert creates or replaces a memory.
+func (s *UserMemoryStore) Upsert(ctx context.Context, mem *memory.UserMemory) error {
+	if mem == nil || mem.ID == "" {
+		return fmt.Errorf("memory id is required")
+	}
+	if err := ensureContext(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	topics, err := json.Marshal(mem.Topics)
+	if err != nil {
+		return fmt.Errorf("failed to encode topics: %w", err)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, id, memory, topics, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, id) DO UPDATE SET
+			memory = excluded.memory,
+			topics = excluded.topics,
+			updated_at = excluded.updated_at`, s.table)
+	_, err = s.db.ExecContext(ctx, query,
+		memory.ResolveUserID(mem.UserID),
+		mem.ID,
+		mem.Memory,
+		string(topics),
+		mem.CreatedAt.UTC(),
+		mem.UpdatedAt.UTC(),
+	)
+	return err
+}
+
+// Get returns a memory of the user, or nil if it does not exist.
+func (s *UserMemoryStore) Get(ctx context.Context, userID, id string) (*memory.UserMemory, error) {
+	if err := ensureContext(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT user_id, id, memory, topics, created_at, updated_at
+		FROM %s WHERE user_id = ? AND id = ?`, s.table)
+	mem, err := scanUserMemory(s.db.QueryRowContext(ctx, query, memory.ResolveUserID(userID), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return mem, err
+}
+
+// List returns the memories of the user in insertion order.
+func (s *UserMemoryStore) List(ctx context.Context, userID string) ([]*memory.UserMemory, error) {
+	if err := ensureContext(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT user_id, id, memory, topics, created_at, updated_at
+		FROM %s WHERE user_id = ? ORDER BY rowid`, s.table)
+	rows, err := s.db.QueryContext(ctx, query, memory.ResolveUserID(userID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memories := make([]*memory.UserMemory, 0)
+	for rows.Next() {
+		mem, err := scanUserMemory(rows)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, mem)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return memories, nil
+}
+
+// Delete removes a memory of the user.
+func (s *UserMemoryStore) Delete(ctx context.Context, userID, id string) error {
+	if err := ensureContext(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = ? AND id = ?`, s.table)
+	_, err := s.db.ExecContext(ctx, query, memory.ResolveUserID(userID), id)
+	return err
+}
+
+// DeleteAll removes every memory of the user.
+func (s *UserMemoryStore) DeleteAll(ctx context.Context, userID string) error {
+	if err := ensureContext(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = ?`, s.table)
+	_, err := s.db.ExecContext(ctx, query, memory.ResolveUserID(userID))
+	return err
+}
+
+func (s *UserMemoryStore) applyTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= s.timeout {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+func scanUserMemory(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*memory.UserMemory, error) {
+	var (
+		mem    memory.UserMemory
+		topics string
+	)
+	if err := scanner.Scan(&mem.UserID, &mem.ID, &mem.Memory, &topics, &mem.CreatedAt, &mem.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if topics != "" {
+		if err := json.Unmarshal([]byte(topics), &mem.Topics); err != nil {
+			return nil, fmt.Errorf("failed to decode topics: %w", err)
+		}
+	}
+	return &mem, nil
+}
+
+func ensureUserMemorySchema(db *sql.DB, table string) error {
+	stmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		user_id TEXT NOT NULL,
+		id TEXT NOT NULL,
+		memory TEXT NOT NULL,
+		topics TEXT,
+		created_at DATETIME,
+		updated_at DATETIME,
+		PRIMARY KEY (user_id, id)
+	)`, table)
+	_, err := db.Exec(stmt)
+	return err
+}
+
+var _ memory.UserMemoryStore = (*UserMemoryStore)(nil)
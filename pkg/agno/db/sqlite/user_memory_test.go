@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/memory"
+)
+
+func TestUserMemoryStore_CRUD(t *testing.T) {
+	db := openMemoryTestDB(t)
+	store, err := NewUserMemoryStore(db, UserMemoryStoreConfig{})
+	if err != nil {
+		t.Fatalf("NewUserMemoryStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	name := &memory.UserMemory{
+		ID:        "mem-1",
+		UserID:    "ada",
+		Memory:    "Name is Ada",
+		Topics:    []string{"name"},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+	lang := &memory.UserMemory{ID: "mem-2", UserID: "ada", Memory: "Likes Python", CreatedAt: created, UpdatedAt: created}
+	other := &memory.UserMemory{ID: "mem-3", UserID: "bob", Memory: "Has a cat", CreatedAt: created, UpdatedAt: created}
+	for _, mem := range []*memory.UserMemory{name, lang, other} {
+		if err := store.Upsert(ctx, mem); err != nil {
+			t.Fatalf("Upsert() error = %v", err)
+		}
+	}
+
+	got, err := store.Get(ctx, "ada", "mem-1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, name) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, name)
+	}
+	if missing, err := store.Get(ctx, "bob", "mem-1"); err != nil || missing != nil {
+		t.Fatalf("expected nil for another user's memory, got %+v, %v", missing, err)
+	}
+
+	lang.Memory = "Prefers Go"
+	lang.UpdatedAt = created.Add(time.Hour)
+	if err := store.Upsert(ctx, lang); err != nil {
+		t.Fatalf("Upsert() update error = %v", err)
+	}
+	list, err := store.List(ctx, "ada")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 || list[0].ID != "mem-1" || list[1].Memory != "Prefers Go" || !list[1].UpdatedAt.Equal(lang.UpdatedAt) {
+		t.Fatalf("unexpected list after update: %+v", list)
+	}
+
+	if err := store.Delete(ctx, "ada", "mem-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if list, _ := store.List(ctx, "ada"); len(list) != 1 {
+		t.Fatalf("expected 1 memory after delete, got %d", len(list))
+	}
+	if err := store.DeleteAll(ctx, "ada"); err != nil {
+		t.Fatalf("DeleteAll() error = %v", err)
+	}
+	if list, _ := store.List(ctx, "ada"); len(list) != 0 {
+		t.Fatalf("expected no memories after DeleteAll, got %d", len(list))
+	}
+	if list, _ := store.List(ctx, "bob"); len(list) != 1 {
+		t.Fatal("DeleteAll should only remove the given user's memories")
+	}
+}
@@ -0,0 +1,665 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/workflow"
+)
+
+const (
+	defaultWorkflowTablePrefix = "workflow"
+	defaultWorkflowTimeout     = 5 * time.Second
+)
+
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// WorkflowStorageConfig configures the SQLite workflow storage.
+type WorkflowStorageConfig struct {
+	// TablePrefix names the tables <prefix>_sessions, <prefix>_runs and
+	// <prefix>_cancellations (default: workflow).
+	TablePrefix      string
+	OperationTimeout time.Duration
+}
+
+// WorkflowStorage persists workflow sessions, their runs (including messages,
+// events and resume data) and cancellation records in SQLite. It implements
+// workflow.WorkflowStorage, workflow.StorageStats, workflow.SessionQuerier,
+// workflow.RunLookup and workflow.RunStore.
+//
+// Timestamps are stored as Unix nanoseconds so range filters compare exactly.
+type WorkflowStorage struct {
+	db                *sql.DB
+	sessionTable      string
+	runTable          string
+	cancellationTable string
+	timeout           time.Duration
+}
+
+// NewWorkflowStorage creates the workflow tables if needed.
+func NewWorkflowStorage(db *sql.DB, cfg WorkflowStorageConfig) (*WorkflowStorage, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+
+	prefix := cfg.TablePrefix
+	if prefix == "" {
+		prefix = defaultWorkflowTablePrefix
+	}
+	if !identifierPattern.MatchString(prefix) {
+		return nil, fmt.Errorf("invalid table prefix: %s", prefix)
+	}
+
+	timeout := cfg.OperationTimeout
+	if timeout <= 0 {
+		timeout = defaultWorkflowTimeout
+	}
+
+	s := &WorkflowStorage{
+		db:                db,
+		sessionTable:      prefix + "_sessions",
+		runTable:          prefix + "_runs",
+		cancellationTable: prefix + "_cancellations",
+		timeout:           timeout,
+	}
+	if err := s.ensureSchema(); err != nil {
+		return nil, fmt.Errorf("failed to create workflow tables: %w", err)
+	}
+	return s, nil
+}
+
+func (s *WorkflowStorage) ensureSchema() error {
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			session_id TEXT PRIMARY KEY,
+			workflow_id TEXT NOT NULL,
+			user_id TEXT NOT NULL DEFAULT '',
+			metadata TEXT,
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL
+		)`, s.sessionTable),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_workflow ON %s (workflow_id, created_at)`, s.sessionTable, s.sessionTable),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_user ON %s (user_id, created_at)`, s.sessionTable, s.sessionTable),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			session_id TEXT NOT NULL,
+			seq INTEGER NOT NULL,
+			run_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			duration_ns INTEGER,
+			data TEXT NOT NULL,
+			PRIMARY KEY (session_id, seq)
+		)`, s.runTable),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_run ON %s (run_id)`, s.runTable, s.runTable),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			session_id TEXT NOT NULL,
+			seq INTEGER NOT NULL,
+			run_id TEXT NOT NULL,
+			data TEXT NOT NULL,
+			PRIMARY KEY (session_id, seq)
+		)`, s.cancellationTable),
+	}
+	for _, stmt := range statements {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateSession implements workflow.WorkflowStorage.
+func (s *WorkflowStorage) CreateSession(ctx context.Context, sessionID, workflowID, userID string) (*workflow.WorkflowSession, error) {
+	if sessionID == "" {
+		return nil, workflow.ErrInvalidSessionID
+	}
+	if workflowID == "" {
+		return nil, workflow.ErrInvalidWorkflowID
+	}
+	if err := ensureContext(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	session := workflow.NewWorkflowSession(sessionID, workflowID, userID)
+	metadata, err := json.Marshal(session.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (session_id, workflow_id, user_id, metadata, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT(session_id) DO NOTHING`, s.sessionTable)
+	result, err := s.db.ExecContext(ctx, query, sessionID, workflowID, userID, string(metadata),
+		session.CreatedAt.UnixNano(), session.UpdatedAt.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return nil, workflow.ErrSessionExists
+	}
+	return session, nil
+}
+
+// GetSession implements workflow.WorkflowStorage.
+func (s *WorkflowStorage) GetSession(ctx context.Context, sessionID string) (*workflow.WorkflowSession, error) {
+	if sessionID == "" {
+		return nil, workflow.ErrInvalidSessionID
+	}
+	if err := ensureContext(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	sessions, err := s.querySessions(ctx, `WHERE session_id = ?`, []interface{}{sessionID}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, workflow.ErrSessionNotFound
+	}
+	return sessions[0], nil
+}
+
+// UpdateSession implements workflow.WorkflowStorage. Runs and cancellation
+// records are replaced with the session's current lists.
+func (s *WorkflowStorage) UpdateSession(ctx context.Context, session *workflow.WorkflowSession) error {
+	if session == nil || session.SessionID == "" {
+		return workflow.ErrInvalidSessionID
+	}
+	if err := ensureContext(ctx); err != nil {
+		return err
+	}
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	session.UpdatedAt = time.Now()
+	metadata, err := json.Marshal(session.Metadata)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET workflow_id = ?, user_id = ?, metadata = ?, updated_at = ?
+		WHERE session_id = ?`, s.sessionTable),
+		session.WorkflowID, session.UserID, string(metadata), session.UpdatedAt.UnixNano(), session.SessionID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return workflow.ErrSessionNotFound
+	}
+
+	if err := s.deleteChildren(ctx, tx, session.SessionID); err != nil {
+		return err
+	}
+
+	runStmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO %s (session_id, seq, run_id, status, duration_ns, data)
+		VALUES (?, ?, ?, ?, ?, ?)`, s.runTable))
+	if err != nil {
+		return err
+	}
+	defer runStmt.Close()
+	for i, run := range session.GetRuns() {
+		if run == nil {
+			continue
+		}
+		data, err := json.Marshal(run)
+		if err != nil {
+			return fmt.Errorf("failed to encode run %s: %w", run.RunID, err)
+		}
+		var duration sql.NullInt64
+		if run.IsCompleted() {
+			duration = sql.NullInt64{Int64: int64(run.Duration()), Valid: true}
+		}
+		if _, err := runStmt.ExecContext(ctx, session.SessionID, i, run.RunID, string(run.Status), duration, string(data)); err != nil {
+			return err
+		}
+	}
+
+	cancelStmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO %s (session_id, seq, run_id, data)
+		VALUES (?, ?, ?, ?)`, s.cancellationTable))
+	if err != nil {
+		return err
+	}
+	defer cancelStmt.Close()
+	for i, record := range session.GetCancellations() {
+		if record == nil {
+			continue
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to encode cancellation for run %s: %w", record.RunID, err)
+		}
+		if _, err := cancelStmt.ExecContext(ctx, session.SessionID, i, record.RunID, string(data)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteSession implements workflow.WorkflowStorage.
+func (s *WorkflowStorage) DeleteSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return workflow.ErrInvalidSessionID
+	}
+	if err := ensureContext(ctx); err != nil {
+		return err
+	}
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE session_id = ?`, s.sessionTable), sessionID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return workflow.ErrSessionNotFound
+	}
+	if err := s.deleteChildren(ctx, tx, sessionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListSessions implements workflow.WorkflowStorage.
+func (s *WorkflowStorage) ListSessions(ctx context.Context, workflowID string, limit, offset int) ([]*workflow.WorkflowSession, error) {
+	if workflowID == "" {
+		return nil, workflow.ErrInvalidWorkflowID
+	}
+	return s.QuerySessions(ctx, workflow.SessionFilter{WorkflowID: workflowID, Limit: limit, Offset: offset})
+}
+
+// ListUserSessions implements workflow.WorkflowStorage.
+func (s *WorkflowStorage) ListUserSessions(ctx context.Context, userID string, limit, offset int) ([]*workflow.WorkflowSession, error) {
+	if err := ensureContext(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	return s.querySessions(ctx, `WHERE user_id = ?`, []interface{}{userID}, limit, offset)
+}
+
+// QuerySessions implements workflow.SessionQuerier.
+func (s *WorkflowStorage) QuerySessions(ctx context.Context, filter workflow.SessionFilter) ([]*workflow.WorkflowSession, error) {
+	if err := ensureContext(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	var (
+		clauses []string
+		args    []interface{}
+	)
+	if filter.WorkflowID != "" {
+		clauses = append(clauses, "workflow_id = ?")
+		args = append(args, filter.WorkflowID)
+	}
+	if filter.UserID != "" {
+		clauses = append(clauses, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		clauses = append(clauses, "created_at > ?")
+		args = append(args, filter.CreatedAfter.UnixNano())
+	}
+	if !filter.CreatedBefore.IsZero() {
+		clauses = append(clauses, "created_at < ?")
+		args = append(args, filter.CreatedBefore.UnixNano())
+	}
+
+	where := ""
+	if len(clauses) > 0 {
+		where = "WHERE " + strings.Join(clauses, " AND ")
+	}
+	return s.querySessions(ctx, where, args, filter.Limit, filter.Offset)
+}
+
+// GetSessionByRunID implements workflow.RunLookup.
+func (s *WorkflowStorage) GetSessionByRunID(ctx context.Context, runID string) (*workflow.WorkflowSession, error) {
+	if err := ensureContext(ctx); err != nil {
+		return nil, err
+	}
+	lookupCtx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	var sessionID string
+	err := s.db.QueryRowContext(lookupCtx, fmt.Sprintf(`SELECT session_id FROM %s WHERE run_id = ? LIMIT 1`, s.runTable), runID).
+		Scan(&sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, workflow.ErrRunNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetSession(ctx, sessionID)
+}
+
+// SaveRun implements workflow.RunStore. It writes only the given run, so
+// checkpoints do not rewrite the other runs of the session.
+func (s *WorkflowStorage) SaveRun(ctx context.Context, sessionID string, run *workflow.WorkflowRun) error {
+	if sessionID == "" {
+		return workflow.ErrInvalidSessionID
+	}
+	if run == nil || run.RunID == "" {
+		return workflow.ErrRunNotFound
+	}
+	if err := ensureContext(ctx); err != nil {
+		return err
+	}
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	revision := run.Revision
+	run.Revision++
+	data, err := json.Marshal(run)
+	if err != nil {
+		run.Revision = revision
+		return fmt.Errorf("failed to encode run %s: %w", run.RunID, err)
+	}
+	if err := s.saveRun(ctx, sessionID, run, revision, string(data)); err != nil {
+		run.Revision = revision
+		return err
+	}
+	return nil
+}
+
+func (s *WorkflowStorage) saveRun(ctx context.Context, sessionID string, run *workflow.WorkflowRun, revision int64, data string) error {
+	var duration sql.NullInt64
+	if run.IsCompleted() {
+		duration = sql.NullInt64{Int64: int64(run.Duration()), Valid: true}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Touching the session first takes the write lock for the whole transaction
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET updated_at = ? WHERE session_id = ?`, s.sessionTable),
+		time.Now().UnixNano(), sessionID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return workflow.ErrSessionNotFound
+	}
+
+	result, err = tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET status = ?, duration_ns = ?, data = ?
+		WHERE session_id = ? AND run_id = ? AND COALESCE(json_extract(data, '$.revision'), 0) = ?`, s.runTable),
+		string(run.Status), duration, data, sessionID, run.RunID, revision)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		var exists int
+		if err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE session_id = ? AND run_id = ?`, s.runTable),
+			sessionID, run.RunID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists > 0 {
+			return workflow.ErrRunConflict
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %[1]s (session_id, seq, run_id, status, duration_ns, data)
+			SELECT ?, COALESCE(MAX(seq) + 1, 0), ?, ?, ?, ? FROM %[1]s WHERE session_id = ?`, s.runTable),
+			sessionID, run.RunID, string(run.Status), duration, data, sessionID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Clear implements workflow.WorkflowStorage.
+func (s *WorkflowStorage) Clear(ctx context.Context, olderThan time.Duration) (int, error) {
+	if err := ensureContext(ctx); err != nil {
+		return 0, err
+	}
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	cutoff := time.Now().Add(-olderThan).UnixNano()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{s.runTable, s.cancellationTable} {
+		query := fmt.Sprintf(`DELETE FROM %s WHERE session_id IN (SELECT session_id FROM %s WHERE created_at < ?)`, table, s.sessionTable)
+		if _, err := tx.ExecContext(ctx, query, cutoff); err != nil {
+			return 0, err
+		}
+	}
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE created_at < ?`, s.sessionTable), cutoff)
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// Close implements workflow.WorkflowStorage. The database handle belongs to
+// the caller and is left open.
+func (s *WorkflowStorage) Close() error {
+	return nil
+}
+
+// GetStats implements workflow.StorageStats.
+func (s *WorkflowStorage) GetStats(ctx context.Context) (*workflow.SessionStats, error) {
+	return s.stats(ctx, "", nil)
+}
+
+// GetWorkflowStats implements workflow.StorageStats.
+func (s *WorkflowStorage) GetWorkflowStats(ctx context.Context, workflowID string) (*workflow.SessionStats, error) {
+	if workflowID == "" {
+		return nil, workflow.ErrInvalidWorkflowID
+	}
+	return s.stats(ctx, "WHERE workflow_id = ?", []interface{}{workflowID})
+}
+
+func (s *WorkflowStorage) stats(ctx context.Context, where string, args []interface{}) (*workflow.SessionStats, error) {
+	if err := ensureContext(ctx); err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.applyTimeout(ctx)
+	defer cancel()
+
+	stats := &workflow.SessionStats{}
+	if err := s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s %s`, s.sessionTable, where), args...).
+		Scan(&stats.TotalSessions); err != nil {
+		return nil, err
+	}
+
+	// Failed runs mirror MemoryStorage: every completed run that did not succeed
+	query := fmt.Sprintf(`SELECT COUNT(*),
+		COALESCE(SUM(CASE WHEN duration_ns IS NOT NULL THEN 1 ELSE 0 END), 0),
+		COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0),
+		AVG(duration_ns)
+		FROM %s WHERE session_id IN (SELECT session_id FROM %s %s)`, s.runTable, s.sessionTable, where)
+	var average sql.NullFloat64
+	if err := s.db.QueryRowContext(ctx, query, append([]interface{}{string(workflow.RunStatusCompleted)}, args...)...).
+		Scan(&stats.TotalRuns, &stats.CompletedRuns, &stats.SuccessfulRuns, &average); err != nil {
+		return nil, err
+	}
+	stats.FailedRuns = stats.CompletedRuns - stats.SuccessfulRuns
+	if average.Valid {
+		stats.AverageDuration = time.Duration(average.Float64)
+	}
+	return stats, nil
+}
+
+func (s *WorkflowStorage) deleteChildren(ctx context.Context, tx *sql.Tx, sessionID string) error {
+	for _, table := range []string{s.runTable, s.cancellationTable} {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE session_id = ?`, table), sessionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// querySessions loads the sessions selected by where, oldest first, together
+// with their runs and cancellation records.
+func (s *WorkflowStorage) querySessions(ctx context.Context, where string, args []interface{}, limit, offset int) ([]*workflow.WorkflowSession, error) {
+	query := fmt.Sprintf(`SELECT session_id, workflow_id, user_id, metadata, created_at, updated_at
+		FROM %s %s ORDER BY created_at, session_id`, s.sessionTable, where)
+	if limit > 0 || offset > 0 {
+		if limit <= 0 {
+			limit = -1
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, max(offset, 0))
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]*workflow.WorkflowSession, 0)
+	byID := make(map[string]*workflow.WorkflowSession)
+	for rows.Next() {
+		var (
+			session              workflow.WorkflowSession
+			metadata             sql.NullString
+			createdAt, updatedAt int64
+		)
+		if err := rows.Scan(&session.SessionID, &session.WorkflowID, &session.UserID, &metadata, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		session.CreatedAt = time.Unix(0, createdAt)
+		session.UpdatedAt = time.Unix(0, updatedAt)
+		session.Runs = make([]*workflow.WorkflowRun, 0)
+		session.Cancellations = make([]*workflow.CancellationRecord, 0)
+		session.Metadata = make(map[string]interface{})
+		if metadata.Valid && metadata.String != "" {
+			if err := json.Unmarshal([]byte(metadata.String), &session.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to decode metadata of session %s: %w", session.SessionID, err)
+			}
+			if session.Metadata == nil {
+				session.Metadata = make(map[string]interface{})
+			}
+		}
+		sessions = append(sessions, &session)
+		byID[session.SessionID] = &session
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return sessions, nil
+	}
+
+	if err := s.loadChildren(ctx, byID); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (s *WorkflowStorage) loadChildren(ctx context.Context, byID map[string]*workflow.WorkflowSession) error {
+	ids := make([]interface{}, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+
+	// Stay well below SQLite's bound parameter limit
+	const chunk = 500
+	for start := 0; start < len(ids); start += chunk {
+		part := ids[start:min(start+chunk, len(ids))]
+		in := strings.TrimSuffix(strings.Repeat("?, ", len(part)), ", ")
+
+		err := s.scanChildren(ctx, s.runTable, in, part, func(sessionID string, data []byte) error {
+			var run workflow.WorkflowRun
+			if err := json.Unmarshal(data, &run); err != nil {
+				return fmt.Errorf("failed to decode run of session %s: %w", sessionID, err)
+			}
+			byID[sessionID].Runs = append(byID[sessionID].Runs, &run)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.scanChildren(ctx, s.cancellationTable, in, part, func(sessionID string, data []byte) error {
+			var record workflow.CancellationRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return fmt.Errorf("failed to decode cancellation of session %s: %w", sessionID, err)
+			}
+			byID[sessionID].Cancellations = append(byID[sessionID].Cancellations, &record)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *WorkflowStorage) scanChildren(ctx context.Context, table, in string, ids []interface{}, fn func(sessionID string, data []byte) error) error {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT session_id, data FROM %s
+		WHERE session_id IN (%s) ORDER BY session_id, seq`, table, in), ids...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			sessionID string
+			data      []byte
+		)
+		if err := rows.Scan(&sessionID, &data); err != nil {
+			return err
+		}
+		if err := fn(sessionID, data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *WorkflowStorage) applyTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= s.timeout {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+var (
+	_ workflow.WorkflowStorage = (*WorkflowStorage)(nil)
+	_ workflow.StorageStats    = (*WorkflowStorage)(nil)
+	_ workflow.SessionQuerier  = (*WorkflowStorage)(nil)
+	_ workflow.RunLookup       = (*WorkflowStorage)(nil)
+	_ workflow.RunStore        = (*WorkflowStorage)(nil)
+)
@@ -0,0 +1,304 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/workflow"
+	"github.com/rexleimo/agno-go/pkg/agno/workflow/storagetest"
+)
+
+func TestWorkflowStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) workflow.WorkflowStorage {
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "workflow.db"))
+		if err != nil {
+			t.Fatalf("sql.Open failed: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		store, err := NewWorkflowStorage(db, WorkflowStorageConfig{})
+		if err != nil {
+			t.Fatalf("NewWorkflowStorage() error = %v", err)
+		}
+		return store
+	})
+}
+
+func TestWorkflowStorage_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workflow.db")
+	ctx := t.Context()
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	store, err := NewWorkflowStorage(db, WorkflowStorageConfig{TablePrefix: "wf"})
+	if err != nil {
+		t.Fatalf("NewWorkflowStorage() error = %v", err)
+	}
+	session, err := store.CreateSession(ctx, "session-1", "workflow-1", "user-1")
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	run := workflow.NewWorkflowRun("run-1", "session-1", "workflow-1", "input")
+	run.ApplyCancellation("shutdown", "step-3", map[string]interface{}{"output": "partial"})
+	session.AddRun(run)
+	if err := store.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+	db.Close()
+
+	db, err = sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	defer db.Close()
+	reopened, err := NewWorkflowStorage(db, WorkflowStorageConfig{TablePrefix: "wf"})
+	if err != nil {
+		t.Fatalf("NewWorkflowStorage() error = %v", err)
+	}
+	restored, err := reopened.GetSession(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	last := restored.GetLastRun()
+	if last == nil || last.LastStepID != "step-3" || last.CancellationSnapshot["output"] != "partial" {
+		t.Fatalf("resume data not restored: %+v", last)
+	}
+}
+
+func TestNewWorkflowStorage_InvalidPrefix(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	if _, err := NewWorkflowStorage(db, WorkflowStorageConfig{TablePrefix: "bad prefix"}); err == nil {
+		t.Fatal("expected error for an invalid table prefix")
+	}
+	if _, err := NewWorkflowStorage(nil, WorkflowStorageConfig{}); err == nil {
+		t.Fatal("expected error for a nil db")
+	}
+}
+
+type funcNode struct {
+	id string
+	fn func(*workflow.ExecutionContext) error
+}
+
+func (n *funcNode) Execute(_ context.Context, execCtx *workflow.ExecutionContext) (*workflow.ExecutionContext, error) {
+	if err := n.fn(execCtx); err != nil {
+		return nil, err
+	}
+	return execCtx, nil
+}
+
+func (n *funcNode) GetID() string              { return n.id }
+func (n *funcNode) GetType() workflow.NodeType { return workflow.NodeTypeStep }
+
+func TestWorkflowStorage_ResumeFromCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workflow.db")
+	ctx := t.Context()
+
+	newWorkflow := func(fail bool) (*workflow.Workflow, *sql.DB) {
+		db, err := sql.Open("sqlite", path)
+		if err != nil {
+			t.Fatalf("sql.Open failed: %v", err)
+		}
+		store, err := NewWorkflowStorage(db, WorkflowStorageConfig{})
+		if err != nil {
+			t.Fatalf("NewWorkflowStorage() error = %v", err)
+		}
+		wf, err := workflow.New(workflow.Config{
+			ID: "pipeline",
+			Steps: []workflow.Node{
+				&funcNode{id: "extract", fn: func(execCtx *workflow.ExecutionContext) error {
+					execCtx.Output = "rows"
+					execCtx.SetSessionState("cursor", "page-2")
+					return nil
+				}},
+				&funcNode{id: "load", fn: func(execCtx *workflow.ExecutionContext) error {
+					if fail {
+						return errors.New("warehouse offline")
+					}
+					cursor, _ := execCtx.GetSessionState("cursor")
+					execCtx.Output += " loaded from " + cursor.(string)
+					return nil
+				}},
+			},
+			HistoryStore:      store,
+			EnableCheckpoints: true,
+		})
+		if err != nil {
+			t.Fatalf("workflow.New() error = %v", err)
+		}
+		return wf, db
+	}
+
+	wf, db := newWorkflow(true)
+	if _, err := wf.Run(ctx, "sync", "session-1"); err == nil {
+		t.Fatal("expected load step to fail")
+	}
+	store, _ := NewWorkflowStorage(db, WorkflowStorageConfig{})
+	session, err := store.GetSession(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	runID := session.GetRuns()[0].RunID
+	db.Close()
+
+	// A new process resumes the failed run from the persisted checkpoint
+	wf, db = newWorkflow(false)
+	defer db.Close()
+	result, err := wf.Resume(ctx, runID)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if result.Output != "rows loaded from page-2" {
+		t.Errorf("unexpected output %q", result.Output)
+	}
+}
+
+// loadBarrier holds run lookups until every caller has loaded the run, so
+// concurrent callers all see the same pending run
+type loadBarrier struct {
+	*WorkflowStorage
+	loaded *sync.WaitGroup
+}
+
+func (b *loadBarrier) GetSessionByRunID(ctx context.Context, runID string) (*workflow.WorkflowSession, error) {
+	session, err := b.WorkflowStorage.GetSessionByRunID(ctx, runID)
+	b.loaded.Done()
+	b.loaded.Wait()
+	return session, err
+}
+
+func TestWorkflowStorage_ResolveApprovalOnce(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "workflow.db") + "?_pragma=busy_timeout(5000)"
+	ctx := t.Context()
+	var sent atomic.Int32
+	var loaded sync.WaitGroup
+	loaded.Add(2)
+
+	// Each workflow uses its own connection pool, like two server processes
+	newWorkflow := func() *workflow.Workflow {
+		db, err := sql.Open("sqlite", dsn)
+		if err != nil {
+			t.Fatalf("sql.Open failed: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		store, err := NewWorkflowStorage(db, WorkflowStorageConfig{})
+		if err != nil {
+			t.Fatalf("NewWorkflowStorage() error = %v", err)
+		}
+		review, err := workflow.NewApproval(workflow.ApprovalConfig{ID: "review"})
+		if err != nil {
+			t.Fatalf("NewApproval() error = %v", err)
+		}
+		wf, err := workflow.New(workflow.Config{
+			ID: "refunds",
+			Steps: []workflow.Node{
+				&funcNode{id: "quote", fn: func(execCtx *workflow.ExecutionContext) error {
+					execCtx.Output = "refund $20"
+					return nil
+				}},
+				review,
+				&funcNode{id: "send", fn: func(execCtx *workflow.ExecutionContext) error {
+					sent.Add(1)
+					return nil
+				}},
+			},
+			HistoryStore: &loadBarrier{WorkflowStorage: store, loaded: &loaded},
+		})
+		if err != nil {
+			t.Fatalf("workflow.New() error = %v", err)
+		}
+		return wf
+	}
+
+	first := newWorkflow()
+	_, err := first.Run(ctx, "complaint", "session-1")
+	var pending *workflow.PendingApprovalError
+	if !errors.As(err, &pending) {
+		t.Fatalf("expected pending approval, got %v", err)
+	}
+	second := newWorkflow()
+
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i, wf := range []*workflow.Workflow{first, second} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = wf.ResolveApproval(ctx, pending.RunID, workflow.ApprovalDecision{Action: workflow.ApprovalActionApprove})
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, workflow.ErrRunConflict):
+			t.Errorf("expected ErrRunConflict for the losing caller, got %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one caller to resume the run, got %d (%v)", succeeded, errs)
+	}
+	if sent.Load() != 1 {
+		t.Fatalf("expected the gated step to run once, ran %d times", sent.Load())
+	}
+}
+
+func TestWorkflowStorage_ConcurrentRunsInSession(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "workflow.db")+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	defer db.Close()
+	store, err := NewWorkflowStorage(db, WorkflowStorageConfig{})
+	if err != nil {
+		t.Fatalf("NewWorkflowStorage() error = %v", err)
+	}
+	ctx := t.Context()
+	if _, err := store.CreateSession(ctx, "session-1", "pipeline", ""); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	steps := make([]workflow.Node, 5)
+	for i := range steps {
+		steps[i] = &funcNode{id: fmt.Sprintf("step-%d", i), fn: func(*workflow.ExecutionContext) error { return nil }}
+	}
+	wf, err := workflow.New(workflow.Config{ID: "pipeline", Steps: steps, HistoryStore: store, EnableCheckpoints: true})
+	if err != nil {
+		t.Fatalf("workflow.New() error = %v", err)
+	}
+
+	// Checkpoints of one run must not drop the runs saved concurrently
+	const runs = 4
+	var wg sync.WaitGroup
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := wf.Run(ctx, "sync", "session-1"); err != nil {
+				t.Errorf("Run() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	session, err := store.GetSession(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	if session.CountRuns() != runs || session.CountSuccessfulRuns() != runs {
+		t.Fatalf("expected %d completed runs, got %d runs, %d completed", runs, session.CountRuns(), session.CountSuccessfulRuns())
+	}
+}
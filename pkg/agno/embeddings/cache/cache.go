@@ -0,0 +1,300 @@
+// Package cache wraps a vectordb.EmbeddingFunction with a content-addressed
+// cache, provider-sized batching, bounded concurrency and rate limiting.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+const (
+	defaultBatchSize   = 100
+	defaultConcurrency = 4
+)
+
+// Config configures a caching Embedder
+type Config struct {
+	// Embedder generates embeddings for cache misses (required)
+	Embedder vectordb.EmbeddingFunction
+
+	// Model identifies the embedding space and is part of every cache key, so
+	// switching models never returns stale vectors (required)
+	Model string
+
+	// Store holds cached embeddings (default: NewMemoryStore(0))
+	Store Store
+
+	// BatchSize is the maximum number of texts per provider call (default: 100)
+	BatchSize int
+
+	// Concurrency is the maximum number of batches in flight (default: 4)
+	Concurrency int
+
+	// RequestsPerSecond limits provider calls; zero means unlimited
+	RequestsPerSecond float64
+}
+
+// Stats reports cache effectiveness
+type Stats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Batches int64 `json:"batches"`
+}
+
+// Embedder implements vectordb.EmbeddingFunction on top of another
+// EmbeddingFunction. Texts already embedded with the same model are served
+// from the Store; the rest are deduplicated, batched and embedded
+// concurrently. Output order always matches input order.
+type Embedder struct {
+	embedder    vectordb.EmbeddingFunction
+	model       string
+	store       Store
+	batchSize   int
+	concurrency int
+	limiter     *limiter
+
+	hits    atomic.Int64
+	misses  atomic.Int64
+	batches atomic.Int64
+}
+
+// New creates a caching Embedder
+func New(config Config) (*Embedder, error) {
+	if config.Embedder == nil {
+		return nil, fmt.Errorf("embedder is required")
+	}
+	if config.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryStore(0)
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaultConcurrency
+	}
+	if config.RequestsPerSecond < 0 {
+		return nil, fmt.Errorf("requests per second must not be negative")
+	}
+
+	return &Embedder{
+		embedder:    config.Embedder,
+		model:       config.Model,
+		store:       config.Store,
+		batchSize:   config.BatchSize,
+		concurrency: config.Concurrency,
+		limiter:     newLimiter(config.RequestsPerSecond),
+	}, nil
+}
+
+// Key returns the cache key of text under model
+func Key(model, text string) string {
+	sum := sha256.Sum256([]byte(model + "\x00" + text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Stats returns cumulative hit, miss and batch counts
+func (e *Embedder) Stats() Stats {
+	return Stats{Hits: e.hits.Load(), Misses: e.misses.Load(), Batches: e.batches.Load()}
+}
+
+// Embed implements vectordb.EmbeddingFunction.Embed
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	keys := make([]string, len(texts))
+	unique := make([]string, 0, len(texts))
+	seen := make(map[string]bool, len(texts))
+	for i, text := range texts {
+		keys[i] = Key(e.model, text)
+		if !seen[keys[i]] {
+			seen[keys[i]] = true
+			unique = append(unique, keys[i])
+		}
+	}
+
+	cached, err := e.store.Get(ctx, unique)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding cache: %w", err)
+	}
+
+	var (
+		missingKeys  []string
+		missingTexts []string
+	)
+	queued := make(map[string]bool)
+	for i, key := range keys {
+		if _, ok := cached[key]; ok {
+			e.hits.Add(1)
+			continue
+		}
+		e.misses.Add(1)
+		if !queued[key] {
+			queued[key] = true
+			missingKeys = append(missingKeys, key)
+			missingTexts = append(missingTexts, texts[i])
+		}
+	}
+
+	if len(missingTexts) > 0 {
+		embeddings, err := e.embedBatches(ctx, missingTexts)
+		if err != nil {
+			return nil, err
+		}
+		fresh := make(map[string][]float32, len(missingKeys))
+		for i, key := range missingKeys {
+			fresh[key] = embeddings[i]
+		}
+		if err := e.store.Set(ctx, fresh); err != nil {
+			return nil, fmt.Errorf("failed to write embedding cache: %w", err)
+		}
+		if cached == nil {
+			cached = make(map[string][]float32, len(fresh))
+		}
+		for key, embedding := range fresh {
+			cached[key] = embedding
+		}
+	}
+
+	out := make([][]float32, len(texts))
+	for i, key := range keys {
+		out[i] = cached[key]
+	}
+	return out, nil
+}
+
+// EmbedSingle implements vectordb.EmbeddingFunction.EmbedSingle
+func (e *Embedder) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// embedBatches embeds texts in batches of at most batchSize, running up to
+// concurrency batches at once. The first error cancels the remaining batches.
+func (e *Embedder) embedBatches(parent context.Context, texts []string) ([][]float32, error) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	out := make([][]float32, len(texts))
+	sem := make(chan struct{}, e.concurrency)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for start := 0; start < len(texts); start += e.batchSize {
+		end := min(start+e.batchSize, len(texts))
+
+		// select picks randomly when both cases are ready, so check ctx
+		// again: a cancelled caller must not get a partial result
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			fail(err)
+			break
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := e.limiter.wait(ctx); err != nil {
+				fail(err)
+				return
+			}
+			e.batches.Add(1)
+			embeddings, err := e.embedder.Embed(ctx, texts[start:end])
+			if err != nil {
+				fail(fmt.Errorf("failed to embed batch %d-%d: %w", start, end, err))
+				return
+			}
+			if len(embeddings) != end-start {
+				fail(fmt.Errorf("batch %d-%d returned %d embeddings", start, end, len(embeddings)))
+				return
+			}
+			for i, embedding := range embeddings {
+				if len(embedding) == 0 {
+					fail(fmt.Errorf("batch %d-%d returned an empty embedding at %d", start, end, start+i))
+					return
+				}
+			}
+			copy(out[start:end], embeddings)
+		}(start, end)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// limiter spaces calls evenly at a fixed rate
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(perSecond float64) *limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &limiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var _ vectordb.EmbeddingFunction = (*Embedder)(nil)
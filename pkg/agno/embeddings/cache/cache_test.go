@@ -0,0 +1,252 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingEmbedder embeds a text as [len(text)] and records every call
+type countingEmbedder struct {
+	mu       sync.Mutex
+	calls    [][]string
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	delay    time.Duration
+	fail     bool
+	empty    bool
+}
+
+func (c *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+
+	c.mu.Lock()
+	c.calls = append(c.calls, append([]string(nil), texts...))
+	c.mu.Unlock()
+
+	if c.delay > 0 {
+		time.Sleep(c.delay)
+	}
+	if c.fail {
+		return nil, errors.New("provider unavailable")
+	}
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		if !c.empty {
+			out[i] = []float32{float32(len(text))}
+		}
+	}
+	return out, nil
+}
+
+func (c *countingEmbedder) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+	out, err := c.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+func (c *countingEmbedder) embedded() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	total := 0
+	for _, call := range c.calls {
+		total += len(call)
+	}
+	return total
+}
+
+func TestEmbedder_CachesAndDeduplicates(t *testing.T) {
+	inner := &countingEmbedder{}
+	embedder, err := New(Config{Embedder: inner, Model: "m1"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	got, err := embedder.Embed(ctx, []string{"a", "bb", "a", "ccc"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	want := [][]float32{{1}, {2}, {1}, {3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Embed() = %v, want %v", got, want)
+	}
+	if inner.embedded() != 3 {
+		t.Fatalf("expected 3 texts sent to the provider, got %d", inner.embedded())
+	}
+
+	if _, err := embedder.Embed(ctx, []string{"ccc", "dddd"}); err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if inner.embedded() != 4 {
+		t.Fatalf("expected only the new text to be embedded, got %d total", inner.embedded())
+	}
+	if stats := embedder.Stats(); stats.Hits != 1 || stats.Misses != 5 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// Another model must not reuse the cached vectors
+	other, _ := New(Config{Embedder: inner, Model: "m2", Store: embedder.store})
+	if _, err := other.EmbedSingle(ctx, "a"); err != nil {
+		t.Fatalf("EmbedSingle() error = %v", err)
+	}
+	if inner.embedded() != 5 {
+		t.Fatalf("expected a miss for a different model, got %d total", inner.embedded())
+	}
+}
+
+func TestEmbedder_BatchesConcurrently(t *testing.T) {
+	inner := &countingEmbedder{delay: 20 * time.Millisecond}
+	embedder, _ := New(Config{Embedder: inner, Model: "m", BatchSize: 10, Concurrency: 3})
+
+	texts := make([]string, 95)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text-%03d", i)
+	}
+	got, err := embedder.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	for i, embedding := range got {
+		if embedding[0] != float32(len(texts[i])) {
+			t.Fatalf("output %d out of order: %v", i, embedding)
+		}
+	}
+	if len(inner.calls) != 10 {
+		t.Fatalf("expected 10 batches, got %d", len(inner.calls))
+	}
+	for _, call := range inner.calls {
+		if len(call) > 10 {
+			t.Fatalf("batch of %d exceeds the batch size", len(call))
+		}
+	}
+	if peak := inner.peak.Load(); peak < 2 || peak > 3 {
+		t.Fatalf("expected 2-3 concurrent batches, peak was %d", peak)
+	}
+}
+
+func TestEmbedder_RateLimit(t *testing.T) {
+	inner := &countingEmbedder{}
+	embedder, _ := New(Config{Embedder: inner, Model: "m", BatchSize: 1, RequestsPerSecond: 50})
+
+	start := time.Now()
+	if _, err := embedder.Embed(context.Background(), []string{"a", "b", "c", "d", "e"}); err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	// Five calls at 50/s need at least four 20ms intervals
+	if elapsed := time.Since(start); elapsed < 75*time.Millisecond {
+		t.Fatalf("rate limit not applied, took %v", elapsed)
+	}
+}
+
+func TestEmbedder_Errors(t *testing.T) {
+	if _, err := New(Config{Model: "m"}); err == nil {
+		t.Fatal("expected error without an embedder")
+	}
+	if _, err := New(Config{Embedder: &countingEmbedder{}}); err == nil {
+		t.Fatal("expected error without a model")
+	}
+
+	store := NewMemoryStore(0)
+	embedder, _ := New(Config{Embedder: &countingEmbedder{fail: true}, Model: "m", Store: store})
+	if _, err := embedder.Embed(context.Background(), []string{"a"}); err == nil {
+		t.Fatal("expected provider error")
+	}
+	if store.Len() != 0 {
+		t.Fatal("failed embeddings must not be cached")
+	}
+}
+
+func TestEmbedder_CancelledContextNotCached(t *testing.T) {
+	// select picks randomly between ready cases, so try several times
+	for i := 0; i < 20; i++ {
+		store := NewMemoryStore(0)
+		embedder, _ := New(Config{Embedder: &countingEmbedder{}, Model: "m", Store: store})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := embedder.Embed(ctx, []string{"a", "bb"}); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Embed() with a cancelled context error = %v, want context.Canceled", err)
+		}
+		if store.Len() != 0 {
+			t.Fatal("a cancelled call must not write the cache")
+		}
+
+		got, err := embedder.Embed(context.Background(), []string{"a", "bb"})
+		if err != nil {
+			t.Fatalf("Embed() error = %v", err)
+		}
+		if want := [][]float32{{1}, {2}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Embed() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEmbedder_EmptyEmbeddingNotCached(t *testing.T) {
+	store := NewMemoryStore(0)
+	embedder, _ := New(Config{Embedder: &countingEmbedder{empty: true}, Model: "m", Store: store})
+	if _, err := embedder.Embed(context.Background(), []string{"a"}); err == nil {
+		t.Fatal("expected error for an empty embedding")
+	}
+	if store.Len() != 0 {
+		t.Fatal("empty embeddings must not be cached")
+	}
+}
+
+func TestMemoryStore_Evicts(t *testing.T) {
+	store := NewMemoryStore(2)
+	ctx := context.Background()
+	store.Set(ctx, map[string][]float32{"a": {1}})
+	store.Set(ctx, map[string][]float32{"b": {2}})
+	store.Get(ctx, []string{"a"}) // a is now more recent than b
+	store.Set(ctx, map[string][]float32{"c": {3}})
+
+	got, _ := store.Get(ctx, []string{"a", "b", "c"})
+	if len(got) != 2 || got["b"] != nil {
+		t.Fatalf("expected b to be evicted, got %v", got)
+	}
+}
+
+func TestSQLiteStore(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db, "")
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	ctx := context.Background()
+	if err := store.Set(ctx, map[string][]float32{"a": {0.5, -1}, "b": {2}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, err := store.Get(ctx, []string{"a", "missing"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(got) != 1 || !reflect.DeepEqual(got["a"], []float32{0.5, -1}) {
+		t.Fatalf("Get() = %v", got)
+	}
+
+	if _, err := NewSQLiteStore(db, "bad name"); err == nil {
+		t.Fatal("expected error for an invalid table name")
+	}
+}
@@ -0,0 +1,69 @@
+//go:build redis
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// RedisStore keeps embeddings in Redis, shared across processes. Like the
+// other Redis integrations it is an optional build: compile with `-tags redis`.
+type RedisStore struct {
+	client goredis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisStore creates a RedisStore. Keys are stored as <prefix>:<key>
+// (default prefix: agno:embedding); ttl of zero keeps entries forever.
+func NewRedisStore(client goredis.UniversalClient, prefix string, ttl time.Duration) (*RedisStore, error) {
+	if client == nil {
+		return nil, fmt.Errorf("redis client cannot be nil")
+	}
+	if prefix == "" {
+		prefix = "agno:embedding"
+	}
+	return &RedisStore{client: client, prefix: prefix, ttl: ttl}, nil
+}
+
+func (r *RedisStore) key(key string) string { return r.prefix + ":" + key }
+
+// Get implements Store
+func (r *RedisStore) Get(ctx context.Context, keys []string) (map[string][]float32, error) {
+	out := make(map[string][]float32)
+	if len(keys) == 0 {
+		return out, nil
+	}
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = r.key(key)
+	}
+	values, err := r.client.MGet(ctx, redisKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			out[keys[i]] = decodeVector([]byte(s))
+		}
+	}
+	return out, nil
+}
+
+// Set implements Store
+func (r *RedisStore) Set(ctx context.Context, entries map[string][]float32) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	_, err := r.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for key, embedding := range entries {
+			pipe.Set(ctx, r.key(key), encodeVector(embedding), r.ttl)
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+
+	_ "modernc.org/sqlite"
+)
+
+// Store persists embeddings by cache key
+type Store interface {
+	// Get returns the cached embeddings for keys; missing keys are omitted
+	Get(ctx context.Context, keys []string) (map[string][]float32, error)
+
+	// Set stores embeddings by key
+	Set(ctx context.Context, entries map[string][]float32) error
+}
+
+// MemoryStore is an in-process LRU Store
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front is most recently used
+	entries    map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key       string
+	embedding []float32
+}
+
+// NewMemoryStore creates a MemoryStore holding at most maxEntries embeddings;
+// zero or less means unbounded
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements Store
+func (m *MemoryStore) Get(ctx context.Context, keys []string) (map[string][]float32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string][]float32)
+	for _, key := range keys {
+		if el, ok := m.entries[key]; ok {
+			m.order.MoveToFront(el)
+			out[key] = el.Value.(*memoryEntry).embedding
+		}
+	}
+	return out, nil
+}
+
+// Set implements Store
+func (m *MemoryStore) Set(ctx context.Context, entries map[string][]float32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, embedding := range entries {
+		if el, ok := m.entries[key]; ok {
+			el.Value.(*memoryEntry).embedding = embedding
+			m.order.MoveToFront(el)
+			continue
+		}
+		m.entries[key] = m.order.PushFront(&memoryEntry{key: key, embedding: embedding})
+	}
+	for m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of cached embeddings
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+var tableNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// SQLiteStore persists embeddings in a SQLite table
+type SQLiteStore struct {
+	db    *sql.DB
+	table string
+}
+
+// NewSQLiteStore creates the cache table (default: embedding_cache) if needed
+func NewSQLiteStore(db *sql.DB, table string) (*SQLiteStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+	if table == "" {
+		table = "embedding_cache"
+	}
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name: %s", table)
+	}
+	ddl := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		key TEXT PRIMARY KEY,
+		embedding BLOB NOT NULL
+	)`, table)
+	if _, err := db.Exec(ddl); err != nil {
+		return nil, fmt.Errorf("failed to create cache table: %w", err)
+	}
+	return &SQLiteStore{db: db, table: table}, nil
+}
+
+// Get implements Store
+func (s *SQLiteStore) Get(ctx context.Context, keys []string) (map[string][]float32, error) {
+	out := make(map[string][]float32)
+	// Stay well below SQLite's bound parameter limit
+	const chunk = 500
+	for start := 0; start < len(keys); start += chunk {
+		part := keys[start:min(start+chunk, len(keys))]
+		args := make([]interface{}, len(part))
+		for i, key := range part {
+			args[i] = key
+		}
+		query := fmt.Sprintf(`SELECT key, embedding FROM %s WHERE key IN (%s)`,
+			s.table, strings.TrimSuffix(strings.Repeat("?, ", len(part)), ", "))
+
+		rows, err := s.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var (
+				key  string
+				blob []byte
+			)
+			if err := rows.Scan(&key, &blob); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			out[key] = decodeVector(blob)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Set implements Store
+func (s *SQLiteStore) Set(ctx context.Context, entries map[string][]float32) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT OR REPLACE INTO %s (key, embedding) VALUES (?, ?)`, s.table))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for key, embedding := range entries {
+		if _, err := stmt.ExecContext(ctx, key, encodeVector(embedding)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// encodeVector packs an embedding as little-endian float32
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
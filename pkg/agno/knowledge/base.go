@@ -0,0 +1,390 @@
+package knowledge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+const (
+	defaultBatchSize   = 100
+	defaultSearchLimit = 5
+
+	// searchCandidateFactor widens the first retrieval stage when results are
+	// fused or reranked, so the final top results can come from deeper ranks
+	searchCandidateFactor = 4
+
+	// Metadata keys written to every stored chunk
+	MetadataDocumentID  = "document_id"
+	MetadataContentHash = "content_hash"
+	MetadataChunkCount  = "chunk_count"
+	MetadataChunkIndex  = "chunk_index"
+	MetadataSource      = "source"
+)
+
+// BaseConfig configures a knowledge Base
+type BaseConfig struct {
+	// VectorDB stores the chunks (required)
+	VectorDB vectordb.VectorDB
+	// Embedder computes chunk and query embeddings. When nil the VectorDB is
+	// expected to embed documents itself.
+	Embedder vectordb.EmbeddingFunction
+	// Chunker splits documents (default: CharacterChunker with 1000/100)
+	Chunker Chunker
+	// Loaders are read by Load
+	Loaders []Loader
+	// BatchSize is the number of chunks embedded and written per call (default: 100)
+	BatchSize int
+	// SearchLimit is the default number of results for Search (default: 5)
+	SearchLimit int
+	// KeywordIndex enables hybrid search: stored chunks are also indexed for
+	// BM25 keyword search, and Search fuses keyword and vector results with
+	// reciprocal rank fusion
+	KeywordIndex *BM25Index
+	// RRFK is the reciprocal rank fusion constant (default: DefaultRRFK)
+	RRFK int
+	// Reranker reorders the retrieved candidates before the top results are
+	// returned
+	Reranker Reranker
+}
+
+// Base ties loaders, a chunker, an embedder and a VectorDB together. It
+// ingests documents into the VectorDB and searches them.
+//
+// Every chunk stores the SHA-256 hash of its source document, so ingesting
+// an unchanged document again is skipped and a changed one replaces its
+// previous chunks.
+//
+// With a KeywordIndex the Base runs hybrid search. The index lives in memory:
+// loading documents that are already stored (for instance after a restart)
+// re-indexes their chunks from the VectorDB without embedding them again.
+type Base struct {
+	vectorDB     vectordb.VectorDB
+	embedder     vectordb.EmbeddingFunction
+	chunker      Chunker
+	loaders      []Loader
+	batchSize    int
+	searchLimit  int
+	keywordIndex *BM25Index
+	rrfK         int
+	reranker     Reranker
+}
+
+// IngestResult summarizes an ingestion
+type IngestResult struct {
+	Documents int `json:"documents"` // Documents written
+	Skipped   int `json:"skipped"`   // Unchanged documents
+	Chunks    int `json:"chunks"`    // Chunks written
+}
+
+// NewBase creates a knowledge base
+func NewBase(config BaseConfig) (*Base, error) {
+	if config.VectorDB == nil {
+		return nil, fmt.Errorf("vector db is required")
+	}
+	if config.Chunker == nil {
+		config.Chunker = NewCharacterChunker(1000, 100)
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.SearchLimit <= 0 {
+		config.SearchLimit = defaultSearchLimit
+	}
+
+	if config.RRFK <= 0 {
+		config.RRFK = DefaultRRFK
+	}
+
+	return &Base{
+		vectorDB:     config.VectorDB,
+		embedder:     config.Embedder,
+		chunker:      config.Chunker,
+		loaders:      config.Loaders,
+		batchSize:    config.BatchSize,
+		searchLimit:  config.SearchLimit,
+		keywordIndex: config.KeywordIndex,
+		rrfK:         config.RRFK,
+		reranker:     config.Reranker,
+	}, nil
+}
+
+// VectorDB returns the underlying vector database
+func (b *Base) VectorDB() vectordb.VectorDB {
+	return b.vectorDB
+}
+
+// Load reads every configured loader and ingests the documents
+func (b *Base) Load(ctx context.Context) (*IngestResult, error) {
+	var docs []Document
+	for _, loader := range b.loaders {
+		loaded, err := loader.Load()
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, loaded...)
+	}
+	return b.LoadDocuments(ctx, docs)
+}
+
+// LoadDocuments chunks, embeds and stores documents, skipping the ones whose
+// content is unchanged since they were last stored. Documents without an ID
+// are identified by their Source, or by their content hash.
+func (b *Base) LoadDocuments(ctx context.Context, docs []Document) (*IngestResult, error) {
+	result := &IngestResult{}
+
+	for _, doc := range docs {
+		hash := contentHash(doc.Content)
+		if doc.ID == "" {
+			doc.ID = doc.Source
+		}
+		if doc.ID == "" {
+			doc.ID = "doc_" + hash[:16]
+		}
+
+		previousHash, previousChunks, err := b.storedState(ctx, doc.ID)
+		if err != nil {
+			return result, err
+		}
+		if previousHash == hash {
+			if err := b.reindex(ctx, doc.ID, previousChunks); err != nil {
+				return result, err
+			}
+			result.Skipped++
+			continue
+		}
+
+		chunks, err := b.chunker.Chunk(doc)
+		if err != nil {
+			return result, fmt.Errorf("failed to chunk document %s: %w", doc.ID, err)
+		}
+
+		if previousChunks > 0 {
+			if err := b.vectorDB.Delete(ctx, chunkIDs(doc.ID, previousChunks)); err != nil {
+				return result, fmt.Errorf("failed to replace document %s: %w", doc.ID, err)
+			}
+			if b.keywordIndex != nil {
+				b.keywordIndex.Remove(chunkIDs(doc.ID, previousChunks)...)
+			}
+		}
+
+		stored := make([]vectordb.Document, 0, len(chunks))
+		for i, chunk := range chunks {
+			metadata := make(map[string]interface{}, len(chunk.Metadata)+5)
+			for k, v := range chunk.Metadata {
+				metadata[k] = v
+			}
+			metadata[MetadataDocumentID] = doc.ID
+			metadata[MetadataContentHash] = hash
+			metadata[MetadataChunkCount] = len(chunks)
+			metadata[MetadataChunkIndex] = i
+			if doc.Source != "" {
+				metadata[MetadataSource] = doc.Source
+			}
+			stored = append(stored, vectordb.Document{
+				ID:       chunkID(doc.ID, i),
+				Content:  chunk.Content,
+				Metadata: metadata,
+			})
+		}
+		if err := b.write(ctx, stored); err != nil {
+			return result, fmt.Errorf("failed to store document %s: %w", doc.ID, err)
+		}
+		if b.keywordIndex != nil {
+			b.keywordIndex.Add(stored...)
+		}
+
+		result.Documents++
+		result.Chunks += len(stored)
+	}
+
+	return result, nil
+}
+
+// Remove deletes the chunks of the given documents
+func (b *Base) Remove(ctx context.Context, docIDs ...string) error {
+	for _, id := range docIDs {
+		_, count, err := b.storedState(ctx, id)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			continue
+		}
+		if err := b.vectorDB.Delete(ctx, chunkIDs(id, count)); err != nil {
+			return fmt.Errorf("failed to remove document %s: %w", id, err)
+		}
+		if b.keywordIndex != nil {
+			b.keywordIndex.Remove(chunkIDs(id, count)...)
+		}
+	}
+	return nil
+}
+
+// reindex adds the stored chunks of a document to the keyword index when
+// they are missing from it
+func (b *Base) reindex(ctx context.Context, docID string, count int) error {
+	if b.keywordIndex == nil || count == 0 || b.keywordIndex.Has(chunkID(docID, 0)) {
+		return nil
+	}
+	stored, err := b.vectorDB.Get(ctx, chunkIDs(docID, count))
+	if err != nil {
+		return fmt.Errorf("failed to index document %s: %w", docID, err)
+	}
+	b.keywordIndex.Add(stored...)
+	return nil
+}
+
+// Search returns the chunks most relevant to query. A limit of zero or less
+// uses the configured SearchLimit.
+//
+// Results come from the VectorDB, fused with keyword matches when a
+// KeywordIndex is configured, then reordered by the Reranker if any.
+func (b *Base) Search(ctx context.Context, query string, limit int, filter map[string]interface{}) ([]vectordb.SearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+	if limit <= 0 {
+		limit = b.searchLimit
+	}
+
+	candidates := limit
+	if b.keywordIndex != nil || b.reranker != nil {
+		candidates = limit * searchCandidateFactor
+	}
+
+	results, err := b.vectorSearch(ctx, query, candidates, filter)
+	if err != nil {
+		return nil, err
+	}
+	if b.keywordIndex != nil {
+		results = FuseRRF(b.rrfK, results, b.keywordIndex.Search(query, candidates, filter))
+	}
+	if b.reranker != nil {
+		results, err = b.reranker.Rerank(ctx, query, results, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rerank results: %w", err)
+		}
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (b *Base) vectorSearch(ctx context.Context, query string, limit int, filter map[string]interface{}) ([]vectordb.SearchResult, error) {
+	if b.embedder == nil {
+		return b.vectorDB.Query(ctx, query, limit, filter)
+	}
+	embedding, err := b.embedder.EmbedSingle(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	return b.vectorDB.QueryWithEmbedding(ctx, embedding, limit, filter)
+}
+
+// write embeds (when an embedder is configured) and adds documents in batches
+func (b *Base) write(ctx context.Context, docs []vectordb.Document) error {
+	for start := 0; start < len(docs); start += b.batchSize {
+		end := start + b.batchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+		batch := docs[start:end]
+
+		if b.embedder != nil {
+			texts := make([]string, len(batch))
+			for i, doc := range batch {
+				texts[i] = doc.Content
+			}
+			embeddings, err := b.embedder.Embed(ctx, texts)
+			if err != nil {
+				return fmt.Errorf("failed to embed chunks: %w", err)
+			}
+			if len(embeddings) != len(batch) {
+				return fmt.Errorf("embedder returned %d embeddings for %d chunks", len(embeddings), len(batch))
+			}
+			for i := range batch {
+				batch[i].Embedding = embeddings[i]
+			}
+		}
+
+		if err := b.vectorDB.Add(ctx, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storedState returns the content hash and chunk count recorded on the first
+// stored chunk of a document, or zero values if it is not stored
+func (b *Base) storedState(ctx context.Context, docID string) (string, int, error) {
+	stored, err := b.vectorDB.Get(ctx, []string{chunkID(docID, 0)})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to look up document %s: %w", docID, err)
+	}
+	for _, doc := range stored {
+		if doc.ID != chunkID(docID, 0) {
+			continue
+		}
+		hash, _ := doc.Metadata[MetadataContentHash].(string)
+		return hash, metadataInt(doc.Metadata[MetadataChunkCount]), nil
+	}
+	return "", 0, nil
+}
+
+// FormatResults renders search results as numbered passages for a prompt
+func FormatResults(results []vectordb.SearchResult) string {
+	var b strings.Builder
+	for i, result := range results {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "[%d]", i+1)
+		if source, ok := result.Metadata[MetadataSource].(string); ok && source != "" {
+			fmt.Fprintf(&b, " (source: %s)", source)
+		}
+		b.WriteString("\n")
+		b.WriteString(strings.TrimSpace(result.Content))
+	}
+	return b.String()
+}
+
+func chunkID(docID string, index int) string {
+	return fmt.Sprintf("%s_chunk_%d", docID, index)
+}
+
+func chunkIDs(docID string, count int) []string {
+	ids := make([]string, count)
+	for i := range ids {
+		ids[i] = chunkID(docID, i)
+	}
+	return ids
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// metadataInt reads an integer that may have been decoded from JSON
+func metadataInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float32:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
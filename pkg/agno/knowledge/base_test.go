@@ -0,0 +1,284 @@
+package knowledge
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+// memoryVectorDB is a minimal VectorDB that matches queries by substring
+type memoryVectorDB struct {
+	vectordb.VectorDB
+	docs    map[string]vectordb.Document
+	adds    int
+	deletes int
+}
+
+func newMemoryVectorDB() *memoryVectorDB {
+	return &memoryVectorDB{docs: make(map[string]vectordb.Document)}
+}
+
+func (m *memoryVectorDB) Add(ctx context.Context, docs []vectordb.Document) error {
+	m.adds++
+	for _, doc := range docs {
+		m.docs[doc.ID] = doc
+	}
+	return nil
+}
+
+func (m *memoryVectorDB) Delete(ctx context.Context, ids []string) error {
+	m.deletes++
+	for _, id := range ids {
+		delete(m.docs, id)
+	}
+	return nil
+}
+
+func (m *memoryVectorDB) Get(ctx context.Context, ids []string) ([]vectordb.Document, error) {
+	var out []vectordb.Document
+	for _, id := range ids {
+		if doc, ok := m.docs[id]; ok {
+			out = append(out, doc)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryVectorDB) Query(ctx context.Context, query string, limit int, filter map[string]interface{}) ([]vectordb.SearchResult, error) {
+	var out []vectordb.SearchResult
+	for _, doc := range m.docs {
+		if strings.Contains(strings.ToLower(doc.Content), strings.ToLower(query)) {
+			out = append(out, vectordb.SearchResult{ID: doc.ID, Content: doc.Content, Metadata: doc.Metadata})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (m *memoryVectorDB) QueryWithEmbedding(ctx context.Context, embedding []float32, limit int, filter map[string]interface{}) ([]vectordb.SearchResult, error) {
+	var out []vectordb.SearchResult
+	for _, doc := range m.docs {
+		if len(doc.Embedding) > 0 && doc.Embedding[0] == embedding[0] {
+			out = append(out, vectordb.SearchResult{ID: doc.ID, Content: doc.Content, Metadata: doc.Metadata})
+		}
+	}
+	return out, nil
+}
+
+// lengthEmbedder embeds a text as its length
+type lengthEmbedder struct {
+	calls int
+}
+
+func (e *lengthEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i] = []float32{float32(len(text))}
+	}
+	return out, nil
+}
+
+func (e *lengthEmbedder) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+	return []float32{float32(len(text))}, nil
+}
+
+func TestNewBase_RequiresVectorDB(t *testing.T) {
+	if _, err := NewBase(BaseConfig{}); err == nil {
+		t.Fatal("expected error without a vector db")
+	}
+}
+
+func TestBase_LoadDocumentsSkipsUnchanged(t *testing.T) {
+	db := newMemoryVectorDB()
+	base, err := NewBase(BaseConfig{VectorDB: db, Chunker: NewParagraphChunker(20)})
+	if err != nil {
+		t.Fatalf("NewBase() error = %v", err)
+	}
+	ctx := context.Background()
+
+	doc := Document{ID: "guide", Content: "Paragraph one.\n\nParagraph two.", Source: "guide.md"}
+	result, err := base.LoadDocuments(ctx, []Document{doc})
+	if err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+	if result.Documents != 1 || result.Chunks != 2 || len(db.docs) != 2 {
+		t.Fatalf("unexpected first ingestion: %+v, stored %d", result, len(db.docs))
+	}
+	first := db.docs["guide_chunk_0"]
+	if first.Metadata[MetadataDocumentID] != "guide" || first.Metadata[MetadataSource] != "guide.md" {
+		t.Fatalf("missing chunk metadata: %+v", first.Metadata)
+	}
+
+	result, err = base.LoadDocuments(ctx, []Document{doc})
+	if err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+	if result.Skipped != 1 || result.Documents != 0 || db.adds != 1 {
+		t.Fatalf("unchanged document should be skipped: %+v, adds %d", result, db.adds)
+	}
+
+	doc.Content = "A single shorter one."
+	result, err = base.LoadDocuments(ctx, []Document{doc})
+	if err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+	if result.Documents != 1 || len(db.docs) != 1 || db.docs["guide_chunk_0"].Content != doc.Content {
+		t.Fatalf("changed document should replace its chunks: %+v, stored %+v", result, db.docs)
+	}
+
+	if err := base.Remove(ctx, "guide"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if len(db.docs) != 0 {
+		t.Fatalf("expected no chunks after Remove, got %d", len(db.docs))
+	}
+}
+
+func TestBase_LoadFromLoadersWithEmbedder(t *testing.T) {
+	db := newMemoryVectorDB()
+	embedder := &lengthEmbedder{}
+	base, err := NewBase(BaseConfig{
+		VectorDB: db,
+		Embedder: embedder,
+		Loaders: []Loader{
+			NewReaderLoader(strings.NewReader("alpha"), "a", nil),
+			NewReaderLoader(strings.NewReader("beta!"), "b", nil),
+		},
+		BatchSize: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewBase() error = %v", err)
+	}
+	ctx := context.Background()
+
+	result, err := base.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if result.Documents != 2 || embedder.calls != 2 {
+		t.Fatalf("expected 2 documents embedded in batches of 1: %+v, calls %d", result, embedder.calls)
+	}
+	if got := db.docs["a_chunk_0"].Embedding; len(got) != 1 || got[0] != 5 {
+		t.Fatalf("expected stored embedding, got %v", got)
+	}
+
+	results, err := base.Search(ctx, "12345", 0, nil)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected the query to be embedded and matched, got %+v", results)
+	}
+}
+
+func TestToolkit_Search(t *testing.T) {
+	db := newMemoryVectorDB()
+	base, _ := NewBase(BaseConfig{VectorDB: db})
+	ctx := context.Background()
+	base.LoadDocuments(ctx, []Document{
+		{ID: "go", Content: "Go was designed at Google.", Source: "go.txt"},
+		{ID: "rust", Content: "Rust was designed at Mozilla."},
+	})
+
+	fn := NewToolkit(base).Functions()[SearchToolName]
+	if fn == nil {
+		t.Fatal("search_knowledge_base not registered")
+	}
+
+	out, err := fn.Handler(ctx, map[string]interface{}{"query": "google", "limit": float64(3)})
+	if err != nil {
+		t.Fatalf("Handler() error = %v", err)
+	}
+	text := out.(string)
+	if !strings.Contains(text, "[1] (source: go.txt)\nGo was designed at Google.") || strings.Contains(text, "Rust") {
+		t.Fatalf("unexpected tool output %q", text)
+	}
+
+	out, _ = fn.Handler(ctx, map[string]interface{}{"query": "python"})
+	if !strings.Contains(out.(string), "No relevant information") {
+		t.Fatalf("expected a no-results message, got %q", out)
+	}
+}
+
+// reverseReranker returns the results in reverse order
+type reverseReranker struct {
+	topN int
+}
+
+func (r *reverseReranker) Rerank(ctx context.Context, query string, results []vectordb.SearchResult, topN int) ([]vectordb.SearchResult, error) {
+	r.topN = topN
+	out := make([]vectordb.SearchResult, 0, len(results))
+	for i := len(results) - 1; i >= 0; i-- {
+		out = append(out, results[i])
+	}
+	return out, nil
+}
+
+func TestBase_HybridSearch(t *testing.T) {
+	db := newMemoryVectorDB()
+	docs := []Document{
+		{ID: "pump", Content: "Pump fails with code ERR-404 after an update."},
+		{ID: "valve", Content: "Valve maintenance guide."},
+	}
+	ctx := context.Background()
+
+	base, _ := NewBase(BaseConfig{VectorDB: db, KeywordIndex: NewBM25Index(BM25Config{})})
+	if _, err := base.LoadDocuments(ctx, docs); err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+
+	// The substring-matching vector db finds nothing for this query; the
+	// keyword index does.
+	results, err := base.Search(ctx, "what is err-404", 3, nil)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "pump_chunk_0" {
+		t.Fatalf("expected a keyword match, got %+v", results)
+	}
+
+	// A fresh index over the same vector db is rebuilt by loading again.
+	index := NewBM25Index(BM25Config{})
+	restarted, _ := NewBase(BaseConfig{VectorDB: db, KeywordIndex: index})
+	result, err := restarted.LoadDocuments(ctx, docs)
+	if err != nil {
+		t.Fatalf("LoadDocuments() error = %v", err)
+	}
+	if result.Skipped != 2 || index.Len() != 2 || db.adds != 2 {
+		t.Fatalf("expected reindexing without rewriting: %+v, indexed %d, adds %d", result, index.Len(), db.adds)
+	}
+
+	if err := restarted.Remove(ctx, "pump"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if index.Has("pump_chunk_0") {
+		t.Fatal("removed document still indexed")
+	}
+}
+
+func TestBase_SearchReranks(t *testing.T) {
+	db := newMemoryVectorDB()
+	reranker := &reverseReranker{}
+	base, _ := NewBase(BaseConfig{VectorDB: db, Reranker: reranker})
+	ctx := context.Background()
+	base.LoadDocuments(ctx, []Document{
+		{ID: "a", Content: "go one"},
+		{ID: "b", Content: "go two"},
+		{ID: "c", Content: "go three"},
+	})
+
+	results, err := base.Search(ctx, "go", 2, nil)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if reranker.topN != 2 || len(results) != 2 || results[0].ID != "c_chunk_0" || results[1].ID != "b_chunk_0" {
+		t.Fatalf("expected reranked candidates, got %+v", results)
+	}
+}
@@ -0,0 +1,240 @@
+package knowledge
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+// BM25Config configures a BM25Index
+type BM25Config struct {
+	// K1 controls term frequency saturation (default: 1.2)
+	K1 float64
+	// B controls document length normalization (default: 0.75)
+	B float64
+}
+
+// BM25Index is an in-memory keyword index scored with Okapi BM25. It
+// complements vector search for exact terms such as part numbers, error
+// codes and identifiers, which embeddings tend to blur.
+//
+// Terms are lowercased runs of letters and digits. Runs joined by "-", "_",
+// "." or "/" (e.g. "ERR-404", "v1.2") are indexed both whole and by part, and
+// CJK characters are indexed individually. The index is safe for concurrent
+// use.
+type BM25Index struct {
+	mu       sync.RWMutex
+	k1       float64
+	b        float64
+	docs     map[string]bm25Document
+	postings map[string]map[string]int // term -> document ID -> term frequency
+	totalLen int
+}
+
+type bm25Document struct {
+	doc    vectordb.Document
+	length int
+}
+
+// NewBM25Index creates an empty keyword index
+func NewBM25Index(config BM25Config) *BM25Index {
+	if config.K1 <= 0 {
+		config.K1 = 1.2
+	}
+	if config.B <= 0 || config.B > 1 {
+		config.B = 0.75
+	}
+	return &BM25Index{
+		k1:       config.K1,
+		b:        config.B,
+		docs:     make(map[string]bm25Document),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+// Add indexes documents, replacing any previously indexed under the same ID
+func (i *BM25Index) Add(docs ...vectordb.Document) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, doc := range docs {
+		i.remove(doc.ID)
+
+		terms := keywordTerms(doc.Content)
+		for _, term := range terms {
+			if i.postings[term] == nil {
+				i.postings[term] = make(map[string]int)
+			}
+			i.postings[term][doc.ID]++
+		}
+		doc.Embedding = nil
+		i.docs[doc.ID] = bm25Document{doc: doc, length: len(terms)}
+		i.totalLen += len(terms)
+	}
+}
+
+// Remove deletes documents from the index
+func (i *BM25Index) Remove(ids ...string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, id := range ids {
+		i.remove(id)
+	}
+}
+
+func (i *BM25Index) remove(id string) {
+	entry, ok := i.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range keywordTerms(entry.doc.Content) {
+		if postings := i.postings[term]; postings != nil {
+			delete(postings, id)
+			if len(postings) == 0 {
+				delete(i.postings, term)
+			}
+		}
+	}
+	i.totalLen -= entry.length
+	delete(i.docs, id)
+}
+
+// Has reports whether a document is indexed
+func (i *BM25Index) Has(id string) bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	_, ok := i.docs[id]
+	return ok
+}
+
+// Len returns the number of indexed documents
+func (i *BM25Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.docs)
+}
+
+// Search returns up to limit documents matching query, best first. Only
+// documents whose metadata satisfies filter (see vectordb.ValidateFilter)
+// are considered.
+func (i *BM25Index) Search(query string, limit int, filter map[string]interface{}) []vectordb.SearchResult {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if len(i.docs) == 0 || limit <= 0 {
+		return nil
+	}
+
+	n := float64(len(i.docs))
+	avgLen := float64(i.totalLen) / n
+	if avgLen == 0 {
+		avgLen = 1
+	}
+
+	scores := make(map[string]float64)
+	seen := make(map[string]bool)
+	for _, term := range keywordTerms(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		postings := i.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range postings {
+			length := float64(i.docs[id].length)
+			freq := float64(tf)
+			scores[id] += idf * freq * (i.k1 + 1) / (freq + i.k1*(1-i.b+i.b*length/avgLen))
+		}
+	}
+
+	results := make([]vectordb.SearchResult, 0, len(scores))
+	for id, score := range scores {
+		doc := i.docs[id].doc
+		if !matchesFilter(doc.Metadata, filter) {
+			continue
+		}
+		results = append(results, vectordb.SearchResult{
+			ID:       doc.ID,
+			Content:  doc.Content,
+			Metadata: doc.Metadata,
+			Score:    float32(score),
+		})
+	}
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].ID < results[b].ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// matchesFilter applies a VectorDB metadata filter; invalid filters match
+// nothing
+func matchesFilter(metadata, filter map[string]interface{}) bool {
+	ok, err := vectordb.MatchesFilter(metadata, filter)
+	return err == nil && ok
+}
+
+func isKeywordConnector(r rune) bool {
+	return r == '-' || r == '_' || r == '.' || r == '/'
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// keywordTerms tokenizes text for the keyword index
+func keywordTerms(text string) []string {
+	var (
+		terms []string
+		word  []rune
+	)
+	flush := func() {
+		// Drop trailing connectors such as the period ending a sentence.
+		for len(word) > 0 && isKeywordConnector(word[len(word)-1]) {
+			word = word[:len(word)-1]
+		}
+		if len(word) == 0 {
+			return
+		}
+		whole := string(word)
+		terms = append(terms, whole)
+		if strings.IndexFunc(whole, isKeywordConnector) >= 0 {
+			for _, part := range strings.FieldsFunc(whole, isKeywordConnector) {
+				terms = append(terms, part)
+			}
+		}
+		word = word[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flush()
+			terms = append(terms, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		case isKeywordConnector(r) && len(word) > 0:
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return terms
+}
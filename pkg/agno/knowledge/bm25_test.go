@@ -0,0 +1,53 @@
+package knowledge
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+func TestKeywordTerms(t *testing.T) {
+	got := keywordTerms("Error ERR-404 in v1.2, see docs/api. 知识")
+	want := []string{"error", "err-404", "err", "404", "in", "v1.2", "v1", "2", "see", "docs/api", "docs", "api", "知", "识"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("keywordTerms() = %q, want %q", got, want)
+	}
+}
+
+func TestBM25Index_Search(t *testing.T) {
+	index := NewBM25Index(BM25Config{})
+	index.Add(
+		vectordb.Document{ID: "a", Content: "Replace part XJ-9000 when the pump fails.", Metadata: map[string]interface{}{"lang": "en"}},
+		vectordb.Document{ID: "b", Content: "The pump is covered by warranty. The pump is quiet.", Metadata: map[string]interface{}{"lang": "en"}},
+		vectordb.Document{ID: "c", Content: "Bomba XJ-9000 reemplazo.", Metadata: map[string]interface{}{"lang": "es"}},
+	)
+
+	results := index.Search("xj-9000", 10, nil)
+	if len(results) != 2 || results[0].Score <= 0 {
+		t.Fatalf("expected both part number matches, got %+v", results)
+	}
+
+	results = index.Search("pump", 10, nil)
+	if len(results) != 2 || results[0].ID != "b" {
+		t.Fatalf("expected the document mentioning pump twice first, got %+v", results)
+	}
+
+	results = index.Search("XJ-9000", 10, map[string]interface{}{"lang": "es"})
+	if len(results) != 1 || results[0].ID != "c" {
+		t.Fatalf("filter not applied: %+v", results)
+	}
+
+	index.Remove("c")
+	if index.Has("c") || index.Len() != 2 {
+		t.Fatalf("document not removed")
+	}
+	if results := index.Search("bomba", 10, nil); len(results) != 0 {
+		t.Fatalf("removed document still matches: %+v", results)
+	}
+
+	index.Add(vectordb.Document{ID: "a", Content: "Updated text"})
+	if results := index.Search("xj-9000", 10, nil); len(results) != 0 {
+		t.Fatalf("replaced document still matches old content: %+v", results)
+	}
+}
@@ -272,7 +272,7 @@ func (c *ParagraphChunker) Chunk(doc Document) ([]Chunk, error) {
 	var currentChunk strings.Builder
 	index := 0
 
-	for i, para := range paragraphs {
+	for _, para := range paragraphs {
 		para = strings.TrimSpace(para)
 		if len(para) == 0 {
 			continue
@@ -319,11 +319,6 @@ func (c *ParagraphChunker) Chunk(doc Document) ([]Chunk, error) {
 			currentChunk.WriteString("\n\n")
 		}
 		currentChunk.WriteString(para)
-
-		// If this is the last paragraph, save the chunk
-		if i == len(paragraphs)-1 {
-			chunks = append(chunks, c.createChunk(doc, currentChunk.String(), index))
-		}
 	}
 
 	// Add any remaining content
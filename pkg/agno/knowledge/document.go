@@ -47,20 +47,17 @@ func (l *TextLoader) Load() ([]Document, error) {
 	}
 
 	doc := Document{
-		ID:      filepath.Base(l.FilePath),
-		Content: string(content),
-		Source:  l.FilePath,
-		Metadata: map[string]interface{}{
-			"filename": filepath.Base(l.FilePath),
-			"path":     l.FilePath,
-			"ext":      filepath.Ext(l.FilePath),
-		},
+		ID:       filepath.Base(l.FilePath),
+		Content:  string(content),
+		Source:   l.FilePath,
+		Metadata: fileMetadata(l.FilePath),
 	}
 
 	return []Document{doc}, nil
 }
 
-// DirectoryLoader loads documents from a directory
+// DirectoryLoader loads documents from a directory. Each file is read by the
+// loader registered for its extension (see LoaderForFile).
 type DirectoryLoader struct {
 	DirPath    string
 	Pattern    string // File pattern to match (e.g., "*.txt", "*.md")
@@ -109,23 +106,12 @@ func (l *DirectoryLoader) Load() ([]Document, error) {
 		}
 
 		// Load file
-		content, err := os.ReadFile(path)
+		docs, err := LoaderForFile(path).Load()
 		if err != nil {
-			return fmt.Errorf("failed to read file %s: %w", path, err)
-		}
-
-		doc := Document{
-			ID:      filepath.Base(path),
-			Content: string(content),
-			Source:  path,
-			Metadata: map[string]interface{}{
-				"filename": filepath.Base(path),
-				"path":     path,
-				"ext":      filepath.Ext(path),
-			},
+			return err
 		}
 
-		documents = append(documents, doc)
+		documents = append(documents, docs...)
 		return nil
 	}
 
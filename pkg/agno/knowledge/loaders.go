@@ -0,0 +1,575 @@
+package knowledge
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// Metadata keys set by the format-aware loaders
+const (
+	MetadataTitle    = "title"
+	MetadataHeadings = "headings" // Newline-separated, in document order
+)
+
+// FileLoaderFunc creates a loader for a file path
+type FileLoaderFunc func(path string) Loader
+
+var (
+	fileLoadersMu sync.RWMutex
+	fileLoaders   = map[string]FileLoaderFunc{
+		".pdf":      func(path string) Loader { return NewPDFLoader(path) },
+		".docx":     func(path string) Loader { return NewDOCXLoader(path) },
+		".html":     func(path string) Loader { return NewHTMLLoader(path) },
+		".htm":      func(path string) Loader { return NewHTMLLoader(path) },
+		".md":       func(path string) Loader { return NewMarkdownLoader(path) },
+		".markdown": func(path string) Loader { return NewMarkdownLoader(path) },
+	}
+)
+
+// RegisterFileLoader sets the loader used for files with the given extension
+// (e.g. ".csv"), replacing any previous registration
+func RegisterFileLoader(ext string, fn FileLoaderFunc) {
+	fileLoadersMu.Lock()
+	defer fileLoadersMu.Unlock()
+	fileLoaders[strings.ToLower(ext)] = fn
+}
+
+// LoaderForFile returns the loader registered for the file's extension,
+// falling back to a TextLoader
+func LoaderForFile(path string) Loader {
+	fileLoadersMu.RLock()
+	fn, ok := fileLoaders[strings.ToLower(filepath.Ext(path))]
+	fileLoadersMu.RUnlock()
+	if ok {
+		return fn(path)
+	}
+	return NewTextLoader(path)
+}
+
+func fileMetadata(path string) map[string]interface{} {
+	return map[string]interface{}{
+		"filename": filepath.Base(path),
+		"path":     path,
+		"ext":      filepath.Ext(path),
+	}
+}
+
+// fileDocument builds the single document produced by the file loaders
+func fileDocument(path, content, title string, headings []string) Document {
+	metadata := fileMetadata(path)
+	if title != "" {
+		metadata[MetadataTitle] = title
+	}
+	if len(headings) > 0 {
+		metadata[MetadataHeadings] = strings.Join(headings, "\n")
+	}
+	return Document{
+		ID:       filepath.Base(path),
+		Content:  content,
+		Source:   path,
+		Metadata: metadata,
+	}
+}
+
+// MarkdownLoader loads Markdown files. YAML front matter is removed from the
+// content; its title, the first top-level heading or the first heading
+// becomes the document title.
+type MarkdownLoader struct {
+	FilePath string
+}
+
+// NewMarkdownLoader creates a new Markdown loader
+func NewMarkdownLoader(filePath string) *MarkdownLoader {
+	return &MarkdownLoader{FilePath: filePath}
+}
+
+// Load loads a Markdown file
+func (l *MarkdownLoader) Load() ([]Document, error) {
+	data, err := os.ReadFile(l.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", l.FilePath, err)
+	}
+
+	content, frontMatter := splitFrontMatter(strings.ReplaceAll(string(data), "\r\n", "\n"))
+	headings := markdownHeadings(content)
+
+	title := frontMatter["title"]
+	for _, h := range headings {
+		if title != "" {
+			break
+		}
+		if h.level == 1 {
+			title = h.text
+		}
+	}
+	if title == "" && len(headings) > 0 {
+		title = headings[0].text
+	}
+
+	texts := make([]string, len(headings))
+	for i, h := range headings {
+		texts[i] = h.text
+	}
+	return []Document{fileDocument(l.FilePath, strings.TrimSpace(content), title, texts)}, nil
+}
+
+// splitFrontMatter removes a leading "---" delimited block and returns its
+// top-level "key: value" pairs
+func splitFrontMatter(content string) (string, map[string]string) {
+	values := make(map[string]string)
+	if !strings.HasPrefix(content, "---\n") {
+		return content, values
+	}
+	end := strings.Index(content[4:], "\n---")
+	if end < 0 {
+		return content, values
+	}
+	block := content[4 : 4+end]
+	rest := content[4+end+len("\n---"):]
+	if i := strings.IndexByte(rest, '\n'); i >= 0 {
+		rest = rest[i+1:]
+	} else {
+		rest = ""
+	}
+
+	for _, line := range strings.Split(block, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, " ") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, "'")
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return rest, values
+}
+
+type markdownHeading struct {
+	level int
+	text  string
+	line  int
+}
+
+// markdownHeadings returns the ATX headings outside fenced code blocks
+func markdownHeadings(content string) []markdownHeading {
+	var headings []markdownHeading
+	fence := ""
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if level, text, ok := parseATXHeading(line); ok {
+			headings = append(headings, markdownHeading{level: level, text: text, line: i})
+		}
+	}
+	return headings
+}
+
+func parseATXHeading(line string) (int, string, bool) {
+	if strings.HasPrefix(line, "    ") {
+		return 0, "", false
+	}
+	line = strings.TrimSpace(line)
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ' && line[level] != '\t') {
+		return 0, "", false
+	}
+	text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+	if text == "" {
+		return 0, "", false
+	}
+	return level, text, true
+}
+
+// HTMLLoader loads HTML files as text. Scripts, styles and other non-content
+// elements are dropped, headings are kept as Markdown headings and list items
+// as "- " lines. The <title> (or first <h1>) becomes the document title and
+// <meta name="description"> the "description" metadata.
+type HTMLLoader struct {
+	FilePath string
+}
+
+// NewHTMLLoader creates a new HTML loader
+func NewHTMLLoader(filePath string) *HTMLLoader {
+	return &HTMLLoader{FilePath: filePath}
+}
+
+// Load loads an HTML file
+func (l *HTMLLoader) Load() ([]Document, error) {
+	file, err := os.Open(l.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", l.FilePath, err)
+	}
+	defer file.Close()
+
+	root, err := html.Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html %s: %w", l.FilePath, err)
+	}
+
+	ex := &htmlExtractor{}
+	ex.walk(root)
+
+	title := ex.title
+	if title == "" {
+		title = ex.firstH1
+	}
+	doc := fileDocument(l.FilePath, normalizeExtractedText(ex.out.String()), title, ex.headings)
+	if ex.description != "" {
+		doc.Metadata["description"] = ex.description
+	}
+	return []Document{doc}, nil
+}
+
+var (
+	htmlSkipped = map[string]bool{
+		"script": true, "style": true, "noscript": true, "template": true,
+		"svg": true, "iframe": true, "object": true, "canvas": true,
+	}
+	htmlBlocks = map[string]bool{
+		"p": true, "div": true, "section": true, "article": true, "main": true,
+		"header": true, "footer": true, "aside": true, "nav": true, "blockquote": true,
+		"pre": true, "ul": true, "ol": true, "table": true, "tr": true, "dl": true,
+		"dt": true, "dd": true, "figure": true, "figcaption": true, "form": true, "hr": true,
+	}
+)
+
+type htmlExtractor struct {
+	out         strings.Builder
+	title       string
+	firstH1     string
+	description string
+	headings    []string
+	pre         int
+}
+
+func (e *htmlExtractor) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		e.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			e.walk(c)
+		}
+		return
+	}
+
+	tag := n.Data
+	switch {
+	case htmlSkipped[tag]:
+		return
+	case tag == "title":
+		if e.title == "" {
+			e.title = strings.Join(strings.Fields(nodeText(n)), " ")
+		}
+		return
+	case tag == "meta":
+		if strings.EqualFold(attr(n, "name"), "description") {
+			e.description = strings.TrimSpace(attr(n, "content"))
+		}
+		return
+	case tag == "br":
+		e.out.WriteString("\n")
+		return
+	case len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6':
+		text := strings.Join(strings.Fields(nodeText(n)), " ")
+		if text == "" {
+			return
+		}
+		if tag == "h1" && e.firstH1 == "" {
+			e.firstH1 = text
+		}
+		e.headings = append(e.headings, text)
+		fmt.Fprintf(&e.out, "\n\n%s %s\n\n", strings.Repeat("#", int(tag[1]-'0')), text)
+		return
+	case tag == "li":
+		e.out.WriteString("\n- ")
+	case tag == "td" || tag == "th":
+		e.out.WriteString(" ")
+	case htmlBlocks[tag]:
+		e.out.WriteString("\n\n")
+	}
+
+	if tag == "pre" {
+		e.pre++
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		e.walk(c)
+	}
+	if tag == "pre" {
+		e.pre--
+	}
+	if htmlBlocks[tag] {
+		e.out.WriteString("\n\n")
+	}
+}
+
+func (e *htmlExtractor) text(s string) {
+	if e.pre > 0 {
+		e.out.WriteString(s)
+		return
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			e.out.WriteString(" ")
+		}
+		return
+	}
+	if strings.TrimLeftFunc(s, unicode.IsSpace) != s {
+		e.out.WriteString(" ")
+	}
+	e.out.WriteString(strings.Join(fields, " "))
+	if strings.TrimRightFunc(s, unicode.IsSpace) != s {
+		e.out.WriteString(" ")
+	}
+}
+
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		}
+		if n.Type == html.ElementNode && htmlSkipped[n.Data] {
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// DOCXLoader loads Word documents. Paragraphs are separated by blank lines,
+// heading-styled paragraphs become Markdown headings and list paragraphs
+// "- " lines. The title comes from the document properties, a Title-styled
+// paragraph or the first heading.
+type DOCXLoader struct {
+	FilePath string
+}
+
+// NewDOCXLoader creates a new DOCX loader
+func NewDOCXLoader(filePath string) *DOCXLoader {
+	return &DOCXLoader{FilePath: filePath}
+}
+
+// Load loads a DOCX file
+func (l *DOCXLoader) Load() ([]Document, error) {
+	reader, err := zip.OpenReader(l.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open docx %s: %w", l.FilePath, err)
+	}
+	defer reader.Close()
+
+	body, err := readZipEntry(&reader.Reader, "word/document.xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read docx %s: %w", l.FilePath, err)
+	}
+
+	paragraphs, err := parseDOCXParagraphs(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse docx %s: %w", l.FilePath, err)
+	}
+
+	title := ""
+	if core, err := readZipEntry(&reader.Reader, "docProps/core.xml"); err == nil {
+		title = docxCoreTitle(core)
+	}
+
+	var (
+		blocks   []string
+		headings []string
+	)
+	for _, p := range paragraphs {
+		switch {
+		case p.title:
+			if title == "" {
+				title = p.text
+			}
+			blocks = append(blocks, "# "+p.text)
+		case p.level > 0:
+			headings = append(headings, p.text)
+			blocks = append(blocks, strings.Repeat("#", p.level)+" "+p.text)
+		case p.list:
+			blocks = append(blocks, "- "+p.text)
+		default:
+			blocks = append(blocks, p.text)
+		}
+	}
+	if title == "" && len(headings) > 0 {
+		title = headings[0]
+	}
+
+	return []Document{fileDocument(l.FilePath, strings.Join(blocks, "\n\n"), title, headings)}, nil
+}
+
+func readZipEntry(reader *zip.Reader, name string) ([]byte, error) {
+	for _, f := range reader.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("%s not found", name)
+}
+
+type docxParagraph struct {
+	text  string
+	level int // Heading level, 0 for body text
+	title bool
+	list  bool
+}
+
+func parseDOCXParagraphs(data []byte) ([]docxParagraph, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var (
+		paragraphs []docxParagraph
+		current    docxParagraph
+		text       strings.Builder
+		inText     bool
+	)
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				current = docxParagraph{}
+				text.Reset()
+			case "pStyle":
+				style := xmlAttr(t, "val")
+				if strings.EqualFold(style, "Title") {
+					current.title = true
+				} else if level := docxHeadingLevel(style); level > 0 {
+					current.level = level
+				}
+			case "outlineLvl":
+				if lvl, err := strconv.Atoi(xmlAttr(t, "val")); err == nil && lvl < 9 && current.level == 0 {
+					current.level = min(lvl+1, 6)
+				}
+			case "numPr":
+				current.list = true
+			case "t":
+				inText = true
+			case "tab":
+				text.WriteString("\t")
+			case "br", "cr":
+				text.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				current.text = strings.TrimSpace(text.String())
+				if current.text != "" {
+					paragraphs = append(paragraphs, current)
+				}
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+	return paragraphs, nil
+}
+
+// docxHeadingLevel recognizes the built-in "Heading1".."Heading9" style IDs
+func docxHeadingLevel(style string) int {
+	lower := strings.ToLower(strings.ReplaceAll(style, " ", ""))
+	if !strings.HasPrefix(lower, "heading") {
+		return 0
+	}
+	level, err := strconv.Atoi(strings.TrimPrefix(lower, "heading"))
+	if err != nil || level < 1 || level > 9 {
+		return 0
+	}
+	return min(level, 6)
+}
+
+func docxCoreTitle(data []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	inTitle := false
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			inTitle = t.Name.Local == "title"
+		case xml.EndElement:
+			inTitle = false
+		case xml.CharData:
+			if inTitle {
+				if title := strings.TrimSpace(string(t)); title != "" {
+					return title
+				}
+			}
+		}
+	}
+}
+
+func xmlAttr(el xml.StartElement, local string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
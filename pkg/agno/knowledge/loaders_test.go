@@ -0,0 +1,354 @@
+package knowledge
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestMarkdownLoader(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "guide.md", []byte("---\ntitle: \"The Guide\"\ntags: [a]\n---\n"+
+		"# Intro\n\nHello.\n\n```\n# not a heading\n```\n\n## Setup ##\n\nSteps.\n"))
+
+	docs, err := NewMarkdownLoader(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	doc := docs[0]
+	if doc.Metadata[MetadataTitle] != "The Guide" {
+		t.Errorf("title = %v, want front matter title", doc.Metadata[MetadataTitle])
+	}
+	if doc.Metadata[MetadataHeadings] != "Intro\nSetup" {
+		t.Errorf("headings = %q", doc.Metadata[MetadataHeadings])
+	}
+	if !strings.HasPrefix(doc.Content, "# Intro") {
+		t.Errorf("front matter should be stripped, got %q", doc.Content)
+	}
+}
+
+func TestHTMLLoader(t *testing.T) {
+	page := `<html><head><title>Docs  Home</title><meta name="description" content="About docs">
+<style>body{}</style><script>var x = 1;</script></head>
+<body><nav>Menu</nav><h1>Welcome</h1><p>First   paragraph with <b>bold</b> text.</p>
+<h2>Features</h2><ul><li>Fast</li><li>Small</li></ul></body></html>`
+	path := writeFile(t, t.TempDir(), "index.html", []byte(page))
+
+	docs, err := NewHTMLLoader(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	doc := docs[0]
+	want := "Menu\n\n# Welcome\n\nFirst paragraph with bold text.\n\n## Features\n\n- Fast\n- Small"
+	if doc.Content != want {
+		t.Errorf("content = %q, want %q", doc.Content, want)
+	}
+	if doc.Metadata[MetadataTitle] != "Docs Home" || doc.Metadata["description"] != "About docs" {
+		t.Errorf("unexpected metadata %+v", doc.Metadata)
+	}
+	if doc.Metadata[MetadataHeadings] != "Welcome\nFeatures" {
+		t.Errorf("headings = %q", doc.Metadata[MetadataHeadings])
+	}
+}
+
+func buildDOCX(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDOCXLoader(t *testing.T) {
+	body := `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Overview</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Hello </w:t></w:r><w:r><w:t>world.</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/></w:numPr></w:pPr><w:r><w:t>Item</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Details</w:t></w:r></w:p>
+<w:p></w:p>
+</w:body></w:document>`
+	core := `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Report</dc:title></cp:coreProperties>`
+	path := writeFile(t, t.TempDir(), "report.docx", buildDOCX(t, map[string]string{
+		"word/document.xml": body,
+		"docProps/core.xml": core,
+	}))
+
+	docs, err := NewDOCXLoader(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	doc := docs[0]
+	want := "# Overview\n\nHello world.\n\n- Item\n\n## Details"
+	if doc.Content != want {
+		t.Errorf("content = %q, want %q", doc.Content, want)
+	}
+	if doc.Metadata[MetadataTitle] != "Report" || doc.Metadata[MetadataHeadings] != "Overview\nDetails" {
+		t.Errorf("unexpected metadata %+v", doc.Metadata)
+	}
+}
+
+// buildPDF assembles a PDF from object bodies numbered from 1, with a valid
+// cross-reference table
+func buildPDF(objects []string, trailer string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer, xref)
+	return buf.Bytes()
+}
+
+func pdfStreamObject(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func TestPDFLoader(t *testing.T) {
+	page1 := []byte("BT /F1 12 Tf 72 720 Td (Hello \\(PDF\\) world) Tj 0 -14 Td [(Second) -300 (line)] TJ ET")
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write([]byte("BT /F2 12 Tf 72 720 Td <00010002> Tj ET"))
+	zw.Close()
+
+	cmap := []byte("/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n" +
+		"1 begincodespacerange <0000> <FFFF> endcodespacerange\n" +
+		"1 beginbfchar <0001> <00E9> endbfchar\n" +
+		"1 beginbfrange <0002> <0003> <0074> endbfrange\n" +
+		"endcmap CMapName currentdict /CMap defineresource pop end end")
+
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 8 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Custom /ToUnicode 9 0 R >>",
+		pdfStreamObject("", page1),
+		pdfStreamObject("/Filter /FlateDecode", compressed.Bytes()),
+		pdfStreamObject("", cmap),
+		"<< /Title (Sample Paper) >>",
+	}, "<< /Size 11 /Root 1 0 R /Info 10 0 R >>")
+	path := writeFile(t, t.TempDir(), "paper.pdf", data)
+
+	docs, err := NewPDFLoader(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("expected one document per page, got %d", len(docs))
+	}
+	if docs[0].Content != "Hello (PDF) world\nSecond line" {
+		t.Errorf("page 1 = %q", docs[0].Content)
+	}
+	if docs[1].Content != "ét" {
+		t.Errorf("page 2 = %q, want text decoded through ToUnicode", docs[1].Content)
+	}
+	meta := docs[1].Metadata
+	if meta["page"] != 2 || meta["page_count"] != 2 || meta[MetadataTitle] != "Sample Paper" || docs[1].ID != "paper.pdf_page_2" {
+		t.Errorf("unexpected page metadata %+v (id %s)", meta, docs[1].ID)
+	}
+}
+
+func TestPDFLoader_InvalidFile(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "broken.pdf", []byte("not a pdf"))
+	if _, err := NewPDFLoader(path).Load(); err == nil {
+		t.Fatal("expected error for a non-pdf file")
+	}
+}
+
+func TestPDFLoader_MalformedInput(t *testing.T) {
+	catalog := "<< /Type /Catalog /Pages 2 0 R >>"
+	page := "<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>"
+	content := pdfStreamObject("", []byte("BT 72 720 Td (Still here) Tj ET"))
+	valid := buildPDF([]string{catalog, "<< /Type /Pages /Kids [3 0 R] >>", page, content}, "<< /Root 1 0 R >>")
+
+	tests := []struct {
+		name string
+		data []byte
+		want string // text of the first page; empty when no page is expected
+	}{
+		{"truncated after content", valid[:bytes.Index(valid, []byte("xref"))], "Still here"},
+		{"truncated inside stream", valid[:bytes.Index(valid, []byte("ET"))], "Still here"},
+		{"garbage cross-reference table", bytes.Replace(valid, []byte("0000000000 65535 f"), []byte("zzzzzzzzzz zzzzz q"), 1), "Still here"},
+		{"negative stream length", buildPDF([]string{catalog, "<< /Type /Pages /Kids [3 0 R] >>", page,
+			"<< /Length -50 >>\nstream\nBT (Negative) Tj ET\nendstream"}, "<< /Root 1 0 R >>"), "Negative"},
+		{"huge stream length", buildPDF([]string{catalog, "<< /Type /Pages /Kids [3 0 R] >>", page,
+			"<< /Length 1e300 >>\nstream\nBT (Huge) Tj ET\nendstream"}, "<< /Root 1 0 R >>"), "Huge"},
+		{"corrupt flate stream", buildPDF([]string{catalog, "<< /Type /Pages /Kids [3 0 R] >>", page,
+			pdfStreamObject("/Filter /FlateDecode", []byte("not deflate data"))}, "<< /Root 1 0 R >>"), ""},
+		{"cyclic page tree", buildPDF([]string{catalog, "<< /Type /Pages /Kids [2 0 R 3 0 R] >>", page, content},
+			"<< /Root 1 0 R >>"), "Still here"},
+		{"self-referencing objects", buildPDF([]string{catalog, "2 0 R", "3 0 R"}, "<< /Root 1 0 R /Info 1 0 R >>"), ""},
+		{"object stream with bad offsets", buildPDF([]string{catalog, "<< /Type /Pages /Kids [3 0 R] >>", page, content,
+			pdfStreamObject("/Type /ObjStm /N 3 /First -4", []byte("6 0 7 99999 8 -7 (x)"))}, "<< /Root 1 0 R >>"), "Still here"},
+		{"empty codespace range", buildPDF([]string{catalog, "<< /Type /Pages /Kids [3 0 R] >>",
+			"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+			pdfStreamObject("", []byte("BT /F1 12 Tf <0102> Tj ET")),
+			"<< /Subtype /Type0 /ToUnicode 6 0 R >>",
+			pdfStreamObject("", []byte("1 begincodespacerange () () endcodespacerange"))}, "<< /Root 1 0 R >>"), ""},
+		{"deeply nested arrays", buildPDF([]string{catalog, "<< /Type /Pages /Kids [3 0 R] >>", page, content,
+			strings.Repeat("[", 100000)}, "<< /Root 1 0 R >>"), "Still here"},
+		{"deeply nested content", buildPDF([]string{catalog, "<< /Type /Pages /Kids [3 0 R] >>", page,
+			pdfStreamObject("", []byte("BT (Before) Tj "+strings.Repeat("[", 100000)))}, "<< /Root 1 0 R >>"), "Before"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, t.TempDir(), "malformed.pdf", tt.data)
+			docs, err := NewPDFLoader(path).Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			got := ""
+			if len(docs) > 0 {
+				got = docs[0].Content
+			}
+			if got != tt.want {
+				t.Errorf("first page = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPDFLoader_NoObjects(t *testing.T) {
+	for _, data := range []string{"%PDF-1.7\n", "%PDF-1.7\n1 0 ob", "%PDF-1.7\ntrailer << /Root 1 0 R >>"} {
+		path := writeFile(t, t.TempDir(), "empty.pdf", []byte(data))
+		if _, err := NewPDFLoader(path).Load(); err == nil {
+			t.Errorf("expected error for %q", data)
+		}
+	}
+}
+
+func TestPDFLoader_Encrypted(t *testing.T) {
+	data := buildPDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] >>",
+		"<< /Filter /Standard /V 2 /R 3 >>",
+	}, "<< /Root 1 0 R /Encrypt 3 0 R >>")
+	path := writeFile(t, t.TempDir(), "locked.pdf", data)
+
+	_, err := NewPDFLoader(path).Load()
+	if err == nil || !strings.Contains(err.Error(), "encrypted") {
+		t.Fatalf("expected an encryption error, got %v", err)
+	}
+}
+
+func TestPDFLoader_Limits(t *testing.T) {
+	setLimit := func(limit *int, value int) {
+		saved := *limit
+		*limit = value
+		t.Cleanup(func() { *limit = saved })
+	}
+
+	t.Run("file size", func(t *testing.T) {
+		setLimit(&maxPDFFileSize, 64)
+		path := writeFile(t, t.TempDir(), "big.pdf", append([]byte("%PDF-1.4\n"), make([]byte, 100)...))
+		if _, err := NewPDFLoader(path).Load(); !errors.Is(err, ErrPDFTooLarge) {
+			t.Fatalf("expected ErrPDFTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("object count", func(t *testing.T) {
+		setLimit(&maxPDFObjects, 3)
+		data := buildPDF([]string{"<< >>", "<< >>", "<< >>", "<< >>"}, "<< >>")
+		path := writeFile(t, t.TempDir(), "many.pdf", data)
+		if _, err := NewPDFLoader(path).Load(); !errors.Is(err, ErrPDFTooLarge) {
+			t.Fatalf("expected ErrPDFTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("decompression bomb", func(t *testing.T) {
+		setLimit(&maxPDFDecodedSize, 1<<20)
+		var bomb bytes.Buffer
+		zw := zlib.NewWriter(&bomb)
+		zw.Write(make([]byte, 4<<20))
+		zw.Close()
+
+		data := buildPDF([]string{
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] >>",
+			"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+			pdfStreamObject("/Filter /FlateDecode", bomb.Bytes()),
+		}, "<< /Root 1 0 R >>")
+		path := writeFile(t, t.TempDir(), "bomb.pdf", data)
+		if _, err := NewPDFLoader(path).Load(); !errors.Is(err, ErrPDFTooLarge) {
+			t.Fatalf("expected ErrPDFTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("repeated stream references", func(t *testing.T) {
+		setLimit(&maxPDFDecodedSize, 1<<20)
+		refs := strings.Repeat("4 0 R ", 300)
+		data := buildPDF([]string{
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] >>",
+			"<< /Type /Page /Parent 2 0 R /Contents [" + refs + "] >>",
+			pdfStreamObject("", bytes.Repeat([]byte("BT (x) Tj ET "), 400)),
+		}, "<< /Root 1 0 R >>")
+		path := writeFile(t, t.TempDir(), "repeat.pdf", data)
+		if _, err := NewPDFLoader(path).Load(); !errors.Is(err, ErrPDFTooLarge) {
+			t.Fatalf("expected ErrPDFTooLarge, got %v", err)
+		}
+	})
+}
+
+func TestDirectoryLoader_DispatchesByExtension(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.md", []byte("# Alpha\n\nText"))
+	writeFile(t, dir, "b.html", []byte("<title>Beta</title><p>Body</p>"))
+	writeFile(t, dir, "c.txt", []byte("plain"))
+
+	docs, err := NewDirectoryLoader(dir, "", false).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(docs) != 3 {
+		t.Fatalf("expected 3 documents, got %d", len(docs))
+	}
+	if docs[0].Metadata[MetadataTitle] != "Alpha" || docs[1].Content != "Body" || docs[2].Content != "plain" {
+		t.Errorf("unexpected documents %+v", docs)
+	}
+
+	RegisterFileLoader(".txt", func(path string) Loader {
+		return NewReaderLoader(strings.NewReader("custom"), filepath.Base(path), nil)
+	})
+	defer func() {
+		fileLoadersMu.Lock()
+		delete(fileLoaders, ".txt")
+		fileLoadersMu.Unlock()
+	}()
+	if docs, _ := NewDirectoryLoader(dir, "*.txt", false).Load(); len(docs) != 1 || docs[0].Content != "custom" {
+		t.Errorf("registered loader not used: %+v", docs)
+	}
+}
@@ -0,0 +1,114 @@
+package knowledge
+
+import (
+	"strings"
+
+	"github.com/rexleimo/agno-go/pkg/agno/memory"
+)
+
+// Metadata keys set by MarkdownChunker
+const (
+	MetadataHeading     = "heading"      // Closest heading above the chunk
+	MetadataHeadingPath = "heading_path" // Breadcrumb of enclosing headings, e.g. "Guide > Setup > Linux"
+)
+
+// markdownSeparators are used to split a section that exceeds the chunk size
+var markdownSeparators = []string{"\n\n", "\n", ". ", "! ", "? ", "。", "; ", ", ", " ", ""}
+
+// MarkdownChunker splits Markdown documents into sections at headings, then
+// splits sections larger than ChunkSize tokens recursively (paragraphs,
+// lines, sentences, words). Every chunk belongs to a single section and
+// records the heading breadcrumb leading to it, so a passage retrieved out of
+// context still says where it came from.
+//
+// Heading lines move from the content to the metadata, so sections that hold
+// only a heading produce no chunk. Headings inside fenced code blocks are
+// ignored.
+type MarkdownChunker struct {
+	ChunkSize       int // Maximum tokens per chunk
+	ChunkOverlap    int // Tokens repeated between consecutive chunks of a section
+	MaxHeadingLevel int // Deepest heading level that starts a section (default: 6)
+	Tokenizer       memory.Tokenizer
+}
+
+// NewMarkdownChunker creates a Markdown chunker using memory.EstimateTokenizer
+func NewMarkdownChunker(chunkSize, chunkOverlap int) *MarkdownChunker {
+	chunkSize, chunkOverlap = tokenChunkSizes(chunkSize, chunkOverlap)
+	return &MarkdownChunker{
+		ChunkSize:       chunkSize,
+		ChunkOverlap:    chunkOverlap,
+		MaxHeadingLevel: 6,
+		Tokenizer:       memory.NewEstimateTokenizer(),
+	}
+}
+
+type markdownSection struct {
+	path []string
+	text string
+}
+
+// Chunk splits a Markdown document into section-aware chunks
+func (c *MarkdownChunker) Chunk(doc Document) ([]Chunk, error) {
+	splitter := newTokenSplitter(c.ChunkSize, c.ChunkOverlap, c.Tokenizer, markdownSeparators)
+
+	var chunks []Chunk
+	for _, section := range c.sections(doc.Content) {
+		var extra map[string]interface{}
+		if len(section.path) > 0 {
+			extra = map[string]interface{}{
+				MetadataHeading:     section.path[len(section.path)-1],
+				MetadataHeadingPath: strings.Join(section.path, " > "),
+			}
+		}
+		for _, text := range splitter.split(section.text) {
+			content := strings.TrimSpace(text)
+			if content == "" {
+				continue
+			}
+			chunks = append(chunks, newTokenChunk(doc, content, len(chunks), splitter.tokenizer, extra))
+		}
+	}
+	return chunks, nil
+}
+
+// sections cuts content at headings up to MaxHeadingLevel, tracking the
+// breadcrumb of each section
+func (c *MarkdownChunker) sections(content string) []markdownSection {
+	maxLevel := c.MaxHeadingLevel
+	if maxLevel <= 0 || maxLevel > 6 {
+		maxLevel = 6
+	}
+	lines := strings.Split(content, "\n")
+
+	var (
+		sections []markdownSection
+		path     [6]string
+		current  []string // breadcrumb of the open section
+		start    int
+	)
+	emit := func(end int) {
+		if text := strings.Join(lines[start:end], "\n"); strings.TrimSpace(text) != "" {
+			sections = append(sections, markdownSection{path: current, text: text})
+		}
+	}
+
+	for _, h := range markdownHeadings(content) {
+		if h.level > maxLevel {
+			continue
+		}
+		emit(h.line)
+		path[h.level-1] = h.text
+		for i := h.level; i < len(path); i++ {
+			path[i] = ""
+		}
+		current = nil
+		for _, title := range path[:h.level] {
+			if title != "" {
+				current = append(current, title)
+			}
+		}
+		start = h.line + 1
+	}
+	emit(len(lines))
+	return sections
+}
@@ -0,0 +1,55 @@
+package knowledge
+
+import "testing"
+
+func TestMarkdownChunker_HeadingBreadcrumbs(t *testing.T) {
+	content := "Preamble text.\n\n# Guide\n\n## Install\n\nRun the installer.\n\n### Linux\n\nUse the package.\n\n" +
+		"```sh\n# not a heading\napt install agno\n```\n\n## Usage\n\none two three four five six seven eight"
+	chunker := NewMarkdownChunker(6, 0)
+	chunker.Tokenizer = wordTokenizer
+
+	chunks, err := chunker.Chunk(Document{ID: "guide", Content: content})
+	if err != nil {
+		t.Fatalf("Chunk() error = %v", err)
+	}
+
+	want := []struct {
+		content, path string
+	}{
+		{"Preamble text.", ""},
+		{"Run the installer.", "Guide > Install"},
+		{"Use the package.", "Guide > Install > Linux"},
+		{"```sh\n# not a heading", "Guide > Install > Linux"},
+		{"apt install agno\n```", "Guide > Install > Linux"},
+		{"one two three four five six", "Guide > Usage"},
+		{"seven eight", "Guide > Usage"},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks %q, want %d", len(chunks), contents(chunks), len(want))
+	}
+	for i, w := range want {
+		if chunks[i].Content != w.content {
+			t.Errorf("chunk %d = %q, want %q", i, chunks[i].Content, w.content)
+		}
+		path, _ := chunks[i].Metadata[MetadataHeadingPath].(string)
+		if path != w.path {
+			t.Errorf("chunk %d heading path = %q, want %q", i, path, w.path)
+		}
+	}
+	if chunks[2].Metadata[MetadataHeading] != "Linux" {
+		t.Errorf("heading = %v, want Linux", chunks[2].Metadata[MetadataHeading])
+	}
+}
+
+func TestMarkdownChunker_MaxHeadingLevel(t *testing.T) {
+	chunker := NewMarkdownChunker(100, 0)
+	chunker.MaxHeadingLevel = 1
+
+	chunks, _ := chunker.Chunk(Document{ID: "doc", Content: "# A\n\nintro\n\n## A.1\n\ndetails\n\n# B\n\nmore"})
+	if len(chunks) != 2 {
+		t.Fatalf("expected sections only at level 1, got %q", contents(chunks))
+	}
+	if chunks[0].Content != "intro\n\n## A.1\n\ndetails" || chunks[1].Metadata[MetadataHeadingPath] != "B" {
+		t.Errorf("unexpected chunks %+v", chunks)
+	}
+}
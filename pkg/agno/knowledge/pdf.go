@@ -0,0 +1,1059 @@
+package knowledge
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// PDFLoader extracts the text of a PDF file, one Document per page. Pages
+// carry "page" and "page_count" metadata and, when the file has one, the
+// document "title".
+//
+// Extraction is deliberately scoped to text-layer PDFs and needs no external
+// tools. Supported: uncompressed, FlateDecode and ASCIIHexDecode streams,
+// object streams, and fonts with a ToUnicode CMap or a single-byte encoding.
+// Not supported: encryption (an error is returned), other stream filters
+// (LZW, DCT, JBIG2, ...; such streams are skipped), embedded font programs
+// without a ToUnicode CMap, and OCR, so scanned PDFs yield no documents.
+//
+// The cross-reference table is not trusted: objects are located by scanning
+// for "obj" headers, which also copes with truncated or damaged files. To keep
+// hostile input cheap, files over 100 MiB, more than 1M objects or 256 MiB of
+// decoded stream data are rejected with ErrPDFTooLarge, and nesting
+// deeper than 256 arrays, dictionaries or page tree levels is ignored.
+type PDFLoader struct {
+	FilePath string
+}
+
+// ErrPDFTooLarge is returned when a PDF exceeds the extraction limits
+var ErrPDFTooLarge = errors.New("pdf exceeds extraction limits")
+
+// Extraction limits; variables so tests can lower them
+var (
+	maxPDFFileSize    = 100 << 20 // bytes read from disk
+	maxPDFObjects     = 1 << 20   // indirect objects, including object streams
+	maxPDFDecodedSize = 256 << 20 // decoded stream bytes per document
+	maxPDFNesting     = 256       // arrays, dictionaries and page tree levels
+)
+
+// NewPDFLoader creates a new PDF loader
+func NewPDFLoader(filePath string) *PDFLoader {
+	return &PDFLoader{FilePath: filePath}
+}
+
+// Load extracts the pages of the PDF
+func (l *PDFLoader) Load() ([]Document, error) {
+	info, err := os.Stat(l.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", l.FilePath, err)
+	}
+	if info.Size() > int64(maxPDFFileSize) {
+		return nil, fmt.Errorf("failed to parse pdf %s: %w: file is %d bytes, limit is %d", l.FilePath, ErrPDFTooLarge, info.Size(), maxPDFFileSize)
+	}
+	data, err := os.ReadFile(l.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", l.FilePath, err)
+	}
+
+	title, pages, err := extractPDF(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pdf %s: %w", l.FilePath, err)
+	}
+
+	base := filepath.Base(l.FilePath)
+	docs := make([]Document, 0, len(pages))
+	for i, text := range pages {
+		if text == "" {
+			continue
+		}
+		metadata := fileMetadata(l.FilePath)
+		metadata["page"] = i + 1
+		metadata["page_count"] = len(pages)
+		if title != "" {
+			metadata["title"] = title
+		}
+		docs = append(docs, Document{
+			ID:       fmt.Sprintf("%s_page_%d", base, i+1),
+			Content:  text,
+			Source:   l.FilePath,
+			Metadata: metadata,
+		})
+	}
+	return docs, nil
+}
+
+// PDF object model
+
+type pdfName string
+
+type pdfString []byte
+
+type pdfKeyword string
+
+type pdfRef struct {
+	num, gen int
+}
+
+type pdfDict map[pdfName]interface{}
+
+type pdfStream struct {
+	dict pdfDict
+	data []byte
+}
+
+// pdfLexer tokenizes PDF object syntax and content streams
+type pdfLexer struct {
+	data  []byte
+	pos   int
+	depth int // open arrays and dictionaries
+}
+
+const (
+	tokDictStart  = pdfKeyword("<<")
+	tokDictEnd    = pdfKeyword(">>")
+	tokArrayStart = pdfKeyword("[")
+	tokArrayEnd   = pdfKeyword("]")
+)
+
+func isPDFWhitespace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isPDFWhitespace(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// token returns the next primitive token: float64, bool, nil, pdfName,
+// pdfString or pdfKeyword (operators and structural delimiters). ok is false
+// at the end of the input.
+func (l *pdfLexer) token() (tok interface{}, ok bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, false
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && !isPDFWhitespace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		return pdfName(decodeNameEscapes(string(l.data[start:l.pos]))), true
+	case c == '(':
+		return l.literalString(), true
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return tokDictStart, true
+		}
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && l.data[l.pos] != '>' {
+			l.pos++
+		}
+		digits := strings.Map(func(r rune) rune {
+			if isPDFWhitespace(byte(r)) {
+				return -1
+			}
+			return r
+		}, string(l.data[start:l.pos]))
+		l.pos++
+		if len(digits)%2 == 1 {
+			digits += "0"
+		}
+		decoded, _ := hex.DecodeString(digits)
+		return pdfString(decoded), true
+	case c == '>':
+		l.pos++
+		if l.pos < len(l.data) && l.data[l.pos] == '>' {
+			l.pos++
+		}
+		return tokDictEnd, true
+	case c == '[':
+		l.pos++
+		return tokArrayStart, true
+	case c == ']':
+		l.pos++
+		return tokArrayEnd, true
+	case c == '{' || c == '}' || c == ')':
+		l.pos++
+		return pdfKeyword(string(c)), true
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFWhitespace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+	switch word {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "null":
+		return nil, true
+	}
+	if (word[0] >= '0' && word[0] <= '9') || word[0] == '-' || word[0] == '+' || word[0] == '.' {
+		if n, err := strconv.ParseFloat(word, 64); err == nil {
+			return n, true
+		}
+	}
+	return pdfKeyword(word), true
+}
+
+func (l *pdfLexer) literalString() pdfString {
+	l.pos++ // opening parenthesis
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func decodeNameEscapes(name string) string {
+	if !strings.Contains(name, "#") {
+		return name
+	}
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '#' && i+2 < len(name) {
+			if v, err := strconv.ParseUint(name[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
+
+// object parses a complete object: dictionaries, arrays and indirect
+// references are assembled from primitive tokens
+func (l *pdfLexer) object() (interface{}, bool) {
+	tok, ok := l.token()
+	if !ok {
+		return nil, false
+	}
+	if tok == tokDictStart || tok == tokArrayStart {
+		if l.depth >= maxPDFNesting {
+			// Too deeply nested to be real content: stop lexing.
+			l.pos = len(l.data)
+			return nil, false
+		}
+		l.depth++
+		defer func() { l.depth-- }()
+	}
+	switch tok {
+	case tokDictStart:
+		dict := pdfDict{}
+		for {
+			key, ok := l.object()
+			if !ok || key == tokDictEnd {
+				return dict, true
+			}
+			name, isName := key.(pdfName)
+			value, ok := l.object()
+			if !ok {
+				return dict, true
+			}
+			if isName {
+				dict[name] = value
+			}
+		}
+	case tokArrayStart:
+		var arr []interface{}
+		for {
+			item, ok := l.object()
+			if !ok || item == tokArrayEnd {
+				return arr, true
+			}
+			arr = append(arr, item)
+		}
+	}
+
+	// "num gen R" is an indirect reference
+	if num, isNum := tok.(float64); isNum && num == float64(int(num)) {
+		saved := l.pos
+		if gen, ok := l.token(); ok {
+			if g, isNum := gen.(float64); isNum {
+				if r, ok := l.token(); ok && r == pdfKeyword("R") {
+					return pdfRef{num: int(num), gen: int(g)}, true
+				}
+			}
+		}
+		l.pos = saved
+	}
+	return tok, true
+}
+
+// pdfDocument holds the parsed objects of a file
+type pdfDocument struct {
+	objects  map[int]interface{}
+	trailers []pdfDict
+	decoded  int   // decoded stream bytes so far
+	err      error // set once a limit is exceeded
+}
+
+var pdfObjectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+func parsePDF(data []byte) (*pdfDocument, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF")) {
+		return nil, fmt.Errorf("not a pdf file")
+	}
+
+	doc := &pdfDocument{objects: make(map[int]interface{})}
+	headers := pdfObjectHeader.FindAllSubmatchIndex(data, maxPDFObjects+1)
+	if len(headers) > maxPDFObjects {
+		return nil, fmt.Errorf("%w: more than %d objects", ErrPDFTooLarge, maxPDFObjects)
+	}
+	for _, m := range headers {
+		num, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+		lex := &pdfLexer{data: data, pos: m[1]}
+		obj, ok := lex.object()
+		if !ok {
+			continue
+		}
+		if dict, isDict := obj.(pdfDict); isDict {
+			saved := lex.pos
+			if kw, ok := lex.token(); ok && kw == pdfKeyword("stream") {
+				obj = &pdfStream{dict: dict, data: streamData(data, lex.pos, dict)}
+			} else {
+				lex.pos = saved
+			}
+		}
+		// Later definitions come from incremental updates and win.
+		doc.objects[num] = obj
+	}
+	if len(doc.objects) == 0 {
+		return nil, fmt.Errorf("no objects found")
+	}
+
+	for idx := 0; ; {
+		i := bytes.Index(data[idx:], []byte("trailer"))
+		if i < 0 {
+			break
+		}
+		lex := &pdfLexer{data: data, pos: idx + i + len("trailer")}
+		if obj, ok := lex.object(); ok {
+			if dict, isDict := obj.(pdfDict); isDict {
+				doc.trailers = append(doc.trailers, dict)
+			}
+		}
+		idx += i + len("trailer")
+	}
+
+	doc.expandObjectStreams()
+	if doc.err != nil {
+		return nil, doc.err
+	}
+	return doc, nil
+}
+
+// streamData returns the bytes between "stream" and "endstream"
+func streamData(data []byte, pos int, dict pdfDict) []byte {
+	if pos < len(data) && data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(data) && data[pos] == '\n' {
+		pos++
+	}
+	if length, ok := dict["Length"].(float64); ok && length >= 0 && length <= float64(len(data)-pos) {
+		end := pos + int(length)
+		if bytes.HasPrefix(bytes.TrimLeft(data[end:], "\r\n \t"), []byte("endstream")) {
+			return data[pos:end]
+		}
+	}
+	end := bytes.Index(data[pos:], []byte("endstream"))
+	if end < 0 {
+		return data[pos:]
+	}
+	return bytes.TrimRight(data[pos:pos+end], "\r\n")
+}
+
+// expandObjectStreams adds the objects stored inside /Type /ObjStm streams
+func (d *pdfDocument) expandObjectStreams() {
+	var streams []*pdfStream
+	for _, obj := range d.objects {
+		if s, ok := obj.(*pdfStream); ok && s.dict["Type"] == pdfName("ObjStm") {
+			streams = append(streams, s)
+		}
+	}
+	for _, s := range streams {
+		data, err := d.decodeStream(s)
+		if err != nil {
+			continue
+		}
+		n, _ := d.resolve(s.dict["N"]).(float64)
+		first, _ := d.resolve(s.dict["First"]).(float64)
+		if first < 0 || first > float64(len(data)) {
+			continue
+		}
+
+		header := &pdfLexer{data: data[:int(first)]}
+		for i := 0; i < int(n); i++ {
+			numTok, ok1 := header.token()
+			offTok, ok2 := header.token()
+			num, isNum := numTok.(float64)
+			off, isOff := offTok.(float64)
+			if !ok1 || !ok2 || !isNum || !isOff || off < 0 || off > float64(len(data))-first {
+				break
+			}
+			if _, exists := d.objects[int(num)]; exists {
+				continue
+			}
+			if len(d.objects) >= maxPDFObjects {
+				d.err = fmt.Errorf("%w: more than %d objects", ErrPDFTooLarge, maxPDFObjects)
+				return
+			}
+			lex := &pdfLexer{data: data, pos: int(first) + int(off)}
+			if obj, ok := lex.object(); ok {
+				d.objects[int(num)] = obj
+			}
+		}
+	}
+}
+
+func (d *pdfDocument) resolve(obj interface{}) interface{} {
+	for depth := 0; depth < 32; depth++ {
+		ref, ok := obj.(pdfRef)
+		if !ok {
+			return obj
+		}
+		obj = d.objects[ref.num]
+	}
+	return nil
+}
+
+func (d *pdfDocument) dict(obj interface{}) pdfDict {
+	switch v := d.resolve(obj).(type) {
+	case pdfDict:
+		return v
+	case *pdfStream:
+		return v.dict
+	}
+	return nil
+}
+
+// decodeStream applies the stream filters; FlateDecode and ASCIIHexDecode
+// are supported. Decoded bytes count towards the document budget.
+func (d *pdfDocument) decodeStream(s *pdfStream) ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	var filters []interface{}
+	switch f := d.resolve(s.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []interface{}{f}
+	case []interface{}:
+		filters = f
+	}
+
+	data := s.data
+	for _, f := range filters {
+		switch d.resolve(f) {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			decoded, err := inflate(data, maxPDFDecodedSize-d.decoded)
+			if err != nil {
+				if errors.Is(err, ErrPDFTooLarge) {
+					d.err = err
+				}
+				return nil, err
+			}
+			data = decoded
+		case pdfName("ASCIIHexDecode"), pdfName("AHx"):
+			lex := &pdfLexer{data: append(append([]byte("<"), data...), '>')}
+			tok, _ := lex.token()
+			data, _ = tok.(pdfString)
+		default:
+			return nil, fmt.Errorf("unsupported stream filter %v", f)
+		}
+	}
+
+	// Raw streams count too: a page may reference one stream many times.
+	d.decoded += len(data)
+	if d.decoded > maxPDFDecodedSize {
+		d.err = fmt.Errorf("%w: more than %d bytes of decoded stream data", ErrPDFTooLarge, maxPDFDecodedSize)
+		return nil, d.err
+	}
+	return data, nil
+}
+
+// inflate decompresses zlib or raw deflate data, failing with ErrPDFTooLarge
+// past limit bytes. Truncated streams keep what was decoded.
+func inflate(data []byte, limit int) ([]byte, error) {
+	var (
+		out []byte
+		err error
+	)
+	if r, zerr := zlib.NewReader(bytes.NewReader(data)); zerr == nil {
+		out, err = io.ReadAll(io.LimitReader(r, int64(limit)+1))
+		if err != nil && len(out) == 0 {
+			out = nil
+		}
+	}
+	if out == nil {
+		out, err = io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(data)), int64(limit)+1))
+		if err != nil && len(out) == 0 {
+			return nil, err
+		}
+	}
+	if len(out) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes of decoded stream data", ErrPDFTooLarge, maxPDFDecodedSize)
+	}
+	return out, nil
+}
+
+// pages returns the page dictionaries in document order with their
+// (possibly inherited) resources
+func (d *pdfDocument) pages() []pdfPage {
+	var catalog pdfDict
+	for i := len(d.trailers) - 1; i >= 0 && catalog == nil; i-- {
+		catalog = d.dict(d.trailers[i]["Root"])
+	}
+	if catalog == nil {
+		nums := d.sortedObjectNumbers()
+		for _, num := range nums {
+			if dict := d.dict(pdfRef{num: num}); dict["Type"] == pdfName("XRef") && dict["Root"] != nil {
+				catalog = d.dict(dict["Root"])
+			}
+			if dict := d.dict(pdfRef{num: num}); catalog == nil && dict["Type"] == pdfName("Catalog") {
+				catalog = dict
+			}
+		}
+	}
+
+	var pages []pdfPage
+	if catalog != nil {
+		visited := make(map[int]bool)
+		d.collectPages(catalog["Pages"], nil, visited, 0, &pages)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	// No usable page tree: fall back to every page object in number order.
+	for _, num := range d.sortedObjectNumbers() {
+		if dict := d.dict(pdfRef{num: num}); dict["Type"] == pdfName("Page") {
+			pages = append(pages, pdfPage{dict: dict, resources: d.dict(dict["Resources"])})
+		}
+	}
+	return pages
+}
+
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+func (d *pdfDocument) collectPages(node interface{}, inherited pdfDict, visited map[int]bool, depth int, pages *[]pdfPage) {
+	if depth > maxPDFNesting {
+		return
+	}
+	if ref, ok := node.(pdfRef); ok {
+		if visited[ref.num] {
+			return
+		}
+		visited[ref.num] = true
+	}
+	dict := d.dict(node)
+	if dict == nil {
+		return
+	}
+	resources := inherited
+	if own := d.dict(dict["Resources"]); own != nil {
+		resources = own
+	}
+
+	if kids, ok := d.resolve(dict["Kids"]).([]interface{}); ok {
+		for _, kid := range kids {
+			d.collectPages(kid, resources, visited, depth+1, pages)
+		}
+		return
+	}
+	*pages = append(*pages, pdfPage{dict: dict, resources: resources})
+}
+
+func (d *pdfDocument) sortedObjectNumbers() []int {
+	nums := make([]int, 0, len(d.objects))
+	for num := range d.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+func (d *pdfDocument) title() string {
+	var info pdfDict
+	for i := len(d.trailers) - 1; i >= 0 && info == nil; i-- {
+		info = d.dict(d.trailers[i]["Info"])
+	}
+	if info == nil {
+		for _, obj := range d.objects {
+			if s, ok := obj.(*pdfStream); ok && s.dict["Type"] == pdfName("XRef") && s.dict["Info"] != nil {
+				info = d.dict(s.dict["Info"])
+			}
+		}
+	}
+	if s, ok := d.resolve(info["Title"]).(pdfString); ok {
+		return strings.TrimSpace(decodeTextString(s))
+	}
+	return ""
+}
+
+// decodeTextString decodes a PDF text string (UTF-16BE with BOM or
+// PDFDocEncoding, approximated by Windows-1252)
+func decodeTextString(s []byte) string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		return decodeUTF16BE(s[2:])
+	}
+	return decodeSingleByte(s)
+}
+
+func decodeUTF16BE(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+// cp1252 maps the 0x80-0x9F range of Windows-1252 (WinAnsiEncoding)
+var cp1252 = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡',
+	0x88: 'ˆ', 0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž',
+	0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—',
+	0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›', 0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+}
+
+func decodeSingleByte(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		if r, ok := cp1252[c]; ok {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune(rune(c))
+		}
+	}
+	return sb.String()
+}
+
+// pdfFont decodes the strings shown with one font
+type pdfFont struct {
+	cmap      *toUnicodeCMap
+	multiByte bool // composite (Type0) font
+}
+
+func (f *pdfFont) decode(s []byte) string {
+	if f == nil {
+		return decodeSingleByte(s)
+	}
+	if f.cmap != nil {
+		return f.cmap.decode(s)
+	}
+	if f.multiByte {
+		// CIDs cannot be mapped to text without a ToUnicode CMap.
+		return ""
+	}
+	return decodeSingleByte(s)
+}
+
+func (d *pdfDocument) font(resources pdfDict, name pdfName, cache map[pdfName]*pdfFont) *pdfFont {
+	if font, ok := cache[name]; ok {
+		return font
+	}
+	var font *pdfFont
+	if dict := d.dict(d.dict(resources["Font"])[name]); dict != nil {
+		font = &pdfFont{multiByte: dict["Subtype"] == pdfName("Type0")}
+		if s, ok := d.resolve(dict["ToUnicode"]).(*pdfStream); ok {
+			if data, err := d.decodeStream(s); err == nil {
+				font.cmap = parseToUnicode(data)
+			}
+		}
+	}
+	cache[name] = font
+	return font
+}
+
+// toUnicodeCMap maps character codes to text
+type toUnicodeCMap struct {
+	ranges []codespaceRange
+	chars  map[string]string // code bytes -> text
+}
+
+type codespaceRange struct {
+	lo, hi []byte
+}
+
+func parseToUnicode(data []byte) *toUnicodeCMap {
+	cmap := &toUnicodeCMap{chars: make(map[string]string)}
+	lex := &pdfLexer{data: data}
+
+	var operands []interface{}
+	for {
+		obj, ok := lex.object()
+		if !ok {
+			break
+		}
+		kw, isKeyword := obj.(pdfKeyword)
+		if !isKeyword {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch kw {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 && len(lo) > 0 && len(lo) == len(hi) {
+					cmap.ranges = append(cmap.ranges, codespaceRange{lo: lo, hi: hi})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					cmap.chars[string(src)] = decodeUTF16BE(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 || len(lo) != len(hi) || len(lo) > 4 {
+					continue
+				}
+				start, end := bytesToUint(lo), bytesToUint(hi)
+				if end < start || end-start > 0xFFFF {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					base := []rune(decodeUTF16BE(dst))
+					for code := start; code <= end && len(base) > 0; code++ {
+						text := append([]rune(nil), base...)
+						text[len(text)-1] += rune(code - start)
+						cmap.chars[string(uintToBytes(code, len(lo)))] = string(text)
+					}
+				case []interface{}:
+					for j, item := range dst {
+						if s, ok := item.(pdfString); ok && start+uint32(j) <= end {
+							cmap.chars[string(uintToBytes(start+uint32(j), len(lo)))] = decodeUTF16BE(s)
+						}
+					}
+				}
+			}
+		}
+		if strings.HasPrefix(string(kw), "end") || strings.HasPrefix(string(kw), "begin") {
+			operands = operands[:0]
+		}
+	}
+	return cmap
+}
+
+func (c *toUnicodeCMap) decode(s []byte) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		n := c.codeLength(s[i:])
+		code := string(s[i:min(i+n, len(s))])
+		if text, ok := c.chars[code]; ok {
+			sb.WriteString(text)
+		}
+		i += n
+	}
+	return sb.String()
+}
+
+// codeLength returns the byte length of the code starting at s, using the
+// codespace ranges (default: 2 bytes when any range is 2 bytes long, else 1)
+func (c *toUnicodeCMap) codeLength(s []byte) int {
+	for _, r := range c.ranges {
+		n := len(r.lo)
+		if n > len(s) {
+			continue
+		}
+		if bytes.Compare(s[:n], r.lo) >= 0 && bytes.Compare(s[:n], r.hi) <= 0 {
+			return n
+		}
+	}
+	for _, r := range c.ranges {
+		if len(r.lo) > 1 {
+			return len(r.lo)
+		}
+	}
+	return 1
+}
+
+func bytesToUint(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
+
+func uintToBytes(v uint32, n int) []byte {
+	out := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		out[i] = byte(v)
+		v >>= 8
+	}
+	return out
+}
+
+// pageText interprets the text operators of a page's content streams
+func (d *pdfDocument) pageText(page pdfPage) string {
+	var content []byte
+	switch c := d.resolve(page.dict["Contents"]).(type) {
+	case *pdfStream:
+		if data, err := d.decodeStream(c); err == nil {
+			content = data
+		}
+	case []interface{}:
+		for _, part := range c {
+			if s, ok := d.resolve(part).(*pdfStream); ok {
+				if data, err := d.decodeStream(s); err == nil {
+					content = append(append(content, data...), '\n')
+				}
+			}
+		}
+	}
+	if len(content) == 0 {
+		return ""
+	}
+
+	var (
+		out      strings.Builder
+		operands []interface{}
+		font     *pdfFont
+		fonts    = make(map[pdfName]*pdfFont)
+		lastY    float64
+		haveY    bool
+	)
+	newline := func() {
+		if out.Len() > 0 {
+			out.WriteByte('\n')
+		}
+	}
+	space := func() {
+		if s := out.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+			out.WriteByte(' ')
+		}
+	}
+	show := func(s interface{}) {
+		if str, ok := s.(pdfString); ok {
+			out.WriteString(font.decode(str))
+		}
+	}
+	number := func(i int) float64 {
+		if i < 0 || i >= len(operands) {
+			return 0
+		}
+		n, _ := operands[i].(float64)
+		return n
+	}
+
+	lex := &pdfLexer{data: content}
+	for {
+		obj, ok := lex.object()
+		if !ok {
+			break
+		}
+		op, isOp := obj.(pdfKeyword)
+		if !isOp {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[0].(pdfName); ok {
+					font = d.font(page.resources, name, fonts)
+				}
+			}
+		case "Tj":
+			if len(operands) > 0 {
+				show(operands[len(operands)-1])
+			}
+		case "'", "\"":
+			newline()
+			if len(operands) > 0 {
+				show(operands[len(operands)-1])
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				items, _ := operands[len(operands)-1].([]interface{})
+				for _, item := range items {
+					if n, ok := item.(float64); ok {
+						// Large negative adjustments separate words.
+						if n < -250 {
+							space()
+						}
+						continue
+					}
+					show(item)
+				}
+			}
+		case "Td", "TD":
+			if number(1) != 0 {
+				newline()
+			} else if number(0) != 0 {
+				space()
+			}
+		case "Tm":
+			y := number(5)
+			if haveY && y != lastY {
+				newline()
+			} else {
+				space()
+			}
+			lastY, haveY = y, true
+		case "T*":
+			newline()
+		case "ET":
+			space()
+		case "BI":
+			// Skip inline image data up to the EI operator.
+			if end := bytes.Index(content[lex.pos:], []byte("EI")); end >= 0 {
+				lex.pos += end + 2
+			} else {
+				lex.pos = len(content)
+			}
+		}
+		operands = operands[:0]
+	}
+
+	return normalizeExtractedText(out.String())
+}
+
+func extractPDF(data []byte) (string, []string, error) {
+	doc, err := parsePDF(data)
+	if err != nil {
+		return "", nil, err
+	}
+	if doc.dictFromTrailers("Encrypt") {
+		return "", nil, fmt.Errorf("encrypted pdf files are not supported")
+	}
+
+	pages := doc.pages()
+	texts := make([]string, len(pages))
+	for i, page := range pages {
+		texts[i] = doc.pageText(page)
+		if doc.err != nil {
+			return "", nil, doc.err
+		}
+	}
+	return doc.title(), texts, nil
+}
+
+func (d *pdfDocument) dictFromTrailers(key pdfName) bool {
+	for _, trailer := range d.trailers {
+		if trailer[key] != nil {
+			return true
+		}
+	}
+	for _, obj := range d.objects {
+		if s, ok := obj.(*pdfStream); ok && s.dict["Type"] == pdfName("XRef") && s.dict[key] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	horizontalSpace = regexp.MustCompile(`[ \t\f\v]+`)
+	blankLines      = regexp.MustCompile(`\n{3,}`)
+)
+
+// normalizeExtractedText collapses runs of spaces and blank lines
+func normalizeExtractedText(text string) string {
+	text = horizontalSpace.ReplaceAllString(text, " ")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = strings.Join(lines, "\n")
+	return strings.TrimSpace(blankLines.ReplaceAllString(text, "\n\n"))
+}
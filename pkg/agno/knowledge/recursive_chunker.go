@@ -0,0 +1,306 @@
+package knowledge
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/rexleimo/agno-go/pkg/agno/memory"
+)
+
+const (
+	defaultTokenChunkSize    = 512
+	defaultTokenChunkOverlap = 50
+)
+
+// DefaultSeparators are tried in order by RecursiveChunker: Markdown
+// headings, paragraphs, lines, sentences, clauses and words, then single
+// characters as a last resort
+var DefaultSeparators = []string{
+	"\n# ", "\n## ", "\n### ", "\n#### ", "\n##### ", "\n###### ",
+	"\n\n", "\n", ". ", "! ", "? ", "。", "; ", ", ", " ", "",
+}
+
+// RecursiveChunker splits documents into chunks of at most ChunkSize tokens.
+// Text is split on the first separator it contains; pieces that are still too
+// large are split again with the following separators, and small pieces are
+// merged back up to ChunkSize. Consecutive chunks share up to ChunkOverlap
+// tokens.
+//
+// Separators beginning with a newline stay with the text that follows them
+// (so a heading starts its chunk); other separators stay with the text
+// before them.
+type RecursiveChunker struct {
+	ChunkSize    int // Maximum tokens per chunk
+	ChunkOverlap int // Tokens repeated between consecutive chunks
+	Separators   []string
+	Tokenizer    memory.Tokenizer
+}
+
+// NewRecursiveChunker creates a recursive token-based chunker with the
+// DefaultSeparators and memory.EstimateTokenizer
+func NewRecursiveChunker(chunkSize, chunkOverlap int) *RecursiveChunker {
+	chunkSize, chunkOverlap = tokenChunkSizes(chunkSize, chunkOverlap)
+	return &RecursiveChunker{
+		ChunkSize:    chunkSize,
+		ChunkOverlap: chunkOverlap,
+		Separators:   DefaultSeparators,
+		Tokenizer:    memory.NewEstimateTokenizer(),
+	}
+}
+
+// Chunk splits a document into token-sized chunks
+func (c *RecursiveChunker) Chunk(doc Document) ([]Chunk, error) {
+	splitter := newTokenSplitter(c.ChunkSize, c.ChunkOverlap, c.Tokenizer, c.Separators)
+
+	var chunks []Chunk
+	for _, text := range splitter.split(doc.Content) {
+		content := strings.TrimSpace(text)
+		if content == "" {
+			continue
+		}
+		chunks = append(chunks, newTokenChunk(doc, content, len(chunks), splitter.tokenizer, nil))
+	}
+	return chunks, nil
+}
+
+// codeSeparators lists, per language, the line prefixes that start top-level
+// declarations; they are tried before blank lines, lines and words
+var codeSeparators = map[string][]string{
+	"go":         {"\nfunc ", "\ntype ", "\nvar ", "\nconst "},
+	"python":     {"\nclass ", "\ndef ", "\n\tdef ", "\n    def ", "\n@"},
+	"javascript": {"\nexport ", "\nfunction ", "\nclass ", "\nconst ", "\nlet ", "\nvar "},
+	"typescript": {"\nexport ", "\nfunction ", "\nclass ", "\ninterface ", "\ntype ", "\nenum ", "\nconst ", "\nlet "},
+	"java":       {"\npublic ", "\nprotected ", "\nprivate ", "\nclass ", "\ninterface ", "\nenum ", "\n    public ", "\n    protected ", "\n    private "},
+	"rust":       {"\npub fn ", "\nfn ", "\npub struct ", "\nstruct ", "\nenum ", "\nimpl ", "\ntrait ", "\nmod "},
+	"c":          {"\nstruct ", "\ntypedef ", "\nstatic ", "\nvoid ", "\nint ", "\n#define "},
+	"cpp":        {"\nclass ", "\nstruct ", "\nnamespace ", "\ntemplate ", "\nstatic ", "\nvoid ", "\nint "},
+	"ruby":       {"\nclass ", "\nmodule ", "\ndef ", "\n  def "},
+	"php":        {"\nclass ", "\nfunction ", "\ninterface ", "\ntrait ", "\n    public function ", "\n    private function "},
+}
+
+var codeExtensions = map[string]string{
+	".go": "go", ".py": "python", ".js": "javascript", ".jsx": "javascript", ".mjs": "javascript",
+	".ts": "typescript", ".tsx": "typescript", ".java": "java", ".rs": "rust",
+	".c": "c", ".h": "c", ".cc": "cpp", ".cpp": "cpp", ".hpp": "cpp", ".rb": "ruby", ".php": "php",
+}
+
+// CodeChunker splits source code into token-sized chunks, preferring to
+// break before top-level declarations (functions, types, classes) so each
+// chunk holds whole definitions where possible. Indentation is preserved and
+// every chunk records its "language" and starting "start_line".
+type CodeChunker struct {
+	ChunkSize    int    // Maximum tokens per chunk
+	ChunkOverlap int    // Tokens repeated between consecutive chunks
+	Language     string // e.g. "go", "python"; detected from the file extension when empty
+	Tokenizer    memory.Tokenizer
+}
+
+// NewCodeChunker creates a code chunker for language (empty to detect it
+// from each document's extension)
+func NewCodeChunker(language string, chunkSize, chunkOverlap int) *CodeChunker {
+	chunkSize, chunkOverlap = tokenChunkSizes(chunkSize, chunkOverlap)
+	return &CodeChunker{
+		ChunkSize:    chunkSize,
+		ChunkOverlap: chunkOverlap,
+		Language:     language,
+		Tokenizer:    memory.NewEstimateTokenizer(),
+	}
+}
+
+// Chunk splits a source file into chunks
+func (c *CodeChunker) Chunk(doc Document) ([]Chunk, error) {
+	language := c.Language
+	if language == "" {
+		ext, _ := doc.Metadata["ext"].(string)
+		if ext == "" {
+			ext = filepath.Ext(doc.Source)
+		}
+		language = codeExtensions[strings.ToLower(ext)]
+	}
+
+	separators := append([]string(nil), codeSeparators[strings.ToLower(language)]...)
+	separators = append(separators, "\n\n", "\n", " ", "")
+	splitter := newTokenSplitter(c.ChunkSize, c.ChunkOverlap, c.Tokenizer, separators)
+
+	var chunks []Chunk
+	offset := 0
+	for _, text := range splitter.split(doc.Content) {
+		// Overlapping chunks repeat text, so locate each chunk in the source.
+		if i := strings.Index(doc.Content[offset:], text); i >= 0 {
+			offset += i
+		}
+		startLine := strings.Count(doc.Content[:offset], "\n") + 1
+
+		content := strings.TrimRight(text, " \t\n")
+		trimmed := strings.TrimLeft(content, "\n")
+		startLine += len(content) - len(trimmed)
+		if strings.TrimSpace(trimmed) == "" {
+			continue
+		}
+
+		extra := map[string]interface{}{"start_line": startLine}
+		if language != "" {
+			extra["language"] = language
+		}
+		chunks = append(chunks, newTokenChunk(doc, trimmed, len(chunks), splitter.tokenizer, extra))
+	}
+	return chunks, nil
+}
+
+func tokenChunkSizes(chunkSize, chunkOverlap int) (int, int) {
+	if chunkSize <= 0 {
+		chunkSize = defaultTokenChunkSize
+	}
+	if chunkOverlap < 0 || chunkOverlap >= chunkSize {
+		chunkOverlap = min(defaultTokenChunkOverlap, chunkSize/10)
+	}
+	return chunkSize, chunkOverlap
+}
+
+// newTokenChunk builds a chunk carrying the document metadata, its token
+// count and any extra metadata
+func newTokenChunk(doc Document, content string, index int, tokenizer memory.Tokenizer, extra map[string]interface{}) Chunk {
+	chunk := Chunk{
+		ID:      chunkID(doc.ID, index),
+		Content: content,
+		Index:   index,
+		Metadata: map[string]interface{}{
+			"document_id": doc.ID,
+			"source":      doc.Source,
+			"chunk_index": index,
+			"token_count": tokenizer.CountTokens(content),
+		},
+	}
+	for k, v := range extra {
+		chunk.Metadata[k] = v
+	}
+
+	// Copy document metadata
+	for k, v := range doc.Metadata {
+		if _, exists := chunk.Metadata[k]; !exists {
+			chunk.Metadata[k] = v
+		}
+	}
+
+	return chunk
+}
+
+// tokenSplitter implements recursive splitting and merging by token count
+type tokenSplitter struct {
+	size       int
+	overlap    int
+	tokenizer  memory.Tokenizer
+	separators []string
+}
+
+func newTokenSplitter(size, overlap int, tokenizer memory.Tokenizer, separators []string) *tokenSplitter {
+	size, overlap = tokenChunkSizes(size, overlap)
+	if tokenizer == nil {
+		tokenizer = memory.NewEstimateTokenizer()
+	}
+	if len(separators) == 0 {
+		separators = DefaultSeparators
+	}
+	return &tokenSplitter{size: size, overlap: overlap, tokenizer: tokenizer, separators: separators}
+}
+
+func (s *tokenSplitter) split(text string) []string {
+	return s.splitWith(text, s.separators)
+}
+
+func (s *tokenSplitter) splitWith(text string, separators []string) []string {
+	if s.tokenizer.CountTokens(text) <= s.size {
+		return []string{text}
+	}
+
+	separator, rest := "", []string(nil)
+	for i, candidate := range separators {
+		if candidate == "" || strings.Contains(text, candidate) {
+			separator, rest = candidate, separators[i+1:]
+			break
+		}
+	}
+
+	var (
+		out   []string
+		small []string
+	)
+	for _, piece := range splitKeepingSeparator(text, separator) {
+		if s.tokenizer.CountTokens(piece) <= s.size {
+			small = append(small, piece)
+			continue
+		}
+		out = append(out, s.merge(small)...)
+		small = nil
+		if len(rest) == 0 || separator == "" {
+			out = append(out, piece)
+		} else {
+			out = append(out, s.splitWith(piece, rest)...)
+		}
+	}
+	return append(out, s.merge(small)...)
+}
+
+// merge joins consecutive pieces into chunks of at most size tokens, starting
+// each chunk with up to overlap tokens from the end of the previous one
+func (s *tokenSplitter) merge(pieces []string) []string {
+	var (
+		out     []string
+		current []string
+		counts  []int
+		total   int
+	)
+	for _, piece := range pieces {
+		n := s.tokenizer.CountTokens(piece)
+		if total+n > s.size && len(current) > 0 {
+			out = append(out, strings.Join(current, ""))
+			for len(current) > 0 && (total > s.overlap || total+n > s.size) {
+				total -= counts[0]
+				current, counts = current[1:], counts[1:]
+			}
+		}
+		current = append(current, piece)
+		counts = append(counts, n)
+		total += n
+	}
+	if len(current) > 0 {
+		out = append(out, strings.Join(current, ""))
+	}
+	return out
+}
+
+// splitKeepingSeparator splits text on sep without dropping it: separators
+// starting with a newline begin the following piece, others end the
+// preceding one. An empty separator splits into characters.
+func splitKeepingSeparator(text, sep string) []string {
+	if sep == "" {
+		pieces := make([]string, 0, len(text))
+		for _, r := range text {
+			pieces = append(pieces, string(r))
+		}
+		return pieces
+	}
+
+	before := strings.HasPrefix(sep, "\n")
+	var pieces []string
+	start := 0
+	for from := 0; ; {
+		i := strings.Index(text[from:], sep)
+		if i < 0 {
+			break
+		}
+		cut := from + i
+		from = cut + len(sep)
+		if !before {
+			cut = from
+		}
+		if cut > start {
+			pieces = append(pieces, text[start:cut])
+			start = cut
+		}
+	}
+	if start < len(text) {
+		pieces = append(pieces, text[start:])
+	}
+	return pieces
+}
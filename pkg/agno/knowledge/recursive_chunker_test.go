@@ -0,0 +1,104 @@
+package knowledge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/memory"
+)
+
+// wordTokenizer counts whitespace-separated words
+var wordTokenizer = memory.TokenizerFunc(func(text string) int { return len(strings.Fields(text)) })
+
+func TestRecursiveChunker_PrefersLargerSeparators(t *testing.T) {
+	content := "# Intro\n\none two three. four five six.\n\nseven eight.\n# Usage\n\n" +
+		"nine ten eleven. twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twentyone."
+	chunker := NewRecursiveChunker(8, 0)
+	chunker.Tokenizer = wordTokenizer
+
+	chunks, err := chunker.Chunk(Document{ID: "doc", Content: content, Metadata: map[string]interface{}{"lang": "en"}})
+	if err != nil {
+		t.Fatalf("Chunk() error = %v", err)
+	}
+
+	want := []string{
+		"# Intro\n\none two three. four five six.",
+		"seven eight.",
+		"# Usage",
+		"nine ten eleven.",
+		"twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen",
+		"twenty twentyone.",
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks %q, want %d", len(chunks), contents(chunks), len(want))
+	}
+	for i, chunk := range chunks {
+		if chunk.Content != want[i] {
+			t.Errorf("chunk %d = %q, want %q", i, chunk.Content, want[i])
+		}
+		if n := wordTokenizer.CountTokens(chunk.Content); n > 8 || chunk.Metadata["token_count"] != n {
+			t.Errorf("chunk %d has %d tokens (metadata %v)", i, n, chunk.Metadata["token_count"])
+		}
+	}
+	if chunks[1].ID != "doc_chunk_1" || chunks[1].Metadata["lang"] != "en" {
+		t.Errorf("unexpected chunk identity %+v", chunks[1])
+	}
+}
+
+func TestRecursiveChunker_Overlap(t *testing.T) {
+	chunker := NewRecursiveChunker(4, 2)
+	chunker.Tokenizer = wordTokenizer
+
+	chunks, _ := chunker.Chunk(Document{ID: "doc", Content: "a b c d e f g h"})
+	got := contents(chunks)
+	want := []string{"a b c d", "c d e f", "e f g h"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("chunks = %q, want %q", got, want)
+	}
+}
+
+func TestCodeChunker_SplitsAtDeclarations(t *testing.T) {
+	source := "package demo\n\nimport \"fmt\"\n\nfunc A() {\n\tfmt.Println(\"a\")\n}\n\nfunc B() {\n\tfmt.Println(\"b\")\n}\n"
+	chunker := NewCodeChunker("", 10, 0)
+	chunker.Tokenizer = wordTokenizer
+
+	chunks, err := chunker.Chunk(Document{ID: "demo.go", Content: source, Source: "src/demo.go"})
+	if err != nil {
+		t.Fatalf("Chunk() error = %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks %q", len(chunks), contents(chunks))
+	}
+	if !strings.HasPrefix(chunks[0].Content, "package demo") || !strings.HasSuffix(chunks[0].Content, "fmt.Println(\"a\")\n}") {
+		t.Errorf("first chunk should hold the header and func A, got %q", chunks[0].Content)
+	}
+	if chunks[1].Content != "func B() {\n\tfmt.Println(\"b\")\n}" {
+		t.Errorf("second chunk = %q", chunks[1].Content)
+	}
+	if chunks[1].Metadata["language"] != "go" || chunks[1].Metadata["start_line"] != 9 {
+		t.Errorf("unexpected metadata %+v", chunks[1].Metadata)
+	}
+}
+
+func contents(chunks []Chunk) []string {
+	out := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		out[i] = chunk.Content
+	}
+	return out
+}
+
+func TestRecursiveChunker_DefaultTokenizerMatchesMemory(t *testing.T) {
+	chunker := NewRecursiveChunker(16, 0)
+	chunks, err := chunker.Chunk(Document{ID: "doc", Content: strings.Repeat("Token budgets agree, 知识库. ", 20)})
+	if err != nil {
+		t.Fatalf("Chunk() error = %v", err)
+	}
+
+	estimator := memory.NewEstimateTokenizer()
+	for _, chunk := range chunks {
+		if n := estimator.CountTokens(chunk.Content); n > 16 || chunk.Metadata["token_count"] != n {
+			t.Fatalf("chunk %q: token_count %v, memory estimate %d", chunk.Content, chunk.Metadata["token_count"], n)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package knowledge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+// DefaultRRFK is the rank constant commonly used for reciprocal rank fusion
+const DefaultRRFK = 60
+
+// FuseRRF merges ranked result lists with reciprocal rank fusion: a result
+// scores the sum of 1/(k+rank) over the lists it appears in, ranks starting
+// at 1. Content and metadata come from the first list holding the result,
+// Score is set to the fused score and Distance is cleared. A k of zero or
+// less uses DefaultRRFK.
+func FuseRRF(k int, lists ...[]vectordb.SearchResult) []vectordb.SearchResult {
+	if k <= 0 {
+		k = DefaultRRFK
+	}
+
+	scores := make(map[string]float64)
+	var fused []vectordb.SearchResult
+	for _, list := range lists {
+		for rank, result := range list {
+			if _, seen := scores[result.ID]; !seen {
+				result.Distance = 0
+				fused = append(fused, result)
+			}
+			scores[result.ID] += 1 / float64(k+rank+1)
+		}
+	}
+
+	for i := range fused {
+		fused[i].Score = float32(scores[fused[i].ID])
+	}
+	sort.SliceStable(fused, func(a, b int) bool {
+		return fused[a].Score > fused[b].Score
+	})
+	return fused
+}
+
+// Reranker reorders search results by their relevance to the query, usually
+// with a cross-encoder that reads the query and each passage together. It
+// returns at most topN results (all of them when topN is zero or less), best
+// first, with Score set to the relevance score.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, results []vectordb.SearchResult, topN int) ([]vectordb.SearchResult, error)
+}
+
+// HTTPRerankerConfig configures an HTTPReranker
+type HTTPRerankerConfig struct {
+	// URL of the rerank endpoint (required), e.g. https://api.cohere.com/v2/rerank
+	// or http://localhost:8000/v1/rerank for a vLLM or Infinity server
+	URL string
+	// Model name sent with each request
+	Model string
+	// APIKey optional bearer token
+	APIKey string
+	// HTTPClient optional custom client (default: 30s timeout)
+	HTTPClient *http.Client
+}
+
+// HTTPReranker calls a cross-encoder served behind the Cohere-style rerank
+// API, which Cohere, Jina, Voyage, vLLM and Infinity all implement:
+//
+//	POST {"model": "...", "query": "...", "documents": ["..."], "top_n": 3}
+//	200  {"results": [{"index": 0, "relevance_score": 0.98}]}
+type HTTPReranker struct {
+	url        string
+	model      string
+	apiKey     string
+	httpClient *http.Client
+}
+
+type rerankRequest struct {
+	Model     string   `json:"model,omitempty"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n,omitempty"`
+}
+
+type rerankResult struct {
+	Index          int      `json:"index"`
+	RelevanceScore *float64 `json:"relevance_score"`
+	Score          *float64 `json:"score"`
+}
+
+type rerankResponse struct {
+	Results []rerankResult `json:"results"`
+}
+
+// NewHTTPReranker creates a reranker for a Cohere-compatible rerank endpoint
+func NewHTTPReranker(config HTTPRerankerConfig) (*HTTPReranker, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &HTTPReranker{
+		url:        config.URL,
+		model:      config.Model,
+		apiKey:     config.APIKey,
+		httpClient: config.HTTPClient,
+	}, nil
+}
+
+// Rerank scores every result against query with the remote cross-encoder
+func (r *HTTPReranker) Rerank(ctx context.Context, query string, results []vectordb.SearchResult, topN int) ([]vectordb.SearchResult, error) {
+	if len(results) == 0 {
+		return results, nil
+	}
+	if topN <= 0 || topN > len(results) {
+		topN = len(results)
+	}
+
+	documents := make([]string, len(results))
+	for i, result := range results {
+		documents[i] = result.Content
+	}
+	data, err := json.Marshal(rerankRequest{Model: r.model, Query: query, Documents: documents, TopN: topN})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("rerank failed: status=%d body=%s", resp.StatusCode, string(body))
+	}
+
+	var parsed rerankResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+
+	reranked := make([]vectordb.SearchResult, 0, len(parsed.Results))
+	seen := make(map[int]bool)
+	for _, item := range parsed.Results {
+		if item.Index < 0 || item.Index >= len(results) || seen[item.Index] {
+			continue
+		}
+		seen[item.Index] = true
+
+		result := results[item.Index]
+		switch {
+		case item.RelevanceScore != nil:
+			result.Score = float32(*item.RelevanceScore)
+		case item.Score != nil:
+			result.Score = float32(*item.Score)
+		}
+		reranked = append(reranked, result)
+	}
+	sort.SliceStable(reranked, func(a, b int) bool {
+		return reranked[a].Score > reranked[b].Score
+	})
+	if len(reranked) > topN {
+		reranked = reranked[:topN]
+	}
+	return reranked, nil
+}
+
+var _ Reranker = (*HTTPReranker)(nil)
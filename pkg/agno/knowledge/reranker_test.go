@@ -0,0 +1,74 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+func TestFuseRRF(t *testing.T) {
+	vector := []vectordb.SearchResult{{ID: "a", Distance: 0.1}, {ID: "b"}, {ID: "c"}}
+	keyword := []vectordb.SearchResult{{ID: "c"}, {ID: "d"}, {ID: "a"}}
+
+	fused := FuseRRF(0, vector, keyword)
+	ids := make([]string, len(fused))
+	for i, r := range fused {
+		ids[i] = r.ID
+	}
+	// a: 1/61 + 1/63, c: 1/63 + 1/61 (tie keeps first-seen order), b: 1/62, d: 1/62
+	if len(ids) != 4 || ids[0] != "a" || ids[1] != "c" || ids[2] != "b" || ids[3] != "d" {
+		t.Fatalf("unexpected fused order %v", ids)
+	}
+	if fused[0].Distance != 0 || fused[0].Score <= fused[2].Score {
+		t.Fatalf("unexpected fused scores %+v", fused)
+	}
+}
+
+func TestHTTPReranker(t *testing.T) {
+	var received rerankRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("missing bearer token")
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"results":[{"index":2,"relevance_score":0.9},{"index":0,"relevance_score":0.4}]}`))
+	}))
+	defer server.Close()
+
+	reranker, err := NewHTTPReranker(HTTPRerankerConfig{URL: server.URL, Model: "rerank-v1", APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("NewHTTPReranker() error = %v", err)
+	}
+
+	results := []vectordb.SearchResult{{ID: "a", Content: "alpha"}, {ID: "b", Content: "beta"}, {ID: "c", Content: "gamma"}}
+	reranked, err := reranker.Rerank(context.Background(), "which?", results, 2)
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+	if received.Query != "which?" || received.Model != "rerank-v1" || received.TopN != 2 || len(received.Documents) != 3 {
+		t.Fatalf("unexpected request %+v", received)
+	}
+	if len(reranked) != 2 || reranked[0].ID != "c" || reranked[0].Score != 0.9 || reranked[1].ID != "a" {
+		t.Fatalf("unexpected reranked results %+v", reranked)
+	}
+}
+
+func TestHTTPReranker_Errors(t *testing.T) {
+	if _, err := NewHTTPReranker(HTTPRerankerConfig{}); err == nil {
+		t.Fatal("expected error without a url")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	reranker, _ := NewHTTPReranker(HTTPRerankerConfig{URL: server.URL})
+	if _, err := reranker.Rerank(context.Background(), "q", []vectordb.SearchResult{{ID: "a"}}, 0); err == nil {
+		t.Fatal("expected error for a failed request")
+	}
+}
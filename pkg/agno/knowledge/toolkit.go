@@ -0,0 +1,62 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rexleimo/agno-go/pkg/agno/tools/toolkit"
+)
+
+// SearchToolName is the name of the function registered by Toolkit
+const SearchToolName = "search_knowledge_base"
+
+// Toolkit exposes a knowledge Base to agents as the search_knowledge_base tool
+type Toolkit struct {
+	*toolkit.BaseToolkit
+	base *Base
+}
+
+// NewToolkit creates a toolkit that searches base
+func NewToolkit(base *Base) *Toolkit {
+	t := &Toolkit{
+		BaseToolkit: toolkit.NewBaseToolkit("knowledge"),
+		base:        base,
+	}
+
+	t.RegisterFunction(&toolkit.Function{
+		Name:        SearchToolName,
+		Description: "Search the knowledge base for information relevant to a question. Use it before answering questions about the documents it contains.",
+		Parameters: map[string]toolkit.Parameter{
+			"query": {
+				Type:        "string",
+				Description: "The search query or question",
+				Required:    true,
+			},
+			"limit": {
+				Type:        "integer",
+				Description: fmt.Sprintf("Maximum number of passages to return (default: %d)", base.searchLimit),
+				Minimum:     toolkit.Float(1),
+			},
+		},
+		Handler: t.search,
+	})
+
+	return t
+}
+
+func (t *Toolkit) search(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	query, ok := args["query"].(string)
+	if !ok {
+		return nil, fmt.Errorf("query must be a string")
+	}
+	limit := metadataInt(args["limit"])
+
+	results, err := t.base.Search(ctx, query, limit, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search knowledge base: %w", err)
+	}
+	if len(results) == 0 {
+		return "No relevant information found in the knowledge base.", nil
+	}
+	return FormatResults(results), nil
+}
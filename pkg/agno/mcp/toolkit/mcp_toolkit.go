@@ -167,48 +167,21 @@ func (t *MCPToolkit) shouldIncludeTool(toolName string) bool {
 	return true
 }
 
-// convertSchema converts MCP InputSchema to agno toolkit parameters
-// convertSchema 将 MCP InputSchema 转换为 agno 工具包参数
+// convertSchema converts MCP InputSchema to agno toolkit parameters.
+// Nested objects, array items, enums, defaults and bounds are preserved.
+// convertSchema 将 MCP InputSchema 转换为 agno 工具包参数，保留嵌套对象、数组元素、枚举、默认值和取值范围
 func (t *MCPToolkit) convertSchema(schema protocol.InputSchema) (map[string]toolkit.Parameter, error) {
-	params := make(map[string]toolkit.Parameter)
-
 	// MCP schemas are JSON Schema objects
 	// MCP 模式是 JSON Schema 对象
 	if schema.Type != "object" {
-		return params, nil // Empty params for non-object types
-	}
-
-	// Convert properties
-	// 转换属性
-	for propName, propSchema := range schema.Properties {
-		// Extract type from property schema
-		// 从属性模式中提取类型
-		propMap, ok := propSchema.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		propType, _ := propMap["type"].(string)
-		propDesc, _ := propMap["description"].(string)
-
-		// Check if required
-		// 检查是否必需
-		required := false
-		for _, reqName := range schema.Required {
-			if reqName == propName {
-				required = true
-				break
-			}
-		}
-
-		params[propName] = toolkit.Parameter{
-			Type:        propType,
-			Description: propDesc,
-			Required:    required,
-		}
+		return make(map[string]toolkit.Parameter), nil // Empty params for non-object types
 	}
 
-	return params, nil
+	return toolkit.ParametersFromSchema(map[string]interface{}{
+		"type":       schema.Type,
+		"properties": schema.Properties,
+		"required":   schema.Required,
+	}), nil
 }
 
 // callTool calls an MCP tool and returns the result
@@ -398,3 +398,55 @@ func TestMCPToolkit_GetTools(t *testing.T) {
 		t.Errorf("Expected 2 tools, got %d", len(tools))
 	}
 }
+
+func TestMCPToolkit_ConvertSchemaNested(t *testing.T) {
+	mcpToolkit := &MCPToolkit{}
+
+	params, err := mcpToolkit.convertSchema(protocol.InputSchema{
+		Type: "object",
+		Properties: map[string]interface{}{
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"sku":      map[string]interface{}{"type": "string"},
+						"quantity": map[string]interface{}{"type": "integer", "minimum": 1.0, "default": 1.0},
+					},
+					"required": []interface{}{"sku"},
+				},
+			},
+			"priority": map[string]interface{}{
+				"type": "string",
+				"enum": []interface{}{"low", "high"},
+			},
+			"level": map[string]interface{}{
+				"type": "integer",
+				"enum": []interface{}{1.0, 2.0, 3.0},
+			},
+		},
+		Required: []string{"items"},
+	})
+	if err != nil {
+		t.Fatalf("convertSchema() error = %v", err)
+	}
+
+	items := params["items"]
+	if !items.Required || items.Items == nil || items.Items.Type != "object" {
+		t.Fatalf("unexpected items parameter: %+v", items)
+	}
+	sku := items.Items.Properties["sku"]
+	if !sku.Required || sku.Type != "string" {
+		t.Fatalf("unexpected nested sku parameter: %+v", sku)
+	}
+	quantity := items.Items.Properties["quantity"]
+	if quantity.Minimum == nil || *quantity.Minimum != 1 || quantity.Default != 1.0 {
+		t.Fatalf("unexpected nested quantity parameter: %+v", quantity)
+	}
+	if got := params["priority"].Enum; len(got) != 2 || got[0] != "low" {
+		t.Fatalf("unexpected priority enum: %v", got)
+	}
+	if got := params["level"].Enum; len(got) != 3 || got[0] != 1.0 {
+		t.Fatalf("unexpected level enum: %v", got)
+	}
+}
@@ -0,0 +1,82 @@
+package media
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// ToContentPart 将 Attachment 转换为消息内容片段。
+// URL 附件直接引用；Path 附件从本地磁盘读取并以 base64 内联。
+// ToContentPart converts an attachment to a message content part. URL
+// attachments are referenced directly; Path attachments are read from disk
+// and inlined as base64. Video is sent as a file part with its MIME type.
+func ToContentPart(att Attachment) (types.ContentPart, error) {
+	if err := validate(&att); err != nil {
+		return types.ContentPart{}, err
+	}
+
+	partType := types.ContentPartFile
+	switch att.Type {
+	case "image":
+		partType = types.ContentPartImage
+	case "audio":
+		partType = types.ContentPartAudio
+	}
+
+	mimeType := att.ContentType
+	if mimeType == "" {
+		source := att.Path
+		if att.URL != "" {
+			source = strings.SplitN(att.URL, "?", 2)[0]
+		}
+		mimeType = mime.TypeByExtension(strings.ToLower(filepath.Ext(source)))
+	}
+
+	name := att.Name
+	if name == "" && att.Path != "" {
+		name = filepath.Base(att.Path)
+	}
+
+	if att.URL != "" {
+		return types.ContentPart{Type: partType, URL: att.URL, MimeType: mimeType, Filename: name}, nil
+	}
+
+	data, err := os.ReadFile(att.Path)
+	if err != nil {
+		return types.ContentPart{}, fmt.Errorf("read %s: %w", att.Path, err)
+	}
+	switch partType {
+	case types.ContentPartImage:
+		part := types.NewImageDataPart(data, mimeType)
+		part.Filename = name
+		return part, nil
+	case types.ContentPartAudio:
+		part := types.NewAudioDataPart(data, mimeType)
+		part.Filename = name
+		return part, nil
+	default:
+		return types.NewFileDataPart(data, mimeType, name), nil
+	}
+}
+
+// ToContentParts 批量转换 Attachment。
+// ToContentParts converts attachments to content parts.
+func ToContentParts(attachments []Attachment) ([]types.ContentPart, error) {
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+	parts := make([]types.ContentPart, len(attachments))
+	for i, att := range attachments {
+		part, err := ToContentPart(att)
+		if err != nil {
+			return nil, fmt.Errorf("media[%d]: %w", i, err)
+		}
+		parts[i] = part
+	}
+	return parts, nil
+}
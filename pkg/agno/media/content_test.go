@@ -0,0 +1,46 @@
+package media
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+func TestToContentPart_URL(t *testing.T) {
+	part, err := ToContentPart(Attachment{Type: "image", URL: "https://example.com/cat.png?x=1"})
+	if err != nil {
+		t.Fatalf("ToContentPart error: %v", err)
+	}
+	if part.Type != types.ContentPartImage || part.URL != "https://example.com/cat.png?x=1" || part.MimeType != "image/png" {
+		t.Fatalf("unexpected part: %+v", part)
+	}
+
+	part, err = ToContentPart(Attachment{Type: "video", URL: "https://example.com/clip", ContentType: "video/mp4"})
+	if err != nil {
+		t.Fatalf("ToContentPart error: %v", err)
+	}
+	if part.Type != types.ContentPartFile || part.MimeType != "video/mp4" {
+		t.Fatalf("video should become a file part: %+v", part)
+	}
+}
+
+func TestToContentParts_Path(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.pdf")
+	if err := os.WriteFile(path, []byte("pdf"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	parts, err := ToContentParts([]Attachment{{Type: "file", Path: path}})
+	if err != nil {
+		t.Fatalf("ToContentParts error: %v", err)
+	}
+	if len(parts) != 1 || parts[0].Data != "cGRm" || parts[0].MimeType != "application/pdf" || parts[0].Filename != "note.pdf" {
+		t.Fatalf("unexpected parts: %+v", parts)
+	}
+
+	if _, err := ToContentParts([]Attachment{{Type: "image", Path: filepath.Join(t.TempDir(), "missing.png")}}); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
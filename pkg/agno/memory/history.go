@@ -0,0 +1,86 @@
+package memory
+
+import "github.com/rexleimo/agno-go/pkg/agno/types"
+
+// DefaultUserID is used when no userID is given
+// DefaultUserID 是未指定 userID 时使用的默认值
+const DefaultUserID = "default"
+
+// ResolveUserID returns the first non-empty userID, or DefaultUserID. Memory
+// implementations outside this package use it to share the same tenancy key.
+// ResolveUserID 返回第一个非空 userID，否则返回 DefaultUserID
+func ResolveUserID(userID ...string) string {
+	return getUserID(userID...)
+}
+
+// groupMessages splits history into units that must be kept or dropped
+// together: an assistant message with tool calls forms one unit with the
+// tool result messages that follow it; every other message is its own unit.
+// groupMessages 将历史拆分为必须整体保留或丢弃的单元
+func groupMessages(messages []*types.Message) [][]*types.Message {
+	groups := make([][]*types.Message, 0, len(messages))
+	for i := 0; i < len(messages); i++ {
+		msg := messages[i]
+		if msg.Role != types.RoleAssistant || len(msg.ToolCalls) == 0 {
+			groups = append(groups, []*types.Message{msg})
+			continue
+		}
+
+		callIDs := make(map[string]bool, len(msg.ToolCalls))
+		for _, call := range msg.ToolCalls {
+			callIDs[call.ID] = true
+		}
+
+		group := []*types.Message{msg}
+		for i+1 < len(messages) {
+			next := messages[i+1]
+			if next.Role != types.RoleTool || (next.ToolCallID != "" && !callIDs[next.ToolCallID]) {
+				break
+			}
+			group = append(group, next)
+			i++
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// TrimMessages returns at most maxMessages messages: every system message
+// plus the most recent turns. Turns are kept whole, so the window never
+// starts with tool results whose tool call was cut off; the most recent
+// turn is always kept. A non-positive maxMessages returns messages unchanged.
+// TrimMessages 返回至多 maxMessages 条消息：所有系统消息加最近的对话；
+// 按完整单元保留，不会拆开工具调用与其结果
+func TrimMessages(messages []*types.Message, maxMessages int) []*types.Message {
+	if maxMessages <= 0 || len(messages) <= maxMessages {
+		return messages
+	}
+
+	groups := groupMessages(messages)
+	keep := make([]bool, len(groups))
+	count := 0
+	for i, group := range groups {
+		if group[0].Role == types.RoleSystem {
+			keep[i] = true
+			count += len(group)
+		}
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		if keep[i] {
+			continue
+		}
+		if count+len(groups[i]) > maxMessages && i != len(groups)-1 {
+			break
+		}
+		keep[i] = true
+		count += len(groups[i])
+	}
+
+	kept := make([]*types.Message, 0, count)
+	for i, group := range groups {
+		if keep[i] {
+			kept = append(kept, group...)
+		}
+	}
+	return kept
+}
@@ -1,6 +1,9 @@
 package memory
 
 import (
+    "context"
+    "errors"
+    "slices"
     "sync"
 
     "github.com/rexleimo/agno-go/pkg/agno/types"
@@ -31,6 +34,65 @@ type Memory interface {
 	Size(userID ...string) int
 }
 
+// ContextAdder is implemented by memories whose Add may do slow work, such as
+// calling a model, that should carry the caller's context
+// ContextAdder 由添加消息时可能执行耗时操作（如调用模型）的内存实现，用于传递调用方的上下文
+type ContextAdder interface {
+	AddContext(ctx context.Context, message *types.Message, userID ...string)
+}
+
+// AddWithContext adds a message through AddContext when mem implements
+// ContextAdder and through Add otherwise
+// AddWithContext 在 mem 实现 ContextAdder 时使用 AddContext 添加消息，否则使用 Add
+func AddWithContext(ctx context.Context, mem Memory, message *types.Message, userID ...string) {
+	if adder, ok := mem.(ContextAdder); ok {
+		adder.AddContext(ctx, message, userID...)
+		return
+	}
+	mem.Add(message, userID...)
+}
+
+// ErrToolCallNotFound is returned by EditToolCall when no stored message carries the tool call
+// ErrToolCallNotFound 在没有已存储消息包含该工具调用时由 EditToolCall 返回
+var ErrToolCallNotFound = errors.New("tool call not found")
+
+// ToolCallEditor is implemented by memories that can replace a stored tool
+// call, so later turns see the arguments the call was actually run with
+// ToolCallEditor 由可替换已存储工具调用的内存实现，使后续对话看到实际执行时的参数
+type ToolCallEditor interface {
+	EditToolCall(ctx context.Context, toolCall types.ToolCall, userID ...string) error
+}
+
+// SetToolCall replaces the tool call with the same ID in message and reports whether it was found
+// SetToolCall 替换 message 中相同 ID 的工具调用，并返回是否找到
+func SetToolCall(message *types.Message, toolCall types.ToolCall) bool {
+	if message == nil || message.Role != types.RoleAssistant {
+		return false
+	}
+	for i := range message.ToolCalls {
+		if message.ToolCalls[i].ID == toolCall.ID {
+			message.ToolCalls[i] = toolCall
+			return true
+		}
+	}
+	return false
+}
+
+// editToolCall replaces the tool call in the newest message carrying it. The
+// message is replaced by an edited copy, because GetMessages hands out copies
+// that share its ToolCalls.
+func editToolCall(messages []*types.Message, toolCall types.ToolCall) error {
+	for i := len(messages) - 1; i >= 0; i-- {
+		edited := *messages[i]
+		edited.ToolCalls = slices.Clone(edited.ToolCalls)
+		if SetToolCall(&edited, toolCall) {
+			messages[i] = &edited
+			return nil
+		}
+	}
+	return ErrToolCallNotFound
+}
+
 // InMemory provides simple in-memory message storage with multi-tenant support
 // InMemory 提供简单的内存消息存储，支持多租户
 type InMemory struct {
@@ -59,7 +121,7 @@ func getUserID(userID ...string) string {
 	if len(userID) > 0 && userID[0] != "" {
 		return userID[0]
 	}
-	return "default"
+	return DefaultUserID
 }
 
 // Add appends a message to memory for a specific user
@@ -131,6 +193,15 @@ func (m *InMemory) GetMessages(userID ...string) []*types.Message {
 	return messages
 }
 
+// EditToolCall implements ToolCallEditor
+// EditToolCall 实现 ToolCallEditor
+func (m *InMemory) EditToolCall(ctx context.Context, toolCall types.ToolCall, userID ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return editToolCall(m.userMessages[getUserID(userID...)], toolCall)
+}
+
 // Clear removes all messages for a specific user
 // Clear 删除特定用户的所有消息
 // If called without userID, clears the default user (for backward compatibility)
@@ -1,6 +1,8 @@
 package memory
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/rexleimo/agno-go/pkg/agno/types"
@@ -237,3 +239,34 @@ func TestInMemory_AssignsIDIfMissing(t *testing.T) {
         t.Error("expected memory.Add to assign an ID to message with empty ID")
     }
 }
+
+func TestInMemory_EditToolCall(t *testing.T) {
+	mem := NewInMemory(10)
+	call := types.ToolCall{
+		ID:       "call_1",
+		Type:     "function",
+		Function: types.ToolCallFunction{Name: "send", Arguments: `{"to":"a"}`},
+	}
+	mem.Add(&types.Message{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{call}}, "alice")
+
+	before := mem.GetMessages("alice")
+
+	call.Function.Arguments = `{"to":"b"}`
+	if err := mem.EditToolCall(context.Background(), call, "alice"); err != nil {
+		t.Fatalf("EditToolCall() error = %v", err)
+	}
+	if got := mem.GetMessages("alice")[0].ToolCalls[0].Function.Arguments; got != `{"to":"b"}` {
+		t.Errorf("expected edited arguments, got %s", got)
+	}
+	if got := before[0].ToolCalls[0].Function.Arguments; got != `{"to":"a"}` {
+		t.Errorf("earlier GetMessages result should not change, got %s", got)
+	}
+
+	call.ID = "call_2"
+	if err := mem.EditToolCall(context.Background(), call, "alice"); !errors.Is(err, ErrToolCallNotFound) {
+		t.Errorf("expected ErrToolCallNotFound, got %v", err)
+	}
+	if err := mem.EditToolCall(context.Background(), call, "bob"); !errors.Is(err, ErrToolCallNotFound) {
+		t.Errorf("expected ErrToolCallNotFound for another user, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// MessageStore persists conversation messages per user. Database-backed
+// memories implement it and expose it as a Memory through StoreMemory.
+// MessageStore 按用户持久化对话消息，数据库后端实现它并通过 StoreMemory 提供 Memory
+type MessageStore interface {
+	// Append stores a message for the user
+	Append(ctx context.Context, userID string, message *types.Message) error
+
+	// Recent returns the user's system messages and at most limit of the
+	// most recent other messages, in insertion order
+	Recent(ctx context.Context, userID string, limit int) ([]*types.Message, error)
+
+	// Delete removes the stored messages of the user
+	Delete(ctx context.Context, userID string) error
+
+	// DeleteAll removes the stored messages of every user
+	DeleteAll(ctx context.Context) error
+}
+
+// ToolCallStore is implemented by message stores that can replace a stored
+// tool call; StoreMemory.EditToolCall uses it
+// ToolCallStore 由可替换已存储工具调用的消息存储实现
+type ToolCallStore interface {
+	ReplaceToolCall(ctx context.Context, userID string, toolCall types.ToolCall) error
+}
+
+// StoreMemory implements Memory on top of a MessageStore. GetMessages only
+// reads the most recent MaxMessages messages, so its cost does not grow with
+// the stored history. The Memory methods cannot return errors, so store
+// failures are logged.
+// StoreMemory 基于 MessageStore 实现 Memory，只读取最近的消息；存储错误记录到日志
+type StoreMemory struct {
+	store       MessageStore
+	maxMessages int
+	logger      *slog.Logger
+}
+
+// NewStoreMemory wraps store. maxMessages limits the history returned by
+// GetMessages, like InMemory's maxSize; all messages stay stored.
+// NewStoreMemory 包装 store，maxMessages 限制 GetMessages 返回的消息数
+func NewStoreMemory(store MessageStore, maxMessages int, logger *slog.Logger) *StoreMemory {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &StoreMemory{store: store, maxMessages: maxMessages, logger: logger}
+}
+
+// Add implements Memory
+func (m *StoreMemory) Add(message *types.Message, userID ...string) {
+	uid := ResolveUserID(userID...)
+	if err := m.store.Append(context.Background(), uid, message); err != nil {
+		m.logger.Error("failed to store memory message", "user_id", uid, "error", err)
+	}
+}
+
+// GetMessages implements Memory, returning at most MaxMessages messages
+func (m *StoreMemory) GetMessages(userID ...string) []*types.Message {
+	uid := ResolveUserID(userID...)
+	messages, err := m.store.Recent(context.Background(), uid, m.maxMessages)
+	if err != nil {
+		m.logger.Error("failed to load memory messages", "user_id", uid, "error", err)
+		return []*types.Message{}
+	}
+	return TrimMessages(dropLeadingToolResults(messages), m.maxMessages)
+}
+
+// Clear implements Memory
+func (m *StoreMemory) Clear(userID ...string) {
+	uid := ResolveUserID(userID...)
+	if err := m.store.Delete(context.Background(), uid); err != nil {
+		m.logger.Error("failed to clear memory messages", "user_id", uid, "error", err)
+	}
+}
+
+// ClearAll removes the messages of every user
+func (m *StoreMemory) ClearAll() {
+	if err := m.store.DeleteAll(context.Background()); err != nil {
+		m.logger.Error("failed to clear memory messages", "error", err)
+	}
+}
+
+// EditToolCall implements ToolCallEditor when the store implements ToolCallStore
+func (m *StoreMemory) EditToolCall(ctx context.Context, toolCall types.ToolCall, userID ...string) error {
+	store, ok := m.store.(ToolCallStore)
+	if !ok {
+		return fmt.Errorf("message store %T cannot edit tool calls", m.store)
+	}
+	return store.ReplaceToolCall(ctx, ResolveUserID(userID...), toolCall)
+}
+
+// Size implements Memory, counting the messages GetMessages returns
+func (m *StoreMemory) Size(userID ...string) int {
+	return len(m.GetMessages(userID...))
+}
+
+// dropLeadingToolResults removes the tool results that open a window of
+// recent messages, because the tool call they answer was cut off
+func dropLeadingToolResults(messages []*types.Message) []*types.Message {
+	kept := make([]*types.Message, 0, len(messages))
+	leading := true
+	for _, msg := range messages {
+		if msg.Role == types.RoleSystem {
+			kept = append(kept, msg)
+			continue
+		}
+		if leading && msg.Role == types.RoleTool {
+			continue
+		}
+		leading = false
+		kept = append(kept, msg)
+	}
+	return kept
+}
+
+var (
+	_ Memory         = (*StoreMemory)(nil)
+	_ ToolCallEditor = (*StoreMemory)(nil)
+)
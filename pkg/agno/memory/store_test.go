@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+type fakeStore struct {
+	messages  []*types.Message
+	lastLimit int
+	err       error
+}
+
+func (s *fakeStore) Append(ctx context.Context, userID string, message *types.Message) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+func (s *fakeStore) Recent(ctx context.Context, userID string, limit int) ([]*types.Message, error) {
+	s.lastLimit = limit
+	return s.messages, s.err
+}
+
+func (s *fakeStore) Delete(ctx context.Context, userID string) error {
+	s.messages = nil
+	return s.err
+}
+
+func (s *fakeStore) DeleteAll(ctx context.Context) error {
+	s.messages = nil
+	return s.err
+}
+
+func TestStoreMemory_GetMessages(t *testing.T) {
+	store := &fakeStore{messages: []*types.Message{
+		types.NewSystemMessage("system"),
+		types.NewToolMessage("call_1", "orphan"),
+		types.NewUserMessage("question"),
+		types.NewAssistantMessage("answer"),
+	}}
+	mem := NewStoreMemory(store, 10, nil)
+
+	got := mem.GetMessages()
+	if store.lastLimit != 10 {
+		t.Fatalf("Recent limit = %d, want 10", store.lastLimit)
+	}
+	if len(got) != 3 || got[0].Role != types.RoleSystem || got[1].Content != "question" {
+		t.Fatalf("unexpected messages: %+v", got)
+	}
+	if mem.Size() != 3 {
+		t.Fatalf("Size() = %d, want 3", mem.Size())
+	}
+}
+
+func TestStoreMemory_StoreErrors(t *testing.T) {
+	mem := NewStoreMemory(&fakeStore{err: errors.New("unavailable")}, 10, nil)
+
+	mem.Add(types.NewUserMessage("hi"))
+	if got := mem.GetMessages(); len(got) != 0 {
+		t.Fatalf("expected no messages on error, got %+v", got)
+	}
+	mem.Clear()
+	mem.ClearAll()
+}
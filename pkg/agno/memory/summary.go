@@ -0,0 +1,422 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+const (
+	defaultSummaryMaxMessages = 20
+	defaultSummaryKeepRecent  = 6
+	defaultSummaryTimeout     = 30 * time.Second
+
+	// SummaryMessagePrefix starts the system message that carries the summary
+	// SummaryMessagePrefix 是摘要系统消息的开头
+	SummaryMessagePrefix = "Summary of the earlier conversation:\n"
+
+	defaultSummaryPrompt = "You maintain a running summary of a conversation between a user and an assistant. " +
+		"Merge the new turns into the existing summary. Keep facts, decisions, user preferences, open questions " +
+		"and results of tool calls that later turns may depend on. Be concise and reply with the updated summary only."
+)
+
+// SummaryMemoryConfig configures a SummaryMemory
+// SummaryMemoryConfig 配置 SummaryMemory
+type SummaryMemoryConfig struct {
+	// Model writes the summaries (required)
+	// Model 用于生成摘要（必填）
+	Model models.Model
+
+	// MaxMessages is the number of non-system messages that triggers
+	// summarization (default: 20)
+	// MaxMessages 是触发摘要的非系统消息数量（默认：20）
+	MaxMessages int
+
+	// MaxTokens additionally triggers summarization when the history exceeds
+	// this many tokens (0 disables the token threshold)
+	// MaxTokens 为历史 token 数阈值，超过时同样触发摘要（0 表示不启用）
+	MaxTokens int
+
+	// KeepRecent is the number of most recent messages kept verbatim after
+	// summarizing (default: 6)
+	// KeepRecent 是摘要后原样保留的最近消息数（默认：6）
+	KeepRecent int
+
+	// Tokenizer counts tokens for MaxTokens (default: EstimateTokenizer)
+	Tokenizer Tokenizer
+
+	// Prompt is the system prompt used to update the summary
+	// Prompt 是更新摘要时使用的系统提示词
+	Prompt string
+
+	// Timeout bounds each summarization call (default: 30s)
+	Timeout time.Duration
+
+	// Logger receives summarization failures (default: slog.Default())
+	Logger *slog.Logger
+}
+
+// summaryState is the history of one user
+type summaryState struct {
+	messages    []*types.Message
+	summary     string
+	summarizing bool
+}
+
+// SummaryMemory compresses older turns into a rolling summary once a
+// threshold is crossed. Each user has its own summary, which is updated
+// incrementally with the turns that fall out of the recent window. The
+// summary is returned as a system message placed after the leading system
+// messages and before the recent turns.
+// SummaryMemory 在超过阈值后将较早的对话压缩为滚动摘要。每个用户拥有独立摘要，
+// 并随滑出窗口的对话增量更新；摘要以系统消息的形式位于最近对话之前
+type SummaryMemory struct {
+	model     models.Model
+	config    SummaryMemoryConfig
+	tokenizer Tokenizer
+	logger    *slog.Logger
+	users     map[string]*summaryState
+	mu        sync.Mutex
+	pending   sync.WaitGroup // Background summarizations started by Add
+}
+
+// NewSummaryMemory creates a summarizing memory
+// NewSummaryMemory 创建摘要内存
+func NewSummaryMemory(config SummaryMemoryConfig) (*SummaryMemory, error) {
+	if config.Model == nil {
+		return nil, types.NewInvalidConfigError("summary model is required", nil)
+	}
+	if config.MaxMessages <= 0 {
+		config.MaxMessages = defaultSummaryMaxMessages
+	}
+	if config.KeepRecent <= 0 {
+		config.KeepRecent = defaultSummaryKeepRecent
+	}
+	if config.KeepRecent >= config.MaxMessages {
+		config.KeepRecent = config.MaxMessages / 2
+	}
+	if config.Tokenizer == nil {
+		config.Tokenizer = NewEstimateTokenizer()
+	}
+	if config.Prompt == "" {
+		config.Prompt = defaultSummaryPrompt
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultSummaryTimeout
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
+	return &SummaryMemory{
+		model:     config.Model,
+		config:    config,
+		tokenizer: config.Tokenizer,
+		logger:    config.Logger,
+		users:     make(map[string]*summaryState),
+	}, nil
+}
+
+// Add appends a message for a user. See AddContext.
+// Add 为用户添加消息，参见 AddContext
+func (m *SummaryMemory) Add(message *types.Message, userID ...string) {
+	m.AddContext(context.Background(), message, userID...)
+}
+
+// AddContext appends a message for a user and, when the threshold is
+// crossed, summarizes older turns in the background so the caller is not
+// blocked by the model. The summarization keeps the values of ctx, such as
+// the run context, but not its cancellation, and is bounded by Timeout.
+// Failures are logged and retried on a later Add; no messages are lost.
+// AddContext 为用户添加消息，超过阈值时在后台对较早的对话进行摘要
+func (m *SummaryMemory) AddContext(ctx context.Context, message *types.Message, userID ...string) {
+	if message == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	uid := getUserID(userID...)
+
+	m.mu.Lock()
+	if message.ID == "" {
+		message.ID = "msg-" + uuid.NewString()
+	}
+	state := m.state(uid)
+	state.messages = append(state.messages, message)
+	due := !state.summarizing && m.overThreshold(state)
+	m.mu.Unlock()
+
+	if !due {
+		return
+	}
+	m.pending.Add(1)
+	go func() {
+		defer m.pending.Done()
+		if err := m.Summarize(context.WithoutCancel(ctx), uid); err != nil {
+			m.logger.Warn("memory summarization failed", "user_id", uid, "error", err)
+		}
+	}()
+}
+
+// Wait blocks until the background summarizations started by Add finish
+// Wait 等待 Add 启动的后台摘要完成
+func (m *SummaryMemory) Wait() {
+	m.pending.Wait()
+}
+
+// Summarize folds all but the most recent turns of a user into the summary,
+// regardless of the threshold. It runs synchronously and honors ctx.
+// Summarize 立即将用户除最近对话外的历史合并进摘要
+func (m *SummaryMemory) Summarize(ctx context.Context, userID ...string) error {
+	uid := getUserID(userID...)
+
+	m.mu.Lock()
+	state := m.state(uid)
+	if state.summarizing {
+		m.mu.Unlock()
+		return nil
+	}
+	folded := m.foldable(state.messages)
+	if len(folded) == 0 {
+		m.mu.Unlock()
+		return nil
+	}
+	previous := state.summary
+	state.summarizing = true
+	m.mu.Unlock()
+
+	summary, err := m.generate(ctx, previous, folded)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The user may have been cleared while the model was running.
+	current, ok := m.users[uid]
+	if !ok || current != state {
+		return nil
+	}
+	state.summarizing = false
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[*types.Message]bool, len(folded))
+	for _, msg := range folded {
+		remove[msg] = true
+	}
+	kept := make([]*types.Message, 0, len(state.messages)-len(folded))
+	for _, msg := range state.messages {
+		if !remove[msg] {
+			kept = append(kept, msg)
+		}
+	}
+	state.messages = kept
+	state.summary = summary
+	return nil
+}
+
+// Summary returns the current summary of a user, if any
+// Summary 返回用户当前的摘要
+func (m *SummaryMemory) Summary(userID ...string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if state, ok := m.users[getUserID(userID...)]; ok {
+		return state.summary
+	}
+	return ""
+}
+
+// GetMessages returns the leading system messages, the summary message and
+// the recent turns of a user
+// GetMessages 返回用户的前置系统消息、摘要消息和最近对话
+func (m *SummaryMemory) GetMessages(userID ...string) []*types.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	uid := getUserID(userID...)
+	state, ok := m.users[uid]
+	if !ok {
+		return []*types.Message{}
+	}
+
+	messages := make([]*types.Message, 0, len(state.messages)+1)
+	i := 0
+	for ; i < len(state.messages) && state.messages[i].Role == types.RoleSystem; i++ {
+		msgCopy := *state.messages[i]
+		messages = append(messages, &msgCopy)
+	}
+	if state.summary != "" {
+		summaryMsg := types.NewSystemMessage(SummaryMessagePrefix + state.summary)
+		summaryMsg.ID = "summary-" + uid
+		messages = append(messages, summaryMsg)
+	}
+	for ; i < len(state.messages); i++ {
+		msgCopy := *state.messages[i]
+		messages = append(messages, &msgCopy)
+	}
+	return messages
+}
+
+// EditToolCall implements ToolCallEditor for the messages not yet folded into the summary
+// EditToolCall 为尚未并入摘要的消息实现 ToolCallEditor
+func (m *SummaryMemory) EditToolCall(ctx context.Context, toolCall types.ToolCall, userID ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.users[getUserID(userID...)]
+	if !ok {
+		return ErrToolCallNotFound
+	}
+	return editToolCall(state.messages, toolCall)
+}
+
+// Clear removes the messages and the summary of a user
+// Clear 删除用户的消息和摘要
+func (m *SummaryMemory) Clear(userID ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.users, getUserID(userID...))
+}
+
+// ClearAll removes the messages and summaries of all users
+// ClearAll 删除所有用户的消息和摘要
+func (m *SummaryMemory) ClearAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.users = make(map[string]*summaryState)
+}
+
+// Size returns the number of stored messages of a user, excluding the summary
+// Size 返回用户存储的消息数量（不含摘要）
+func (m *SummaryMemory) Size(userID ...string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if state, ok := m.users[getUserID(userID...)]; ok {
+		return len(state.messages)
+	}
+	return 0
+}
+
+func (m *SummaryMemory) state(uid string) *summaryState {
+	state, ok := m.users[uid]
+	if !ok {
+		state = &summaryState{}
+		m.users[uid] = state
+	}
+	return state
+}
+
+func (m *SummaryMemory) overThreshold(state *summaryState) bool {
+	count, tokens := 0, 0
+	for _, msg := range state.messages {
+		if msg.Role != types.RoleSystem {
+			count++
+		}
+		if m.config.MaxTokens > 0 {
+			tokens += CountMessageTokens(m.tokenizer, msg)
+		}
+	}
+	if count > m.config.MaxMessages {
+		return true
+	}
+	if m.config.MaxTokens > 0 {
+		tokens += m.tokenizer.CountTokens(state.summary)
+		return tokens > m.config.MaxTokens
+	}
+	return false
+}
+
+// foldable returns the non-system messages outside the recent window. The
+// window grows to whole units so tool calls stay with their results.
+func (m *SummaryMemory) foldable(messages []*types.Message) []*types.Message {
+	groups := groupMessages(messages)
+
+	recent := 0
+	cut := len(groups)
+	for cut > 0 && recent < m.config.KeepRecent {
+		cut--
+		if groups[cut][0].Role != types.RoleSystem {
+			recent += len(groups[cut])
+		}
+	}
+
+	var folded []*types.Message
+	for _, group := range groups[:cut] {
+		if group[0].Role != types.RoleSystem {
+			folded = append(folded, group...)
+		}
+	}
+	return folded
+}
+
+func (m *SummaryMemory) generate(ctx context.Context, previous string, messages []*types.Message) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	var prompt strings.Builder
+	if previous != "" {
+		prompt.WriteString("Existing summary:\n")
+		prompt.WriteString(previous)
+		prompt.WriteString("\n\n")
+	}
+	prompt.WriteString("New conversation turns:\n")
+	prompt.WriteString(formatTranscript(messages))
+
+	resp, err := m.model.Invoke(ctx, &models.InvokeRequest{
+		Messages: []*types.Message{
+			types.NewSystemMessage(m.config.Prompt),
+			types.NewUserMessage(prompt.String()),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	summary := strings.TrimSpace(resp.Content)
+	if summary == "" {
+		return "", fmt.Errorf("summary model %s returned an empty summary", m.model.GetID())
+	}
+	return summary, nil
+}
+
+// formatTranscript renders messages as plain text for the summary prompt, so
+// tool calls do not need to be paired the way providers require
+func formatTranscript(messages []*types.Message) string {
+	var b strings.Builder
+	for _, msg := range messages {
+		switch {
+		case len(msg.ToolCalls) > 0:
+			for _, call := range msg.ToolCalls {
+				fmt.Fprintf(&b, "assistant called %s(%s)\n", call.Function.Name, call.Function.Arguments)
+			}
+			if msg.Content != "" {
+				fmt.Fprintf(&b, "assistant: %s\n", msg.Content)
+			}
+		case msg.Role == types.RoleTool:
+			fmt.Fprintf(&b, "tool result: %s\n", msg.Content)
+		default:
+			fmt.Fprintf(&b, "%s: %s\n", msg.Role, msg.Content)
+		}
+		for _, part := range msg.Parts {
+			if part.Type != types.ContentPartText {
+				fmt.Fprintf(&b, "%s attached %s\n", msg.Role, part.Describe())
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+type mockSummaryModel struct {
+	models.BaseModel
+	prompts []string
+	err     error
+}
+
+func (m *mockSummaryModel) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+	m.prompts = append(m.prompts, req.Messages[len(req.Messages)-1].Content)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &types.ModelResponse{Content: "summary " + string(rune('0'+len(m.prompts)))}, nil
+}
+
+func (m *mockSummaryModel) InvokeStream(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
+	ch := make(chan types.ResponseChunk)
+	close(ch)
+	return ch, nil
+}
+
+func newMockSummaryModel() *mockSummaryModel {
+	return &mockSummaryModel{BaseModel: models.BaseModel{ID: "summary", Provider: "mock"}}
+}
+
+// addAndWait adds a message and waits for the summarization it may start
+func addAndWait(mem *SummaryMemory, message *types.Message, userID ...string) {
+	mem.Add(message, userID...)
+	mem.Wait()
+}
+
+func TestNewSummaryMemory_RequiresModel(t *testing.T) {
+	if _, err := NewSummaryMemory(SummaryMemoryConfig{}); err == nil {
+		t.Fatal("expected error without a model")
+	}
+}
+
+func TestSummaryMemory_SummarizesOlderTurns(t *testing.T) {
+	model := newMockSummaryModel()
+	mem, err := NewSummaryMemory(SummaryMemoryConfig{Model: model, MaxMessages: 4, KeepRecent: 2})
+	if err != nil {
+		t.Fatalf("NewSummaryMemory() error = %v", err)
+	}
+
+	addAndWait(mem, types.NewSystemMessage("be helpful"))
+	addAndWait(mem, types.NewUserMessage("my name is Ada"))
+	addAndWait(mem, types.NewAssistantMessage("hello Ada"))
+	addAndWait(mem, types.NewUserMessage("I like Go"))
+	addAndWait(mem, types.NewAssistantMessage("noted"))
+	if len(model.prompts) != 0 {
+		t.Fatal("summarization should wait until the threshold is crossed")
+	}
+	addAndWait(mem, types.NewUserMessage("what do I like?"))
+
+	if len(model.prompts) != 1 {
+		t.Fatalf("expected one summarization, got %d", len(model.prompts))
+	}
+	if !strings.Contains(model.prompts[0], "user: my name is Ada") || strings.Contains(model.prompts[0], "what do I like?") {
+		t.Errorf("unexpected summary prompt: %q", model.prompts[0])
+	}
+
+	messages := mem.GetMessages()
+	if len(messages) != 4 {
+		t.Fatalf("expected system, summary and 2 recent messages, got %d", len(messages))
+	}
+	if messages[0].Content != "be helpful" {
+		t.Error("instructions should stay first")
+	}
+	if messages[1].Role != types.RoleSystem || messages[1].Content != SummaryMessagePrefix+"summary 1" {
+		t.Errorf("unexpected summary message: %+v", messages[1])
+	}
+	if messages[2].Content != "noted" || messages[3].Content != "what do I like?" {
+		t.Errorf("unexpected recent turns: %q, %q", messages[2].Content, messages[3].Content)
+	}
+	if mem.Size() != 3 {
+		t.Errorf("Size() = %d, want 3", mem.Size())
+	}
+}
+
+func TestSummaryMemory_IncrementalPerUser(t *testing.T) {
+	model := newMockSummaryModel()
+	mem, _ := NewSummaryMemory(SummaryMemoryConfig{Model: model, MaxMessages: 2, KeepRecent: 1})
+
+	for _, text := range []string{"a1", "a2", "a3"} {
+		addAndWait(mem, types.NewUserMessage(text), "alice")
+	}
+	addAndWait(mem, types.NewUserMessage("b1"), "bob")
+
+	if mem.Summary("alice") == "" {
+		t.Fatal("alice should have a summary")
+	}
+	if mem.Summary("bob") != "" {
+		t.Fatal("bob should not have a summary yet")
+	}
+
+	addAndWait(mem, types.NewUserMessage("a4"), "alice")
+	addAndWait(mem, types.NewUserMessage("a5"), "alice")
+
+	last := model.prompts[len(model.prompts)-1]
+	if !strings.Contains(last, "Existing summary:\nsummary 1") {
+		t.Errorf("later summaries should build on the previous one: %q", last)
+	}
+	if strings.Contains(last, "a1") {
+		t.Errorf("already summarized turns should not be resent: %q", last)
+	}
+}
+
+func TestSummaryMemory_KeepsToolCallsWithResults(t *testing.T) {
+	model := newMockSummaryModel()
+	mem, _ := NewSummaryMemory(SummaryMemoryConfig{Model: model, MaxMessages: 2, KeepRecent: 1})
+
+	addAndWait(mem, types.NewUserMessage("weather?"))
+	addAndWait(mem, &types.Message{
+		Role:      types.RoleAssistant,
+		ToolCalls: []types.ToolCall{{ID: "c1", Function: types.ToolCallFunction{Name: "weather", Arguments: `{"city":"Paris"}`}}},
+	})
+	addAndWait(mem, types.NewToolMessage("c1", "sunny"))
+
+	// The recent window grows to keep the tool result with its call
+	messages := mem.GetMessages()
+	if len(messages) != 3 || len(messages[1].ToolCalls) != 1 || messages[2].Role != types.RoleTool {
+		t.Fatalf("expected summary followed by the tool call and its result, got %d messages", len(messages))
+	}
+
+	addAndWait(mem, types.NewAssistantMessage("It is sunny"))
+	if len(model.prompts) != 2 || !strings.Contains(model.prompts[1], "assistant called weather") {
+		t.Errorf("tool calls should be rendered in the transcript: %q", model.prompts)
+	}
+	if mem.Size() != 1 {
+		t.Errorf("Size() = %d, want 1", mem.Size())
+	}
+}
+
+func TestSummaryMemory_FailureKeepsMessages(t *testing.T) {
+	model := newMockSummaryModel()
+	model.err = errors.New("boom")
+	mem, _ := NewSummaryMemory(SummaryMemoryConfig{Model: model, MaxMessages: 2, KeepRecent: 1})
+
+	for _, text := range []string{"one", "two", "three"} {
+		addAndWait(mem, types.NewUserMessage(text))
+	}
+
+	if mem.Size() != 3 || mem.Summary() != "" {
+		t.Fatalf("failed summarization must not drop messages: size=%d summary=%q", mem.Size(), mem.Summary())
+	}
+
+	mem.Clear()
+	if mem.Size() != 0 || len(mem.GetMessages()) != 0 {
+		t.Error("Clear should remove messages")
+	}
+}
+
+// blockingSummaryModel waits for release before answering
+type blockingSummaryModel struct {
+	mockSummaryModel
+	release chan struct{}
+	ctxKey  interface{}
+	seen    interface{}
+}
+
+func (m *blockingSummaryModel) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+	<-m.release
+	m.seen = ctx.Value(m.ctxKey)
+	return &types.ModelResponse{Content: "summary"}, nil
+}
+
+func TestSummaryMemory_AddDoesNotBlock(t *testing.T) {
+	type ctxKey struct{}
+	model := &blockingSummaryModel{
+		mockSummaryModel: *newMockSummaryModel(),
+		release:          make(chan struct{}),
+		ctxKey:           ctxKey{},
+	}
+	mem, _ := NewSummaryMemory(SummaryMemoryConfig{Model: model, MaxMessages: 2, KeepRecent: 1})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "run-1"))
+	for _, text := range []string{"one", "two", "three"} {
+		AddWithContext(ctx, mem, types.NewUserMessage(text))
+	}
+	// The run ends before the summary model answers
+	cancel()
+	if mem.Size() != 3 {
+		t.Fatalf("Size() = %d, want 3 while summarizing", mem.Size())
+	}
+
+	close(model.release)
+	mem.Wait()
+	if mem.Summary() != "summary" || mem.Size() != 1 {
+		t.Fatalf("summary = %q, size = %d", mem.Summary(), mem.Size())
+	}
+	if model.seen != "run-1" {
+		t.Fatalf("summary model saw context value %v, want run-1", model.seen)
+	}
+}
+
+var _ Memory = (*SummaryMemory)(nil)
+var _ ContextAdder = (*SummaryMemory)(nil)
@@ -0,0 +1,230 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// maxDefaultReserveTokens caps the share of the context window reserved for
+// the model's reply when TokenBudgetConfig.ReserveTokens is not set
+const maxDefaultReserveTokens = 4096
+
+// TokenBudgetConfig configures a TokenBudget memory
+// TokenBudgetConfig 配置 TokenBudget 内存
+type TokenBudgetConfig struct {
+	// MaxTokens is the history budget in tokens. When zero it is derived from
+	// the context window of ModelID minus ReserveTokens.
+	// MaxTokens 是历史记录的 token 预算，为 0 时根据 ModelID 的上下文窗口计算
+	MaxTokens int
+
+	// ModelID selects the context window used when MaxTokens is zero
+	// ModelID 用于在 MaxTokens 为 0 时确定上下文窗口
+	ModelID string
+
+	// ReserveTokens is kept free for the instructions, tools and the reply
+	// when the budget is derived from ModelID (default: a quarter of the
+	// window, at most 4096)
+	// ReserveTokens 为指令、工具和回复预留的 token 数
+	ReserveTokens int
+
+	// Tokenizer counts tokens (default: EstimateTokenizer)
+	// Tokenizer 用于统计 token（默认：EstimateTokenizer）
+	Tokenizer Tokenizer
+}
+
+// TokenBudget keeps conversation history within a token budget per user.
+// When the budget is exceeded the oldest turns are dropped first. System
+// messages are always kept, an assistant message with tool calls is dropped
+// together with its tool results, and the most recent turn is never dropped.
+// TokenBudget 按 token 预算保存每个用户的对话历史：超出预算时优先丢弃最早的对话，
+// 始终保留系统消息，带工具调用的助手消息与其工具结果一起丢弃，最近一轮永不丢弃
+type TokenBudget struct {
+	userMessages map[string][]*types.Message
+	trimmed      map[string]bool // users whose history lost its oldest units
+	maxTokens    int
+	tokenizer    Tokenizer
+	mu           sync.RWMutex
+}
+
+// NewTokenBudget creates a token-budget memory
+// NewTokenBudget 创建基于 token 预算的内存
+func NewTokenBudget(config TokenBudgetConfig) *TokenBudget {
+	if config.Tokenizer == nil {
+		config.Tokenizer = NewEstimateTokenizer()
+	}
+
+	maxTokens := config.MaxTokens
+	if maxTokens <= 0 {
+		window := ContextWindow(config.ModelID)
+		reserve := config.ReserveTokens
+		if reserve <= 0 {
+			reserve = window / 4
+			if reserve > maxDefaultReserveTokens {
+				reserve = maxDefaultReserveTokens
+			}
+		}
+		maxTokens = window - reserve
+		if maxTokens <= 0 {
+			maxTokens = window / 2
+		}
+	}
+
+	return &TokenBudget{
+		userMessages: make(map[string][]*types.Message),
+		trimmed:      make(map[string]bool),
+		maxTokens:    maxTokens,
+		tokenizer:    config.Tokenizer,
+	}
+}
+
+// MaxTokens returns the history budget in tokens
+// MaxTokens 返回历史记录的 token 预算
+func (m *TokenBudget) MaxTokens() int {
+	return m.maxTokens
+}
+
+// Add appends a message for a user and trims the history to the budget
+// Add 为用户添加消息并将历史裁剪到预算之内
+func (m *TokenBudget) Add(message *types.Message, userID ...string) {
+	if message == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	uid := getUserID(userID...)
+
+	// Ensure message has an ID for traceability
+	if message.ID == "" {
+		message.ID = "msg-" + uuid.NewString()
+	}
+
+	messages, trimmed := trimToBudget(append(m.userMessages[uid], message), m.maxTokens, m.tokenizer, m.trimmed[uid])
+	m.userMessages[uid] = messages
+	if trimmed {
+		m.trimmed[uid] = true
+	}
+}
+
+// GetMessages returns a copy of the messages for a user
+// GetMessages 返回用户消息的副本
+func (m *TokenBudget) GetMessages(userID ...string) []*types.Message {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	userMsgs := m.userMessages[getUserID(userID...)]
+	messages := make([]*types.Message, len(userMsgs))
+	for i, msg := range userMsgs {
+		msgCopy := *msg
+		messages[i] = &msgCopy
+	}
+	return messages
+}
+
+// EditToolCall implements ToolCallEditor
+// EditToolCall 实现 ToolCallEditor
+func (m *TokenBudget) EditToolCall(ctx context.Context, toolCall types.ToolCall, userID ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return editToolCall(m.userMessages[getUserID(userID...)], toolCall)
+}
+
+// Clear removes all messages for a user
+// Clear 删除用户的所有消息
+func (m *TokenBudget) Clear(userID ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	uid := getUserID(userID...)
+	delete(m.userMessages, uid)
+	delete(m.trimmed, uid)
+}
+
+// ClearAll removes all messages for all users
+// ClearAll 删除所有用户的所有消息
+func (m *TokenBudget) ClearAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.userMessages = make(map[string][]*types.Message)
+	m.trimmed = make(map[string]bool)
+}
+
+// Size returns the number of messages for a user
+// Size 返回用户的消息数量
+func (m *TokenBudget) Size(userID ...string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.userMessages[getUserID(userID...)])
+}
+
+// Tokens returns the tokens currently used by a user's history
+// Tokens 返回用户历史当前占用的 token 数
+func (m *TokenBudget) Tokens(userID ...string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	total := 0
+	for _, msg := range m.userMessages[getUserID(userID...)] {
+		total += CountMessageTokens(m.tokenizer, msg)
+	}
+	return total
+}
+
+// trimToBudget drops the oldest non-system units until the history fits in
+// maxTokens and reports whether the history is trimmed. A trimmed history,
+// now or by an earlier call (wasTrimmed), also loses the units left before
+// its first user message, because providers such as Anthropic require the
+// conversation to open with one; an untrimmed history is kept as is, so a
+// leading greeting survives. System messages and the most recent unit are
+// always kept, so the result may still exceed the budget when they alone do.
+// trimToBudget 丢弃最早的非系统单元直到历史符合预算，裁剪过的历史保证以用户消息开头
+func trimToBudget(messages []*types.Message, maxTokens int, tokenizer Tokenizer, wasTrimmed bool) ([]*types.Message, bool) {
+	total := 0
+	for _, msg := range messages {
+		total += CountMessageTokens(tokenizer, msg)
+	}
+
+	groups := groupMessages(messages)
+	dropped := make([]bool, len(groups))
+	trimmed := wasTrimmed
+	changed := false
+	for i := 0; i < len(groups)-1 && total > maxTokens; i++ {
+		if groups[i][0].Role == types.RoleSystem {
+			continue
+		}
+		for _, msg := range groups[i] {
+			total -= CountMessageTokens(tokenizer, msg)
+		}
+		dropped[i] = true
+		trimmed = true
+		changed = true
+	}
+	for i := 0; trimmed && i < len(groups)-1; i++ {
+		if dropped[i] || groups[i][0].Role == types.RoleSystem {
+			continue
+		}
+		if groups[i][0].Role == types.RoleUser {
+			break
+		}
+		dropped[i] = true
+		changed = true
+	}
+	if !changed {
+		return messages, trimmed
+	}
+
+	kept := make([]*types.Message, 0, len(messages))
+	for i, group := range groups {
+		if !dropped[i] {
+			kept = append(kept, group...)
+		}
+	}
+	return kept, trimmed
+}
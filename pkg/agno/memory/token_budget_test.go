@@ -0,0 +1,252 @@
+package memory
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// wordTokenizer counts one token per whitespace-separated word
+type wordTokenizer struct{}
+
+func (wordTokenizer) CountTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+func TestEstimateTokenizer_CountTokens(t *testing.T) {
+	tk := NewEstimateTokenizer()
+
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"你好", 2},
+		{"hi 你好", 3},
+	}
+	for _, tt := range tests {
+		if got := tk.CountTokens(tt.text); got != tt.want {
+			t.Errorf("CountTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCountMessageTokens_IncludesToolCalls(t *testing.T) {
+	plain := &types.Message{Role: types.RoleAssistant, Content: "ok"}
+	withCall := &types.Message{
+		Role:    types.RoleAssistant,
+		Content: "ok",
+		ToolCalls: []types.ToolCall{{
+			ID:       "call_1",
+			Type:     "function",
+			Function: types.ToolCallFunction{Name: "search", Arguments: `{"query":"a long search query"}`},
+		}},
+	}
+
+	tk := NewEstimateTokenizer()
+	if CountMessageTokens(tk, withCall) <= CountMessageTokens(tk, plain) {
+		t.Error("tool call arguments should be counted")
+	}
+	if CountMessageTokens(tk, nil) != 0 {
+		t.Error("nil message should count as zero tokens")
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{"gpt-4", 8192},
+		{"gpt-4o-mini", 128000},
+		{"openai/gpt-4-turbo-preview", 128000},
+		{"claude-3-5-sonnet-20241022", 200000},
+		{"llama3.1:8b", 131072},
+		{"unknown-model", DefaultContextWindow},
+	}
+	for _, tt := range tests {
+		if got := ContextWindow(tt.model); got != tt.want {
+			t.Errorf("ContextWindow(%q) = %d, want %d", tt.model, got, tt.want)
+		}
+	}
+
+	RegisterContextWindow("my-finetune", 4000)
+	if got := ContextWindow("My-Finetune-v2"); got != 4000 {
+		t.Errorf("registered window = %d, want 4000", got)
+	}
+}
+
+func TestNewTokenBudget_FromModel(t *testing.T) {
+	mem := NewTokenBudget(TokenBudgetConfig{ModelID: "gpt-4"})
+	if got, want := mem.MaxTokens(), 8192-2048; got != want {
+		t.Errorf("MaxTokens() = %d, want %d", got, want)
+	}
+
+	mem = NewTokenBudget(TokenBudgetConfig{ModelID: "gpt-4o", ReserveTokens: 1000})
+	if got, want := mem.MaxTokens(), 127000; got != want {
+		t.Errorf("MaxTokens() = %d, want %d", got, want)
+	}
+}
+
+func TestTokenBudget_TrimsOldestAndKeepsSystem(t *testing.T) {
+	// Each message costs 4 overhead + its word count
+	mem := NewTokenBudget(TokenBudgetConfig{MaxTokens: 20, Tokenizer: wordTokenizer{}})
+
+	mem.Add(types.NewSystemMessage("be brief"))     // 6
+	mem.Add(types.NewUserMessage("first question")) // 6
+	mem.Add(types.NewAssistantMessage("first"))     // 5
+	mem.Add(types.NewUserMessage("second"))         // 5 -> 22, drop "first question" and the orphaned reply
+
+	messages := mem.GetMessages()
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	if messages[0].Role != types.RoleSystem {
+		t.Error("system message should be kept")
+	}
+	if messages[1].Content != "second" {
+		t.Errorf("unexpected history: %q", messages[1].Content)
+	}
+	if got := mem.Tokens(); got > mem.MaxTokens() {
+		t.Errorf("Tokens() = %d exceeds budget %d", got, mem.MaxTokens())
+	}
+}
+
+func TestTokenBudget_KeepsToolCallGroupsTogether(t *testing.T) {
+	mem := NewTokenBudget(TokenBudgetConfig{MaxTokens: 30, Tokenizer: wordTokenizer{}})
+
+	mem.Add(types.NewUserMessage("look it up"))
+	mem.Add(&types.Message{
+		Role: types.RoleAssistant,
+		ToolCalls: []types.ToolCall{
+			{ID: "a", Function: types.ToolCallFunction{Name: "search", Arguments: "{}"}},
+			{ID: "b", Function: types.ToolCallFunction{Name: "search", Arguments: "{}"}},
+		},
+	})
+	mem.Add(types.NewToolMessage("a", "result a"))
+	mem.Add(types.NewToolMessage("b", "result b"))
+	mem.Add(types.NewAssistantMessage("done"))
+	mem.Add(types.NewUserMessage("a much longer follow up question that uses many tokens"))
+
+	messages := mem.GetMessages()
+	for i, msg := range messages {
+		if msg.Role == types.RoleTool && (i == 0 || messages[i-1].Role != types.RoleAssistant && messages[i-1].Role != types.RoleTool) {
+			t.Fatalf("tool result at %d was separated from its tool call", i)
+		}
+		if msg.Role == types.RoleAssistant && len(msg.ToolCalls) > 0 {
+			if i+2 >= len(messages) || messages[i+1].ToolCallID != "a" || messages[i+2].ToolCallID != "b" {
+				t.Fatalf("tool call at %d lost its results", i)
+			}
+		}
+	}
+	if messages[len(messages)-1].Role != types.RoleUser {
+		t.Error("most recent message should be kept")
+	}
+}
+
+func TestTokenBudget_StartsWithUserMessage(t *testing.T) {
+	mem := NewTokenBudget(TokenBudgetConfig{MaxTokens: 40, Tokenizer: wordTokenizer{}})
+
+	mem.Add(types.NewSystemMessage("be brief"))
+	mem.Add(types.NewUserMessage("a first question with quite a few words in it"))
+	mem.Add(&types.Message{
+		Role:      types.RoleAssistant,
+		ToolCalls: []types.ToolCall{{ID: "a", Function: types.ToolCallFunction{Name: "search", Arguments: "{}"}}},
+	})
+	mem.Add(types.NewToolMessage("a", "result a"))
+	mem.Add(types.NewAssistantMessage("done"))
+	mem.Add(types.NewUserMessage("next"))
+	mem.Add(types.NewAssistantMessage("ok"))
+
+	messages := mem.GetMessages()
+	if messages[0].Role != types.RoleSystem {
+		t.Fatal("system message should be kept")
+	}
+	if len(messages) < 2 || messages[1].Role != types.RoleUser {
+		t.Fatalf("history must open with a user message, got %s", messages[1].Role)
+	}
+	if messages[len(messages)-1].Content != "ok" {
+		t.Error("most recent message should be kept")
+	}
+}
+
+func TestTokenBudget_KeepsLeadingAssistantUnderBudget(t *testing.T) {
+	mem := NewTokenBudget(TokenBudgetConfig{MaxTokens: 100, Tokenizer: wordTokenizer{}})
+
+	mem.Add(types.NewSystemMessage("be brief"))
+	mem.Add(types.NewAssistantMessage("hello, how can I help?"))
+	mem.Add(types.NewUserMessage("a question"))
+	mem.Add(types.NewUserMessage("another question"))
+
+	messages := mem.GetMessages()
+	if len(messages) != 4 {
+		t.Fatalf("expected all 4 messages under budget, got %d", len(messages))
+	}
+	if messages[1].Role != types.RoleAssistant {
+		t.Fatalf("leading assistant greeting should be kept, got %s", messages[1].Role)
+	}
+}
+
+func TestTokenBudget_KeepsLatestMessageOverBudget(t *testing.T) {
+	mem := NewTokenBudget(TokenBudgetConfig{MaxTokens: 5, Tokenizer: wordTokenizer{}})
+
+	mem.Add(types.NewUserMessage("short"))
+	mem.Add(types.NewUserMessage("this message alone is over the budget"))
+
+	messages := mem.GetMessages()
+	if len(messages) != 1 || !strings.HasPrefix(messages[0].Content, "this message") {
+		t.Fatalf("expected only the latest message, got %d messages", len(messages))
+	}
+}
+
+func TestTokenBudget_MultiUser(t *testing.T) {
+	mem := NewTokenBudget(TokenBudgetConfig{MaxTokens: 100})
+
+	mem.Add(types.NewUserMessage("hello"), "alice")
+	mem.Add(types.NewUserMessage("hi"), "bob")
+	mem.Add(types.NewUserMessage("again"), "bob")
+
+	if mem.Size("alice") != 1 || mem.Size("bob") != 2 {
+		t.Fatalf("unexpected sizes: alice=%d bob=%d", mem.Size("alice"), mem.Size("bob"))
+	}
+
+	mem.Clear("bob")
+	if mem.Size("bob") != 0 || mem.Size("alice") != 1 {
+		t.Error("Clear should only affect the given user")
+	}
+
+	mem.ClearAll()
+	if mem.Size("alice") != 0 {
+		t.Error("ClearAll should remove every user's messages")
+	}
+}
+
+var _ Memory = (*TokenBudget)(nil)
+
+func TestTrimMessages(t *testing.T) {
+	messages := []*types.Message{
+		types.NewSystemMessage("system"),
+		types.NewUserMessage("old"),
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "c1"}}},
+		types.NewToolMessage("c1", "result"),
+		types.NewAssistantMessage("answer"),
+		types.NewUserMessage("latest"),
+	}
+
+	got := TrimMessages(messages, 4)
+	if len(got) != 3 || got[0].Role != types.RoleSystem || got[1].Content != "answer" || got[2].Content != "latest" {
+		t.Fatalf("unexpected window: %+v", got)
+	}
+
+	got = TrimMessages(messages, 5)
+	if len(got) != 5 || len(got[1].ToolCalls) != 1 || got[2].Role != types.RoleTool {
+		t.Fatalf("tool call group should be kept whole: %+v", got)
+	}
+
+	if len(TrimMessages(messages, 0)) != len(messages) {
+		t.Error("non-positive limit should keep every message")
+	}
+}
@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// Tokenizer counts the tokens a piece of text occupies in a model's context.
+// Implementations can wrap an exact tokenizer (e.g. tiktoken) for a model.
+// Tokenizer 统计文本在模型上下文中占用的 token 数，可替换为精确的分词器
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface
+// TokenizerFunc 将函数适配为 Tokenizer
+type TokenizerFunc func(text string) int
+
+// CountTokens calls f(text)
+func (f TokenizerFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+const (
+	// defaultCharsPerToken is the average number of ASCII characters per token
+	// for English text and code with BPE tokenizers
+	defaultCharsPerToken = 4.0
+
+	// messageOverheadTokens approximates the role and separator tokens that
+	// chat formats add around every message
+	messageOverheadTokens = 4
+
+	// toolCallOverheadTokens approximates the framing of one tool call
+	toolCallOverheadTokens = 3
+
+	// mediaPartTokens approximates an image, audio or file part; providers
+	// bill these by size, so this is a floor rather than an estimate
+	mediaPartTokens = 85
+)
+
+// EstimateTokenizer is a dependency-free Tokenizer that estimates token counts
+// from character counts. ASCII text counts as CharsPerToken characters per
+// token; every other rune (CJK, emoji, accented letters) counts as one token,
+// which errs on the side of overestimating.
+// EstimateTokenizer 基于字符数估算 token：ASCII 按 CharsPerToken 个字符计一个 token，
+// 其他字符（中日韩文字、表情等）每个计一个 token
+type EstimateTokenizer struct {
+	// CharsPerToken is the number of ASCII characters per token (default: 4)
+	CharsPerToken float64
+}
+
+// NewEstimateTokenizer creates an estimator with the default ratio
+// NewEstimateTokenizer 创建使用默认比例的估算分词器
+func NewEstimateTokenizer() *EstimateTokenizer {
+	return &EstimateTokenizer{CharsPerToken: defaultCharsPerToken}
+}
+
+// CountTokens estimates the number of tokens in text
+// CountTokens 估算文本的 token 数
+func (e *EstimateTokenizer) CountTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	ratio := e.CharsPerToken
+	if ratio <= 0 {
+		ratio = defaultCharsPerToken
+	}
+
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return int(math.Ceil(float64(ascii)/ratio)) + other
+}
+
+// CountMessageTokens returns the tokens a message occupies, including its
+// content, parts, name, tool calls and the per-message formatting overhead
+// CountMessageTokens 返回消息占用的 token 数，包括内容、内容片段、名称、工具调用及格式开销
+func CountMessageTokens(tokenizer Tokenizer, msg *types.Message) int {
+	if msg == nil {
+		return 0
+	}
+	if tokenizer == nil {
+		tokenizer = NewEstimateTokenizer()
+	}
+
+	tokens := messageOverheadTokens + tokenizer.CountTokens(msg.Content)
+	if msg.Name != "" {
+		tokens += tokenizer.CountTokens(msg.Name)
+	}
+	if msg.ToolCallID != "" {
+		tokens += tokenizer.CountTokens(msg.ToolCallID)
+	}
+	for _, part := range msg.Parts {
+		if part.Type == types.ContentPartText {
+			tokens += tokenizer.CountTokens(part.Text)
+		} else {
+			tokens += mediaPartTokens
+		}
+	}
+	for _, call := range msg.ToolCalls {
+		tokens += toolCallOverheadTokens +
+			tokenizer.CountTokens(call.ID) +
+			tokenizer.CountTokens(call.Function.Name) +
+			tokenizer.CountTokens(call.Function.Arguments)
+	}
+	return tokens
+}
+
+// DefaultContextWindow is used for models missing from the context window table
+// DefaultContextWindow 是未登记模型使用的默认上下文窗口
+const DefaultContextWindow = 8192
+
+var (
+	contextWindowsMu sync.RWMutex
+
+	// contextWindows maps model ID prefixes to context window sizes in tokens.
+	// The longest matching prefix wins.
+	contextWindows = map[string]int{
+		"gpt-3.5-turbo":   16385,
+		"gpt-4":           8192,
+		"gpt-4-32k":       32768,
+		"gpt-4-turbo":     128000,
+		"gpt-4o":          128000,
+		"gpt-4.1":         1047576,
+		"gpt-5":           400000,
+		"o1":              200000,
+		"o3":              200000,
+		"o4-mini":         200000,
+		"claude-2":        100000,
+		"claude-3":        200000,
+		"claude-opus-4":   200000,
+		"claude-sonnet-4": 200000,
+		"gemini-1.0":      32760,
+		"gemini-1.5":      1048576,
+		"gemini-2":        1048576,
+		"deepseek":        64000,
+		"glm-4":           128000,
+		"qwen":            32768,
+		"llama2":          4096,
+		"llama3":          8192,
+		"llama3.1":        131072,
+		"llama3.2":        131072,
+		"mistral":         32768,
+		"command-r":       128000,
+		"kimi":            131072,
+		"moonshot-v1-8k":  8192,
+		"moonshot-v1-32k": 32768,
+	}
+)
+
+// ContextWindow returns the context window size in tokens for a model ID.
+// Provider prefixes such as "openai/" are ignored and matching is by the
+// longest known prefix; unknown models get DefaultContextWindow.
+// ContextWindow 返回模型的上下文窗口大小（按最长前缀匹配，未知模型返回默认值）
+func ContextWindow(modelID string) int {
+	id := strings.ToLower(strings.TrimSpace(modelID))
+	if idx := strings.LastIndex(id, "/"); idx >= 0 {
+		id = id[idx+1:]
+	}
+
+	contextWindowsMu.RLock()
+	defer contextWindowsMu.RUnlock()
+
+	prefixes := make([]string, 0, len(contextWindows))
+	for prefix := range contextWindows {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(id, prefix) {
+			return contextWindows[prefix]
+		}
+	}
+	return DefaultContextWindow
+}
+
+// RegisterContextWindow sets the context window for model IDs starting with prefix
+// RegisterContextWindow 为指定前缀的模型登记上下文窗口大小
+func RegisterContextWindow(prefix string, tokens int) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" || tokens <= 0 {
+		return
+	}
+	contextWindowsMu.Lock()
+	defer contextWindowsMu.Unlock()
+	contextWindows[prefix] = tokens
+}
@@ -0,0 +1,524 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+const (
+	defaultUserMemoryLimit   = 10
+	defaultUserMemoryTimeout = 30 * time.Second
+
+	// UserMemoryContextPrefix starts the system message that carries the
+	// memories of the current user
+	// UserMemoryContextPrefix 是用户记忆系统消息的开头
+	UserMemoryContextPrefix = "Things you remember about the user from earlier sessions:\n"
+
+	defaultUserMemoryPrompt = "You manage long-term memories about a user. Memories are short, self-contained facts " +
+		"that stay useful across conversations: the user's name, preferences, projects, goals and relationships. " +
+		"Do not store small talk, one-off requests or facts about the assistant.\n" +
+		"Compare the conversation with the existing memories and reply with a JSON object of the form " +
+		`{"operations":[{"action":"add","memory":"...","topics":["..."]},` +
+		`{"action":"update","id":"...","memory":"...","topics":["..."]},{"action":"delete","id":"..."}]}. ` +
+		"Update a memory when the conversation refines or corrects it and delete it when the user retracts it. " +
+		`Reply with {"operations":[]} when nothing should change.`
+)
+
+// UserMemory is a durable fact about a user, such as a preference or a name,
+// that outlives a single conversation
+// UserMemory 是关于用户的持久事实（如偏好、姓名），跨会话保留
+type UserMemory struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Memory    string    `json:"memory"`
+	Topics    []string  `json:"topics,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserMemoryStore persists user memories. List returns them in insertion
+// order and Get returns nil without an error when the memory does not exist.
+// UserMemoryStore 持久化用户记忆；List 按插入顺序返回，记忆不存在时 Get 返回 nil 且无错误
+type UserMemoryStore interface {
+	Upsert(ctx context.Context, memory *UserMemory) error
+	Get(ctx context.Context, userID, id string) (*UserMemory, error)
+	List(ctx context.Context, userID string) ([]*UserMemory, error)
+	Delete(ctx context.Context, userID, id string) error
+	DeleteAll(ctx context.Context, userID string) error
+}
+
+// InMemoryUserMemoryStore keeps user memories in process memory
+// InMemoryUserMemoryStore 在进程内存中保存用户记忆
+type InMemoryUserMemoryStore struct {
+	users map[string][]*UserMemory
+	mu    sync.RWMutex
+}
+
+// NewInMemoryUserMemoryStore creates an empty in-memory store
+// NewInMemoryUserMemoryStore 创建空的内存存储
+func NewInMemoryUserMemoryStore() *InMemoryUserMemoryStore {
+	return &InMemoryUserMemoryStore{users: make(map[string][]*UserMemory)}
+}
+
+// Upsert creates or replaces a memory
+func (s *InMemoryUserMemoryStore) Upsert(ctx context.Context, memory *UserMemory) error {
+	if memory == nil || memory.ID == "" {
+		return fmt.Errorf("memory id is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uid := ResolveUserID(memory.UserID)
+	for i, existing := range s.users[uid] {
+		if existing.ID == memory.ID {
+			s.users[uid][i] = copyUserMemory(memory)
+			return nil
+		}
+	}
+	s.users[uid] = append(s.users[uid], copyUserMemory(memory))
+	return nil
+}
+
+// Get returns a memory of the user, or nil if it does not exist
+func (s *InMemoryUserMemoryStore) Get(ctx context.Context, userID, id string) (*UserMemory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, memory := range s.users[ResolveUserID(userID)] {
+		if memory.ID == id {
+			return copyUserMemory(memory), nil
+		}
+	}
+	return nil, nil
+}
+
+// List returns the memories of the user in insertion order
+func (s *InMemoryUserMemoryStore) List(ctx context.Context, userID string) ([]*UserMemory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored := s.users[ResolveUserID(userID)]
+	memories := make([]*UserMemory, 0, len(stored))
+	for _, memory := range stored {
+		memories = append(memories, copyUserMemory(memory))
+	}
+	return memories, nil
+}
+
+// Delete removes a memory of the user
+func (s *InMemoryUserMemoryStore) Delete(ctx context.Context, userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uid := ResolveUserID(userID)
+	kept := s.users[uid][:0]
+	for _, memory := range s.users[uid] {
+		if memory.ID != id {
+			kept = append(kept, memory)
+		}
+	}
+	s.users[uid] = kept
+	return nil
+}
+
+// DeleteAll removes every memory of the user
+func (s *InMemoryUserMemoryStore) DeleteAll(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.users, ResolveUserID(userID))
+	return nil
+}
+
+func copyUserMemory(memory *UserMemory) *UserMemory {
+	c := *memory
+	c.Topics = append([]string(nil), memory.Topics...)
+	return &c
+}
+
+// UserMemoryManagerConfig configures a UserMemoryManager
+// UserMemoryManagerConfig 配置 UserMemoryManager
+type UserMemoryManagerConfig struct {
+	// Model extracts memories from conversations (required)
+	// Model 用于从对话中提取记忆（必填）
+	Model models.Model
+
+	// Store persists the memories (default: InMemoryUserMemoryStore)
+	// Store 用于持久化记忆（默认：内存存储）
+	Store UserMemoryStore
+
+	// VectorDB optionally indexes the memories so Relevant retrieves them by
+	// similarity to the input. Documents carry a "user_id" metadata field.
+	// VectorDB 可选，用于按输入相似度检索记忆
+	VectorDB vectordb.VectorDB
+
+	// Limit is the maximum number of memories injected into a run (default: 10)
+	// Limit 是注入单次运行的最大记忆数（默认：10）
+	Limit int
+
+	// Prompt is the system prompt used for extraction
+	// Prompt 是提取记忆时使用的系统提示词
+	Prompt string
+
+	// Timeout bounds each extraction call (default: 30s)
+	Timeout time.Duration
+
+	// Logger receives index failures (default: slog.Default())
+	Logger *slog.Logger
+}
+
+// UserMemoryManager maintains durable per-user memories. After a run,
+// Extract asks the model which memories to add, update or delete; before a
+// run, Relevant and SystemMessage provide the memories for the system context.
+// UserMemoryManager 维护每个用户的长期记忆：运行后由模型提取、更新或删除记忆，
+// 运行前检索相关记忆注入系统上下文
+type UserMemoryManager struct {
+	model    models.Model
+	store    UserMemoryStore
+	vectorDB vectordb.VectorDB
+	config   UserMemoryManagerConfig
+	logger   *slog.Logger
+}
+
+// NewUserMemoryManager creates a user memory manager
+// NewUserMemoryManager 创建用户记忆管理器
+func NewUserMemoryManager(config UserMemoryManagerConfig) (*UserMemoryManager, error) {
+	if config.Model == nil {
+		return nil, types.NewInvalidConfigError("user memory model is required", nil)
+	}
+	if config.Store == nil {
+		config.Store = NewInMemoryUserMemoryStore()
+	}
+	if config.Limit <= 0 {
+		config.Limit = defaultUserMemoryLimit
+	}
+	if config.Prompt == "" {
+		config.Prompt = defaultUserMemoryPrompt
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultUserMemoryTimeout
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
+	return &UserMemoryManager{
+		model:    config.Model,
+		store:    config.Store,
+		vectorDB: config.VectorDB,
+		config:   config,
+		logger:   config.Logger,
+	}, nil
+}
+
+// Store returns the underlying store
+// Store 返回底层存储
+func (m *UserMemoryManager) Store() UserMemoryStore {
+	return m.store
+}
+
+// Memories returns every memory of the user in insertion order
+// Memories 返回用户的全部记忆
+func (m *UserMemoryManager) Memories(ctx context.Context, userID string) ([]*UserMemory, error) {
+	return m.store.List(ctx, ResolveUserID(userID))
+}
+
+// AddMemory stores a memory for the user directly, without the model
+// AddMemory 直接为用户添加一条记忆（不经过模型）
+func (m *UserMemoryManager) AddMemory(ctx context.Context, userID, text string, topics ...string) (*UserMemory, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, types.NewInvalidInputError("memory cannot be empty", nil)
+	}
+	now := time.Now().UTC()
+	memory := &UserMemory{
+		ID:        "mem-" + uuid.NewString(),
+		UserID:    ResolveUserID(userID),
+		Memory:    text,
+		Topics:    topics,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := m.store.Upsert(ctx, memory); err != nil {
+		return nil, err
+	}
+	m.index(ctx, memory, false)
+	return memory, nil
+}
+
+// DeleteMemory removes one memory of the user
+// DeleteMemory 删除用户的一条记忆
+func (m *UserMemoryManager) DeleteMemory(ctx context.Context, userID, id string) error {
+	if err := m.store.Delete(ctx, ResolveUserID(userID), id); err != nil {
+		return err
+	}
+	m.unindex(ctx, []string{id})
+	return nil
+}
+
+// ClearMemories removes every memory of the user
+// ClearMemories 删除用户的全部记忆
+func (m *UserMemoryManager) ClearMemories(ctx context.Context, userID string) error {
+	uid := ResolveUserID(userID)
+	memories, err := m.store.List(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if err := m.store.DeleteAll(ctx, uid); err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(memories))
+	for _, memory := range memories {
+		ids = append(ids, memory.ID)
+	}
+	m.unindex(ctx, ids)
+	return nil
+}
+
+// Relevant returns up to Limit memories of the user for the query. With a
+// VectorDB the closest memories are returned; otherwise, or when the vector
+// search fails, the most recently updated ones.
+// Relevant 返回与查询相关的至多 Limit 条记忆（有向量库时按相似度，否则按最近更新）
+func (m *UserMemoryManager) Relevant(ctx context.Context, userID, query string) ([]*UserMemory, error) {
+	uid := ResolveUserID(userID)
+
+	if m.vectorDB != nil && strings.TrimSpace(query) != "" {
+		memories, err := m.search(ctx, uid, query)
+		if err == nil {
+			return memories, nil
+		}
+		m.logger.Warn("user memory vector search failed", "user_id", uid, "error", err)
+	}
+
+	memories, err := m.store.List(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(memories, func(i, j int) bool {
+		return memories[i].UpdatedAt.After(memories[j].UpdatedAt)
+	})
+	if len(memories) > m.config.Limit {
+		memories = memories[:m.config.Limit]
+	}
+	return memories, nil
+}
+
+func (m *UserMemoryManager) search(ctx context.Context, uid, query string) ([]*UserMemory, error) {
+	results, err := m.vectorDB.Query(ctx, query, m.config.Limit, map[string]interface{}{"user_id": uid})
+	if err != nil {
+		return nil, err
+	}
+	memories := make([]*UserMemory, 0, len(results))
+	for _, result := range results {
+		// The store is authoritative; the index may lag behind it.
+		memory, err := m.store.Get(ctx, uid, result.ID)
+		if err != nil {
+			return nil, err
+		}
+		if memory != nil {
+			memories = append(memories, memory)
+		}
+	}
+	return memories, nil
+}
+
+// SystemMessage renders memories as a system message, or returns nil when
+// there are none
+// SystemMessage 将记忆渲染为系统消息，没有记忆时返回 nil
+func (m *UserMemoryManager) SystemMessage(memories []*UserMemory) *types.Message {
+	if len(memories) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString(UserMemoryContextPrefix)
+	for _, memory := range memories {
+		fmt.Fprintf(&b, "- %s\n", memory.Memory)
+	}
+	msg := types.NewSystemMessage(strings.TrimRight(b.String(), "\n"))
+	msg.ID = "user-memories"
+	return msg
+}
+
+// userMemoryOperation is one change requested by the extraction model
+type userMemoryOperation struct {
+	Action string   `json:"action"`
+	ID     string   `json:"id,omitempty"`
+	Memory string   `json:"memory,omitempty"`
+	Topics []string `json:"topics,omitempty"`
+}
+
+// Extract asks the model which memories of the user to add, update or delete
+// given the messages of a run, and applies the changes. Operations that
+// reference unknown memories are skipped.
+// Extract 根据一次运行的消息让模型决定新增、更新或删除哪些记忆，并应用这些变更
+func (m *UserMemoryManager) Extract(ctx context.Context, userID string, messages []*types.Message) error {
+	uid := ResolveUserID(userID)
+
+	conversation := make([]*types.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg != nil && msg.Role != types.RoleSystem {
+			conversation = append(conversation, msg)
+		}
+	}
+	if len(conversation) == 0 {
+		return nil
+	}
+
+	existing, err := m.store.List(ctx, uid)
+	if err != nil {
+		return err
+	}
+
+	operations, err := m.generate(ctx, existing, conversation)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]*UserMemory, len(existing))
+	for _, memory := range existing {
+		known[memory.ID] = memory
+	}
+
+	now := time.Now().UTC()
+	for _, op := range operations {
+		switch strings.ToLower(op.Action) {
+		case "add":
+			if strings.TrimSpace(op.Memory) == "" {
+				continue
+			}
+			memory := &UserMemory{
+				ID:        "mem-" + uuid.NewString(),
+				UserID:    uid,
+				Memory:    strings.TrimSpace(op.Memory),
+				Topics:    op.Topics,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if err := m.store.Upsert(ctx, memory); err != nil {
+				return err
+			}
+			m.index(ctx, memory, false)
+		case "update":
+			memory, ok := known[op.ID]
+			if !ok || strings.TrimSpace(op.Memory) == "" {
+				m.logger.Debug("skipping user memory update", "user_id", uid, "id", op.ID)
+				continue
+			}
+			memory.Memory = strings.TrimSpace(op.Memory)
+			if op.Topics != nil {
+				memory.Topics = op.Topics
+			}
+			memory.UpdatedAt = now
+			if err := m.store.Upsert(ctx, memory); err != nil {
+				return err
+			}
+			m.index(ctx, memory, true)
+		case "delete":
+			if _, ok := known[op.ID]; !ok {
+				m.logger.Debug("skipping user memory delete", "user_id", uid, "id", op.ID)
+				continue
+			}
+			if err := m.store.Delete(ctx, uid, op.ID); err != nil {
+				return err
+			}
+			delete(known, op.ID)
+			m.unindex(ctx, []string{op.ID})
+		default:
+			m.logger.Debug("unknown user memory operation", "action", op.Action)
+		}
+	}
+	return nil
+}
+
+func (m *UserMemoryManager) generate(ctx context.Context, existing []*UserMemory, conversation []*types.Message) ([]userMemoryOperation, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	var prompt strings.Builder
+	prompt.WriteString("Existing memories:\n")
+	if len(existing) == 0 {
+		prompt.WriteString("(none)\n")
+	}
+	for _, memory := range existing {
+		fmt.Fprintf(&prompt, "[%s] %s\n", memory.ID, memory.Memory)
+	}
+	prompt.WriteString("\nConversation:\n")
+	prompt.WriteString(formatTranscript(conversation))
+
+	resp, err := m.model.Invoke(ctx, &models.InvokeRequest{
+		Messages: []*types.Message{
+			types.NewSystemMessage(m.config.Prompt),
+			types.NewUserMessage(prompt.String()),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseUserMemoryOperations(resp.Content)
+}
+
+// parseUserMemoryOperations decodes the model reply, tolerating Markdown code
+// fences and surrounding prose
+func parseUserMemoryOperations(content string) ([]userMemoryOperation, error) {
+	text := strings.TrimSpace(content)
+	start := strings.IndexByte(text, '{')
+	end := strings.LastIndexByte(text, '}')
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("user memory model returned no JSON object")
+	}
+
+	var reply struct {
+		Operations []userMemoryOperation `json:"operations"`
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &reply); err != nil {
+		return nil, fmt.Errorf("failed to decode user memory operations: %w", err)
+	}
+	return reply.Operations, nil
+}
+
+// index mirrors a memory into the VectorDB. Index failures are logged; the
+// store stays authoritative and Relevant falls back to it.
+func (m *UserMemoryManager) index(ctx context.Context, memory *UserMemory, update bool) {
+	if m.vectorDB == nil {
+		return
+	}
+	doc := vectordb.Document{
+		ID:       memory.ID,
+		Content:  memory.Memory,
+		Metadata: map[string]interface{}{"user_id": memory.UserID},
+	}
+	var err error
+	if update {
+		err = m.vectorDB.Update(ctx, []vectordb.Document{doc})
+	} else {
+		err = m.vectorDB.Add(ctx, []vectordb.Document{doc})
+	}
+	if err != nil {
+		m.logger.Warn("failed to index user memory", "id", memory.ID, "error", err)
+	}
+}
+
+func (m *UserMemoryManager) unindex(ctx context.Context, ids []string) {
+	if m.vectorDB == nil || len(ids) == 0 {
+		return
+	}
+	if err := m.vectorDB.Delete(ctx, ids); err != nil {
+		m.logger.Warn("failed to remove user memories from index", "count", len(ids), "error", err)
+	}
+}
+
+var _ UserMemoryStore = (*InMemoryUserMemoryStore)(nil)
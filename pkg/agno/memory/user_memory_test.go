@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+type mockExtractModel struct {
+	models.BaseModel
+	replies []string
+	prompts []string
+}
+
+func (m *mockExtractModel) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+	m.prompts = append(m.prompts, req.Messages[len(req.Messages)-1].Content)
+	reply := `{"operations":[]}`
+	if len(m.replies) > 0 {
+		reply, m.replies = m.replies[0], m.replies[1:]
+	}
+	return &types.ModelResponse{Content: reply}, nil
+}
+
+func (m *mockExtractModel) InvokeStream(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
+	ch := make(chan types.ResponseChunk)
+	close(ch)
+	return ch, nil
+}
+
+// fakeVectorDB records indexed documents and returns the ones whose content
+// contains the query.
+type fakeVectorDB struct {
+	vectordb.VectorDB
+	docs    map[string]vectordb.Document
+	filters []map[string]interface{}
+}
+
+func newFakeVectorDB() *fakeVectorDB {
+	return &fakeVectorDB{docs: make(map[string]vectordb.Document)}
+}
+
+func (f *fakeVectorDB) Add(ctx context.Context, docs []vectordb.Document) error {
+	for _, doc := range docs {
+		f.docs[doc.ID] = doc
+	}
+	return nil
+}
+
+func (f *fakeVectorDB) Update(ctx context.Context, docs []vectordb.Document) error {
+	return f.Add(ctx, docs)
+}
+
+func (f *fakeVectorDB) Delete(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		delete(f.docs, id)
+	}
+	return nil
+}
+
+func (f *fakeVectorDB) Query(ctx context.Context, query string, limit int, filter map[string]interface{}) ([]vectordb.SearchResult, error) {
+	f.filters = append(f.filters, filter)
+	var results []vectordb.SearchResult
+	for _, doc := range f.docs {
+		if doc.Metadata["user_id"] == filter["user_id"] && strings.Contains(doc.Content, query) {
+			results = append(results, vectordb.SearchResult{ID: doc.ID, Content: doc.Content})
+		}
+	}
+	return results, nil
+}
+
+func TestNewUserMemoryManager_RequiresModel(t *testing.T) {
+	if _, err := NewUserMemoryManager(UserMemoryManagerConfig{}); err == nil {
+		t.Fatal("expected error without a model")
+	}
+}
+
+func TestUserMemoryManager_ExtractAddsUpdatesAndDeletes(t *testing.T) {
+	model := &mockExtractModel{replies: []string{
+		"```json\n" + `{"operations":[{"action":"add","memory":"Name is Ada","topics":["name"]},` +
+			`{"action":"add","memory":"Likes Python"}]}` + "\n```",
+	}}
+	manager, err := NewUserMemoryManager(UserMemoryManagerConfig{Model: model})
+	if err != nil {
+		t.Fatalf("NewUserMemoryManager() error = %v", err)
+	}
+	ctx := context.Background()
+
+	turn := []*types.Message{types.NewUserMessage("I'm Ada and I like Python"), types.NewAssistantMessage("Hi Ada")}
+	if err := manager.Extract(ctx, "ada", turn); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	memories, _ := manager.Memories(ctx, "ada")
+	if len(memories) != 2 || memories[0].Memory != "Name is Ada" || memories[0].Topics[0] != "name" {
+		t.Fatalf("unexpected memories after add: %+v", memories)
+	}
+	if !strings.Contains(model.prompts[0], "(none)") || !strings.Contains(model.prompts[0], "user: I'm Ada") {
+		t.Fatalf("prompt should list no memories and the conversation, got %q", model.prompts[0])
+	}
+
+	name, python := memories[0], memories[1]
+	model.replies = []string{`Sure: {"operations":[` +
+		`{"action":"update","id":"` + python.ID + `","memory":"Prefers Go over Python"},` +
+		`{"action":"delete","id":"` + name.ID + `"},` +
+		`{"action":"delete","id":"mem-unknown"}]}`}
+	turn = []*types.Message{types.NewUserMessage("Forget my name, and I switched to Go")}
+	if err := manager.Extract(ctx, "ada", turn); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if !strings.Contains(model.prompts[1], "["+python.ID+"] Likes Python") {
+		t.Fatalf("prompt should list existing memories with IDs, got %q", model.prompts[1])
+	}
+
+	memories, _ = manager.Memories(ctx, "ada")
+	if len(memories) != 1 || memories[0].ID != python.ID || memories[0].Memory != "Prefers Go over Python" {
+		t.Fatalf("unexpected memories after update: %+v", memories)
+	}
+	if others, _ := manager.Memories(ctx, "bob"); len(others) != 0 {
+		t.Fatalf("memories leaked to another user: %+v", others)
+	}
+}
+
+func TestUserMemoryManager_ExtractRejectsInvalidReply(t *testing.T) {
+	model := &mockExtractModel{replies: []string{"nothing to remember"}}
+	manager, _ := NewUserMemoryManager(UserMemoryManagerConfig{Model: model})
+
+	err := manager.Extract(context.Background(), "ada", []*types.Message{types.NewUserMessage("hi")})
+	if err == nil {
+		t.Fatal("expected error for a reply without JSON")
+	}
+}
+
+func TestUserMemoryManager_RelevantAndSystemMessage(t *testing.T) {
+	manager, _ := NewUserMemoryManager(UserMemoryManagerConfig{Model: &mockExtractModel{}, Limit: 2})
+	ctx := context.Background()
+
+	if msg := manager.SystemMessage(nil); msg != nil {
+		t.Fatalf("expected no message without memories, got %+v", msg)
+	}
+
+	for _, text := range []string{"Lives in Paris", "Has a cat", "Works on compilers"} {
+		if _, err := manager.AddMemory(ctx, "ada", text); err != nil {
+			t.Fatalf("AddMemory() error = %v", err)
+		}
+	}
+	relevant, err := manager.Relevant(ctx, "ada", "anything")
+	if err != nil {
+		t.Fatalf("Relevant() error = %v", err)
+	}
+	if len(relevant) != 2 {
+		t.Fatalf("expected Limit memories, got %d", len(relevant))
+	}
+
+	msg := manager.SystemMessage(relevant)
+	if msg.Role != types.RoleSystem || !strings.HasPrefix(msg.Content, UserMemoryContextPrefix) {
+		t.Fatalf("unexpected system message: %+v", msg)
+	}
+	if !strings.Contains(msg.Content, "- "+relevant[0].Memory) {
+		t.Fatalf("system message should list the memories, got %q", msg.Content)
+	}
+}
+
+func TestUserMemoryManager_VectorRetrieval(t *testing.T) {
+	index := newFakeVectorDB()
+	manager, _ := NewUserMemoryManager(UserMemoryManagerConfig{Model: &mockExtractModel{}, VectorDB: index})
+	ctx := context.Background()
+
+	cat, _ := manager.AddMemory(ctx, "ada", "Has a cat named Turing")
+	manager.AddMemory(ctx, "ada", "Lives in Paris")
+	manager.AddMemory(ctx, "bob", "Has a cat named Pascal")
+
+	relevant, err := manager.Relevant(ctx, "ada", "cat")
+	if err != nil {
+		t.Fatalf("Relevant() error = %v", err)
+	}
+	if len(relevant) != 1 || relevant[0].ID != cat.ID {
+		t.Fatalf("expected only ada's cat memory, got %+v", relevant)
+	}
+	if index.filters[0]["user_id"] != "ada" {
+		t.Fatalf("expected a user_id filter, got %+v", index.filters[0])
+	}
+
+	if err := manager.ClearMemories(ctx, "ada"); err != nil {
+		t.Fatalf("ClearMemories() error = %v", err)
+	}
+	if len(index.docs) != 1 {
+		t.Fatalf("expected only bob's memory to stay indexed, got %d", len(index.docs))
+	}
+}
@@ -134,7 +134,7 @@ func (a *Anthropic) Invoke(ctx context.Context, req *models.InvokeRequest) (*typ
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, types.NewAPIError(fmt.Sprintf("API error: %s", string(body)), nil)
+		return nil, types.NewAPIError(fmt.Sprintf("API error: %s", string(body)), models.NewHTTPStatusError(resp, body))
 	}
 
 	var claudeResp ClaudeResponse
@@ -142,7 +142,9 @@ func (a *Anthropic) Invoke(ctx context.Context, req *models.InvokeRequest) (*typ
 		return nil, types.NewAPIError("failed to decode response", err)
 	}
 
-	return a.convertResponse(&claudeResp), nil
+	modelResp := a.convertResponse(&claudeResp)
+	applyStructuredOutput(req, modelResp)
+	return modelResp, nil
 }
 
 // InvokeStream calls the Anthropic API with streaming response
@@ -170,7 +172,7 @@ func (a *Anthropic) InvokeStream(ctx context.Context, req *models.InvokeRequest)
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, types.NewAPIError(fmt.Sprintf("API error: %s", string(body)), nil)
+		return nil, types.NewAPIError(fmt.Sprintf("API error: %s", string(body)), models.NewHTTPStatusError(resp, body))
 	}
 
 	chunks := make(chan types.ResponseChunk)
@@ -179,6 +181,11 @@ func (a *Anthropic) InvokeStream(ctx context.Context, req *models.InvokeRequest)
 		defer close(chunks)
 		defer resp.Body.Close()
 
+		// The forced output tool streams its JSON input as partial deltas of
+		// its tool_use block; those deltas are forwarded as content.
+		outputTool, structured := structuredOutputTool(req)
+		outputBlock := -1
+
 		decoder := json.NewDecoder(resp.Body)
 		for {
 			var event StreamEvent
@@ -192,7 +199,14 @@ func (a *Anthropic) InvokeStream(ctx context.Context, req *models.InvokeRequest)
 				return
 			}
 
+			if structured && event.Type == "content_block_start" && event.ContentBlock != nil &&
+				event.ContentBlock.Type == "tool_use" && event.ContentBlock.Name == outputTool {
+				outputBlock = event.Index
+			}
 			chunk := a.convertStreamEvent(&event)
+			if event.Type == "content_block_delta" && event.Delta.Type == "input_json_delta" && event.Index == outputBlock {
+				chunk.Content = event.Delta.PartialJSON
+			}
 			select {
 			case chunks <- chunk:
 				if chunk.Done {
@@ -334,6 +348,9 @@ func (a *Anthropic) buildClaudeRequest(req *models.InvokeRequest) *ClaudeRequest
 				Role:    string(msg.Role),
 				Content: msg.Content,
 			}
+			if msg.HasMedia() {
+				claudeMsg.Content = buildContentBlocks(msg)
+			}
 			claudeReq.Messages = append(claudeReq.Messages, claudeMsg)
 		case types.RoleTool:
 			// Handle tool results
@@ -345,6 +362,14 @@ func (a *Anthropic) buildClaudeRequest(req *models.InvokeRequest) *ClaudeRequest
 		}
 	}
 
+	// Claude has no JSON mode, so plain JSON output is requested via the prompt
+	if req.ResponseFormat != nil && req.ResponseFormat.Type == models.ResponseFormatJSONObject {
+		if systemPrompt != "" {
+			systemPrompt += "\n\n"
+		}
+		systemPrompt += jsonObjectInstruction
+	}
+
 	if systemPrompt != "" {
 		claudeReq.System = systemPrompt
 	}
@@ -361,9 +386,117 @@ func (a *Anthropic) buildClaudeRequest(req *models.InvokeRequest) *ClaudeRequest
 		}
 	}
 
+	// Structured output is implemented with a dedicated tool whose input
+	// schema is the requested schema. Without other tools the model is forced
+	// to call it; otherwise it must call some tool, and a call to the output
+	// tool is treated as the final answer.
+	if name, ok := structuredOutputTool(req); ok {
+		claudeReq.Tools = append(claudeReq.Tools, ClaudeTool{
+			Name:        name,
+			Description: structuredOutputDescription(req.ResponseFormat),
+			InputSchema: req.ResponseFormat.Schema,
+		})
+		if len(req.Tools) == 0 {
+			claudeReq.ToolChoice = map[string]interface{}{"type": "tool", "name": name}
+		} else {
+			claudeReq.ToolChoice = map[string]interface{}{"type": "any"}
+		}
+	}
+
 	return claudeReq
 }
 
+const jsonObjectInstruction = "Respond only with a single valid JSON object and no other text."
+
+// structuredOutputTool returns the name of the synthetic output tool when the
+// request asks for schema-constrained output.
+func structuredOutputTool(req *models.InvokeRequest) (string, bool) {
+	if req == nil || req.ResponseFormat == nil {
+		return "", false
+	}
+	if req.ResponseFormat.Type != models.ResponseFormatJSONSchema || len(req.ResponseFormat.Schema) == 0 {
+		return "", false
+	}
+	return req.ResponseFormat.SchemaName(), true
+}
+
+func structuredOutputDescription(format *models.ResponseFormat) string {
+	if format.Description != "" {
+		return format.Description
+	}
+	return "Return the final answer using this structured format."
+}
+
+// buildContentBlocks converts message parts to Claude content blocks. Images
+// become image blocks and PDF files become document blocks; other parts,
+// such as audio, are described as text.
+func buildContentBlocks(msg *types.Message) []ClaudeContentBlock {
+	parts := msg.ContentParts()
+	blocks := make([]ClaudeContentBlock, 0, len(parts))
+	for _, part := range parts {
+		switch {
+		case part.Type == types.ContentPartText:
+			blocks = append(blocks, ClaudeContentBlock{Type: "text", Text: part.Text})
+		case part.Type == types.ContentPartImage:
+			if source := mediaSource(part); source != nil {
+				blocks = append(blocks, ClaudeContentBlock{Type: "image", Source: source})
+				continue
+			}
+			blocks = append(blocks, ClaudeContentBlock{Type: "text", Text: part.Describe()})
+		case part.Type == types.ContentPartFile && isDocumentType(part):
+			if source := mediaSource(part); source != nil {
+				blocks = append(blocks, ClaudeContentBlock{Type: "document", Source: source})
+				continue
+			}
+			blocks = append(blocks, ClaudeContentBlock{Type: "text", Text: part.Describe()})
+		default:
+			blocks = append(blocks, ClaudeContentBlock{Type: "text", Text: part.Describe()})
+		}
+	}
+	return blocks
+}
+
+func mediaSource(part types.ContentPart) *ClaudeMediaSource {
+	if mimeType, data, ok := part.InlineData(); ok {
+		if mimeType == "" {
+			return nil
+		}
+		return &ClaudeMediaSource{Type: "base64", MediaType: mimeType, Data: data}
+	}
+	if part.URL != "" {
+		return &ClaudeMediaSource{Type: "url", URL: part.URL}
+	}
+	return nil
+}
+
+func isDocumentType(part types.ContentPart) bool {
+	mimeType := part.MimeType
+	if inline, _, ok := part.InlineData(); ok && inline != "" {
+		mimeType = inline
+	}
+	return mimeType == "application/pdf" || (mimeType == "" && strings.HasSuffix(strings.ToLower(part.URL), ".pdf"))
+}
+
+// applyStructuredOutput moves the synthetic output tool call into Content so
+// callers receive the JSON document as the response body.
+func applyStructuredOutput(req *models.InvokeRequest, resp *types.ModelResponse) {
+	name, ok := structuredOutputTool(req)
+	if !ok || resp == nil {
+		return
+	}
+	for i, tc := range resp.ToolCalls {
+		if tc.Function.Name != name {
+			continue
+		}
+		resp.Content = tc.Function.Arguments
+		resp.ToolCalls = append(resp.ToolCalls[:i:i], resp.ToolCalls[i+1:]...)
+		if len(resp.ToolCalls) == 0 {
+			resp.ToolCalls = nil
+		}
+		return
+	}
+}
+
 // convertResponse converts Claude response to ModelResponse
 func (a *Anthropic) convertResponse(resp *ClaudeResponse) *types.ModelResponse {
 	modelResp := &types.ModelResponse{
@@ -521,16 +654,33 @@ type ClaudeRequest struct {
 	MaxTokens         int                    `json:"max_tokens"`
 	Temperature       float64                `json:"temperature,omitempty"`
 	Tools             []ClaudeTool           `json:"tools,omitempty"`
+	ToolChoice        map[string]interface{} `json:"tool_choice,omitempty"`
 	Stream            bool                   `json:"stream,omitempty"`
 	Thinking          *ThinkingConfig        `json:"thinking,omitempty"`
 	Betas             []string               `json:"betas,omitempty"`
 	ContextManagement map[string]interface{} `json:"context_management,omitempty"`
 }
 
-// ClaudeMessage represents a message in the conversation
+// ClaudeMessage represents a message in the conversation. Content is a
+// string, or a []ClaudeContentBlock for multimodal messages.
 type ClaudeMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// ClaudeContentBlock represents a content block in a request message
+type ClaudeContentBlock struct {
+	Type   string             `json:"type"`
+	Text   string             `json:"text,omitempty"`
+	Source *ClaudeMediaSource `json:"source,omitempty"`
+}
+
+// ClaudeMediaSource is the source of an image or document block
+type ClaudeMediaSource struct {
+	Type      string `json:"type"` // base64 or url
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
 }
 
 // ClaudeTool represents a tool definition
@@ -573,16 +723,26 @@ type ClaudeUsage struct {
 
 // StreamEvent represents a streaming event
 type StreamEvent struct {
-	Type  string      `json:"type"`
-	Delta StreamDelta `json:"delta,omitempty"`
-	Error StreamError `json:"error,omitempty"`
+	Type         string              `json:"type"`
+	Index        int                 `json:"index"`
+	ContentBlock *StreamContentBlock `json:"content_block,omitempty"`
+	Delta        StreamDelta         `json:"delta,omitempty"`
+	Error        StreamError         `json:"error,omitempty"`
+}
+
+// StreamContentBlock is the block announced by a content_block_start event
+type StreamContentBlock struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
 }
 
 // StreamDelta represents delta content in streaming
 type StreamDelta struct {
-	Type     string `json:"type"`
-	Text     string `json:"text,omitempty"`
-	Thinking string `json:"thinking,omitempty"`
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	Thinking    string `json:"thinking,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
 }
 
 // StreamError represents an error in streaming
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -768,3 +770,127 @@ func TestNew_WithTimeout(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildClaudeRequest_ResponseFormat(t *testing.T) {
+	model, _ := New("claude-3-opus-20240229", Config{APIKey: "test-key"})
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"answer": map[string]interface{}{"type": "string"}},
+	}
+
+	req := &models.InvokeRequest{
+		Messages:       []*types.Message{{Role: types.RoleUser, Content: "Hi"}},
+		ResponseFormat: &models.ResponseFormat{Type: models.ResponseFormatJSONSchema, Name: "reply", Schema: schema},
+	}
+	claudeReq := model.buildClaudeRequest(req)
+	if len(claudeReq.Tools) != 1 || claudeReq.Tools[0].Name != "reply" {
+		t.Fatalf("expected synthetic output tool, got %+v", claudeReq.Tools)
+	}
+	if claudeReq.ToolChoice["type"] != "tool" || claudeReq.ToolChoice["name"] != "reply" {
+		t.Fatalf("expected forced tool choice, got %+v", claudeReq.ToolChoice)
+	}
+
+	resp := &types.ModelResponse{
+		ToolCalls: []types.ToolCall{{
+			ID:       "toolu_1",
+			Type:     "function",
+			Function: types.ToolCallFunction{Name: "reply", Arguments: `{"answer":"ok"}`},
+		}},
+	}
+	applyStructuredOutput(req, resp)
+	if resp.Content != `{"answer":"ok"}` || resp.HasToolCalls() {
+		t.Fatalf("expected output tool call to become content, got %+v", resp)
+	}
+
+	jsonReq := model.buildClaudeRequest(&models.InvokeRequest{
+		Messages:       []*types.Message{{Role: types.RoleUser, Content: "Hi"}},
+		ResponseFormat: &models.ResponseFormat{Type: models.ResponseFormatJSONObject},
+	})
+	if len(jsonReq.Tools) != 0 || jsonReq.System != jsonObjectInstruction {
+		t.Fatalf("expected json_object to add a system instruction, got %+v", jsonReq)
+	}
+}
+
+func TestBuildClaudeRequest_MediaParts(t *testing.T) {
+	model, _ := New("claude-3-5-sonnet-20241022", Config{APIKey: "test-key"})
+
+	msg := types.NewUserMessageWithParts("Describe these",
+		types.NewImageDataPart([]byte("png-bytes"), "image/png"),
+		types.NewImageURLPart("https://example.com/cat.jpg"),
+		types.NewFileURLPart("https://example.com/paper.pdf", "application/pdf", "paper.pdf"),
+		types.NewAudioDataPart([]byte("wav"), "audio/wav"),
+	)
+	claudeReq := model.buildClaudeRequest(&models.InvokeRequest{Messages: []*types.Message{msg}})
+
+	blocks, ok := claudeReq.Messages[0].Content.([]ClaudeContentBlock)
+	if !ok {
+		t.Fatalf("expected content blocks, got %T", claudeReq.Messages[0].Content)
+	}
+	if len(blocks) != 5 {
+		t.Fatalf("expected 5 blocks, got %d", len(blocks))
+	}
+	if blocks[0].Type != "text" || blocks[0].Text != "Describe these" {
+		t.Errorf("unexpected text block: %+v", blocks[0])
+	}
+	if blocks[1].Type != "image" || blocks[1].Source.Type != "base64" || blocks[1].Source.MediaType != "image/png" || blocks[1].Source.Data != "cG5nLWJ5dGVz" {
+		t.Errorf("unexpected base64 image block: %+v", blocks[1])
+	}
+	if blocks[2].Type != "image" || blocks[2].Source.Type != "url" || blocks[2].Source.URL != "https://example.com/cat.jpg" {
+		t.Errorf("unexpected url image block: %+v", blocks[2])
+	}
+	if blocks[3].Type != "document" || blocks[3].Source.URL != "https://example.com/paper.pdf" {
+		t.Errorf("unexpected document block: %+v", blocks[3])
+	}
+	if blocks[4].Type != "text" || blocks[4].Text != "[audio attachment: audio/wav]" {
+		t.Errorf("audio should be described as text, got %+v", blocks[4])
+	}
+
+	// Text-only messages keep plain string content
+	plain := model.buildClaudeRequest(&models.InvokeRequest{Messages: []*types.Message{types.NewUserMessage("hi")}})
+	if plain.Messages[0].Content != "hi" {
+		t.Errorf("expected string content, got %#v", plain.Messages[0].Content)
+	}
+}
+
+func TestInvokeStream_StructuredOutputWithTools(t *testing.T) {
+	events := []string{
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text"}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Sure. "}}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"reply"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"answer\":"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"ok\"}"}}`,
+		`{"type":"message_stop"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Join(events, "\n")))
+	}))
+	defer server.Close()
+
+	model, _ := New("claude-3-opus-20240229", Config{APIKey: "test-key", BaseURL: server.URL})
+	stream, err := model.InvokeStream(context.Background(), &models.InvokeRequest{
+		Messages: []*types.Message{{Role: types.RoleUser, Content: "Hi"}},
+		Tools: []models.ToolDefinition{{
+			Type:     "function",
+			Function: models.FunctionSchema{Name: "lookup", Parameters: map[string]interface{}{"type": "object"}},
+		}},
+		ResponseFormat: &models.ResponseFormat{
+			Type:   models.ResponseFormatJSONSchema,
+			Name:   "reply",
+			Schema: map[string]interface{}{"type": "object"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("InvokeStream() error = %v", err)
+	}
+
+	var content strings.Builder
+	for chunk := range stream {
+		if chunk.Error != nil {
+			t.Fatalf("stream error = %v", chunk.Error)
+		}
+		content.WriteString(chunk.Content)
+	}
+	if got := content.String(); got != `Sure. {"answer":"ok"}` {
+		t.Fatalf("streamed content = %q", got)
+	}
+}
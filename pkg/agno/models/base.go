@@ -8,12 +8,49 @@ import (
 
 // InvokeRequest contains parameters for model invocation
 type InvokeRequest struct {
-	Messages    []*types.Message
-	Tools       []ToolDefinition
-	Temperature float64
-	MaxTokens   int
-	Stream      bool
-	Extra       map[string]interface{}
+	Messages       []*types.Message
+	Tools          []ToolDefinition
+	Temperature    float64
+	MaxTokens      int
+	Stream         bool
+	ResponseFormat *ResponseFormat // Optional structured output constraint
+	Extra          map[string]interface{}
+}
+
+// ResponseFormatType selects how a model should format its reply
+type ResponseFormatType string
+
+const (
+	// ResponseFormatText requests plain text output (the default)
+	ResponseFormatText ResponseFormatType = "text"
+	// ResponseFormatJSONObject requests any syntactically valid JSON object
+	ResponseFormatJSONObject ResponseFormatType = "json_object"
+	// ResponseFormatJSONSchema requests JSON conforming to Schema
+	ResponseFormatJSONSchema ResponseFormatType = "json_schema"
+)
+
+// ResponseFormat asks the model for structured output. Providers map it to
+// their native mode (OpenAI response_format, Gemini responseSchema, Ollama
+// format, Anthropic forced tool use).
+type ResponseFormat struct {
+	Type        ResponseFormatType     `json:"type"`
+	Name        string                 `json:"name,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Schema      map[string]interface{} `json:"schema,omitempty"`
+	Strict      bool                   `json:"strict,omitempty"`
+}
+
+// IsJSON reports whether the format asks for JSON output
+func (f *ResponseFormat) IsJSON() bool {
+	return f != nil && (f.Type == ResponseFormatJSONObject || f.Type == ResponseFormatJSONSchema)
+}
+
+// SchemaName returns the schema name, falling back to a generic default
+func (f *ResponseFormat) SchemaName() string {
+	if f == nil || f.Name == "" {
+		return "structured_output"
+	}
+	return f.Name
 }
 
 // ToolDefinition defines a tool that can be called by the model
@@ -156,13 +156,25 @@ func (c *Cohere) buildChatRequest(req *models.InvokeRequest) ChatRequest {
         Preamble:     preamble,
         Temperature:  temperature,
         MaxTokens:    maxTokens,
-        ResponseJSON: nil,
+        ResponseJSON: buildResponseFormat(req.ResponseFormat),
     }
 
-    // Basic tool/function schema could be added via response_format when needed
     return chatReq
 }
 
+// buildResponseFormat maps a structured output request to Cohere's
+// response_format ({"type":"json_object","schema":{...}})
+func buildResponseFormat(format *models.ResponseFormat) map[string]interface{} {
+    if !format.IsJSON() {
+        return nil
+    }
+    out := map[string]interface{}{"type": "json_object"}
+    if format.Type == models.ResponseFormatJSONSchema && len(format.Schema) > 0 {
+        out["schema"] = format.Schema
+    }
+    return out
+}
+
 func extractAssistantText(resp ChatResponse) string {
     // Prefer top-level message content
     if resp.Message.Content != nil {
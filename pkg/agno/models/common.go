@@ -58,7 +58,7 @@ func ReadJSONResponse(resp *http.Response, target interface{}) error {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return types.NewAPIError(fmt.Sprintf("API error (status %d): %s", resp.StatusCode, string(body)), nil)
+		return types.NewAPIError(fmt.Sprintf("API error (status %d): %s", resp.StatusCode, string(body)), NewHTTPStatusError(resp, body))
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
@@ -73,7 +73,7 @@ func ReadErrorResponse(resp *http.Response) error {
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
-	return types.NewAPIError(fmt.Sprintf("API error (status %d): %s", resp.StatusCode, string(body)), nil)
+	return types.NewAPIError(fmt.Sprintf("API error (status %d): %s", resp.StatusCode, string(body)), NewHTTPStatusError(resp, body))
 }
 
 // ConvertMessages converts types.Message to a generic message format
@@ -2,11 +2,14 @@ package models
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/rexleimo/agno-go/pkg/agno/types"
+	"github.com/sashabaranov/go-openai"
 )
 
 func TestNewHTTPClient(t *testing.T) {
@@ -288,3 +291,103 @@ func TestBuildToolDefinitions_Empty(t *testing.T) {
 		t.Errorf("Expected nil for empty tools slice, got %v", result)
 	}
 }
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{name: "none", header: http.Header{}, want: 0},
+		{name: "seconds", header: http.Header{"Retry-After": {"3"}}, want: 3 * time.Second},
+		{name: "milliseconds", header: http.Header{"Retry-After-Ms": {"250"}}, want: 250 * time.Millisecond},
+		{name: "http date", header: http.Header{"Retry-After": {now.Add(90 * time.Second).Format(http.TimeFormat)}}, want: 90 * time.Second},
+		{name: "past date", header: http.Header{"Retry-After": {now.Add(-time.Minute).Format(http.TimeFormat)}}, want: 0},
+		{name: "garbage", header: http.Header{"Retry-After": {"soon"}}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseRetryAfter(tt.header, now); got != tt.want {
+				t.Errorf("ParseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadErrorResponse_CarriesStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to get response: %v", err)
+	}
+
+	var statusErr *HTTPStatusError
+	if !errors.As(ReadErrorResponse(resp), &statusErr) {
+		t.Fatal("expected HTTPStatusError cause")
+	}
+	if statusErr.StatusCode != http.StatusTooManyRequests || statusErr.RetryAfter != 2*time.Second || !statusErr.Retryable() {
+		t.Fatalf("unexpected status error: %+v", statusErr)
+	}
+}
+
+func TestNewOpenAIClient_CarriesStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"slow down","type":"rate_limit"}}`))
+	}))
+	defer server.Close()
+
+	config := openai.DefaultConfig("test-key")
+	config.BaseURL = server.URL
+	client := NewOpenAIClient(config)
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: "gpt-4o"})
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected HTTPStatusError cause, got %v", err)
+	}
+	if statusErr.StatusCode != http.StatusTooManyRequests || statusErr.RetryAfter != 3*time.Second {
+		t.Fatalf("unexpected status error: %+v", statusErr)
+	}
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusTooManyRequests || apiErr.Message != "slow down" {
+		t.Fatalf("expected the SDK's APIError to stay reachable, got %v", err)
+	}
+
+	_, err = client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{Model: "gpt-4o"})
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected HTTPStatusError cause for streams, got %v", err)
+	}
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError for streams, got %v", err)
+	}
+}
+
+func TestNewOpenAIClient_NonJSONErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("upstream unavailable"))
+	}))
+	defer server.Close()
+
+	config := openai.DefaultConfig("test-key")
+	config.BaseURL = server.URL
+	client := NewOpenAIClient(config)
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: "gpt-4o"})
+	var reqErr *openai.RequestError
+	if !errors.As(err, &reqErr) || reqErr.HTTPStatusCode != http.StatusBadGateway || string(reqErr.Body) != "upstream unavailable" {
+		t.Fatalf("expected the SDK's RequestError, got %v", err)
+	}
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || !statusErr.Retryable() {
+		t.Fatalf("expected a retryable HTTPStatusError, got %v", err)
+	}
+}
@@ -49,7 +49,7 @@ func New(modelID string, config Config) (*DeepSeek, error) {
 			Provider: "deepseek",
 			Name:     modelID,
 		},
-		client: openai.NewClientWithConfig(clientConfig),
+		client: models.NewOpenAIClient(clientConfig),
 		config: config,
 	}, nil
 }
@@ -236,6 +236,9 @@ func (d *DeepSeek) buildChatRequest(req *models.InvokeRequest) openai.ChatComple
 		chatReq.MaxTokens = d.config.MaxTokens
 	}
 
+	// Set response format for structured output
+	chatReq.ResponseFormat = models.OpenAIResponseFormat(req.ResponseFormat)
+
 	return chatReq
 }
 
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"path"
 	"strconv"
 	"strings"
 
@@ -108,7 +110,7 @@ func (g *Gemini) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, types.NewAPIError(fmt.Sprintf("API error (status %d): %s", resp.StatusCode, string(body)), nil)
+		return nil, types.NewAPIError(fmt.Sprintf("API error (status %d): %s", resp.StatusCode, string(body)), models.NewHTTPStatusError(resp, body))
 	}
 
 	var geminiResp GeminiResponse
@@ -144,7 +146,7 @@ func (g *Gemini) InvokeStream(ctx context.Context, req *models.InvokeRequest) (<
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, types.NewAPIError(fmt.Sprintf("API error (status %d): %s", resp.StatusCode, string(body)), nil)
+		return nil, types.NewAPIError(fmt.Sprintf("API error (status %d): %s", resp.StatusCode, string(body)), models.NewHTTPStatusError(resp, body))
 	}
 
 	chunks := make(chan types.ResponseChunk)
@@ -261,6 +263,15 @@ func (g *Gemini) buildGeminiRequest(req *models.InvokeRequest) *GeminiRequest {
 		}
 	}
 
+	// Gemini rejects a JSON response mime type combined with function calling,
+	// so structured output is only requested when no tools are offered.
+	if req.ResponseFormat.IsJSON() && len(req.Tools) == 0 {
+		geminiReq.GenerationConfig.ResponseMimeType = "application/json"
+		if req.ResponseFormat.Type == models.ResponseFormatJSONSchema && len(req.ResponseFormat.Schema) > 0 {
+			geminiReq.GenerationConfig.ResponseSchema = toGeminiSchema(req.ResponseFormat.Schema)
+		}
+	}
+
 	if thinkingBudget > 0 || includeThoughtsPtr != nil {
 		tc := &ThinkingConfig{}
 		if thinkingBudget > 0 {
@@ -280,10 +291,8 @@ func (g *Gemini) buildGeminiRequest(req *models.InvokeRequest) *GeminiRequest {
 			systemInstruction = msg.Content
 		case types.RoleUser:
 			geminiReq.Contents = append(geminiReq.Contents, Content{
-				Role: "user",
-				Parts: []Part{
-					{Text: msg.Content},
-				},
+				Role:  "user",
+				Parts: buildUserParts(msg),
 			})
 		case types.RoleAssistant:
 			content := Content{
@@ -487,12 +496,60 @@ type Content struct {
 	Parts []Part `json:"parts"`
 }
 
+// buildUserParts converts a user message to Gemini parts. Images, audio and
+// files are sent as inlineData or fileData; media whose MIME type is unknown
+// is described as text.
+func buildUserParts(msg *types.Message) []Part {
+	if !msg.HasMedia() {
+		return []Part{{Text: msg.Content}}
+	}
+
+	contentParts := msg.ContentParts()
+	parts := make([]Part, 0, len(contentParts))
+	for _, part := range contentParts {
+		if part.Type == types.ContentPartText {
+			parts = append(parts, Part{Text: part.Text})
+			continue
+		}
+		if mimeType, data, ok := part.InlineData(); ok && mimeType != "" {
+			parts = append(parts, Part{InlineData: &Blob{MimeType: mimeType, Data: data}})
+			continue
+		}
+		if part.URL != "" {
+			mimeType := part.MimeType
+			if mimeType == "" {
+				mimeType = mime.TypeByExtension(path.Ext(strings.SplitN(part.URL, "?", 2)[0]))
+			}
+			if mimeType != "" {
+				parts = append(parts, Part{FileData: &FileData{MimeType: mimeType, FileURI: part.URL}})
+				continue
+			}
+		}
+		parts = append(parts, Part{Text: part.Describe()})
+	}
+	return parts
+}
+
 // Part represents a part of the content
 type Part struct {
 	Text             string            `json:"text,omitempty"`
 	FunctionCall     *FunctionCall     `json:"functionCall,omitempty"`
 	FunctionResponse *FunctionResponse `json:"functionResponse,omitempty"`
 	Thought          bool              `json:"thought,omitempty"`
+	InlineData       *Blob             `json:"inlineData,omitempty"`
+	FileData         *FileData         `json:"fileData,omitempty"`
+}
+
+// Blob carries inline base64 media
+type Blob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+// FileData references media by URI
+type FileData struct {
+	MimeType string `json:"mimeType"`
+	FileURI  string `json:"fileUri"`
 }
 
 // FunctionCall represents a function call
@@ -521,10 +578,86 @@ type FunctionDeclaration struct {
 
 // GenerationConfig represents generation configuration
 type GenerationConfig struct {
-	Temperature     float64 `json:"temperature,omitempty"`
-	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
-	TopP            float64 `json:"topP,omitempty"`
-	TopK            int     `json:"topK,omitempty"`
+	Temperature      float64                `json:"temperature,omitempty"`
+	MaxOutputTokens  int                    `json:"maxOutputTokens,omitempty"`
+	TopP             float64                `json:"topP,omitempty"`
+	TopK             int                    `json:"topK,omitempty"`
+	ResponseMimeType string                 `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]interface{} `json:"responseSchema,omitempty"`
+}
+
+// geminiUnsupportedSchemaKeys lists JSON Schema keywords the Gemini
+// responseSchema (an OpenAPI subset) does not accept.
+var geminiUnsupportedSchemaKeys = map[string]struct{}{
+	"$schema":              {},
+	"$defs":                {},
+	"$ref":                 {},
+	"$id":                  {},
+	"additionalProperties": {},
+	"default":              {},
+	"strict":               {},
+	"title":                {},
+}
+
+// toGeminiSchema converts a JSON schema into the OpenAPI subset accepted by
+// Gemini: unsupported keywords are dropped and nullable type unions such as
+// ["string","null"] become {"type":"string","nullable":true}.
+func toGeminiSchema(schema map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		if _, skip := geminiUnsupportedSchemaKeys[key]; skip {
+			continue
+		}
+		switch key {
+		case "type":
+			if list, ok := value.([]interface{}); ok {
+				for _, item := range list {
+					if t, ok := item.(string); ok {
+						if t == "null" {
+							out["nullable"] = true
+						} else if _, set := out["type"]; !set {
+							out["type"] = t
+						}
+					}
+				}
+				continue
+			}
+			out[key] = value
+		case "properties":
+			if props, ok := value.(map[string]interface{}); ok {
+				converted := make(map[string]interface{}, len(props))
+				for name, prop := range props {
+					if propSchema, ok := prop.(map[string]interface{}); ok {
+						converted[name] = toGeminiSchema(propSchema)
+					}
+				}
+				out[key] = converted
+				continue
+			}
+			out[key] = value
+		case "items":
+			if items, ok := value.(map[string]interface{}); ok {
+				out[key] = toGeminiSchema(items)
+				continue
+			}
+			out[key] = value
+		case "anyOf":
+			if list, ok := value.([]interface{}); ok {
+				converted := make([]interface{}, 0, len(list))
+				for _, item := range list {
+					if itemSchema, ok := item.(map[string]interface{}); ok {
+						converted = append(converted, toGeminiSchema(itemSchema))
+					}
+				}
+				out[key] = converted
+				continue
+			}
+			out[key] = value
+		default:
+			out[key] = value
+		}
+	}
+	return out
 }
 
 // ThinkingConfig represents reasoning configuration for Gemini thinking models
@@ -727,3 +727,81 @@ func TestSSEDecoder(t *testing.T) {
 		t.Errorf("expected to find 'message1' in messages, got %v", messages)
 	}
 }
+
+func TestBuildGeminiRequest_ResponseFormat(t *testing.T) {
+	model := &Gemini{BaseModel: models.BaseModel{ID: "gemini-pro", Provider: "gemini"}}
+	format := &models.ResponseFormat{
+		Type: models.ResponseFormatJSONSchema,
+		Schema: map[string]interface{}{
+			"$schema":              "http://json-schema.org/draft-07/schema#",
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties": map[string]interface{}{
+				"note": map[string]interface{}{"type": []interface{}{"string", "null"}},
+			},
+		},
+	}
+
+	req := model.buildGeminiRequest(&models.InvokeRequest{
+		Messages:       []*types.Message{{Role: types.RoleUser, Content: "Hi"}},
+		ResponseFormat: format,
+	})
+	if req.GenerationConfig == nil || req.GenerationConfig.ResponseMimeType != "application/json" {
+		t.Fatalf("expected JSON mime type, got %+v", req.GenerationConfig)
+	}
+	schema := req.GenerationConfig.ResponseSchema
+	if _, ok := schema["$schema"]; ok {
+		t.Fatalf("expected $schema to be stripped, got %v", schema)
+	}
+	if _, ok := schema["additionalProperties"]; ok {
+		t.Fatalf("expected additionalProperties to be stripped, got %v", schema)
+	}
+	note := schema["properties"].(map[string]interface{})["note"].(map[string]interface{})
+	if note["type"] != "string" || note["nullable"] != true {
+		t.Fatalf("expected nullable string, got %v", note)
+	}
+
+	withTools := model.buildGeminiRequest(&models.InvokeRequest{
+		Messages:       []*types.Message{{Role: types.RoleUser, Content: "Hi"}},
+		ResponseFormat: format,
+		Tools: []models.ToolDefinition{{
+			Type:     "function",
+			Function: models.FunctionSchema{Name: "lookup"},
+		}},
+	})
+	if withTools.GenerationConfig != nil && withTools.GenerationConfig.ResponseMimeType != "" {
+		t.Fatalf("expected JSON mode to be skipped when tools are present")
+	}
+}
+
+func TestBuildGeminiRequest_MediaParts(t *testing.T) {
+	model, _ := New("gemini-1.5-flash", Config{APIKey: "test-key"})
+
+	msg := types.NewUserMessageWithParts("What do you see and hear?",
+		types.NewImageDataPart([]byte("png-bytes"), "image/png"),
+		types.NewImageURLPart("https://example.com/photo.jpg?size=large"),
+		types.NewAudioDataPart([]byte("wav"), "audio/wav"),
+		types.NewFileURLPart("gs://bucket/report", "", "report"),
+	)
+	geminiReq := model.buildGeminiRequest(&models.InvokeRequest{Messages: []*types.Message{msg}})
+
+	parts := geminiReq.Contents[0].Parts
+	if len(parts) != 5 {
+		t.Fatalf("expected 5 parts, got %d", len(parts))
+	}
+	if parts[0].Text != "What do you see and hear?" {
+		t.Errorf("unexpected text part: %+v", parts[0])
+	}
+	if parts[1].InlineData == nil || parts[1].InlineData.MimeType != "image/png" || parts[1].InlineData.Data != "cG5nLWJ5dGVz" {
+		t.Errorf("unexpected inline image: %+v", parts[1])
+	}
+	if parts[2].FileData == nil || parts[2].FileData.MimeType != "image/jpeg" || parts[2].FileData.FileURI != "https://example.com/photo.jpg?size=large" {
+		t.Errorf("unexpected file data image: %+v", parts[2])
+	}
+	if parts[3].InlineData == nil || parts[3].InlineData.MimeType != "audio/wav" {
+		t.Errorf("unexpected inline audio: %+v", parts[3])
+	}
+	if parts[4].Text != "[file attachment: report, gs://bucket/report]" {
+		t.Errorf("file without MIME type should be described, got %+v", parts[4])
+	}
+}
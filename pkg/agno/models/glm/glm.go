@@ -124,7 +124,7 @@ func (g *GLM) InvokeStream(ctx context.Context, req *models.InvokeRequest) (<-ch
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, types.NewAPIError(fmt.Sprintf("GLM API error (status %d): %s", resp.StatusCode, string(body)), nil)
+		return nil, types.NewAPIError(fmt.Sprintf("GLM API error (status %d): %s", resp.StatusCode, string(body)), models.NewHTTPStatusError(resp, body))
 	}
 
 	// Create channel for streaming chunks
@@ -336,7 +336,7 @@ func (g *GLM) makeRequest(ctx context.Context, glmReq *glmRequest) (*glmResponse
 		if err := json.Unmarshal(body, &errResp); err == nil {
 			return nil, types.NewAPIError(fmt.Sprintf("GLM API error: %s (code: %s)", errResp.Error.Message, errResp.Error.Code), nil)
 		}
-		return nil, types.NewAPIError(fmt.Sprintf("GLM API error (status %d): %s", resp.StatusCode, string(body)), nil)
+		return nil, types.NewAPIError(fmt.Sprintf("GLM API error (status %d): %s", resp.StatusCode, string(body)), models.NewHTTPStatusError(resp, body))
 	}
 
 	// Parse response
@@ -70,7 +70,7 @@ func New(modelID string, config Config) (*Groq, error) {
 			ID:       modelID,
 			Provider: "groq",
 		},
-		client: openai.NewClientWithConfig(clientConfig),
+		client: models.NewOpenAIClient(clientConfig),
 		config: Config{
 			APIKey:      config.APIKey,
 			BaseURL:     baseURL,
@@ -274,6 +274,9 @@ func (g *Groq) buildChatRequest(req *models.InvokeRequest) openai.ChatCompletion
 		chatReq.MaxTokens = g.config.MaxTokens
 	}
 
+	// Set response format for structured output
+	chatReq.ResponseFormat = models.OpenAIResponseFormat(req.ResponseFormat)
+
 	return chatReq
 }
 
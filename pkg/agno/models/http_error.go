@@ -0,0 +1,150 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// HTTPStatusError describes a non-2xx response from a model API. Providers
+// attach it as the cause of their API errors so callers (for example retry
+// wrappers) can inspect the status code and the server's Retry-After hint.
+type HTTPStatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // Zero when the server sent no hint
+	Body       string
+	Err        error // SDK error built from the response, if any
+}
+
+// NewHTTPStatusError builds an HTTPStatusError from a response and its body
+func NewHTTPStatusError(resp *http.Response, body []byte) *HTTPStatusError {
+	if resp == nil {
+		return &HTTPStatusError{Body: string(body)}
+	}
+	return &HTTPStatusError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: ParseRetryAfter(resp.Header, time.Now()),
+		Body:       string(body),
+	}
+}
+
+// Error implements the error interface
+func (e *HTTPStatusError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("http status %d: %v", e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("http status %d", e.StatusCode)
+}
+
+// Unwrap returns the SDK error, so errors.As still finds it
+func (e *HTTPStatusError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the status usually indicates a transient failure
+// (request timeout, rate limiting or a server-side error)
+func (e *HTTPStatusError) Retryable() bool {
+	switch {
+	case e.StatusCode == http.StatusRequestTimeout,
+		e.StatusCode == http.StatusTooEarly,
+		e.StatusCode == http.StatusTooManyRequests:
+		return true
+	case e.StatusCode >= 500 && e.StatusCode != http.StatusNotImplemented:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseRetryAfter reads the delay requested by the server from the
+// "retry-after-ms" or "Retry-After" header (delta-seconds or HTTP-date)
+func ParseRetryAfter(header http.Header, now time.Time) time.Duration {
+	if header == nil {
+		return 0
+	}
+	if ms := strings.TrimSpace(header.Get("retry-after-ms")); ms != "" {
+		if v, err := strconv.ParseFloat(ms, 64); err == nil && v > 0 {
+			return time.Duration(v * float64(time.Millisecond))
+		}
+	}
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// NewOpenAIClient creates a go-openai client for OpenAI-compatible providers.
+// go-openai drops the response headers from its errors, so non-2xx responses
+// are reported as an HTTPStatusError wrapping the *openai.APIError or
+// *openai.RequestError the SDK would have returned. Callers can match either
+// with errors.As and still read the Retry-After hint.
+func NewOpenAIClient(config openai.ClientConfig) *openai.Client {
+	doer := config.HTTPClient
+	if doer == nil {
+		doer = &http.Client{}
+	}
+	config.HTTPClient = statusErrorDoer{doer: doer}
+	return openai.NewClientWithConfig(config)
+}
+
+// statusErrorDoer reports non-2xx responses as errors
+type statusErrorDoer struct {
+	doer openai.HTTPDoer
+}
+
+// Do sends the request and converts error responses into HTTPStatusErrors
+func (d statusErrorDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.doer.Do(req)
+	if err != nil || resp.StatusCode < http.StatusBadRequest {
+		return resp, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error, reading response body: %w", err)
+	}
+	statusErr := NewHTTPStatusError(resp, body)
+	statusErr.Err = openAIError(resp, body)
+	return nil, statusErr
+}
+
+// openAIError builds the error go-openai returns for an error response
+func openAIError(resp *http.Response, body []byte) error {
+	var errRes openai.ErrorResponse
+	err := json.Unmarshal(body, &errRes)
+	if err != nil || errRes.Error == nil {
+		reqErr := &openai.RequestError{
+			HTTPStatus:     resp.Status,
+			HTTPStatusCode: resp.StatusCode,
+			Err:            err,
+			Body:           body,
+		}
+		if errRes.Error != nil {
+			reqErr.Err = errRes.Error
+		}
+		return reqErr
+	}
+
+	errRes.Error.HTTPStatus = resp.Status
+	errRes.Error.HTTPStatusCode = resp.StatusCode
+	return errRes.Error
+}
@@ -31,7 +31,7 @@ func New(modelID string, config Config) (*InternLM, error) {
     cc := openai.DefaultConfig(config.APIKey); cc.BaseURL = config.BaseURL
     to := config.Timeout; if to == 0 { to = 60 * time.Second }
     cc.HTTPClient = &http.Client{Timeout: to}
-    return &InternLM{ BaseModel: models.BaseModel{ID: modelID, Provider: "internlm"}, client: openai.NewClientWithConfig(cc), config: Config{APIKey: config.APIKey, BaseURL: config.BaseURL, Temperature: config.Temperature, MaxTokens: config.MaxTokens, Timeout: to} }, nil
+    return &InternLM{ BaseModel: models.BaseModel{ID: modelID, Provider: "internlm"}, client: models.NewOpenAIClient(cc), config: Config{APIKey: config.APIKey, BaseURL: config.BaseURL, Temperature: config.Temperature, MaxTokens: config.MaxTokens, Timeout: to} }, nil
 }
 
 func (in *InternLM) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
@@ -66,6 +66,7 @@ func (in *InternLM) buildChatRequest(req *models.InvokeRequest) openai.ChatCompl
     temp, max := models.MergeConfig(req.Temperature, in.config.Temperature, req.MaxTokens, in.config.MaxTokens)
     if temp > 0 { chatReq.Temperature = float32(temp) }
     if max > 0 { chatReq.MaxTokens = max }
+    chatReq.ResponseFormat = models.OpenAIResponseFormat(req.ResponseFormat)
     return chatReq
 }
 
@@ -53,7 +53,7 @@ func New(modelID string, config Config) (*LMStudio, error) {
 
     return &LMStudio{
         BaseModel: models.BaseModel{ID: modelID, Provider: "lmstudio"},
-        client:    openai.NewClientWithConfig(clientConfig),
+        client:    models.NewOpenAIClient(clientConfig),
         config: Config{
             APIKey:      apiKey,
             BaseURL:     baseURL,
@@ -171,6 +171,9 @@ func (l *LMStudio) buildChatRequest(req *models.InvokeRequest) openai.ChatComple
     if maxTokens > 0 {
         chatReq.MaxTokens = maxTokens
     }
+    // Set response format for structured output
+    chatReq.ResponseFormat = models.OpenAIResponseFormat(req.ResponseFormat)
+
     return chatReq
 }
 
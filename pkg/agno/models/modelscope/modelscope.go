@@ -49,7 +49,7 @@ func New(modelID string, config Config) (*ModelScope, error) {
 			Provider: "modelscope",
 			Name:     modelID,
 		},
-		client: openai.NewClientWithConfig(clientConfig),
+		client: models.NewOpenAIClient(clientConfig),
 		config: config,
 	}, nil
 }
@@ -236,6 +236,9 @@ func (m *ModelScope) buildChatRequest(req *models.InvokeRequest) openai.ChatComp
 		chatReq.MaxTokens = m.config.MaxTokens
 	}
 
+	// Set response format for structured output
+	chatReq.ResponseFormat = models.OpenAIResponseFormat(req.ResponseFormat)
+
 	return chatReq
 }
 
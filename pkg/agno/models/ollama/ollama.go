@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/rexleimo/agno-go/pkg/agno/models"
 	"github.com/rexleimo/agno-go/pkg/agno/types"
@@ -75,7 +76,7 @@ func (o *Ollama) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, types.NewAPIError(fmt.Sprintf("API error: %s", string(body)), nil)
+		return nil, types.NewAPIError(fmt.Sprintf("API error: %s", string(body)), models.NewHTTPStatusError(resp, body))
 	}
 
 	var ollamaResp OllamaResponse
@@ -111,7 +112,7 @@ func (o *Ollama) InvokeStream(ctx context.Context, req *models.InvokeRequest) (<
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, types.NewAPIError(fmt.Sprintf("API error: %s", string(body)), nil)
+		return nil, types.NewAPIError(fmt.Sprintf("API error: %s", string(body)), models.NewHTTPStatusError(resp, body))
 	}
 
 	chunks := make(chan types.ResponseChunk)
@@ -170,6 +171,9 @@ func (o *Ollama) buildOllamaRequest(req *models.InvokeRequest) *OllamaRequest {
 			Role:    string(msg.Role),
 			Content: msg.Content,
 		}
+		if msg.HasMedia() {
+			ollamaMsg.Content, ollamaMsg.Images = buildMultimodalContent(msg)
+		}
 		ollamaReq.Messages = append(ollamaReq.Messages, ollamaMsg)
 	}
 
@@ -192,6 +196,15 @@ func (o *Ollama) buildOllamaRequest(req *models.InvokeRequest) *OllamaRequest {
 		ollamaReq.Options = options
 	}
 
+	// Set structured output format: "json" or a JSON schema
+	if req.ResponseFormat.IsJSON() {
+		if req.ResponseFormat.Type == models.ResponseFormatJSONSchema && len(req.ResponseFormat.Schema) > 0 {
+			ollamaReq.Format = req.ResponseFormat.Schema
+		} else {
+			ollamaReq.Format = "json"
+		}
+	}
+
 	// Convert tools
 	if len(req.Tools) > 0 {
 		ollamaReq.Tools = make([]OllamaTool, len(req.Tools))
@@ -259,12 +272,34 @@ type OllamaRequest struct {
 	Stream   bool                   `json:"stream"`
 	Options  map[string]interface{} `json:"options,omitempty"`
 	Tools    []OllamaTool           `json:"tools,omitempty"`
+	Format   interface{}            `json:"format,omitempty"`
+}
+
+// buildMultimodalContent splits message parts into text content and base64
+// images. Ollama only accepts inline image data, so remote images, audio and
+// files are described in the text instead.
+func buildMultimodalContent(msg *types.Message) (string, []string) {
+	var texts []string
+	var images []string
+	for _, part := range msg.ContentParts() {
+		if part.Type == types.ContentPartImage {
+			if _, data, ok := part.InlineData(); ok {
+				images = append(images, data)
+				continue
+			}
+		}
+		if text := part.Describe(); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n"), images
 }
 
 // OllamaMessage represents a message in the conversation
 type OllamaMessage struct {
 	Role      string           `json:"role"`
 	Content   string           `json:"content"`
+	Images    []string         `json:"images,omitempty"` // base64-encoded images
 	ToolCalls []OllamaToolCall `json:"tool_calls,omitempty"`
 }
 
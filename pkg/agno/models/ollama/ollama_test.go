@@ -122,6 +122,28 @@ func TestBuildOllamaRequest(t *testing.T) {
 	}
 }
 
+func TestBuildOllamaRequest_Images(t *testing.T) {
+	model, err := New("llava", Config{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	msg := types.NewUserMessageWithParts("What is this?",
+		types.NewImageDataPart([]byte("png-bytes"), "image/png"),
+		types.ContentPart{Type: types.ContentPartImage, URL: "data:image/jpeg;base64,anBn"},
+		types.NewImageURLPart("https://example.com/cat.png"),
+	)
+	ollamaReq := model.buildOllamaRequest(&models.InvokeRequest{Messages: []*types.Message{msg}})
+
+	got := ollamaReq.Messages[0]
+	if len(got.Images) != 2 || got.Images[0] != "cG5nLWJ5dGVz" || got.Images[1] != "anBn" {
+		t.Errorf("unexpected images: %v", got.Images)
+	}
+	if got.Content != "What is this?\n[image attachment: https://example.com/cat.png]" {
+		t.Errorf("unexpected content: %q", got.Content)
+	}
+}
+
 func TestConvertResponse(t *testing.T) {
 	model, _ := New("llama2", Config{})
 
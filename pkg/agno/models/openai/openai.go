@@ -1,10 +1,13 @@
 package openai
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/rexleimo/agno-go/pkg/agno/models"
@@ -46,22 +49,26 @@ func New(modelID string, config Config) (*OpenAI, error) {
 	if timeout == 0 {
 		timeout = 60 * time.Second // Default 60 seconds / 默认60秒
 	}
-	clientConfig.HTTPClient = &http.Client{
+	clientConfig.HTTPClient = mediaDoer{next: &http.Client{
 		Timeout: timeout,
-	}
+	}}
 
 	return &OpenAI{
 		BaseModel: models.BaseModel{
 			ID:       modelID,
 			Provider: "openai",
 		},
-		client: openai.NewClientWithConfig(clientConfig),
+		client: models.NewOpenAIClient(clientConfig),
 		config: config,
 	}, nil
 }
 
 // Invoke calls the OpenAI API synchronously
 func (o *OpenAI) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+	ctx, err := withNativeContent(ctx, req.Messages)
+	if err != nil {
+		return nil, err
+	}
 	chatReq := o.buildChatRequest(req)
 
 	resp, err := o.client.CreateChatCompletion(ctx, chatReq)
@@ -108,6 +115,10 @@ func (o *OpenAI) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.
 
 // InvokeStream calls the OpenAI API with streaming response
 func (o *OpenAI) InvokeStream(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
+	ctx, err := withNativeContent(ctx, req.Messages)
+	if err != nil {
+		return nil, err
+	}
 	chatReq := o.buildChatRequest(req)
 	chatReq.Stream = true
 
@@ -186,6 +197,12 @@ func (o *OpenAI) buildChatRequest(req *models.InvokeRequest) openai.ChatCompleti
 			Name:    msg.Name,
 		}
 
+		// Send multimodal content as parts
+		if msg.HasMedia() {
+			chatMsg.Content = ""
+			chatMsg.MultiContent = buildMultiContent(msg)
+		}
+
 		// Handle tool call responses
 		if msg.ToolCallID != "" {
 			chatMsg.ToolCallID = msg.ToolCallID
@@ -242,6 +259,9 @@ func (o *OpenAI) buildChatRequest(req *models.InvokeRequest) openai.ChatCompleti
 		chatReq.MaxTokens = o.config.MaxTokens
 	}
 
+	// Set response format for structured output
+	chatReq.ResponseFormat = models.OpenAIResponseFormat(req.ResponseFormat)
+
 	return chatReq
 }
 
@@ -252,3 +272,181 @@ func ValidateConfig(config Config) error {
 	}
 	return nil
 }
+
+// buildMultiContent converts message parts to chat message parts. Images are
+// sent as image_url parts (inline data as data: URLs). Audio and file parts,
+// which the go-openai types cannot express, are emitted as typed placeholders
+// that mediaDoer replaces with their native JSON when the request is sent.
+func buildMultiContent(msg *types.Message) []openai.ChatMessagePart {
+	parts := msg.ContentParts()
+	result := make([]openai.ChatMessagePart, 0, len(parts))
+	for _, part := range parts {
+		switch part.Type {
+		case types.ContentPartImage:
+			result = append(result, openai.ChatMessagePart{
+				Type: openai.ChatMessagePartTypeImageURL,
+				ImageURL: &openai.ChatMessageImageURL{
+					URL:    part.DataURL(),
+					Detail: openai.ImageURLDetail(part.Detail),
+				},
+			})
+		case types.ContentPartAudio:
+			result = append(result, openai.ChatMessagePart{Type: partTypeInputAudio})
+		case types.ContentPartFile:
+			result = append(result, openai.ChatMessagePart{Type: partTypeFile})
+		default:
+			result = append(result, openai.ChatMessagePart{
+				Type: openai.ChatMessagePartTypeText,
+				Text: part.Describe(),
+			})
+		}
+	}
+	return result
+}
+
+const (
+	partTypeInputAudio openai.ChatMessagePartType = "input_audio"
+	partTypeFile       openai.ChatMessagePartType = "file"
+)
+
+// chatContentPart is the wire form of a chat message part, including the
+// input_audio and file parts missing from the go-openai types.
+type chatContentPart struct {
+	Type       openai.ChatMessagePartType  `json:"type"`
+	Text       string                      `json:"text,omitempty"`
+	ImageURL   *openai.ChatMessageImageURL `json:"image_url,omitempty"`
+	InputAudio *chatInputAudio             `json:"input_audio,omitempty"`
+	File       *chatInputFile              `json:"file,omitempty"`
+}
+
+type chatInputAudio struct {
+	Data   string `json:"data"`
+	Format string `json:"format"`
+}
+
+type chatInputFile struct {
+	Filename string `json:"filename,omitempty"`
+	FileData string `json:"file_data"`
+}
+
+// audioFormats maps audio MIME types to the input_audio formats the API accepts.
+var audioFormats = map[string]string{
+	"audio/wav":   "wav",
+	"audio/wave":  "wav",
+	"audio/x-wav": "wav",
+	"audio/mpeg":  "mp3",
+	"audio/mp3":   "mp3",
+}
+
+// nativeContentKey carries, per message index, the content arrays mediaDoer
+// writes into the request body.
+type nativeContentKey struct{}
+
+// withNativeContent serializes the content of messages with audio or file
+// parts and attaches it to ctx. Media the API cannot accept, such as audio or
+// files given only by URL, is reported as an error instead of being dropped.
+func withNativeContent(ctx context.Context, messages []*types.Message) (context.Context, error) {
+	contents := make(map[int]json.RawMessage)
+	for i, msg := range messages {
+		if !hasNativeOnlyParts(msg) {
+			continue
+		}
+		content, err := nativeContent(msg)
+		if err != nil {
+			return ctx, err
+		}
+		contents[i] = content
+	}
+	if len(contents) == 0 {
+		return ctx, nil
+	}
+	return context.WithValue(ctx, nativeContentKey{}, contents), nil
+}
+
+func hasNativeOnlyParts(msg *types.Message) bool {
+	for _, part := range msg.ContentParts() {
+		if part.Type == types.ContentPartAudio || part.Type == types.ContentPartFile {
+			return true
+		}
+	}
+	return false
+}
+
+func nativeContent(msg *types.Message) (json.RawMessage, error) {
+	placeholders := buildMultiContent(msg)
+	parts := make([]chatContentPart, 0, len(placeholders))
+	media := msg.ContentParts()
+	for i, placeholder := range placeholders {
+		part := chatContentPart{Type: placeholder.Type, Text: placeholder.Text, ImageURL: placeholder.ImageURL}
+		switch placeholder.Type {
+		case partTypeInputAudio:
+			mimeType, data, ok := media[i].InlineData()
+			if !ok {
+				return nil, types.NewInvalidInputError("OpenAI accepts audio only as inline data, not by URL", nil)
+			}
+			format, ok := audioFormats[strings.ToLower(mimeType)]
+			if !ok {
+				return nil, types.NewInvalidInputError(fmt.Sprintf("OpenAI does not accept audio of type %q; use wav or mp3", mimeType), nil)
+			}
+			part.InputAudio = &chatInputAudio{Data: data, Format: format}
+		case partTypeFile:
+			if _, _, ok := media[i].InlineData(); !ok {
+				return nil, types.NewInvalidInputError("OpenAI accepts files only as inline data, not by URL", nil)
+			}
+			part.File = &chatInputFile{Filename: media[i].Filename, FileData: media[i].DataURL()}
+		}
+		parts = append(parts, part)
+	}
+	return json.Marshal(parts)
+}
+
+// mediaDoer writes the native content attached by withNativeContent into the
+// JSON body of chat completion requests.
+type mediaDoer struct {
+	next openai.HTTPDoer
+}
+
+func (d mediaDoer) Do(req *http.Request) (*http.Response, error) {
+	contents, ok := req.Context().Value(nativeContentKey{}).(map[int]json.RawMessage)
+	if !ok || req.Body == nil {
+		return d.next.Do(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	body, err = replaceMessageContent(body, contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode media content: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return d.next.Do(req)
+}
+
+func replaceMessageContent(body []byte, contents map[int]json.RawMessage) ([]byte, error) {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	var messages []map[string]json.RawMessage
+	if err := json.Unmarshal(payload["messages"], &messages); err != nil {
+		return nil, err
+	}
+	for i, content := range contents {
+		if i < len(messages) {
+			messages[i]["content"] = content
+		}
+	}
+	encoded, err := json.Marshal(messages)
+	if err != nil {
+		return nil, err
+	}
+	payload["messages"] = encoded
+	return json.Marshal(payload)
+}
@@ -1,11 +1,17 @@
 package openai
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/rexleimo/agno-go/pkg/agno/models"
 	"github.com/rexleimo/agno-go/pkg/agno/types"
+	"github.com/sashabaranov/go-openai"
 )
 
 func TestNew(t *testing.T) {
@@ -239,6 +245,107 @@ func TestOpenAI_buildChatRequest_WithToolCalls(t *testing.T) {
 	}
 }
 
+func TestOpenAI_buildChatRequest_WithMedia(t *testing.T) {
+	model, err := New("gpt-4o", Config{APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	msg := types.NewUserMessageWithParts("What is in these images?",
+		types.NewImageURLPart("https://example.com/cat.png"),
+		types.NewImageDataPart([]byte("png-bytes"), "image/png"),
+		types.NewFileURLPart("https://example.com/report.pdf", "application/pdf", "report.pdf"),
+	)
+
+	chatReq := model.buildChatRequest(&models.InvokeRequest{Messages: []*types.Message{msg}})
+	got := chatReq.Messages[0]
+
+	if got.Content != "" {
+		t.Errorf("Content = %q, want empty when MultiContent is used", got.Content)
+	}
+	if len(got.MultiContent) != 4 {
+		t.Fatalf("MultiContent parts = %d, want 4", len(got.MultiContent))
+	}
+	if got.MultiContent[0].Type != openai.ChatMessagePartTypeText || got.MultiContent[0].Text != "What is in these images?" {
+		t.Errorf("first part = %+v, want the text", got.MultiContent[0])
+	}
+	if got.MultiContent[1].ImageURL == nil || got.MultiContent[1].ImageURL.URL != "https://example.com/cat.png" {
+		t.Errorf("second part = %+v, want image URL", got.MultiContent[1])
+	}
+	if got.MultiContent[2].ImageURL == nil || got.MultiContent[2].ImageURL.URL != "data:image/png;base64,cG5nLWJ5dGVz" {
+		t.Errorf("third part = %+v, want data URL", got.MultiContent[2])
+	}
+	if got.MultiContent[3].Type != partTypeFile {
+		t.Errorf("fourth part = %+v, want file placeholder", got.MultiContent[3])
+	}
+}
+
+func TestOpenAI_Invoke_NativeMediaParts(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","model":"gpt-4o-audio-preview","choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	model, err := New("gpt-4o-audio-preview", Config{APIKey: "test-key", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	msg := types.NewUserMessageWithParts("Summarize these",
+		types.NewAudioDataPart([]byte("wav-bytes"), "audio/wav"),
+		types.NewFileDataPart([]byte("pdf-bytes"), "application/pdf", "report.pdf"),
+	)
+	if _, err := model.Invoke(context.Background(), &models.InvokeRequest{Messages: []*types.Message{msg}}); err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+
+	var sent struct {
+		Messages []struct {
+			Content []map[string]interface{} `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("invalid request body: %v", err)
+	}
+	content := sent.Messages[0].Content
+	if len(content) != 3 || content[0]["text"] != "Summarize these" {
+		t.Fatalf("unexpected content: %s", body)
+	}
+	audio, _ := content[1]["input_audio"].(map[string]interface{})
+	if content[1]["type"] != "input_audio" || audio["format"] != "wav" || audio["data"] != "d2F2LWJ5dGVz" {
+		t.Errorf("audio part = %v, want native input_audio", content[1])
+	}
+	file, _ := content[2]["file"].(map[string]interface{})
+	if content[2]["type"] != "file" || file["filename"] != "report.pdf" || file["file_data"] != "data:application/pdf;base64,cGRmLWJ5dGVz" {
+		t.Errorf("file part = %v, want native file", content[2])
+	}
+}
+
+func TestOpenAI_Invoke_RejectsUnsupportedMedia(t *testing.T) {
+	model, err := New("gpt-4o", Config{APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("Failed to create model: %v", err)
+	}
+
+	tests := map[string]types.ContentPart{
+		"audio url":    {Type: types.ContentPartAudio, URL: "https://example.com/a.wav", MimeType: "audio/wav"},
+		"audio format": types.NewAudioDataPart([]byte("ogg"), "audio/ogg"),
+		"file url":     types.NewFileURLPart("https://example.com/report.pdf", "application/pdf", "report.pdf"),
+	}
+	for name, part := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg := types.NewUserMessageWithParts("hi", part)
+			_, err := model.Invoke(context.Background(), &models.InvokeRequest{Messages: []*types.Message{msg}})
+			if err == nil {
+				t.Fatal("expected error for media the API cannot accept")
+			}
+		})
+	}
+}
+
 func TestOpenAI_GetProvider(t *testing.T) {
 	model, err := New("gpt-4o-mini", Config{APIKey: "test-key"})
 	if err != nil {
@@ -79,7 +79,7 @@ func New(modelID string, config Config) (*OpenRouter, error) {
 
     return &OpenRouter{
         BaseModel: models.BaseModel{ID: modelID, Provider: "openrouter"},
-        client:    openai.NewClientWithConfig(clientConfig),
+        client:    models.NewOpenAIClient(clientConfig),
         config: Config{
             APIKey:      config.APIKey,
             BaseURL:     baseURL,
@@ -236,6 +236,9 @@ func (o *OpenRouter) buildChatRequest(req *models.InvokeRequest) openai.ChatComp
         chatReq.MaxTokens = maxTokens
     }
 
+    // Set response format for structured output
+    chatReq.ResponseFormat = models.OpenAIResponseFormat(req.ResponseFormat)
+
     return chatReq
 }
 
@@ -32,7 +32,7 @@ func New(modelID string, config Config) (*Portkey, error) {
     cc := openai.DefaultConfig(config.APIKey); cc.BaseURL = baseURL
     to := config.Timeout; if to == 0 { to = 60 * time.Second }
     cc.HTTPClient = &http.Client{Timeout: to}
-    return &Portkey{ BaseModel: models.BaseModel{ID: modelID, Provider: "portkey"}, client: openai.NewClientWithConfig(cc), config: Config{APIKey: config.APIKey, BaseURL: baseURL, Temperature: config.Temperature, MaxTokens: config.MaxTokens, Timeout: to}}, nil
+    return &Portkey{ BaseModel: models.BaseModel{ID: modelID, Provider: "portkey"}, client: models.NewOpenAIClient(cc), config: Config{APIKey: config.APIKey, BaseURL: baseURL, Temperature: config.Temperature, MaxTokens: config.MaxTokens, Timeout: to}}, nil
 }
 
 func (p *Portkey) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
@@ -67,6 +67,7 @@ func (p *Portkey) buildChatRequest(req *models.InvokeRequest) openai.ChatComplet
     temp, max := models.MergeConfig(req.Temperature, p.config.Temperature, req.MaxTokens, p.config.MaxTokens)
     if temp > 0 { chatReq.Temperature = float32(temp) }
     if max > 0 { chatReq.MaxTokens = max }
+    chatReq.ResponseFormat = models.OpenAIResponseFormat(req.ResponseFormat)
     return chatReq
 }
 
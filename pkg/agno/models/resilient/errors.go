@@ -0,0 +1,82 @@
+package resilient
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+	"github.com/sashabaranov/go-openai"
+)
+
+// IsRetryable reports whether err looks transient: rate limiting (429),
+// request timeouts, 5xx responses, network timeouts or dropped connections.
+// Context cancellation is never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || isCancellation(err) {
+		return false
+	}
+
+	var statusErr *models.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Retryable()
+	}
+	if code, ok := openAIStatus(err); ok {
+		return (&models.HTTPStatusError{StatusCode: code}).Retryable()
+	}
+
+	var agnoErr *types.AgnoError
+	if errors.As(err, &agnoErr) {
+		switch agnoErr.Code {
+		case types.ErrCodeRateLimitError, types.ErrCodeModelTimeout:
+			return true
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// RetryAfter returns the delay requested by the provider for err, or zero
+func RetryAfter(err error) time.Duration {
+	var statusErr *models.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
+
+// StatusCode returns the HTTP status carried by err, or zero
+func StatusCode(err error) int {
+	var statusErr *models.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	if code, ok := openAIStatus(err); ok {
+		return code
+	}
+	return 0
+}
+
+// openAIStatus extracts the status code from go-openai errors, which are used
+// by every OpenAI-compatible provider
+func openAIStatus(err error) (int, bool) {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode != 0 {
+		return apiErr.HTTPStatusCode, true
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) && reqErr.HTTPStatusCode != 0 {
+		return reqErr.HTTPStatusCode, true
+	}
+	return 0, false
+}
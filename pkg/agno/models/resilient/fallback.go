@@ -0,0 +1,136 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// FallbackConfig configures the fallback wrapper
+type FallbackConfig struct {
+	// Models are tried in order until one succeeds. The first model provides
+	// the wrapper's provider, ID and name.
+	Models []models.Model
+
+	// ShouldFallback decides whether an error moves on to the next model
+	// (default: every error except context cancellation)
+	ShouldFallback func(err error) bool
+
+	// OnFallback is called when a model fails and the next one is tried
+	OnFallback func(failed models.Model, err error, next models.Model)
+}
+
+// Fallback tries an ordered list of models and returns the first success.
+// The answering model is recorded in ModelResponse.Metadata.Extra under
+// MetadataProvider, MetadataModelID and MetadataFallbackIndex.
+type Fallback struct {
+	models         []models.Model
+	shouldFallback func(err error) bool
+	onFallback     func(failed models.Model, err error, next models.Model)
+}
+
+// NewFallback creates a fallback wrapper
+func NewFallback(config FallbackConfig) (*Fallback, error) {
+	if len(config.Models) == 0 {
+		return nil, types.NewInvalidConfigError("at least one model is required", nil)
+	}
+	for i, m := range config.Models {
+		if m == nil {
+			return nil, types.NewInvalidConfigError(fmt.Sprintf("model at index %d is nil", i), nil)
+		}
+	}
+
+	shouldFallback := config.ShouldFallback
+	if shouldFallback == nil {
+		shouldFallback = func(err error) bool { return !isCancellation(err) }
+	}
+
+	return &Fallback{
+		models:         append([]models.Model(nil), config.Models...),
+		shouldFallback: shouldFallback,
+		onFallback:     config.OnFallback,
+	}, nil
+}
+
+// Invoke calls each model in order until one succeeds
+func (f *Fallback) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+	var errs []error
+	for i, model := range f.models {
+		resp, err := model.Invoke(ctx, req)
+		if err == nil {
+			setMetadata(resp, MetadataProvider, model.GetProvider())
+			setMetadata(resp, MetadataModelID, model.GetID())
+			setMetadata(resp, MetadataFallbackIndex, i)
+			return resp, nil
+		}
+		errs = append(errs, fmt.Errorf("%s/%s: %w", model.GetProvider(), model.GetID(), err))
+		if !f.next(ctx, i, err) {
+			break
+		}
+	}
+	return nil, f.failure(errs)
+}
+
+// InvokeStream starts a stream on each model in order until one starts
+// successfully. A model whose stream fails before producing any content is
+// skipped; errors after content has been streamed are passed through. The
+// answering model is recorded in the first chunk's Metadata.
+func (f *Fallback) InvokeStream(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
+	var errs []error
+	for i, model := range f.models {
+		stream, err := startStream(ctx, model, req, map[string]interface{}{
+			MetadataProvider:      model.GetProvider(),
+			MetadataModelID:       model.GetID(),
+			MetadataFallbackIndex: i,
+		})
+		if err == nil {
+			return stream, nil
+		}
+		errs = append(errs, fmt.Errorf("%s/%s: %w", model.GetProvider(), model.GetID(), err))
+		if !f.next(ctx, i, err) {
+			break
+		}
+	}
+	return nil, f.failure(errs)
+}
+
+// next reports whether the model after index i should be tried
+func (f *Fallback) next(ctx context.Context, i int, err error) bool {
+	if ctx.Err() != nil || i == len(f.models)-1 || !f.shouldFallback(err) {
+		return false
+	}
+	if f.onFallback != nil {
+		f.onFallback(f.models[i], err, f.models[i+1])
+	}
+	return true
+}
+
+func (f *Fallback) failure(errs []error) error {
+	if len(errs) == 1 {
+		return errors.Unwrap(errs[0])
+	}
+	return types.NewAPIError(fmt.Sprintf("all %d models failed", len(errs)), errors.Join(errs...))
+}
+
+// GetProvider returns the primary model's provider
+func (f *Fallback) GetProvider() string {
+	return f.models[0].GetProvider()
+}
+
+// GetID returns the primary model's ID
+func (f *Fallback) GetID() string {
+	return f.models[0].GetID()
+}
+
+// GetName returns the primary model's name
+func (f *Fallback) GetName() string {
+	return f.models[0].GetName()
+}
+
+// Models returns the fallback chain
+func (f *Fallback) Models() []models.Model {
+	return append([]models.Model(nil), f.models...)
+}
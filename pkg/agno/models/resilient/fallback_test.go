@@ -0,0 +1,110 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/models"
+)
+
+func TestFallback_Invoke(t *testing.T) {
+	first := newScriptedModel("openai", statusError(500, 0))
+	second := newScriptedModel("anthropic", errors.New("overloaded"))
+	third := newScriptedModel("ollama")
+
+	var switched []string
+	fb, err := NewFallback(FallbackConfig{
+		Models: []models.Model{first, second, third},
+		OnFallback: func(failed models.Model, err error, next models.Model) {
+			switched = append(switched, failed.GetID()+"->"+next.GetID())
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewFallback() error = %v", err)
+	}
+	if fb.GetID() != "openai" {
+		t.Fatalf("expected primary model ID, got %s", fb.GetID())
+	}
+
+	resp, err := fb.Invoke(context.Background(), &models.InvokeRequest{})
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if resp.Content != "answer from ollama" {
+		t.Fatalf("unexpected content %q", resp.Content)
+	}
+	extra := resp.Metadata.Extra
+	if extra[MetadataModelID] != "ollama" || extra[MetadataProvider] != "mock-ollama" || extra[MetadataFallbackIndex] != 2 {
+		t.Fatalf("unexpected metadata: %v", extra)
+	}
+	if strings.Join(switched, ",") != "openai->anthropic,anthropic->ollama" {
+		t.Fatalf("unexpected fallback sequence: %v", switched)
+	}
+}
+
+func TestFallback_AllFail(t *testing.T) {
+	fb, err := NewFallback(FallbackConfig{Models: []models.Model{
+		newScriptedModel("a", errors.New("a failed")),
+		newScriptedModel("b", errors.New("b failed")),
+	}})
+	if err != nil {
+		t.Fatalf("NewFallback() error = %v", err)
+	}
+
+	_, err = fb.Invoke(context.Background(), &models.InvokeRequest{})
+	if err == nil || !strings.Contains(err.Error(), "a failed") || !strings.Contains(err.Error(), "b failed") {
+		t.Fatalf("expected combined error, got %v", err)
+	}
+}
+
+func TestFallback_StopsOnCancellation(t *testing.T) {
+	second := newScriptedModel("b")
+	fb, _ := NewFallback(FallbackConfig{Models: []models.Model{
+		newScriptedModel("a", context.Canceled),
+		second,
+	}})
+
+	if _, err := fb.Invoke(context.Background(), &models.InvokeRequest{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+	if second.calls != 0 {
+		t.Fatalf("expected no fallback after cancellation")
+	}
+}
+
+func TestFallback_InvokeStream(t *testing.T) {
+	fb, _ := NewFallback(FallbackConfig{Models: []models.Model{
+		newScriptedModel("a", statusError(503, 0)),
+		newScriptedModel("b"),
+	}})
+
+	stream, err := fb.InvokeStream(context.Background(), &models.InvokeRequest{})
+	if err != nil {
+		t.Fatalf("InvokeStream() error = %v", err)
+	}
+	var content string
+	meta := map[string]interface{}{}
+	for chunk := range stream {
+		content += chunk.Content
+		for k, v := range chunk.Metadata {
+			meta[k] = v
+		}
+	}
+	if content != "answer from b" {
+		t.Fatalf("unexpected streamed content %q", content)
+	}
+	if meta[MetadataFallbackIndex] != 1 || meta[MetadataModelID] != "b" {
+		t.Fatalf("unexpected stream metadata: %v", meta)
+	}
+}
+
+func TestNewFallback_Validation(t *testing.T) {
+	if _, err := NewFallback(FallbackConfig{}); err == nil {
+		t.Fatal("expected error for empty model list")
+	}
+	if _, err := NewFallback(FallbackConfig{Models: []models.Model{nil}}); err == nil {
+		t.Fatal("expected error for nil model")
+	}
+}
@@ -0,0 +1,308 @@
+// Package resilient provides models.Model wrappers that add retries with
+// exponential backoff and ordered fallback across models. Wrappers compose:
+//
+//	primary, _ := resilient.NewRetry(openaiModel, resilient.RetryConfig{})
+//	secondary, _ := resilient.NewRetry(claudeModel, resilient.RetryConfig{})
+//	model, _ := resilient.NewFallback(resilient.FallbackConfig{
+//		Models: []models.Model{primary, secondary, ollamaModel},
+//	})
+package resilient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// Metadata keys recorded in ModelResponse.Metadata.Extra
+const (
+	MetadataProvider      = "model_provider" // Provider of the model that answered
+	MetadataModelID       = "model_id"       // ID of the model that answered
+	MetadataAttempts      = "model_attempts" // Attempts made by the retry wrapper
+	MetadataFallbackIndex = "fallback_index" // Position of the answering model in the fallback chain
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+	defaultMultiplier     = 2.0
+	defaultJitter         = 0.2
+	defaultMaxRetryAfter  = time.Minute
+)
+
+// RetryConfig configures the retry wrapper. Zero values use the defaults.
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt (default: 3).
+	// Use a negative value to disable retries.
+	MaxRetries int
+
+	// InitialBackoff is the delay before the first retry (default: 500ms)
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the computed exponential delay (default: 30s)
+	MaxBackoff time.Duration
+
+	// Multiplier grows the delay after each retry (default: 2)
+	Multiplier float64
+
+	// Jitter randomizes each delay by ±Jitter as a fraction (default: 0.2 when
+	// nil). Use a pointer to 0 to disable jitter.
+	Jitter *float64
+
+	// MaxRetryAfter is the longest server-requested Retry-After delay that is
+	// honored; longer hints stop retrying so a fallback can take over
+	// (default: 1m)
+	MaxRetryAfter time.Duration
+
+	// ShouldRetry decides whether an error is transient (default: IsRetryable)
+	ShouldRetry func(err error) bool
+}
+
+// Retry wraps a model and retries transient failures with exponential
+// backoff and jitter, honoring Retry-After hints from the provider.
+type Retry struct {
+	model  models.Model
+	config RetryConfig
+	jitter float64
+	sleep  func(ctx context.Context, d time.Duration) error
+	rand   func() float64
+}
+
+// NewRetry creates a retry wrapper around model
+func NewRetry(model models.Model, config RetryConfig) (*Retry, error) {
+	if model == nil {
+		return nil, types.NewInvalidConfigError("model is required", nil)
+	}
+
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultMaxRetries
+	} else if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaultInitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaultMaxBackoff
+	}
+	if config.Multiplier < 1 {
+		config.Multiplier = defaultMultiplier
+	}
+	jitter := defaultJitter
+	if config.Jitter != nil {
+		jitter = *config.Jitter
+		if jitter < 0 || jitter > 1 {
+			return nil, types.NewInvalidConfigError("jitter must be between 0 and 1", nil)
+		}
+	}
+	if config.MaxRetryAfter <= 0 {
+		config.MaxRetryAfter = defaultMaxRetryAfter
+	}
+	if config.ShouldRetry == nil {
+		config.ShouldRetry = IsRetryable
+	}
+
+	return &Retry{
+		model:  model,
+		config: config,
+		jitter: jitter,
+		sleep:  sleepContext,
+		rand:   rand.Float64,
+	}, nil
+}
+
+// Invoke calls the wrapped model, retrying transient failures
+func (r *Retry) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := r.model.Invoke(ctx, req)
+		if err == nil {
+			setMetadata(resp, MetadataProvider, r.model.GetProvider())
+			setMetadata(resp, MetadataModelID, r.model.GetID())
+			setMetadata(resp, MetadataAttempts, attempt+1)
+			return resp, nil
+		}
+		if waitErr := r.wait(ctx, attempt, err); waitErr != nil {
+			return nil, waitErr
+		}
+	}
+}
+
+// InvokeStream starts a stream on the wrapped model, retrying transient
+// failures that occur before any content has been received. Once chunks
+// have been forwarded, later errors are passed through unchanged. The
+// answering model is recorded in the first chunk's Metadata.
+func (r *Retry) InvokeStream(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
+	for attempt := 0; ; attempt++ {
+		stream, err := startStream(ctx, r.model, req, map[string]interface{}{
+			MetadataProvider: r.model.GetProvider(),
+			MetadataModelID:  r.model.GetID(),
+			MetadataAttempts: attempt + 1,
+		})
+		if err == nil {
+			return stream, nil
+		}
+		if waitErr := r.wait(ctx, attempt, err); waitErr != nil {
+			return nil, waitErr
+		}
+	}
+}
+
+// wait returns nil when another attempt should be made after sleeping, or
+// the error to report otherwise.
+func (r *Retry) wait(ctx context.Context, attempt int, err error) error {
+	if ctx.Err() != nil || attempt >= r.config.MaxRetries || !r.config.ShouldRetry(err) {
+		return err
+	}
+
+	delay := r.backoff(attempt)
+	if hint := RetryAfter(err); hint > 0 {
+		if hint > r.config.MaxRetryAfter {
+			return err
+		}
+		delay = hint
+	}
+
+	if sleepErr := r.sleep(ctx, delay); sleepErr != nil {
+		return err
+	}
+	return nil
+}
+
+// backoff computes the jittered exponential delay before retry attempt+1
+func (r *Retry) backoff(attempt int) time.Duration {
+	base := float64(r.config.InitialBackoff) * math.Pow(r.config.Multiplier, float64(attempt))
+	if max := float64(r.config.MaxBackoff); base > max {
+		base = max
+	}
+	// Spread the delay uniformly over [base*(1-jitter), base*(1+jitter)].
+	factor := 1 + r.jitter*(2*r.rand()-1)
+	return time.Duration(base * factor)
+}
+
+// GetProvider returns the wrapped model's provider
+func (r *Retry) GetProvider() string {
+	return r.model.GetProvider()
+}
+
+// GetID returns the wrapped model's ID
+func (r *Retry) GetID() string {
+	return r.model.GetID()
+}
+
+// GetName returns the wrapped model's name
+func (r *Retry) GetName() string {
+	return r.model.GetName()
+}
+
+// Unwrap returns the wrapped model
+func (r *Retry) Unwrap() models.Model {
+	return r.model
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startStream invokes the model's stream and waits for the first chunk, so
+// failures reported as an initial error chunk (as OpenAI-compatible
+// providers do) are returned as errors and can be retried or fall back.
+// meta is added to the Metadata of the first forwarded chunk.
+func startStream(ctx context.Context, model models.Model, req *models.InvokeRequest, meta map[string]interface{}) (<-chan types.ResponseChunk, error) {
+	stream, err := model.InvokeStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if stream == nil {
+		return nil, types.NewAPIError(fmt.Sprintf("model %s returned no stream", model.GetID()), nil)
+	}
+
+	var first types.ResponseChunk
+	var ok bool
+	select {
+	case first, ok = <-stream:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	out := make(chan types.ResponseChunk)
+	if !ok {
+		close(out)
+		return out, nil
+	}
+	if first.Error != nil && !errors.Is(first.Error, io.EOF) && first.Content == "" && len(first.ToolCalls) == 0 {
+		go drain(stream)
+		return nil, first.Error
+	}
+
+	first.Metadata = mergeMetadata(first.Metadata, meta)
+
+	go func() {
+		defer close(out)
+		select {
+		case out <- first:
+		case <-ctx.Done():
+			go drain(stream)
+			return
+		}
+		for chunk := range stream {
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				go drain(stream)
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// drain discards the remaining chunks so the provider goroutine can exit
+func drain(stream <-chan types.ResponseChunk) {
+	for range stream {
+	}
+}
+
+func setMetadata(resp *types.ModelResponse, key string, value interface{}) {
+	if resp == nil {
+		return
+	}
+	if resp.Metadata.Extra == nil {
+		resp.Metadata.Extra = make(map[string]interface{})
+	}
+	resp.Metadata.Extra[key] = value
+}
+
+// mergeMetadata returns a copy of dst with the entries of src added
+func mergeMetadata(dst, src map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(dst)+len(src))
+	for k, v := range dst {
+		merged[k] = v
+	}
+	for k, v := range src {
+		merged[k] = v
+	}
+	return merged
+}
+
+// isCancellation reports whether err stems from context cancellation
+func isCancellation(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
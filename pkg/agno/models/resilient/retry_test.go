@@ -0,0 +1,206 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// scriptedModel returns the scripted errors in order, then succeeds
+type scriptedModel struct {
+	models.BaseModel
+	errs    []error
+	calls   int
+	content string
+}
+
+func newScriptedModel(id string, errs ...error) *scriptedModel {
+	return &scriptedModel{
+		BaseModel: models.BaseModel{ID: id, Provider: "mock-" + id},
+		errs:      errs,
+		content:   "answer from " + id,
+	}
+}
+
+func (m *scriptedModel) next() error {
+	m.calls++
+	if m.calls <= len(m.errs) {
+		return m.errs[m.calls-1]
+	}
+	return nil
+}
+
+func (m *scriptedModel) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+	if err := m.next(); err != nil {
+		return nil, err
+	}
+	return &types.ModelResponse{Content: m.content}, nil
+}
+
+// InvokeStream reports failures as an initial error chunk, like the
+// OpenAI-compatible providers do.
+func (m *scriptedModel) InvokeStream(ctx context.Context, req *models.InvokeRequest) (<-chan types.ResponseChunk, error) {
+	ch := make(chan types.ResponseChunk, 2)
+	if err := m.next(); err != nil {
+		ch <- types.ResponseChunk{Error: err, Done: true}
+	} else {
+		ch <- types.ResponseChunk{Content: m.content}
+		ch <- types.ResponseChunk{Done: true}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func statusError(code int, retryAfter time.Duration) error {
+	return types.NewAPIError("API error", &models.HTTPStatusError{StatusCode: code, RetryAfter: retryAfter})
+}
+
+func newTestRetry(t *testing.T, model models.Model, config RetryConfig) (*Retry, *[]time.Duration) {
+	t.Helper()
+	r, err := NewRetry(model, config)
+	if err != nil {
+		t.Fatalf("NewRetry() error = %v", err)
+	}
+	var delays []time.Duration
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+	r.rand = func() float64 { return 0.5 } // no jitter
+	return r, &delays
+}
+
+func TestRetry_Invoke_BacksOffAndHonorsRetryAfter(t *testing.T) {
+	model := newScriptedModel("primary", statusError(503, 0), statusError(429, 7*time.Second), statusError(500, 0))
+	r, delays := newTestRetry(t, model, RetryConfig{InitialBackoff: 100 * time.Millisecond})
+
+	resp, err := r.Invoke(context.Background(), &models.InvokeRequest{})
+	if err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+	if resp.Content != "answer from primary" {
+		t.Fatalf("unexpected content %q", resp.Content)
+	}
+	if resp.Metadata.Extra[MetadataAttempts] != 4 || resp.Metadata.Extra[MetadataModelID] != "primary" {
+		t.Fatalf("unexpected metadata: %v", resp.Metadata.Extra)
+	}
+
+	want := []time.Duration{100 * time.Millisecond, 7 * time.Second, 400 * time.Millisecond}
+	if len(*delays) != len(want) {
+		t.Fatalf("delays = %v, want %v", *delays, want)
+	}
+	for i := range want {
+		if (*delays)[i] != want[i] {
+			t.Fatalf("delays = %v, want %v", *delays, want)
+		}
+	}
+}
+
+func TestRetry_Invoke_StopsOnPermanentErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		config RetryConfig
+		calls  int
+	}{
+		{name: "bad request", err: statusError(400, 0), calls: 1},
+		{name: "retry-after too long", err: statusError(429, time.Hour), calls: 1},
+		{name: "retries exhausted", err: statusError(502, 0), config: RetryConfig{MaxRetries: 2}, calls: 3},
+		{name: "retries disabled", err: statusError(502, 0), config: RetryConfig{MaxRetries: -1}, calls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := make([]error, 10)
+			for i := range errs {
+				errs[i] = tt.err
+			}
+			model := newScriptedModel("m", errs...)
+			r, _ := newTestRetry(t, model, tt.config)
+
+			if _, err := r.Invoke(context.Background(), &models.InvokeRequest{}); !errors.Is(err, tt.err) {
+				t.Fatalf("expected original error, got %v", err)
+			}
+			if model.calls != tt.calls {
+				t.Fatalf("calls = %d, want %d", model.calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestRetry_InvokeStream_RetriesInitialErrorChunk(t *testing.T) {
+	model := newScriptedModel("streamer", statusError(503, 0))
+	r, delays := newTestRetry(t, model, RetryConfig{})
+
+	stream, err := r.InvokeStream(context.Background(), &models.InvokeRequest{})
+	if err != nil {
+		t.Fatalf("InvokeStream() error = %v", err)
+	}
+	var content string
+	meta := map[string]interface{}{}
+	for chunk := range stream {
+		if chunk.Error != nil {
+			t.Fatalf("unexpected chunk error: %v", chunk.Error)
+		}
+		content += chunk.Content
+		for k, v := range chunk.Metadata {
+			meta[k] = v
+		}
+	}
+	if content != "answer from streamer" || len(*delays) != 1 {
+		t.Fatalf("content = %q, delays = %v", content, *delays)
+	}
+	if meta[MetadataAttempts] != 2 || meta[MetadataModelID] != "streamer" {
+		t.Fatalf("unexpected stream metadata: %v", meta)
+	}
+}
+
+func TestRetry_Jitter(t *testing.T) {
+	jitter := func(v float64) *float64 { return &v }
+
+	for _, tt := range []struct {
+		name   string
+		jitter *float64
+		want   time.Duration
+	}{
+		{name: "default", jitter: nil, want: 120 * time.Millisecond},
+		{name: "disabled", jitter: jitter(0), want: 100 * time.Millisecond},
+		{name: "custom", jitter: jitter(0.5), want: 150 * time.Millisecond},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newTestRetry(t, newScriptedModel("m"), RetryConfig{InitialBackoff: 100 * time.Millisecond, Jitter: tt.jitter})
+			r.rand = func() float64 { return 1 } // maximum jitter
+			if got := r.backoff(0); got != tt.want {
+				t.Fatalf("backoff(0) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewRetry(newScriptedModel("m"), RetryConfig{Jitter: jitter(1.5)}); err == nil {
+		t.Fatal("expected error for jitter above 1")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.Canceled, false},
+		{statusError(429, 0), true},
+		{statusError(503, 0), true},
+		{statusError(501, 0), false},
+		{statusError(401, 0), false},
+		{types.NewRateLimitError("slow down", nil), true},
+		{errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// jsonSchemaMarshaler adapts a schema map to the json.Marshaler expected by go-openai
+type jsonSchemaMarshaler map[string]interface{}
+
+// MarshalJSON implements json.Marshaler
+func (s jsonSchemaMarshaler) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}(s))
+}
+
+// OpenAIResponseFormat converts a ResponseFormat to the OpenAI chat completion
+// response_format. It is shared by every OpenAI-compatible provider and
+// returns nil when no format (or plain text) is requested.
+func OpenAIResponseFormat(format *ResponseFormat) *openai.ChatCompletionResponseFormat {
+	if format == nil {
+		return nil
+	}
+
+	switch format.Type {
+	case ResponseFormatJSONObject:
+		return &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+	case ResponseFormatJSONSchema:
+		if len(format.Schema) == 0 {
+			return &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+		}
+		return &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:        format.SchemaName(),
+				Description: format.Description,
+				Schema:      jsonSchemaMarshaler(format.Schema),
+				Strict:      format.Strict,
+			},
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestOpenAIResponseFormat(t *testing.T) {
+	if got := OpenAIResponseFormat(nil); got != nil {
+		t.Fatalf("expected nil for nil format, got %+v", got)
+	}
+	if got := OpenAIResponseFormat(&ResponseFormat{Type: ResponseFormatText}); got != nil {
+		t.Fatalf("expected nil for text format, got %+v", got)
+	}
+
+	obj := OpenAIResponseFormat(&ResponseFormat{Type: ResponseFormatJSONObject})
+	if obj == nil || obj.Type != openai.ChatCompletionResponseFormatTypeJSONObject {
+		t.Fatalf("expected json_object format, got %+v", obj)
+	}
+
+	// A json_schema request without a schema degrades to json_object.
+	empty := OpenAIResponseFormat(&ResponseFormat{Type: ResponseFormatJSONSchema})
+	if empty == nil || empty.Type != openai.ChatCompletionResponseFormatTypeJSONObject {
+		t.Fatalf("expected json_object fallback, got %+v", empty)
+	}
+
+	format := OpenAIResponseFormat(&ResponseFormat{
+		Type:   ResponseFormatJSONSchema,
+		Schema: map[string]interface{}{"type": "object"},
+		Strict: true,
+	})
+	data, err := json.Marshal(format)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var decoded struct {
+		Type       string `json:"type"`
+		JSONSchema struct {
+			Name   string                 `json:"name"`
+			Schema map[string]interface{} `json:"schema"`
+			Strict bool                   `json:"strict"`
+		} `json:"json_schema"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if decoded.Type != "json_schema" || decoded.JSONSchema.Name != "structured_output" || !decoded.JSONSchema.Strict {
+		t.Fatalf("unexpected response_format: %s", data)
+	}
+	if decoded.JSONSchema.Schema["type"] != "object" {
+		t.Fatalf("expected schema to be embedded, got %s", data)
+	}
+}
@@ -32,7 +32,7 @@ func New(modelID string, config Config) (*SambaNova, error) {
     cc := openai.DefaultConfig(config.APIKey); cc.BaseURL = baseURL
     to := config.Timeout; if to == 0 { to = 60 * time.Second }
     cc.HTTPClient = &http.Client{Timeout: to}
-    return &SambaNova{ BaseModel: models.BaseModel{ID: modelID, Provider: "sambanova"}, client: openai.NewClientWithConfig(cc), config: Config{APIKey: config.APIKey, BaseURL: baseURL, Temperature: config.Temperature, MaxTokens: config.MaxTokens, Timeout: to}}, nil
+    return &SambaNova{ BaseModel: models.BaseModel{ID: modelID, Provider: "sambanova"}, client: models.NewOpenAIClient(cc), config: Config{APIKey: config.APIKey, BaseURL: baseURL, Temperature: config.Temperature, MaxTokens: config.MaxTokens, Timeout: to}}, nil
 }
 
 func (s *SambaNova) Invoke(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
@@ -67,6 +67,7 @@ func (s *SambaNova) buildChatRequest(req *models.InvokeRequest) openai.ChatCompl
     temp, max := models.MergeConfig(req.Temperature, s.config.Temperature, req.MaxTokens, s.config.MaxTokens)
     if temp > 0 { chatReq.Temperature = float32(temp) }
     if max > 0 { chatReq.MaxTokens = max }
+    chatReq.ResponseFormat = models.OpenAIResponseFormat(req.ResponseFormat)
     return chatReq
 }
 
@@ -53,7 +53,7 @@ func New(modelID string, config Config) (*Together, error) {
 
     return &Together{
         BaseModel: models.BaseModel{ID: modelID, Provider: "together"},
-        client:    openai.NewClientWithConfig(clientConfig),
+        client:    models.NewOpenAIClient(clientConfig),
         config: Config{
             APIKey:      config.APIKey,
             BaseURL:     baseURL,
@@ -200,6 +200,9 @@ func (t *Together) buildChatRequest(req *models.InvokeRequest) openai.ChatComple
         chatReq.MaxTokens = maxTokens
     }
 
+    // Set response format for structured output
+    chatReq.ResponseFormat = models.OpenAIResponseFormat(req.ResponseFormat)
+
     return chatReq
 }
 
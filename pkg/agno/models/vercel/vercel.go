@@ -42,7 +42,7 @@ func New(modelID string, config Config) (*Vercel, error) {
 
     return &Vercel{
         BaseModel: models.BaseModel{ID: modelID, Provider: "vercel"},
-        client:    openai.NewClientWithConfig(clientConfig),
+        client:    models.NewOpenAIClient(clientConfig),
         config: Config{
             APIKey:      config.APIKey,
             BaseURL:     baseURL,
@@ -101,6 +101,7 @@ func (v *Vercel) buildChatRequest(req *models.InvokeRequest) openai.ChatCompleti
     temp, max := models.MergeConfig(req.Temperature, v.config.Temperature, req.MaxTokens, v.config.MaxTokens)
     if temp > 0 { chatReq.Temperature = float32(temp) }
     if max > 0 { chatReq.MaxTokens = max }
+    chatReq.ResponseFormat = models.OpenAIResponseFormat(req.ResponseFormat)
     return chatReq
 }
 
@@ -3,8 +3,10 @@ package run
 import "time"
 
 const (
-	EventTypeRunContent   = "run_content"
-	EventTypeRunCompleted = "run_completed"
+	EventTypeRunContent        = "run_content"
+	EventTypeRunCompleted      = "run_completed"
+	EventTypeToolCallStarted   = "tool_call_started"
+	EventTypeToolCallCompleted = "tool_call_completed"
 )
 
 // Events represents a collection of base run output events that can be marshaled
@@ -54,6 +56,31 @@ type RunCompletedEvent struct {
 	Output  string `json:"content,omitempty"`
 }
 
+// ToolCallStartedEvent signals that a tool call requested by the model is
+// about to be executed.
+type ToolCallStartedEvent struct {
+	eventBase
+	RunID      string `json:"run_id,omitempty"`
+	AgentID    string `json:"agent_id,omitempty"`
+	TeamID     string `json:"team_id,omitempty"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	ToolName   string `json:"tool_name,omitempty"`
+	Arguments  string `json:"arguments,omitempty"`
+}
+
+// ToolCallCompletedEvent carries the outcome of a tool call. Error is set
+// when the tool failed; Result then holds the message returned to the model.
+type ToolCallCompletedEvent struct {
+	eventBase
+	RunID      string `json:"run_id,omitempty"`
+	AgentID    string `json:"agent_id,omitempty"`
+	TeamID     string `json:"team_id,omitempty"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	ToolName   string `json:"tool_name,omitempty"`
+	Result     string `json:"result,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
 // NewRunContentEvent constructs a content event for an agent run.
 func NewRunContentEvent(runID, agentID, role, content string, sequence int) *RunContentEvent {
 	return &RunContentEvent{
@@ -85,3 +112,28 @@ func NewRunCompletedEvent(runID, agentID, teamID, status, output string) *RunCom
 		Output:    output,
 	}
 }
+
+// NewToolCallStartedEvent constructs a tool-call start event for an agent run.
+func NewToolCallStartedEvent(runID, agentID, toolCallID, toolName, arguments string) *ToolCallStartedEvent {
+	return &ToolCallStartedEvent{
+		eventBase:  eventBase{eventType: EventTypeToolCallStarted, timestamp: time.Now().UTC()},
+		RunID:      runID,
+		AgentID:    agentID,
+		ToolCallID: toolCallID,
+		ToolName:   toolName,
+		Arguments:  arguments,
+	}
+}
+
+// NewToolCallCompletedEvent constructs a tool-call completion event for an agent run.
+func NewToolCallCompletedEvent(runID, agentID, toolCallID, toolName, result, errMsg string) *ToolCallCompletedEvent {
+	return &ToolCallCompletedEvent{
+		eventBase:  eventBase{eventType: EventTypeToolCallCompleted, timestamp: time.Now().UTC()},
+		RunID:      runID,
+		AgentID:    agentID,
+		ToolCallID: toolCallID,
+		ToolName:   toolName,
+		Result:     result,
+		Error:      errMsg,
+	}
+}
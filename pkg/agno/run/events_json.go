@@ -63,6 +63,18 @@ func decodeEvent(raw json.RawMessage) (BaseRunOutputEvent, error) {
 		kind = strings.ToLower(strings.TrimSpace(meta.EventType))
 	}
 	switch {
+	case strings.Contains(kind, EventTypeToolCallStarted):
+		var evt ToolCallStartedEvent
+		if err := json.Unmarshal(raw, &evt); err != nil {
+			return nil, err
+		}
+		return &evt, nil
+	case strings.Contains(kind, EventTypeToolCallCompleted):
+		var evt ToolCallCompletedEvent
+		if err := json.Unmarshal(raw, &evt); err != nil {
+			return nil, err
+		}
+		return &evt, nil
 	case kind == "" || strings.Contains(kind, "content"):
 		var evt RunContentEvent
 		if err := json.Unmarshal(raw, &evt); err != nil {
@@ -157,6 +169,70 @@ func (e *RunCompletedEvent) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalJSON serializes ToolCallStartedEvent with canonical metadata fields.
+func (e *ToolCallStartedEvent) MarshalJSON() ([]byte, error) {
+	type alias ToolCallStartedEvent
+	payload := struct {
+		Event     string `json:"event"`
+		CreatedAt int64  `json:"created_at"`
+		*alias
+	}{
+		Event:     canonicalEventType(e.eventBase.eventType, EventTypeToolCallStarted),
+		CreatedAt: unixSeconds(e.timestamp),
+		alias:     (*alias)(e),
+	}
+	return json.Marshal(payload)
+}
+
+// UnmarshalJSON hydrates ToolCallStartedEvent from JSON.
+func (e *ToolCallStartedEvent) UnmarshalJSON(data []byte) error {
+	type alias ToolCallStartedEvent
+	aux := struct {
+		*alias
+	}{alias: (*alias)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	meta := map[string]interface{}{}
+	if err := json.Unmarshal(data, &meta); err == nil {
+		e.eventBase.eventType = extractEventKind(meta, EventTypeToolCallStarted)
+		e.eventBase.timestamp = extractTimestamp(meta)
+	}
+	return nil
+}
+
+// MarshalJSON serializes ToolCallCompletedEvent with canonical metadata fields.
+func (e *ToolCallCompletedEvent) MarshalJSON() ([]byte, error) {
+	type alias ToolCallCompletedEvent
+	payload := struct {
+		Event     string `json:"event"`
+		CreatedAt int64  `json:"created_at"`
+		*alias
+	}{
+		Event:     canonicalEventType(e.eventBase.eventType, EventTypeToolCallCompleted),
+		CreatedAt: unixSeconds(e.timestamp),
+		alias:     (*alias)(e),
+	}
+	return json.Marshal(payload)
+}
+
+// UnmarshalJSON hydrates ToolCallCompletedEvent from JSON.
+func (e *ToolCallCompletedEvent) UnmarshalJSON(data []byte) error {
+	type alias ToolCallCompletedEvent
+	aux := struct {
+		*alias
+	}{alias: (*alias)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	meta := map[string]interface{}{}
+	if err := json.Unmarshal(data, &meta); err == nil {
+		e.eventBase.eventType = extractEventKind(meta, EventTypeToolCallCompleted)
+		e.eventBase.timestamp = extractTimestamp(meta)
+	}
+	return nil
+}
+
 // GenericRunEvent preserves unknown event payloads while implementing BaseRunOutputEvent.
 type GenericRunEvent struct {
 	eventBase
@@ -72,3 +72,32 @@ func TestGenericRunEventPreservesPayload(t *testing.T) {
 		t.Fatalf("expected event persisted after roundtrip")
 	}
 }
+
+func TestToolCallEventsRoundTrip(t *testing.T) {
+	events := Events{
+		NewToolCallStartedEvent("run-1", "agent-1", "call-1", "add", `{"a":1}`),
+		NewToolCallCompletedEvent("run-1", "agent-1", "call-1", "add", "2", ""),
+	}
+	data, err := json.Marshal(events)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var decoded Events
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	started, ok := decoded[0].(*ToolCallStartedEvent)
+	if !ok {
+		t.Fatalf("expected ToolCallStartedEvent, got %T", decoded[0])
+	}
+	if started.ToolName != "add" || started.Arguments != `{"a":1}` {
+		t.Fatalf("unexpected started event: %+v", started)
+	}
+	completed, ok := decoded[1].(*ToolCallCompletedEvent)
+	if !ok {
+		t.Fatalf("expected ToolCallCompletedEvent, got %T", decoded[1])
+	}
+	if completed.EventType() != EventTypeToolCallCompleted || completed.Result != "2" {
+		t.Fatalf("unexpected completed event: %+v", completed)
+	}
+}
@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Coerce converts a decoded JSON value towards the types declared by schema
+// where the conversion is lossless:
+//
+//   - numeric strings ("5", "2.5") become numbers for "integer"/"number"
+//   - "true"/"false" strings become booleans for "boolean"
+//   - strings holding a JSON array/object become that value for "array"/"object"
+//
+// Nested object properties, additionalProperties and array items are coerced
+// recursively. Maps and slices are updated in place. changed reports whether
+// any conversion happened; values that cannot be converted are returned as-is
+// so Validate can report them.
+func Coerce(schema map[string]interface{}, value interface{}) (result interface{}, changed bool) {
+	if len(schema) == 0 {
+		return value, false
+	}
+
+	if s, ok := value.(string); ok && !typeAllows(schema["type"], "string") {
+		if converted, ok := coerceString(schema["type"], s); ok {
+			value, changed = converted, true
+		}
+	}
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		for key, item := range val {
+			propSchema, ok := properties[key].(map[string]interface{})
+			if !ok {
+				propSchema = additional
+			}
+			if propSchema == nil {
+				continue
+			}
+			if converted, itemChanged := Coerce(propSchema, item); itemChanged {
+				val[key] = converted
+				changed = true
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range val {
+				if converted, itemChanged := Coerce(items, item); itemChanged {
+					val[i] = converted
+					changed = true
+				}
+			}
+		}
+	}
+
+	return value, changed
+}
+
+// coerceString tries each declared type in order and returns the first
+// successful conversion of s.
+func coerceString(typ interface{}, s string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(s)
+	for _, t := range typeList(typ) {
+		switch t {
+		case "integer":
+			if n, err := strconv.ParseFloat(trimmed, 64); err == nil && n == math.Trunc(n) && !math.IsInf(n, 0) {
+				return n, true
+			}
+		case "number":
+			if n, err := strconv.ParseFloat(trimmed, 64); err == nil && !math.IsInf(n, 0) && !math.IsNaN(n) {
+				return n, true
+			}
+		case "boolean":
+			switch strings.ToLower(trimmed) {
+			case "true":
+				return true, true
+			case "false":
+				return false, true
+			}
+		case "array":
+			if strings.HasPrefix(trimmed, "[") {
+				var arr []interface{}
+				if err := json.Unmarshal([]byte(trimmed), &arr); err == nil {
+					return arr, true
+				}
+			}
+		case "object":
+			if strings.HasPrefix(trimmed, "{") {
+				var obj map[string]interface{}
+				if err := json.Unmarshal([]byte(trimmed), &obj); err == nil {
+					return obj, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// typeAllows reports whether the schema type declaration permits want.
+// A missing type allows everything.
+func typeAllows(typ interface{}, want string) bool {
+	types := typeList(typ)
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == want {
+			return true
+		}
+	}
+	return false
+}
+
+func typeList(typ interface{}) []string {
+	if s, ok := typ.(string); ok {
+		if s == "" {
+			return nil
+		}
+		return []string{s}
+	}
+	return stringList(typ)
+}
@@ -0,0 +1,57 @@
+package schema
+
+// ApplyDefaults fills missing object properties that declare a "default"
+// value, recursing into nested objects and array items. value is modified
+// in place; values of other shapes are returned unchanged.
+func ApplyDefaults(schema map[string]interface{}, value interface{}) interface{} {
+	switch val := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, raw := range properties {
+			propSchema, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			current, exists := val[name]
+			if !exists {
+				def, hasDefault := propSchema["default"]
+				if !hasDefault {
+					continue
+				}
+				current = copyValue(def)
+			}
+			val[name] = ApplyDefaults(propSchema, current)
+		}
+		return val
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range val {
+				val[i] = ApplyDefaults(items, item)
+			}
+		}
+		return val
+	default:
+		return value
+	}
+}
+
+// copyValue deep-copies decoded JSON containers so defaults stored in a
+// schema are never shared with (and mutated through) argument values.
+func copyValue(value interface{}) interface{} {
+	switch val := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, v := range val {
+			out[k] = copyValue(v)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, v := range val {
+			out[i] = copyValue(v)
+		}
+		return out
+	default:
+		return value
+	}
+}
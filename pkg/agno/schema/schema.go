@@ -0,0 +1,266 @@
+// Package schema builds JSON Schema documents from Go types and validates
+// decoded JSON values against them. It is shared by structured agent output
+// and tool argument handling.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// FromValue generates a JSON schema for the dynamic type of v.
+// Pointers are dereferenced, so both T{} and (*T)(nil) are accepted.
+func FromValue(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, fmt.Errorf("schema: cannot derive schema from nil")
+	}
+	return FromType(reflect.TypeOf(v))
+}
+
+// FromType generates a JSON schema for t.
+//
+// Struct fields are mapped using their `json` tag names; fields without
+// `omitempty` are required. The following additional tags are understood:
+//
+//	description:"..."  field description
+//	enum:"a,b,c"       allowed values (applied to the items of slice fields)
+//	default:"..."      default value, parsed as JSON unless the field is a string
+//	minimum:"n"        inclusive lower bound for numeric fields
+//	maximum:"n"        inclusive upper bound for numeric fields
+func FromType(t reflect.Type) (map[string]interface{}, error) {
+	if t == nil {
+		return nil, fmt.Errorf("schema: nil type")
+	}
+	return fromType(t, map[reflect.Type]bool{})
+}
+
+func fromType(t reflect.Type, visiting map[reflect.Type]bool) (map[string]interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case rawMessageType:
+		return map[string]interface{}{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as a base64 string by encoding/json.
+			return map[string]interface{}{"type": "string"}, nil
+		}
+		items, err := fromType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("schema: unsupported map key type %s", t.Key())
+		}
+		values, err := fromType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		out := map[string]interface{}{"type": "object"}
+		if len(values) > 0 {
+			out["additionalProperties"] = values
+		}
+		return out, nil
+	case reflect.Struct:
+		if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+			return map[string]interface{}{}, nil
+		}
+		if visiting[t] {
+			return nil, fmt.Errorf("schema: recursive type %s is not supported", t)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		return structSchema(t, visiting)
+	default:
+		return nil, fmt.Errorf("schema: unsupported type %s", t)
+	}
+}
+
+func structSchema(t reflect.Type, visiting map[reflect.Type]bool) (map[string]interface{}, error) {
+	properties := map[string]interface{}{}
+	var required []string
+
+	if err := collectFields(t, visiting, properties, &required); err != nil {
+		return nil, err
+	}
+
+	out := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		out["required"] = required
+	}
+	return out, nil
+}
+
+func collectFields(t reflect.Type, visiting map[reflect.Type]bool, properties map[string]interface{}, required *[]string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		// Embedded structs without an explicit name are flattened, matching
+		// encoding/json behaviour.
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := collectFields(ft, visiting, properties, required); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop, err := fromType(field.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if err := applyFieldTags(prop, field); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		properties[name] = prop
+		if !omitEmpty {
+			*required = append(*required, name)
+		}
+	}
+	return nil
+}
+
+// jsonFieldName returns the JSON name of a struct field and whether it is
+// marked omitempty. skip is true for fields tagged `json:"-"`.
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return "", false, false
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitEmpty = true
+		}
+	}
+	return parts[0], omitEmpty, false
+}
+
+func applyFieldTags(prop map[string]interface{}, field reflect.StructField) error {
+	if desc := field.Tag.Get("description"); desc != "" {
+		prop["description"] = desc
+	}
+	if enum := field.Tag.Get("enum"); enum != "" {
+		target, elem := prop, field.Type
+		if items, ok := prop["items"].(map[string]interface{}); ok {
+			target, elem = items, sliceElem(field.Type)
+		}
+		values, err := parseTagValues(enum, elem)
+		if err != nil {
+			return fmt.Errorf("invalid enum tag: %w", err)
+		}
+		target["enum"] = values
+	}
+	if def, ok := field.Tag.Lookup("default"); ok {
+		value, err := parseTagValue(def, field.Type)
+		if err != nil {
+			return fmt.Errorf("invalid default tag: %w", err)
+		}
+		prop["default"] = value
+	}
+	for _, key := range []string{"minimum", "maximum"} {
+		raw, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		bound, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag %q", key, raw)
+		}
+		prop[key] = bound
+	}
+	return nil
+}
+
+func sliceElem(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return t.Elem()
+	}
+	return t
+}
+
+// parseTagValues splits a comma-separated tag value and converts each item
+// to the JSON representation matching t.
+func parseTagValues(raw string, t reflect.Type) ([]interface{}, error) {
+	parts := strings.Split(raw, ",")
+	values := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		v, err := parseTagValue(strings.TrimSpace(part), t)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func parseTagValue(raw string, t reflect.Type) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	default:
+		var v interface{}
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, fmt.Errorf("cannot parse %q as %s", raw, t)
+		}
+		return v, nil
+	}
+}
@@ -0,0 +1,267 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type address struct {
+	City string `json:"city" description:"City name"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type person struct {
+	Name     string          `json:"name"`
+	Age      int             `json:"age"`
+	Role     string          `json:"role" enum:"admin,user"`
+	Tags     []string        `json:"tags,omitempty"`
+	Address  *address        `json:"address,omitempty"`
+	Extra    map[string]int  `json:"extra,omitempty"`
+	Born     time.Time       `json:"born,omitempty"`
+	Raw      json.RawMessage `json:"raw,omitempty"`
+	Ignored  string          `json:"-"`
+	private  string
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func TestFromType_Struct(t *testing.T) {
+	s, err := FromValue(&person{})
+	if err != nil {
+		t.Fatalf("FromValue() error = %v", err)
+	}
+	if s["type"] != "object" {
+		t.Fatalf("expected object schema, got %v", s["type"])
+	}
+
+	props := s["properties"].(map[string]interface{})
+	if _, ok := props["Ignored"]; ok {
+		t.Fatal("json:\"-\" field should be skipped")
+	}
+	if _, ok := props["private"]; ok {
+		t.Fatal("unexported field should be skipped")
+	}
+
+	required := s["required"].([]string)
+	if !reflect.DeepEqual(required, []string{"name", "age", "role"}) {
+		t.Fatalf("unexpected required list: %v", required)
+	}
+
+	role := props["role"].(map[string]interface{})
+	if !reflect.DeepEqual(role["enum"], []interface{}{"admin", "user"}) {
+		t.Fatalf("unexpected enum: %v", role["enum"])
+	}
+
+	addr := props["address"].(map[string]interface{})
+	city := addr["properties"].(map[string]interface{})["city"].(map[string]interface{})
+	if city["description"] != "City name" {
+		t.Fatalf("expected description tag, got %v", city["description"])
+	}
+
+	born := props["born"].(map[string]interface{})
+	if born["format"] != "date-time" {
+		t.Fatalf("expected date-time format, got %v", born["format"])
+	}
+
+	extra := props["extra"].(map[string]interface{})
+	if extra["additionalProperties"].(map[string]interface{})["type"] != "integer" {
+		t.Fatalf("unexpected map schema: %v", extra)
+	}
+}
+
+type node struct {
+	Next *node `json:"next"`
+}
+
+func TestFromType_Errors(t *testing.T) {
+	if _, err := FromValue(nil); err == nil {
+		t.Fatal("expected error for nil value")
+	}
+	if _, err := FromValue(node{}); err == nil {
+		t.Fatal("expected error for recursive type")
+	}
+	if _, err := FromValue(map[int]string{}); err == nil {
+		t.Fatal("expected error for non-string map keys")
+	}
+	if _, err := FromValue(make(chan int)); err == nil {
+		t.Fatal("expected error for channel type")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s, err := FromValue(person{})
+	if err != nil {
+		t.Fatalf("FromValue() error = %v", err)
+	}
+
+	decode := func(raw string) interface{} {
+		var v interface{}
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			t.Fatalf("bad fixture: %v", err)
+		}
+		return v
+	}
+
+	if err := Validate(s, decode(`{"name":"Ann","age":30,"role":"admin","tags":["a"]}`)); err != nil {
+		t.Fatalf("expected valid value, got %v", err)
+	}
+
+	err = Validate(s, decode(`{"name":"Ann","age":1.5,"role":"root","tags":[1]}`))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	paths := map[string]bool{}
+	for _, issue := range verr.Issues {
+		paths[issue.Path] = true
+	}
+	for _, want := range []string{"$.age", "$.role", "$.tags[0]"} {
+		if !paths[want] {
+			t.Errorf("expected issue at %s, got %+v", want, verr.Issues)
+		}
+	}
+
+	if err := Validate(s, decode(`{"name":"Ann"}`)); err == nil {
+		t.Fatal("expected missing required properties to fail")
+	}
+	if err := Validate(s, decode(`[]`)); err == nil {
+		t.Fatal("expected type mismatch to fail")
+	}
+}
+
+func TestValidate_AdditionalProperties(t *testing.T) {
+	s := map[string]interface{}{
+		"type":                 "object",
+		"properties":           map[string]interface{}{"a": map[string]interface{}{"type": "string"}},
+		"additionalProperties": false,
+	}
+	if err := Validate(s, map[string]interface{}{"a": "x", "b": 1.0}); err == nil {
+		t.Fatal("expected additional property to be rejected")
+	}
+
+	s["type"] = []interface{}{"object", "null"}
+	if err := Validate(s, nil); err != nil {
+		t.Fatalf("expected null to match type union, got %v", err)
+	}
+}
+
+type options struct {
+	Mode  string   `json:"mode,omitempty" default:"fast"`
+	Depth int      `json:"depth,omitempty" default:"2"`
+	Tags  []string `json:"tags,omitempty" enum:"a,b" default:"[\"a\"]"`
+	Inner *struct {
+		Flag bool `json:"flag,omitempty" default:"true"`
+	} `json:"inner,omitempty" default:"{}"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	s, err := FromValue(options{})
+	if err != nil {
+		t.Fatalf("FromValue() error = %v", err)
+	}
+
+	props := s["properties"].(map[string]interface{})
+	tags := props["tags"].(map[string]interface{})
+	if !reflect.DeepEqual(tags["items"].(map[string]interface{})["enum"], []interface{}{"a", "b"}) {
+		t.Fatalf("expected enum on slice items, got %v", tags)
+	}
+
+	value := ApplyDefaults(s, map[string]interface{}{"depth": 7.0})
+	want := map[string]interface{}{
+		"mode":  "fast",
+		"depth": 7.0,
+		"tags":  []interface{}{"a"},
+		"inner": map[string]interface{}{"flag": true},
+	}
+	if !reflect.DeepEqual(value, want) {
+		t.Fatalf("ApplyDefaults() = %v, want %v", value, want)
+	}
+
+	// Defaults stored in the schema must not be mutated by later calls.
+	if inner := props["inner"].(map[string]interface{})["default"].(map[string]interface{}); len(inner) != 0 {
+		t.Fatalf("schema default was mutated: %v", inner)
+	}
+}
+
+func TestValidate_Ranges(t *testing.T) {
+	s := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"age":   map[string]interface{}{"type": "integer", "minimum": 0.0, "maximum": 150.0},
+			"ratio": map[string]interface{}{"type": "number", "exclusiveMinimum": 0.0},
+			"code":  map[string]interface{}{"type": "string", "pattern": "^[A-Z]{3}$", "maxLength": 3},
+			"tags":  map[string]interface{}{"type": "array", "minItems": 1, "items": map[string]interface{}{"type": "string"}},
+			"id": map[string]interface{}{"oneOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				map[string]interface{}{"type": "integer"},
+			}},
+		},
+	}
+
+	ok := map[string]interface{}{"age": 30.0, "ratio": 0.5, "code": "ABC", "tags": []interface{}{"x"}, "id": 7.0}
+	if err := Validate(s, ok); err != nil {
+		t.Fatalf("expected valid value, got %v", err)
+	}
+
+	bad := map[string]interface{}{"age": 200.0, "ratio": 0.0, "code": "abcd", "tags": []interface{}{}, "id": true}
+	var verr *ValidationError
+	if !errors.As(Validate(s, bad), &verr) {
+		t.Fatal("expected ValidationError")
+	}
+	paths := map[string]int{}
+	for _, issue := range verr.Issues {
+		paths[issue.Path]++
+	}
+	for _, want := range []string{"$.age", "$.ratio", "$.code", "$.tags", "$.id"} {
+		if paths[want] == 0 {
+			t.Errorf("expected issue at %s, got %+v", want, verr.Issues)
+		}
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	s := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"count":  map[string]interface{}{"type": "integer"},
+			"ratio":  map[string]interface{}{"type": "number"},
+			"flag":   map[string]interface{}{"type": "boolean"},
+			"name":   map[string]interface{}{"type": "string"},
+			"ids":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}},
+			"nested": map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "number"}},
+		},
+	}
+
+	value := map[string]interface{}{
+		"count":  "5",
+		"ratio":  " 2.5 ",
+		"flag":   "TRUE",
+		"name":   "42",
+		"ids":    `[1, "2"]`,
+		"nested": map[string]interface{}{"a": "1.5"},
+	}
+	got, changed := Coerce(s, value)
+	if !changed {
+		t.Fatal("expected coercion to report changes")
+	}
+	want := map[string]interface{}{
+		"count":  5.0,
+		"ratio":  2.5,
+		"flag":   true,
+		"name":   "42",
+		"ids":    []interface{}{1.0, 2.0},
+		"nested": map[string]interface{}{"a": 1.5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Coerce() = %v, want %v", got, want)
+	}
+
+	// Lossy conversions are left for Validate to report.
+	got, changed = Coerce(map[string]interface{}{"type": "integer"}, "2.5")
+	if changed || got != "2.5" {
+		t.Fatalf("expected non-integral string to be left unchanged, got %v", got)
+	}
+}
@@ -0,0 +1,342 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Issue describes a single schema violation.
+type Issue struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every issue found while validating a value.
+type ValidationError struct {
+	Issues []Issue `json:"issues"`
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	if e == nil || len(e.Issues) == 0 {
+		return "schema validation failed"
+	}
+	parts := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		parts[i] = fmt.Sprintf("%s: %s", issue.Path, issue.Message)
+	}
+	return "schema validation failed: " + strings.Join(parts, "; ")
+}
+
+// Validate checks a decoded JSON value (as produced by encoding/json into an
+// interface{}) against schema. It returns a *ValidationError listing every
+// violation, or nil when the value conforms.
+func Validate(schema map[string]interface{}, value interface{}) error {
+	v := &validator{}
+	v.validate("$", schema, value)
+	if len(v.issues) > 0 {
+		return &ValidationError{Issues: v.issues}
+	}
+	return nil
+}
+
+type validator struct {
+	issues []Issue
+}
+
+func (v *validator) addf(path, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate(path string, schema map[string]interface{}, value interface{}) {
+	if len(schema) == 0 {
+		return
+	}
+
+	if typ, ok := schema["type"]; ok && !matchesType(typ, value) {
+		v.addf(path, "expected %s, got %s", describeType(typ), jsonTypeOf(value))
+		return
+	}
+
+	if enum, ok := schema["enum"]; ok && !enumContains(enum, value) {
+		v.addf(path, "value %v is not one of %v", value, enum)
+	}
+
+	v.validateAlternatives(path, schema, value)
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(path, schema, val)
+	case []interface{}:
+		v.validateArray(path, schema, val)
+	case string:
+		v.validateString(path, schema, val)
+	default:
+		if n, ok := toFloat(value); ok {
+			v.validateNumber(path, schema, n)
+		}
+	}
+}
+
+func (v *validator) validateNumber(path string, schema map[string]interface{}, n float64) {
+	if min, ok := toFloat(schema["minimum"]); ok && n < min {
+		v.addf(path, "value %v is less than minimum %v", n, min)
+	}
+	if max, ok := toFloat(schema["maximum"]); ok && n > max {
+		v.addf(path, "value %v is greater than maximum %v", n, max)
+	}
+	if min, ok := toFloat(schema["exclusiveMinimum"]); ok && n <= min {
+		v.addf(path, "value %v must be greater than %v", n, min)
+	}
+	if max, ok := toFloat(schema["exclusiveMaximum"]); ok && n >= max {
+		v.addf(path, "value %v must be less than %v", n, max)
+	}
+}
+
+func (v *validator) validateString(path string, schema map[string]interface{}, s string) {
+	length := utf8.RuneCountInString(s)
+	if min, ok := toFloat(schema["minLength"]); ok && float64(length) < min {
+		v.addf(path, "length %d is shorter than minLength %v", length, min)
+	}
+	if max, ok := toFloat(schema["maxLength"]); ok && float64(length) > max {
+		v.addf(path, "length %d is longer than maxLength %v", length, max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok && pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.addf(path, "invalid pattern %q in schema", pattern)
+		} else if !re.MatchString(s) {
+			v.addf(path, "value %q does not match pattern %q", s, pattern)
+		}
+	}
+}
+
+func (v *validator) validateArray(path string, schema map[string]interface{}, arr []interface{}) {
+	if min, ok := toFloat(schema["minItems"]); ok && float64(len(arr)) < min {
+		v.addf(path, "array has %d items, fewer than minItems %v", len(arr), min)
+	}
+	if max, ok := toFloat(schema["maxItems"]); ok && float64(len(arr)) > max {
+		v.addf(path, "array has %d items, more than maxItems %v", len(arr), max)
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range arr {
+			v.validate(fmt.Sprintf("%s[%d]", path, i), items, item)
+		}
+	}
+}
+
+// validateAlternatives checks oneOf (exactly one match) and anyOf (at least
+// one match). Issues from the alternatives themselves are not reported.
+func (v *validator) validateAlternatives(path string, schema map[string]interface{}, value interface{}) {
+	countMatches := func(raw interface{}) (int, bool) {
+		list, ok := raw.([]interface{})
+		if !ok || len(list) == 0 {
+			return 0, false
+		}
+		matches := 0
+		for _, item := range list {
+			alt, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			sub := &validator{}
+			sub.validate(path, alt, value)
+			if len(sub.issues) == 0 {
+				matches++
+			}
+		}
+		return matches, true
+	}
+
+	if matches, ok := countMatches(schema["oneOf"]); ok && matches != 1 {
+		v.addf(path, "value must match exactly one schema in oneOf, matched %d", matches)
+	}
+	if matches, ok := countMatches(schema["anyOf"]); ok && matches == 0 {
+		v.addf(path, "value does not match any schema in anyOf")
+	}
+}
+
+func (v *validator) validateObject(path string, schema map[string]interface{}, obj map[string]interface{}) {
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := obj[name]; !ok {
+			v.addf(joinPath(path, name), "required property is missing")
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	additional := schema["additionalProperties"]
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if propSchema, ok := properties[key].(map[string]interface{}); ok {
+			v.validate(joinPath(path, key), propSchema, obj[key])
+			continue
+		}
+		switch add := additional.(type) {
+		case bool:
+			if !add {
+				v.addf(joinPath(path, key), "additional property is not allowed")
+			}
+		case map[string]interface{}:
+			v.validate(joinPath(path, key), add, obj[key])
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	return path + "." + key
+}
+
+func matchesType(typ interface{}, value interface{}) bool {
+	switch t := typ.(type) {
+	case string:
+		return matchesSingleType(t, value)
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok && matchesSingleType(s, value) {
+				return true
+			}
+		}
+		return false
+	case []string:
+		for _, s := range t {
+			if matchesSingleType(s, value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func matchesSingleType(typ string, value interface{}) bool {
+	switch typ {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		f, ok := toFloat(value)
+		return ok && f == math.Trunc(f)
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	default:
+		return true
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	if f, ok := toFloat(value); ok {
+		if f == math.Trunc(f) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func describeType(typ interface{}) string {
+	switch t := typ.(type) {
+	case string:
+		return t
+	case []string:
+		return strings.Join(t, " or ")
+	case []interface{}:
+		return strings.Join(stringList(t), " or ")
+	default:
+		return fmt.Sprintf("%v", typ)
+	}
+}
+
+func enumContains(enum interface{}, value interface{}) bool {
+	rv := reflect.ValueOf(enum)
+	if rv.Kind() != reflect.Slice {
+		return true
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if valuesEqual(rv.Index(i).Interface(), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func valuesEqual(a, b interface{}) bool {
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		return ok && af == bf
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func stringList(raw interface{}) []string {
+	switch v := raw.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
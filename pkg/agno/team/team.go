@@ -623,6 +623,13 @@ func (t *Team) invokeAgent(ctx context.Context, ag *agent.Agent, input string) (
 	if output != nil && len(output.Events) > 0 {
 		annotateEventsWithTeam(t.ID, output.Events)
 	}
+	// Team members cannot ask for tool-call confirmation: cancel the paused
+	// run so the agent stays usable and report the pause as a failure
+	// 团队成员无法确认工具调用：取消暂停的运行并将其视为失败
+	if pausedErr := output.PausedError(); err == nil && pausedErr != nil {
+		_ = ag.CancelPausedRun(output.RunID)
+		return nil, fmt.Errorf("agent %s: %w", ag.ID, pausedErr)
+	}
 	return output, err
 }
 
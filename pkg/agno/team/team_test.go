@@ -2,11 +2,13 @@ package team
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
 	"github.com/rexleimo/agno-go/pkg/agno/agent"
 	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/tools/toolkit"
 	"github.com/rexleimo/agno-go/pkg/agno/types"
 )
 
@@ -52,6 +54,40 @@ func createMockAgent(id string, responseContent string) *agent.Agent {
 	return ag
 }
 
+// createConfirmingAgent returns an agent whose model calls a tool that
+// requires confirmation until it sees a tool result.
+func createConfirmingAgent(id string) *agent.Agent {
+	tk := toolkit.NewBaseToolkit("confirm")
+	tk.RegisterFunction(&toolkit.Function{
+		Name:                 "delete",
+		Description:          "delete",
+		RequiresConfirmation: true,
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "deleted", nil
+		},
+	})
+	model := &MockModel{
+		BaseModel: models.BaseModel{ID: id, Provider: "mock"},
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			for _, msg := range req.Messages {
+				if msg.Role == types.RoleTool {
+					return &types.ModelResponse{Content: "done"}, nil
+				}
+			}
+			return &types.ModelResponse{ToolCalls: []types.ToolCall{
+				{ID: "call_delete", Type: "function", Function: types.ToolCallFunction{Name: "delete", Arguments: `{}`}},
+			}}, nil
+		},
+	}
+
+	ag, _ := agent.New(agent.Config{
+		ID:       id,
+		Model:    model,
+		Toolkits: []toolkit.Toolkit{tk},
+	})
+	return ag
+}
+
 func constantModel(id, content string) *MockModel {
 	return &MockModel{
 		BaseModel: models.BaseModel{ID: id, Provider: "mock"},
@@ -628,3 +664,27 @@ func TestTeam_RunAttachesRunContextToAgents(t *testing.T) {
 		t.Fatalf("expected team_id %q in run_context, got %#v", team.ID, rcMap["team_id"])
 	}
 }
+
+func TestTeam_AgentPausedForConfirmation(t *testing.T) {
+	confirming := createConfirmingAgent("confirming")
+	team, err := New(Config{
+		Name:   "confirm-team",
+		Agents: []*agent.Agent{confirming},
+		Mode:   ModeSequential,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create team: %v", err)
+	}
+
+	_, err = team.Run(context.Background(), "clean up")
+	var pausedErr *agent.PausedRunError
+	if !errors.As(err, &pausedErr) {
+		t.Fatalf("Run() error = %v, want PausedRunError", err)
+	}
+
+	// The paused run was cancelled, so the agent can run again
+	output, err := confirming.Run(context.Background(), "again")
+	if err != nil || output.Content != "done" {
+		t.Fatalf("agent Run() after pause = %v, %v", output, err)
+	}
+}
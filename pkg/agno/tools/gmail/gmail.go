@@ -24,6 +24,10 @@ type Config struct {
 	UserID      string
 	HTTPClient  *http.Client
 	Timeout     time.Duration
+
+	// RequireConfirmation 使 agent 在执行 gmail_mark_as_read 前暂停，等待人工确认。
+	// RequireConfirmation pauses agents before gmail_mark_as_read until a human confirms the call.
+	RequireConfirmation bool
 }
 
 // Toolkit 提供 Gmail 标记已读功能。
@@ -98,7 +102,8 @@ func New(cfg Config) (*Toolkit, error) {
 				Description: "Optional Gmail user ID (defaults to 'me')",
 			},
 		},
-		Handler: tk.markAsRead,
+		Handler:              tk.markAsRead,
+		RequiresConfirmation: cfg.RequireConfirmation,
 	})
 
 	return tk, nil
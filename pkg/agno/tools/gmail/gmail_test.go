@@ -50,3 +50,21 @@ func TestMarkAsRead(t *testing.T) {
 		t.Fatalf("unexpected status %v", data["status"])
 	}
 }
+
+func TestNew_RequireConfirmation(t *testing.T) {
+	tk, err := New(Config{AccessToken: "token"})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	if tk.Functions()["gmail_mark_as_read"].RequiresConfirmation {
+		t.Fatal("confirmation should be opt-in")
+	}
+
+	tk, err = New(Config{AccessToken: "token", RequireConfirmation: true})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	if !tk.Functions()["gmail_mark_as_read"].RequiresConfirmation {
+		t.Fatal("expected gmail_mark_as_read to require confirmation")
+	}
+}
@@ -26,6 +26,14 @@ func TestNew(t *testing.T) {
 	if _, exists := functions["http_post"]; !exists {
 		t.Error("http_post function not found")
 	}
+
+	// Confirmation is opt-in
+	if functions["http_post"].RequiresConfirmation {
+		t.Error("http_post should not require confirmation by default")
+	}
+	if err := toolkit.RequireConfirmation("http_post"); err != nil || !functions["http_post"].RequiresConfirmation {
+		t.Errorf("RequireConfirmation(http_post) = %v", err)
+	}
 }
 
 func TestHTTPToolkit_Get(t *testing.T) {
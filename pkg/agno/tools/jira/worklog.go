@@ -18,6 +18,10 @@ type Config struct {
 	AuthToken  string
 	HTTPClient *http.Client
 	Timeout    time.Duration
+
+	// RequireConfirmation 使 agent 在执行 jira_add_worklog 前暂停，等待人工确认。
+	// RequireConfirmation pauses agents before jira_add_worklog until a human confirms the call.
+	RequireConfirmation bool
 }
 
 // Toolkit 提供 Jira 工时登记功能。
@@ -98,7 +102,8 @@ func New(cfg Config) (*Toolkit, error) {
 				Description: "Optional comment to store with the worklog",
 			},
 		},
-		Handler: tk.addWorklog,
+		Handler:              tk.addWorklog,
+		RequiresConfirmation: cfg.RequireConfirmation,
 	})
 
 	return tk, nil
@@ -60,3 +60,21 @@ func TestAddWorklog(t *testing.T) {
 		t.Fatalf("unexpected id %v", data["id"])
 	}
 }
+
+func TestNew_RequireConfirmation(t *testing.T) {
+	tk, err := New(Config{BaseURL: "https://jira.example.com", AuthToken: "token"})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	if tk.Functions()["jira_add_worklog"].RequiresConfirmation {
+		t.Fatal("confirmation should be opt-in")
+	}
+
+	tk, err = New(Config{BaseURL: "https://jira.example.com", AuthToken: "token", RequireConfirmation: true})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	if !tk.Functions()["jira_add_worklog"].RequiresConfirmation {
+		t.Fatal("expected jira_add_worklog to require confirmation")
+	}
+}
@@ -37,6 +37,11 @@ func New() *PandasToolkit {
 				Type:        "object",
 				Description: "Data in format {\"column1\": [values], \"column2\": [values]}",
 				Required:    true,
+				AdditionalProperties: &toolkit.Parameter{
+					Type:        "array",
+					Description: "Column values, one per row",
+					Items:       &toolkit.Parameter{},
+				},
 			},
 		},
 		Handler: t.createDataFrame,
@@ -47,11 +52,7 @@ func New() *PandasToolkit {
 		Name:        "dataframe_info",
 		Description: "Get information about a DataFrame",
 		Parameters: map[string]toolkit.Parameter{
-			"dataframe": {
-				Type:        "object",
-				Description: "DataFrame object",
-				Required:    true,
-			},
+			"dataframe": dataframeParameter(),
 		},
 		Handler: t.dataframeInfo,
 	})
@@ -61,16 +62,13 @@ func New() *PandasToolkit {
 		Name:        "dataframe_head",
 		Description: "Get the first n rows of a DataFrame",
 		Parameters: map[string]toolkit.Parameter{
-			"dataframe": {
-				Type:        "object",
-				Description: "DataFrame object",
-				Required:    true,
-			},
+			"dataframe": dataframeParameter(),
 			"n": {
 				Type:        "integer",
 				Description: "Number of rows to return (default: 5)",
 				Required:    false,
 				Default:     5,
+				Minimum:     toolkit.Float(0),
 			},
 		},
 		Handler: t.dataframeHead,
@@ -81,15 +79,28 @@ func New() *PandasToolkit {
 		Name:        "dataframe_filter",
 		Description: "Filter DataFrame rows based on conditions",
 		Parameters: map[string]toolkit.Parameter{
-			"dataframe": {
-				Type:        "object",
-				Description: "DataFrame object",
-				Required:    true,
-			},
+			"dataframe": dataframeParameter(),
 			"conditions": {
 				Type:        "object",
 				Description: "Filter conditions (e.g., {\"column\": \"age\", \"operator\": \">\", \"value\": 30})",
 				Required:    true,
+				Properties: map[string]toolkit.Parameter{
+					"column": {
+						Type:        "string",
+						Description: "Column to compare",
+						Required:    true,
+					},
+					"operator": {
+						Type:        "string",
+						Description: "Comparison operator",
+						Required:    true,
+						Enum:        []interface{}{"==", "!=", ">", ">=", "<", "<=", "contains", "starts_with", "ends_with"},
+					},
+					"value": {
+						Description: "Value to compare against",
+						Required:    true,
+					},
+				},
 			},
 		},
 		Handler: t.dataframeFilter,
@@ -100,11 +111,7 @@ func New() *PandasToolkit {
 		Name:        "dataframe_describe",
 		Description: "Generate descriptive statistics for numerical columns",
 		Parameters: map[string]toolkit.Parameter{
-			"dataframe": {
-				Type:        "object",
-				Description: "DataFrame object",
-				Required:    true,
-			},
+			"dataframe": dataframeParameter(),
 		},
 		Handler: t.dataframeDescribe,
 	})
@@ -114,20 +121,22 @@ func New() *PandasToolkit {
 		Name:        "dataframe_groupby",
 		Description: "Group DataFrame by specified columns and apply aggregation",
 		Parameters: map[string]toolkit.Parameter{
-			"dataframe": {
-				Type:        "object",
-				Description: "DataFrame object",
-				Required:    true,
-			},
+			"dataframe": dataframeParameter(),
 			"by": {
 				Type:        "array",
 				Description: "Columns to group by",
 				Required:    true,
+				Items:       &toolkit.Parameter{Type: "string"},
+				MinItems:    toolkit.Int(1),
 			},
 			"agg": {
 				Type:        "object",
 				Description: "Aggregation functions (e.g., {\"age\": \"mean\", \"salary\": \"sum\"})",
 				Required:    true,
+				AdditionalProperties: &toolkit.Parameter{
+					Type: "string",
+					Enum: []interface{}{"sum", "mean", "count", "min", "max"},
+				},
 			},
 		},
 		Handler: t.dataframeGroupBy,
@@ -136,6 +145,29 @@ func New() *PandasToolkit {
 	return t
 }
 
+// dataframeParameter describes the DataFrame object returned by create_dataframe
+func dataframeParameter() toolkit.Parameter {
+	return toolkit.Parameter{
+		Type:        "object",
+		Description: "DataFrame object",
+		Required:    true,
+		Properties: map[string]toolkit.Parameter{
+			"columns": {
+				Type:        "array",
+				Description: "Column names",
+				Required:    true,
+				Items:       &toolkit.Parameter{Type: "string"},
+			},
+			"data": {
+				Type:        "array",
+				Description: "Rows keyed by column name",
+				Required:    true,
+				Items:       &toolkit.Parameter{Type: "object"},
+			},
+		},
+	}
+}
+
 // createDataFrame creates a DataFrame from structured data
 func (p *PandasToolkit) createDataFrame(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	dataArg, ok := args["data"].(map[string]interface{})
@@ -201,7 +233,7 @@ func (p *PandasToolkit) dataframeInfo(ctx context.Context, args map[string]inter
 	columnInfo := make([]map[string]interface{}, len(columns))
 	for i, column := range columns {
 		info := map[string]interface{}{
-			"column": column,
+			"column":         column,
 			"non_null_count": 0,
 			"null_count":     0,
 			"dtype":          "object", // Default to object type
@@ -304,7 +336,7 @@ func (p *PandasToolkit) dataframeFilter(ctx context.Context, args map[string]int
 	}
 
 	return map[string]interface{}{
-		"columns":        dataframe["columns"],
+		"columns":       dataframe["columns"],
 		"data":          filteredData,
 		"shape":         []int{len(filteredData), len(dataframe["columns"].([]string))},
 		"original_rows": len(data),
@@ -359,7 +391,7 @@ func (p *PandasToolkit) dataframeDescribe(ctx context.Context, args map[string]i
 	}
 
 	return map[string]interface{}{
-		"statistics": stats,
+		"statistics":      stats,
 		"numeric_columns": len(stats),
 	}, nil
 }
@@ -652,4 +684,4 @@ func startsWith(s, prefix string) bool {
 
 func endsWith(s, suffix string) bool {
 	return len(s) >= len(suffix) && s[len(s)-len(suffix):] == suffix
-}
\ No newline at end of file
+}
@@ -0,0 +1,146 @@
+package toolkit
+
+import "encoding/json"
+
+// ParametersFromSchema converts the properties of an "object" JSON schema to
+// a Parameter set, marking the properties listed in "required"
+func ParametersFromSchema(objSchema map[string]interface{}) map[string]Parameter {
+	properties, _ := objSchema["properties"].(map[string]interface{})
+	if len(properties) == 0 {
+		return map[string]Parameter{}
+	}
+
+	required := make(map[string]bool)
+	switch list := objSchema["required"].(type) {
+	case []string:
+		for _, name := range list {
+			required[name] = true
+		}
+	case []interface{}:
+		for _, item := range list {
+			if name, ok := item.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	params := make(map[string]Parameter, len(properties))
+	for name, raw := range properties {
+		propSchema, _ := raw.(map[string]interface{})
+		param := parameterFromSchema(propSchema)
+		param.Required = required[name]
+		params[name] = param
+	}
+	return params
+}
+
+// parameterFromSchema converts a single property schema to a Parameter
+func parameterFromSchema(propSchema map[string]interface{}) Parameter {
+	param := Parameter{Type: schemaType(propSchema["type"])}
+	param.Description, _ = propSchema["description"].(string)
+	param.Format, _ = propSchema["format"].(string)
+	param.Pattern, _ = propSchema["pattern"].(string)
+
+	switch enum := propSchema["enum"].(type) {
+	case []interface{}:
+		param.Enum = append([]interface{}(nil), enum...)
+	case []string:
+		for _, value := range enum {
+			param.Enum = append(param.Enum, value)
+		}
+	}
+	if def, ok := propSchema["default"]; ok {
+		param.Default = def
+	}
+
+	param.Minimum = schemaFloat(propSchema["minimum"])
+	param.Maximum = schemaFloat(propSchema["maximum"])
+	param.ExclusiveMinimum = schemaFloat(propSchema["exclusiveMinimum"])
+	param.ExclusiveMaximum = schemaFloat(propSchema["exclusiveMaximum"])
+	param.MinLength = schemaInt(propSchema["minLength"])
+	param.MaxLength = schemaInt(propSchema["maxLength"])
+	param.MinItems = schemaInt(propSchema["minItems"])
+	param.MaxItems = schemaInt(propSchema["maxItems"])
+
+	if items, ok := propSchema["items"].(map[string]interface{}); ok {
+		itemParam := parameterFromSchema(items)
+		param.Items = &itemParam
+	}
+	if _, ok := propSchema["properties"]; ok {
+		param.Properties = ParametersFromSchema(propSchema)
+		if param.Type == "" {
+			param.Type = "object"
+		}
+	}
+	if additional, ok := propSchema["additionalProperties"].(map[string]interface{}); ok {
+		additionalParam := parameterFromSchema(additional)
+		param.AdditionalProperties = &additionalParam
+	}
+	param.OneOf = parametersFromList(propSchema["oneOf"])
+	param.AnyOf = parametersFromList(propSchema["anyOf"])
+	return param
+}
+
+func parametersFromList(raw interface{}) []Parameter {
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil
+	}
+	params := make([]Parameter, 0, len(list))
+	for _, item := range list {
+		if itemSchema, ok := item.(map[string]interface{}); ok {
+			params = append(params, parameterFromSchema(itemSchema))
+		}
+	}
+	return params
+}
+
+func schemaFloat(raw interface{}) *float64 {
+	switch n := raw.(type) {
+	case float64:
+		return &n
+	case float32:
+		v := float64(n)
+		return &v
+	case int:
+		v := float64(n)
+		return &v
+	case int64:
+		v := float64(n)
+		return &v
+	case json.Number:
+		if v, err := n.Float64(); err == nil {
+			return &v
+		}
+	}
+	return nil
+}
+
+func schemaInt(raw interface{}) *int {
+	if f := schemaFloat(raw); f != nil {
+		v := int(*f)
+		return &v
+	}
+	return nil
+}
+
+// schemaType returns the primary JSON type, skipping "null" in type unions
+func schemaType(raw interface{}) string {
+	switch t := raw.(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s != "null" {
+				return s
+			}
+		}
+	case []string:
+		for _, s := range t {
+			if s != "null" {
+				return s
+			}
+		}
+	}
+	return ""
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/rexleimo/agno-go/pkg/agno/models"
 )
@@ -14,15 +15,59 @@ type Function struct {
 	Description string
 	Parameters  map[string]Parameter
 	Handler     HandlerFunc
+
+	// RequiresConfirmation makes agents pause before running the function
+	// until a human approves, edits or rejects the call
+	RequiresConfirmation bool
 }
 
-// Parameter defines a function parameter
+// Parameter defines a function parameter as a JSON Schema subset.
+// Nested structure is described with Items (arrays), Properties and
+// AdditionalProperties (objects) and OneOf/AnyOf (alternatives).
 type Parameter struct {
-	Type        string      `json:"type"`
-	Description string      `json:"description,omitempty"`
-	Required    bool        `json:"required,omitempty"`
-	Enum        []string    `json:"enum,omitempty"`
-	Default     interface{} `json:"default,omitempty"`
+	Type        string        `json:"type"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"` // Allowed values of any JSON type
+	Default     interface{}   `json:"default,omitempty"`
+	Format      string        `json:"format,omitempty"`
+
+	// Numeric bounds
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+
+	// String constraints
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+
+	// Items describes the elements of an "array" parameter
+	Items    *Parameter `json:"items,omitempty"`
+	MinItems *int       `json:"minItems,omitempty"`
+	MaxItems *int       `json:"maxItems,omitempty"`
+
+	// Properties describes the fields of an "object" parameter; nested
+	// Required flags mark the object's required fields
+	Properties map[string]Parameter `json:"properties,omitempty"`
+	// AdditionalProperties describes the values of map-like objects whose
+	// keys are not known in advance
+	AdditionalProperties *Parameter `json:"additionalProperties,omitempty"`
+
+	// OneOf / AnyOf list alternative schemas for the parameter
+	OneOf []Parameter `json:"oneOf,omitempty"`
+	AnyOf []Parameter `json:"anyOf,omitempty"`
+}
+
+// Float returns a pointer to v, for use with Parameter bounds
+func Float(v float64) *float64 {
+	return &v
+}
+
+// Int returns a pointer to v, for use with Parameter length limits
+func Int(v int) *int {
+	return &v
 }
 
 // HandlerFunc is the function signature for tool handlers
@@ -66,6 +111,27 @@ func (t *BaseToolkit) RegisterFunction(fn *Function) {
 	t.functions[fn.Name] = fn
 }
 
+// RequireConfirmation makes agents pause before running the named functions,
+// or every function of the toolkit when no name is given, until a human
+// approves, edits or rejects the call
+func (t *BaseToolkit) RequireConfirmation(names ...string) error {
+	if len(names) == 0 {
+		for _, fn := range t.functions {
+			fn.RequiresConfirmation = true
+		}
+		return nil
+	}
+	for _, name := range names {
+		if _, ok := t.functions[name]; !ok {
+			return fmt.Errorf("function %s not found in toolkit %s", name, t.name)
+		}
+	}
+	for _, name := range names {
+		t.functions[name].RequiresConfirmation = true
+	}
+	return nil
+}
+
 // Execute calls a function by name with the given arguments
 func (t *BaseToolkit) Execute(ctx context.Context, fnName string, args map[string]interface{}) (interface{}, error) {
 	fn, ok := t.functions[fnName]
@@ -73,16 +139,13 @@ func (t *BaseToolkit) Execute(ctx context.Context, fnName string, args map[strin
 		return nil, fmt.Errorf("function %s not found in toolkit %s", fnName, t.name)
 	}
 
-	// Validate required parameters
-	for paramName, param := range fn.Parameters {
-		if param.Required {
-			if _, exists := args[paramName]; !exists {
-				return nil, fmt.Errorf("required parameter %s missing", paramName)
-			}
-		}
+	// Validate and coerce arguments against the parameter schema
+	validArgs, err := ValidateArguments(fn, args)
+	if err != nil {
+		return nil, err
 	}
 
-	return fn.Handler(ctx, args)
+	return fn.Handler(ctx, validArgs)
 }
 
 // ToModelToolDefinitions converts toolkit functions to model tool definitions
@@ -91,37 +154,12 @@ func ToModelToolDefinitions(toolkits []Toolkit) []models.ToolDefinition {
 
 	for _, toolkit := range toolkits {
 		for _, fn := range toolkit.Functions() {
-			// Build parameters schema
-			properties := make(map[string]interface{})
-			var required []string
-
-			for paramName, param := range fn.Parameters {
-				properties[paramName] = map[string]interface{}{
-					"type":        param.Type,
-					"description": param.Description,
-				}
-				if len(param.Enum) > 0 {
-					properties[paramName].(map[string]interface{})["enum"] = param.Enum
-				}
-				if param.Required {
-					required = append(required, paramName)
-				}
-			}
-
-			parameters := map[string]interface{}{
-				"type":       "object",
-				"properties": properties,
-			}
-			if len(required) > 0 {
-				parameters["required"] = required
-			}
-
 			definitions = append(definitions, models.ToolDefinition{
 				Type: "function",
 				Function: models.FunctionSchema{
 					Name:        fn.Name,
 					Description: fn.Description,
-					Parameters:  parameters,
+					Parameters:  objectSchema(fn.Parameters),
 				},
 			})
 		}
@@ -130,6 +168,103 @@ func ToModelToolDefinitions(toolkits []Toolkit) []models.ToolDefinition {
 	return definitions
 }
 
+// objectSchema builds an "object" JSON schema from a parameter set
+func objectSchema(params map[string]Parameter) map[string]interface{} {
+	properties := make(map[string]interface{}, len(params))
+	var required []string
+
+	for paramName, param := range params {
+		properties[paramName] = parameterSchema(param)
+		if param.Required {
+			required = append(required, paramName)
+		}
+	}
+	sort.Strings(required)
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// parameterSchema converts a single parameter to its JSON schema
+func parameterSchema(param Parameter) map[string]interface{} {
+	schema := map[string]interface{}{}
+	if param.Type == "object" && len(param.Properties) > 0 {
+		schema = objectSchema(param.Properties)
+	}
+	if param.Type != "" {
+		schema["type"] = param.Type
+	}
+	if param.Description != "" {
+		schema["description"] = param.Description
+	}
+	if len(param.Enum) > 0 {
+		schema["enum"] = param.Enum
+	}
+	if param.Default != nil {
+		schema["default"] = param.Default
+	}
+	if param.Format != "" {
+		schema["format"] = param.Format
+	}
+
+	setFloat := func(key string, v *float64) {
+		if v != nil {
+			schema[key] = *v
+		}
+	}
+	setFloat("minimum", param.Minimum)
+	setFloat("maximum", param.Maximum)
+	setFloat("exclusiveMinimum", param.ExclusiveMinimum)
+	setFloat("exclusiveMaximum", param.ExclusiveMaximum)
+
+	setInt := func(key string, v *int) {
+		if v != nil {
+			schema[key] = *v
+		}
+	}
+	setInt("minLength", param.MinLength)
+	setInt("maxLength", param.MaxLength)
+	setInt("minItems", param.MinItems)
+	setInt("maxItems", param.MaxItems)
+
+	if param.Pattern != "" {
+		schema["pattern"] = param.Pattern
+	}
+	if param.Items != nil {
+		schema["items"] = parameterSchema(*param.Items)
+	}
+	if param.AdditionalProperties != nil {
+		schema["additionalProperties"] = parameterSchema(*param.AdditionalProperties)
+	}
+	if len(param.OneOf) > 0 {
+		schema["oneOf"] = parameterSchemas(param.OneOf)
+	}
+	if len(param.AnyOf) > 0 {
+		schema["anyOf"] = parameterSchemas(param.AnyOf)
+	}
+	return schema
+}
+
+func parameterSchemas(params []Parameter) []interface{} {
+	out := make([]interface{}, len(params))
+	for i, param := range params {
+		out[i] = parameterSchema(param)
+	}
+	return out
+}
+
+// ParameterSchema returns the JSON schema of a parameter set as sent to
+// models: an "object" schema with one property per parameter
+func ParameterSchema(params map[string]Parameter) map[string]interface{} {
+	return objectSchema(params)
+}
+
 // ParseArguments parses JSON arguments string into a map
 func ParseArguments(argsJSON string) (map[string]interface{}, error) {
 	var args map[string]interface{}
@@ -2,6 +2,9 @@ package toolkit
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -81,6 +84,29 @@ func TestBaseToolkit_RegisterFunction(t *testing.T) {
 	}
 }
 
+func TestBaseToolkit_RequireConfirmation(t *testing.T) {
+	tk := NewBaseToolkit("test")
+	tk.RegisterFunction(&Function{Name: "read"})
+	tk.RegisterFunction(&Function{Name: "write"})
+
+	if err := tk.RequireConfirmation("write"); err != nil {
+		t.Fatalf("RequireConfirmation() error = %v", err)
+	}
+	if tk.Functions()["read"].RequiresConfirmation || !tk.Functions()["write"].RequiresConfirmation {
+		t.Fatal("expected only write to require confirmation")
+	}
+	if err := tk.RequireConfirmation("missing"); err == nil {
+		t.Fatal("expected error for an unknown function")
+	}
+
+	if err := tk.RequireConfirmation(); err != nil {
+		t.Fatalf("RequireConfirmation() error = %v", err)
+	}
+	if !tk.Functions()["read"].RequiresConfirmation {
+		t.Fatal("expected every function to require confirmation")
+	}
+}
+
 func TestBaseToolkit_Execute(t *testing.T) {
 	toolkit := NewMockToolkit()
 
@@ -302,3 +328,151 @@ func TestBaseToolkit_MultipleRegistrations(t *testing.T) {
 		t.Errorf("func2 result = %v, want 2", result2)
 	}
 }
+
+func TestToModelToolDefinitions_FullSchema(t *testing.T) {
+	tk := NewBaseToolkit("orders")
+	tk.RegisterFunction(&Function{
+		Name:        "create_order",
+		Description: "Create an order",
+		Parameters: map[string]Parameter{
+			"lines": {
+				Type:     "array",
+				Required: true,
+				MinItems: Int(1),
+				Items: &Parameter{
+					Type: "object",
+					Properties: map[string]Parameter{
+						"sku":      {Type: "string", Required: true, Pattern: "^[A-Z]+$"},
+						"quantity": {Type: "integer", Minimum: Float(1), Maximum: Float(100), Default: 1},
+					},
+				},
+			},
+			"metadata": {
+				Type:                 "object",
+				AdditionalProperties: &Parameter{Type: "string"},
+			},
+			"discount": {
+				OneOf: []Parameter{
+					{Type: "number", ExclusiveMinimum: Float(0)},
+					{Type: "string", Format: "percent"},
+				},
+			},
+		},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) { return nil, nil },
+	})
+
+	defs := ToModelToolDefinitions([]Toolkit{tk})
+	params := defs[0].Function.Parameters
+	if !reflect.DeepEqual(params["required"], []string{"lines"}) {
+		t.Fatalf("unexpected required list: %v", params["required"])
+	}
+
+	props := params["properties"].(map[string]interface{})
+	lines := props["lines"].(map[string]interface{})
+	if lines["minItems"] != 1 {
+		t.Fatalf("expected minItems, got %v", lines)
+	}
+	items := lines["items"].(map[string]interface{})
+	if !reflect.DeepEqual(items["required"], []string{"sku"}) {
+		t.Fatalf("expected nested required list, got %v", items["required"])
+	}
+	quantity := items["properties"].(map[string]interface{})["quantity"].(map[string]interface{})
+	if quantity["minimum"] != 1.0 || quantity["maximum"] != 100.0 || quantity["default"] != 1 {
+		t.Fatalf("unexpected quantity schema: %v", quantity)
+	}
+
+	metadata := props["metadata"].(map[string]interface{})
+	if metadata["additionalProperties"].(map[string]interface{})["type"] != "string" {
+		t.Fatalf("unexpected metadata schema: %v", metadata)
+	}
+
+	discount := props["discount"].(map[string]interface{})
+	if _, hasType := discount["type"]; hasType {
+		t.Fatalf("expected untyped oneOf parameter, got %v", discount)
+	}
+	if oneOf := discount["oneOf"].([]interface{}); len(oneOf) != 2 {
+		t.Fatalf("expected 2 oneOf alternatives, got %v", oneOf)
+	}
+
+	// The model-facing schema converts back without losing detail.
+	roundTrip := ParametersFromSchema(params)
+	if got := roundTrip["lines"].Items.Properties["quantity"]; got.Maximum == nil || *got.Maximum != 100 {
+		t.Fatalf("round trip lost quantity bounds: %+v", got)
+	}
+}
+
+func TestValidateArguments(t *testing.T) {
+	fn := &Function{
+		Name: "resize",
+		Parameters: map[string]Parameter{
+			"width": {Type: "integer", Required: true, Minimum: Float(1)},
+			"mode":  {Type: "string", Enum: []interface{}{"fit", "fill"}},
+			"crop":  {Type: "boolean"},
+			"tags":  {Type: "array", Items: &Parameter{Type: "string"}},
+		},
+	}
+
+	args, err := ValidateArguments(fn, map[string]interface{}{
+		"width": "640",
+		"crop":  "false",
+		"tags":  []string{"thumb"},
+	})
+	if err != nil {
+		t.Fatalf("ValidateArguments() error = %v", err)
+	}
+	if args["width"] != 640.0 || args["crop"] != false {
+		t.Fatalf("expected coerced values, got %v", args)
+	}
+	if _, ok := args["tags"].([]string); !ok {
+		t.Fatalf("expected untouched Go value to keep its type, got %T", args["tags"])
+	}
+
+	_, err = ValidateArguments(fn, map[string]interface{}{"width": 0, "mode": "stretch", "tags": []interface{}{1}})
+	var argErr *ArgumentError
+	if !errors.As(err, &argErr) {
+		t.Fatalf("expected ArgumentError, got %v", err)
+	}
+	if argErr.Function != "resize" || len(argErr.Issues) != 3 {
+		t.Fatalf("unexpected issues: %+v", argErr.Issues)
+	}
+
+	data, err := json.Marshal(argErr)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if payload["error"] != "invalid_arguments" || len(payload["issues"].([]interface{})) != 3 {
+		t.Fatalf("unexpected model payload: %s", data)
+	}
+}
+
+func TestBaseToolkit_Execute_ValidatesArguments(t *testing.T) {
+	tk := NewBaseToolkit("math")
+	tk.RegisterFunction(&Function{
+		Name: "double",
+		Parameters: map[string]Parameter{
+			"n": {Type: "integer", Required: true},
+		},
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return args["n"].(float64) * 2, nil
+		},
+	})
+
+	result, err := tk.Execute(context.Background(), "double", map[string]interface{}{"n": "21"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result != 42.0 {
+		t.Fatalf("Execute() = %v, want 42", result)
+	}
+
+	if _, err := tk.Execute(context.Background(), "double", map[string]interface{}{"n": "abc"}); err == nil {
+		t.Fatal("expected validation error for non-numeric argument")
+	}
+	if _, err := tk.Execute(context.Background(), "double", map[string]interface{}{}); err == nil {
+		t.Fatal("expected validation error for missing argument")
+	}
+}
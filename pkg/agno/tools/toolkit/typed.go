@@ -0,0 +1,102 @@
+package toolkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/rexleimo/agno-go/pkg/agno/schema"
+)
+
+// TypedHandlerFunc is a tool handler that receives decoded, validated arguments
+type TypedHandlerFunc[Args any, Result any] func(ctx context.Context, args Args) (Result, error)
+
+// NewTypedFunction builds a Function from a typed Go handler.
+//
+// The parameter schema is derived from the Args struct using its `json` tags
+// (fields without omitempty are required) plus the optional `description`,
+// `enum`, `default`, `minimum` and `maximum` tags understood by the schema
+// package. Nested structs, slices and maps are supported. Before the handler
+// runs, the raw arguments are filled with defaults, validated against the
+// schema and decoded into Args, so the handler needs no type assertions:
+//
+//	type addArgs struct {
+//		A float64 `json:"a" description:"First number"`
+//		B float64 `json:"b" description:"Second number"`
+//	}
+//
+//	fn, err := toolkit.NewTypedFunction("add", "Add two numbers",
+//		func(ctx context.Context, args addArgs) (float64, error) {
+//			return args.A + args.B, nil
+//		})
+func NewTypedFunction[Args any, Result any](name, description string, handler TypedHandlerFunc[Args, Result]) (*Function, error) {
+	if name == "" {
+		return nil, fmt.Errorf("function name is required")
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("handler for function %s is nil", name)
+	}
+
+	argsType := reflect.TypeOf((*Args)(nil)).Elem()
+	structType := argsType
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("arguments of function %s must be a struct, got %s", name, argsType)
+	}
+
+	argsSchema, err := schema.FromType(structType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build schema for function %s: %w", name, err)
+	}
+
+	return &Function{
+		Name:        name,
+		Description: description,
+		Parameters:  ParametersFromSchema(argsSchema),
+		Handler: func(ctx context.Context, raw map[string]interface{}) (interface{}, error) {
+			args, err := decodeTypedArgs[Args](argsSchema, raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid arguments for %s: %w", name, err)
+			}
+			return handler(ctx, args)
+		},
+	}, nil
+}
+
+// decodeTypedArgs applies schema defaults to raw, validates it and decodes
+// the result into Args
+func decodeTypedArgs[Args any](argsSchema map[string]interface{}, raw map[string]interface{}) (Args, error) {
+	var args Args
+
+	if raw == nil {
+		raw = map[string]interface{}{}
+	}
+
+	// Round-trip through JSON so values passed from Go code (ints, typed
+	// slices, structs) are validated in the same shape as model arguments.
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return args, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return args, err
+	}
+
+	value = schema.ApplyDefaults(argsSchema, value)
+	if err := schema.Validate(argsSchema, value); err != nil {
+		return args, err
+	}
+
+	data, err = json.Marshal(value)
+	if err != nil {
+		return args, err
+	}
+	if err := json.Unmarshal(data, &args); err != nil {
+		return args, err
+	}
+	return args, nil
+}
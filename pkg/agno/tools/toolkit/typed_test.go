@@ -0,0 +1,172 @@
+package toolkit
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type searchFilter struct {
+	Field string `json:"field" description:"Field to filter on"`
+	Value string `json:"value"`
+}
+
+type searchArgs struct {
+	Query   string         `json:"query" description:"Search query"`
+	Limit   int            `json:"limit,omitempty" default:"5"`
+	Sort    string         `json:"sort,omitempty" enum:"asc,desc" default:"asc"`
+	Tags    []string       `json:"tags,omitempty" enum:"news,blog"`
+	Filters []searchFilter `json:"filters,omitempty"`
+}
+
+type searchResult struct {
+	Query string
+	Limit int
+	Sort  string
+	Tags  []string
+	Count int
+}
+
+func newSearchFunction(t *testing.T) *Function {
+	t.Helper()
+	fn, err := NewTypedFunction("search", "Search documents",
+		func(ctx context.Context, args searchArgs) (searchResult, error) {
+			return searchResult{
+				Query: args.Query,
+				Limit: args.Limit,
+				Sort:  args.Sort,
+				Tags:  args.Tags,
+				Count: len(args.Filters),
+			}, nil
+		})
+	if err != nil {
+		t.Fatalf("NewTypedFunction() error = %v", err)
+	}
+	return fn
+}
+
+func TestNewTypedFunction_Parameters(t *testing.T) {
+	fn := newSearchFunction(t)
+
+	query := fn.Parameters["query"]
+	if query.Type != "string" || !query.Required || query.Description != "Search query" {
+		t.Fatalf("unexpected query parameter: %+v", query)
+	}
+
+	limit := fn.Parameters["limit"]
+	if limit.Type != "integer" || limit.Required || limit.Default != float64(5) {
+		t.Fatalf("unexpected limit parameter: %+v", limit)
+	}
+
+	if !reflect.DeepEqual(fn.Parameters["sort"].Enum, []interface{}{"asc", "desc"}) {
+		t.Fatalf("unexpected sort enum: %+v", fn.Parameters["sort"].Enum)
+	}
+
+	tags := fn.Parameters["tags"]
+	if tags.Type != "array" || tags.Items == nil || !reflect.DeepEqual(tags.Items.Enum, []interface{}{"news", "blog"}) {
+		t.Fatalf("unexpected tags parameter: %+v", tags)
+	}
+
+	filters := fn.Parameters["filters"]
+	if filters.Items == nil || filters.Items.Type != "object" {
+		t.Fatalf("unexpected filters parameter: %+v", filters)
+	}
+	if field := filters.Items.Properties["field"]; !field.Required || field.Description != "Field to filter on" {
+		t.Fatalf("unexpected nested field parameter: %+v", field)
+	}
+
+	tk := NewBaseToolkit("docs")
+	tk.RegisterFunction(fn)
+	defs := ToModelToolDefinitions([]Toolkit{tk})
+	props := defs[0].Function.Parameters["properties"].(map[string]interface{})
+	items := props["filters"].(map[string]interface{})["items"].(map[string]interface{})
+	if !reflect.DeepEqual(items["required"], []string{"field", "value"}) {
+		t.Fatalf("expected nested required list in tool definition, got %v", items["required"])
+	}
+	if props["limit"].(map[string]interface{})["default"] != float64(5) {
+		t.Fatalf("expected default in tool definition, got %v", props["limit"])
+	}
+}
+
+func TestNewTypedFunction_Handler(t *testing.T) {
+	fn := newSearchFunction(t)
+
+	result, err := fn.Handler(context.Background(), map[string]interface{}{
+		"query":   "go",
+		"tags":    []interface{}{"news"},
+		"filters": []interface{}{map[string]interface{}{"field": "lang", "value": "en"}},
+	})
+	if err != nil {
+		t.Fatalf("Handler() error = %v", err)
+	}
+	got := result.(searchResult)
+	want := searchResult{Query: "go", Limit: 5, Sort: "asc", Tags: []string{"news"}, Count: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Handler() = %+v, want %+v", got, want)
+	}
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{name: "missing required", args: map[string]interface{}{}, want: "$.query"},
+		{name: "bad enum", args: map[string]interface{}{"query": "go", "sort": "up"}, want: "$.sort"},
+		{name: "bad item enum", args: map[string]interface{}{"query": "go", "tags": []interface{}{"video"}}, want: "$.tags[0]"},
+		{name: "bad nested type", args: map[string]interface{}{"query": "go", "filters": []interface{}{map[string]interface{}{"field": 1, "value": "x"}}}, want: "$.filters[0].field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fn.Handler(context.Background(), tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error mentioning %s, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNewTypedFunction_IntegerEnum(t *testing.T) {
+	type pageArgs struct {
+		Size   int  `json:"size" enum:"10,25,50"`
+		Strict bool `json:"strict,omitempty" enum:"true"`
+	}
+	fn, err := NewTypedFunction("page", "Page results",
+		func(ctx context.Context, args pageArgs) (int, error) { return args.Size, nil })
+	if err != nil {
+		t.Fatalf("NewTypedFunction() error = %v", err)
+	}
+
+	if got := fn.Parameters["size"].Enum; !reflect.DeepEqual(got, []interface{}{float64(10), float64(25), float64(50)}) {
+		t.Fatalf("unexpected size enum: %#v", got)
+	}
+	if got := fn.Parameters["strict"].Enum; !reflect.DeepEqual(got, []interface{}{true}) {
+		t.Fatalf("unexpected strict enum: %#v", got)
+	}
+
+	tk := NewBaseToolkit("pages")
+	tk.RegisterFunction(fn)
+	defs := ToModelToolDefinitions([]Toolkit{tk})
+	props := defs[0].Function.Parameters["properties"].(map[string]interface{})
+	if got := props["size"].(map[string]interface{})["enum"]; !reflect.DeepEqual(got, []interface{}{float64(10), float64(25), float64(50)}) {
+		t.Fatalf("expected integer enum in tool definition, got %#v", got)
+	}
+
+	if _, err := ValidateArguments(fn, map[string]interface{}{"size": 25}); err != nil {
+		t.Fatalf("ValidateArguments() error = %v", err)
+	}
+	if _, err := ValidateArguments(fn, map[string]interface{}{"size": 30}); err == nil || !strings.Contains(err.Error(), "$.size") {
+		t.Fatalf("expected enum violation for size, got %v", err)
+	}
+}
+
+func TestNewTypedFunction_Errors(t *testing.T) {
+	if _, err := NewTypedFunction("bad", "", func(ctx context.Context, args string) (string, error) {
+		return args, nil
+	}); err == nil {
+		t.Fatal("expected error for non-struct arguments")
+	}
+	if _, err := NewTypedFunction[searchArgs, string]("nil", "", nil); err == nil {
+		t.Fatal("expected error for nil handler")
+	}
+}
@@ -0,0 +1,102 @@
+package toolkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rexleimo/agno-go/pkg/agno/schema"
+)
+
+// ArgumentError reports tool arguments that do not match the function's
+// parameter schema. It marshals to a JSON document the model can use to
+// correct its next call.
+type ArgumentError struct {
+	Function string         `json:"function"`
+	Issues   []schema.Issue `json:"issues"`
+}
+
+// Error implements the error interface
+func (e *ArgumentError) Error() string {
+	parts := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		parts[i] = fmt.Sprintf("%s: %s", issue.Path, issue.Message)
+	}
+	return fmt.Sprintf("invalid arguments for %s: %s", e.Function, strings.Join(parts, "; "))
+}
+
+// MarshalJSON renders the error as a tool result for the model
+func (e *ArgumentError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"error":    "invalid_arguments",
+		"function": e.Function,
+		"issues":   e.Issues,
+		"hint":     "Fix the listed arguments to match the tool's parameter schema and call the tool again.",
+	})
+}
+
+// ValidateArguments checks args against the function's parameters and
+// returns the arguments to pass to the handler.
+//
+// Values are coerced where the conversion is safe (for example the string
+// "5" for an integer parameter, or "true" for a boolean); other values are
+// passed through unchanged, keeping their Go types. Type, enum, range and
+// nested structure violations are reported as an *ArgumentError.
+func ValidateArguments(fn *Function, args map[string]interface{}) (map[string]interface{}, error) {
+	if fn == nil {
+		return args, fmt.Errorf("function is nil")
+	}
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+
+	objSchema := objectSchema(fn.Parameters)
+	properties, _ := objSchema["properties"].(map[string]interface{})
+
+	out := make(map[string]interface{}, len(args))
+	normalized := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		out[key] = value
+
+		// Validate a JSON-shaped copy so Go callers (ints, typed slices,
+		// structs) are checked the same way as model-produced arguments.
+		plain, err := normalizeValue(value)
+		if err != nil {
+			return nil, &ArgumentError{
+				Function: fn.Name,
+				Issues:   []schema.Issue{{Path: "$." + key, Message: fmt.Sprintf("value cannot be encoded as JSON: %v", err)}},
+			}
+		}
+		if propSchema, ok := properties[key].(map[string]interface{}); ok {
+			if coerced, changed := schema.Coerce(propSchema, plain); changed {
+				plain = coerced
+				out[key] = coerced
+			}
+		}
+		normalized[key] = plain
+	}
+
+	if err := schema.Validate(objSchema, normalized); err != nil {
+		if verr, ok := err.(*schema.ValidationError); ok {
+			return nil, &ArgumentError{Function: fn.Name, Issues: verr.Issues}
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func normalizeValue(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case nil, bool, string, float64:
+		return value, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var plain interface{}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
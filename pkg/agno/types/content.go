@@ -0,0 +1,168 @@
+package types
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// ContentPartType identifies the kind of a ContentPart
+// ContentPartType 标识内容片段的类型
+type ContentPartType string
+
+const (
+	ContentPartText  ContentPartType = "text"
+	ContentPartImage ContentPartType = "image"
+	ContentPartAudio ContentPartType = "audio"
+	ContentPartFile  ContentPartType = "file"
+)
+
+// ContentPart is one piece of multimodal message content. Media parts carry
+// either a URL (http(s) or data:) or base64-encoded Data with its MimeType.
+// ContentPart 是多模态消息内容的一个片段；媒体片段携带 URL 或 base64 数据
+type ContentPart struct {
+	Type     ContentPartType `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	URL      string          `json:"url,omitempty"`
+	Data     string          `json:"data,omitempty"` // base64, standard encoding
+	MimeType string          `json:"mime_type,omitempty"`
+	Filename string          `json:"filename,omitempty"`
+	Detail   string          `json:"detail,omitempty"` // image detail hint: low, high or auto
+}
+
+// NewTextPart creates a text part
+func NewTextPart(text string) ContentPart {
+	return ContentPart{Type: ContentPartText, Text: text}
+}
+
+// NewImageURLPart creates an image part referencing a URL
+func NewImageURLPart(url string) ContentPart {
+	return ContentPart{Type: ContentPartImage, URL: url}
+}
+
+// NewImageDataPart creates an image part from raw bytes
+func NewImageDataPart(data []byte, mimeType string) ContentPart {
+	return ContentPart{Type: ContentPartImage, Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// NewAudioDataPart creates an audio part from raw bytes
+func NewAudioDataPart(data []byte, mimeType string) ContentPart {
+	return ContentPart{Type: ContentPartAudio, Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// NewFileURLPart creates a file part referencing a URL
+func NewFileURLPart(url, mimeType, filename string) ContentPart {
+	return ContentPart{Type: ContentPartFile, URL: url, MimeType: mimeType, Filename: filename}
+}
+
+// NewFileDataPart creates a file part from raw bytes
+func NewFileDataPart(data []byte, mimeType, filename string) ContentPart {
+	return ContentPart{Type: ContentPartFile, Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType, Filename: filename}
+}
+
+// InlineData returns the base64 payload and MIME type of a media part, taken
+// from Data or decoded from a data: URL. ok is false for remote URLs.
+// InlineData 返回媒体片段的 base64 数据与 MIME 类型
+func (p ContentPart) InlineData() (mimeType, data string, ok bool) {
+	if p.Data != "" {
+		return p.MimeType, p.Data, true
+	}
+	if !strings.HasPrefix(p.URL, "data:") {
+		return "", "", false
+	}
+
+	header, payload, found := strings.Cut(strings.TrimPrefix(p.URL, "data:"), ",")
+	if !found || !strings.HasSuffix(header, ";base64") {
+		return "", "", false
+	}
+	mimeType = strings.TrimSuffix(header, ";base64")
+	if mimeType == "" {
+		mimeType = p.MimeType
+	}
+	return mimeType, payload, true
+}
+
+// DataURL returns the part as a URL: the URL itself, or a data: URL built
+// from the inline data
+// DataURL 以 URL 形式返回片段（原 URL 或由内联数据构造的 data: URL）
+func (p ContentPart) DataURL() string {
+	if p.URL != "" {
+		return p.URL
+	}
+	if p.Data == "" {
+		return ""
+	}
+	mimeType := p.MimeType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return "data:" + mimeType + ";base64," + p.Data
+}
+
+// Describe renders the part as text for providers or consumers that cannot
+// handle it natively
+// Describe 为无法原生处理该片段的模型渲染一段文本描述
+func (p ContentPart) Describe() string {
+	if p.Type == ContentPartText {
+		return p.Text
+	}
+
+	details := make([]string, 0, 3)
+	if p.Filename != "" {
+		details = append(details, p.Filename)
+	}
+	if p.MimeType != "" {
+		details = append(details, p.MimeType)
+	}
+	if p.URL != "" && !strings.HasPrefix(p.URL, "data:") {
+		details = append(details, p.URL)
+	}
+	if len(details) == 0 {
+		return fmt.Sprintf("[%s attachment]", p.Type)
+	}
+	return fmt.Sprintf("[%s attachment: %s]", p.Type, strings.Join(details, ", "))
+}
+
+// ContentParts returns the message content as parts. Content is returned as
+// a single text part when Parts is empty, and is prepended as a text part
+// when Parts holds no text of its own.
+// ContentParts 以片段形式返回消息内容
+func (m *Message) ContentParts() []ContentPart {
+	if len(m.Parts) == 0 {
+		if m.Content == "" {
+			return nil
+		}
+		return []ContentPart{NewTextPart(m.Content)}
+	}
+
+	for _, part := range m.Parts {
+		if part.Type == ContentPartText {
+			return m.Parts
+		}
+	}
+	if m.Content == "" {
+		return m.Parts
+	}
+	return append([]ContentPart{NewTextPart(m.Content)}, m.Parts...)
+}
+
+// HasMedia reports whether the message carries non-text parts
+// HasMedia 判断消息是否包含非文本片段
+func (m *Message) HasMedia() bool {
+	for _, part := range m.Parts {
+		if part.Type != ContentPartText {
+			return true
+		}
+	}
+	return false
+}
+
+// NewUserMessageWithParts creates a user message with text and media parts
+// NewUserMessageWithParts 创建包含文本与媒体片段的用户消息
+func NewUserMessageWithParts(text string, parts ...ContentPart) *Message {
+	msg := NewUserMessage(text)
+	if len(parts) > 0 {
+		msg.Parts = parts
+	}
+	return msg
+}
@@ -0,0 +1,42 @@
+package types
+
+import "testing"
+
+func TestContentPart_InlineData(t *testing.T) {
+	part := NewImageDataPart([]byte("abc"), "image/png")
+	if mimeType, data, ok := part.InlineData(); !ok || mimeType != "image/png" || data != "YWJj" {
+		t.Fatalf("InlineData() = %q, %q, %v", mimeType, data, ok)
+	}
+	if got := part.DataURL(); got != "data:image/png;base64,YWJj" {
+		t.Fatalf("DataURL() = %q", got)
+	}
+
+	fromURL := NewImageURLPart("data:image/jpeg;base64,eHl6")
+	if mimeType, data, ok := fromURL.InlineData(); !ok || mimeType != "image/jpeg" || data != "eHl6" {
+		t.Fatalf("InlineData() from data URL = %q, %q, %v", mimeType, data, ok)
+	}
+
+	if _, _, ok := NewImageURLPart("https://example.com/a.png").InlineData(); ok {
+		t.Fatal("remote URLs have no inline data")
+	}
+}
+
+func TestMessage_ContentParts(t *testing.T) {
+	if parts := NewUserMessage("hi").ContentParts(); len(parts) != 1 || parts[0].Text != "hi" {
+		t.Fatalf("text message parts = %+v", parts)
+	}
+
+	msg := NewUserMessageWithParts("look", NewImageURLPart("https://example.com/a.png"))
+	parts := msg.ContentParts()
+	if len(parts) != 2 || parts[0].Text != "look" || parts[1].Type != ContentPartImage {
+		t.Fatalf("multimodal parts = %+v", parts)
+	}
+	if !msg.HasMedia() {
+		t.Fatal("HasMedia() should be true")
+	}
+
+	explicit := &Message{Role: RoleUser, Content: "look", Parts: []ContentPart{NewTextPart("look"), NewImageURLPart("u")}}
+	if len(explicit.ContentParts()) != 2 {
+		t.Fatal("explicit text parts should not be duplicated")
+	}
+}
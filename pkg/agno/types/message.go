@@ -24,6 +24,10 @@ type Message struct {
     ToolCalls  []ToolCall  `json:"tool_calls,omitempty"`
     Metadata   interface{} `json:"metadata,omitempty"`
 
+	// Parts 包含多模态内容片段（文本、图片、音频、文件）
+	// Parts holds multimodal content parts (text, images, audio, files)
+	Parts []ContentPart `json:"parts,omitempty"`
+
 	// ReasoningContent 包含模型的推理过程(仅推理模型)
 	// ReasoningContent contains the model's reasoning process (reasoning models only)
 	ReasoningContent *ReasoningContent `json:"reasoning_content,omitempty"`
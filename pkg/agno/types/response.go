@@ -33,6 +33,9 @@ type ResponseChunk struct {
 	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 	Done      bool       `json:"done"`
 	Error     error      `json:"error,omitempty"`
+
+	// Metadata is merged into the aggregated response's Metadata.Extra
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // HasToolCalls checks if the response contains tool calls
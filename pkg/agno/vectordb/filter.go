@@ -0,0 +1,263 @@
+package vectordb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Filter operators, following the ChromaDB "where" syntax
+const (
+	FilterAnd = "$and"
+	FilterOr  = "$or"
+	FilterEq  = "$eq"
+	FilterNe  = "$ne"
+	FilterGt  = "$gt"
+	FilterGte = "$gte"
+	FilterLt  = "$lt"
+	FilterLte = "$lte"
+	FilterIn  = "$in"
+	FilterNin = "$nin"
+)
+
+// ValidateFilter checks that a metadata filter is well formed. Filters use
+// the ChromaDB "where" syntax understood by every VectorDB implementation:
+//
+//	{"field": value}                              equality
+//	{"field": {"$ne": value}}                     also $eq, $gt, $gte, $lt, $lte
+//	{"field": {"$in": [value, ...]}}              also $nin
+//	{"$and": [filter, ...]}, {"$or": [filter, ...]}
+//
+// Several keys in one filter must all match.
+func ValidateFilter(filter map[string]interface{}) error {
+	for key, value := range filter {
+		switch key {
+		case FilterAnd, FilterOr:
+			clauses, err := filterClauses(key, value)
+			if err != nil {
+				return err
+			}
+			for _, clause := range clauses {
+				if err := ValidateFilter(clause); err != nil {
+					return err
+				}
+			}
+		default:
+			if strings.HasPrefix(key, "$") {
+				return fmt.Errorf("unsupported filter operator %q", key)
+			}
+			ops, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for op, operand := range ops {
+				switch op {
+				case FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte:
+				case FilterIn, FilterNin:
+					if _, ok := FilterList(operand); !ok {
+						return fmt.Errorf("%s on %q requires a list", op, key)
+					}
+				default:
+					return fmt.Errorf("unsupported filter operator %q on %q", op, key)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// MatchesFilter reports whether metadata satisfies filter (see
+// ValidateFilter for the syntax). Numbers compare by value whatever their Go
+// type; strings also support the ordering operators. A nil or empty filter
+// matches everything.
+func MatchesFilter(metadata, filter map[string]interface{}) (bool, error) {
+	for key, value := range filter {
+		var (
+			ok  bool
+			err error
+		)
+		switch key {
+		case FilterAnd, FilterOr:
+			var clauses []map[string]interface{}
+			clauses, err = filterClauses(key, value)
+			if err != nil {
+				return false, err
+			}
+			ok = key == FilterAnd
+			for _, clause := range clauses {
+				matched, err := MatchesFilter(metadata, clause)
+				if err != nil {
+					return false, err
+				}
+				if key == FilterAnd && !matched {
+					ok = false
+					break
+				}
+				if key == FilterOr && matched {
+					ok = true
+					break
+				}
+			}
+		default:
+			if strings.HasPrefix(key, "$") {
+				return false, fmt.Errorf("unsupported filter operator %q", key)
+			}
+			actual, present := metadata[key]
+			ok, err = matchField(actual, present, value)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchField(actual interface{}, present bool, condition interface{}) (bool, error) {
+	ops, isOps := condition.(map[string]interface{})
+	if !isOps {
+		return present && filterEqual(actual, condition), nil
+	}
+
+	for op, operand := range ops {
+		var ok bool
+		switch op {
+		case FilterEq:
+			ok = present && filterEqual(actual, operand)
+		case FilterNe:
+			ok = !present || !filterEqual(actual, operand)
+		case FilterGt, FilterGte, FilterLt, FilterLte:
+			cmp, comparable := filterCompare(actual, operand)
+			if !present || !comparable {
+				return false, nil
+			}
+			switch op {
+			case FilterGt:
+				ok = cmp > 0
+			case FilterGte:
+				ok = cmp >= 0
+			case FilterLt:
+				ok = cmp < 0
+			case FilterLte:
+				ok = cmp <= 0
+			}
+		case FilterIn, FilterNin:
+			values, isList := FilterList(operand)
+			if !isList {
+				return false, fmt.Errorf("%s requires a list", op)
+			}
+			found := false
+			for _, v := range values {
+				if present && filterEqual(actual, v) {
+					found = true
+					break
+				}
+			}
+			ok = found == (op == FilterIn)
+		default:
+			return false, fmt.Errorf("unsupported filter operator %q", op)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func filterClauses(op string, value interface{}) ([]map[string]interface{}, error) {
+	items, ok := FilterList(value)
+	if !ok {
+		return nil, fmt.Errorf("%s requires a list of filters", op)
+	}
+	clauses := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		clause, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s requires a list of filters", op)
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses, nil
+}
+
+// FilterList converts an $in, $nin, $and or $or operand (any slice) to
+// []interface{}
+func FilterList(value interface{}) ([]interface{}, bool) {
+	if items, ok := value.([]interface{}); ok {
+		return items, true
+	}
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() || rv.Kind() != reflect.Slice {
+		return nil, false
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+// FilterNumber converts numeric filter and metadata values to float64
+func FilterNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func filterEqual(a, b interface{}) bool {
+	if fa, ok := FilterNumber(a); ok {
+		fb, ok := FilterNumber(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func filterCompare(a, b interface{}) (int, bool) {
+	if fa, ok := FilterNumber(a); ok {
+		fb, ok := FilterNumber(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	}
+	sa, okA := a.(string)
+	sb, okB := b.(string)
+	if !okA || !okB {
+		return 0, false
+	}
+	return strings.Compare(sa, sb), true
+}
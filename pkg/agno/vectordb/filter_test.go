@@ -0,0 +1,79 @@
+package vectordb
+
+import "testing"
+
+func TestMatchesFilter(t *testing.T) {
+	metadata := map[string]interface{}{
+		"lang":  "en",
+		"year":  2023,
+		"score": 0.8,
+		"tags":  "guide",
+	}
+
+	tests := []struct {
+		name   string
+		filter map[string]interface{}
+		want   bool
+	}{
+		{"empty", nil, true},
+		{"equality", map[string]interface{}{"lang": "en"}, true},
+		{"equality across numeric types", map[string]interface{}{"year": float64(2023)}, true},
+		{"equality mismatch", map[string]interface{}{"lang": "fr"}, false},
+		{"missing field", map[string]interface{}{"author": "ada"}, false},
+		{"all keys must match", map[string]interface{}{"lang": "en", "year": 2020}, false},
+		{"$in", map[string]interface{}{"lang": map[string]interface{}{"$in": []interface{}{"fr", "en"}}}, true},
+		{"$in typed slice", map[string]interface{}{"lang": map[string]interface{}{"$in": []string{"fr", "de"}}}, false},
+		{"$nin", map[string]interface{}{"lang": map[string]interface{}{"$nin": []interface{}{"fr"}}}, true},
+		{"$gt", map[string]interface{}{"year": map[string]interface{}{"$gt": 2020}}, true},
+		{"$gt and $lt", map[string]interface{}{"score": map[string]interface{}{"$gt": 0.5, "$lt": 0.8}}, false},
+		{"$lte", map[string]interface{}{"score": map[string]interface{}{"$lte": 0.8}}, true},
+		{"$gt on string", map[string]interface{}{"lang": map[string]interface{}{"$gt": "de"}}, true},
+		{"$gt on missing", map[string]interface{}{"pages": map[string]interface{}{"$gt": 1}}, false},
+		{"$ne on missing", map[string]interface{}{"pages": map[string]interface{}{"$ne": 1}}, true},
+		{"$and", map[string]interface{}{"$and": []interface{}{
+			map[string]interface{}{"lang": "en"},
+			map[string]interface{}{"year": map[string]interface{}{"$gte": 2023}},
+		}}, true},
+		{"$or", map[string]interface{}{"$or": []interface{}{
+			map[string]interface{}{"lang": "fr"},
+			map[string]interface{}{"tags": "guide"},
+		}}, true},
+		{"$or none", map[string]interface{}{"$or": []map[string]interface{}{
+			{"lang": "fr"},
+			{"year": 1999},
+		}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFilter(tt.filter); err != nil {
+				t.Fatalf("ValidateFilter() error = %v", err)
+			}
+			got, err := MatchesFilter(metadata, tt.filter)
+			if err != nil {
+				t.Fatalf("MatchesFilter() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MatchesFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFilter_Errors(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"$not": map[string]interface{}{"a": 1}},
+		{"a": map[string]interface{}{"$regex": "x"}},
+		{"a": map[string]interface{}{"$in": "x"}},
+		{"$and": map[string]interface{}{"a": 1}},
+		{"$or": []interface{}{"a"}},
+	}
+	for _, filter := range invalid {
+		if err := ValidateFilter(filter); err == nil {
+			t.Errorf("ValidateFilter(%v) expected error", filter)
+		}
+		if _, err := MatchesFilter(map[string]interface{}{"a": 1}, filter); err == nil {
+			t.Errorf("MatchesFilter(%v) expected error", filter)
+		}
+	}
+}
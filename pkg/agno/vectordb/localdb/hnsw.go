@@ -0,0 +1,250 @@
+package localdb
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// candidate is a document and its distance to a query
+type candidate struct {
+	node int
+	dist float64
+}
+
+// minHeap pops the nearest candidate first
+type minHeap []candidate
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].dist < h[j].dist }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// maxHeap pops the farthest candidate first
+type maxHeap []candidate
+
+func (h maxHeap) Len() int            { return len(h) }
+func (h maxHeap) Less(i, j int) bool  { return h[i].dist > h[j].dist }
+func (h maxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+type hnswNode struct {
+	id        string
+	vector    []float32
+	neighbors [][]int // per layer, 0 is the densest
+	deleted   bool
+}
+
+// hnsw is a Hierarchical Navigable Small World graph (Malkov & Yashunin).
+// Removed documents are tombstoned and the graph is rebuilt once they make
+// up half of it.
+type hnsw struct {
+	m              int
+	efConstruction int
+	efSearch       int
+	levelMult      float64
+	distance       distanceFunc
+	rng            *rand.Rand
+
+	nodes    []hnswNode
+	ids      map[string]int
+	entry    int
+	maxLevel int
+	deleted  int
+}
+
+func newHNSW(m, efConstruction, efSearch int, distance distanceFunc) *hnsw {
+	return &hnsw{
+		m:              m,
+		efConstruction: efConstruction,
+		efSearch:       efSearch,
+		levelMult:      1 / math.Log(float64(m)),
+		distance:       distance,
+		// A fixed seed keeps graphs, and therefore results, reproducible
+		rng:   rand.New(rand.NewSource(1)),
+		ids:   make(map[string]int),
+		entry: -1,
+	}
+}
+
+func (h *hnsw) maxNeighbors(layer int) int {
+	if layer == 0 {
+		return 2 * h.m
+	}
+	return h.m
+}
+
+func (h *hnsw) add(id string, vector []float32) {
+	h.remove(id)
+
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+	node := len(h.nodes)
+	h.nodes = append(h.nodes, hnswNode{id: id, vector: vector, neighbors: make([][]int, level+1)})
+	h.ids[id] = node
+
+	if h.entry < 0 {
+		h.entry = node
+		h.maxLevel = level
+		return
+	}
+
+	ep := h.entry
+	for layer := h.maxLevel; layer > level; layer-- {
+		ep = h.greedy(vector, ep, layer)
+	}
+	for layer := min(level, h.maxLevel); layer >= 0; layer-- {
+		found := h.searchLayer(vector, ep, h.efConstruction, layer, h.live)
+		if len(found) == 0 {
+			continue
+		}
+		neighbors := found
+		if len(neighbors) > h.m {
+			neighbors = neighbors[:h.m]
+		}
+		for _, c := range neighbors {
+			h.nodes[node].neighbors[layer] = append(h.nodes[node].neighbors[layer], c.node)
+			h.link(c.node, node, layer)
+		}
+		ep = found[0].node
+	}
+	if level > h.maxLevel {
+		h.entry = node
+		h.maxLevel = level
+	}
+}
+
+// link adds to as a neighbor of from, keeping only the closest neighbors
+func (h *hnsw) link(from, to, layer int) {
+	neighbors := append(h.nodes[from].neighbors[layer], to)
+	if limit := h.maxNeighbors(layer); len(neighbors) > limit {
+		vector := h.nodes[from].vector
+		sort.Slice(neighbors, func(i, j int) bool {
+			return h.distance(vector, h.nodes[neighbors[i]].vector) < h.distance(vector, h.nodes[neighbors[j]].vector)
+		})
+		neighbors = neighbors[:limit]
+	}
+	h.nodes[from].neighbors[layer] = neighbors
+}
+
+func (h *hnsw) remove(id string) {
+	node, ok := h.ids[id]
+	if !ok {
+		return
+	}
+	delete(h.ids, id)
+	h.nodes[node].deleted = true
+	h.deleted++
+	if h.deleted*2 >= len(h.nodes) {
+		h.rebuild()
+	}
+}
+
+func (h *hnsw) rebuild() {
+	nodes := h.nodes
+	h.nodes = nil
+	h.ids = make(map[string]int)
+	h.entry = -1
+	h.maxLevel = 0
+	h.deleted = 0
+	for _, n := range nodes {
+		if !n.deleted {
+			h.add(n.id, n.vector)
+		}
+	}
+}
+
+func (h *hnsw) live(node int) bool { return !h.nodes[node].deleted }
+
+func (h *hnsw) len() int { return len(h.ids) }
+
+// greedy walks towards query on one layer and returns the closest node found
+func (h *hnsw) greedy(query []float32, ep, layer int) int {
+	best := ep
+	bestDist := h.distance(query, h.nodes[ep].vector)
+	for changed := true; changed; {
+		changed = false
+		for _, n := range h.nodes[best].neighbors[layer] {
+			if d := h.distance(query, h.nodes[n].vector); d < bestDist {
+				best, bestDist, changed = n, d, true
+			}
+		}
+	}
+	return best
+}
+
+// searchLayer returns up to ef nodes accepted by include, nearest first.
+// Rejected nodes are still traversed so filters do not disconnect the graph.
+func (h *hnsw) searchLayer(query []float32, ep, ef, layer int, include func(int) bool) []candidate {
+	visited := map[int]bool{ep: true}
+	start := candidate{node: ep, dist: h.distance(query, h.nodes[ep].vector)}
+	candidates := &minHeap{start}
+	results := &maxHeap{}
+	if include(ep) {
+		heap.Push(results, start)
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(candidate)
+		if results.Len() >= ef && c.dist > (*results)[0].dist {
+			break
+		}
+		for _, n := range h.nodes[c.node].neighbors[layer] {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+			d := h.distance(query, h.nodes[n].vector)
+			if results.Len() >= ef && d >= (*results)[0].dist {
+				continue
+			}
+			heap.Push(candidates, candidate{node: n, dist: d})
+			if include(n) {
+				heap.Push(results, candidate{node: n, dist: d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	found := make([]candidate, results.Len())
+	for i := len(found) - 1; i >= 0; i-- {
+		found[i] = heap.Pop(results).(candidate)
+	}
+	return found
+}
+
+// search returns the k nearest live documents accepted by include
+func (h *hnsw) search(query []float32, k int, include func(id string) bool) []match {
+	if h.entry < 0 || k <= 0 {
+		return nil
+	}
+	ep := h.entry
+	for layer := h.maxLevel; layer > 0; layer-- {
+		ep = h.greedy(query, ep, layer)
+	}
+	found := h.searchLayer(query, ep, max(h.efSearch, k), 0, func(node int) bool {
+		return h.live(node) && (include == nil || include(h.nodes[node].id))
+	})
+	if len(found) > k {
+		found = found[:k]
+	}
+	matches := make([]match, len(found))
+	for i, c := range found {
+		matches[i] = match{id: h.nodes[c.node].id, dist: c.dist}
+	}
+	return matches
+}
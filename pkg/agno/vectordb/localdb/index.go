@@ -0,0 +1,107 @@
+package localdb
+
+import (
+	"math"
+	"sort"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+// match is a search hit; lower dist is closer
+type match struct {
+	id   string
+	dist float64
+}
+
+// vectorIndex finds the nearest embeddings of a collection
+type vectorIndex interface {
+	add(id string, vector []float32)
+	remove(id string)
+	search(query []float32, k int, include func(id string) bool) []match
+	len() int
+}
+
+// distanceFunc returns a distance where lower is closer
+type distanceFunc func(a, b []float32) float64
+
+func distanceFor(fn vectordb.DistanceFunction) distanceFunc {
+	switch fn {
+	case vectordb.Cosine:
+		return cosineDistance
+	case vectordb.InnerProduct:
+		return func(a, b []float32) float64 { return -dot(a, b) }
+	default:
+		return squaredL2
+	}
+}
+
+// scoreFor converts a distance to a similarity score (higher is better)
+func scoreFor(fn vectordb.DistanceFunction, dist float64) float64 {
+	switch fn {
+	case vectordb.Cosine:
+		return 1 - dist
+	default:
+		return -dist
+	}
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func squaredL2(a, b []float32) float64 {
+	var sum float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		d := float64(a[i]) - float64(b[i])
+		sum += d * d
+	}
+	return sum
+}
+
+func cosineDistance(a, b []float32) float64 {
+	na, nb := dot(a, a), dot(b, b)
+	if na == 0 || nb == 0 {
+		return 1
+	}
+	return 1 - dot(a, b)/(math.Sqrt(na)*math.Sqrt(nb))
+}
+
+// flatIndex compares the query against every embedding
+type flatIndex struct {
+	distance distanceFunc
+	vectors  map[string][]float32
+}
+
+func newFlatIndex(distance distanceFunc) *flatIndex {
+	return &flatIndex{distance: distance, vectors: make(map[string][]float32)}
+}
+
+func (f *flatIndex) add(id string, vector []float32) { f.vectors[id] = vector }
+
+func (f *flatIndex) remove(id string) { delete(f.vectors, id) }
+
+func (f *flatIndex) len() int { return len(f.vectors) }
+
+func (f *flatIndex) search(query []float32, k int, include func(id string) bool) []match {
+	matches := make([]match, 0, len(f.vectors))
+	for id, vector := range f.vectors {
+		if include != nil && !include(id) {
+			continue
+		}
+		matches = append(matches, match{id: id, dist: f.distance(query, vector)})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		return matches[i].id < matches[j].id
+	})
+	if k > 0 && len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
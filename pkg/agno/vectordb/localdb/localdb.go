@@ -0,0 +1,457 @@
+package localdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+// IndexType selects the in-memory search index
+type IndexType string
+
+const (
+	// IndexFlat compares the query with every document (exact)
+	IndexFlat IndexType = "flat"
+	// IndexHNSW searches a navigable small-world graph (approximate)
+	IndexHNSW IndexType = "hnsw"
+)
+
+// Config holds LocalDB configuration
+type Config struct {
+	// CollectionName is the name of the collection to use
+	CollectionName string
+
+	// Path of the SQLite file persisting collections. If empty, data lives
+	// in memory only.
+	Path string
+
+	// DistanceFunction for new collections (default: L2)
+	DistanceFunction vectordb.DistanceFunction
+
+	// Index used for search (default: flat)
+	Index IndexType
+
+	// HNSWM is the number of graph neighbors per node (default: 16)
+	HNSWM int
+
+	// HNSWEfConstruction is the candidate list size when inserting (default: 200)
+	HNSWEfConstruction int
+
+	// HNSWEfSearch is the candidate list size when searching (default: 64)
+	HNSWEfSearch int
+
+	// EmbeddingFunction to use for generating embeddings
+	// If nil, documents must already have embeddings
+	EmbeddingFunction vectordb.EmbeddingFunction
+}
+
+// LocalDB is an in-process VectorDB for tests and small deployments.
+// Documents are held in memory, searched with a flat or HNSW index and
+// optionally persisted to SQLite, from which indexes are rebuilt on open.
+type LocalDB struct {
+	mu            sync.RWMutex
+	config        Config
+	store         *sqliteStore
+	collections   map[string]*collection
+	current       string
+	embeddingFunc vectordb.EmbeddingFunction
+}
+
+type collection struct {
+	distance   vectordb.DistanceFunction
+	dimensions int
+	docs       map[string]vectordb.Document
+	index      vectorIndex
+}
+
+// New creates a LocalDB, loading persisted collections when Path is set
+func New(config Config) (*LocalDB, error) {
+	if config.CollectionName == "" {
+		return nil, fmt.Errorf("collection name is required")
+	}
+	if config.DistanceFunction == "" {
+		config.DistanceFunction = vectordb.L2
+	}
+	switch config.DistanceFunction {
+	case vectordb.L2, vectordb.Cosine, vectordb.InnerProduct:
+	default:
+		return nil, fmt.Errorf("unsupported distance function: %s", config.DistanceFunction)
+	}
+	if config.Index == "" {
+		config.Index = IndexFlat
+	}
+	if config.Index != IndexFlat && config.Index != IndexHNSW {
+		return nil, fmt.Errorf("unsupported index type: %s", config.Index)
+	}
+	if config.HNSWM <= 1 {
+		config.HNSWM = 16
+	}
+	if config.HNSWEfConstruction <= 0 {
+		config.HNSWEfConstruction = 200
+	}
+	if config.HNSWEfSearch <= 0 {
+		config.HNSWEfSearch = 64
+	}
+
+	l := &LocalDB{
+		config:        config,
+		collections:   make(map[string]*collection),
+		current:       config.CollectionName,
+		embeddingFunc: config.EmbeddingFunction,
+	}
+	if config.Path != "" {
+		store, err := openSQLiteStore(config.Path)
+		if err != nil {
+			return nil, err
+		}
+		l.store = store
+		if err := l.load(context.Background()); err != nil {
+			store.close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *LocalDB) newCollection(distance vectordb.DistanceFunction) *collection {
+	var index vectorIndex
+	if l.config.Index == IndexHNSW {
+		index = newHNSW(l.config.HNSWM, l.config.HNSWEfConstruction, l.config.HNSWEfSearch, distanceFor(distance))
+	} else {
+		index = newFlatIndex(distanceFor(distance))
+	}
+	return &collection{distance: distance, docs: make(map[string]vectordb.Document), index: index}
+}
+
+func (l *LocalDB) load(ctx context.Context) error {
+	collections, err := l.store.loadCollections(ctx)
+	if err != nil {
+		return err
+	}
+	for name, distance := range collections {
+		l.collections[name] = l.newCollection(distance)
+	}
+	return l.store.loadDocuments(ctx, func(name string, doc vectordb.Document) {
+		if c := l.collections[name]; c != nil {
+			c.put(doc)
+		}
+	})
+}
+
+func (c *collection) put(doc vectordb.Document) {
+	c.docs[doc.ID] = doc
+	if len(doc.Embedding) == 0 {
+		c.index.remove(doc.ID)
+		return
+	}
+	if c.dimensions == 0 {
+		c.dimensions = len(doc.Embedding)
+	}
+	c.index.add(doc.ID, doc.Embedding)
+}
+
+func (c *collection) checkDimensions(docs []vectordb.Document) error {
+	dimensions := c.dimensions
+	for _, doc := range docs {
+		if len(doc.Embedding) == 0 {
+			continue
+		}
+		if dimensions == 0 {
+			dimensions = len(doc.Embedding)
+		}
+		if len(doc.Embedding) != dimensions {
+			return fmt.Errorf("document %s has %d dimensions, collection expects %d", doc.ID, len(doc.Embedding), dimensions)
+		}
+	}
+	return nil
+}
+
+// active returns the current collection, creating it if needed; callers hold mu
+func (l *LocalDB) active(ctx context.Context) (*collection, error) {
+	if c := l.collections[l.current]; c != nil {
+		return c, nil
+	}
+	c := l.newCollection(l.config.DistanceFunction)
+	if l.store != nil {
+		if err := l.store.saveCollection(ctx, l.current, c.distance); err != nil {
+			return nil, err
+		}
+	}
+	l.collections[l.current] = c
+	return c, nil
+}
+
+// CreateCollection creates a new collection or connects to an existing one.
+// metadata may set "distance_function" for a new collection.
+func (l *LocalDB) CreateCollection(ctx context.Context, name string, metadata map[string]interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if name != "" {
+		l.current = name
+	}
+	if l.collections[l.current] != nil {
+		return nil
+	}
+
+	distance := l.config.DistanceFunction
+	if df, ok := metadata["distance_function"].(vectordb.DistanceFunction); ok {
+		switch df {
+		case vectordb.L2, vectordb.Cosine, vectordb.InnerProduct:
+			distance = df
+		default:
+			return fmt.Errorf("unsupported distance function: %s", df)
+		}
+	}
+	c := l.newCollection(distance)
+	if l.store != nil {
+		if err := l.store.saveCollection(ctx, l.current, distance); err != nil {
+			return err
+		}
+	}
+	l.collections[l.current] = c
+	return nil
+}
+
+// DeleteCollection deletes a collection and its documents
+func (l *LocalDB) DeleteCollection(ctx context.Context, name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if name == "" {
+		name = l.current
+	}
+	if l.store != nil {
+		if err := l.store.deleteCollection(ctx, name); err != nil {
+			return err
+		}
+	}
+	delete(l.collections, name)
+	return nil
+}
+
+// Add adds documents, replacing documents with the same ID
+func (l *LocalDB) Add(ctx context.Context, documents []vectordb.Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	documents, err := l.prepare(ctx, documents)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c, err := l.active(ctx)
+	if err != nil {
+		return err
+	}
+	return l.write(ctx, c, documents)
+}
+
+// Update updates existing documents; unknown IDs are ignored
+func (l *LocalDB) Update(ctx context.Context, documents []vectordb.Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	documents, err := l.prepare(ctx, documents)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c := l.collections[l.current]
+	if c == nil {
+		return nil
+	}
+	existing := documents[:0:0]
+	for _, doc := range documents {
+		if _, ok := c.docs[doc.ID]; ok {
+			existing = append(existing, doc)
+		}
+	}
+	return l.write(ctx, c, existing)
+}
+
+func (l *LocalDB) write(ctx context.Context, c *collection, documents []vectordb.Document) error {
+	if err := c.checkDimensions(documents); err != nil {
+		return err
+	}
+	if l.store != nil {
+		if err := l.store.saveDocuments(ctx, l.current, documents); err != nil {
+			return err
+		}
+	}
+	for _, doc := range documents {
+		c.put(doc)
+	}
+	return nil
+}
+
+// prepare copies documents, generating missing embeddings
+func (l *LocalDB) prepare(ctx context.Context, documents []vectordb.Document) ([]vectordb.Document, error) {
+	docs := make([]vectordb.Document, len(documents))
+	var (
+		missing  []int
+		contents []string
+	)
+	for i, doc := range documents {
+		if doc.ID == "" {
+			return nil, fmt.Errorf("document id is required")
+		}
+		docs[i] = doc
+		if len(doc.Embedding) == 0 {
+			missing = append(missing, i)
+			contents = append(contents, doc.Content)
+		}
+	}
+	if len(missing) == 0 || l.embeddingFunc == nil {
+		return docs, nil
+	}
+
+	embeddings, err := l.embeddingFunc.Embed(ctx, contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+	if len(embeddings) != len(missing) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(missing), len(embeddings))
+	}
+	for j, i := range missing {
+		docs[i].Embedding = embeddings[j]
+	}
+	return docs, nil
+}
+
+// Delete deletes documents by IDs
+func (l *LocalDB) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c := l.collections[l.current]
+	if c == nil {
+		return nil
+	}
+	if l.store != nil {
+		if err := l.store.deleteDocuments(ctx, l.current, ids); err != nil {
+			return err
+		}
+	}
+	for _, id := range ids {
+		delete(c.docs, id)
+		c.index.remove(id)
+	}
+	return nil
+}
+
+// Query searches for similar documents using text query
+func (l *LocalDB) Query(ctx context.Context, query string, limit int, filter map[string]interface{}) ([]vectordb.SearchResult, error) {
+	if l.embeddingFunc == nil {
+		return nil, fmt.Errorf("embedding function is required for text queries")
+	}
+	embedding, err := l.embeddingFunc.EmbedSingle(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	return l.QueryWithEmbedding(ctx, embedding, limit, filter)
+}
+
+// QueryWithEmbedding searches for similar documents using a pre-computed
+// embedding. filter follows vectordb.ValidateFilter.
+func (l *LocalDB) QueryWithEmbedding(ctx context.Context, embedding []float32, limit int, filter map[string]interface{}) ([]vectordb.SearchResult, error) {
+	if len(embedding) == 0 {
+		return nil, errors.New("embedding is required")
+	}
+	if err := vectordb.ValidateFilter(filter); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	c := l.collections[l.current]
+	if c == nil {
+		return nil, nil
+	}
+	if c.dimensions != 0 && len(embedding) != c.dimensions {
+		return nil, fmt.Errorf("query has %d dimensions, collection expects %d", len(embedding), c.dimensions)
+	}
+
+	var include func(id string) bool
+	if len(filter) > 0 {
+		include = func(id string) bool {
+			ok, _ := vectordb.MatchesFilter(c.docs[id].Metadata, filter)
+			return ok
+		}
+	}
+
+	matches := c.index.search(embedding, limit, include)
+	results := make([]vectordb.SearchResult, len(matches))
+	for i, m := range matches {
+		doc := c.docs[m.id]
+		results[i] = vectordb.SearchResult{
+			ID:       doc.ID,
+			Content:  doc.Content,
+			Metadata: doc.Metadata,
+			Score:    float32(scoreFor(c.distance, m.dist)),
+			Distance: float32(m.dist),
+		}
+	}
+	return results, nil
+}
+
+// Get retrieves documents by IDs
+func (l *LocalDB) Get(ctx context.Context, ids []string) ([]vectordb.Document, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	c := l.collections[l.current]
+	if c == nil {
+		return nil, nil
+	}
+	docs := make([]vectordb.Document, 0, len(ids))
+	for _, id := range ids {
+		if doc, ok := c.docs[id]; ok {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+// Count returns the number of documents in the collection
+func (l *LocalDB) Count(ctx context.Context) (int, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if c := l.collections[l.current]; c != nil {
+		return len(c.docs), nil
+	}
+	return 0, nil
+}
+
+// Close closes the SQLite file, if any
+func (l *LocalDB) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.store != nil {
+		err := l.store.close()
+		l.store = nil
+		return err
+	}
+	return nil
+}
+
+var _ vectordb.VectorDB = (*LocalDB)(nil)
@@ -0,0 +1,239 @@
+package localdb
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+type mockEmbedding struct{}
+
+func (mockEmbedding) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i], _ = mockEmbedding{}.EmbedSingle(ctx, text)
+	}
+	return out, nil
+}
+
+func (mockEmbedding) EmbedSingle(ctx context.Context, text string) ([]float32, error) {
+	return []float32{float32(len(text)), 1}, nil
+}
+
+func randomVectors(n, dim int, seed int64) [][]float32 {
+	rng := rand.New(rand.NewSource(seed))
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		vectors[i] = make([]float32, dim)
+		for j := range vectors[i] {
+			vectors[i][j] = rng.Float32()*2 - 1
+		}
+	}
+	return vectors
+}
+
+func TestLocalDB_Distances(t *testing.T) {
+	ctx := context.Background()
+	docs := []vectordb.Document{
+		{ID: "x", Embedding: []float32{1, 0}},
+		{ID: "y", Embedding: []float32{0, 1}},
+		{ID: "far", Embedding: []float32{10, 1}},
+	}
+
+	tests := []struct {
+		distance vectordb.DistanceFunction
+		want     string
+	}{
+		{vectordb.L2, "x"},
+		{vectordb.Cosine, "x"},
+		{vectordb.InnerProduct, "far"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.distance), func(t *testing.T) {
+			db, err := New(Config{CollectionName: "docs", DistanceFunction: tt.distance})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := db.Add(ctx, docs); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+			results, err := db.QueryWithEmbedding(ctx, []float32{1, 0}, 3, nil)
+			if err != nil {
+				t.Fatalf("QueryWithEmbedding() error = %v", err)
+			}
+			if len(results) != 3 || results[0].ID != tt.want {
+				t.Fatalf("unexpected results %+v", results)
+			}
+			if results[0].Score < results[2].Score {
+				t.Fatalf("scores not descending: %+v", results)
+			}
+		})
+	}
+}
+
+func TestLocalDB_Filter(t *testing.T) {
+	ctx := context.Background()
+	for _, index := range []IndexType{IndexFlat, IndexHNSW} {
+		t.Run(string(index), func(t *testing.T) {
+			db, _ := New(Config{CollectionName: "docs", Index: index})
+			vectors := randomVectors(200, 8, 1)
+			docs := make([]vectordb.Document, len(vectors))
+			for i, v := range vectors {
+				docs[i] = vectordb.Document{ID: fmt.Sprintf("doc-%d", i), Embedding: v, Metadata: map[string]interface{}{"n": i, "even": i%2 == 0}}
+			}
+			if err := db.Add(ctx, docs); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+
+			results, err := db.QueryWithEmbedding(ctx, vectors[0], 5, map[string]interface{}{
+				"$and": []interface{}{
+					map[string]interface{}{"even": false},
+					map[string]interface{}{"n": map[string]interface{}{"$lt": 20}},
+				},
+			})
+			if err != nil {
+				t.Fatalf("QueryWithEmbedding() error = %v", err)
+			}
+			if len(results) != 5 {
+				t.Fatalf("expected 5 results, got %d", len(results))
+			}
+			for _, r := range results {
+				if r.Metadata["even"] != false || r.Metadata["n"].(int) >= 20 {
+					t.Fatalf("result %s does not match the filter", r.ID)
+				}
+			}
+
+			if _, err := db.QueryWithEmbedding(ctx, vectors[0], 5, map[string]interface{}{"$xor": 1}); err == nil {
+				t.Fatal("expected error for an invalid filter")
+			}
+		})
+	}
+}
+
+func TestHNSW_Recall(t *testing.T) {
+	vectors := randomVectors(1000, 16, 2)
+	queries := randomVectors(50, 16, 3)
+
+	flat := newFlatIndex(squaredL2)
+	graph := newHNSW(16, 200, 64, squaredL2)
+	for i, v := range vectors {
+		id := fmt.Sprintf("doc-%d", i)
+		flat.add(id, v)
+		graph.add(id, v)
+	}
+
+	const k = 10
+	hits := 0
+	for _, q := range queries {
+		want := make(map[string]bool)
+		for _, m := range flat.search(q, k, nil) {
+			want[m.id] = true
+		}
+		for _, m := range graph.search(q, k, nil) {
+			if want[m.id] {
+				hits++
+			}
+		}
+	}
+	if recall := float64(hits) / float64(len(queries)*k); recall < 0.9 {
+		t.Fatalf("recall = %.2f, want >= 0.9", recall)
+	}
+}
+
+func TestHNSW_RemoveAndRebuild(t *testing.T) {
+	graph := newHNSW(4, 50, 20, squaredL2)
+	vectors := randomVectors(40, 4, 4)
+	for i, v := range vectors {
+		graph.add(fmt.Sprintf("doc-%d", i), v)
+	}
+	for i := 0; i < 30; i++ {
+		graph.remove(fmt.Sprintf("doc-%d", i))
+	}
+	if graph.len() != 10 || len(graph.nodes) > 40 {
+		t.Fatalf("unexpected graph size: live=%d nodes=%d", graph.len(), len(graph.nodes))
+	}
+	results := graph.search(vectors[35], 10, nil)
+	if len(results) != 10 || results[0].id != "doc-35" {
+		t.Fatalf("unexpected results after removals %+v", results)
+	}
+}
+
+func TestLocalDB_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vectors.db")
+
+	db, err := New(Config{CollectionName: "docs", Path: path, Index: IndexHNSW, EmbeddingFunction: mockEmbedding{}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := db.CreateCollection(ctx, "", map[string]interface{}{"distance_function": vectordb.Cosine}); err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	err = db.Add(ctx, []vectordb.Document{
+		{ID: "a", Content: "alpha", Metadata: map[string]interface{}{"lang": "en"}},
+		{ID: "b", Content: "beta gamma", Metadata: map[string]interface{}{"lang": "es"}},
+		{ID: "c", Content: "delta"},
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := db.Delete(ctx, []string{"c"}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := db.Update(ctx, []vectordb.Document{{ID: "a", Content: "alpha v2", Metadata: map[string]interface{}{"lang": "en", "v": 2}}, {ID: "zzz"}}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened, err := New(Config{CollectionName: "docs", Path: path, Index: IndexHNSW, EmbeddingFunction: mockEmbedding{}})
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer reopened.Close()
+
+	if n, _ := reopened.Count(ctx); n != 2 {
+		t.Fatalf("Count() = %d, want 2", n)
+	}
+	docs, _ := reopened.Get(ctx, []string{"a", "c"})
+	if len(docs) != 1 || docs[0].Content != "alpha v2" || len(docs[0].Embedding) != 2 || docs[0].Metadata["v"] != float64(2) {
+		t.Fatalf("unexpected documents %+v", docs)
+	}
+	results, err := reopened.Query(ctx, "alpha v2", 5, map[string]interface{}{"lang": "en"})
+	if err != nil || len(results) != 1 || results[0].ID != "a" || results[0].Score < 0.99 {
+		t.Fatalf("Query() = %+v, %v", results, err)
+	}
+
+	if err := reopened.DeleteCollection(ctx, ""); err != nil {
+		t.Fatalf("DeleteCollection() error = %v", err)
+	}
+	if n, _ := reopened.Count(ctx); n != 0 {
+		t.Fatalf("Count() after delete = %d", n)
+	}
+}
+
+func TestLocalDB_Errors(t *testing.T) {
+	if _, err := New(Config{}); err == nil {
+		t.Fatal("expected error without a collection name")
+	}
+	if _, err := New(Config{CollectionName: "docs", Index: "ivf"}); err == nil {
+		t.Fatal("expected error for an unknown index")
+	}
+
+	ctx := context.Background()
+	db, _ := New(Config{CollectionName: "docs"})
+	if err := db.Add(ctx, []vectordb.Document{{ID: "a", Embedding: []float32{1, 2}}}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := db.Add(ctx, []vectordb.Document{{ID: "b", Embedding: []float32{1, 2, 3}}}); err == nil {
+		t.Fatal("expected error for mismatched dimensions")
+	}
+	if _, err := db.Query(ctx, "text", 1, nil); err == nil {
+		t.Fatal("expected error without an embedding function")
+	}
+}
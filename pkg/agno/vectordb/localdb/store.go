@@ -0,0 +1,190 @@
+package localdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS vector_collections (
+	name TEXT PRIMARY KEY,
+	distance TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS vector_documents (
+	collection TEXT NOT NULL,
+	id TEXT NOT NULL,
+	content TEXT NOT NULL,
+	metadata TEXT,
+	embedding BLOB,
+	PRIMARY KEY (collection, id)
+);`
+
+// sqliteStore persists collections; the in-memory indexes are the source
+// for queries
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func openSQLiteStore(path string) (*sqliteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	// SQLite allows a single writer; one connection avoids "database is locked"
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) close() error { return s.db.Close() }
+
+func (s *sqliteStore) loadCollections(ctx context.Context) (map[string]vectordb.DistanceFunction, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name, distance FROM vector_collections`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := make(map[string]vectordb.DistanceFunction)
+	for rows.Next() {
+		var name, distance string
+		if err := rows.Scan(&name, &distance); err != nil {
+			return nil, fmt.Errorf("failed to load collections: %w", err)
+		}
+		collections[name] = vectordb.DistanceFunction(distance)
+	}
+	return collections, rows.Err()
+}
+
+func (s *sqliteStore) loadDocuments(ctx context.Context, fn func(collection string, doc vectordb.Document)) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT collection, id, content, metadata, embedding FROM vector_documents`)
+	if err != nil {
+		return fmt.Errorf("failed to load documents: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			name      string
+			doc       vectordb.Document
+			metadata  sql.NullString
+			embedding []byte
+		)
+		if err := rows.Scan(&name, &doc.ID, &doc.Content, &metadata, &embedding); err != nil {
+			return fmt.Errorf("failed to load documents: %w", err)
+		}
+		if metadata.Valid && metadata.String != "" {
+			if err := json.Unmarshal([]byte(metadata.String), &doc.Metadata); err != nil {
+				return fmt.Errorf("failed to decode metadata of %s: %w", doc.ID, err)
+			}
+		}
+		doc.Embedding = decodeVector(embedding)
+		fn(name, doc)
+	}
+	return rows.Err()
+}
+
+func (s *sqliteStore) saveCollection(ctx context.Context, name string, distance vectordb.DistanceFunction) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO vector_collections (name, distance) VALUES (?, ?) ON CONFLICT(name) DO NOTHING`,
+		name, string(distance))
+	if err != nil {
+		return fmt.Errorf("failed to save collection: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) deleteCollection(ctx context.Context, name string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM vector_documents WHERE collection = ?`, name); err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM vector_collections WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) saveDocuments(ctx context.Context, name string, docs []vectordb.Document) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO vector_documents
+		(collection, id, content, metadata, embedding) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, doc := range docs {
+		var metadata interface{}
+		if doc.Metadata != nil {
+			b, err := json.Marshal(doc.Metadata)
+			if err != nil {
+				return fmt.Errorf("failed to encode metadata of %s: %w", doc.ID, err)
+			}
+			metadata = string(b)
+		}
+		if _, err := stmt.ExecContext(ctx, name, doc.ID, doc.Content, metadata, encodeVector(doc.Embedding)); err != nil {
+			return fmt.Errorf("failed to save document %s: %w", doc.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) deleteDocuments(ctx context.Context, name string, ids []string) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	args := make([]interface{}, 0, len(ids)+1)
+	args = append(args, name)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	query := fmt.Sprintf(`DELETE FROM vector_documents WHERE collection = ? AND id IN (%s)`, placeholders)
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+	return nil
+}
+
+// encodeVector packs an embedding as little-endian float32
+func encodeVector(v []float32) []byte {
+	if len(v) == 0 {
+		return nil
+	}
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	if len(buf) < 4 {
+		return nil
+	}
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
@@ -0,0 +1,154 @@
+package pgvector
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+// filterBuilder translates a validated vectordb filter into a SQL condition
+// on the metadata column. Keys and values are always bound as parameters.
+type filterBuilder struct {
+	args []interface{}
+}
+
+func (b *filterBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// build returns a condition for filter, which must have passed
+// vectordb.ValidateFilter
+func (b *filterBuilder) build(filter map[string]interface{}) string {
+	if len(filter) == 0 {
+		return "TRUE"
+	}
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	conditions := make([]string, 0, len(keys))
+	for _, key := range keys {
+		switch key {
+		case vectordb.FilterAnd, vectordb.FilterOr:
+			conditions = append(conditions, b.clauses(key, filter[key]))
+		default:
+			conditions = append(conditions, b.field(key, filter[key]))
+		}
+	}
+	return joinConditions(conditions, " AND ")
+}
+
+func (b *filterBuilder) clauses(op string, value interface{}) string {
+	items, _ := vectordb.FilterList(value)
+	clauses := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if clause, ok := item.(map[string]interface{}); ok {
+			clauses = append(clauses, clause)
+		}
+	}
+	if len(clauses) == 0 {
+		// An empty $and matches everything and an empty $or nothing
+		if op == vectordb.FilterAnd {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	conditions := make([]string, len(clauses))
+	for i, clause := range clauses {
+		conditions[i] = b.build(clause)
+	}
+	sep := " AND "
+	if op == vectordb.FilterOr {
+		sep = " OR "
+	}
+	return joinConditions(conditions, sep)
+}
+
+func (b *filterBuilder) field(name string, condition interface{}) string {
+	ops, isOps := condition.(map[string]interface{})
+	if !isOps {
+		return b.equal(name, condition)
+	}
+	if len(ops) == 0 {
+		return "TRUE"
+	}
+	names := make([]string, 0, len(ops))
+	for op := range ops {
+		names = append(names, op)
+	}
+	sort.Strings(names)
+
+	conditions := make([]string, 0, len(ops))
+	for _, op := range names {
+		operand := ops[op]
+		switch op {
+		case vectordb.FilterEq:
+			conditions = append(conditions, b.equal(name, operand))
+		case vectordb.FilterNe:
+			conditions = append(conditions, "NOT "+b.equal(name, operand))
+		case vectordb.FilterGt, vectordb.FilterGte, vectordb.FilterLt, vectordb.FilterLte:
+			conditions = append(conditions, b.compare(name, op, operand))
+		case vectordb.FilterIn, vectordb.FilterNin:
+			in := b.in(name, operand)
+			if op == vectordb.FilterNin {
+				in = "NOT " + in
+			}
+			conditions = append(conditions, in)
+		}
+	}
+	return joinConditions(conditions, " AND ")
+}
+
+// equal uses JSONB containment, which compares numbers by value and can use
+// the GIN index on metadata
+func (b *filterBuilder) equal(name string, value interface{}) string {
+	doc, _ := json.Marshal(map[string]interface{}{name: value})
+	return "(metadata @> " + b.arg(string(doc)) + "::jsonb)"
+}
+
+func (b *filterBuilder) in(name string, value interface{}) string {
+	values, _ := vectordb.FilterList(value)
+	if len(values) == 0 {
+		return "FALSE"
+	}
+	conditions := make([]string, len(values))
+	for i, v := range values {
+		conditions[i] = b.equal(name, v)
+	}
+	return joinConditions(conditions, " OR ")
+}
+
+// compare only matches values of the operand's JSON type, mirroring
+// vectordb.MatchesFilter; strings compare bytewise
+func (b *filterBuilder) compare(name, op string, value interface{}) string {
+	sqlOp := map[string]string{
+		vectordb.FilterGt:  ">",
+		vectordb.FilterGte: ">=",
+		vectordb.FilterLt:  "<",
+		vectordb.FilterLte: "<=",
+	}[op]
+
+	key := b.arg(name) + "::text"
+	if n, ok := vectordb.FilterNumber(value); ok {
+		return "(CASE WHEN jsonb_typeof(metadata->" + key + ") = 'number' THEN (metadata->>" + key + ")::numeric " +
+			sqlOp + " " + b.arg(n) + " ELSE FALSE END)"
+	}
+	if s, ok := value.(string); ok {
+		return "(CASE WHEN jsonb_typeof(metadata->" + key + ") = 'string' THEN (metadata->>" + key + ") COLLATE \"C\" " +
+			sqlOp + " " + b.arg(s) + " ELSE FALSE END)"
+	}
+	return "FALSE"
+}
+
+func joinConditions(conditions []string, sep string) string {
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return "(" + strings.Join(conditions, sep) + ")"
+}
@@ -0,0 +1,521 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	// Registers the "pgx" database/sql driver used when Config.DSN is set
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// IndexType selects the pgvector index built by CreateCollection
+type IndexType string
+
+const (
+	// IndexNone performs exact sequential scans
+	IndexNone IndexType = ""
+	// IndexHNSW builds an approximate graph index
+	IndexHNSW IndexType = "hnsw"
+	// IndexIVFFlat builds an approximate inverted-list index; create it after
+	// loading data so the lists reflect the distribution
+	IndexIVFFlat IndexType = "ivfflat"
+)
+
+// Config holds pgvector configuration
+type Config struct {
+	// DB is an existing connection pool. If nil, DSN is opened with the pgx driver.
+	DB *sql.DB
+
+	// DSN is the Postgres connection string used when DB is nil
+	DSN string
+
+	// Schema holding the collection tables (default: public)
+	Schema string
+
+	// CollectionName is the table storing the collection
+	CollectionName string
+
+	// Dimensions of the embeddings; required by CreateCollection
+	Dimensions int
+
+	// DistanceFunction to use for similarity search (default: L2)
+	DistanceFunction vectordb.DistanceFunction
+
+	// Index built by CreateCollection (default: none)
+	Index IndexType
+
+	// HNSWM and HNSWEfConstruction tune the HNSW index (defaults: 16, 64)
+	HNSWM              int
+	HNSWEfConstruction int
+
+	// IVFFlatLists is the number of IVFFlat lists (default: 100)
+	IVFFlatLists int
+
+	// EmbeddingFunction to use for generating embeddings
+	// If nil, documents must already have embeddings
+	EmbeddingFunction vectordb.EmbeddingFunction
+}
+
+// PgVector implements the VectorDB interface on Postgres with the pgvector
+// extension. Each collection is a table with id, content, JSONB metadata and
+// a vector column.
+type PgVector struct {
+	db            *sql.DB
+	ownsDB        bool
+	config        Config
+	schema        string
+	collection    string
+	distance      vectordb.DistanceFunction
+	embeddingFunc vectordb.EmbeddingFunction
+}
+
+// New creates a new pgvector client
+func New(config Config) (*PgVector, error) {
+	if config.CollectionName == "" {
+		return nil, fmt.Errorf("collection name is required")
+	}
+	if !identifierPattern.MatchString(config.CollectionName) {
+		return nil, fmt.Errorf("invalid collection name: %s", config.CollectionName)
+	}
+	if config.Schema == "" {
+		config.Schema = "public"
+	}
+	if !identifierPattern.MatchString(config.Schema) {
+		return nil, fmt.Errorf("invalid schema name: %s", config.Schema)
+	}
+	if config.DistanceFunction == "" {
+		config.DistanceFunction = vectordb.L2
+	}
+	if _, _, err := distanceOperator(config.DistanceFunction); err != nil {
+		return nil, err
+	}
+	switch config.Index {
+	case IndexNone, IndexHNSW, IndexIVFFlat:
+	default:
+		return nil, fmt.Errorf("unsupported index type: %s", config.Index)
+	}
+	if config.HNSWM <= 0 {
+		config.HNSWM = 16
+	}
+	if config.HNSWEfConstruction <= 0 {
+		config.HNSWEfConstruction = 64
+	}
+	if config.IVFFlatLists <= 0 {
+		config.IVFFlatLists = 100
+	}
+
+	db := config.DB
+	ownsDB := false
+	if db == nil {
+		if config.DSN == "" {
+			return nil, fmt.Errorf("db or dsn is required")
+		}
+		var err error
+		db, err = sql.Open("pgx", config.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open postgres: %w", err)
+		}
+		ownsDB = true
+	}
+
+	return &PgVector{
+		db:            db,
+		ownsDB:        ownsDB,
+		config:        config,
+		schema:        config.Schema,
+		collection:    config.CollectionName,
+		distance:      config.DistanceFunction,
+		embeddingFunc: config.EmbeddingFunction,
+	}, nil
+}
+
+func (p *PgVector) table() string {
+	return fmt.Sprintf(`"%s"."%s"`, p.schema, p.collection)
+}
+
+// distanceOperator returns the pgvector operator and operator class for fn
+func distanceOperator(fn vectordb.DistanceFunction) (op string, opClass string, err error) {
+	switch fn {
+	case vectordb.L2:
+		return "<->", "vector_l2_ops", nil
+	case vectordb.Cosine:
+		return "<=>", "vector_cosine_ops", nil
+	case vectordb.InnerProduct:
+		return "<#>", "vector_ip_ops", nil
+	default:
+		return "", "", fmt.Errorf("unsupported distance function: %s", fn)
+	}
+}
+
+// CreateCollection creates the collection table, its vector index and a GIN
+// index on metadata. metadata may override "distance_function" and
+// "dimensions".
+func (p *PgVector) CreateCollection(ctx context.Context, name string, metadata map[string]interface{}) error {
+	if name != "" {
+		if !identifierPattern.MatchString(name) {
+			return fmt.Errorf("invalid collection name: %s", name)
+		}
+		p.collection = name
+	}
+
+	dimensions := p.config.Dimensions
+	if metadata != nil {
+		if df, ok := metadata["distance_function"].(vectordb.DistanceFunction); ok {
+			if _, _, err := distanceOperator(df); err != nil {
+				return err
+			}
+			p.distance = df
+		}
+		if d, ok := vectordb.FilterNumber(metadata["dimensions"]); ok {
+			dimensions = int(d)
+		}
+	}
+	if dimensions <= 0 {
+		return fmt.Errorf("dimensions are required to create a collection")
+	}
+
+	_, opClass, _ := distanceOperator(p.distance)
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id TEXT PRIMARY KEY,
+	content TEXT NOT NULL DEFAULT '',
+	metadata JSONB NOT NULL DEFAULT '{}',
+	embedding vector(%d)
+)`, p.table(), dimensions),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS "%s_metadata_idx" ON %s USING gin (metadata)`, p.collection, p.table()),
+	}
+	switch p.config.Index {
+	case IndexHNSW:
+		statements = append(statements, fmt.Sprintf(
+			`CREATE INDEX IF NOT EXISTS "%s_embedding_idx" ON %s USING hnsw (embedding %s) WITH (m = %d, ef_construction = %d)`,
+			p.collection, p.table(), opClass, p.config.HNSWM, p.config.HNSWEfConstruction))
+	case IndexIVFFlat:
+		statements = append(statements, fmt.Sprintf(
+			`CREATE INDEX IF NOT EXISTS "%s_embedding_idx" ON %s USING ivfflat (embedding %s) WITH (lists = %d)`,
+			p.collection, p.table(), opClass, p.config.IVFFlatLists))
+	}
+
+	for _, stmt := range statements {
+		if _, err := p.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to create collection: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteCollection drops the collection table
+func (p *PgVector) DeleteCollection(ctx context.Context, name string) error {
+	if name != "" {
+		if !identifierPattern.MatchString(name) {
+			return fmt.Errorf("invalid collection name: %s", name)
+		}
+		p.collection = name
+	}
+	if _, err := p.db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS %s`, p.table())); err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	return nil
+}
+
+// Add inserts documents, replacing existing documents with the same ID
+func (p *PgVector) Add(ctx context.Context, documents []vectordb.Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	if err := p.embedMissing(ctx, documents); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (id, content, metadata, embedding)
+VALUES ($1, $2, $3::jsonb, $4::vector)
+ON CONFLICT (id) DO UPDATE SET content = EXCLUDED.content, metadata = EXCLUDED.metadata, embedding = EXCLUDED.embedding`, p.table())
+	return p.writeDocuments(ctx, query, documents)
+}
+
+// Update updates existing documents; unknown IDs are ignored
+func (p *PgVector) Update(ctx context.Context, documents []vectordb.Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	if err := p.embedMissing(ctx, documents); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET content = $2, metadata = $3::jsonb, embedding = $4::vector WHERE id = $1`, p.table())
+	return p.writeDocuments(ctx, query, documents)
+}
+
+func (p *PgVector) writeDocuments(ctx context.Context, query string, documents []vectordb.Document) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, doc := range documents {
+		metadata, err := json.Marshal(doc.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata for %s: %w", doc.ID, err)
+		}
+		if doc.Metadata == nil {
+			metadata = []byte("{}")
+		}
+		var embedding interface{}
+		if len(doc.Embedding) > 0 {
+			embedding = encodeVector(doc.Embedding)
+		}
+		if _, err := tx.ExecContext(ctx, query, doc.ID, doc.Content, string(metadata), embedding); err != nil {
+			return fmt.Errorf("failed to write document %s: %w", doc.ID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit documents: %w", err)
+	}
+	return nil
+}
+
+// embedMissing fills in embeddings for documents without one
+func (p *PgVector) embedMissing(ctx context.Context, documents []vectordb.Document) error {
+	if p.embeddingFunc == nil {
+		return nil
+	}
+	var (
+		indexes  []int
+		contents []string
+	)
+	for i, doc := range documents {
+		if len(doc.Embedding) == 0 {
+			indexes = append(indexes, i)
+			contents = append(contents, doc.Content)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+	embeddings, err := p.embeddingFunc.Embed(ctx, contents)
+	if err != nil {
+		return fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+	if len(embeddings) != len(indexes) {
+		return fmt.Errorf("expected %d embeddings, got %d", len(indexes), len(embeddings))
+	}
+	for j, i := range indexes {
+		documents[i].Embedding = embeddings[j]
+	}
+	return nil
+}
+
+// Delete deletes documents by IDs
+func (p *PgVector) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	placeholders, args := idPlaceholders(ids)
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id IN (%s)`, p.table(), placeholders)
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+	return nil
+}
+
+// Query searches for similar documents using text query
+func (p *PgVector) Query(ctx context.Context, query string, limit int, filter map[string]interface{}) ([]vectordb.SearchResult, error) {
+	if p.embeddingFunc == nil {
+		return nil, fmt.Errorf("embedding function is required for text queries")
+	}
+	embedding, err := p.embeddingFunc.EmbedSingle(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	return p.QueryWithEmbedding(ctx, embedding, limit, filter)
+}
+
+// QueryWithEmbedding searches for similar documents using a pre-computed
+// embedding. filter follows vectordb.ValidateFilter and is evaluated on the
+// JSONB metadata column.
+func (p *PgVector) QueryWithEmbedding(ctx context.Context, embedding []float32, limit int, filter map[string]interface{}) ([]vectordb.SearchResult, error) {
+	if len(embedding) == 0 {
+		return nil, errors.New("embedding is required")
+	}
+	if err := vectordb.ValidateFilter(filter); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	op, _, _ := distanceOperator(p.distance)
+	b := &filterBuilder{args: []interface{}{encodeVector(embedding)}}
+	where := b.build(filter)
+	b.args = append(b.args, limit)
+
+	query := fmt.Sprintf(`SELECT id, content, metadata, embedding %[1]s $1::vector AS distance
+FROM %[2]s
+WHERE embedding IS NOT NULL AND %[3]s
+ORDER BY embedding %[1]s $1::vector
+LIMIT $%[4]d`, op, p.table(), where, len(b.args))
+
+	rows, err := p.db.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	var results []vectordb.SearchResult
+	for rows.Next() {
+		var (
+			result   vectordb.SearchResult
+			metadata []byte
+			distance float64
+		)
+		if err := rows.Scan(&result.ID, &result.Content, &metadata, &distance); err != nil {
+			return nil, fmt.Errorf("failed to scan result: %w", err)
+		}
+		result.Metadata = decodeMetadata(metadata)
+		score, dist := scoreDistance(distance, p.distance)
+		result.Score = float32(score)
+		result.Distance = float32(dist)
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read results: %w", err)
+	}
+	return results, nil
+}
+
+// scoreDistance converts a pgvector operator result to a similarity score
+// (higher is better) and a distance (lower is better)
+func scoreDistance(value float64, fn vectordb.DistanceFunction) (score, distance float64) {
+	switch fn {
+	case vectordb.Cosine:
+		return 1 - value, value
+	case vectordb.InnerProduct:
+		// <#> returns the negative inner product
+		return -value, value
+	default:
+		return 1 / (1 + value), value
+	}
+}
+
+// Get retrieves documents by IDs
+func (p *PgVector) Get(ctx context.Context, ids []string) ([]vectordb.Document, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	placeholders, args := idPlaceholders(ids)
+	query := fmt.Sprintf(`SELECT id, content, metadata, embedding::text FROM %s WHERE id IN (%s)`, p.table(), placeholders)
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []vectordb.Document
+	for rows.Next() {
+		var (
+			doc       vectordb.Document
+			metadata  []byte
+			embedding sql.NullString
+		)
+		if err := rows.Scan(&doc.ID, &doc.Content, &metadata, &embedding); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		doc.Metadata = decodeMetadata(metadata)
+		if embedding.Valid {
+			if doc.Embedding, err = decodeVector(embedding.String); err != nil {
+				return nil, err
+			}
+		}
+		docs = append(docs, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read documents: %w", err)
+	}
+	return docs, nil
+}
+
+// Count returns the number of documents in the collection
+func (p *PgVector) Count(ctx context.Context) (int, error) {
+	var count int
+	if err := p.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s`, p.table())).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	return count, nil
+}
+
+// Close closes the connection pool if it was opened from Config.DSN
+func (p *PgVector) Close() error {
+	if p.ownsDB {
+		return p.db.Close()
+	}
+	return nil
+}
+
+func idPlaceholders(ids []string) (string, []interface{}) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+		args[i] = id
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+func decodeMetadata(raw []byte) map[string]interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(raw, &metadata); err != nil || len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
+// encodeVector formats an embedding in the pgvector text format
+func encodeVector(v []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, f := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// decodeVector parses the pgvector text format
+func decodeVector(s string) ([]float32, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return nil, fmt.Errorf("invalid vector %q", s)
+	}
+	s = s[1 : len(s)-1]
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	v := make([]float32, len(parts))
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector component %q: %w", part, err)
+		}
+		v[i] = float32(f)
+	}
+	return v, nil
+}
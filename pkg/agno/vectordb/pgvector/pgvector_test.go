@@ -0,0 +1,202 @@
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+func newMockDB(t *testing.T, config Config) (*PgVector, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	config.DB = db
+	if config.CollectionName == "" {
+		config.CollectionName = "docs"
+	}
+	pg, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return pg, mock
+}
+
+func TestNew_Validation(t *testing.T) {
+	db, _, _ := sqlmock.New()
+	defer db.Close()
+
+	invalid := []Config{
+		{DB: db},
+		{DB: db, CollectionName: "docs; DROP TABLE x"},
+		{DB: db, CollectionName: "docs", Schema: "bad-schema"},
+		{DB: db, CollectionName: "docs", DistanceFunction: "manhattan"},
+		{DB: db, CollectionName: "docs", Index: "lsh"},
+		{CollectionName: "docs"},
+	}
+	for _, config := range invalid {
+		if _, err := New(config); err == nil {
+			t.Errorf("New(%+v) expected error", config)
+		}
+	}
+}
+
+func TestCreateCollection_HNSW(t *testing.T) {
+	pg, mock := newMockDB(t, Config{Dimensions: 3, Index: IndexHNSW})
+
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE EXTENSION IF NOT EXISTS vector`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "public"."kb"`) + `(?s).*embedding vector\(3\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`USING gin (metadata)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`USING hnsw (embedding vector_cosine_ops) WITH (m = 16, ef_construction = 64)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := pg.CreateCollection(context.Background(), "kb", map[string]interface{}{"distance_function": vectordb.Cosine})
+	if err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateCollection_RequiresDimensions(t *testing.T) {
+	pg, _ := newMockDB(t, Config{})
+	if err := pg.CreateCollection(context.Background(), "", nil); err == nil {
+		t.Fatal("expected error without dimensions")
+	}
+}
+
+func TestAdd_UpsertsInTransaction(t *testing.T) {
+	pg, mock := newMockDB(t, Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "public"."docs"`)).
+		WithArgs("a", "alpha", `{"lang":"en"}`, "[0.5,-1]").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := pg.Add(context.Background(), []vectordb.Document{
+		{ID: "a", Content: "alpha", Metadata: map[string]interface{}{"lang": "en"}, Embedding: []float32{0.5, -1}},
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryWithEmbedding_Filter(t *testing.T) {
+	pg, mock := newMockDB(t, Config{DistanceFunction: vectordb.Cosine})
+
+	rows := sqlmock.NewRows([]string{"id", "content", "metadata", "distance"}).
+		AddRow("a", "alpha", []byte(`{"lang":"en","year":2024}`), 0.25)
+	mock.ExpectQuery(regexp.QuoteMeta(`embedding <=> $1::vector AS distance`)+`(?s).*`+
+		regexp.QuoteMeta(`WHERE embedding IS NOT NULL AND ((metadata @> $2::jsonb) AND (CASE WHEN jsonb_typeof(metadata->$3::text) = 'number'`)+
+		`(?s).*`+regexp.QuoteMeta(`LIMIT $5`)).
+		WithArgs("[1,0]", `{"lang":"en"}`, "year", float64(2020), 5).
+		WillReturnRows(rows)
+
+	results, err := pg.QueryWithEmbedding(context.Background(), []float32{1, 0}, 5, map[string]interface{}{
+		"lang": "en",
+		"year": map[string]interface{}{"$gt": 2020},
+	})
+	if err != nil {
+		t.Fatalf("QueryWithEmbedding() error = %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "a" || results[0].Score != 0.75 || results[0].Distance != 0.25 {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := pg.QueryWithEmbedding(context.Background(), []float32{1}, 5, map[string]interface{}{"$not": 1}); err == nil {
+		t.Fatal("expected error for an invalid filter")
+	}
+}
+
+func TestFilterBuilder(t *testing.T) {
+	b := &filterBuilder{}
+	got := b.build(map[string]interface{}{
+		"$or": []interface{}{
+			map[string]interface{}{"lang": map[string]interface{}{"$in": []string{"en", "fr"}}},
+			map[string]interface{}{"draft": map[string]interface{}{"$ne": true}},
+		},
+	})
+	want := `(((metadata @> $1::jsonb) OR (metadata @> $2::jsonb)) OR NOT (metadata @> $3::jsonb))`
+	if got != want {
+		t.Fatalf("build() = %s, want %s", got, want)
+	}
+	wantArgs := []interface{}{`{"lang":"en"}`, `{"lang":"fr"}`, `{"draft":true}`}
+	if !reflect.DeepEqual(b.args, wantArgs) {
+		t.Fatalf("args = %v, want %v", b.args, wantArgs)
+	}
+
+	b = &filterBuilder{}
+	if got := b.build(map[string]interface{}{"lang": map[string]interface{}{"$nin": []interface{}{}}}); got != "NOT FALSE" {
+		t.Fatalf("empty $nin = %s", got)
+	}
+}
+
+func TestVectorEncoding(t *testing.T) {
+	v := []float32{0.25, -1, 3}
+	got, err := decodeVector(encodeVector(v))
+	if err != nil || !reflect.DeepEqual(got, v) {
+		t.Fatalf("round trip = %v, %v", got, err)
+	}
+	if _, err := decodeVector("0.1,0.2"); err == nil {
+		t.Fatal("expected error for a malformed vector")
+	}
+}
+
+// TestPgVector_Live runs against a real Postgres with the vector extension
+func TestPgVector_Live(t *testing.T) {
+	dsn := os.Getenv("TEST_PGVECTOR_DSN")
+	if dsn == "" {
+		t.Skip("set TEST_PGVECTOR_DSN to run pgvector tests")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	pg, err := New(Config{DB: db, CollectionName: "agno_pgvector_test", Dimensions: 2, Index: IndexHNSW, DistanceFunction: vectordb.Cosine})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := pg.CreateCollection(ctx, "", nil); err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	defer pg.DeleteCollection(ctx, "")
+
+	err = pg.Add(ctx, []vectordb.Document{
+		{ID: "a", Content: "alpha", Embedding: []float32{1, 0}, Metadata: map[string]interface{}{"lang": "en", "year": 2024}},
+		{ID: "b", Content: "beta", Embedding: []float32{0.9, 0.1}, Metadata: map[string]interface{}{"lang": "es", "year": 2020}},
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	results, err := pg.QueryWithEmbedding(ctx, []float32{1, 0}, 10, map[string]interface{}{"year": map[string]interface{}{"$lt": 2022}})
+	if err != nil || len(results) != 1 || results[0].ID != "b" {
+		t.Fatalf("filtered query = %+v, %v", results, err)
+	}
+	docs, err := pg.Get(ctx, []string{"a"})
+	if err != nil || len(docs) != 1 || !reflect.DeepEqual(docs[0].Embedding, []float32{1, 0}) {
+		t.Fatalf("Get() = %+v, %v", docs, err)
+	}
+	if n, err := pg.Count(ctx); err != nil || n != 2 {
+		t.Fatalf("Count() = %d, %v", n, err)
+	}
+}
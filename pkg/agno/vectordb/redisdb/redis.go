@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"sync"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
@@ -29,6 +30,14 @@ type Config struct {
 	DistanceFunction vectordb.DistanceFunction
 	// KeyPrefix optional custom prefix
 	KeyPrefix string
+	// Index optionally builds a RediSearch vector index (FLAT or HNSW). When
+	// the server lacks RediSearch, queries fall back to a scan.
+	Index IndexType
+	// Dimensions of the embeddings; required when Index is set
+	Dimensions int
+	// FilterFields are metadata fields indexed for filtering with Index.
+	// Filters on other fields are applied after the vector search.
+	FilterFields []FilterField
 }
 
 type RedisDB struct {
@@ -37,6 +46,13 @@ type RedisDB struct {
 	coll     string
 	embedder vectordb.EmbeddingFunction
 	distance vectordb.DistanceFunction
+
+	index        IndexType
+	dimensions   int
+	filterFields []FilterField
+
+	modeMu sync.Mutex
+	mode   storageMode
 }
 
 func New(cfg Config) (*RedisDB, error) {
@@ -46,21 +62,48 @@ func New(cfg Config) (*RedisDB, error) {
 	if cfg.DistanceFunction == "" {
 		cfg.DistanceFunction = vectordb.Cosine
 	}
-	rdb := redis.NewClient(&redis.Options{Addr: cfg.Addr, Password: cfg.Password, DB: cfg.DB})
+	if err := validateIndexConfig(cfg); err != nil {
+		return nil, err
+	}
+	// RESP2 keeps FT.SEARCH replies as plain arrays
+	rdb := redis.NewClient(&redis.Options{Addr: cfg.Addr, Password: cfg.Password, DB: cfg.DB, Protocol: 2})
 	prefix := cfg.KeyPrefix
 	if prefix == "" {
 		prefix = "vectordb"
 	}
-	return &RedisDB{client: rdb, coll: cfg.CollectionName, prefix: prefix, embedder: cfg.EmbeddingFunction, distance: cfg.DistanceFunction}, nil
+	return &RedisDB{
+		client:       rdb,
+		coll:         cfg.CollectionName,
+		prefix:       prefix,
+		embedder:     cfg.EmbeddingFunction,
+		distance:     cfg.DistanceFunction,
+		index:        cfg.Index,
+		dimensions:   cfg.Dimensions,
+		filterFields: cfg.FilterFields,
+	}, nil
 }
 
-func (r *RedisDB) keyDoc(id string) string { return fmt.Sprintf("%s:%s:doc:%s", r.prefix, r.coll, id) }
-func (r *RedisDB) keyIdx() string          { return fmt.Sprintf("%s:%s:index", r.prefix, r.coll) }
+// docPrefix is the key prefix of the collection's documents. Index mode
+// stores hashes under its own prefix so they never collide with the JSON
+// strings of the scan mode.
+func (r *RedisDB) docPrefix(mode storageMode) string {
+	if mode == modeIndex {
+		return fmt.Sprintf("%s:%s:hdoc:", r.prefix, r.coll)
+	}
+	return fmt.Sprintf("%s:%s:doc:", r.prefix, r.coll)
+}
+
+func (r *RedisDB) keyDoc(mode storageMode, id string) string { return r.docPrefix(mode) + id }
+func (r *RedisDB) keyIdx() string                            { return fmt.Sprintf("%s:%s:index", r.prefix, r.coll) }
 
 func (r *RedisDB) CreateCollection(ctx context.Context, name string, _ map[string]interface{}) error {
 	if name != "" {
 		r.coll = name
 	}
+	r.resetMode()
+	if _, err := r.storage(ctx); err != nil {
+		return err
+	}
 	// Mark index key
 	return r.client.HSetNX(ctx, r.keyIdx(), "_created", "1").Err()
 }
@@ -69,22 +112,29 @@ func (r *RedisDB) DeleteCollection(ctx context.Context, name string) error {
 	if name != "" {
 		r.coll = name
 	}
-	// Scan-delete all docs under prefix
-	cursor := uint64(0)
-	pattern := fmt.Sprintf("%s:%s:doc:*", r.prefix, r.coll)
-	for {
-		keys, next, err := r.client.Scan(ctx, cursor, pattern, 200).Result()
-		if err != nil {
-			return err
-		}
-		if len(keys) > 0 {
-			if err := r.client.Del(ctx, keys...).Err(); err != nil {
+	if r.index != IndexNone {
+		// Keep the documents' keys for the scan below; errors mean there is no index
+		r.client.Do(ctx, "FT.DROPINDEX", r.indexName())
+	}
+	r.resetMode()
+	// Scan-delete the docs of both storage modes
+	for _, mode := range []storageMode{modeScan, modeIndex} {
+		cursor := uint64(0)
+		pattern := r.docPrefix(mode) + "*"
+		for {
+			keys, next, err := r.client.Scan(ctx, cursor, pattern, 200).Result()
+			if err != nil {
 				return err
 			}
-		}
-		cursor = next
-		if cursor == 0 {
-			break
+			if len(keys) > 0 {
+				if err := r.client.Del(ctx, keys...).Err(); err != nil {
+					return err
+				}
+			}
+			cursor = next
+			if cursor == 0 {
+				break
+			}
 		}
 	}
 	return r.client.Del(ctx, r.keyIdx()).Err()
@@ -94,9 +144,16 @@ func (r *RedisDB) Add(ctx context.Context, documents []vectordb.Document) error
 	if len(documents) == 0 {
 		return nil
 	}
+	mode, err := r.storage(ctx)
+	if err != nil {
+		return err
+	}
+	if mode == modeIndex {
+		return r.addHashes(ctx, documents)
+	}
 	for _, d := range documents {
 		b, _ := json.Marshal(d)
-		if err := r.client.Set(ctx, r.keyDoc(d.ID), b, 0).Err(); err != nil {
+		if err := r.client.Set(ctx, r.keyDoc(mode, d.ID), b, 0).Err(); err != nil {
 			return err
 		}
 	}
@@ -111,9 +168,13 @@ func (r *RedisDB) Delete(ctx context.Context, ids []string) error {
 	if len(ids) == 0 {
 		return nil
 	}
+	mode, err := r.storage(ctx)
+	if err != nil {
+		return err
+	}
 	keys := make([]string, len(ids))
 	for i, id := range ids {
-		keys[i] = r.keyDoc(id)
+		keys[i] = r.keyDoc(mode, id)
 	}
 	return r.client.Del(ctx, keys...).Err()
 }
@@ -129,13 +190,23 @@ func (r *RedisDB) Query(ctx context.Context, query string, limit int, filter map
 	return r.QueryWithEmbedding(ctx, emb, limit, filter)
 }
 
-func (r *RedisDB) QueryWithEmbedding(ctx context.Context, embedding []float32, limit int, _ map[string]interface{}) ([]vectordb.SearchResult, error) {
+func (r *RedisDB) QueryWithEmbedding(ctx context.Context, embedding []float32, limit int, filter map[string]interface{}) ([]vectordb.SearchResult, error) {
 	if len(embedding) == 0 {
 		return nil, errors.New("embedding required")
 	}
+	if err := vectordb.ValidateFilter(filter); err != nil {
+		return nil, err
+	}
+	mode, err := r.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if mode == modeIndex {
+		return r.searchIndex(ctx, embedding, limit, filter)
+	}
 	// Fetch all docs for naive scoring
 	cursor := uint64(0)
-	pattern := fmt.Sprintf("%s:%s:doc:*", r.prefix, r.coll)
+	pattern := r.docPrefix(mode) + "*"
 	results := make([]vectordb.SearchResult, 0)
 	for {
 		keys, next, err := r.client.Scan(ctx, cursor, pattern, 200).Result()
@@ -154,6 +225,9 @@ func (r *RedisDB) QueryWithEmbedding(ctx context.Context, embedding []float32, l
 			if len(doc.Embedding) == 0 {
 				continue
 			}
+			if ok, _ := vectordb.MatchesFilter(doc.Metadata, filter); !ok {
+				continue
+			}
 			score, dist := scoreVectors(embedding, doc.Embedding, r.distance)
 			results = append(results, vectordb.SearchResult{ID: doc.ID, Content: doc.Content, Metadata: doc.Metadata, Score: float32(score), Distance: float32(dist)})
 		}
@@ -179,9 +253,16 @@ func (r *RedisDB) Get(ctx context.Context, ids []string) ([]vectordb.Document, e
 	if len(ids) == 0 {
 		return nil, nil
 	}
+	mode, err := r.storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if mode == modeIndex {
+		return r.getHashes(ctx, ids)
+	}
 	keys := make([]string, len(ids))
 	for i, id := range ids {
-		keys[i] = r.keyDoc(id)
+		keys[i] = r.keyDoc(mode, id)
 	}
 	vals, err := r.client.MGet(ctx, keys...).Result()
 	if err != nil {
@@ -201,8 +282,12 @@ func (r *RedisDB) Get(ctx context.Context, ids []string) ([]vectordb.Document, e
 }
 
 func (r *RedisDB) Count(ctx context.Context) (int, error) {
+	mode, err := r.storage(ctx)
+	if err != nil {
+		return 0, err
+	}
 	cursor := uint64(0)
-	pattern := fmt.Sprintf("%s:%s:doc:*", r.prefix, r.coll)
+	pattern := r.docPrefix(mode) + "*"
 	total := 0
 	for {
 		keys, next, err := r.client.Scan(ctx, cursor, pattern, 500).Result()
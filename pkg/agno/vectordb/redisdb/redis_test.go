@@ -6,6 +6,8 @@ import (
 	"context"
 	"os"
 	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
 )
 
 func TestRedisDB_Smoke(t *testing.T) {
@@ -25,7 +27,10 @@ func TestRedisDB_Smoke(t *testing.T) {
 		t.Fatalf("create: %v", err)
 	}
 	defer db.DeleteCollection(ctx, "")
-	err = db.Add(ctx, []Document{{ID: "1", Content: "hello", Embedding: []float32{0.1, 0.2, 0.3}}})
+	err = db.Add(ctx, []vectordb.Document{
+		{ID: "1", Content: "hello", Embedding: []float32{0.1, 0.2, 0.3}, Metadata: map[string]interface{}{"lang": "en"}},
+		{ID: "2", Content: "hola", Embedding: []float32{0.1, 0.2, 0.3}, Metadata: map[string]interface{}{"lang": "es"}},
+	})
 	if err != nil {
 		t.Fatalf("add: %v", err)
 	}
@@ -33,4 +38,8 @@ func TestRedisDB_Smoke(t *testing.T) {
 	if err != nil || n < 1 {
 		t.Fatalf("count: %v n=%d", err, n)
 	}
+	res, err := db.QueryWithEmbedding(ctx, []float32{0.1, 0.2, 0.3}, 10, map[string]interface{}{"lang": "es"})
+	if err != nil || len(res) != 1 || res[0].ID != "2" {
+		t.Fatalf("filtered query: %v %+v", err, res)
+	}
 }
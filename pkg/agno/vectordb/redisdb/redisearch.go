@@ -0,0 +1,622 @@
+//go:build redis
+
+package redisdb
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rexleimo/agno-go/pkg/agno/vectordb"
+)
+
+// IndexType selects the RediSearch vector index algorithm
+type IndexType string
+
+const (
+	// IndexNone stores documents as JSON and scans them on query
+	IndexNone IndexType = ""
+	// IndexFLAT is an exact brute-force index
+	IndexFLAT IndexType = "FLAT"
+	// IndexHNSW is an approximate graph index
+	IndexHNSW IndexType = "HNSW"
+)
+
+// FieldType is the RediSearch type of an indexed metadata field
+type FieldType string
+
+const (
+	// FieldTag indexes exact values (strings, booleans, numbers as text)
+	FieldTag FieldType = "TAG"
+	// FieldNumeric indexes numbers and supports range filters
+	FieldNumeric FieldType = "NUMERIC"
+)
+
+// FilterField is a metadata field indexed for filtering
+type FilterField struct {
+	Name string
+	Type FieldType
+}
+
+type storageMode int
+
+const (
+	modeUnknown storageMode = iota
+	// modeScan stores JSON strings and scores in Go
+	modeScan
+	// modeIndex stores hashes searched by a RediSearch index
+	modeIndex
+)
+
+const (
+	hashID        = "id"
+	hashContent   = "content"
+	hashMetadata  = "metadata"
+	hashEmbedding = "embedding"
+	distanceAlias = "__dist"
+	// overFetchFactor widens the KNN when part of the filter is applied in Go
+	overFetchFactor = 10
+)
+
+func validateIndexConfig(cfg Config) error {
+	switch cfg.Index {
+	case IndexNone:
+		return nil
+	case IndexFLAT, IndexHNSW:
+	default:
+		return fmt.Errorf("unsupported index type %q", cfg.Index)
+	}
+	if cfg.Dimensions <= 0 {
+		return fmt.Errorf("dimensions are required when index is set")
+	}
+	for _, f := range cfg.FilterFields {
+		if f.Name == "" {
+			return fmt.Errorf("filter field name is required")
+		}
+		if f.Type != FieldTag && f.Type != FieldNumeric {
+			return fmt.Errorf("unsupported type %q for filter field %q", f.Type, f.Name)
+		}
+	}
+	return nil
+}
+
+func (r *RedisDB) indexName() string { return fmt.Sprintf("%s:%s:idx", r.prefix, r.coll) }
+
+func (r *RedisDB) resetMode() {
+	r.modeMu.Lock()
+	r.mode = modeUnknown
+	r.modeMu.Unlock()
+}
+
+// storage decides once whether documents live in a RediSearch index,
+// creating the index when it does not exist yet
+func (r *RedisDB) storage(ctx context.Context) (storageMode, error) {
+	r.modeMu.Lock()
+	defer r.modeMu.Unlock()
+
+	if r.mode != modeUnknown {
+		return r.mode, nil
+	}
+	if r.index == IndexNone {
+		r.mode = modeScan
+		return r.mode, nil
+	}
+
+	err := r.client.Do(ctx, "FT.INFO", r.indexName()).Err()
+	switch {
+	case err == nil:
+		r.mode = modeIndex
+	case isUnknownCommand(err):
+		r.mode = modeScan
+	case isUnknownIndex(err):
+		if err := r.client.Do(ctx, r.createIndexArgs()...).Err(); err != nil {
+			return modeUnknown, fmt.Errorf("failed to create index: %w", err)
+		}
+		if err := r.migrateScanDocuments(ctx); err != nil {
+			return modeUnknown, fmt.Errorf("failed to migrate documents into the index: %w", err)
+		}
+		r.mode = modeIndex
+	default:
+		return modeUnknown, fmt.Errorf("failed to inspect index: %w", err)
+	}
+	return r.mode, nil
+}
+
+func isUnknownCommand(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "unknown command")
+}
+
+func isUnknownIndex(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unknown index") || strings.Contains(msg, "no such index")
+}
+
+func (r *RedisDB) createIndexArgs() []interface{} {
+	metric := "COSINE"
+	switch r.distance {
+	case vectordb.L2:
+		metric = "L2"
+	case vectordb.InnerProduct:
+		metric = "IP"
+	}
+	args := []interface{}{
+		"FT.CREATE", r.indexName(), "ON", "HASH",
+		"PREFIX", 1, r.docPrefix(modeIndex),
+		"SCHEMA", hashEmbedding, "VECTOR", string(r.index), 6,
+		"TYPE", "FLOAT32", "DIM", r.dimensions, "DISTANCE_METRIC", metric,
+	}
+	for _, f := range r.filterFields {
+		args = append(args, metadataField(f.Name), string(f.Type))
+		if f.Type == FieldTag {
+			args = append(args, "CASESENSITIVE")
+		}
+	}
+	return args
+}
+
+// migrateScanDocuments moves documents a scan-mode collection stored as JSON
+// strings into index hashes, so enabling Index on an existing collection keeps
+// its documents searchable
+func (r *RedisDB) migrateScanDocuments(ctx context.Context) error {
+	cursor := uint64(0)
+	pattern := r.docPrefix(modeScan) + "*"
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, pattern, 200).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			vals, err := r.client.MGet(ctx, keys...).Result()
+			if err != nil {
+				return err
+			}
+			documents := make([]vectordb.Document, 0, len(vals))
+			for _, v := range vals {
+				raw, ok := v.(string)
+				if !ok {
+					continue
+				}
+				var doc vectordb.Document
+				if err := json.Unmarshal([]byte(raw), &doc); err == nil {
+					documents = append(documents, doc)
+				}
+			}
+			if err := r.addHashes(ctx, documents); err != nil {
+				return err
+			}
+			if err := r.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// metadataField is the hash field holding an indexed metadata value
+func metadataField(name string) string { return "m_" + name }
+
+func (r *RedisDB) addHashes(ctx context.Context, documents []vectordb.Document) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, d := range documents {
+			key := r.keyDoc(modeIndex, d.ID)
+			// Replace the whole hash so stale metadata fields do not linger
+			pipe.Del(ctx, key)
+			pipe.HSet(ctx, key, r.documentHash(d))
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *RedisDB) documentHash(d vectordb.Document) map[string]interface{} {
+	metadata, _ := json.Marshal(d.Metadata)
+	fields := map[string]interface{}{
+		hashID:       d.ID,
+		hashContent:  d.Content,
+		hashMetadata: string(metadata),
+	}
+	if len(d.Embedding) > 0 {
+		fields[hashEmbedding] = encodeVector(d.Embedding)
+	}
+	for _, f := range r.filterFields {
+		value, ok := d.Metadata[f.Name]
+		if !ok {
+			continue
+		}
+		if f.Type == FieldNumeric {
+			n, ok := vectordb.FilterNumber(value)
+			if !ok {
+				continue
+			}
+			fields[metadataField(f.Name)] = formatNumber(n)
+			continue
+		}
+		if s, ok := tagValue(value); ok {
+			fields[metadataField(f.Name)] = s
+		}
+	}
+	return fields
+}
+
+func (r *RedisDB) getHashes(ctx context.Context, ids []string) ([]vectordb.Document, error) {
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.HGetAll(ctx, r.keyDoc(modeIndex, id))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]vectordb.Document, 0, len(ids))
+	for _, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			continue
+		}
+		doc := vectordb.Document{
+			ID:        fields[hashID],
+			Content:   fields[hashContent],
+			Embedding: decodeVector([]byte(fields[hashEmbedding])),
+		}
+		if raw := fields[hashMetadata]; raw != "" {
+			_ = json.Unmarshal([]byte(raw), &doc.Metadata)
+		}
+		out = append(out, doc)
+	}
+	return out, nil
+}
+
+func (r *RedisDB) searchIndex(ctx context.Context, embedding []float32, limit int, filter map[string]interface{}) ([]vectordb.SearchResult, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	fields := make(map[string]FieldType, len(r.filterFields))
+	for _, f := range r.filterFields {
+		fields[f.Name] = f.Type
+	}
+	expr, exact := translateFilter(filter, fields)
+	k := limit
+	if !exact {
+		expr = "*"
+		k = limit * overFetchFactor
+	}
+
+	query := fmt.Sprintf("(%s)=>[KNN %d @%s $vec AS %s]", expr, k, hashEmbedding, distanceAlias)
+	if expr == "*" {
+		query = fmt.Sprintf("*=>[KNN %d @%s $vec AS %s]", k, hashEmbedding, distanceAlias)
+	}
+	reply, err := r.client.Do(ctx, "FT.SEARCH", r.indexName(), query,
+		"PARAMS", 2, "vec", encodeVector(embedding),
+		"SORTBY", distanceAlias, "ASC",
+		"RETURN", 4, hashID, hashContent, hashMetadata, distanceAlias,
+		"LIMIT", 0, k,
+		"DIALECT", 2,
+	).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search index: %w", err)
+	}
+	hits, err := parseSearchReply(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]vectordb.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		var metadata map[string]interface{}
+		if raw := hit[hashMetadata]; raw != "" {
+			_ = json.Unmarshal([]byte(raw), &metadata)
+		}
+		// Also re-checks translated filters, e.g. tag values containing separators
+		if ok, _ := vectordb.MatchesFilter(metadata, filter); !ok {
+			continue
+		}
+		dist, _ := strconv.ParseFloat(hit[distanceAlias], 64)
+		score, distance := indexScore(dist, r.distance)
+		results = append(results, vectordb.SearchResult{
+			ID:       hit[hashID],
+			Content:  hit[hashContent],
+			Metadata: metadata,
+			Score:    float32(score),
+			Distance: float32(distance),
+		})
+		if len(results) == limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// indexScore maps a RediSearch distance onto the scan path's score and
+// distance so both modes rank and report alike
+func indexScore(dist float64, fn vectordb.DistanceFunction) (score, distance float64) {
+	switch fn {
+	case vectordb.L2:
+		return -dist, dist
+	case vectordb.InnerProduct:
+		// RediSearch reports 1 - dot
+		return 1 - dist, dist - 1
+	default:
+		return 1 - dist, dist
+	}
+}
+
+// parseSearchReply reads a RESP2 FT.SEARCH reply:
+// [total, key, [field, value, ...], key, [...], ...]
+func parseSearchReply(reply interface{}) ([]map[string]string, error) {
+	items, ok := reply.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("unexpected search reply %T", reply)
+	}
+	hits := make([]map[string]string, 0, (len(items)-1)/2)
+	for i := 1; i+1 < len(items); i += 2 {
+		pairs, ok := items[i+1].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected search reply fields %T", items[i+1])
+		}
+		hit := make(map[string]string, len(pairs)/2)
+		for j := 0; j+1 < len(pairs); j += 2 {
+			hit[fmt.Sprint(pairs[j])] = fmt.Sprint(pairs[j+1])
+		}
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+// translateFilter converts a VectorDB filter into a RediSearch query over the
+// indexed fields. exact is false when part of the filter cannot be expressed,
+// in which case the caller must filter the results itself.
+func translateFilter(filter map[string]interface{}, fields map[string]FieldType) (expr string, exact bool) {
+	if len(filter) == 0 {
+		return "*", true
+	}
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	terms := make([]string, 0, len(keys))
+	for _, key := range keys {
+		var (
+			term string
+			ok   bool
+		)
+		switch key {
+		case vectordb.FilterAnd, vectordb.FilterOr:
+			term, ok = translateClauses(key, filter[key], fields)
+		default:
+			term, ok = translateField(key, filter[key], fields)
+		}
+		if !ok {
+			return "", false
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return terms[0], true
+	}
+	return "(" + strings.Join(terms, " ") + ")", true
+}
+
+func translateClauses(op string, value interface{}, fields map[string]FieldType) (string, bool) {
+	var clauses []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		clauses = v
+	case []map[string]interface{}:
+		for _, c := range v {
+			clauses = append(clauses, c)
+		}
+	default:
+		return "", false
+	}
+	if len(clauses) == 0 {
+		return "", false
+	}
+	terms := make([]string, 0, len(clauses))
+	for _, c := range clauses {
+		clause, ok := c.(map[string]interface{})
+		if !ok || len(clause) == 0 {
+			return "", false
+		}
+		term, ok := translateFilter(clause, fields)
+		if !ok {
+			return "", false
+		}
+		terms = append(terms, term)
+	}
+	sep := " "
+	if op == vectordb.FilterOr {
+		sep = " | "
+	}
+	return "(" + strings.Join(terms, sep) + ")", true
+}
+
+func translateField(name string, condition interface{}, fields map[string]FieldType) (string, bool) {
+	fieldType, indexed := fields[name]
+	if !indexed {
+		return "", false
+	}
+	field := "@" + metadataField(name)
+
+	ops, isOps := condition.(map[string]interface{})
+	if !isOps {
+		return matchTerm(field, fieldType, condition)
+	}
+	if len(ops) == 0 {
+		return "", false
+	}
+	names := make([]string, 0, len(ops))
+	for op := range ops {
+		names = append(names, op)
+	}
+	sort.Strings(names)
+
+	terms := make([]string, 0, len(ops))
+	for _, op := range names {
+		operand := ops[op]
+		var (
+			term string
+			ok   bool
+		)
+		switch op {
+		case vectordb.FilterEq:
+			term, ok = matchTerm(field, fieldType, operand)
+		case vectordb.FilterNe:
+			term, ok = matchTerm(field, fieldType, operand)
+			term = "-" + term
+		case vectordb.FilterGt, vectordb.FilterGte, vectordb.FilterLt, vectordb.FilterLte:
+			term, ok = rangeTerm(field, fieldType, op, operand)
+		case vectordb.FilterIn, vectordb.FilterNin:
+			term, ok = inTerm(field, fieldType, operand)
+			if op == vectordb.FilterNin {
+				term = "-" + term
+			}
+		}
+		if !ok {
+			return "", false
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return terms[0], true
+	}
+	return "(" + strings.Join(terms, " ") + ")", true
+}
+
+func matchTerm(field string, fieldType FieldType, value interface{}) (string, bool) {
+	if fieldType == FieldNumeric {
+		n, ok := vectordb.FilterNumber(value)
+		if !ok {
+			return "", false
+		}
+		v := formatNumber(n)
+		return fmt.Sprintf("%s:[%s %s]", field, v, v), true
+	}
+	s, ok := tagValue(value)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s:{%s}", field, escapeTag(s)), true
+}
+
+func rangeTerm(field string, fieldType FieldType, op string, value interface{}) (string, bool) {
+	if fieldType != FieldNumeric {
+		return "", false
+	}
+	n, ok := vectordb.FilterNumber(value)
+	if !ok {
+		return "", false
+	}
+	v := formatNumber(n)
+	switch op {
+	case vectordb.FilterGt:
+		return fmt.Sprintf("%s:[(%s +inf]", field, v), true
+	case vectordb.FilterGte:
+		return fmt.Sprintf("%s:[%s +inf]", field, v), true
+	case vectordb.FilterLt:
+		return fmt.Sprintf("%s:[-inf (%s]", field, v), true
+	default:
+		return fmt.Sprintf("%s:[-inf %s]", field, v), true
+	}
+}
+
+func inTerm(field string, fieldType FieldType, value interface{}) (string, bool) {
+	var values []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		values = v
+	case []string:
+		for _, s := range v {
+			values = append(values, s)
+		}
+	default:
+		return "", false
+	}
+	if len(values) == 0 {
+		return "", false
+	}
+
+	if fieldType == FieldTag {
+		tags := make([]string, 0, len(values))
+		for _, v := range values {
+			s, ok := tagValue(v)
+			if !ok {
+				return "", false
+			}
+			tags = append(tags, escapeTag(s))
+		}
+		return fmt.Sprintf("%s:{%s}", field, strings.Join(tags, " | ")), true
+	}
+	terms := make([]string, 0, len(values))
+	for _, v := range values {
+		term, ok := matchTerm(field, fieldType, v)
+		if !ok {
+			return "", false
+		}
+		terms = append(terms, term)
+	}
+	return "(" + strings.Join(terms, " | ") + ")", true
+}
+
+// tagValue renders a metadata value the way it is stored in a TAG field
+func tagValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	if n, ok := vectordb.FilterNumber(value); ok {
+		return formatNumber(n), true
+	}
+	return "", false
+}
+
+func formatNumber(n float64) string { return strconv.FormatFloat(n, 'f', -1, 64) }
+
+// escapeTag escapes the characters RediSearch treats as tag query syntax
+func escapeTag(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r == '_' || r > 0x7f || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteByte('\\')
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// encodeVector packs an embedding as little-endian float32, the layout
+// RediSearch expects for FLOAT32 vectors
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	if len(buf) < 4 {
+		return nil
+	}
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
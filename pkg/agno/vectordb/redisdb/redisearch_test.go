@@ -0,0 +1,100 @@
+//go:build redis
+
+package redisdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTranslateFilter(t *testing.T) {
+	fields := map[string]FieldType{"lang": FieldTag, "year": FieldNumeric}
+
+	tests := []struct {
+		name   string
+		filter map[string]interface{}
+		want   string
+		exact  bool
+	}{
+		{"empty", nil, "*", true},
+		{"tag equality", map[string]interface{}{"lang": "en-US"}, `@m_lang:{en\-US}`, true},
+		{"numeric equality", map[string]interface{}{"year": 2023}, "@m_year:[2023 2023]", true},
+		{"range", map[string]interface{}{"year": map[string]interface{}{"$gt": 2020, "$lte": 2024}}, "(@m_year:[(2020 +inf] @m_year:[-inf 2024])", true},
+		{"$in", map[string]interface{}{"lang": map[string]interface{}{"$in": []interface{}{"en", "fr"}}}, "@m_lang:{en | fr}", true},
+		{"$nin", map[string]interface{}{"year": map[string]interface{}{"$nin": []interface{}{1, 2}}}, "-(@m_year:[1 1] | @m_year:[2 2])", true},
+		{"$ne", map[string]interface{}{"lang": map[string]interface{}{"$ne": "en"}}, "-@m_lang:{en}", true},
+		{"$or", map[string]interface{}{"$or": []interface{}{
+			map[string]interface{}{"lang": "en"},
+			map[string]interface{}{"year": map[string]interface{}{"$lt": 2000}},
+		}}, "(@m_lang:{en} | @m_year:[-inf (2000])", true},
+		{"several keys", map[string]interface{}{"lang": "en", "year": 2023}, "(@m_lang:{en} @m_year:[2023 2023])", true},
+		{"unindexed field", map[string]interface{}{"author": "ada"}, "", false},
+		{"range on tag", map[string]interface{}{"lang": map[string]interface{}{"$gt": "a"}}, "", false},
+		{"unindexed inside $and", map[string]interface{}{"$and": []interface{}{
+			map[string]interface{}{"lang": "en"},
+			map[string]interface{}{"author": "ada"},
+		}}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, exact := translateFilter(tt.filter, fields)
+			if exact != tt.exact || got != tt.want {
+				t.Errorf("translateFilter() = %q, %v; want %q, %v", got, exact, tt.want, tt.exact)
+			}
+		})
+	}
+}
+
+func TestParseSearchReply(t *testing.T) {
+	reply := []interface{}{
+		int64(2),
+		"vectordb:docs:hdoc:a", []interface{}{"id", "a", "content", "alpha", "__dist", "0.25"},
+		"vectordb:docs:hdoc:b", []interface{}{"id", "b", "content", "beta", "__dist", "0.5"},
+	}
+	hits, err := parseSearchReply(reply)
+	if err != nil {
+		t.Fatalf("parseSearchReply() error = %v", err)
+	}
+	if len(hits) != 2 || hits[0]["id"] != "a" || hits[1]["__dist"] != "0.5" {
+		t.Fatalf("unexpected hits %+v", hits)
+	}
+
+	if _, err := parseSearchReply("OK"); err == nil {
+		t.Fatal("expected error for a non-array reply")
+	}
+}
+
+func TestEncodeVector(t *testing.T) {
+	v := []float32{0.5, -1, 3.25}
+	if got := decodeVector(encodeVector(v)); !reflect.DeepEqual(got, v) {
+		t.Fatalf("round trip = %v, want %v", got, v)
+	}
+}
+
+func TestNew_IndexConfig(t *testing.T) {
+	if _, err := New(Config{CollectionName: "docs", Index: IndexHNSW}); err == nil {
+		t.Fatal("expected error without dimensions")
+	}
+	if _, err := New(Config{CollectionName: "docs", Index: "IVF", Dimensions: 3}); err == nil {
+		t.Fatal("expected error for an unknown index type")
+	}
+	if _, err := New(Config{CollectionName: "docs", Index: IndexFLAT, Dimensions: 3,
+		FilterFields: []FilterField{{Name: "lang", Type: "TEXT"}}}); err == nil {
+		t.Fatal("expected error for an unknown field type")
+	}
+}
+
+func TestDocPrefix_SeparatesModes(t *testing.T) {
+	db, err := New(Config{CollectionName: "docs", Index: IndexHNSW, Dimensions: 3})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if scan, index := db.docPrefix(modeScan), db.docPrefix(modeIndex); scan == index {
+		t.Fatalf("scan and index documents share the prefix %q", scan)
+	}
+	args := db.createIndexArgs()
+	if args[6] != db.docPrefix(modeIndex) {
+		t.Fatalf("index prefix = %v, want %q", args[6], db.docPrefix(modeIndex))
+	}
+}
@@ -0,0 +1,152 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrApprovalRejected is returned by an approval node whose request was rejected
+var ErrApprovalRejected = errors.New("approval rejected")
+
+// ApprovalAction is the decision taken on an approval request
+type ApprovalAction string
+
+const (
+	// ApprovalActionApprove continues with the output unchanged
+	ApprovalActionApprove ApprovalAction = "approve"
+	// ApprovalActionReject fails the run at the approval node
+	ApprovalActionReject ApprovalAction = "reject"
+	// ApprovalActionEdit replaces the output with the decision input and continues
+	ApprovalActionEdit ApprovalAction = "edit"
+)
+
+// ApprovalDecision is a reviewer's answer to an approval request
+type ApprovalDecision struct {
+	Action    ApprovalAction `json:"action"`
+	Input     string         `json:"input,omitempty"`
+	Comment   string         `json:"comment,omitempty"`
+	DecidedBy string         `json:"decided_by,omitempty"`
+	DecidedAt time.Time      `json:"decided_at"`
+}
+
+// Validate checks the action and the input required by edits
+func (d ApprovalDecision) Validate() error {
+	switch d.Action {
+	case ApprovalActionApprove, ApprovalActionReject:
+		return nil
+	case ApprovalActionEdit:
+		if d.Input == "" {
+			return fmt.Errorf("edit decision requires input")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown approval action %q", d.Action)
+	}
+}
+
+// ApprovalRequest describes the output awaiting review at an approval node
+type ApprovalRequest struct {
+	StepID      string            `json:"step_id"`
+	Message     string            `json:"message,omitempty"`
+	Output      string            `json:"output"`
+	RequestedAt time.Time         `json:"requested_at"`
+	Decision    *ApprovalDecision `json:"decision,omitempty"`
+}
+
+// PendingApprovalError is returned by Workflow.Run and Workflow.ResolveApproval
+// when the run stopped at an approval node. The run is stored with status
+// pending until ResolveApproval is called with its RunID.
+type PendingApprovalError struct {
+	RunID   string
+	Request *ApprovalRequest
+}
+
+func (e *PendingApprovalError) Error() string {
+	return fmt.Sprintf("run %s is waiting for approval at step %s", e.RunID, e.Request.StepID)
+}
+
+// errApprovalRequired signals runSteps that an approval node has no decision yet
+type errApprovalRequired struct {
+	request *ApprovalRequest
+}
+
+func (e *errApprovalRequired) Error() string {
+	return fmt.Sprintf("step %s requires approval", e.request.StepID)
+}
+
+// Approval represents a human-in-the-loop node that suspends the workflow until
+// the current output is approved, edited or rejected. Approvals are meant to be
+// top-level nodes; nested inside another node, the enclosing node is executed
+// again when the run resumes.
+type Approval struct {
+	ID      string
+	Name    string
+	Message string
+}
+
+// ApprovalConfig contains approval configuration
+type ApprovalConfig struct {
+	ID   string
+	Name string
+	// Message is shown to the reviewer
+	Message string
+}
+
+// NewApproval creates a new approval node
+func NewApproval(config ApprovalConfig) (*Approval, error) {
+	if config.ID == "" && config.Name == "" {
+		return nil, fmt.Errorf("approval ID or name is required")
+	}
+
+	if config.ID == "" {
+		config.ID = fmt.Sprintf("approval-%s", config.Name)
+	}
+
+	if config.Name == "" {
+		config.Name = config.ID
+	}
+
+	return &Approval{
+		ID:      config.ID,
+		Name:    config.Name,
+		Message: config.Message,
+	}, nil
+}
+
+// Execute applies the pending decision for this node, or suspends the run if there is none
+func (a *Approval) Execute(ctx context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+	decision := execCtx.takeApprovalDecision(a.ID)
+	if decision == nil {
+		return nil, &errApprovalRequired{request: &ApprovalRequest{
+			StepID:      a.ID,
+			Message:     a.Message,
+			Output:      execCtx.Output,
+			RequestedAt: time.Now(),
+		}}
+	}
+
+	execCtx.Set(fmt.Sprintf("approval_%s_action", a.ID), string(decision.Action))
+
+	switch decision.Action {
+	case ApprovalActionReject:
+		if decision.Comment != "" {
+			return nil, fmt.Errorf("%w: %s", ErrApprovalRejected, decision.Comment)
+		}
+		return nil, ErrApprovalRejected
+	case ApprovalActionEdit:
+		execCtx.Output = decision.Input
+	}
+	return execCtx, nil
+}
+
+// GetID returns the approval ID
+func (a *Approval) GetID() string {
+	return a.ID
+}
+
+// GetType returns the node type
+func (a *Approval) GetType() NodeType {
+	return NodeTypeApproval
+}
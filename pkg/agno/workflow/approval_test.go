@@ -0,0 +1,227 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func newApprovalWorkflow(t *testing.T, store WorkflowStorage, published *[]string) *Workflow {
+	t.Helper()
+
+	draft := &stubNode{
+		id: "draft",
+		execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+			execCtx.Output = "draft v1"
+			execCtx.SetSessionState("author", "bot")
+			return execCtx, nil
+		},
+	}
+	review, err := NewApproval(ApprovalConfig{ID: "review", Message: "Publish this draft?"})
+	if err != nil {
+		t.Fatalf("NewApproval error: %v", err)
+	}
+	publish := &stubNode{
+		id: "publish",
+		execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+			*published = append(*published, execCtx.Output)
+			execCtx.Output = "published: " + execCtx.Output
+			return execCtx, nil
+		},
+	}
+
+	wf, err := New(Config{ID: "publishing", Steps: []Node{draft, review, publish}, HistoryStore: store})
+	if err != nil {
+		t.Fatalf("failed to create workflow: %v", err)
+	}
+	return wf
+}
+
+func startApprovalRun(t *testing.T, wf *Workflow, sessionID string) *PendingApprovalError {
+	t.Helper()
+
+	_, err := wf.Run(context.Background(), "write a post", sessionID)
+	var pending *PendingApprovalError
+	if !errors.As(err, &pending) {
+		t.Fatalf("expected PendingApprovalError, got %v", err)
+	}
+	return pending
+}
+
+func TestApproval_SuspendsRun(t *testing.T) {
+	store := NewMemoryStorage(10)
+	var published []string
+	wf := newApprovalWorkflow(t, store, &published)
+
+	pending := startApprovalRun(t, wf, "sess-1")
+	if pending.Request.StepID != "review" || pending.Request.Output != "draft v1" || pending.Request.Message != "Publish this draft?" {
+		t.Fatalf("unexpected request %+v", pending.Request)
+	}
+	if len(published) != 0 {
+		t.Fatal("publish must not run before approval")
+	}
+
+	session, err := store.GetSession(context.Background(), "sess-1")
+	if err != nil {
+		t.Fatalf("GetSession error: %v", err)
+	}
+	stored := session.GetRun(pending.RunID)
+	if stored.Status != RunStatusPending || stored.PendingApproval == nil || stored.Checkpoint.StepID != "draft" {
+		t.Fatalf("unexpected stored run %+v", stored)
+	}
+
+	request, err := wf.PendingApproval(context.Background(), pending.RunID)
+	if err != nil || request == nil || request.StepID != "review" {
+		t.Fatalf("PendingApproval() = %+v, %v", request, err)
+	}
+	if _, err := wf.Resume(context.Background(), pending.RunID); err == nil {
+		t.Fatal("expected Resume to refuse a run waiting for approval")
+	}
+}
+
+func TestApproval_Approve(t *testing.T) {
+	store := NewMemoryStorage(10)
+	var published []string
+	wf := newApprovalWorkflow(t, store, &published)
+	pending := startApprovalRun(t, wf, "sess-1")
+
+	// A fresh workflow instance, as in another process, resolves the approval
+	wf = newApprovalWorkflow(t, store, &published)
+	result, err := wf.ResolveApproval(context.Background(), pending.RunID, ApprovalDecision{
+		Action:    ApprovalActionApprove,
+		DecidedBy: "manager",
+	})
+	if err != nil {
+		t.Fatalf("ResolveApproval error: %v", err)
+	}
+	if result.Output != "published: draft v1" {
+		t.Fatalf("unexpected output %q", result.Output)
+	}
+	if author, _ := result.GetSessionState("author"); author != "bot" {
+		t.Fatalf("expected session state restored, got %v", author)
+	}
+
+	session, _ := store.GetSession(context.Background(), "sess-1")
+	stored := session.GetRun(pending.RunID)
+	if stored.Status != RunStatusCompleted || stored.PendingApproval != nil || len(stored.Approvals) != 1 {
+		t.Fatalf("unexpected stored run %+v", stored)
+	}
+	if decision := stored.Approvals[0].Decision; decision == nil || decision.DecidedBy != "manager" || decision.DecidedAt.IsZero() {
+		t.Fatalf("decision not recorded: %+v", stored.Approvals[0])
+	}
+
+	if _, err := wf.ResolveApproval(context.Background(), pending.RunID, ApprovalDecision{Action: ApprovalActionApprove}); err == nil {
+		t.Fatal("expected error when resolving a completed run")
+	}
+}
+
+// loadBarrier returns a snapshot of the run's session, as a database would,
+// and holds the lookup until every caller has loaded the run
+type loadBarrier struct {
+	*MemoryStorage
+	loaded *sync.WaitGroup
+}
+
+func (b *loadBarrier) GetSessionByRunID(ctx context.Context, runID string) (*WorkflowSession, error) {
+	session, err := b.MemoryStorage.GetSessionByRunID(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := NewWorkflowSession(session.SessionID, session.WorkflowID, session.UserID)
+	for _, run := range session.GetRuns() {
+		snapshot.AddRun(run.clone())
+	}
+	b.loaded.Done()
+	b.loaded.Wait()
+	return snapshot, nil
+}
+
+func TestApproval_ConcurrentResolveResumesOnce(t *testing.T) {
+	store := NewMemoryStorage(10)
+	var published []string
+	pending := startApprovalRun(t, newApprovalWorkflow(t, store, &published), "sess-1")
+
+	var loaded sync.WaitGroup
+	loaded.Add(2)
+	wf := newApprovalWorkflow(t, &loadBarrier{MemoryStorage: store, loaded: &loaded}, &published)
+
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = wf.ResolveApproval(context.Background(), pending.RunID, ApprovalDecision{Action: ApprovalActionApprove})
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrRunConflict):
+			t.Errorf("expected ErrRunConflict for the losing caller, got %v", err)
+		}
+	}
+	if succeeded != 1 || len(published) != 1 {
+		t.Fatalf("expected one resume and one publish, got %d resumes and %v", succeeded, published)
+	}
+}
+
+func TestApproval_Edit(t *testing.T) {
+	var published []string
+	wf := newApprovalWorkflow(t, NewMemoryStorage(10), &published)
+	pending := startApprovalRun(t, wf, "sess-1")
+
+	result, err := wf.ResolveApproval(context.Background(), pending.RunID, ApprovalDecision{
+		Action: ApprovalActionEdit,
+		Input:  "draft v2",
+	})
+	if err != nil {
+		t.Fatalf("ResolveApproval error: %v", err)
+	}
+	if result.Output != "published: draft v2" || len(published) != 1 || published[0] != "draft v2" {
+		t.Fatalf("expected edited output to be published, got %q (%v)", result.Output, published)
+	}
+}
+
+func TestApproval_Reject(t *testing.T) {
+	store := NewMemoryStorage(10)
+	var published []string
+	wf := newApprovalWorkflow(t, store, &published)
+	pending := startApprovalRun(t, wf, "sess-1")
+
+	_, err := wf.ResolveApproval(context.Background(), pending.RunID, ApprovalDecision{
+		Action:  ApprovalActionReject,
+		Comment: "off brand",
+	})
+	if !errors.Is(err, ErrApprovalRejected) {
+		t.Fatalf("expected ErrApprovalRejected, got %v", err)
+	}
+	if len(published) != 0 {
+		t.Fatal("publish must not run after rejection")
+	}
+
+	session, _ := store.GetSession(context.Background(), "sess-1")
+	if stored := session.GetRun(pending.RunID); stored.Status != RunStatusFailed {
+		t.Fatalf("expected failed run, got %s", stored.Status)
+	}
+}
+
+func TestApproval_InvalidDecision(t *testing.T) {
+	var published []string
+	wf := newApprovalWorkflow(t, NewMemoryStorage(10), &published)
+	pending := startApprovalRun(t, wf, "sess-1")
+
+	for _, decision := range []ApprovalDecision{{Action: "maybe"}, {Action: ApprovalActionEdit}} {
+		if _, err := wf.ResolveApproval(context.Background(), pending.RunID, decision); err == nil {
+			t.Errorf("expected error for decision %+v", decision)
+		}
+	}
+	if _, err := NewApproval(ApprovalConfig{}); err == nil {
+		t.Error("expected error for approval without ID or name")
+	}
+}
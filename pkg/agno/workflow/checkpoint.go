@@ -0,0 +1,110 @@
+package workflow
+
+import (
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+// Checkpoint is the execution state saved after a top-level node completes
+// Checkpoint 是顶层节点完成后保存的执行状态
+type Checkpoint struct {
+	// StepID is the last completed node; empty means no node has completed yet
+	// StepID 是最后完成的节点；为空表示尚无节点完成
+	StepID string `json:"step_id,omitempty"`
+
+	// StepIndex is the position of StepID in the workflow (-1 before the first node)
+	// StepIndex 是 StepID 在工作流中的位置（首个节点之前为 -1）
+	StepIndex int `json:"step_index"`
+
+	// Output is the execution output after the node
+	// Output 是节点执行后的输出
+	Output string `json:"output"`
+
+	// Data is the execution data after the node
+	// Data 是节点执行后的执行数据
+	Data map[string]interface{} `json:"data,omitempty"`
+
+	// Metadata is the execution metadata after the node
+	// Metadata 是节点执行后的元数据
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// SessionState is the session state after the node, without the history
+	// entries that are reloaded on resume
+	// SessionState 是节点执行后的会话状态，不含恢复时重新加载的历史条目
+	SessionState map[string]interface{} `json:"session_state,omitempty"`
+
+	// Messages is the conversation accumulated so far
+	// Messages 是目前累积的对话消息
+	Messages []*types.Message `json:"messages,omitempty"`
+
+	// CreatedAt is when the checkpoint was taken
+	// CreatedAt 是检查点的创建时间
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// transientSessionState lists session state keys that are rebuilt on resume
+// instead of being checkpointed (workflow_session would also form a cycle)
+// transientSessionState 列出恢复时重建而非写入检查点的会话状态键
+var transientSessionState = map[string]bool{
+	"messages":                 true,
+	"workflow_session":         true,
+	"workflow_history":         true,
+	"workflow_history_context": true,
+	"workflow_history_config":  true,
+}
+
+// newCheckpoint snapshots execCtx after the node at stepIndex
+// newCheckpoint 在 stepIndex 节点完成后为 execCtx 创建快照
+func newCheckpoint(stepIndex int, stepID string, execCtx *ExecutionContext) *Checkpoint {
+	state := execCtx.ExportSessionState()
+	for key := range state {
+		if transientSessionState[key] {
+			delete(state, key)
+		}
+	}
+
+	messages := execCtx.GetMessages()
+	return &Checkpoint{
+		StepID:       stepID,
+		StepIndex:    stepIndex,
+		Output:       execCtx.Output,
+		Data:         cloneMap(execCtx.Data),
+		Metadata:     cloneMap(execCtx.Metadata),
+		SessionState: state,
+		Messages:     append([]*types.Message(nil), messages...),
+		CreatedAt:    time.Now(),
+	}
+}
+
+// restore copies the checkpointed state into execCtx
+// restore 将检查点状态复制到 execCtx
+func (c *Checkpoint) restore(execCtx *ExecutionContext) {
+	execCtx.Output = c.Output
+	for k, v := range c.Data {
+		execCtx.Data[k] = v
+	}
+	execCtx.MergeMetadata(c.Metadata)
+	execCtx.ApplySessionState(c.SessionState)
+	if len(c.Messages) > 0 {
+		execCtx.AddMessages(c.Messages)
+	}
+}
+
+// resumeIndex returns the index of the first node to run after the checkpoint,
+// or -1 if the checkpointed node is no longer part of the workflow
+// resumeIndex 返回检查点之后要执行的第一个节点索引；若节点已不在工作流中则返回 -1
+func (c *Checkpoint) resumeIndex(steps []Node) int {
+	if c.StepID == "" {
+		return max(c.StepIndex+1, 0)
+	}
+	if c.StepIndex >= 0 && c.StepIndex < len(steps) && steps[c.StepIndex].GetID() == c.StepID {
+		return c.StepIndex + 1
+	}
+	for i, step := range steps {
+		if step.GetID() == c.StepID {
+			return i + 1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,363 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+)
+
+func TestWorkflow_ResumeAfterFailure(t *testing.T) {
+	store := NewMemoryStorage(10)
+	failing := true
+	var secondRuns, thirdRuns int
+
+	first := &stubNode{
+		id: "first",
+		execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+			execCtx.Output = "drafted"
+			execCtx.Set("draft", "v1")
+			execCtx.SetSessionState("counter", 1)
+			execCtx.AddMessages([]*types.Message{types.NewAssistantMessage("draft ready")})
+			return execCtx, nil
+		},
+	}
+	second := &stubNode{
+		id: "second",
+		execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+			secondRuns++
+			if failing {
+				return nil, errors.New("upstream unavailable")
+			}
+			draft, _ := execCtx.Get("draft")
+			execCtx.Output = execCtx.Output + "+" + draft.(string)
+			return execCtx, nil
+		},
+	}
+	third := &stubNode{
+		id: "third",
+		execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+			thirdRuns++
+			execCtx.Output += "+published"
+			return execCtx, nil
+		},
+	}
+
+	wf, err := New(Config{
+		ID:                "checkpointed",
+		Steps:             []Node{first, second, third},
+		HistoryStore:      store,
+		EnableCheckpoints: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create workflow: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := wf.Run(ctx, "write a post", "sess-1", WithUserID("user-1")); err == nil {
+		t.Fatal("expected second step to fail")
+	}
+
+	session, err := store.GetSession(ctx, "sess-1")
+	if err != nil {
+		t.Fatalf("GetSession error: %v", err)
+	}
+	runs := session.GetRuns()
+	if len(runs) != 1 {
+		t.Fatalf("expected a single run record, got %d", len(runs))
+	}
+	failed := runs[0]
+	if failed.Status != RunStatusFailed || failed.LastStepID != "second" {
+		t.Fatalf("unexpected failed run %+v", failed)
+	}
+	if failed.Checkpoint == nil || failed.Checkpoint.StepID != "first" || failed.Checkpoint.Output != "drafted" {
+		t.Fatalf("expected checkpoint after first step, got %+v", failed.Checkpoint)
+	}
+
+	failing = false
+	result, err := wf.Resume(ctx, failed.RunID)
+	if err != nil {
+		t.Fatalf("Resume error: %v", err)
+	}
+	if result.Output != "drafted+v1+published" {
+		t.Fatalf("unexpected output %q", result.Output)
+	}
+	if counter, _ := result.GetSessionState("counter"); counter != 1 {
+		t.Fatalf("expected session state restored, got %v", counter)
+	}
+	if msgs := result.GetMessages(); len(msgs) != 1 || msgs[0].Content != "draft ready" {
+		t.Fatalf("expected messages restored, got %+v", msgs)
+	}
+	if result.UserID != "user-1" {
+		t.Fatalf("expected user restored, got %q", result.UserID)
+	}
+	if secondRuns != 2 || thirdRuns != 1 {
+		t.Fatalf("unexpected executions second=%d third=%d", secondRuns, thirdRuns)
+	}
+
+	session, _ = store.GetSession(ctx, "sess-1")
+	resumed := session.GetRun(failed.RunID)
+	if session.CountRuns() != 1 || resumed.Status != RunStatusCompleted || resumed.ResumedFrom != "second" {
+		t.Fatalf("unexpected resumed run %+v", resumed)
+	}
+	if resumed.Checkpoint != nil {
+		t.Fatal("expected checkpoint cleared after completion")
+	}
+
+	if _, err := wf.Resume(ctx, failed.RunID); err == nil {
+		t.Fatal("expected completed run to be rejected")
+	}
+}
+
+func TestWorkflow_ResumeAfterCrash(t *testing.T) {
+	store := NewMemoryStorage(10)
+	newWorkflow := func(crash bool) *Workflow {
+		steps := []Node{
+			&stubNode{id: "fetch", execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+				execCtx.Set("items", 3)
+				return execCtx, nil
+			}},
+			&stubNode{id: "process", execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+				if crash {
+					// Stop the goroutine without returning, like a killed process
+					runtime.Goexit()
+				}
+				items, _ := execCtx.Get("items")
+				execCtx.Output = "processed"
+				execCtx.Set("count", items)
+				return execCtx, nil
+			}},
+		}
+		wf, err := New(Config{ID: "crashy", Steps: steps, HistoryStore: store, EnableCheckpoints: true})
+		if err != nil {
+			t.Fatalf("failed to create workflow: %v", err)
+		}
+		return wf
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		newWorkflow(true).Run(context.Background(), "go", "sess-crash")
+	}()
+	<-done
+
+	ctx := context.Background()
+	session, err := store.GetSession(ctx, "sess-crash")
+	if err != nil {
+		t.Fatalf("GetSession error: %v", err)
+	}
+	interrupted := session.GetRuns()[0]
+	if interrupted.Status != RunStatusRunning || interrupted.Checkpoint == nil || interrupted.Checkpoint.StepID != "fetch" {
+		t.Fatalf("expected running run checkpointed at fetch, got %+v", interrupted)
+	}
+
+	result, err := newWorkflow(false).Resume(ctx, interrupted.RunID)
+	if err != nil {
+		t.Fatalf("Resume error: %v", err)
+	}
+	if result.Output != "processed" {
+		t.Fatalf("unexpected output %q", result.Output)
+	}
+	if count, _ := result.Get("count"); count != 3 {
+		t.Fatalf("expected data restored, got %v", count)
+	}
+}
+
+// updateCounter counts session-wide writes
+type updateCounter struct {
+	*MemoryStorage
+	updates int
+}
+
+func (c *updateCounter) UpdateSession(ctx context.Context, session *WorkflowSession) error {
+	c.updates++
+	return c.MemoryStorage.UpdateSession(ctx, session)
+}
+
+func TestWorkflow_CheckpointsSaveOnlyTheRun(t *testing.T) {
+	store := &updateCounter{MemoryStorage: NewMemoryStorage(10)}
+	steps := []Node{
+		&stubNode{id: "a", execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) { return execCtx, nil }},
+		&stubNode{id: "b", execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) { return execCtx, nil }},
+	}
+	wf, err := New(Config{ID: "checkpointed", Steps: steps, HistoryStore: store, EnableCheckpoints: true})
+	if err != nil {
+		t.Fatalf("failed to create workflow: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := wf.Run(ctx, "go", "sess-1"); err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+	}
+	if store.updates != 0 {
+		t.Fatalf("expected runs saved through RunStore, got %d session updates", store.updates)
+	}
+	session, _ := store.GetSession(ctx, "sess-1")
+	for _, run := range session.GetRuns() {
+		if run.Status != RunStatusCompleted {
+			t.Fatalf("unexpected run %+v", run)
+		}
+	}
+	if session.CountRuns() != 3 {
+		t.Fatalf("expected 3 runs, got %d", session.CountRuns())
+	}
+}
+
+func TestWorkflow_ConcurrentResumeRunsOnce(t *testing.T) {
+	store := NewMemoryStorage(10)
+	failing := true
+	var published atomic.Int32
+	steps := []Node{
+		&stubNode{id: "draft", execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) { return execCtx, nil }},
+		&stubNode{id: "publish", execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+			if failing {
+				return nil, errors.New("offline")
+			}
+			published.Add(1)
+			return execCtx, nil
+		}},
+	}
+	wf, err := New(Config{ID: "checkpointed", Steps: steps, HistoryStore: store, EnableCheckpoints: true})
+	if err != nil {
+		t.Fatalf("failed to create workflow: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := wf.Run(ctx, "go", "sess-1"); err == nil {
+		t.Fatal("expected publish to fail")
+	}
+	session, _ := store.GetSession(ctx, "sess-1")
+	runID := session.GetRuns()[0].RunID
+
+	failing = false
+	var loaded sync.WaitGroup
+	loaded.Add(2)
+	wf, err = New(Config{ID: "checkpointed", Steps: steps, HistoryStore: &loadBarrier{MemoryStorage: store, loaded: &loaded}, EnableCheckpoints: true})
+	if err != nil {
+		t.Fatalf("failed to create workflow: %v", err)
+	}
+
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = wf.Resume(ctx, runID)
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrRunConflict):
+			t.Errorf("expected ErrRunConflict for the losing caller, got %v", err)
+		}
+	}
+	if succeeded != 1 || published.Load() != 1 {
+		t.Fatalf("expected one resume and one publish, got %d resumes and %d publishes", succeeded, published.Load())
+	}
+}
+
+func TestWorkflow_ResumeFencesLiveRun(t *testing.T) {
+	store := NewMemoryStorage(10)
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var published atomic.Int32
+
+	newWorkflow := func(block bool) *Workflow {
+		steps := []Node{
+			&stubNode{id: "fetch", execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) { return execCtx, nil }},
+			&stubNode{id: "process", execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+				if block {
+					close(entered)
+					<-release
+				}
+				return execCtx, nil
+			}},
+			&stubNode{id: "publish", execute: func(_ context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+				published.Add(1)
+				return execCtx, nil
+			}},
+		}
+		wf, err := New(Config{ID: "fenced", Steps: steps, HistoryStore: store, EnableCheckpoints: true})
+		if err != nil {
+			t.Fatalf("failed to create workflow: %v", err)
+		}
+		return wf
+	}
+
+	// The original run hangs in process, looking dead to an operator
+	ctx := context.Background()
+	done := make(chan error, 1)
+	go func() {
+		_, err := newWorkflow(true).Run(ctx, "go", "sess-1")
+		done <- err
+	}()
+	<-entered
+
+	session, _ := store.GetSession(ctx, "sess-1")
+	runID := session.GetRuns()[0].RunID
+	if _, err := newWorkflow(false).Resume(ctx, runID); err != nil {
+		t.Fatalf("Resume error: %v", err)
+	}
+
+	// Once it wakes up, the original run must stop instead of publishing again
+	close(release)
+	if err := <-done; !errors.Is(err, ErrRunConflict) {
+		t.Fatalf("expected the stale run to stop with ErrRunConflict, got %v", err)
+	}
+	if published.Load() != 1 {
+		t.Fatalf("expected publish to run once, ran %d times", published.Load())
+	}
+	session, _ = store.GetSession(ctx, "sess-1")
+	if run := session.GetRun(runID); run.Status != RunStatusCompleted {
+		t.Fatalf("expected the resumed run to stay completed, got %s", run.Status)
+	}
+}
+
+func TestWorkflow_ResumeErrors(t *testing.T) {
+	wf, err := New(Config{ID: "plain", Steps: []Node{&stubNode{id: "only"}}})
+	if err != nil {
+		t.Fatalf("failed to create workflow: %v", err)
+	}
+	if _, err := wf.Resume(context.Background(), "run-1"); err == nil {
+		t.Fatal("expected error without history store")
+	}
+
+	wf, _ = New(Config{ID: "checkpointed", Steps: []Node{&stubNode{id: "only"}}, EnableCheckpoints: true})
+	if _, err := wf.Resume(context.Background(), "missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("expected ErrRunNotFound, got %v", err)
+	}
+	if _, err := wf.Resume(context.Background(), ""); err == nil {
+		t.Fatal("expected error for empty run ID")
+	}
+}
+
+func TestCheckpoint_ResumeIndex(t *testing.T) {
+	steps := []Node{&stubNode{id: "a"}, &stubNode{id: "b"}, &stubNode{id: "c"}}
+	cases := []struct {
+		checkpoint Checkpoint
+		want       int
+	}{
+		{Checkpoint{StepIndex: -1}, 0},
+		{Checkpoint{StepID: "a", StepIndex: 0}, 1},
+		{Checkpoint{StepID: "b", StepIndex: 0}, 2},
+		{Checkpoint{StepID: "c", StepIndex: 2}, 3},
+		{Checkpoint{StepID: "gone", StepIndex: 1}, -1},
+	}
+	for _, c := range cases {
+		if got := c.checkpoint.resumeIndex(steps); got != c.want {
+			t.Errorf("resumeIndex(%s, %d) = %d, want %d", c.checkpoint.StepID, c.checkpoint.StepIndex, got, c.want)
+		}
+	}
+}
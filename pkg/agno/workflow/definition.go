@@ -0,0 +1,583 @@
+package workflow
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rexleimo/agno-go/pkg/agno/agent"
+	"github.com/rexleimo/agno-go/pkg/agno/team"
+	"github.com/rexleimo/agno-go/pkg/agno/workflow/expr"
+	"gopkg.in/yaml.v3"
+)
+
+// Definition is the declarative form of a workflow. It is usually loaded from
+// YAML or JSON with ParseDefinition and turned into a Workflow with Build.
+//
+//	id: support
+//	steps:
+//	  - type: step
+//	    id: classify
+//	    agent: classifier
+//	  - type: condition
+//	    id: urgent
+//	    if: "output contains 'urgent'"
+//	    then: {type: step, id: escalate, team: escalation}
+//	    else: {type: step, id: reply, agent: responder}
+type Definition struct {
+	ID                string            `yaml:"id,omitempty" json:"id,omitempty"`
+	Name              string            `yaml:"name,omitempty" json:"name,omitempty"`
+	EnableHistory     bool              `yaml:"enable_history,omitempty" json:"enable_history,omitempty"`
+	NumHistoryRuns    int               `yaml:"num_history_runs,omitempty" json:"num_history_runs,omitempty"`
+	AddHistoryToSteps bool              `yaml:"add_history_to_steps,omitempty" json:"add_history_to_steps,omitempty"`
+	EnableCheckpoints bool              `yaml:"enable_checkpoints,omitempty" json:"enable_checkpoints,omitempty"`
+	Steps             []*NodeDefinition `yaml:"steps" json:"steps"`
+
+	pos position
+}
+
+// NodeDefinition describes one workflow node. Type selects the node kind and
+// which of the other fields apply.
+type NodeDefinition struct {
+	Type NodeType `yaml:"type" json:"type"`
+	ID   string   `yaml:"id" json:"id"`
+	Name string   `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// Step: exactly one of Agent or Team, referenced by registered ID
+	Description      string `yaml:"description,omitempty" json:"description,omitempty"`
+	Agent            string `yaml:"agent,omitempty" json:"agent,omitempty"`
+	Team             string `yaml:"team,omitempty" json:"team,omitempty"`
+	AddHistoryToStep *bool  `yaml:"add_history_to_step,omitempty" json:"add_history_to_step,omitempty"`
+	NumHistoryRuns   *int   `yaml:"num_history_runs,omitempty" json:"num_history_runs,omitempty"`
+
+	// Condition: If is an expression choosing between Then and Else
+	If   string          `yaml:"if,omitempty" json:"if,omitempty"`
+	Then *NodeDefinition `yaml:"then,omitempty" json:"then,omitempty"`
+	Else *NodeDefinition `yaml:"else,omitempty" json:"else,omitempty"`
+
+	// Loop: Body runs while the While expression holds, at most MaxIterations times
+	While         string          `yaml:"while,omitempty" json:"while,omitempty"`
+	MaxIterations int             `yaml:"max_iterations,omitempty" json:"max_iterations,omitempty"`
+	Body          *NodeDefinition `yaml:"body,omitempty" json:"body,omitempty"`
+
+	// Parallel: Steps run concurrently
+	Steps []*NodeDefinition `yaml:"steps,omitempty" json:"steps,omitempty"`
+
+	// Router: the Route expression yields a key of Routes; Default names the
+	// route taken when the key matches none. A null route is a no-op.
+	Route   string                     `yaml:"route,omitempty" json:"route,omitempty"`
+	Routes  map[string]*NodeDefinition `yaml:"routes,omitempty" json:"routes,omitempty"`
+	Default string                     `yaml:"default,omitempty" json:"default,omitempty"`
+
+	// Approval
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	pos position
+}
+
+// DefinitionError is a problem found at a line of a workflow definition
+type DefinitionError struct {
+	Line    int
+	Message string
+}
+
+func (e DefinitionError) Error() string {
+	if e.Line <= 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// DefinitionErrors lists every problem found while loading a definition
+type DefinitionErrors []DefinitionError
+
+func (e DefinitionErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "invalid workflow definition:\n" + strings.Join(msgs, "\n")
+}
+
+// LoaderConfig supplies the runtime objects a definition refers to
+type LoaderConfig struct {
+	// Agents and Teams resolve the agent and team IDs used by steps
+	Agents map[string]*agent.Agent
+	Teams  map[string]*team.Team
+
+	HistoryStore WorkflowStorage
+	Logger       *slog.Logger
+}
+
+// Expression variables available to conditions, loops and routers
+var expressionVarNames = []string{"input", "output", "data", "state", "metadata"}
+
+// definitionFields lists the keys accepted at the top level of a definition
+var definitionFields = fieldSet("id", "name", "enable_history", "num_history_runs", "add_history_to_steps", "enable_checkpoints", "steps")
+
+// nodeFields lists the keys accepted for each node type
+var nodeFields = map[NodeType]map[string]bool{
+	NodeTypeStep:      fieldSet("type", "id", "name", "description", "agent", "team", "add_history_to_step", "num_history_runs"),
+	NodeTypeCondition: fieldSet("type", "id", "name", "if", "then", "else"),
+	NodeTypeLoop:      fieldSet("type", "id", "name", "while", "max_iterations", "body"),
+	NodeTypeParallel:  fieldSet("type", "id", "name", "steps"),
+	NodeTypeRouter:    fieldSet("type", "id", "name", "route", "routes", "default"),
+	NodeTypeApproval:  fieldSet("type", "id", "name", "message"),
+}
+
+// LoadDefinitionFile reads a YAML or JSON workflow definition and builds it
+func LoadDefinitionFile(path string, config LoaderConfig) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow definition: %w", err)
+	}
+
+	wf, err := LoadDefinition(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return wf, nil
+}
+
+// LoadDefinition parses a YAML or JSON workflow definition and builds it
+func LoadDefinition(data []byte, config LoaderConfig) (*Workflow, error) {
+	def, err := ParseDefinition(data)
+	if err != nil {
+		return nil, err
+	}
+	return def.Build(config)
+}
+
+// ParseDefinition decodes a YAML or JSON workflow definition. Syntax errors,
+// mistyped values and unknown fields are returned as DefinitionErrors.
+func ParseDefinition(data []byte) (*Definition, error) {
+	var def Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, yamlDefinitionErrors(err)
+	}
+	return &def, nil
+}
+
+// Build resolves agent and team references, compiles expressions and creates
+// the workflow. All problems are reported together as DefinitionErrors.
+func (d *Definition) Build(config LoaderConfig) (*Workflow, error) {
+	b := &definitionBuilder{config: config, ids: make(map[string]int)}
+
+	if len(d.Steps) == 0 {
+		b.errorf(d.pos.lineOf("steps"), "workflow has no steps")
+	}
+
+	steps := make([]Node, 0, len(d.Steps))
+	for _, step := range d.Steps {
+		if step == nil {
+			b.errorf(d.pos.lineOf("steps"), "empty step")
+			continue
+		}
+		steps = append(steps, b.node(step))
+	}
+
+	if len(b.errs) > 0 {
+		sort.SliceStable(b.errs, func(i, j int) bool { return b.errs[i].Line < b.errs[j].Line })
+		return nil, b.errs
+	}
+
+	return New(Config{
+		ID:                d.ID,
+		Name:              d.Name,
+		Steps:             steps,
+		Logger:            config.Logger,
+		EnableHistory:     d.EnableHistory,
+		HistoryStore:      config.HistoryStore,
+		NumHistoryRuns:    d.NumHistoryRuns,
+		AddHistoryToSteps: d.AddHistoryToSteps,
+		EnableCheckpoints: d.EnableCheckpoints,
+	})
+}
+
+// UnmarshalYAML decodes the definition and rejects unknown fields
+func (d *Definition) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return typeError(value.Line, "workflow definition must be a mapping")
+	}
+
+	if err := rejectAliases(value); err != nil {
+		return err
+	}
+
+	type plain Definition
+	err := value.Decode((*plain)(d))
+	d.pos = newPosition(value)
+	return checkFields(value, err, "workflow", definitionFields)
+}
+
+// UnmarshalYAML decodes the node and rejects fields that do not apply to its type
+func (n *NodeDefinition) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return typeError(value.Line, "node must be a mapping with a type")
+	}
+
+	if err := rejectAliases(value); err != nil {
+		return err
+	}
+
+	type plain NodeDefinition
+	err := value.Decode((*plain)(n))
+	n.pos = newPosition(value)
+
+	// Unknown types have no field set; Build reports them
+	return checkFields(value, err, fmt.Sprintf("%s node", n.Type), nodeFields[n.Type])
+}
+
+// rejectAliases returns an error for the first alias under value. Each
+// UnmarshalYAML decodes with a fresh decoder, which loses yaml's check for
+// anchors that contain themselves, so a self-referencing node would recurse
+// until the stack overflows.
+func rejectAliases(value *yaml.Node) error {
+	if value.Kind == yaml.AliasNode {
+		return typeError(value.Line, fmt.Sprintf("alias *%s is not supported in workflow definitions", value.Value))
+	}
+	for _, child := range value.Content {
+		if err := rejectAliases(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// position records where a definition and its fields appear in the source
+type position struct {
+	line   int
+	fields map[string]int
+}
+
+func newPosition(value *yaml.Node) position {
+	p := position{line: value.Line, fields: make(map[string]int, len(value.Content)/2)}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		p.fields[value.Content[i].Value] = value.Content[i].Line
+	}
+	return p
+}
+
+// lineOf returns the line of the given field, or of the enclosing mapping
+func (p position) lineOf(field string) int {
+	if line, ok := p.fields[field]; ok {
+		return line
+	}
+	return p.line
+}
+
+func fieldSet(names ...string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// checkFields merges decode errors with errors for keys missing from fields.
+// A nil fields set skips the check.
+func checkFields(value *yaml.Node, decodeErr error, kind string, fields map[string]bool) error {
+	var msgs []string
+	if decodeErr != nil {
+		typeErr, ok := decodeErr.(*yaml.TypeError)
+		if !ok {
+			return decodeErr
+		}
+		msgs = append(msgs, typeErr.Errors...)
+	}
+
+	if fields != nil {
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			key := value.Content[i]
+			if !fields[key.Value] {
+				msgs = append(msgs, fmt.Sprintf("line %d: unknown field %q for %s", key.Line, key.Value, kind))
+			}
+		}
+	}
+
+	if len(msgs) > 0 {
+		return &yaml.TypeError{Errors: msgs}
+	}
+	return nil
+}
+
+func typeError(line int, msg string) error {
+	return &yaml.TypeError{Errors: []string{fmt.Sprintf("line %d: %s", line, msg)}}
+}
+
+// yamlDefinitionErrors converts yaml errors of the form "line N: msg" into DefinitionErrors
+func yamlDefinitionErrors(err error) DefinitionErrors {
+	var msgs []string
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		msgs = typeErr.Errors
+	} else {
+		msgs = []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+	}
+
+	errs := make(DefinitionErrors, 0, len(msgs))
+	for _, msg := range msgs {
+		var line int
+		if _, scanErr := fmt.Sscanf(msg, "line %d:", &line); scanErr == nil {
+			msg = strings.TrimSpace(msg[strings.Index(msg, ":")+1:])
+		}
+		errs = append(errs, DefinitionError{Line: line, Message: msg})
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Line < errs[j].Line })
+	return errs
+}
+
+// definitionBuilder turns node definitions into nodes while collecting errors
+type definitionBuilder struct {
+	config LoaderConfig
+	ids    map[string]int
+	errs   DefinitionErrors
+}
+
+func (b *definitionBuilder) errorf(line int, format string, args ...interface{}) {
+	b.errs = append(b.errs, DefinitionError{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// node builds n, or returns nil if n or one of its children is invalid
+func (b *definitionBuilder) node(n *NodeDefinition) Node {
+	before := len(b.errs)
+
+	if n.ID == "" {
+		b.errorf(n.pos.line, "node requires an id")
+	} else if first, dup := b.ids[n.ID]; dup {
+		b.errorf(n.pos.lineOf("id"), "duplicate node id %q (first used on line %d)", n.ID, first)
+	} else {
+		b.ids[n.ID] = n.pos.lineOf("id")
+	}
+
+	var build func() (Node, error)
+	switch n.Type {
+	case NodeTypeStep:
+		build = b.step(n)
+	case NodeTypeCondition:
+		build = b.condition(n)
+	case NodeTypeLoop:
+		build = b.loop(n)
+	case NodeTypeParallel:
+		build = b.parallel(n)
+	case NodeTypeRouter:
+		build = b.router(n)
+	case NodeTypeApproval:
+		build = func() (Node, error) {
+			return NewApproval(ApprovalConfig{ID: n.ID, Name: n.Name, Message: n.Message})
+		}
+	case "":
+		b.errorf(n.pos.line, "node type is required")
+	default:
+		b.errorf(n.pos.lineOf("type"), "unknown node type %q", n.Type)
+	}
+
+	if len(b.errs) > before || build == nil {
+		return nil
+	}
+	node, err := build()
+	if err != nil {
+		b.errorf(n.pos.line, "%s node %q: %v", n.Type, n.ID, err)
+		return nil
+	}
+	return node
+}
+
+// child builds a nested node, or reports it as missing when required
+func (b *definitionBuilder) child(parent *NodeDefinition, field string, n *NodeDefinition, required bool) Node {
+	if n == nil {
+		if required {
+			b.errorf(parent.pos.lineOf(field), "%s node %q requires %s", parent.Type, parent.ID, field)
+		}
+		return nil
+	}
+	return b.node(n)
+}
+
+// expression compiles the expression in field, reporting a missing one when required
+func (b *definitionBuilder) expression(n *NodeDefinition, field, src string, required bool, vars ...string) *expr.Expression {
+	if src == "" {
+		if required {
+			b.errorf(n.pos.lineOf(field), "%s node %q requires %s", n.Type, n.ID, field)
+		}
+		return nil
+	}
+	e, err := expr.Compile(src, vars...)
+	if err != nil {
+		b.errorf(n.pos.lineOf(field), "invalid %s expression %q: %v", field, src, err)
+		return nil
+	}
+	return e
+}
+
+func (b *definitionBuilder) step(n *NodeDefinition) func() (Node, error) {
+	config := StepConfig{
+		ID:               n.ID,
+		Name:             n.Name,
+		Description:      n.Description,
+		AddHistoryToStep: n.AddHistoryToStep,
+		NumHistoryRuns:   n.NumHistoryRuns,
+	}
+
+	switch {
+	case n.Agent != "" && n.Team != "":
+		b.errorf(n.pos.lineOf("team"), "step %q must reference either an agent or a team, not both", n.ID)
+	case n.Agent != "":
+		config.Agent = b.config.Agents[n.Agent]
+		if config.Agent == nil {
+			b.errorf(n.pos.lineOf("agent"), "step %q references unknown agent %q", n.ID, n.Agent)
+		}
+	case n.Team != "":
+		config.Team = b.config.Teams[n.Team]
+		if config.Team == nil {
+			b.errorf(n.pos.lineOf("team"), "step %q references unknown team %q", n.ID, n.Team)
+		}
+	default:
+		b.errorf(n.pos.line, "step %q requires an agent or a team", n.ID)
+	}
+
+	return func() (Node, error) { return NewStep(config) }
+}
+
+func (b *definitionBuilder) condition(n *NodeDefinition) func() (Node, error) {
+	cond := b.expression(n, "if", n.If, true, expressionVarNames...)
+	if n.Then == nil && n.Else == nil {
+		b.errorf(n.pos.line, "condition node %q requires then or else", n.ID)
+	}
+	thenNode := b.child(n, "then", n.Then, false)
+	elseNode := b.child(n, "else", n.Else, false)
+
+	return func() (Node, error) {
+		return NewCondition(ConditionConfig{
+			ID:   n.ID,
+			Name: n.Name,
+			Condition: func(execCtx *ExecutionContext) bool {
+				result, err := cond.EvalBool(expressionVars(execCtx))
+				if err != nil {
+					execCtx.Set(fmt.Sprintf("condition_%s_error", n.ID), err.Error())
+					return false
+				}
+				return result
+			},
+			TrueNode:  thenNode,
+			FalseNode: elseNode,
+		})
+	}
+}
+
+func (b *definitionBuilder) loop(n *NodeDefinition) func() (Node, error) {
+	cond := b.expression(n, "while", n.While, false, append([]string{"iteration"}, expressionVarNames...)...)
+	if n.MaxIterations < 0 {
+		b.errorf(n.pos.lineOf("max_iterations"), "loop node %q: max_iterations must not be negative", n.ID)
+	}
+	body := b.child(n, "body", n.Body, true)
+
+	return func() (Node, error) {
+		return NewLoop(LoopConfig{
+			ID:   n.ID,
+			Name: n.Name,
+			Body: body,
+			Condition: func(execCtx *ExecutionContext, iteration int) bool {
+				if cond == nil {
+					return true
+				}
+				vars := expressionVars(execCtx)
+				vars["iteration"] = iteration
+				result, err := cond.EvalBool(vars)
+				if err != nil {
+					execCtx.Set(fmt.Sprintf("loop_%s_error", n.ID), err.Error())
+					return false
+				}
+				return result
+			},
+			MaxIteration: n.MaxIterations,
+		})
+	}
+}
+
+func (b *definitionBuilder) parallel(n *NodeDefinition) func() (Node, error) {
+	if len(n.Steps) == 0 {
+		b.errorf(n.pos.lineOf("steps"), "parallel node %q requires steps", n.ID)
+	}
+	nodes := make([]Node, 0, len(n.Steps))
+	for _, step := range n.Steps {
+		if step == nil {
+			b.errorf(n.pos.lineOf("steps"), "parallel node %q has an empty step", n.ID)
+			continue
+		}
+		nodes = append(nodes, b.node(step))
+	}
+
+	return func() (Node, error) {
+		return NewParallel(ParallelConfig{ID: n.ID, Name: n.Name, Nodes: nodes})
+	}
+}
+
+func (b *definitionBuilder) router(n *NodeDefinition) func() (Node, error) {
+	route := b.expression(n, "route", n.Route, true, expressionVarNames...)
+	if len(n.Routes) == 0 {
+		b.errorf(n.pos.lineOf("routes"), "router node %q requires routes", n.ID)
+	}
+	if _, ok := n.Routes[n.Default]; n.Default != "" && !ok {
+		b.errorf(n.pos.lineOf("default"), "router node %q: default route %q is not defined", n.ID, n.Default)
+	}
+
+	// Build routes in source order so errors and duplicate IDs are reported consistently
+	keys := make([]string, 0, len(n.Routes))
+	for key := range n.Routes {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		li, lj := routeLine(n.Routes[keys[i]]), routeLine(n.Routes[keys[j]])
+		if li != lj {
+			return li < lj
+		}
+		return keys[i] < keys[j]
+	})
+
+	routes := make(map[string]Node, len(n.Routes))
+	for _, key := range keys {
+		if n.Routes[key] == nil {
+			routes[key] = nil
+			continue
+		}
+		routes[key] = b.node(n.Routes[key])
+	}
+
+	return func() (Node, error) {
+		return NewRouter(RouterConfig{
+			ID:   n.ID,
+			Name: n.Name,
+			Router: func(execCtx *ExecutionContext) string {
+				key, err := route.EvalString(expressionVars(execCtx))
+				if err != nil {
+					execCtx.Set(fmt.Sprintf("router_%s_error", n.ID), err.Error())
+				}
+				if _, ok := routes[key]; !ok && n.Default != "" {
+					return n.Default
+				}
+				return key
+			},
+			Routes: routes,
+		})
+	}
+}
+
+func routeLine(n *NodeDefinition) int {
+	if n == nil {
+		return 0
+	}
+	return n.pos.line
+}
+
+// expressionVars exposes the execution context to definition expressions
+func expressionVars(execCtx *ExecutionContext) map[string]interface{} {
+	return map[string]interface{}{
+		"input":    execCtx.Input,
+		"output":   execCtx.Output,
+		"data":     execCtx.Data,
+		"metadata": execCtx.Metadata,
+		"state":    execCtx.ExportSessionState(),
+	}
+}
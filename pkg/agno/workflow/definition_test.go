@@ -0,0 +1,286 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/agent"
+	"github.com/rexleimo/agno-go/pkg/agno/team"
+)
+
+func definitionLoaderConfig(t *testing.T) LoaderConfig {
+	t.Helper()
+	escalation, err := team.New(team.Config{
+		ID:     "escalation",
+		Agents: []*agent.Agent{createMockAgent("oncall", "escalated")},
+		Mode:   team.ModeSequential,
+	})
+	if err != nil {
+		t.Fatalf("team.New() error = %v", err)
+	}
+
+	return LoaderConfig{
+		Agents: map[string]*agent.Agent{
+			"classifier": createMockAgent("classifier", "URGENT billing issue"),
+			"responder":  createMockAgent("responder", "replied"),
+			"billing":    createMockAgent("billing", "billing handled"),
+			"tech":       createMockAgent("tech", "tech handled"),
+			"refiner":    createMockAgent("refiner", "refined"),
+		},
+		Teams: map[string]*team.Team{"escalation": escalation},
+	}
+}
+
+const supportDefinition = `
+id: support
+name: Support flow
+steps:
+  - type: step
+    id: classify
+    agent: classifier
+  - type: condition
+    id: urgent
+    if: "lower(output) contains 'urgent' && state.tier == 'pro'"
+    then: {type: step, id: escalate, team: escalation}
+    else: {type: step, id: reply, agent: responder}
+  - type: router
+    id: route
+    route: state.category
+    default: tech
+    routes:
+      billing: {type: step, id: billing-step, agent: billing}
+      tech: {type: step, id: tech-step, agent: tech}
+      ignore: null
+  - type: loop
+    id: refine
+    while: iteration < 2
+    max_iterations: 5
+    body:
+      type: parallel
+      id: polish
+      steps:
+        - {type: step, id: polish-a, agent: refiner}
+        - {type: step, id: polish-b, agent: refiner}
+`
+
+func TestLoadDefinition_Run(t *testing.T) {
+	wf, err := LoadDefinition([]byte(supportDefinition), definitionLoaderConfig(t))
+	if err != nil {
+		t.Fatalf("LoadDefinition() error = %v", err)
+	}
+	if wf.ID != "support" || wf.Name != "Support flow" || len(wf.Steps) != 4 {
+		t.Fatalf("unexpected workflow %s/%s with %d steps", wf.ID, wf.Name, len(wf.Steps))
+	}
+
+	tests := []struct {
+		name     string
+		state    map[string]interface{}
+		branch   string
+		route    string
+		routeOut string
+	}{
+		{"pro billing", map[string]interface{}{"tier": "pro", "category": "billing"}, "step_escalate_output", "billing", "step_billing-step_output"},
+		{"free unknown category", map[string]interface{}{"tier": "free", "category": "sales"}, "step_reply_output", "tech", "step_tech-step_output"},
+		{"ignored", map[string]interface{}{"category": "ignore"}, "step_reply_output", "ignore", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := wf.Run(context.Background(), "help", "", WithSessionState(tt.state))
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if _, ok := result.Get(tt.branch); !ok {
+				t.Errorf("expected condition branch %s to run", tt.branch)
+			}
+			if selected, _ := result.Get("router_route_selected"); selected != tt.route {
+				t.Errorf("router selected %v, want %s", selected, tt.route)
+			}
+			if tt.routeOut != "" {
+				if _, ok := result.Get(tt.routeOut); !ok {
+					t.Errorf("expected route output %s", tt.routeOut)
+				}
+			}
+			if iterations, _ := result.Get("loop_refine_iterations"); iterations != 2 {
+				t.Errorf("loop iterations = %v, want 2", iterations)
+			}
+			if result.Output != "refined" {
+				t.Errorf("Output = %q, want refined", result.Output)
+			}
+		})
+	}
+}
+
+func TestLoadDefinition_JSON(t *testing.T) {
+	definition := "{\n" +
+		"\t\"id\": \"json-flow\",\n" +
+		"\t\"steps\": [\n" +
+		"\t\t{\"type\": \"step\", \"id\": \"first\", \"agent\": \"responder\"},\n" +
+		"\t\t{\"type\": \"approval\", \"id\": \"review\", \"message\": \"Check the reply\"}\n" +
+		"\t]\n" +
+		"}"
+
+	wf, err := LoadDefinition([]byte(definition), definitionLoaderConfig(t))
+	if err != nil {
+		t.Fatalf("LoadDefinition() error = %v", err)
+	}
+	if len(wf.Steps) != 2 || wf.Steps[1].GetType() != NodeTypeApproval {
+		t.Fatalf("unexpected steps: %+v", wf.Steps)
+	}
+	if wf.historyStore == nil {
+		t.Fatal("approval workflows need a default history store")
+	}
+}
+
+func TestLoadDefinition_ValidationErrors(t *testing.T) {
+	definition := `id: broken
+steps:
+  - type: step
+    id: classify
+    agent: classifer
+  - type: step
+    id: classify
+    team: nobody
+  - type: condition
+    id: check
+    if: "ouput contains 'x'"
+    then: {type: step, id: reply, agent: responder, retries: 3}
+  - type: loop
+    id: again
+    while: "iteration <"
+  - type: router
+    id: route
+    route: output
+    default: missing
+    routes:
+      a: {type: step, agent: responder}
+  - type: branch
+    id: mystery
+  - id: untyped
+`
+
+	_, err := LoadDefinition([]byte(definition), definitionLoaderConfig(t))
+	var defErrs DefinitionErrors
+	if !errors.As(err, &defErrs) {
+		t.Fatalf("expected DefinitionErrors, got %v", err)
+	}
+
+	// Unknown fields are reported while parsing, before references are resolved
+	if len(defErrs) != 1 || defErrs[0].Line != 12 || !strings.Contains(defErrs[0].Message, `unknown field "retries" for step node`) {
+		t.Fatalf("unexpected parse errors: %v", err)
+	}
+
+	definition = strings.Replace(definition, ", retries: 3", "", 1)
+	_, err = LoadDefinition([]byte(definition), definitionLoaderConfig(t))
+	if !errors.As(err, &defErrs) {
+		t.Fatalf("expected DefinitionErrors, got %v", err)
+	}
+
+	want := []struct {
+		line int
+		msg  string
+	}{
+		{5, `unknown agent "classifer"`},
+		{7, `duplicate node id "classify" (first used on line 4)`},
+		{8, `unknown team "nobody"`},
+		{11, `unknown variable "ouput"`},
+		{13, `loop node "again" requires body`},
+		{15, `invalid while expression`},
+		{19, `default route "missing" is not defined`},
+		{21, "node requires an id"},
+		{22, `unknown node type "branch"`},
+		{24, "node type is required"},
+	}
+	if len(defErrs) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(defErrs), len(want), err)
+	}
+	for i, w := range want {
+		if defErrs[i].Line != w.line || !strings.Contains(defErrs[i].Message, w.msg) {
+			t.Errorf("error %d = %v, want line %d containing %q", i, defErrs[i], w.line, w.msg)
+		}
+	}
+}
+
+func TestParseDefinition_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		line       int
+		msg        string
+	}{
+		{"syntax", "id: x\nname: a: b\n", 2, "mapping values are not allowed"},
+		{"mistyped value", "steps:\n  - type: loop\n    id: l\n    max_iterations: lots\n", 4, "cannot unmarshal"},
+		{"scalar node", "steps:\n  - classify\n", 2, "node must be a mapping"},
+		{"unknown top-level field", "id: x\nstep: []\n", 2, `unknown field "step" for workflow`},
+		{"not a mapping", "- a\n- b\n", 1, "workflow definition must be a mapping"},
+		{"self-referencing alias", "steps: [&a {type: parallel, id: p, steps: [*a]}]\n", 1, "alias *a is not supported"},
+		{"alias", "steps:\n  - &s {type: step, id: a, agent: writer}\n  - *s\n", 3, "alias *s is not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDefinition([]byte(tt.definition))
+			var defErrs DefinitionErrors
+			if !errors.As(err, &defErrs) || len(defErrs) != 1 {
+				t.Fatalf("expected one DefinitionError, got %v", err)
+			}
+			if defErrs[0].Line != tt.line || !strings.Contains(defErrs[0].Message, tt.msg) {
+				t.Fatalf("error = %v, want line %d containing %q", defErrs[0], tt.line, tt.msg)
+			}
+		})
+	}
+
+	def, err := ParseDefinition(nil)
+	if err != nil {
+		t.Fatalf("ParseDefinition(nil) error = %v", err)
+	}
+	if _, err := def.Build(LoaderConfig{}); err == nil || !strings.Contains(err.Error(), "workflow has no steps") {
+		t.Fatalf("Build() error = %v, want no steps error", err)
+	}
+}
+
+func TestLoadDefinition_ExpressionErrorsAtRuntime(t *testing.T) {
+	definition := `steps:
+  - type: condition
+    id: check
+    if: "state.count > 3"
+    then: {type: step, id: reply, agent: responder}
+`
+	wf, err := LoadDefinition([]byte(definition), definitionLoaderConfig(t))
+	if err != nil {
+		t.Fatalf("LoadDefinition() error = %v", err)
+	}
+
+	result, err := wf.Run(context.Background(), "hi", "", WithSessionState(map[string]interface{}{"count": "many"}))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if taken, _ := result.Get("condition_check_result"); taken != false {
+		t.Fatalf("condition result = %v, want false", taken)
+	}
+	if msg, _ := result.Get("condition_check_error"); msg == nil {
+		t.Fatal("expected the evaluation error to be recorded")
+	}
+}
+
+func TestLoadDefinitionFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "support.yaml")
+	if err := os.WriteFile(path, []byte(supportDefinition), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadDefinitionFile(path, definitionLoaderConfig(t)); err != nil {
+		t.Fatalf("LoadDefinitionFile() error = %v", err)
+	}
+
+	_, err := LoadDefinitionFile(path, LoaderConfig{})
+	var defErrs DefinitionErrors
+	if !errors.As(err, &defErrs) || !strings.HasPrefix(err.Error(), path+": ") {
+		t.Fatalf("expected DefinitionErrors prefixed with the path, got %v", err)
+	}
+
+	if _, err := LoadDefinitionFile(filepath.Join(t.TempDir(), "missing.yaml"), LoaderConfig{}); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
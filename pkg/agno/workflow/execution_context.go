@@ -42,6 +42,10 @@ type ExecutionContext struct {
 	// HistoryContext is the formatted history context string
 	// HistoryContext 是格式化的历史上下文字符串
 	HistoryContext string `json:"history_context,omitempty"`
+
+	// approvals holds reviewer decisions waiting to be consumed by approval nodes
+	// approvals 保存等待审批节点使用的审批决定
+	approvals map[string]*ApprovalDecision
 }
 
 // NewExecutionContext creates a new execution context
@@ -265,3 +269,20 @@ func (ec *ExecutionContext) SetRunContextMetadata(runCtx map[string]interface{})
 	}
 	ec.Metadata["run_context"] = runCtx
 }
+
+// setApprovalDecision queues a decision for the approval node with stepID
+// setApprovalDecision 为 stepID 对应的审批节点设置决定
+func (ec *ExecutionContext) setApprovalDecision(stepID string, decision *ApprovalDecision) {
+	if ec.approvals == nil {
+		ec.approvals = make(map[string]*ApprovalDecision)
+	}
+	ec.approvals[stepID] = decision
+}
+
+// takeApprovalDecision returns and removes the decision for stepID
+// takeApprovalDecision 返回并移除 stepID 对应的决定
+func (ec *ExecutionContext) takeApprovalDecision(stepID string) *ApprovalDecision {
+	decision := ec.approvals[stepID]
+	delete(ec.approvals, stepID)
+	return decision
+}
@@ -0,0 +1,405 @@
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type node interface {
+	eval(vars map[string]interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+type variableNode struct {
+	name string
+}
+
+func (n *variableNode) eval(vars map[string]interface{}) (interface{}, error) {
+	return vars[n.name], nil
+}
+
+type listNode struct {
+	items []node
+}
+
+func (n *listNode) eval(vars map[string]interface{}) (interface{}, error) {
+	values := make([]interface{}, len(n.items))
+	for i, item := range n.items {
+		v, err := item.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+type lookupNode struct {
+	target node
+	key    node
+}
+
+func (n *lookupNode) eval(vars map[string]interface{}) (interface{}, error) {
+	target, err := n.target.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	key, err := n.key.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return lookup(target, key)
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(vars map[string]interface{}) (interface{}, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+type logicalNode struct {
+	or          bool
+	left, right node
+}
+
+func (n *logicalNode) eval(vars map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(left) == n.or {
+		return n.or, nil
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(right), nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n *compareNode) eval(vars map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "contains":
+		return contains(left, right)
+	case "in":
+		return contains(right, left)
+	}
+
+	cmp, err := order(left, right)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.op, err)
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+type callNode struct {
+	name string
+	fn   func(args []interface{}) (interface{}, error)
+	args []node
+}
+
+func (n *callNode) eval(vars map[string]interface{}) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	v, err := n.fn(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return v, nil
+}
+
+type function struct {
+	arity int
+	call  func(args []interface{}) (interface{}, error)
+}
+
+var functions = map[string]function{
+	"len": {1, func(args []interface{}) (interface{}, error) {
+		if args[0] == nil {
+			return float64(0), nil
+		}
+		if s, ok := args[0].(string); ok {
+			return float64(len([]rune(s))), nil
+		}
+		rv := reflect.ValueOf(args[0])
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return float64(rv.Len()), nil
+		}
+		return nil, fmt.Errorf("cannot take length of %T", args[0])
+	}},
+	"lower": {1, stringFunc(strings.ToLower)},
+	"upper": {1, stringFunc(strings.ToUpper)},
+	"trim":  {1, stringFunc(strings.TrimSpace)},
+	"startsWith": {2, func(args []interface{}) (interface{}, error) {
+		return strings.HasPrefix(toString(args[0]), toString(args[1])), nil
+	}},
+	"endsWith": {2, func(args []interface{}) (interface{}, error) {
+		return strings.HasSuffix(toString(args[0]), toString(args[1])), nil
+	}},
+	"string": {1, func(args []interface{}) (interface{}, error) {
+		return toString(args[0]), nil
+	}},
+	"number": {1, func(args []interface{}) (interface{}, error) {
+		if n, ok := toNumber(args[0]); ok {
+			return n, nil
+		}
+		if s, ok := args[0].(string); ok {
+			if n, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				return n, nil
+			}
+		}
+		return nil, fmt.Errorf("cannot convert %v to a number", args[0])
+	}},
+}
+
+func stringFunc(fn func(string) string) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		return fn(toString(args[0])), nil
+	}
+}
+
+// lookup returns target[key] for maps, slices and arrays. Missing keys and
+// out-of-range indexes yield nil, as does any lookup on nil.
+func lookup(target, key interface{}) (interface{}, error) {
+	if target == nil {
+		return nil, nil
+	}
+	if m, ok := target.(map[string]interface{}); ok {
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("map key must be a string, got %T", key)
+		}
+		return m[name], nil
+	}
+
+	rv := reflect.ValueOf(target)
+	switch rv.Kind() {
+	case reflect.Map:
+		name, ok := key.(string)
+		if !ok || rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot index %T with %T", target, key)
+		}
+		v := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+		if !v.IsValid() {
+			return nil, nil
+		}
+		return v.Interface(), nil
+	case reflect.Slice, reflect.Array:
+		n, ok := toNumber(key)
+		if !ok || n != float64(int(n)) {
+			return nil, fmt.Errorf("index of %T must be an integer, got %v", target, key)
+		}
+		i := int(n)
+		if i < 0 || i >= rv.Len() {
+			return nil, nil
+		}
+		return rv.Index(i).Interface(), nil
+	}
+	return nil, fmt.Errorf("cannot look up %v in %T", key, target)
+}
+
+func truthy(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case string:
+		return val != ""
+	}
+	if n, ok := toNumber(v); ok {
+		return n != 0
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len() > 0
+	case reflect.Pointer, reflect.Interface:
+		return !rv.IsNil()
+	}
+	return true
+}
+
+func equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		return ok && x == y
+	}
+	if x, ok := a.(string); ok {
+		y, ok := b.(string)
+		return ok && x == y
+	}
+	if x, ok := a.(bool); ok {
+		y, ok := b.(bool)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// order compares two numbers or two strings
+func order(a, b interface{}) (int, error) {
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			switch {
+			case x < y:
+				return -1, nil
+			case x > y:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s and %s", typeName(a), typeName(b))
+}
+
+// contains reports whether container holds item: a substring for strings, an
+// element for lists and a key for maps.
+func contains(container, item interface{}) (bool, error) {
+	if container == nil {
+		return false, nil
+	}
+	if s, ok := container.(string); ok {
+		sub, ok := item.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot search string for %s", typeName(item))
+		}
+		return strings.Contains(s, sub), nil
+	}
+
+	rv := reflect.ValueOf(container)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if equal(rv.Index(i).Interface(), item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case reflect.Map:
+		v, err := lookup(container, item)
+		if err != nil {
+			return false, err
+		}
+		if v != nil {
+			return true, nil
+		}
+		// Distinguish a present key holding nil from a missing key
+		name, _ := item.(string)
+		return rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key())).IsValid(), nil
+	}
+	return false, fmt.Errorf("cannot search %s", typeName(container))
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	}
+	if n, ok := toNumber(v); ok {
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func typeName(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	if _, ok := toNumber(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
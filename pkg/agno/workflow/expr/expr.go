@@ -0,0 +1,115 @@
+// Package expr implements the small expression language used by declarative
+// workflow definitions for conditions, loop guards and router keys.
+//
+// Expressions are side-effect free. They can read the variables supplied by
+// the caller and call a fixed set of built-in functions; there is no
+// assignment, no method calls and no access to struct fields.
+//
+//	output contains 'urgent' && state.retries < 3
+//	lower(data["step_classify_output"]) in ['billing', 'tech']
+//	!(iteration >= 2 || len(output) > 500)
+//
+// Supported syntax:
+//   - literals: numbers, 'single' or "double" quoted strings, true, false, null
+//     and lists such as [1, 'a']
+//   - variables and lookups: name, name.key, name["key"], list[0]
+//   - operators: ! && || == != < <= > >= contains in, and the word forms
+//     not, and, or
+//   - functions: len, lower, upper, trim, startsWith, endsWith, string, number
+//
+// Looking up a missing key yields null, and lookups on null yield null, so
+// `state.user.tier == 'pro'` is false rather than an error when user is unset.
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDepth bounds the nesting of parsed expressions
+const maxDepth = 64
+
+// Expression is a compiled expression
+type Expression struct {
+	source string
+	root   node
+}
+
+// Error reports a syntax or reference error in an expression
+type Error struct {
+	// Column is the 1-based position in the expression source
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+}
+
+// Compile parses src. Identifiers other than the given variable names and
+// the built-in functions are rejected.
+func Compile(src string, vars ...string) (*Expression, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, &Error{Column: 1, Msg: "empty expression"}
+	}
+
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		allowed[v] = true
+	}
+
+	p := &parser{tokens: tokens, vars: allowed}
+	root, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok.describe())
+	}
+
+	return &Expression{source: src, root: root}, nil
+}
+
+// MustCompile is like Compile but panics on error
+func MustCompile(src string, vars ...string) *Expression {
+	e, err := Compile(src, vars...)
+	if err != nil {
+		panic(fmt.Sprintf("expr: %q: %v", src, err))
+	}
+	return e
+}
+
+// String returns the expression source
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression against vars
+func (e *Expression) Eval(vars map[string]interface{}) (interface{}, error) {
+	return e.root.eval(vars)
+}
+
+// EvalBool evaluates the expression and converts the result to a boolean.
+// null, false, zero, empty strings and empty collections are false.
+func (e *Expression) EvalBool(vars map[string]interface{}) (bool, error) {
+	v, err := e.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+// EvalString evaluates the expression and converts the result to a string.
+// null becomes the empty string and whole numbers have no decimal point.
+func (e *Expression) EvalString(vars map[string]interface{}) (string, error) {
+	v, err := e.Eval(vars)
+	if err != nil {
+		return "", err
+	}
+	return toString(v), nil
+}
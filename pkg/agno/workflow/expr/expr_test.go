@@ -0,0 +1,174 @@
+package expr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testVars() map[string]interface{} {
+	return map[string]interface{}{
+		"input":  "Please help, this is URGENT",
+		"output": "category: billing",
+		"data": map[string]interface{}{
+			"step_classify_output": "Billing",
+			"scores":               []interface{}{1, 2.5, "x"},
+		},
+		"state": map[string]interface{}{
+			"retries": 2,
+			"user":    map[string]interface{}{"tier": "pro"},
+			"tags":    []string{"vip", "beta"},
+			"limits":  map[string]int{"daily": 10},
+			"count":   "42",
+			"empty":   nil,
+		},
+	}
+}
+
+var testVarNames = []string{"input", "output", "data", "state"}
+
+func TestExpression_Eval(t *testing.T) {
+	tests := []struct {
+		src  string
+		want interface{}
+	}{
+		{`output contains 'billing'`, true},
+		{`lower(input) contains "urgent"`, true},
+		{`input contains 'urgent'`, false},
+		{`state.retries < 3`, true},
+		{`state.retries >= 3`, false},
+		{`state.retries == 2.0`, true},
+		{`state.user.tier == 'pro'`, true},
+		{`state.user.missing == null`, true},
+		{`state.nothing.deeper == null`, true},
+		{`state["user"]["tier"]`, "pro"},
+		{`data["step_classify_output"]`, "Billing"},
+		{`lower(data.step_classify_output) in ['billing', 'tech']`, true},
+		{`'vip' in state.tags`, true},
+		{`state.tags contains 'alpha'`, false},
+		{`state contains 'empty'`, true},
+		{`state contains 'absent'`, false},
+		{`state.limits.daily > 5`, true},
+		{`data.scores[1]`, 2.5},
+		{`data.scores[7]`, nil},
+		{`len(data.scores)`, float64(3)},
+		{`len(state.user.missing)`, float64(0)},
+		{`number(state.count) == 42`, true},
+		{`string(state.retries)`, "2"},
+		{`startsWith(output, 'category') && endsWith(output, 'billing')`, true},
+		{`!(state.retries > 1) || upper('a') == 'A'`, true},
+		{`not state.retries > 1 or false`, false},
+		{`state.retries > 1 and trim('  x ') == 'x'`, true},
+		{`-1 < 0`, true},
+		{`'b' > 'a'`, true},
+		{`[]`, []interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := Compile(tt.src, testVarNames...)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			got, err := e.Eval(testVars())
+			if err != nil {
+				t.Fatalf("Eval() error = %v", err)
+			}
+			if !equal(got, tt.want) {
+				t.Fatalf("Eval() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpression_EvalBoolAndString(t *testing.T) {
+	vars := testVars()
+
+	truthiness := map[string]bool{
+		`state.retries`:   true,
+		`state.empty`:     false,
+		`state.tags`:      true,
+		`data.missing`:    false,
+		`''`:              false,
+		`0`:               false,
+		`output`:          true,
+		`state.user.tier`: true,
+	}
+	for src, want := range truthiness {
+		got, err := MustCompile(src, testVarNames...).EvalBool(vars)
+		if err != nil || got != want {
+			t.Errorf("EvalBool(%s) = %v, %v; want %v", src, got, err, want)
+		}
+	}
+
+	strs := map[string]string{
+		`state.user.tier`: "pro",
+		`state.retries`:   "2",
+		`state.missing`:   "",
+		`1.5`:             "1.5",
+	}
+	for src, want := range strs {
+		got, err := MustCompile(src, testVarNames...).EvalString(vars)
+		if err != nil || got != want {
+			t.Errorf("EvalString(%s) = %q, %v; want %q", src, got, err, want)
+		}
+	}
+}
+
+func TestExpression_ShortCircuit(t *testing.T) {
+	// The right-hand side would fail to evaluate
+	e := MustCompile(`state.retries > 5 && state.user < 1`, testVarNames...)
+	got, err := e.EvalBool(testVars())
+	if err != nil || got {
+		t.Fatalf("EvalBool() = %v, %v; want false, nil", got, err)
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		src    string
+		column int
+		msg    string
+	}{
+		{``, 1, "empty expression"},
+		{`ouput == 'x'`, 1, `unknown variable "ouput"`},
+		{`exec('rm')`, 1, `unknown function "exec"`},
+		{`len(output, input)`, 1, "expects 1 argument"},
+		{`output ==`, 10, "unexpected end of expression"},
+		{`(output`, 8, `expected ")"`},
+		{`output = 'x'`, 8, "unexpected character"},
+		{`'open`, 1, "unterminated string"},
+		{`output contains 'a' 'b'`, 21, "unexpected"},
+		{`state.`, 7, "expected field name"},
+		{strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100), 65, "nested too deeply"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := Compile(tt.src, testVarNames...)
+			var exprErr *Error
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("Compile() error = %v, want *Error", err)
+			}
+			if exprErr.Column != tt.column || !strings.Contains(exprErr.Msg, tt.msg) {
+				t.Fatalf("Compile() error = %v, want column %d containing %q", err, tt.column, tt.msg)
+			}
+		})
+	}
+}
+
+func TestExpression_EvalErrors(t *testing.T) {
+	tests := []string{
+		`state.user < 1`,
+		`output contains 1`,
+		`number('abc') == 1`,
+		`len(state.retries)`,
+		`output.field`,
+		`data.scores['x']`,
+	}
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			if _, err := MustCompile(src, testVarNames...).Eval(testVars()); err == nil {
+				t.Fatal("expected evaluation error")
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // 0-based byte offset
+	num  float64
+	str  string
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return fmt.Sprintf("string %q", t.str)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// operators lists the symbolic operators, longest first
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "-", "(", ")", "[", "]", ",", "."}
+
+// keywords maps word operators to their symbolic form
+var keywords = map[string]string{
+	"and":      "&&",
+	"or":       "||",
+	"not":      "!",
+	"contains": "contains",
+	"in":       "in",
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && isIdentPart(src[i]) {
+				i++
+			}
+			word := src[start:i]
+			if op, ok := keywords[word]; ok {
+				tokens = append(tokens, token{kind: tokOp, text: op, pos: start})
+			} else {
+				tokens = append(tokens, token{kind: tokIdent, text: word, pos: start})
+			}
+		case isDigit(c):
+			start := i
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			num, err := strconv.ParseFloat(src[start:i], 64)
+			if err != nil {
+				return nil, &Error{Column: start + 1, Msg: fmt.Sprintf("invalid number %q", src[start:i])}
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[start:i], pos: start, num: num})
+		case c == '\'' || c == '"':
+			start := i
+			str, next, err := scanString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			i = next
+			tokens = append(tokens, token{kind: tokString, text: src[start:i], pos: start, str: str})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &Error{Column: i + 1, Msg: fmt.Sprintf("unexpected character %q", c)}
+			}
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// scanString reads the quoted string starting at src[start] and returns its
+// value and the offset just past the closing quote.
+func scanString(src string, start int) (string, int, error) {
+	quote := src[start]
+	var b strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(src):
+			i++
+			switch src[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '\'', '"':
+				b.WriteByte(src[i])
+			default:
+				return "", 0, &Error{Column: i, Msg: fmt.Sprintf("unknown escape \\%c", src[i])}
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, &Error{Column: start + 1, Msg: "unterminated string"}
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parser is a recursive descent parser over the token list. Precedence from
+// lowest to highest: ||, &&, unary !, comparisons, postfix lookups.
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+	vars   map[string]bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == tokOp && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if p.accept(op) {
+		return nil
+	}
+	tok := p.peek()
+	return p.errorf(tok, "expected %q, found %s", op, tok.describe())
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return &Error{Column: tok.pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxDepth {
+		return p.errorf(p.peek(), "expression is nested too deeply")
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) parseExpression() (node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") {
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+var comparisonOps = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "contains": true, "in": true,
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	if tok.kind != tokOp || !comparisonOps[tok.text] {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	return &compareNode{op: tok.text, left: left, right: right}, nil
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	target, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("."):
+			tok := p.next()
+			if tok.kind != tokIdent {
+				return nil, p.errorf(tok, "expected field name after '.', found %s", tok.describe())
+			}
+			target = &lookupNode{target: target, key: &literalNode{value: tok.text}}
+		case p.accept("["):
+			key, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			target = &lookupNode{target: target, key: key}
+		default:
+			return target, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		return &literalNode{value: tok.num}, nil
+	case tokString:
+		return &literalNode{value: tok.str}, nil
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		if p.accept("(") {
+			return p.parseCall(tok)
+		}
+		if !p.vars[tok.text] {
+			return nil, p.errorf(tok, "unknown variable %q", tok.text)
+		}
+		return &variableNode{name: tok.text}, nil
+	case tokOp:
+		switch tok.text {
+		case "(":
+			inner, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "[":
+			return p.parseList()
+		case "-":
+			num := p.next()
+			if num.kind != tokNumber {
+				return nil, p.errorf(num, "expected number after '-', found %s", num.describe())
+			}
+			return &literalNode{value: -num.num}, nil
+		}
+	}
+	return nil, p.errorf(tok, "unexpected %s", tok.describe())
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, p.errorf(name, "unknown function %q", name.text)
+	}
+
+	var args []node
+	if !p.accept(")") {
+		for {
+			arg, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(args) != fn.arity {
+		return nil, p.errorf(name, "%s expects %d argument(s), got %d", name.text, fn.arity, len(args))
+	}
+	return &callNode{name: name.text, fn: fn.call, args: args}, nil
+}
+
+func (p *parser) parseList() (node, error) {
+	list := &listNode{}
+	if p.accept("]") {
+		return list, nil
+	}
+	for {
+		item, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		list.items = append(list.items, item)
+		if p.accept("]") {
+			return list, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 )
@@ -235,6 +236,100 @@ func (m *MemoryStorage) ListUserSessions(ctx context.Context, userID string, lim
 	return applyPagination(filtered, limit, offset), nil
 }
 
+// QuerySessions returns sessions matching the filter, oldest first
+// QuerySessions 返回匹配过滤条件的会话，按创建时间升序排列
+func (m *MemoryStorage) QuerySessions(ctx context.Context, filter SessionFilter) ([]*WorkflowSession, error) {
+	// Check context cancellation
+	// 检查上下文取消
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	filtered := make([]*WorkflowSession, 0)
+	for _, session := range m.sessions {
+		if filter.Matches(session) {
+			filtered = append(filtered, session)
+		}
+	}
+
+	// Sort by creation time so pagination is stable
+	// 按创建时间排序以保证分页稳定
+	sort.Slice(filtered, func(i, j int) bool {
+		if filtered[i].CreatedAt.Equal(filtered[j].CreatedAt) {
+			return filtered[i].SessionID < filtered[j].SessionID
+		}
+		return filtered[i].CreatedAt.Before(filtered[j].CreatedAt)
+	})
+
+	return applyPagination(filtered, filter.Limit, filter.Offset), nil
+}
+
+// GetSessionByRunID returns the session containing the run
+// GetSessionByRunID 返回包含该运行的会话
+func (m *MemoryStorage) GetSessionByRunID(ctx context.Context, runID string) (*WorkflowSession, error) {
+	// Check context cancellation
+	// 检查上下文取消
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, session := range m.sessions {
+		if session.GetRun(runID) != nil {
+			return session, nil
+		}
+	}
+	return nil, ErrRunNotFound
+}
+
+// SaveRun inserts or replaces a single run of the session
+// SaveRun 插入或替换会话中的单个运行
+func (m *MemoryStorage) SaveRun(ctx context.Context, sessionID string, run *WorkflowRun) error {
+	// Validate input
+	// 验证输入
+	if sessionID == "" {
+		return ErrInvalidSessionID
+	}
+	if run == nil || run.RunID == "" {
+		return ErrRunNotFound
+	}
+
+	// Check context cancellation
+	// 检查上下文取消
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return ErrSessionNotFound
+	}
+
+	// Only replace the stored run while nobody else saved it since it was loaded
+	// 仅当加载后没有其他调用方保存过该运行时才替换
+	if stored := session.GetRun(run.RunID); stored != nil && stored.Revision != run.Revision {
+		return ErrRunConflict
+	}
+	run.Revision++
+	session.UpsertRun(run)
+
+	return nil
+}
+
 // Clear removes all sessions older than the specified duration
 // Clear 删除早于指定持续时间的所有会话
 func (m *MemoryStorage) Clear(ctx context.Context, olderThan time.Duration) (int, error) {
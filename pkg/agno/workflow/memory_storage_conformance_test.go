@@ -0,0 +1,14 @@
+package workflow_test
+
+import (
+	"testing"
+
+	"github.com/rexleimo/agno-go/pkg/agno/workflow"
+	"github.com/rexleimo/agno-go/pkg/agno/workflow/storagetest"
+)
+
+func TestMemoryStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) workflow.WorkflowStorage {
+		return workflow.NewMemoryStorage(0)
+	})
+}
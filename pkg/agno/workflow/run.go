@@ -1,6 +1,8 @@
 package workflow
 
 import (
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/rexleimo/agno-go/pkg/agno/run"
@@ -12,8 +14,8 @@ import (
 type RunStatus string
 
 const (
-	// RunStatusPending indicates the run is pending
-	// RunStatusPending 表示运行正在等待
+	// RunStatusPending indicates the run is pending, e.g. waiting for approval
+	// RunStatusPending 表示运行正在等待（例如等待审批）
 	RunStatusPending RunStatus = "pending"
 
 	// RunStatusRunning indicates the run is in progress
@@ -94,6 +96,22 @@ type WorkflowRun struct {
 
 	// Events captures structured run output events for observability.
 	Events run.Events `json:"events,omitempty"`
+
+	// Checkpoint is the state after the last completed node, used by Workflow.Resume
+	// Checkpoint 是最后完成节点之后的状态，供 Workflow.Resume 使用
+	Checkpoint *Checkpoint `json:"checkpoint,omitempty"`
+
+	// PendingApproval is the request the run is waiting on while its status is pending
+	// PendingApproval 是运行处于 pending 状态时等待的审批请求
+	PendingApproval *ApprovalRequest `json:"pending_approval,omitempty"`
+
+	// Approvals lists the resolved approval requests with their decisions
+	// Approvals 列出已处理的审批请求及其决定
+	Approvals []*ApprovalRequest `json:"approvals,omitempty"`
+
+	// Revision counts the saves through RunStore.SaveRun, which uses it to detect concurrent updates
+	// Revision 记录通过 RunStore.SaveRun 保存的次数，用于检测并发更新
+	Revision int64 `json:"revision,omitempty"`
 }
 
 // NewWorkflowRun creates a new workflow run with the given parameters
@@ -112,6 +130,22 @@ func NewWorkflowRun(runID, sessionID, workflowID, input string) *WorkflowRun {
 	}
 }
 
+// clone copies the run so it can be changed without touching an instance the
+// storage shares with other readers
+// clone 复制运行记录，修改副本不会影响存储与其他读取方共享的实例
+func (r *WorkflowRun) clone() *WorkflowRun {
+	c := *r
+	c.Messages = slices.Clone(r.Messages)
+	c.Events = slices.Clone(r.Events)
+	c.Approvals = slices.Clone(r.Approvals)
+	c.Metadata = maps.Clone(r.Metadata)
+	if r.PendingApproval != nil {
+		request := *r.PendingApproval
+		c.PendingApproval = &request
+	}
+	return &c
+}
+
 // MarkStarted marks the run as started
 // MarkStarted 将运行标记为已开始
 func (r *WorkflowRun) MarkStarted() {
@@ -135,6 +169,14 @@ func (r *WorkflowRun) MarkFailed(err error) {
 	r.CompletedAt = time.Now()
 }
 
+// MarkPendingApproval suspends the run until the request is resolved
+// MarkPendingApproval 挂起运行直到审批请求被处理
+func (r *WorkflowRun) MarkPendingApproval(request *ApprovalRequest) {
+	r.Status = RunStatusPending
+	r.PendingApproval = request
+	r.LastStepID = request.StepID
+}
+
 // MarkCancelled marks the run as cancelled
 // MarkCancelled 将运行标记为已取消
 func (r *WorkflowRun) MarkCancelled() {
@@ -109,6 +109,39 @@ func (s *WorkflowSession) AddRun(run *WorkflowRun) {
 	s.UpdatedAt = time.Now()
 }
 
+// UpsertRun replaces the run with the same RunID, or appends it
+// UpsertRun 替换具有相同 RunID 的运行，不存在则追加
+func (s *WorkflowSession) UpsertRun(run *WorkflowRun) {
+	if run == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.UpdatedAt = time.Now()
+	for i, existing := range s.Runs {
+		if existing != nil && existing.RunID == run.RunID {
+			s.Runs[i] = run
+			return
+		}
+	}
+	s.Runs = append(s.Runs, run)
+}
+
+// GetRun returns the run with the given ID, or nil
+// GetRun 返回指定 ID 的运行，不存在则返回 nil
+func (s *WorkflowSession) GetRun(runID string) *WorkflowRun {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, run := range s.Runs {
+		if run != nil && run.RunID == runID {
+			return run
+		}
+	}
+	return nil
+}
+
 // AddCancellation records a cancellation snapshot.
 func (s *WorkflowSession) AddCancellation(record *CancellationRecord) {
 	if record == nil {
@@ -5,14 +5,16 @@ import (
 	"fmt"
 
 	"github.com/rexleimo/agno-go/pkg/agno/agent"
+	"github.com/rexleimo/agno-go/pkg/agno/team"
 )
 
-// Step represents a basic workflow step that executes an agent
-// Step 代表执行 agent 的基本工作流步骤
+// Step represents a basic workflow step that executes an agent or a team
+// Step 代表执行 agent 或 team 的基本工作流步骤
 type Step struct {
 	ID          string
 	Name        string
 	Agent       *agent.Agent
+	Team        *team.Team
 	Description string
 
 	// History configuration (private fields)
@@ -34,9 +36,12 @@ type Step struct {
 // StepConfig contains step configuration
 // StepConfig 包含步骤配置
 type StepConfig struct {
-	ID          string
-	Name        string
-	Agent       *agent.Agent
+	ID    string
+	Name  string
+	Agent *agent.Agent
+	// Team runs instead of Agent; exactly one of them must be set
+	// Team 代替 Agent 运行；两者必须且只能设置一个
+	Team        *team.Team
 	Description string
 
 	// History configuration
@@ -57,8 +62,12 @@ type StepConfig struct {
 
 // NewStep creates a new step
 func NewStep(config StepConfig) (*Step, error) {
-	if config.Agent == nil {
-		return nil, fmt.Errorf("agent is required for step")
+	if config.Agent == nil && config.Team == nil {
+		return nil, fmt.Errorf("agent or team is required for step")
+	}
+
+	if config.Agent != nil && config.Team != nil {
+		return nil, fmt.Errorf("step cannot have both an agent and a team")
 	}
 
 	if config.ID == "" {
@@ -73,6 +82,7 @@ func NewStep(config StepConfig) (*Step, error) {
 		ID:               config.ID,
 		Name:             config.Name,
 		Agent:            config.Agent,
+		Team:             config.Team,
 		Description:      config.Description,
 		addHistoryToStep: config.AddHistoryToStep,
 		numHistoryRuns:   config.NumHistoryRuns,
@@ -98,6 +108,10 @@ func (s *Step) Execute(ctx context.Context, execCtx *ExecutionContext) (*Executi
 		input = execCtx.Input
 	}
 
+	if s.Team != nil {
+		return s.executeTeam(ctx, execCtx, input)
+	}
+
 	// 3. Inject history into agent's system message if needed
 	// 3. 如果需要,将历史注入到 agent 的系统消息中
 	if s.shouldAddHistory(workflowConfig) && s.Agent != nil {
@@ -120,6 +134,13 @@ func (s *Step) Execute(ctx context.Context, execCtx *ExecutionContext) (*Executi
 	if err != nil {
 		return nil, fmt.Errorf("step %s execution failed: %w", s.ID, err)
 	}
+	// A workflow cannot confirm tool calls: cancel the paused run so the agent
+	// stays usable and fail the step
+	// 工作流无法确认工具调用：取消暂停的运行以保持 agent 可用，并使步骤失败
+	if pausedErr := output.PausedError(); pausedErr != nil {
+		_ = s.Agent.CancelPausedRun(output.RunID)
+		return nil, fmt.Errorf("step %s execution failed: %w", s.ID, pausedErr)
+	}
 
 	// 5. Update execution context
 	// 5. 更新执行上下文
@@ -143,6 +164,20 @@ func (s *Step) Execute(ctx context.Context, execCtx *ExecutionContext) (*Executi
 	return execCtx, nil
 }
 
+// executeTeam runs the step's team; history injection only applies to agents
+// executeTeam 运行步骤的 team；历史注入仅适用于 agent
+func (s *Step) executeTeam(ctx context.Context, execCtx *ExecutionContext, input string) (*ExecutionContext, error) {
+	output, err := s.Team.Run(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("step %s execution failed: %w", s.ID, err)
+	}
+
+	execCtx.Output = output.Content
+	execCtx.Set(fmt.Sprintf("step_%s_output", s.ID), output.Content)
+
+	return execCtx, nil
+}
+
 // GetID returns the step ID
 func (s *Step) GetID() string {
 	return s.ID
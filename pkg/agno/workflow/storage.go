@@ -22,6 +22,15 @@ var (
 	// ErrInvalidWorkflowID is returned when the workflow ID is invalid
 	// ErrInvalidWorkflowID 当工作流 ID 无效时返回
 	ErrInvalidWorkflowID = errors.New("invalid workflow ID")
+
+	// ErrRunNotFound is returned when a workflow run is not found
+	// ErrRunNotFound 当工作流运行未找到时返回
+	ErrRunNotFound = errors.New("run not found")
+
+	// ErrRunConflict is returned when a run was saved by someone else since it was loaded,
+	// e.g. because another caller already resumed it
+	// ErrRunConflict 当运行在加载后已被其他调用方保存（例如已被恢复）时返回
+	ErrRunConflict = errors.New("run was modified concurrently")
 )
 
 // WorkflowStorage defines the interface for storing and retrieving workflow sessions
@@ -100,6 +109,59 @@ type SessionFilter struct {
 	Offset int
 }
 
+// SessionQuerier is an optional interface for storage implementations that can filter sessions
+// SessionQuerier 是支持按条件过滤会话的存储实现的可选接口
+type SessionQuerier interface {
+	// QuerySessions returns sessions matching the filter, oldest first
+	// QuerySessions 返回匹配过滤条件的会话，按创建时间升序排列
+	QuerySessions(ctx context.Context, filter SessionFilter) ([]*WorkflowSession, error)
+}
+
+// RunLookup is an optional interface for storage implementations that can find a run's session directly
+// RunLookup 是可直接查找运行所属会话的存储实现的可选接口
+type RunLookup interface {
+	// GetSessionByRunID returns the session containing the run
+	// GetSessionByRunID 返回包含该运行的会话
+	// Returns ErrRunNotFound if no session contains the run
+	// 如果没有会话包含该运行则返回 ErrRunNotFound
+	GetSessionByRunID(ctx context.Context, runID string) (*WorkflowSession, error)
+}
+
+// RunStore is an optional interface for storage implementations that can save a single run
+// RunStore 是可单独保存运行记录的存储实现的可选接口
+type RunStore interface {
+	// SaveRun inserts the run into the session or replaces the stored run with the same ID,
+	// leaving the session's other runs untouched. A stored run is only replaced while its
+	// Revision still equals run.Revision; on success run.Revision is incremented
+	// SaveRun 将运行插入会话或替换同 ID 的已存储运行，不改动会话中的其他运行。
+	// 仅当已存储运行的 Revision 仍等于 run.Revision 时才替换；成功后 run.Revision 加一
+	// Returns ErrRunConflict if the stored run has a different revision and
+	// ErrSessionNotFound if the session does not exist
+	// 已存储运行的版本不同时返回 ErrRunConflict，会话不存在时返回 ErrSessionNotFound
+	SaveRun(ctx context.Context, sessionID string, run *WorkflowRun) error
+}
+
+// Matches reports whether the session satisfies the filter (Limit and Offset are ignored)
+// Matches 判断会话是否满足过滤条件（忽略 Limit 和 Offset）
+func (f SessionFilter) Matches(session *WorkflowSession) bool {
+	if session == nil {
+		return false
+	}
+	if f.WorkflowID != "" && session.WorkflowID != f.WorkflowID {
+		return false
+	}
+	if f.UserID != "" && session.UserID != f.UserID {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && !session.CreatedAt.After(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !session.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
 // SessionStats provides statistics about workflow sessions
 // SessionStats 提供有关工作流会话的统计信息
 type SessionStats struct {
@@ -0,0 +1,525 @@
+// Package storagetest provides a conformance suite for workflow.WorkflowStorage implementations
+// Package storagetest 为 workflow.WorkflowStorage 实现提供一致性测试套件
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rexleimo/agno-go/pkg/agno/run"
+	"github.com/rexleimo/agno-go/pkg/agno/types"
+	"github.com/rexleimo/agno-go/pkg/agno/workflow"
+)
+
+// Factory returns an empty storage for a single subtest
+// Factory 为单个子测试返回一个空存储
+type Factory func(t *testing.T) workflow.WorkflowStorage
+
+// Run exercises the WorkflowStorage contract, plus StorageStats, SessionQuerier and RunLookup when implemented
+// Run 测试 WorkflowStorage 契约；若实现了 StorageStats、SessionQuerier 和 RunLookup 也会一并测试
+func Run(t *testing.T, newStorage Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, storage workflow.WorkflowStorage)
+	}{
+		{"CreateSession", testCreateSession},
+		{"GetSession", testGetSession},
+		{"UpdateSession", testUpdateSession},
+		{"RoundTrip", testRoundTrip},
+		{"DeleteSession", testDeleteSession},
+		{"ListSessions", testListSessions},
+		{"ListUserSessions", testListUserSessions},
+		{"Clear", testClear},
+		{"ContextCancellation", testContextCancellation},
+		{"Stats", testStats},
+		{"QuerySessions", testQuerySessions},
+		{"GetSessionByRunID", testGetSessionByRunID},
+		{"SaveRun", testSaveRun},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newStorage(t)
+			t.Cleanup(func() { storage.Close() })
+			tt.fn(t, storage)
+		})
+	}
+}
+
+func mustCreate(t *testing.T, storage workflow.WorkflowStorage, sessionID, workflowID, userID string) *workflow.WorkflowSession {
+	t.Helper()
+	session, err := storage.CreateSession(context.Background(), sessionID, workflowID, userID)
+	if err != nil {
+		t.Fatalf("CreateSession(%s) error = %v", sessionID, err)
+	}
+	return session
+}
+
+func testCreateSession(t *testing.T, storage workflow.WorkflowStorage) {
+	ctx := context.Background()
+
+	session := mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+	if session.SessionID != "session-1" || session.WorkflowID != "workflow-1" || session.UserID != "user-1" {
+		t.Errorf("unexpected session %+v", session)
+	}
+	if session.CreatedAt.IsZero() {
+		t.Error("expected CreatedAt to be set")
+	}
+
+	if _, err := storage.CreateSession(ctx, "session-1", "workflow-1", "user-1"); !errors.Is(err, workflow.ErrSessionExists) {
+		t.Errorf("expected ErrSessionExists, got %v", err)
+	}
+	if _, err := storage.CreateSession(ctx, "", "workflow-1", "user-1"); !errors.Is(err, workflow.ErrInvalidSessionID) {
+		t.Errorf("expected ErrInvalidSessionID, got %v", err)
+	}
+	if _, err := storage.CreateSession(ctx, "session-2", "", "user-1"); !errors.Is(err, workflow.ErrInvalidWorkflowID) {
+		t.Errorf("expected ErrInvalidWorkflowID, got %v", err)
+	}
+}
+
+func testGetSession(t *testing.T, storage workflow.WorkflowStorage) {
+	ctx := context.Background()
+	mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+
+	retrieved, err := storage.GetSession(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	if retrieved.SessionID != "session-1" || retrieved.WorkflowID != "workflow-1" || retrieved.UserID != "user-1" {
+		t.Errorf("unexpected session %+v", retrieved)
+	}
+
+	if _, err := storage.GetSession(ctx, "non-existent"); !errors.Is(err, workflow.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+	if _, err := storage.GetSession(ctx, ""); !errors.Is(err, workflow.ErrInvalidSessionID) {
+		t.Errorf("expected ErrInvalidSessionID, got %v", err)
+	}
+}
+
+func testUpdateSession(t *testing.T, storage workflow.WorkflowStorage) {
+	ctx := context.Background()
+	session := mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+
+	session.AddRun(workflow.NewWorkflowRun("run-1", "session-1", "workflow-1", "input"))
+	if err := storage.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+
+	retrieved, err := storage.GetSession(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	if retrieved.CountRuns() != 1 {
+		t.Errorf("expected 1 run, got %d", retrieved.CountRuns())
+	}
+
+	// Runs removed from the session are removed from storage too
+	// 从会话中移除的运行也会从存储中移除
+	retrieved.Clear()
+	if err := storage.UpdateSession(ctx, retrieved); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+	retrieved, _ = storage.GetSession(ctx, "session-1")
+	if retrieved.CountRuns() != 0 {
+		t.Errorf("expected 0 runs after clearing, got %d", retrieved.CountRuns())
+	}
+
+	nonExistent := workflow.NewWorkflowSession("non-existent", "workflow-1", "user-1")
+	if err := storage.UpdateSession(ctx, nonExistent); !errors.Is(err, workflow.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+	if err := storage.UpdateSession(ctx, nil); !errors.Is(err, workflow.ErrInvalidSessionID) {
+		t.Errorf("expected ErrInvalidSessionID, got %v", err)
+	}
+}
+
+func testRoundTrip(t *testing.T, storage workflow.WorkflowStorage) {
+	ctx := context.Background()
+	session := mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+	session.SetMetadata("tier", "gold")
+
+	completed := workflow.NewWorkflowRun("run-1", "session-1", "workflow-1", "hello")
+	completed.MarkStarted()
+	completed.AddMessage(types.NewUserMessage("hello"))
+	completed.AddMessage(types.NewAssistantMessage("hi"))
+	completed.AddEvents(run.Events{run.NewRunContentEvent("run-1", "agent-1", "assistant", "hi", 1)})
+	completed.Metadata["step_count"] = float64(2)
+	completed.MarkCompleted("hi")
+	session.AddRun(completed)
+
+	cancelled := workflow.NewWorkflowRun("run-2", "session-1", "workflow-1", "again")
+	cancelled.MarkStarted()
+	cancelled.ApplyCancellation("user stop", "step-2", map[string]interface{}{"output": "partial"})
+	cancelled.ResumedFrom = "step-1"
+	session.AddRun(cancelled)
+	session.AddCancellation(&workflow.CancellationRecord{
+		RunID:      "run-2",
+		Reason:     "user stop",
+		StepID:     "step-2",
+		Snapshot:   map[string]interface{}{"output": "partial"},
+		OccurredAt: time.Now(),
+	})
+
+	if err := storage.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+
+	retrieved, err := storage.GetSession(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	if value, _ := retrieved.GetMetadata("tier"); value != "gold" {
+		t.Errorf("expected metadata tier=gold, got %v", value)
+	}
+
+	runs := retrieved.GetRuns()
+	if len(runs) != 2 || runs[0].RunID != "run-1" || runs[1].RunID != "run-2" {
+		t.Fatalf("unexpected runs %+v", runs)
+	}
+	first := runs[0]
+	if first.Status != workflow.RunStatusCompleted || first.Output != "hi" || first.Input != "hello" {
+		t.Errorf("unexpected completed run %+v", first)
+	}
+	if len(first.Messages) != 2 || first.Messages[1].Content != "hi" {
+		t.Errorf("messages not persisted: %+v", first.Messages)
+	}
+	if len(first.Events) != 1 || first.Events[0].EventType() != run.EventTypeRunContent {
+		t.Errorf("events not persisted: %+v", first.Events)
+	}
+	if first.Metadata["step_count"] != float64(2) {
+		t.Errorf("run metadata not persisted: %+v", first.Metadata)
+	}
+	if first.Duration() <= 0 || first.CompletedAt.IsZero() {
+		t.Errorf("timestamps not persisted: started=%v completed=%v", first.StartedAt, first.CompletedAt)
+	}
+
+	second := runs[1]
+	if second.Status != workflow.RunStatusCancelled || second.CancellationReason != "user stop" ||
+		second.LastStepID != "step-2" || second.ResumedFrom != "step-1" ||
+		second.CancellationSnapshot["output"] != "partial" {
+		t.Errorf("cancelled run not persisted: %+v", second)
+	}
+
+	cancellations := retrieved.GetCancellations()
+	if len(cancellations) != 1 || cancellations[0].RunID != "run-2" || cancellations[0].Snapshot["output"] != "partial" {
+		t.Errorf("cancellations not persisted: %+v", cancellations)
+	}
+	if len(retrieved.GetHistory(0)) != 2 {
+		t.Errorf("expected history for both completed runs, got %d", len(retrieved.GetHistory(0)))
+	}
+}
+
+func testDeleteSession(t *testing.T, storage workflow.WorkflowStorage) {
+	ctx := context.Background()
+	session := mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+	session.AddRun(workflow.NewWorkflowRun("run-1", "session-1", "workflow-1", "input"))
+	if err := storage.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+
+	if err := storage.DeleteSession(ctx, "session-1"); err != nil {
+		t.Fatalf("DeleteSession() error = %v", err)
+	}
+	if _, err := storage.GetSession(ctx, "session-1"); !errors.Is(err, workflow.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+
+	// A deleted ID can be reused without inheriting the old runs
+	// 删除后的 ID 可以复用，且不会继承旧的运行
+	mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+	retrieved, err := storage.GetSession(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	if retrieved.CountRuns() != 0 {
+		t.Errorf("expected a fresh session, got %d runs", retrieved.CountRuns())
+	}
+
+	if err := storage.DeleteSession(ctx, "non-existent"); !errors.Is(err, workflow.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+	if err := storage.DeleteSession(ctx, ""); !errors.Is(err, workflow.ErrInvalidSessionID) {
+		t.Errorf("expected ErrInvalidSessionID, got %v", err)
+	}
+}
+
+func testListSessions(t *testing.T, storage workflow.WorkflowStorage) {
+	ctx := context.Background()
+	mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+	mustCreate(t, storage, "session-2", "workflow-1", "user-2")
+	mustCreate(t, storage, "session-3", "workflow-2", "user-1")
+
+	cases := []struct {
+		workflowID    string
+		limit, offset int
+		want          int
+	}{
+		{"workflow-1", 0, 0, 2},
+		{"workflow-2", 0, 0, 1},
+		{"workflow-1", 1, 0, 1},
+		{"workflow-1", 0, 1, 1},
+		{"workflow-1", 0, 5, 0},
+		{"workflow-3", 0, 0, 0},
+	}
+	for _, c := range cases {
+		sessions, err := storage.ListSessions(ctx, c.workflowID, c.limit, c.offset)
+		if err != nil {
+			t.Fatalf("ListSessions(%s, %d, %d) error = %v", c.workflowID, c.limit, c.offset, err)
+		}
+		if sessions == nil || len(sessions) != c.want {
+			t.Errorf("ListSessions(%s, %d, %d) returned %d sessions, want %d", c.workflowID, c.limit, c.offset, len(sessions), c.want)
+		}
+	}
+
+	if _, err := storage.ListSessions(ctx, "", 0, 0); !errors.Is(err, workflow.ErrInvalidWorkflowID) {
+		t.Errorf("expected ErrInvalidWorkflowID, got %v", err)
+	}
+}
+
+func testListUserSessions(t *testing.T, storage workflow.WorkflowStorage) {
+	ctx := context.Background()
+	mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+	mustCreate(t, storage, "session-2", "workflow-1", "user-1")
+	mustCreate(t, storage, "session-3", "workflow-2", "user-2")
+
+	sessions, err := storage.ListUserSessions(ctx, "user-1", 0, 0)
+	if err != nil {
+		t.Fatalf("ListUserSessions() error = %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Errorf("expected 2 sessions for user-1, got %d", len(sessions))
+	}
+
+	sessions, _ = storage.ListUserSessions(ctx, "user-2", 0, 0)
+	if len(sessions) != 1 {
+		t.Errorf("expected 1 session for user-2, got %d", len(sessions))
+	}
+
+	sessions, _ = storage.ListUserSessions(ctx, "user-1", 1, 0)
+	if len(sessions) != 1 {
+		t.Errorf("expected 1 session with limit=1, got %d", len(sessions))
+	}
+}
+
+func testClear(t *testing.T, storage workflow.WorkflowStorage) {
+	ctx := context.Background()
+	for i := 1; i <= 3; i++ {
+		mustCreate(t, storage, fmt.Sprintf("session-%d", i), "workflow-1", "user-1")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	mustCreate(t, storage, "session-new", "workflow-1", "user-1")
+
+	count, err := storage.Clear(ctx, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected to clear 3 sessions, got %d", count)
+	}
+
+	sessions, err := storage.ListSessions(ctx, "workflow-1", 0, 0)
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].SessionID != "session-new" {
+		t.Errorf("expected only session-new to remain, got %d sessions", len(sessions))
+	}
+}
+
+func testContextCancellation(t *testing.T, storage workflow.WorkflowStorage) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := storage.CreateSession(ctx, "session-1", "workflow-1", "user-1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+	if _, err := storage.GetSession(ctx, "session-1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := storage.ListSessions(ctx, "workflow-1", 0, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func testStats(t *testing.T, storage workflow.WorkflowStorage) {
+	statsStorage, ok := storage.(workflow.StorageStats)
+	if !ok {
+		t.Skip("storage does not implement StorageStats")
+	}
+	ctx := context.Background()
+
+	session1 := mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+	run1 := workflow.NewWorkflowRun("run-1", "session-1", "workflow-1", "input-1")
+	run1.MarkStarted()
+	time.Sleep(10 * time.Millisecond)
+	run1.MarkCompleted("output-1")
+	session1.AddRun(run1)
+	run2 := workflow.NewWorkflowRun("run-2", "session-1", "workflow-1", "input-2")
+	run2.MarkStarted()
+	run2.MarkFailed(errors.New("test error"))
+	session1.AddRun(run2)
+	if err := storage.UpdateSession(ctx, session1); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+
+	session2 := mustCreate(t, storage, "session-2", "workflow-2", "user-1")
+	run3 := workflow.NewWorkflowRun("run-3", "session-2", "workflow-2", "input-3")
+	run3.MarkStarted()
+	session2.AddRun(run3)
+	if err := storage.UpdateSession(ctx, session2); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+
+	stats, err := statsStorage.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.TotalSessions != 2 || stats.TotalRuns != 3 || stats.CompletedRuns != 2 ||
+		stats.SuccessfulRuns != 1 || stats.FailedRuns != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.AverageDuration <= 0 {
+		t.Error("expected positive average duration")
+	}
+
+	stats, err = statsStorage.GetWorkflowStats(ctx, "workflow-2")
+	if err != nil {
+		t.Fatalf("GetWorkflowStats() error = %v", err)
+	}
+	if stats.TotalSessions != 1 || stats.TotalRuns != 1 || stats.CompletedRuns != 0 || stats.AverageDuration != 0 {
+		t.Errorf("unexpected workflow stats %+v", stats)
+	}
+
+	if _, err := statsStorage.GetWorkflowStats(ctx, ""); !errors.Is(err, workflow.ErrInvalidWorkflowID) {
+		t.Errorf("expected ErrInvalidWorkflowID, got %v", err)
+	}
+}
+
+func testQuerySessions(t *testing.T, storage workflow.WorkflowStorage) {
+	querier, ok := storage.(workflow.SessionQuerier)
+	if !ok {
+		t.Skip("storage does not implement SessionQuerier")
+	}
+	ctx := context.Background()
+
+	mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+	time.Sleep(5 * time.Millisecond)
+	middle := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	mustCreate(t, storage, "session-2", "workflow-1", "user-2")
+	mustCreate(t, storage, "session-3", "workflow-1", "user-1")
+	mustCreate(t, storage, "session-4", "workflow-2", "user-1")
+
+	cases := []struct {
+		name   string
+		filter workflow.SessionFilter
+		want   []string
+	}{
+		{"all", workflow.SessionFilter{}, []string{"session-1", "session-2", "session-3", "session-4"}},
+		{"workflow and user", workflow.SessionFilter{WorkflowID: "workflow-1", UserID: "user-1"}, []string{"session-1", "session-3"}},
+		{"created after", workflow.SessionFilter{WorkflowID: "workflow-1", CreatedAfter: middle}, []string{"session-2", "session-3"}},
+		{"created before", workflow.SessionFilter{CreatedBefore: middle}, []string{"session-1"}},
+		{"paginated", workflow.SessionFilter{Limit: 2, Offset: 1}, []string{"session-2", "session-3"}},
+		{"past the end", workflow.SessionFilter{Offset: 10}, []string{}},
+	}
+	for _, c := range cases {
+		sessions, err := querier.QuerySessions(ctx, c.filter)
+		if err != nil {
+			t.Fatalf("%s: QuerySessions() error = %v", c.name, err)
+		}
+		got := make([]string, len(sessions))
+		for i, session := range sessions {
+			got[i] = session.SessionID
+		}
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("%s: QuerySessions() = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func testGetSessionByRunID(t *testing.T, storage workflow.WorkflowStorage) {
+	lookup, ok := storage.(workflow.RunLookup)
+	if !ok {
+		t.Skip("storage does not implement RunLookup")
+	}
+	ctx := context.Background()
+
+	mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+	session := mustCreate(t, storage, "session-2", "workflow-1", "user-1")
+	session.AddRun(workflow.NewWorkflowRun("run-a", "session-2", "workflow-1", "input"))
+	if err := storage.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+
+	found, err := lookup.GetSessionByRunID(ctx, "run-a")
+	if err != nil {
+		t.Fatalf("GetSessionByRunID() error = %v", err)
+	}
+	if found.SessionID != "session-2" || found.GetRun("run-a") == nil {
+		t.Errorf("unexpected session %s", found.SessionID)
+	}
+	if _, err := lookup.GetSessionByRunID(ctx, "missing"); !errors.Is(err, workflow.ErrRunNotFound) {
+		t.Errorf("expected ErrRunNotFound, got %v", err)
+	}
+}
+
+func testSaveRun(t *testing.T, storage workflow.WorkflowStorage) {
+	store, ok := storage.(workflow.RunStore)
+	if !ok {
+		t.Skip("storage does not implement RunStore")
+	}
+	ctx := context.Background()
+
+	session := mustCreate(t, storage, "session-1", "workflow-1", "user-1")
+	session.AddRun(workflow.NewWorkflowRun("run-a", "session-1", "workflow-1", "first"))
+	if err := storage.UpdateSession(ctx, session); err != nil {
+		t.Fatalf("UpdateSession() error = %v", err)
+	}
+
+	// Insert a new run next to the existing one
+	runB := workflow.NewWorkflowRun("run-b", "session-1", "workflow-1", "second")
+	if err := store.SaveRun(ctx, "session-1", runB); err != nil {
+		t.Fatalf("SaveRun() insert error = %v", err)
+	}
+	if runB.Revision != 1 {
+		t.Errorf("expected revision 1 after insert, got %d", runB.Revision)
+	}
+
+	// Two callers load the same revision of run-a; only the first save wins
+	winner := workflow.NewWorkflowRun("run-a", "session-1", "workflow-1", "first")
+	winner.MarkCompleted("done")
+	loser := workflow.NewWorkflowRun("run-a", "session-1", "workflow-1", "first")
+	loser.MarkFailed(errors.New("stale"))
+	if err := store.SaveRun(ctx, "session-1", winner); err != nil {
+		t.Fatalf("SaveRun() update error = %v", err)
+	}
+	if err := store.SaveRun(ctx, "session-1", loser); !errors.Is(err, workflow.ErrRunConflict) {
+		t.Fatalf("expected ErrRunConflict for a stale revision, got %v", err)
+	}
+
+	got, err := storage.GetSession(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	if got.CountRuns() != 2 {
+		t.Fatalf("expected 2 runs, got %d", got.CountRuns())
+	}
+	runs := got.GetRuns()
+	if runs[0].RunID != "run-a" || runs[1].RunID != "run-b" {
+		t.Errorf("unexpected run order %s, %s", runs[0].RunID, runs[1].RunID)
+	}
+	if stored := got.GetRun("run-a"); stored.Status != workflow.RunStatusCompleted || stored.Revision != 1 {
+		t.Errorf("unexpected stored run-a: status %s, revision %d", stored.Status, stored.Revision)
+	}
+
+	missing := workflow.NewWorkflowRun("run-c", "missing", "workflow-1", "input")
+	if err := store.SaveRun(ctx, "missing", missing); !errors.Is(err, workflow.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+}
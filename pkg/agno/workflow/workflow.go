@@ -2,9 +2,11 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,6 +28,14 @@ type Workflow struct {
 	historyStore      WorkflowStorage
 	numHistoryRuns    int
 	addHistoryToSteps bool
+
+	// enableCheckpoints persists the execution state after every node
+	// enableCheckpoints 在每个节点完成后持久化执行状态
+	enableCheckpoints bool
+
+	// runMu serializes run saves for storages that do not implement RunStore
+	// runMu 为未实现 RunStore 的存储串行化运行保存
+	runMu sync.Mutex
 }
 
 // Node represents a node in the workflow graph
@@ -44,6 +54,7 @@ const (
 	NodeTypeLoop      NodeType = "loop"
 	NodeTypeParallel  NodeType = "parallel"
 	NodeTypeRouter    NodeType = "router"
+	NodeTypeApproval  NodeType = "approval"
 )
 
 // Config contains workflow configuration
@@ -69,6 +80,12 @@ type Config struct {
 	// AddHistoryToSteps automatically adds history context to all steps
 	// AddHistoryToSteps 自动将历史上下文添加到所有步骤
 	AddHistoryToSteps bool `json:"add_history_to_steps"`
+
+	// EnableCheckpoints saves the run and an ExecutionContext snapshot to HistoryStore
+	// after every top-level node, so Resume can continue interrupted runs
+	// EnableCheckpoints 在每个顶层节点完成后将运行和 ExecutionContext 快照保存到 HistoryStore，
+	// 使 Resume 可以继续被中断的运行
+	EnableCheckpoints bool `json:"enable_checkpoints"`
 }
 
 // New creates a new workflow
@@ -88,7 +105,7 @@ func New(config Config) (*Workflow, error) {
 
 	// 历史配置验证和默认值
 	// History configuration validation and defaults
-	if config.EnableHistory && config.HistoryStore == nil {
+	if (config.EnableHistory || config.EnableCheckpoints || hasApproval(config.Steps)) && config.HistoryStore == nil {
 		// 使用默认内存存储
 		// Use default memory storage
 		config.HistoryStore = NewMemoryStorage(100)
@@ -107,6 +124,7 @@ func New(config Config) (*Workflow, error) {
 		historyStore:      config.HistoryStore,
 		numHistoryRuns:    config.NumHistoryRuns,
 		addHistoryToSteps: config.AddHistoryToSteps,
+		enableCheckpoints: config.EnableCheckpoints,
 	}, nil
 }
 
@@ -165,21 +183,25 @@ func (w *Workflow) Run(ctx context.Context, input string, sessionID string, opts
 		execCtx.SetSessionState("media_payload", options.mediaPayload)
 	}
 
-	if w.enableHistory && w.historyStore != nil {
-		if err := w.loadHistory(ctx, execCtx); err != nil {
-			w.logger.Error("failed to load history", "error", err)
-		}
-	}
+	w.prepareHistory(ctx, execCtx)
 
-	if w.enableHistory {
-		execCtx.SetSessionState("workflow_history_config", &WorkflowHistoryConfig{
-			AddHistoryToSteps: w.addHistoryToSteps,
-			NumHistoryRuns:    w.numHistoryRuns,
-		})
+	startIdx := 0
+	if options.resumeFromStep != "" {
+		found := false
+		for i, step := range w.Steps {
+			if step.GetID() == options.resumeFromStep {
+				startIdx = i
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, types.NewInvalidInputError("resume step not found", fmt.Errorf("step %s not in workflow", options.resumeFromStep))
+		}
 	}
 
 	var workflowRun *WorkflowRun
-	if w.enableHistory {
+	if w.persistsRuns() {
 		runID := runCtx.RunID
 		workflowRun = NewWorkflowRun(runID, sessionID, w.ID, input)
 		workflowRun.MarkStarted()
@@ -202,22 +224,195 @@ func (w *Workflow) Run(ctx context.Context, input string, sessionID string, opts
 		}
 	}
 
+	// 记录初始检查点，使在首个节点完成前中断的运行也能恢复
+	// Record the initial checkpoint so runs interrupted before the first node can resume
+	if w.checkpointing() {
+		if err := w.saveCheckpoint(ctx, workflowRun, newCheckpoint(startIdx-1, "", execCtx)); err != nil {
+			return nil, err
+		}
+	}
+
+	return w.runSteps(ctx, execCtx, workflowRun, startIdx, metrics)
+}
+
+// Resume continues a failed, cancelled or interrupted run from the node after
+// its last checkpoint, restoring the checkpointed output, data, session state
+// and messages. The run keeps its ID and is updated in place.
+// Resume 从最后一个检查点之后的节点继续失败、取消或中断的运行，
+// 并恢复检查点中的输出、数据、会话状态和消息。运行保持原 ID 并原地更新。
+func (w *Workflow) Resume(ctx context.Context, runID string) (*ExecutionContext, error) {
+	session, workflowRun, err := w.loadRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if workflowRun.Status == RunStatusCompleted {
+		return nil, types.NewInvalidInputError(fmt.Sprintf("run %s already completed", runID), nil)
+	}
+	if workflowRun.PendingApproval != nil {
+		return nil, types.NewInvalidInputError(fmt.Sprintf("run %s is waiting for approval", runID), nil)
+	}
+
+	return w.resumeRun(ctx, session, workflowRun, nil)
+}
+
+// ResolveApproval records the decision for a run waiting at an approval node and
+// resumes it. Approve continues unchanged, edit replaces the output with the
+// decision input, and reject fails the run with ErrApprovalRejected.
+// ResolveApproval 记录等待审批的运行的决定并恢复运行。approve 保持输出不变继续，
+// edit 用决定中的输入替换输出，reject 以 ErrApprovalRejected 使运行失败。
+func (w *Workflow) ResolveApproval(ctx context.Context, runID string, decision ApprovalDecision) (*ExecutionContext, error) {
+	if err := decision.Validate(); err != nil {
+		return nil, types.NewInvalidInputError("invalid approval decision", err)
+	}
+
+	session, workflowRun, err := w.loadRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	request := workflowRun.PendingApproval
+	if workflowRun.Status != RunStatusPending || request == nil {
+		return nil, types.NewInvalidInputError(fmt.Sprintf("run %s is not waiting for approval", runID), nil)
+	}
+
+	if decision.DecidedAt.IsZero() {
+		decision.DecidedAt = time.Now()
+	}
+	request.Decision = &decision
+	workflowRun.Approvals = append(workflowRun.Approvals, request)
+	workflowRun.PendingApproval = nil
+
+	w.logger.Info("approval resolved",
+		"workflow_id", w.ID,
+		"run_id", runID,
+		"step_id", request.StepID,
+		"action", decision.Action)
+
+	return w.resumeRun(ctx, session, workflowRun, request)
+}
+
+// PendingApproval returns the approval request a run is waiting on, or nil if it is not waiting
+// PendingApproval 返回运行正在等待的审批请求；未在等待时返回 nil
+func (w *Workflow) PendingApproval(ctx context.Context, runID string) (*ApprovalRequest, error) {
+	_, workflowRun, err := w.loadRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	return workflowRun.PendingApproval, nil
+}
+
+// loadRun finds a stored run of this workflow and its session
+// loadRun 查找此工作流已存储的运行及其 session
+func (w *Workflow) loadRun(ctx context.Context, runID string) (*WorkflowSession, *WorkflowRun, error) {
+	if runID == "" {
+		return nil, nil, types.NewInvalidInputError("run ID cannot be empty", nil)
+	}
+	if w.historyStore == nil {
+		return nil, nil, types.NewInvalidConfigError("resume requires a history store", nil)
+	}
+
+	session, err := w.findRunSession(ctx, runID)
+	if err != nil {
+		if errors.Is(err, ErrRunNotFound) {
+			return nil, nil, types.NewInvalidInputError(fmt.Sprintf("run %s not found", runID), err)
+		}
+		return nil, nil, types.NewError(types.ErrCodeUnknown, "failed to load run", err)
+	}
+	// Work on a copy: storages may share the stored run with other callers
+	// 使用副本：存储可能与其他调用方共享已存储的运行
+	return session, session.GetRun(runID).clone(), nil
+}
+
+// resumeRun restores the run's checkpoint and executes the remaining nodes.
+// A resolved approval is handed to its node through the execution context.
+// resumeRun 恢复运行的检查点并执行剩余节点；已处理的审批通过执行上下文传给对应节点。
+func (w *Workflow) resumeRun(ctx context.Context, session *WorkflowSession, workflowRun *WorkflowRun, approval *ApprovalRequest) (*ExecutionContext, error) {
+	runCtx := run.NewContext()
+	if existing, ok := run.FromContext(ctx); ok && existing != nil {
+		runCtx = existing.Clone()
+	}
+	runCtx.RunID = workflowRun.RunID
+	runCtx.SessionID = session.SessionID
+	runCtx.WorkflowID = w.ID
+	if runCtx.UserID == "" {
+		runCtx.UserID = session.UserID
+	}
+	ctx = run.WithContext(ctx, runCtx)
+
+	execCtx := NewExecutionContextWithSession(workflowRun.Input, session.SessionID, session.UserID)
 	startIdx := 0
-	if options.resumeFromStep != "" {
-		found := false
-		for i, step := range w.Steps {
-			if step.GetID() == options.resumeFromStep {
-				startIdx = i
-				found = true
-				break
-			}
+	if checkpoint := workflowRun.Checkpoint; checkpoint != nil {
+		startIdx = checkpoint.resumeIndex(w.Steps)
+		if startIdx < 0 {
+			return nil, types.NewInvalidInputError("checkpoint step not found",
+				fmt.Errorf("step %s not in workflow", checkpoint.StepID))
 		}
-		if !found {
-			return nil, types.NewInvalidInputError("resume step not found", fmt.Errorf("step %s not in workflow", options.resumeFromStep))
+		checkpoint.restore(execCtx)
+		workflowRun.LastStepID = checkpoint.StepID
+	}
+	if approval != nil {
+		execCtx.setApprovalDecision(approval.StepID, approval.Decision)
+	}
+	execCtx.SetRunContextMetadata(runContextMetadata(runCtx))
+	w.prepareHistory(ctx, execCtx)
+
+	workflowRun.Status = RunStatusRunning
+	workflowRun.Error = ""
+	workflowRun.CompletedAt = time.Time{}
+	if startIdx < len(w.Steps) {
+		workflowRun.ResumedFrom = w.Steps[startIdx].GetID()
+	}
+
+	w.logger.Info("workflow resumed",
+		"workflow_id", w.ID,
+		"session_id", session.SessionID,
+		"run_id", workflowRun.RunID,
+		"resume_from", workflowRun.ResumedFrom)
+
+	// Claim the run before executing anything, so only one caller resumes it
+	// 执行前先认领运行，确保只有一个调用方恢复它
+	if err := w.storeRun(ctx, session.SessionID, workflowRun); err != nil {
+		if errors.Is(err, ErrRunConflict) {
+			return nil, w.runTakenOver(workflowRun, err)
 		}
+		return nil, types.NewError(types.ErrCodeUnknown, "failed to claim run", err)
 	}
 
+	metrics := NewWorkflowMetrics()
+	metrics.Start()
+	defer metrics.Stop()
+
+	return w.runSteps(ctx, execCtx, workflowRun, startIdx, metrics)
+}
+
+// prepareHistory loads history and the session into execCtx when history or checkpoints are enabled
+// prepareHistory 在启用历史或检查点时将历史和会话加载到 execCtx
+func (w *Workflow) prepareHistory(ctx context.Context, execCtx *ExecutionContext) {
+	if w.enableHistory && w.historyStore != nil {
+		if err := w.loadHistory(ctx, execCtx); err != nil {
+			w.logger.Error("failed to load history", "error", err)
+		}
+	} else if w.persistsRuns() {
+		if _, err := w.ensureSession(ctx, execCtx.SessionID, execCtx.UserID); err != nil {
+			w.logger.Error("failed to prepare session", "error", err)
+		}
+	}
+
+	if w.enableHistory {
+		execCtx.SetSessionState("workflow_history_config", &WorkflowHistoryConfig{
+			AddHistoryToSteps: w.addHistoryToSteps,
+			NumHistoryRuns:    w.numHistoryRuns,
+		})
+	}
+}
+
+// runSteps executes the workflow nodes from startIdx and records the run outcome
+// runSteps 从 startIdx 开始执行工作流节点并记录运行结果
+func (w *Workflow) runSteps(ctx context.Context, execCtx *ExecutionContext, workflowRun *WorkflowRun, startIdx int, metrics *WorkflowMetrics) (*ExecutionContext, error) {
+	sessionID := execCtx.SessionID
 	var lastStepID string
+	if workflowRun != nil {
+		lastStepID = workflowRun.LastStepID
+	}
 
 	for idx := startIdx; idx < len(w.Steps); idx++ {
 		step := w.Steps[idx]
@@ -259,6 +454,10 @@ func (w *Workflow) Run(ctx context.Context, input string, sessionID string, opts
 			"sequence", sequence)
 
 		result, err := step.Execute(ctx, execCtx)
+		var approval *errApprovalRequired
+		if errors.As(err, &approval) {
+			return nil, w.suspendForApproval(ctx, execCtx, workflowRun, idx, approval.request, metrics)
+		}
 		if err != nil {
 			w.logger.Error("step execution failed",
 				"step_id", currentStepID,
@@ -280,14 +479,22 @@ func (w *Workflow) Run(ctx context.Context, input string, sessionID string, opts
 				workflowRun.AddEvents(events)
 			}
 		}
+		if w.checkpointing() {
+			if err := w.saveCheckpoint(ctx, workflowRun, newCheckpoint(idx, currentStepID, execCtx)); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if workflowRun != nil {
 		workflowRun.MarkCompleted(execCtx.Output)
 		workflowRun.LastStepID = lastStepID
 		workflowRun.Messages = extractMessages(execCtx)
+		workflowRun.Checkpoint = nil
 		metrics.Stop()
-		w.saveRun(ctx, sessionID, workflowRun, metrics)
+		if err := w.saveRun(ctx, sessionID, workflowRun, metrics); errors.Is(err, ErrRunConflict) {
+			return nil, w.runTakenOver(workflowRun, err)
+		}
 	}
 
 	metrics.Stop()
@@ -314,23 +521,9 @@ func (w *Workflow) loadHistory(ctx context.Context, execCtx *ExecutionContext) e
 
 	// 获取或创建 session
 	// Get or create session
-	session, err := w.historyStore.GetSession(ctx, execCtx.SessionID)
+	session, err := w.ensureSession(ctx, execCtx.SessionID, execCtx.UserID)
 	if err != nil {
-		if err == ErrSessionNotFound {
-			// 创建新 session
-			// Create new session
-			session, err = w.historyStore.CreateSession(
-				ctx,
-				execCtx.SessionID,
-				w.ID,
-				execCtx.UserID,
-			)
-			if err != nil {
-				return fmt.Errorf("failed to create session: %w", err)
-			}
-		} else {
-			return fmt.Errorf("failed to get session: %w", err)
-		}
+		return err
 	}
 
 	// 获取历史记录
@@ -367,31 +560,143 @@ func (w *Workflow) loadHistory(ctx context.Context, execCtx *ExecutionContext) e
 	return nil
 }
 
-// saveRun 保存运行记录到存储
-// saveRun saves run record to storage
-func (w *Workflow) saveRun(ctx context.Context, sessionID string, run *WorkflowRun, metrics *WorkflowMetrics) error {
-	if w.historyStore == nil {
-		return nil
+// ensureSession 获取或创建 session
+// ensureSession gets or creates the session in the history store
+func (w *Workflow) ensureSession(ctx context.Context, sessionID, userID string) (*WorkflowSession, error) {
+	session, err := w.historyStore.GetSession(ctx, sessionID)
+	if err == nil {
+		return session, nil
+	}
+	if !errors.Is(err, ErrSessionNotFound) {
+		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
-	// 获取 session
-	// Get session
-	session, err := w.historyStore.GetSession(ctx, sessionID)
+	session, err = w.historyStore.CreateSession(ctx, sessionID, w.ID, userID)
 	if err != nil {
-		return fmt.Errorf("failed to get session: %w", err)
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return session, nil
+}
+
+// checkpointing reports whether checkpoints are enabled and can be stored
+// checkpointing 报告检查点是否启用且可存储
+func (w *Workflow) checkpointing() bool {
+	return w.enableCheckpoints && w.historyStore != nil
+}
+
+// persistsRuns reports whether runs are recorded in the history store
+// persistsRuns 报告运行是否记录到历史存储
+func (w *Workflow) persistsRuns() bool {
+	return w.historyStore != nil && (w.enableHistory || w.enableCheckpoints || hasApproval(w.Steps))
+}
+
+// hasApproval reports whether any top-level node is an approval
+// hasApproval 报告顶层节点中是否存在审批节点
+func hasApproval(steps []Node) bool {
+	for _, step := range steps {
+		if step != nil && step.GetType() == NodeTypeApproval {
+			return true
+		}
+	}
+	return false
+}
+
+// suspendForApproval 保存审批前的检查点并将运行标记为 pending
+// suspendForApproval stores the checkpoint before the approval node and marks the run pending
+func (w *Workflow) suspendForApproval(ctx context.Context, execCtx *ExecutionContext, workflowRun *WorkflowRun, idx int, request *ApprovalRequest, metrics *WorkflowMetrics) error {
+	if workflowRun == nil {
+		return types.NewInvalidConfigError("approval requires a history store", nil)
+	}
+
+	previousID := ""
+	if idx > 0 {
+		previousID = w.Steps[idx-1].GetID()
+	}
+	workflowRun.Checkpoint = newCheckpoint(idx-1, previousID, execCtx)
+	workflowRun.MarkPendingApproval(request)
+	metrics.Stop()
+	if err := w.saveRun(ctx, execCtx.SessionID, workflowRun, metrics); err != nil {
+		return types.NewError(types.ErrCodeUnknown, "failed to save approval request", err)
+	}
+
+	w.logger.Info("workflow waiting for approval",
+		"workflow_id", w.ID,
+		"run_id", workflowRun.RunID,
+		"step_id", request.StepID)
+
+	return &PendingApprovalError{RunID: workflowRun.RunID, Request: request}
+}
+
+// saveCheckpoint 保存检查点；运行已被其他调用方接管时返回错误，其他失败只记录日志
+// saveCheckpoint stores the checkpoint with the run. It returns an error when another
+// caller took over the run, so this one stops; other failures are logged and do not stop the run
+func (w *Workflow) saveCheckpoint(ctx context.Context, workflowRun *WorkflowRun, checkpoint *Checkpoint) error {
+	if workflowRun == nil {
+		return nil
+	}
+	workflowRun.Checkpoint = checkpoint
+	if err := w.saveRun(ctx, workflowRun.SessionID, workflowRun, nil); err != nil {
+		if errors.Is(err, ErrRunConflict) {
+			return w.runTakenOver(workflowRun, err)
+		}
+		w.logger.Warn("failed to save checkpoint",
+			"run_id", workflowRun.RunID,
+			"step_id", checkpoint.StepID,
+			"error", err)
+	}
+	return nil
+}
+
+// runTakenOver 报告运行已被其他调用方恢复
+// runTakenOver reports that another caller resumed the run
+func (w *Workflow) runTakenOver(workflowRun *WorkflowRun, err error) error {
+	w.logger.Warn("run was resumed by another caller",
+		"workflow_id", w.ID,
+		"run_id", workflowRun.RunID)
+	return types.NewError(types.ErrCodeUnknown, fmt.Sprintf("run %s was resumed by another caller", workflowRun.RunID), err)
+}
+
+// findRunSession 查找包含运行的 session
+// findRunSession finds the session of this workflow that contains the run
+func (w *Workflow) findRunSession(ctx context.Context, runID string) (*WorkflowSession, error) {
+	var session *WorkflowSession
+	if lookup, ok := w.historyStore.(RunLookup); ok {
+		found, err := lookup.GetSessionByRunID(ctx, runID)
+		if err != nil {
+			return nil, err
+		}
+		session = found
+	} else {
+		sessions, err := w.historyStore.ListSessions(ctx, w.ID, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range sessions {
+			if candidate.GetRun(runID) != nil {
+				session = candidate
+				break
+			}
+		}
+	}
+
+	if session == nil || session.WorkflowID != w.ID || session.GetRun(runID) == nil {
+		return nil, ErrRunNotFound
+	}
+	return session, nil
+}
+
+// saveRun 保存运行记录到存储，只写入该运行
+// saveRun saves run record to storage, writing only this run
+func (w *Workflow) saveRun(ctx context.Context, sessionID string, run *WorkflowRun, metrics *WorkflowMetrics) error {
+	if w.historyStore == nil {
+		return nil
 	}
 	if metrics != nil {
 		attachWorkflowMetrics(run, metrics)
 	}
 
-	// 添加运行记录
-	// Add run record
-	session.AddRun(run)
-
-	// 更新 session
-	// Update session
-	if err := w.historyStore.UpdateSession(ctx, session); err != nil {
-		return fmt.Errorf("failed to update session: %w", err)
+	if err := w.storeRun(ctx, sessionID, run); err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
 	}
 
 	w.logger.Debug("saved run",
@@ -402,6 +707,33 @@ func (w *Workflow) saveRun(ctx context.Context, sessionID string, run *WorkflowR
 	return nil
 }
 
+// storeRun 保存单个运行并检查其版本，成功后 run.Revision 加一
+// storeRun saves a single run with the RunStore revision check and increments run.Revision.
+// Storages without RunStore get the same check within this process only.
+func (w *Workflow) storeRun(ctx context.Context, sessionID string, run *WorkflowRun) error {
+	if store, ok := w.historyStore.(RunStore); ok {
+		return store.SaveRun(ctx, sessionID, run)
+	}
+
+	w.runMu.Lock()
+	defer w.runMu.Unlock()
+
+	session, err := w.historyStore.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if stored := session.GetRun(run.RunID); stored != nil && stored.Revision != run.Revision {
+		return ErrRunConflict
+	}
+	run.Revision++
+	session.UpsertRun(run)
+	if err := w.historyStore.UpdateSession(ctx, session); err != nil {
+		run.Revision--
+		return err
+	}
+	return nil
+}
+
 func attachWorkflowMetrics(run *WorkflowRun, metrics *WorkflowMetrics) {
 	if run == nil || metrics == nil {
 		return
@@ -2,12 +2,15 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
 	"github.com/rexleimo/agno-go/pkg/agno/agent"
 	"github.com/rexleimo/agno-go/pkg/agno/media"
 	"github.com/rexleimo/agno-go/pkg/agno/models"
+	"github.com/rexleimo/agno-go/pkg/agno/team"
+	"github.com/rexleimo/agno-go/pkg/agno/tools/toolkit"
 	"github.com/rexleimo/agno-go/pkg/agno/types"
 )
 
@@ -53,6 +56,40 @@ func createMockAgent(id string, responseContent string) *agent.Agent {
 	return ag
 }
 
+// createConfirmingAgent returns an agent whose model calls a tool that
+// requires confirmation until it sees a tool result.
+func createConfirmingAgent(id string) *agent.Agent {
+	tk := toolkit.NewBaseToolkit("confirm")
+	tk.RegisterFunction(&toolkit.Function{
+		Name:                 "delete",
+		Description:          "delete",
+		RequiresConfirmation: true,
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "deleted", nil
+		},
+	})
+	model := &MockModel{
+		BaseModel: models.BaseModel{ID: id, Provider: "mock"},
+		InvokeFunc: func(ctx context.Context, req *models.InvokeRequest) (*types.ModelResponse, error) {
+			for _, msg := range req.Messages {
+				if msg.Role == types.RoleTool {
+					return &types.ModelResponse{Content: "done"}, nil
+				}
+			}
+			return &types.ModelResponse{ToolCalls: []types.ToolCall{
+				{ID: "call_delete", Type: "function", Function: types.ToolCallFunction{Name: "delete", Arguments: `{}`}},
+			}}, nil
+		},
+	}
+
+	ag, _ := agent.New(agent.Config{
+		ID:       id,
+		Model:    model,
+		Toolkits: []toolkit.Toolkit{tk},
+	})
+	return ag
+}
+
 type stubNode struct {
 	id       string
 	nodeType NodeType
@@ -247,6 +284,60 @@ func TestStep_Execute(t *testing.T) {
 	}
 }
 
+func TestStep_ExecuteTeam(t *testing.T) {
+	tm, err := team.New(team.Config{
+		ID:     "reviewers",
+		Agents: []*agent.Agent{createMockAgent("a", "draft"), createMockAgent("b", "reviewed")},
+		Mode:   team.ModeSequential,
+	})
+	if err != nil {
+		t.Fatalf("team.New() error = %v", err)
+	}
+
+	if _, err := NewStep(StepConfig{ID: "both", Agent: createMockAgent("c", "x"), Team: tm}); err == nil {
+		t.Fatal("expected error when both agent and team are set")
+	}
+	if _, err := NewStep(StepConfig{ID: "neither"}); err == nil {
+		t.Fatal("expected error when neither agent nor team is set")
+	}
+
+	step, err := NewStep(StepConfig{ID: "review", Team: tm})
+	if err != nil {
+		t.Fatalf("NewStep() error = %v", err)
+	}
+
+	result, err := step.Execute(context.Background(), NewExecutionContext("input"))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Output != "reviewed" {
+		t.Errorf("Output = %v, want 'reviewed'", result.Output)
+	}
+	if out, _ := result.Get("step_review_output"); out != "reviewed" {
+		t.Errorf("step_review_output = %v, want 'reviewed'", out)
+	}
+}
+
+func TestStep_ExecutePausedAgent(t *testing.T) {
+	confirming := createConfirmingAgent("confirming")
+	step, err := NewStep(StepConfig{ID: "cleanup", Agent: confirming})
+	if err != nil {
+		t.Fatalf("NewStep() error = %v", err)
+	}
+
+	_, err = step.Execute(context.Background(), NewExecutionContext("clean up"))
+	var pausedErr *agent.PausedRunError
+	if !errors.As(err, &pausedErr) {
+		t.Fatalf("Execute() error = %v, want PausedRunError", err)
+	}
+
+	// The paused run was cancelled, so the agent can run again
+	output, err := confirming.Run(context.Background(), "again")
+	if err != nil || output.Content != "done" {
+		t.Fatalf("agent Run() after pause = %v, %v", output, err)
+	}
+}
+
 func TestCondition_Execute(t *testing.T) {
 	trueAgent := createMockAgent("true", "true branch")
 	falseAgent := createMockAgent("false", "false branch")
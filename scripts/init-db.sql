@@ -40,6 +40,19 @@ CREATE INDEX IF NOT EXISTS idx_messages_session_id ON messages(session_id);
 -- Create index on created_at for sorting
 CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages(created_at);
 
+-- Conversation memory table (pkg/agno/db/postgres.Memory)
+CREATE TABLE IF NOT EXISTS memory_messages (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index on user_id for per-user history lookups
+CREATE INDEX IF NOT EXISTS idx_memory_messages_user_id ON memory_messages(user_id, id);
+
 -- Agent runs table (for tracking agent executions)
 CREATE TABLE IF NOT EXISTS agent_runs (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),